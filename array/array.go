@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package array provides a generic dense array stored in host memory.
+package array
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Dense is an array storing all its elements in row-major order in a Go slice.
+type Dense[T dtype.GoDataType] struct {
+	axisLengths []int
+	data        []T
+}
+
+var _ shape.ArrayND[float32] = (*Dense[float32])(nil)
+
+// New returns an array given its data and its axis lengths.
+// The array uses data as its storage without copying it.
+func New[T dtype.GoDataType](data []T, axisLengths ...int) (*Dense[T], error) {
+	if size := shape.Size(axisLengths); len(data) != size {
+		return nil, fmt.Errorf("cannot create an array of shape %v with %d elements: want %d elements", axisLengths, len(data), size)
+	}
+	return &Dense[T]{axisLengths: slices.Clone(axisLengths), data: data}, nil
+}
+
+// Zeros returns an array of the given axis lengths filled with zero values.
+func Zeros[T dtype.GoDataType](axisLengths ...int) *Dense[T] {
+	return &Dense[T]{
+		axisLengths: slices.Clone(axisLengths),
+		data:        make([]T, shape.Size(axisLengths)),
+	}
+}
+
+// Scalar returns an array with no axis storing a single value.
+func Scalar[T dtype.GoDataType](val T) *Dense[T] {
+	return &Dense[T]{data: []T{val}}
+}
+
+// FromNested returns an array from a value of type T or from nested Go slices or arrays
+// of T, for example [][]float32{{1, 2}, {3, 4}} or [2][3]int64{...}.
+// Nested slices must be rectangular.
+func FromNested[T dtype.GoDataType](nested any) (*Dense[T], error) {
+	val := reflect.ValueOf(nested)
+	want := reflect.TypeFor[T]()
+	var axisLengths []int
+	for typ, v := val.Type(), val; typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array; typ = typ.Elem() {
+		axisLengths = append(axisLengths, v.Len())
+		if v.Len() > 0 {
+			v = v.Index(0)
+		}
+	}
+	a := Zeros[T](axisLengths...)
+	data := a.data[:0]
+	var fill func(v reflect.Value, axis int) error
+	fill = func(v reflect.Value, axis int) error {
+		if axis == len(axisLengths) {
+			if !v.Type().ConvertibleTo(want) || dtype.FromReflectType(v.Type()) != dtype.Generic[T]() {
+				return fmt.Errorf("cannot store a value of type %s in an array of %s", v.Type(), want)
+			}
+			data = append(data, v.Convert(want).Interface().(T))
+			return nil
+		}
+		if k := v.Kind(); k != reflect.Slice && k != reflect.Array {
+			return fmt.Errorf("value of type %s at axis %d is not a slice or an array", v.Type(), axis)
+		}
+		if v.Len() != axisLengths[axis] {
+			return fmt.Errorf("non-rectangular value: got length %d but want %d at axis %d", v.Len(), axisLengths[axis], axis)
+		}
+		for i := range v.Len() {
+			if err := fill(v.Index(i), axis+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := fill(val, 0); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Shape returns the axis lengths of the array.
+func (a *Dense[T]) Shape() []int {
+	return a.axisLengths
+}
+
+// Flat returns the elements of the array in row-major order.
+// The returned slice shares its memory with the array.
+func (a *Dense[T]) Flat() []T {
+	return a.data
+}
+
+// Rank returns the number of axes of the array.
+func (a *Dense[T]) Rank() int {
+	return len(a.axisLengths)
+}
+
+// DType returns the data type of the elements of the array.
+func (a *Dense[T]) DType() dtype.DataType {
+	return dtype.Generic[T]()
+}
+
+// FullShape returns the shape of the array including its data type.
+func (a *Dense[T]) FullShape() *shape.Shape {
+	return &shape.Shape{DType: a.DType(), AxisLengths: slices.Clone(a.axisLengths)}
+}
+
+func (a *Dense[T]) offset(indices []int) int {
+	return shape.NewIndexer(&shape.Shape{AxisLengths: a.axisLengths}).Offset(indices...)
+}
+
+// At returns the element at the given indices.
+// It panics if the indices are out of bounds.
+func (a *Dense[T]) At(indices ...int) T {
+	return a.data[a.offset(indices)]
+}
+
+// Set sets the element at the given indices.
+// It panics if the indices are out of bounds.
+func (a *Dense[T]) Set(val T, indices ...int) {
+	a.data[a.offset(indices)] = val
+}
+
+// Reshape returns an array sharing the same data with different axis lengths.
+func (a *Dense[T]) Reshape(axisLengths ...int) (*Dense[T], error) {
+	return New(a.data, axisLengths...)
+}
+
+// Clone returns a copy of the array.
+func (a *Dense[T]) Clone() *Dense[T] {
+	return &Dense[T]{axisLengths: slices.Clone(a.axisLengths), data: slices.Clone(a.data)}
+}
+
+// All returns an iterator over the indices and values of all the elements in row-major order.
+// The indices slice is reused between iterations.
+func (a *Dense[T]) All() iter.Seq2[[]int, T] {
+	return func(yield func([]int, T) bool) {
+		indices := make([]int, len(a.axisLengths))
+		for _, val := range a.data {
+			if !yield(indices, val) {
+				return
+			}
+			for axis := len(indices) - 1; axis >= 0; axis-- {
+				indices[axis]++
+				if indices[axis] < a.axisLengths[axis] {
+					break
+				}
+				indices[axis] = 0
+			}
+		}
+	}
+}
+
+// ToHostBuffer allocates a host buffer and copies the elements of the array into it.
+func (a *Dense[T]) ToHostBuffer(alloc platform.Allocator) (platform.HostBuffer, error) {
+	buf, err := alloc.Allocate(a.FullShape())
+	if err != nil {
+		return nil, err
+	}
+	dst := buf.Acquire()
+	src := dtype.FromSlice(a.data)
+	if len(dst) != len(src) {
+		buf.Release()
+		buf.Free()
+		return nil, fmt.Errorf("allocator returned a buffer of %d bytes for %d bytes of data", len(dst), len(src))
+	}
+	copy(dst, src)
+	buf.Release()
+	return buf, nil
+}
+
+// FromHandle reads the content of a handle into a new array.
+func FromHandle[T dtype.GoDataType](handle platform.Handle) (*Dense[T], error) {
+	sh := handle.Shape()
+	if want := dtype.Generic[T](); sh.DType != want {
+		return nil, fmt.Errorf("cannot read an array of %s into an array of %s", sh.DType, want)
+	}
+	if !sh.IsContiguous() || sh.IsBitPacked() {
+		return nil, fmt.Errorf("cannot read an array with a non-default layout")
+	}
+	a := Zeros[T](sh.AxisLengths...)
+	if buf, ok := handle.(platform.HostBuffer); ok {
+		return a, a.copyFrom(buf)
+	}
+	if err := handle.ToHost(a.HostBuffer()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// FromHostBuffer copies the content of a host buffer into a new array.
+func FromHostBuffer[T dtype.GoDataType](buf platform.HostBuffer) (*Dense[T], error) {
+	return FromHandle[T](buf)
+}
+
+func (a *Dense[T]) copyFrom(buf platform.HostBuffer) error {
+	src := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	if src == nil {
+		return fmt.Errorf("cannot read a host buffer which has been freed")
+	}
+	if size := len(a.data) * dtype.Sizeof(dtype.Generic[T]()); len(src) != size {
+		return fmt.Errorf("host buffer of %d bytes does not match array of %d bytes", len(src), size)
+	}
+	dtype.CopyToSlice(a.data, src)
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/array"
+)
+
+func TestFromNested(t *testing.T) {
+	a, err := array.FromNested[float32]([][]float32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Shape(), []int{2, 3}; !slices.Equal(got, want) {
+		t.Errorf("got shape %v but want %v", got, want)
+	}
+	if got := a.At(1, 0); got != 4 {
+		t.Errorf("got %v but want 4", got)
+	}
+	if _, err := array.FromNested[float32]([][]float32{{1, 2}, {3}}); err == nil {
+		t.Errorf("expected an error for a non-rectangular value")
+	}
+	if _, err := array.FromNested[float32]([]int32{1}); err == nil {
+		t.Errorf("expected an error for a value of the wrong type")
+	}
+	fixed, err := array.FromNested[int64]([2][2]int64{{1, 2}, {3, 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fixed.At(0, 1); got != 2 {
+		t.Errorf("got %v but want 2", got)
+	}
+}
+
+func TestReshapeAndIterate(t *testing.T) {
+	a, err := array.New([]int32{0, 1, 2, 3, 4, 5}, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := a.Reshape(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indices [][]int
+	for idx, val := range b.All() {
+		if got := b.At(idx...); got != val {
+			t.Errorf("At(%v) = %v but iterator returned %v", idx, got, val)
+		}
+		indices = append(indices, slices.Clone(idx))
+	}
+	if want := [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}, {2, 1}}; !slices.EqualFunc(indices, want, slices.Equal) {
+		t.Errorf("got indices %v but want %v", indices, want)
+	}
+	if _, err := a.Reshape(4); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestHostBuffer(t *testing.T) {
+	a, err := array.New([]float64{1, 2, 3}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := array.FromHostBuffer[float64](a.HostBuffer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.Flat(), b.Flat()) {
+		t.Errorf("got %v but want %v", b.Flat(), a.Flat())
+	}
+}
+
+func TestSubViews(t *testing.T) {
+	a, err := array.FromNested[int32]([][]int32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]int32
+	for _, row := range a.Rows() {
+		rows = append(rows, row.Flat())
+	}
+	if want := [][]int32{{1, 2, 3}, {4, 5, 6}}; !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("got rows %v but want %v", rows, want)
+	}
+	var cols [][]int32
+	for _, col := range a.SubViews(1) {
+		cols = append(cols, col.Flat())
+	}
+	if want := [][]int32{{1, 4}, {2, 5}, {3, 6}}; !slices.EqualFunc(cols, want, slices.Equal) {
+		t.Errorf("got columns %v but want %v", cols, want)
+	}
+	a.Index(1).Set(42, 0)
+	if got := a.At(1, 0); got != 42 {
+		t.Errorf("Index does not share memory with the array: got %v", got)
+	}
+}
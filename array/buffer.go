@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// hostBuffer exposes the memory of a dense array as a platform host buffer.
+type hostBuffer[T dtype.GoDataType] struct {
+	mu    sync.RWMutex
+	shape *shape.Shape
+	array *Dense[T]
+	// acquired is the copy of the array returned by Acquire when dtype.ZeroCopy is false.
+	acquired []byte
+}
+
+// HostBuffer returns a host buffer sharing its memory with the array.
+func (a *Dense[T]) HostBuffer() platform.HostBuffer {
+	return &hostBuffer[T]{shape: a.FullShape(), array: a}
+}
+
+func (b *hostBuffer[T]) Shape() *shape.Shape {
+	return b.shape
+}
+
+func (b *hostBuffer[T]) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	data := b.AcquireRead()
+	defer b.ReleaseRead()
+	return dev.Send(data, b.Shape())
+}
+
+func (b *hostBuffer[T]) ToHost(dst platform.HostBuffer) error {
+	return platform.HostTransfer(dst, b)
+}
+
+func (b *hostBuffer[T]) Acquire() []byte {
+	b.mu.Lock()
+	if b.array == nil {
+		return nil
+	}
+	data := dtype.FromSlice(b.array.data)
+	if !dtype.ZeroCopy {
+		b.acquired = data
+	}
+	return data
+}
+
+func (b *hostBuffer[T]) Release() {
+	if b.acquired != nil {
+		dtype.CopyToSlice(b.array.data, b.acquired)
+		b.acquired = nil
+	}
+	b.mu.Unlock()
+}
+
+func (b *hostBuffer[T]) AcquireRead() []byte {
+	b.mu.RLock()
+	if b.array == nil {
+		return nil
+	}
+	return dtype.FromSlice(b.array.data)
+}
+
+func (b *hostBuffer[T]) ReleaseRead() {
+	b.mu.RUnlock()
+}
+
+func (b *hostBuffer[T]) Free() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.array = nil
+}
+
+func (b *hostBuffer[T]) String() string {
+	return fmt.Sprintf("HostBuffer(%s)", b.shape)
+}
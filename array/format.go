@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Printer formats the content of arrays in the style of NumPy.
+// Fields which are not positive take their value from DefaultPrinter.
+type Printer struct {
+	// Threshold is the number of elements above which arrays are truncated.
+	Threshold int
+	// EdgeItems is the number of elements printed at the beginning and at the end
+	// of each axis when an array is truncated.
+	EdgeItems int
+}
+
+// DefaultPrinter uses the same defaults as NumPy.
+var DefaultPrinter = Printer{Threshold: 1000, EdgeItems: 3}
+
+// Sprint formats an array using the default printer.
+func Sprint[T dtype.GoDataType](a shape.ArrayI[T]) string {
+	return Format(DefaultPrinter, a)
+}
+
+// Format formats an array using a printer.
+func Format[T dtype.GoDataType](p Printer, a shape.ArrayI[T]) string {
+	if p.Threshold <= 0 {
+		p.Threshold = DefaultPrinter.Threshold
+	}
+	if p.EdgeItems <= 0 {
+		p.EdgeItems = DefaultPrinter.EdgeItems
+	}
+	axisLengths := a.Shape()
+	flat := a.Flat()
+	truncate := len(flat) > p.Threshold
+	var b strings.Builder
+	var format func(axis, offset int)
+	format = func(axis, offset int) {
+		if axis == len(axisLengths) {
+			fmt.Fprint(&b, flat[offset])
+			return
+		}
+		b.WriteByte('[')
+		length := axisLengths[axis]
+		stride := shape.Size(axisLengths[axis+1:])
+		for i := 0; i < length; i++ {
+			if truncate && length > 2*p.EdgeItems && i == p.EdgeItems {
+				b.WriteString("...")
+				i = length - p.EdgeItems
+				writeSeparator(&b, axis, len(axisLengths))
+			}
+			format(axis+1, offset+i*stride)
+			if i < length-1 {
+				writeSeparator(&b, axis, len(axisLengths))
+			}
+		}
+		b.WriteByte(']')
+	}
+	format(0, 0)
+	return b.String()
+}
+
+func writeSeparator(b *strings.Builder, axis, rank int) {
+	if axis == rank-1 {
+		b.WriteByte(' ')
+		return
+	}
+	b.WriteString(strings.Repeat("\n", rank-1-axis))
+	b.WriteString(strings.Repeat(" ", axis+1))
+}
+
+// SprintHostBuffer formats the content of a host buffer using the default printer.
+func SprintHostBuffer(buf platform.HostBuffer) (string, error) {
+	switch buf.Shape().DType {
+	case dtype.Bool:
+		return sprintHostBuffer[bool](buf)
+	case dtype.Int8:
+		return sprintHostBuffer[int8](buf)
+	case dtype.Int16:
+		return sprintHostBuffer[int16](buf)
+	case dtype.Int32:
+		return sprintHostBuffer[int32](buf)
+	case dtype.Int64:
+		return sprintHostBuffer[int64](buf)
+	case dtype.Uint8:
+		return sprintHostBuffer[uint8](buf)
+	case dtype.Uint16:
+		return sprintHostBuffer[uint16](buf)
+	case dtype.Uint32:
+		return sprintHostBuffer[uint32](buf)
+	case dtype.Uint64:
+		return sprintHostBuffer[uint64](buf)
+	case dtype.Bfloat16:
+		return sprintHostBuffer[dtype.Bfloat16T](buf)
+	case dtype.Float16:
+		return sprintHostBuffer[dtype.Float16T](buf)
+	case dtype.Float32:
+		return sprintHostBuffer[float32](buf)
+	case dtype.Float64:
+		return sprintHostBuffer[float64](buf)
+	case dtype.Complex64:
+		return sprintHostBuffer[complex64](buf)
+	case dtype.Complex128:
+		return sprintHostBuffer[complex128](buf)
+	}
+	return "", fmt.Errorf("cannot format a host buffer of %s", buf.Shape().DType)
+}
+
+func sprintHostBuffer[T dtype.GoDataType](buf platform.HostBuffer) (string, error) {
+	a, err := FromHostBuffer[T](buf)
+	if err != nil {
+		return "", err
+	}
+	return Sprint[T](a), nil
+}
+
+// String returns the content of the array formatted by the default printer.
+func (a *Dense[T]) String() string {
+	return Sprint[T](a)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/array"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		nested  any
+		printer array.Printer
+		want    string
+	}{
+		{
+			nested:  float32(2.5),
+			printer: array.DefaultPrinter,
+			want:    "2.5",
+		},
+		{
+			nested:  []float32{1, 2, 3},
+			printer: array.DefaultPrinter,
+			want:    "[1 2 3]",
+		},
+		{
+			nested:  [][]float32{{1, 2}, {3, 4}},
+			printer: array.DefaultPrinter,
+			want:    "[[1 2]\n [3 4]]",
+		},
+		{
+			nested:  [][][]float32{{{1}, {2}}, {{3}, {4}}},
+			printer: array.DefaultPrinter,
+			want:    "[[[1]\n  [2]]\n\n [[3]\n  [4]]]",
+		},
+		{
+			nested:  []float32{0, 1, 2, 3, 4, 5, 6},
+			printer: array.Printer{Threshold: 5, EdgeItems: 2},
+			want:    "[0 1 ... 5 6]",
+		},
+		{
+			nested:  []float32{0, 1, 2, 3, 4, 5, 6},
+			printer: array.Printer{},
+			want:    "[0 1 2 3 4 5 6]",
+		},
+		{
+			nested:  make([]float32, 1001),
+			printer: array.Printer{},
+			want:    "[0 0 0 ... 0 0 0]",
+		},
+	}
+	for i, test := range tests {
+		a, err := array.FromNested[float32](test.nested)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := array.Format[float32](test.printer, a); got != test.want {
+			t.Errorf("test %d: got\n%s\nbut want\n%s", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package array
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// Index returns the element at index i along the outermost axis.
+// The returned array shares its memory with a.
+// It panics if the array is atomic or if the index is out of bounds.
+func (a *Dense[T]) Index(i int) *Dense[T] {
+	if a.Rank() == 0 {
+		panic("cannot index an atomic array")
+	}
+	if i < 0 || i >= a.axisLengths[0] {
+		panic(fmt.Sprintf("index %d out of range [0, %d)", i, a.axisLengths[0]))
+	}
+	stride := shape.Size(a.axisLengths[1:])
+	return &Dense[T]{
+		axisLengths: slices.Clone(a.axisLengths[1:]),
+		data:        a.data[i*stride : (i+1)*stride : (i+1)*stride],
+	}
+}
+
+// Rows returns an iterator over the elements along the outermost axis.
+// The arrays returned by the iterator share their memory with a.
+func (a *Dense[T]) Rows() iter.Seq2[int, *Dense[T]] {
+	return func(yield func(int, *Dense[T]) bool) {
+		if a.Rank() == 0 {
+			return
+		}
+		for i := range a.axisLengths[0] {
+			if !yield(i, a.Index(i)) {
+				return
+			}
+		}
+	}
+}
+
+// SubViews returns an iterator over the sub-arrays obtained by fixing the index of a given axis.
+// For the outermost axis, the sub-arrays share their memory with a.
+// For other axes, the elements are not contiguous in memory and the sub-arrays are copies.
+// It panics if the axis is out of range.
+func (a *Dense[T]) SubViews(axis int) iter.Seq2[int, *Dense[T]] {
+	if axis < 0 || axis >= a.Rank() {
+		panic(fmt.Sprintf("axis %d out of range for rank %d", axis, a.Rank()))
+	}
+	if axis == 0 {
+		return a.Rows()
+	}
+	return func(yield func(int, *Dense[T]) bool) {
+		outer := shape.Size(a.axisLengths[:axis])
+		inner := shape.Size(a.axisLengths[axis+1:])
+		length := a.axisLengths[axis]
+		subLengths := slices.Delete(slices.Clone(a.axisLengths), axis, axis+1)
+		for i := range length {
+			sub := Zeros[T](subLengths...)
+			for o := range outer {
+				src := a.data[(o*length+i)*inner:]
+				copy(sub.data[o*inner:(o+1)*inner], src[:inner])
+			}
+			if !yield(i, sub) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package artifact stores ahead-of-time compiled executables with the shapes of
+// their parameters and outputs in a FlatBuffers container defined by artifact.fbs.
+//
+// A container is loaded without being parsed: Open only verifies that all the
+// objects of the buffer are within bounds and the executable is returned
+// without being copied.
+package artifact
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
+
+// Identifier is the FlatBuffers file identifier of artifacts.
+const Identifier = "GXAF"
+
+// Artifact is an ahead-of-time compiled executable.
+type Artifact struct {
+	// Platform is the name of the platform for which the executable has been compiled.
+	Platform string
+	// Name of the compiled graph.
+	Name string
+	// Executable in the format of the platform.
+	Executable []byte
+	// Params are the shapes of the parameters of the executable.
+	Params []*shape.Shape
+	// Outputs are the shapes of the outputs of the executable.
+	Outputs []*shape.Shape
+	// Traced are the shapes of the traced values of the executable.
+	Traced []*shape.Shape
+	// Metadata are additional key-value pairs, for example the version of the compiler.
+	Metadata map[string]string
+}
+
+// Field slots of the tables of the schema.
+const (
+	artifactPlatform = iota
+	artifactName
+	artifactExecutable
+	artifactParams
+	artifactOutputs
+	artifactTraced
+	artifactMetadata
+	artifactNumFields
+)
+
+const (
+	shapeDType = iota
+	shapeAxisLengths
+	shapeAxisNames
+	shapeQuant
+	shapeBitPacked
+	shapeLayout
+	shapeNumFields
+)
+
+const (
+	quantStorage = iota
+	quantExpressed
+	quantAxis
+	quantScales
+	quantZeroPoints
+	quantNumFields
+)
+
+const (
+	layoutMinorToMajor = iota
+	layoutStrides
+	layoutTile
+	layoutNumFields
+)
+
+const (
+	metadataKey = iota
+	metadataValue
+	metadataNumFields
+)
+
+var (
+	layoutSchema = []fieldSpec{
+		layoutMinorToMajor: {kind: kindInt64s},
+		layoutStrides:      {kind: kindInt64s},
+		layoutTile:         {kind: kindInt64s},
+	}
+	quantSchema = []fieldSpec{
+		quantStorage:    {kind: kindUint32},
+		quantExpressed:  {kind: kindUint32},
+		quantAxis:       {kind: kindInt64},
+		quantScales:     {kind: kindDoubles},
+		quantZeroPoints: {kind: kindInt64s},
+	}
+	shapeSchema = []fieldSpec{
+		shapeDType:       {kind: kindUint32},
+		shapeAxisLengths: {kind: kindInt64s},
+		shapeAxisNames:   {kind: kindStrings},
+		shapeQuant:       {kind: kindTable, schema: quantSchema},
+		shapeBitPacked:   {kind: kindBool},
+		shapeLayout:      {kind: kindTable, schema: layoutSchema},
+	}
+	metadataSchema = []fieldSpec{
+		metadataKey:   {kind: kindString},
+		metadataValue: {kind: kindString},
+	}
+	artifactSchema = []fieldSpec{
+		artifactPlatform:   {kind: kindString},
+		artifactName:       {kind: kindString},
+		artifactExecutable: {kind: kindBytes},
+		artifactParams:     {kind: kindTables, schema: shapeSchema},
+		artifactOutputs:    {kind: kindTables, schema: shapeSchema},
+		artifactTraced:     {kind: kindTables, schema: shapeSchema},
+		artifactMetadata:   {kind: kindTables, schema: metadataSchema},
+	}
+)
+
+// Marshal encodes the artifact in a FlatBuffers container.
+func (a *Artifact) Marshal() []byte {
+	b := newBuilder(len(a.Executable) + 1024)
+	exec := b.createBytes(a.Executable, false)
+	params := writeShapes(b, a.Params)
+	outputs := writeShapes(b, a.Outputs)
+	traced := writeShapes(b, a.Traced)
+	metadata := writeMetadata(b, a.Metadata)
+	platform := b.createString(a.Platform)
+	name := b.createString(a.Name)
+
+	b.startTable(artifactNumFields)
+	b.addOffset(artifactPlatform, platform)
+	b.addOffset(artifactName, name)
+	b.addOffset(artifactExecutable, exec)
+	b.addOffset(artifactParams, params)
+	b.addOffset(artifactOutputs, outputs)
+	b.addOffset(artifactTraced, traced)
+	b.addOffset(artifactMetadata, metadata)
+	return b.finish(b.endTable(), Identifier)
+}
+
+func writeShapes(b *builder, shapes []*shape.Shape) int {
+	offs := make([]int, len(shapes))
+	for i, sh := range shapes {
+		offs[i] = writeShape(b, sh)
+	}
+	return b.createOffsets(offs)
+}
+
+func writeShape(b *builder, sh *shape.Shape) int {
+	axisLengths := b.createInt64s(sh.AxisLengths)
+	var axisNames, quant, layout int
+	if sh.AxisNames != nil {
+		names := make([]int, len(sh.AxisNames))
+		for i, name := range sh.AxisNames {
+			names[i] = b.createString(name)
+		}
+		axisNames = b.createOffsets(names)
+	}
+	if q := sh.Quant; q != nil {
+		scales := b.createDoubles(q.Scales)
+		zeroPoints := make([]int, len(q.ZeroPoints))
+		for i, zp := range q.ZeroPoints {
+			zeroPoints[i] = int(zp)
+		}
+		zps := b.createInt64s(zeroPoints)
+		b.startTable(quantNumFields)
+		b.addInt64(quantAxis, int64(q.Axis))
+		b.addUint32(quantStorage, uint32(q.Storage))
+		b.addUint32(quantExpressed, uint32(q.Expressed))
+		b.addOffset(quantScales, scales)
+		b.addOffset(quantZeroPoints, zps)
+		quant = b.endTable()
+	}
+	if l := sh.Layout; l != nil {
+		minorToMajor := b.createInt64s(l.MinorToMajor)
+		var strides, tile int
+		if l.Strides != nil {
+			strides = b.createInt64s(l.Strides)
+		}
+		if l.Tile != nil {
+			tile = b.createInt64s(l.Tile)
+		}
+		b.startTable(layoutNumFields)
+		b.addOffset(layoutMinorToMajor, minorToMajor)
+		b.addOffset(layoutStrides, strides)
+		b.addOffset(layoutTile, tile)
+		layout = b.endTable()
+	}
+	b.startTable(shapeNumFields)
+	b.addUint32(shapeDType, uint32(sh.DType))
+	b.addOffset(shapeAxisLengths, axisLengths)
+	b.addOffset(shapeAxisNames, axisNames)
+	b.addOffset(shapeQuant, quant)
+	b.addOffset(shapeLayout, layout)
+	b.addBool(shapeBitPacked, sh.BitPacked)
+	return b.endTable()
+}
+
+func writeMetadata(b *builder, metadata map[string]string) int {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	offs := make([]int, len(keys))
+	for i, key := range keys {
+		k, v := b.createString(key), b.createString(metadata[key])
+		b.startTable(metadataNumFields)
+		b.addOffset(metadataKey, k)
+		b.addOffset(metadataValue, v)
+		offs[i] = b.endTable()
+	}
+	return b.createOffsets(offs)
+}
+
+// View reads an artifact directly from a FlatBuffers container.
+type View struct {
+	root                    table
+	params, outputs, traced []*shape.Shape
+}
+
+// Open verifies a FlatBuffers container and returns a view of its artifact.
+// The buffer must not be modified while the view is used.
+func Open(buf []byte) (*View, error) {
+	if len(buf) < 8 || string(buf[4:8]) != Identifier {
+		return nil, fmt.Errorf("invalid artifact: missing file identifier %q", Identifier)
+	}
+	v := verifier{buf: buf}
+	root, err := v.deref(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact: %v", err)
+	}
+	if err := v.table(root, artifactSchema); err != nil {
+		return nil, fmt.Errorf("invalid artifact: %v", err)
+	}
+	view := &View{root: table{buf: buf, pos: root}}
+	if view.params, err = readShapes(view.root, artifactParams); err != nil {
+		return nil, fmt.Errorf("invalid artifact parameters: %v", err)
+	}
+	if view.outputs, err = readShapes(view.root, artifactOutputs); err != nil {
+		return nil, fmt.Errorf("invalid artifact outputs: %v", err)
+	}
+	if view.traced, err = readShapes(view.root, artifactTraced); err != nil {
+		return nil, fmt.Errorf("invalid artifact traced values: %v", err)
+	}
+	return view, nil
+}
+
+func readShapes(t table, slot int) ([]*shape.Shape, error) {
+	tables := t.tables(slot)
+	shapes := make([]*shape.Shape, len(tables))
+	for i, st := range tables {
+		sh := readShape(st)
+		if err := sh.Check(); err != nil {
+			return nil, err
+		}
+		shapes[i] = sh
+	}
+	return shapes, nil
+}
+
+func readShape(t table) *shape.Shape {
+	sh := &shape.Shape{
+		DType:       dtype.DataType(t.uint32(shapeDType)),
+		AxisLengths: t.int64s(shapeAxisLengths),
+		AxisNames:   t.strings(shapeAxisNames),
+		BitPacked:   t.bool(shapeBitPacked),
+	}
+	if qt, ok := t.table(shapeQuant); ok {
+		sh.Quant = &dtype.Quantization{
+			Storage:   dtype.DataType(qt.uint32(quantStorage)),
+			Expressed: dtype.DataType(qt.uint32(quantExpressed)),
+			Axis:      int(qt.int64(quantAxis)),
+			Scales:    qt.doubles(quantScales),
+		}
+		for _, zp := range qt.int64s(quantZeroPoints) {
+			sh.Quant.ZeroPoints = append(sh.Quant.ZeroPoints, int64(zp))
+		}
+	}
+	if lt, ok := t.table(shapeLayout); ok {
+		sh.Layout = &shape.Layout{
+			MinorToMajor: lt.int64s(layoutMinorToMajor),
+			Strides:      lt.int64s(layoutStrides),
+			Tile:         lt.int64s(layoutTile),
+		}
+	}
+	return sh
+}
+
+// Platform returns the name of the platform for which the executable has been compiled.
+func (v *View) Platform() string {
+	return v.root.string(artifactPlatform)
+}
+
+// Name returns the name of the compiled graph.
+func (v *View) Name() string {
+	return v.root.string(artifactName)
+}
+
+// Executable returns the executable. The returned slice shares the memory of the container.
+func (v *View) Executable() []byte {
+	return v.root.bytes(artifactExecutable)
+}
+
+// Params returns the shapes of the parameters of the executable.
+func (v *View) Params() []*shape.Shape {
+	return v.params
+}
+
+// Outputs returns the shapes of the outputs of the executable.
+func (v *View) Outputs() []*shape.Shape {
+	return v.outputs
+}
+
+// Traced returns the shapes of the traced values of the executable.
+func (v *View) Traced() []*shape.Shape {
+	return v.traced
+}
+
+// Metadata returns the value of a metadata key.
+func (v *View) Metadata(key string) (string, bool) {
+	entries := v.root.tables(artifactMetadata)
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].string(metadataKey) >= key
+	})
+	if i == len(entries) || entries[i].string(metadataKey) != key {
+		return "", false
+	}
+	return entries[i].string(metadataValue), true
+}
+
+// Artifact returns the artifact stored in the container.
+// The executable of the artifact shares the memory of the container.
+func (v *View) Artifact() *Artifact {
+	a := &Artifact{
+		Platform:   v.Platform(),
+		Name:       v.Name(),
+		Executable: v.Executable(),
+		Params:     v.params,
+		Outputs:    v.outputs,
+		Traced:     v.traced,
+	}
+	if entries := v.root.tables(artifactMetadata); len(entries) > 0 {
+		a.Metadata = make(map[string]string, len(entries))
+		for _, entry := range entries {
+			a.Metadata[entry.string(metadataKey)] = entry.string(metadataValue)
+		}
+	}
+	return a
+}
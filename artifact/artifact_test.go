@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/gx-org/backend/artifact"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
+
+func newArtifact() *artifact.Artifact {
+	return &artifact.Artifact{
+		Platform:   "cpu",
+		Name:       "main",
+		Executable: []byte("executable"),
+		Params: []*shape.Shape{
+			{DType: dtype.Float32, AxisLengths: []int{2, 3}, AxisNames: []string{"batch", "features"}},
+			{DType: dtype.Float32, AxisLengths: []int{3, 2}, Layout: &shape.Layout{MinorToMajor: []int{0, 1}}},
+		},
+		Outputs: []*shape.Shape{{
+			DType:       dtype.Int32,
+			AxisLengths: []int{2},
+			Quant: &dtype.Quantization{
+				Storage:    dtype.Int32,
+				Expressed:  dtype.Float32,
+				Axis:       dtype.PerTensor,
+				Scales:     []float64{0.5},
+				ZeroPoints: []int64{-3},
+			},
+		}},
+		Traced:   []*shape.Shape{shape.Scalar(dtype.Bool)},
+		Metadata: map[string]string{"compiler": "v1", "date": "today", "a": ""},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := newArtifact()
+	buf := want.Marshal()
+	if len(buf)%8 != 0 {
+		t.Errorf("container of %d bytes is not aligned", len(buf))
+	}
+	view, err := artifact.Open(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Platform() != want.Platform || view.Name() != want.Name {
+		t.Errorf("got platform %q and name %q but want %q and %q", view.Platform(), view.Name(), want.Platform, want.Name)
+	}
+	exec := view.Executable()
+	if !bytes.Equal(exec, want.Executable) {
+		t.Errorf("got executable %q but want %q", exec, want.Executable)
+	}
+	if start := uintptr(unsafe.Pointer(&exec[0])); start < uintptr(unsafe.Pointer(&buf[0])) || start >= uintptr(unsafe.Pointer(&buf[len(buf)-1])) {
+		t.Errorf("executable has been copied out of the container")
+	}
+	for key, value := range want.Metadata {
+		if got, ok := view.Metadata(key); !ok || got != value {
+			t.Errorf("metadata %q: got %q, %v but want %q", key, got, ok, value)
+		}
+	}
+	if _, ok := view.Metadata("missing"); ok {
+		t.Errorf("got a value for a missing metadata key")
+	}
+	got := view.Artifact()
+	for i, sh := range got.Params {
+		if !sh.Equal(want.Params[i]) || !reflect.DeepEqual(sh.AxisNames, want.Params[i].AxisNames) {
+			t.Errorf("parameter %d: got shape %s but want %s", i, sh, want.Params[i])
+		}
+	}
+	if len(got.Outputs) != 1 || !got.Outputs[0].Equal(want.Outputs[0]) {
+		t.Errorf("got outputs %v but want %v", got.Outputs, want.Outputs)
+	}
+	if len(got.Traced) != 1 || !got.Traced[0].Equal(want.Traced[0]) {
+		t.Errorf("got traced %v but want %v", got.Traced, want.Traced)
+	}
+	if !reflect.DeepEqual(got.Metadata, want.Metadata) {
+		t.Errorf("got metadata %v but want %v", got.Metadata, want.Metadata)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	view, err := artifact.Open((&artifact.Artifact{}).Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := view.Artifact()
+	if got.Platform != "" || len(got.Executable) != 0 || len(got.Params) != 0 || len(got.Outputs) != 0 || len(got.Metadata) != 0 {
+		t.Errorf("got artifact %+v but want an empty artifact", got)
+	}
+}
+
+func TestCorrupted(t *testing.T) {
+	buf := newArtifact().Marshal()
+	if _, err := artifact.Open(buf[:len(buf)/2]); err == nil {
+		t.Errorf("truncated container opened without error")
+	}
+	if _, err := artifact.Open([]byte("not an artifact")); err == nil {
+		t.Errorf("invalid container opened without error")
+	}
+	// Corrupting any byte must not make Open or the accessors panic.
+	for i := range buf {
+		corrupted := bytes.Clone(buf)
+		corrupted[i] ^= 0xff
+		view, err := artifact.Open(corrupted)
+		if err != nil {
+			continue
+		}
+		view.Artifact()
+	}
+}
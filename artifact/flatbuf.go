@@ -0,0 +1,457 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// minAlign is the alignment of the size of a finished buffer.
+const minAlign = 8
+
+// builder builds a FlatBuffers buffer from back to front.
+// Objects are identified by their distance from the end of the buffer.
+type builder struct {
+	buf        []byte
+	head       int
+	vtable     []int
+	tableStart int
+}
+
+func newBuilder(size int) *builder {
+	return &builder{buf: make([]byte, size), head: size}
+}
+
+// offset returns the number of bytes written, which identifies the last written object.
+func (b *builder) offset() int {
+	return len(b.buf) - b.head
+}
+
+func (b *builder) grow(n int) {
+	for b.head < n {
+		size := max(2*len(b.buf), 64)
+		buf := make([]byte, size)
+		copy(buf[size-b.offset():], b.buf[b.head:])
+		b.head += size - len(b.buf)
+		b.buf = buf
+	}
+}
+
+// place reserves n bytes in front of the buffer.
+func (b *builder) place(n int) []byte {
+	b.grow(n)
+	b.head -= n
+	return b.buf[b.head : b.head+n]
+}
+
+// prep pads the buffer such that a value of the given size is aligned
+// once additional bytes have been written.
+func (b *builder) prep(size, additional int) {
+	pad := -(b.offset() + additional) & (size - 1)
+	clear(b.place(pad))
+}
+
+func (b *builder) putUint32(v uint32) {
+	b.prep(4, 0)
+	binary.LittleEndian.PutUint32(b.place(4), v)
+}
+
+// putOffset writes a reference to an object written earlier.
+func (b *builder) putOffset(off int) {
+	b.prep(4, 0)
+	b.putUint32(uint32(b.offset() + 4 - off))
+}
+
+// createBytes writes a vector of bytes, terminated by a zero for strings.
+func (b *builder) createBytes(data []byte, zeroTerminated bool) int {
+	extra := 0
+	if zeroTerminated {
+		extra = 1
+	}
+	b.prep(4, len(data)+extra)
+	clear(b.place(extra))
+	copy(b.place(len(data)), data)
+	b.putUint32(uint32(len(data)))
+	return b.offset()
+}
+
+func (b *builder) createString(s string) int {
+	return b.createBytes([]byte(s), true)
+}
+
+func (b *builder) createInt64s(vals []int) int {
+	b.prep(8, 8*len(vals))
+	for i := len(vals) - 1; i >= 0; i-- {
+		binary.LittleEndian.PutUint64(b.place(8), uint64(int64(vals[i])))
+	}
+	b.putUint32(uint32(len(vals)))
+	return b.offset()
+}
+
+func (b *builder) createDoubles(vals []float64) int {
+	b.prep(8, 8*len(vals))
+	for i := len(vals) - 1; i >= 0; i-- {
+		binary.LittleEndian.PutUint64(b.place(8), math.Float64bits(vals[i]))
+	}
+	b.putUint32(uint32(len(vals)))
+	return b.offset()
+}
+
+// createOffsets writes a vector of references to objects written earlier.
+func (b *builder) createOffsets(offs []int) int {
+	b.prep(4, 4*len(offs))
+	for i := len(offs) - 1; i >= 0; i-- {
+		b.putOffset(offs[i])
+	}
+	b.putUint32(uint32(len(offs)))
+	return b.offset()
+}
+
+func (b *builder) startTable(numFields int) {
+	b.vtable = make([]int, numFields)
+	b.tableStart = b.offset()
+}
+
+func (b *builder) addUint32(slot int, v uint32) {
+	if v == 0 {
+		return
+	}
+	b.putUint32(v)
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addInt64(slot int, v int64) {
+	if v == 0 {
+		return
+	}
+	b.prep(8, 0)
+	binary.LittleEndian.PutUint64(b.place(8), uint64(v))
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addBool(slot int, v bool) {
+	if !v {
+		return
+	}
+	b.place(1)[0] = 1
+	b.vtable[slot] = b.offset()
+}
+
+// addOffset adds a reference to an object written earlier. Objects at offset 0 are ignored.
+func (b *builder) addOffset(slot int, off int) {
+	if off == 0 {
+		return
+	}
+	b.putOffset(off)
+	b.vtable[slot] = b.offset()
+}
+
+// endTable writes the table and its vtable.
+func (b *builder) endTable() int {
+	b.putUint32(0)
+	table := b.offset()
+	n := len(b.vtable)
+	for n > 0 && b.vtable[n-1] == 0 {
+		n--
+	}
+	for i := n - 1; i >= 0; i-- {
+		var off uint16
+		if b.vtable[i] != 0 {
+			off = uint16(table - b.vtable[i])
+		}
+		binary.LittleEndian.PutUint16(b.place(2), off)
+	}
+	binary.LittleEndian.PutUint16(b.place(2), uint16(table-b.tableStart))
+	binary.LittleEndian.PutUint16(b.place(2), uint16(4+2*n))
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-table:], uint32(int32(b.offset()-table)))
+	b.vtable = nil
+	return table
+}
+
+// finish writes the reference to the root table and the file identifier.
+func (b *builder) finish(root int, ident string) []byte {
+	b.prep(minAlign, 8)
+	copy(b.place(4), ident)
+	b.putOffset(root)
+	return b.buf[b.head:]
+}
+
+// table reads a table of a verified buffer.
+type table struct {
+	buf []byte
+	pos int
+}
+
+func (t table) u16(p int) int {
+	return int(binary.LittleEndian.Uint16(t.buf[p:]))
+}
+
+func (t table) u32(p int) int {
+	return int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t table) vtable() int {
+	return t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+}
+
+// field returns the position of a field in the buffer or 0 if the field is absent.
+func (t table) field(slot int) int {
+	vt := t.vtable()
+	entry := 4 + 2*slot
+	if entry+2 > t.u16(vt) {
+		return 0
+	}
+	off := t.u16(vt + entry)
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t table) uint32(slot int) uint32 {
+	if p := t.field(slot); p != 0 {
+		return binary.LittleEndian.Uint32(t.buf[p:])
+	}
+	return 0
+}
+
+func (t table) int64(slot int) int64 {
+	if p := t.field(slot); p != 0 {
+		return int64(binary.LittleEndian.Uint64(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t table) bool(slot int) bool {
+	if p := t.field(slot); p != 0 {
+		return t.buf[p] != 0
+	}
+	return false
+}
+
+// deref returns the position of the object referenced by a field or 0 if the field is absent.
+func (t table) deref(slot int) int {
+	if p := t.field(slot); p != 0 {
+		return p + t.u32(p)
+	}
+	return 0
+}
+
+// vector returns the position of the first element of a vector and its length.
+func (t table) vector(slot int) (int, int) {
+	p := t.deref(slot)
+	if p == 0 {
+		return 0, 0
+	}
+	return p + 4, t.u32(p)
+}
+
+// bytes returns a vector of bytes without copying the buffer.
+func (t table) bytes(slot int) []byte {
+	p, n := t.vector(slot)
+	if p == 0 {
+		return nil
+	}
+	return t.buf[p : p+n : p+n]
+}
+
+func (t table) string(slot int) string {
+	return string(t.bytes(slot))
+}
+
+func (t table) int64s(slot int) []int {
+	p, n := t.vector(slot)
+	if n == 0 {
+		return nil
+	}
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = int(int64(binary.LittleEndian.Uint64(t.buf[p+8*i:])))
+	}
+	return vals
+}
+
+func (t table) doubles(slot int) []float64 {
+	p, n := t.vector(slot)
+	if n == 0 {
+		return nil
+	}
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = math.Float64frombits(binary.LittleEndian.Uint64(t.buf[p+8*i:]))
+	}
+	return vals
+}
+
+// elem returns the ith element of a vector of references.
+func (t table) elem(p, i int) int {
+	p += 4 * i
+	return p + t.u32(p)
+}
+
+func (t table) strings(slot int) []string {
+	p, n := t.vector(slot)
+	if n == 0 {
+		return nil
+	}
+	strs := make([]string, n)
+	for i := range strs {
+		s := t.elem(p, i)
+		strs[i] = string(t.buf[s+4 : s+4+t.u32(s)])
+	}
+	return strs
+}
+
+func (t table) table(slot int) (table, bool) {
+	p := t.deref(slot)
+	return table{buf: t.buf, pos: p}, p != 0
+}
+
+func (t table) tables(slot int) []table {
+	p, n := t.vector(slot)
+	tables := make([]table, n)
+	for i := range tables {
+		tables[i] = table{buf: t.buf, pos: t.elem(p, i)}
+	}
+	return tables
+}
+
+// kind is the type of a field of a table.
+type kind int
+
+const (
+	kindBool kind = iota
+	kindUint32
+	kindInt64
+	kindString
+	kindBytes
+	kindInt64s
+	kindDoubles
+	kindStrings
+	kindTable
+	kindTables
+)
+
+// fieldSpec describes a field of a table to verify a buffer.
+type fieldSpec struct {
+	kind   kind
+	schema []fieldSpec
+}
+
+// verifier checks that all the objects of a buffer are within bounds,
+// such that tables can then be read without checks.
+type verifier struct {
+	buf []byte
+}
+
+func (v verifier) in(p, n int) bool {
+	return p >= 0 && n >= 0 && p <= len(v.buf) && n <= len(v.buf)-p
+}
+
+func (v verifier) deref(p int) (int, error) {
+	if !v.in(p, 4) {
+		return 0, fmt.Errorf("reference at %d out of bounds", p)
+	}
+	target := p + int(binary.LittleEndian.Uint32(v.buf[p:]))
+	if !v.in(target, 4) {
+		return 0, fmt.Errorf("object at %d out of bounds", target)
+	}
+	return target, nil
+}
+
+func (v verifier) vector(p, elemSize, extra int) (int, error) {
+	n := int(binary.LittleEndian.Uint32(v.buf[p:]))
+	if !v.in(p+4, n*elemSize+extra) {
+		return 0, fmt.Errorf("vector at %d of %d elements out of bounds", p, n)
+	}
+	return n, nil
+}
+
+func (v verifier) table(pos int, schema []fieldSpec) error {
+	if !v.in(pos, 4) {
+		return fmt.Errorf("table at %d out of bounds", pos)
+	}
+	vt := pos - int(int32(binary.LittleEndian.Uint32(v.buf[pos:])))
+	if !v.in(vt, 4) {
+		return fmt.Errorf("vtable at %d out of bounds", vt)
+	}
+	vsize := int(binary.LittleEndian.Uint16(v.buf[vt:]))
+	tsize := int(binary.LittleEndian.Uint16(v.buf[vt+2:]))
+	if vsize < 4 || vsize%2 != 0 || !v.in(vt, vsize) || !v.in(pos, tsize) {
+		return fmt.Errorf("invalid vtable at %d", vt)
+	}
+	for slot, spec := range schema {
+		entry := 4 + 2*slot
+		if entry+2 > vsize {
+			break
+		}
+		off := int(binary.LittleEndian.Uint16(v.buf[vt+entry:]))
+		if off == 0 {
+			continue
+		}
+		size := 4
+		switch spec.kind {
+		case kindBool:
+			size = 1
+		case kindInt64:
+			size = 8
+		}
+		if off+size > tsize {
+			return fmt.Errorf("field %d of table at %d out of bounds", slot, pos)
+		}
+		if err := v.field(pos+off, spec); err != nil {
+			return fmt.Errorf("field %d of table at %d: %v", slot, pos, err)
+		}
+	}
+	return nil
+}
+
+func (v verifier) field(p int, spec fieldSpec) error {
+	switch spec.kind {
+	case kindBool, kindUint32, kindInt64:
+		return nil
+	}
+	target, err := v.deref(p)
+	if err != nil {
+		return err
+	}
+	switch spec.kind {
+	case kindString:
+		_, err = v.vector(target, 1, 1)
+	case kindBytes:
+		_, err = v.vector(target, 1, 0)
+	case kindInt64s, kindDoubles:
+		_, err = v.vector(target, 8, 0)
+	case kindTable:
+		err = v.table(target, spec.schema)
+	case kindStrings, kindTables:
+		var n int
+		if n, err = v.vector(target, 4, 0); err != nil {
+			return err
+		}
+		elemSpec := fieldSpec{kind: kindString}
+		if spec.kind == kindTables {
+			elemSpec = fieldSpec{kind: kindTable, schema: spec.schema}
+		}
+		for i := range n {
+			if err := v.field(target+4+4*i, elemSpec); err != nil {
+				return fmt.Errorf("element %d: %v", i, err)
+			}
+		}
+	}
+	return err
+}
@@ -25,10 +25,21 @@ type Backend interface {
 	// Platform supporting the backend.
 	Platform() platform.Platform
 
-	// NewOps returns a new ops builder.
-	NewOps(name string) (ops.Graph, error)
+	// NewOps returns a new ops builder configured by options.
+	// An error is returned if the graph cannot be created or if an option is invalid.
+	NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error)
+
+	// Capabilities returns the data types and operations supported by the backend.
+	Capabilities() ops.Capabilities
+
+	// Close releases everything linked to the backend.
+	// Runners executing when Close is called complete before compiled graphs are released.
+	// It is invalid to use the platform, the graph builders, runners or handles after this call.
+	// Calling Close more than once returns the error of the first call.
+	Close() error
 
 	// Release everything linked to the platform.
-	// It is invalid to use the platform or graph builder after this call.
+	//
+	// Deprecated: use Close.
 	Release() error
 }
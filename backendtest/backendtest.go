@@ -0,0 +1,252 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backendtest provides a conformance test suite for backends.
+//
+// RunConformance builds, compiles and runs small graphs exercising every method of the
+// builders of a backend, and compares their results with reference values.
+// Running the same suite on every backend ensures they all converge on the same behavior.
+// Cases using a data type or an operation not reported by the capabilities of a backend
+// are skipped.
+package backendtest
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/dtype/convert"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// literal is an array given on the host to build constants, arguments or expected results.
+// Its values are stored as complex128 whatever its data type, which represents exactly
+// all the values used by the suite.
+type literal struct {
+	dt   dtype.DataType
+	dims []int
+	vals []complex128
+}
+
+// lit returns a literal of a data type with real values.
+// dims are the axis lengths: nil for an atomic value.
+func lit(dt dtype.DataType, dims []int, vals ...float64) literal {
+	l := literal{dt: dt, dims: dims, vals: make([]complex128, len(vals))}
+	for i, v := range vals {
+		l.vals[i] = complex(v, 0)
+	}
+	return l
+}
+
+// clit returns a literal of a complex data type.
+func clit(dt dtype.DataType, dims []int, vals ...complex128) literal {
+	return literal{dt: dt, dims: dims, vals: vals}
+}
+
+// bools returns a boolean literal.
+func bools(dims []int, vals ...bool) literal {
+	l := literal{dt: dtype.Bool, dims: dims, vals: make([]complex128, len(vals))}
+	for i, v := range vals {
+		if v {
+			l.vals[i] = 1
+		}
+	}
+	return l
+}
+
+// dims returns axis lengths. It improves the readability of literals.
+func dims(axisLengths ...int) []int {
+	return axisLengths
+}
+
+func (l literal) shape() *shape.Shape {
+	return shape.Of(l.dt, l.dims...)
+}
+
+// hostBuffer converts the values of the literal into a host buffer of its data type.
+func (l literal) hostBuffer() (platform.HostBuffer, error) {
+	sh := l.shape()
+	if size := sh.Size(); len(l.vals) != size {
+		return nil, fmt.Errorf("literal of shape %s has %d values: want %d", sh, len(l.vals), size)
+	}
+	data, err := convert.Bytes(dtype.FromSlice(l.vals), dtype.Complex128, l.dt, convert.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return platform.Borrow(data, sh)
+}
+
+// readValues copies the content of a handle to the host and returns its values as complex128.
+func readValues(h platform.Handle) ([]complex128, error) {
+	sh := h.Shape()
+	data := make([]byte, sh.ByteSize())
+	buf, err := platform.Borrow(data, sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.ToHost(buf); err != nil {
+		return nil, err
+	}
+	vals, err := convert.Bytes(data, sh.DType, dtype.Complex128, convert.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return dtype.ToSlice[complex128](vals), nil
+}
+
+// testCase builds a graph and compares its result with an expected literal.
+type testCase struct {
+	name string
+	// ops are the operations used by the case, apart from constants.
+	ops []ops.OpID
+	// args are passed as arguments to the graph.
+	args []literal
+	// build returns the node computing the result.
+	build func(b *builder) ops.Node
+	// want is the expected result.
+	want literal
+	// tol is the tolerance of the comparison of floating-point values, relative to the
+	// magnitude of the expected values larger than 1. Integer values are compared exactly.
+	tol float64
+}
+
+// group of test cases for a builder.
+type group struct {
+	name  string
+	cases func() []testCase
+}
+
+var groups = []group{
+	{name: "core", cases: coreCases},
+	{name: "num", cases: numCases},
+	{name: "dtype", cases: dtypeCases},
+	{name: "math", cases: mathCases},
+	{name: "nn", cases: nnCases},
+	{name: "rand", cases: randCases},
+}
+
+// RunConformance runs the conformance test suite on the default device of a backend.
+// Each case is run as a subtest named after the builder and the operations it covers.
+func RunConformance(t *testing.T, b backend.Backend) {
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatalf("cannot get the default device: %v", err)
+	}
+	caps := b.Capabilities()
+	for _, grp := range groups {
+		t.Run(grp.name, func(t *testing.T) {
+			for _, c := range grp.cases() {
+				t.Run(c.name, func(t *testing.T) {
+					runCase(t, b, dev, caps, c)
+				})
+			}
+		})
+	}
+}
+
+func runCase(t *testing.T, b backend.Backend, dev platform.Device, caps ops.Capabilities, c testCase) {
+	for _, op := range append([]ops.OpID{ops.OpConstant}, c.ops...) {
+		if !caps.SupportsOp(op) {
+			t.Skipf("operation %s not supported", op)
+		}
+	}
+	g, err := b.NewOps(c.name)
+	if err != nil {
+		t.Fatalf("cannot create a graph: %v", err)
+	}
+	bld := newBuilder(g, c.args)
+	out, err := bld.run(c.build)
+	if err != nil {
+		t.Fatalf("cannot build the graph: %v", err)
+	}
+	for _, sh := range append(bld.st.shapes, c.want.shape()) {
+		if !caps.SupportsDType(sh.DType) {
+			t.Skipf("data type %s not supported", sh.DType)
+		}
+		if maxRank := caps.MaxRank(); maxRank >= 0 && len(sh.AxisLengths) > maxRank {
+			t.Skipf("rank %d larger than the maximum rank %d", len(sh.AxisLengths), maxRank)
+		}
+	}
+	params := make([]*shape.Shape, len(c.args))
+	args := make([]platform.Handle, len(c.args))
+	for i, arg := range c.args {
+		params[i] = arg.shape()
+		if args[i], err = arg.hostBuffer(); err != nil {
+			t.Fatalf("invalid argument %d: %v", i, err)
+		}
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: out, Shape: c.want.shape()}}, nil, params)
+	if err != nil {
+		t.Fatalf("cannot compile the graph: %v", err)
+	}
+	res, _, err := runner.Run(args)
+	if err != nil {
+		t.Fatalf("cannot run the graph: %v", err)
+	}
+	defer func() {
+		for _, h := range res {
+			h.Free()
+		}
+	}()
+	if len(res) != 1 {
+		t.Fatalf("got %d results but want 1", len(res))
+	}
+	if got, want := res[0].Shape(), c.want.shape(); !got.Equal(want) {
+		t.Fatalf("got a result of shape %s but want %s", got, want)
+	}
+	got, err := readValues(res[0])
+	if err != nil {
+		t.Fatalf("cannot read the result: %v", err)
+	}
+	if i := mismatch(got, c.want.vals, c.tol); i >= 0 {
+		t.Errorf("got %v but want %v: element %d differs", format(c.want.dt, got), format(c.want.dt, c.want.vals), i)
+	}
+}
+
+// format returns the values as a string, dropping the imaginary parts of non-complex values.
+func format(dt dtype.DataType, vals []complex128) string {
+	if dtype.IsComplex(dt) {
+		return fmt.Sprint(vals)
+	}
+	reals := make([]float64, len(vals))
+	for i, v := range vals {
+		reals[i] = real(v)
+	}
+	return fmt.Sprint(reals)
+}
+
+// mismatch returns the index of the first value of got not equal to the value of want
+// within a tolerance, or -1 if all values match. NaN matches NaN.
+func mismatch(got, want []complex128, tol float64) int {
+	if len(got) != len(want) {
+		return 0
+	}
+	for i, w := range want {
+		g := got[i]
+		switch {
+		case g == w:
+		case cmplx.IsNaN(g) && cmplx.IsNaN(w):
+		case cmplx.IsInf(g) || cmplx.IsInf(w) || cmplx.IsNaN(g) || cmplx.IsNaN(w):
+			return i
+		case cmplx.Abs(g-w) > tol*math.Max(1, cmplx.Abs(w)):
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,32 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/backendtest"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/platform"
+)
+
+func TestGoEval(t *testing.T) {
+	b, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	backendtest.RunConformance(t, b)
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// buildState is shared by a builder and the builders of its subgraphs.
+type buildState struct {
+	// err is the first error returned by a builder method.
+	err error
+	// shapes are the shapes of the constants and arguments, used to check capabilities.
+	shapes []*shape.Shape
+}
+
+// builder builds the nodes of a graph, recording the first error such that cases
+// can chain calls without checking errors.
+type builder struct {
+	g    ops.Graph
+	args []literal
+	st   *buildState
+}
+
+func newBuilder(g ops.Graph, args []literal) *builder {
+	b := &builder{g: g, args: args, st: &buildState{}}
+	for _, arg := range args {
+		b.st.shapes = append(b.st.shapes, arg.shape())
+	}
+	return b
+}
+
+// run calls build and returns its result or the first error of the builder.
+// A panic of a builder method called with the nil result of a failed method
+// reports the error of the failed method.
+func (b *builder) run(build func(*builder) ops.Node) (n ops.Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if b.st.err == nil {
+				panic(r)
+			}
+			n, err = nil, b.st.err
+		}
+	}()
+	n = build(b)
+	return n, b.st.err
+}
+
+func (b *builder) core() ops.CoreBuilder {
+	return b.g.Core()
+}
+
+// node returns n, recording err if it is not nil.
+func (b *builder) node(n ops.Node, err error) ops.Node {
+	if err != nil && b.st.err == nil {
+		b.st.err = err
+	}
+	return n
+}
+
+// element returns the ith element of a tuple node.
+func (b *builder) element(n ops.Node, i int) ops.Node {
+	tpl, ok := n.(ops.Tuple)
+	if !ok {
+		return b.node(nil, fmt.Errorf("node %T is not a tuple", n))
+	}
+	return b.node(tpl.Element(i))
+}
+
+// constant returns a node for a literal.
+func (b *builder) constant(l literal) ops.Node {
+	buf, err := l.hostBuffer()
+	if err != nil {
+		return b.node(nil, err)
+	}
+	b.st.shapes = append(b.st.shapes, l.shape())
+	return b.node(b.core().Constant(buf))
+}
+
+// arg returns the node of the ith argument of the graph.
+func (b *builder) arg(i int) ops.Node {
+	return b.node(b.core().Argument(fmt.Sprintf("arg%d", i), b.args[i].shape(), i))
+}
+
+// unary applies a unary operator to a literal.
+func (b *builder) unary(op ops.UnaryOperator, x literal) ops.Node {
+	return b.node(b.core().UnaryOp(op, b.constant(x)))
+}
+
+// binary applies a binary operator to two literals.
+func (b *builder) binary(op ops.BinaryOperator, x, y literal) ops.Node {
+	return b.node(b.core().BinaryOp(op, b.constant(x), b.constant(y)))
+}
+
+// subgraph returns a subgraph taking arguments of the given shapes and returning
+// the node built by body, of the shape result.
+func (b *builder) subgraph(name string, params []*shape.Shape, result *shape.Shape, body func(sb *builder, args []ops.Node) ops.Node) *ops.Subgraph {
+	g, err := b.core().Subgraph(name, params)
+	if err != nil {
+		b.node(nil, err)
+		return nil
+	}
+	sb := &builder{g: g, st: b.st}
+	args := make([]ops.Node, len(params))
+	for i, param := range params {
+		args[i] = sb.node(g.Core().Argument(fmt.Sprintf("%s%d", name, i), param, i))
+	}
+	return &ops.Subgraph{Graph: g, Result: ops.OutputNode{Node: body(sb, args), Shape: result}}
+}
+
+// combiner returns a subgraph applying a binary operator to two atomic values.
+func (b *builder) combiner(op ops.BinaryOperator, sh *shape.Shape) *ops.Subgraph {
+	return b.subgraph("combine", []*shape.Shape{sh, sh}, sh, func(sb *builder, args []ops.Node) ops.Node {
+		return sb.node(sb.core().BinaryOp(op, args[0], args[1]))
+	})
+}
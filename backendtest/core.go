@@ -0,0 +1,848 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+var (
+	signedTypes   = []dtype.DataType{dtype.Int8, dtype.Int16, dtype.Int32, dtype.Int64}
+	unsignedTypes = []dtype.DataType{dtype.Uint8, dtype.Uint16, dtype.Uint32, dtype.Uint64}
+	floatTypes    = []dtype.DataType{dtype.Float32, dtype.Float64}
+	halfTypes     = []dtype.DataType{dtype.Bfloat16, dtype.Float16}
+	complexTypes  = []dtype.DataType{dtype.Complex64, dtype.Complex128}
+)
+
+// tolerance returns the tolerance to compare results computed with a data type.
+func tolerance(dt dtype.DataType) float64 {
+	switch dt {
+	case dtype.Bfloat16:
+		return 1e-2
+	case dtype.Float16:
+		return 1e-3
+	case dtype.Float32, dtype.Complex64:
+		return 1e-5
+	case dtype.Float64, dtype.Complex128:
+		return 1e-12
+	}
+	return 0
+}
+
+// apply returns f applied to every value.
+func apply(vals []float64, f func(float64) float64) []float64 {
+	res := make([]float64, len(vals))
+	for i, v := range vals {
+		res[i] = f(v)
+	}
+	return res
+}
+
+// apply2 returns f applied to every pair of values.
+func apply2(x, y []float64, f func(float64, float64) float64) []float64 {
+	res := make([]float64, len(x))
+	for i := range x {
+		res[i] = f(x[i], y[i])
+	}
+	return res
+}
+
+// iota returns the values from 1 to n.
+func iota(n int) []float64 {
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = float64(i + 1)
+	}
+	return vals
+}
+
+// maxUint returns the maximum value of an unsigned integer type smaller than 64 bits.
+func maxUint(dt dtype.DataType) float64 {
+	return math.Exp2(float64(dtype.BitSizeof(dt))) - 1
+}
+
+func coreCases() []testCase {
+	return slices.Concat(
+		unaryCases(),
+		binaryCases(),
+		comparisonCases(),
+		structuralCases(),
+		castCases(),
+		dotCases(),
+		controlCases(),
+		quantizeCases(),
+		reduceCases(),
+		indexingCases(),
+		sortCases(),
+	)
+}
+
+func unaryCase(name string, op ops.UnaryOperator, x, want literal) testCase {
+	return testCase{
+		name: fmt.Sprintf("%s/%s", name, x.dt),
+		ops:  []ops.OpID{ops.OpUnary},
+		build: func(b *builder) ops.Node {
+			return b.unary(op, x)
+		},
+		want: want,
+	}
+}
+
+func unaryCases() []testCase {
+	var cases []testCase
+	x := []float64{1, -2, 3, 0}
+	neg := apply(x, func(v float64) float64 { return -v })
+	for _, dt := range slices.Concat(signedTypes, floatTypes, halfTypes) {
+		cases = append(cases,
+			unaryCase("Plus", ops.Plus, lit(dt, dims(4), x...), lit(dt, dims(4), x...)),
+			unaryCase("Neg", ops.Neg, lit(dt, dims(4), x...), lit(dt, dims(4), neg...)),
+		)
+	}
+	for _, dt := range signedTypes {
+		not := apply(x, func(v float64) float64 { return -v - 1 })
+		cases = append(cases, unaryCase("BitNot", ops.BitNot, lit(dt, dims(4), x...), lit(dt, dims(4), not...)))
+	}
+	ux := []float64{1, 2, 3, 0}
+	for _, dt := range unsignedTypes {
+		cases = append(cases, unaryCase("Plus", ops.Plus, lit(dt, dims(4), ux...), lit(dt, dims(4), ux...)))
+		if dt == dtype.Uint64 {
+			// Results of Neg and BitNot are not representable by float64 literals.
+			continue
+		}
+		maxVal := maxUint(dt)
+		not := apply(ux, func(v float64) float64 { return maxVal - v })
+		wrapped := apply(ux, func(v float64) float64 { return math.Mod(maxVal+1-v, maxVal+1) })
+		cases = append(cases,
+			unaryCase("BitNot", ops.BitNot, lit(dt, dims(4), ux...), lit(dt, dims(4), not...)),
+			unaryCase("Neg", ops.Neg, lit(dt, dims(4), ux...), lit(dt, dims(4), wrapped...)),
+		)
+	}
+	cx := []complex128{1 + 2i, -3, 4i}
+	for _, dt := range complexTypes {
+		cases = append(cases,
+			unaryCase("Plus", ops.Plus, clit(dt, dims(3), cx...), clit(dt, dims(3), cx...)),
+			unaryCase("Neg", ops.Neg, clit(dt, dims(3), cx...), clit(dt, dims(3), -cx[0], -cx[1], -cx[2])),
+		)
+	}
+	return append(cases,
+		unaryCase("Not", ops.Not, bools(dims(2), true, false), bools(dims(2), false, true)),
+		unaryCase("BitNot", ops.BitNot, bools(dims(2), true, false), bools(dims(2), false, true)),
+		testCase{
+			name: "Unary/ast",
+			ops:  []ops.OpID{ops.OpUnary},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().Unary(&ast.UnaryExpr{Op: token.SUB}, b.constant(lit(dtype.Float32, dims(2), 1, -2))))
+			},
+			want: lit(dtype.Float32, dims(2), -1, 2),
+		},
+	)
+}
+
+func binaryCase(name string, op ops.BinaryOperator, x, y, want literal) testCase {
+	return testCase{
+		name: fmt.Sprintf("%s/%s", name, x.dt),
+		ops:  []ops.OpID{ops.OpBinary},
+		build: func(b *builder) ops.Node {
+			return b.binary(op, x, y)
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+// binaryRef is a binary operator with a function computing reference results.
+type binaryRef struct {
+	name string
+	op   ops.BinaryOperator
+	f    func(x, y float64) float64
+}
+
+var (
+	arithmeticRefs = []binaryRef{
+		{"Add", ops.Add, func(x, y float64) float64 { return x + y }},
+		{"Sub", ops.Sub, func(x, y float64) float64 { return x - y }},
+		{"Mul", ops.Mul, func(x, y float64) float64 { return x * y }},
+		{"Div", ops.Div, func(x, y float64) float64 { return x / y }},
+		{"Rem", ops.Rem, math.Mod},
+	}
+
+	bitwiseRefs = []binaryRef{
+		{"BitAnd", ops.BitAnd, intFunc(func(x, y int64) int64 { return x & y })},
+		{"BitOr", ops.BitOr, intFunc(func(x, y int64) int64 { return x | y })},
+		{"BitXor", ops.BitXor, intFunc(func(x, y int64) int64 { return x ^ y })},
+		{"BitAndNot", ops.BitAndNot, intFunc(func(x, y int64) int64 { return x &^ y })},
+	}
+
+	comparisonRefs = []binaryRef{
+		{"Equal", ops.Equal, boolFunc(func(x, y float64) bool { return x == y })},
+		{"NotEqual", ops.NotEqual, boolFunc(func(x, y float64) bool { return x != y })},
+		{"Less", ops.Less, boolFunc(func(x, y float64) bool { return x < y })},
+		{"LessEqual", ops.LessEqual, boolFunc(func(x, y float64) bool { return x <= y })},
+		{"Greater", ops.Greater, boolFunc(func(x, y float64) bool { return x > y })},
+		{"GreaterEqual", ops.GreaterEqual, boolFunc(func(x, y float64) bool { return x >= y })},
+	}
+)
+
+func intFunc(f func(x, y int64) int64) func(x, y float64) float64 {
+	return func(x, y float64) float64 { return float64(f(int64(x), int64(y))) }
+}
+
+func boolFunc(f func(x, y float64) bool) func(x, y float64) float64 {
+	return func(x, y float64) float64 {
+		if f(x, y) {
+			return 1
+		}
+		return 0
+	}
+}
+
+func binaryCases() []testCase {
+	var cases []testCase
+	sx, sy := []float64{7, -7, 12, 0}, []float64{2, 2, -5, 3}
+	ux, uy := []float64{7, 9, 12, 3}, []float64{2, 4, 5, 3}
+	for _, ref := range arithmeticRefs {
+		f := ref.f
+		if ref.op == ops.Div {
+			f = func(x, y float64) float64 { return math.Trunc(x / y) }
+		}
+		for _, dt := range signedTypes {
+			cases = append(cases, binaryCase(ref.name, ref.op, lit(dt, dims(4), sx...), lit(dt, dims(4), sy...), lit(dt, dims(4), apply2(sx, sy, f)...)))
+		}
+		for _, dt := range unsignedTypes {
+			cases = append(cases, binaryCase(ref.name, ref.op, lit(dt, dims(4), ux...), lit(dt, dims(4), uy...), lit(dt, dims(4), apply2(ux, uy, f)...)))
+		}
+		for _, dt := range slices.Concat(floatTypes, halfTypes) {
+			cases = append(cases, binaryCase(ref.name, ref.op, lit(dt, dims(4), sx...), lit(dt, dims(4), sy...), lit(dt, dims(4), apply2(sx, sy, ref.f)...)))
+		}
+	}
+	cx, cy := []complex128{1 + 2i, 3 - 1i, -2}, []complex128{3 - 1i, 1 + 1i, 4i}
+	complexRefs := []struct {
+		name string
+		op   ops.BinaryOperator
+		f    func(x, y complex128) complex128
+	}{
+		{"Add", ops.Add, func(x, y complex128) complex128 { return x + y }},
+		{"Sub", ops.Sub, func(x, y complex128) complex128 { return x - y }},
+		{"Mul", ops.Mul, func(x, y complex128) complex128 { return x * y }},
+		{"Div", ops.Div, func(x, y complex128) complex128 { return x / y }},
+	}
+	for _, ref := range complexRefs {
+		want := make([]complex128, len(cx))
+		for i := range cx {
+			want[i] = ref.f(cx[i], cy[i])
+		}
+		for _, dt := range complexTypes {
+			cases = append(cases, binaryCase(ref.name, ref.op, clit(dt, dims(3), cx...), clit(dt, dims(3), cy...), clit(dt, dims(3), want...)))
+		}
+	}
+	px, py := []float64{2, 4, 9, 2}, []float64{3, 0.5, 0.5, -1}
+	for _, dt := range floatTypes {
+		cases = append(cases, binaryCase("Pow", ops.Pow, lit(dt, dims(4), px...), lit(dt, dims(4), py...), lit(dt, dims(4), apply2(px, py, math.Pow)...)))
+	}
+	ix, iy := []float64{2, 3, -2, 5}, []float64{3, 2, 3, 0}
+	for _, dt := range signedTypes {
+		cases = append(cases, binaryCase("Pow", ops.Pow, lit(dt, dims(4), ix...), lit(dt, dims(4), iy...), lit(dt, dims(4), apply2(ix, iy, math.Pow)...)))
+	}
+	bx, by := []float64{12, 10, 7, 0}, []float64{10, 6, 1, 5}
+	for _, ref := range bitwiseRefs {
+		for _, dt := range slices.Concat(signedTypes, unsignedTypes) {
+			cases = append(cases, binaryCase(ref.name, ref.op, lit(dt, dims(4), bx...), lit(dt, dims(4), by...), lit(dt, dims(4), apply2(bx, by, ref.f)...)))
+		}
+	}
+	px, py = []float64{1, 0, 1, 0}, []float64{1, 1, 0, 0}
+	for _, ref := range append(bitwiseRefs[:3:3],
+		binaryRef{"LogicalAnd", ops.LogicalAnd, bitwiseRefs[0].f},
+		binaryRef{"LogicalOr", ops.LogicalOr, bitwiseRefs[1].f},
+	) {
+		cases = append(cases, binaryCase(ref.name, ref.op, lit(dtype.Bool, dims(4), px...), lit(dtype.Bool, dims(4), py...), lit(dtype.Bool, dims(4), apply2(px, py, ref.f)...)))
+	}
+	shl := intFunc(func(x, y int64) int64 { return x << y })
+	shr := intFunc(func(x, y int64) int64 { return x >> y })
+	shifts := []float64{0, 1, 2, 3}
+	for _, dt := range signedTypes {
+		lx, rx := []float64{1, 3, 5, -7}, []float64{-8, 16, 7, -1}
+		cases = append(cases,
+			binaryCase("Shl", ops.Shl, lit(dt, dims(4), lx...), lit(dt, dims(4), shifts...), lit(dt, dims(4), apply2(lx, shifts, shl)...)),
+			binaryCase("Shr", ops.Shr, lit(dt, dims(4), rx...), lit(dt, dims(4), shifts...), lit(dt, dims(4), apply2(rx, shifts, shr)...)),
+		)
+	}
+	for _, dt := range unsignedTypes {
+		lx, rx := []float64{1, 3, 5, 7}, []float64{8, 16, 7, 100}
+		cases = append(cases,
+			binaryCase("Shl", ops.Shl, lit(dt, dims(4), lx...), lit(dt, dims(4), shifts...), lit(dt, dims(4), apply2(lx, shifts, shl)...)),
+			binaryCase("Shr", ops.Shr, lit(dt, dims(4), rx...), lit(dt, dims(4), shifts...), lit(dt, dims(4), apply2(rx, shifts, shr)...)),
+		)
+	}
+	return append(cases,
+		binaryCase("WrapAround/Add", ops.Add, lit(dtype.Int8, dims(2), 100, -100), lit(dtype.Int8, dims(2), 100, -100), lit(dtype.Int8, dims(2), -56, 56)),
+		binaryCase("WrapAround/Add", ops.Add, lit(dtype.Uint8, dims(2), 250, 1), lit(dtype.Uint8, dims(2), 10, 1), lit(dtype.Uint8, dims(2), 4, 2)),
+		binaryCase("WrapAround/Sub", ops.Sub, lit(dtype.Int16, dims(1), -32768), lit(dtype.Int16, dims(1), 1), lit(dtype.Int16, dims(1), 32767)),
+		binaryCase("WrapAround/Sub", ops.Sub, lit(dtype.Uint32, dims(1), 0), lit(dtype.Uint32, dims(1), 1), lit(dtype.Uint32, dims(1), math.MaxUint32)),
+		binaryCase("WrapAround/Mul", ops.Mul, lit(dtype.Int32, dims(1), 65536), lit(dtype.Int32, dims(1), 65536), lit(dtype.Int32, dims(1), 0)),
+		binaryCase("Broadcast/Add", ops.Add, lit(dtype.Float32, dims(2, 2), 1, 2, 3, 4), lit(dtype.Float32, nil, 10), lit(dtype.Float32, dims(2, 2), 11, 12, 13, 14)),
+		binaryCase("Broadcast/Sub", ops.Sub, lit(dtype.Int32, nil, 10), lit(dtype.Int32, dims(3), 1, 2, 3), lit(dtype.Int32, dims(3), 9, 8, 7)),
+		binaryCase("Atomic/Mul", ops.Mul, lit(dtype.Float64, nil, 3), lit(dtype.Float64, nil, -2), lit(dtype.Float64, nil, -6)),
+		testCase{
+			name: "Binary/ast",
+			ops:  []ops.OpID{ops.OpBinary},
+			build: func(b *builder) ops.Node {
+				x := b.constant(lit(dtype.Int32, dims(2), 1, 2))
+				return b.node(b.core().Binary(&ast.BinaryExpr{Op: token.MUL}, x, x))
+			},
+			want: lit(dtype.Int32, dims(2), 1, 4),
+		},
+	)
+}
+
+func comparisonCases() []testCase {
+	var cases []testCase
+	x, y := []float64{1, 2, 3, 0}, []float64{2, 2, 1, 0}
+	types := []dtype.DataType{dtype.Int8, dtype.Int32, dtype.Int64, dtype.Uint8, dtype.Uint32, dtype.Float32, dtype.Float64, dtype.Bfloat16, dtype.Float16}
+	nan := math.NaN()
+	nx, ny := []float64{nan, nan, 1, math.Inf(-1)}, []float64{nan, 1, nan, -1}
+	for _, ref := range comparisonRefs {
+		for _, dt := range types {
+			cases = append(cases, binaryCase(ref.name, ref.op, lit(dt, dims(4), x...), lit(dt, dims(4), y...), lit(dtype.Bool, dims(4), apply2(x, y, ref.f)...)))
+		}
+		for _, dt := range floatTypes {
+			cases = append(cases, binaryCase(ref.name+"/NaN", ref.op, lit(dt, dims(4), nx...), lit(dt, dims(4), ny...), lit(dtype.Bool, dims(4), apply2(nx, ny, ref.f)...)))
+		}
+	}
+	return append(cases,
+		binaryCase("Equal", ops.Equal, bools(dims(2), true, false), bools(dims(2), true, true), bools(dims(2), true, false)),
+		binaryCase("Equal", ops.Equal, clit(dtype.Complex64, dims(2), 1+1i, 2), clit(dtype.Complex64, dims(2), 1+1i, 2i), bools(dims(2), true, false)),
+	)
+}
+
+// shapeCase returns a case applying a structural operation f to the literal x.
+func shapeCase(name string, op ops.OpID, x literal, want literal, f func(b *builder, x ops.Node) ops.Node) testCase {
+	return testCase{
+		name: name,
+		ops:  []ops.OpID{op},
+		build: func(b *builder) ops.Node {
+			return f(b, b.constant(x))
+		},
+		want: want,
+	}
+}
+
+func structuralCases() []testCase {
+	f32 := func(axisLengths []int, vals ...float64) literal { return lit(dtype.Float32, axisLengths, vals...) }
+	i32 := func(axisLengths []int, vals ...float64) literal { return lit(dtype.Int32, axisLengths, vals...) }
+	m23 := f32(dims(2, 3), iota(6)...)
+	reshape := func(axisLengths ...int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node { return b.node(b.core().Reshape(x, axisLengths)) }
+	}
+	concat := func(axis int, others ...literal) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node {
+			nodes := []ops.Node{x}
+			for _, other := range others {
+				nodes = append(nodes, b.constant(other))
+			}
+			return b.node(b.core().Concat(axis, nodes))
+		}
+	}
+	slice := func(index int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node { return b.node(b.core().Slice(x, index)) }
+	}
+	set := func(updates literal, index int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node {
+			return b.node(b.core().Set(x, b.constant(updates), b.constant(i32(nil, float64(index)))))
+		}
+	}
+	broadcast := func(sh *shape.Shape, axes ...int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node { return b.node(b.core().BroadcastInDim(x, sh, axes)) }
+	}
+	transpose := func(perm ...int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node { return b.node(b.core().Transpose(x, perm)) }
+	}
+	reverse := func(axes ...int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node { return b.node(b.core().Reverse(x, axes)) }
+	}
+	pad := func(val float64, low, high, interior []int) func(b *builder, x ops.Node) ops.Node {
+		return func(b *builder, x ops.Node) ops.Node {
+			return b.node(b.core().Pad(x, b.constant(f32(nil, val)), low, high, interior))
+		}
+	}
+	return []testCase{
+		shapeCase("Reshape/matrix", ops.OpReshape, m23, f32(dims(3, 2), iota(6)...), reshape(3, 2)),
+		shapeCase("Reshape/unit_axes", ops.OpReshape, i32(dims(6), iota(6)...), i32(dims(1, 6, 1), iota(6)...), reshape(1, 6, 1)),
+		shapeCase("Reshape/atomic", ops.OpReshape, f32(dims(1), 7), f32(nil, 7), reshape()),
+		shapeCase("Reshape/bool", ops.OpReshape, bools(dims(2, 2), true, false, false, true), bools(dims(4), true, false, false, true), reshape(4)),
+		shapeCase("Concat/axis0", ops.OpConcat, f32(dims(1, 2), 1, 2), f32(dims(3, 2), iota(6)...), concat(0, f32(dims(2, 2), 3, 4, 5, 6))),
+		shapeCase("Concat/axis1", ops.OpConcat, f32(dims(2, 1), 1, 2), f32(dims(2, 3), 1, 3, 4, 2, 5, 6), concat(1, f32(dims(2, 2), 3, 4, 5, 6))),
+		shapeCase("Concat/three", ops.OpConcat, i32(dims(1), 1), i32(dims(6), iota(6)...), concat(0, i32(dims(2), 2, 3), i32(dims(3), 4, 5, 6))),
+		shapeCase("Concat/single", ops.OpConcat, i32(dims(2), 1, 2), i32(dims(2), 1, 2), concat(0)),
+		shapeCase("Slice/matrix", ops.OpSlice, f32(dims(3, 2), iota(6)...), f32(dims(2), 3, 4), slice(1)),
+		shapeCase("Slice/vector", ops.OpSlice, i32(dims(3), 5, 6, 7), i32(nil, 7), slice(2)),
+		shapeCase("Slice/rank3", ops.OpSlice, f32(dims(2, 2, 2), iota(8)...), f32(dims(2, 2), 1, 2, 3, 4), slice(0)),
+		shapeCase("Set/matrix", ops.OpSet, f32(dims(3, 2), iota(6)...), f32(dims(3, 2), 1, 2, 9, 8, 5, 6), set(f32(dims(2), 9, 8), 1)),
+		shapeCase("Set/vector", ops.OpSet, i32(dims(3), 1, 2, 3), i32(dims(3), 1, 2, -1), set(i32(nil, -1), 2)),
+		shapeCase("BroadcastInDim/rows", ops.OpBroadcastInDim, f32(dims(3), 1, 2, 3), f32(dims(2, 3), 1, 2, 3, 1, 2, 3), broadcast(shape.Of(dtype.Float32, 2, 3), 1)),
+		shapeCase("BroadcastInDim/columns", ops.OpBroadcastInDim, f32(dims(2), 1, 2), f32(dims(2, 3), 1, 1, 1, 2, 2, 2), broadcast(shape.Of(dtype.Float32, 2, 3), 0)),
+		shapeCase("BroadcastInDim/atomic", ops.OpBroadcastInDim, i32(nil, 7), i32(dims(2, 2), 7, 7, 7, 7), broadcast(shape.Of(dtype.Int32, 2, 2))),
+		shapeCase("BroadcastInDim/unit_axis", ops.OpBroadcastInDim, f32(dims(1, 3), 1, 2, 3), f32(dims(2, 3), 1, 2, 3, 1, 2, 3), broadcast(shape.Of(dtype.Float32, 2, 3), 0, 1)),
+		shapeCase("Transpose/matrix", ops.OpTranspose, m23, f32(dims(3, 2), 1, 4, 2, 5, 3, 6), transpose(1, 0)),
+		shapeCase("Transpose/rank3", ops.OpTranspose, f32(dims(2, 1, 3), iota(6)...), f32(dims(3, 2, 1), 1, 4, 2, 5, 3, 6), transpose(2, 0, 1)),
+		shapeCase("Transpose/identity", ops.OpTranspose, m23, m23, transpose(0, 1)),
+		shapeCase("Reverse/axis0", ops.OpReverse, m23, f32(dims(2, 3), 4, 5, 6, 1, 2, 3), reverse(0)),
+		shapeCase("Reverse/axis1", ops.OpReverse, m23, f32(dims(2, 3), 3, 2, 1, 6, 5, 4), reverse(1)),
+		shapeCase("Reverse/all", ops.OpReverse, m23, f32(dims(2, 3), 6, 5, 4, 3, 2, 1), reverse(0, 1)),
+		shapeCase("Pad/edges", ops.OpPad, f32(dims(3), 1, 2, 3), f32(dims(6), 0, 1, 2, 3, 0, 0), pad(0, dims(1), dims(2), dims(0))),
+		shapeCase("Pad/interior", ops.OpPad, f32(dims(3), 1, 2, 3), f32(dims(5), 1, -1, 2, -1, 3), pad(-1, dims(0), dims(0), dims(1))),
+		shapeCase("Pad/negative", ops.OpPad, f32(dims(3), 1, 2, 3), f32(dims(3), 2, 3, 0), pad(0, dims(-1), dims(1), dims(0))),
+		shapeCase("Pad/matrix", ops.OpPad, f32(dims(2, 2), 1, 2, 3, 4), f32(dims(3, 3), 9, 9, 9, 1, 2, 9, 3, 4, 9), pad(9, dims(1, 0), dims(0, 1), dims(0, 0))),
+		{
+			name: "Argument",
+			ops:  []ops.OpID{ops.OpArgument, ops.OpBinary},
+			args: []literal{f32(dims(2, 2), 1, 2, 3, 4), f32(nil, 2)},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().BinaryOp(ops.Mul, b.arg(0), b.arg(1)))
+			},
+			want: f32(dims(2, 2), 2, 4, 6, 8),
+		},
+		{
+			name: "Tuple",
+			ops:  []ops.OpID{ops.OpTuple},
+			build: func(b *builder) ops.Node {
+				tpl, err := b.core().Tuple([]ops.Node{b.constant(f32(dims(2), 1, 2)), b.constant(i32(dims(3), 3, 4, 5))})
+				if err != nil {
+					return b.node(nil, err)
+				}
+				return b.element(tpl, 1)
+			},
+			want: i32(dims(3), 3, 4, 5),
+		},
+	}
+}
+
+// castCase returns a case casting x to the data type of want.
+func castCase(x, want literal) testCase {
+	return testCase{
+		name: fmt.Sprintf("%s_to_%s", x.dt, want.dt),
+		ops:  []ops.OpID{ops.OpCast},
+		build: func(b *builder) ops.Node {
+			return b.node(b.core().Cast(b.constant(x), want.dt))
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+func castCases() []testCase {
+	cases := []testCase{
+		castCase(lit(dtype.Float32, dims(4), -2.7, -0.5, 0.5, 2.7), lit(dtype.Int32, dims(4), -2, 0, 0, 2)),
+		castCase(lit(dtype.Float64, dims(3), -100.9, 3.99, 127), lit(dtype.Int8, dims(3), -100, 3, 127)),
+		castCase(lit(dtype.Float32, dims(2), 3.5, 255), lit(dtype.Uint8, dims(2), 3, 255)),
+		castCase(lit(dtype.Int32, dims(4), 300, -129, 127, -1), lit(dtype.Int8, dims(4), 44, 127, 127, -1)),
+		castCase(lit(dtype.Int32, dims(2), 70000, -1), lit(dtype.Uint16, dims(2), 4464, 65535)),
+		castCase(lit(dtype.Int8, dims(2), -1, 5), lit(dtype.Int32, dims(2), -1, 5)),
+		castCase(lit(dtype.Int8, dims(2), -1, 5), lit(dtype.Uint8, dims(2), 255, 5)),
+		castCase(lit(dtype.Uint8, dims(2), 255, 5), lit(dtype.Int32, dims(2), 255, 5)),
+		castCase(lit(dtype.Uint8, dims(2), 255, 5), lit(dtype.Int8, dims(2), -1, 5)),
+		castCase(lit(dtype.Int32, dims(2), -1, 7), lit(dtype.Uint32, dims(2), math.MaxUint32, 7)),
+		castCase(lit(dtype.Int32, dims(2), -1, 7), lit(dtype.Int64, dims(2), -1, 7)),
+		castCase(lit(dtype.Uint32, dims(2), math.MaxUint32, 7), lit(dtype.Int64, dims(2), math.MaxUint32, 7)),
+		castCase(lit(dtype.Int64, dims(2), -(1<<40), 1<<40+3), lit(dtype.Int32, dims(2), 0, 3)),
+		castCase(lit(dtype.Int32, dims(3), 0, 3, -2), lit(dtype.Bool, dims(3), 0, 1, 1)),
+		castCase(lit(dtype.Float32, dims(3), 0, 0.5, -2), lit(dtype.Bool, dims(3), 0, 1, 1)),
+		castCase(bools(dims(2), true, false), lit(dtype.Float32, dims(2), 1, 0)),
+		castCase(bools(dims(2), true, false), lit(dtype.Int32, dims(2), 1, 0)),
+		castCase(lit(dtype.Int32, dims(3), -3, 0, 1<<24), lit(dtype.Float32, dims(3), -3, 0, 1<<24)),
+		castCase(lit(dtype.Int64, dims(2), -3, 1<<40), lit(dtype.Float64, dims(2), -3, 1<<40)),
+		castCase(lit(dtype.Float32, dims(2), 0.25, -1e30), lit(dtype.Float64, dims(2), 0.25, -1.0000000150474662e30)),
+		castCase(lit(dtype.Float64, dims(2), 1.0/3, 1e300), lit(dtype.Float32, dims(2), 1.0/3, math.Inf(1))),
+		castCase(lit(dtype.Float32, dims(3), 1.5, -2, 0.1), lit(dtype.Bfloat16, dims(3), 1.5, -2, 0.1)),
+		castCase(lit(dtype.Bfloat16, dims(2), 1.5, -2), lit(dtype.Float32, dims(2), 1.5, -2)),
+		castCase(lit(dtype.Float32, dims(3), 1.5, -2, 0.1), lit(dtype.Float16, dims(3), 1.5, -2, 0.1)),
+		castCase(lit(dtype.Float16, dims(2), 1.5, -2), lit(dtype.Float64, dims(2), 1.5, -2)),
+		castCase(lit(dtype.Bfloat16, dims(2), 1.5, -2.5), lit(dtype.Int32, dims(2), 1, -2)),
+		castCase(lit(dtype.Float32, dims(2), 1.5, -2), lit(dtype.Complex64, dims(2), 1.5, -2)),
+		castCase(lit(dtype.Float64, dims(2), 1.5, -2), lit(dtype.Complex128, dims(2), 1.5, -2)),
+		castCase(clit(dtype.Complex64, dims(2), 1+2i, -3i), clit(dtype.Complex128, dims(2), 1+2i, -3i)),
+	}
+	nan := math.NaN()
+	return append(cases, castCase(lit(dtype.Float32, dims(3), nan, math.Inf(-1), 1), lit(dtype.Float64, dims(3), nan, math.Inf(-1), 1)))
+}
+
+func dotCase(name string, x, y literal, batchAxes, reduceAxes [2][]int, precision ops.Precision, want literal) testCase {
+	return testCase{
+		name: name,
+		ops:  []ops.OpID{ops.OpDotGeneral},
+		build: func(b *builder) ops.Node {
+			return b.node(b.core().DotGeneral(b.constant(x), b.constant(y), batchAxes, reduceAxes, precision))
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+func dotCases() []testCase {
+	matmul := [2][]int{{1}, {0}}
+	var cases []testCase
+	for _, dt := range []dtype.DataType{dtype.Float32, dtype.Float64, dtype.Int32, dtype.Int64, dtype.Bfloat16} {
+		cases = append(cases, dotCase("MatMul/"+dt.String(),
+			lit(dt, dims(2, 3), iota(6)...), lit(dt, dims(3, 2), iota(6)...),
+			[2][]int{}, matmul, ops.DefaultPrecision,
+			lit(dt, dims(2, 2), 22, 28, 49, 64)))
+	}
+	for _, precision := range []ops.Precision{ops.TF32Precision, ops.HighestPrecision} {
+		cases = append(cases, dotCase("MatMul/"+precision.String(),
+			lit(dtype.Float32, dims(2, 3), iota(6)...), lit(dtype.Float32, dims(3, 2), iota(6)...),
+			[2][]int{}, matmul, precision,
+			lit(dtype.Float32, dims(2, 2), 22, 28, 49, 64)))
+	}
+	return append(cases,
+		dotCase("Batch",
+			lit(dtype.Float32, dims(2, 1, 2), 1, 2, 3, 4), lit(dtype.Float32, dims(2, 2, 1), 5, 6, 7, 8),
+			[2][]int{{0}, {0}}, [2][]int{{2}, {1}}, ops.DefaultPrecision,
+			lit(dtype.Float32, dims(2, 1, 1), 17, 53)),
+		dotCase("Vector",
+			lit(dtype.Float32, dims(3), 1, 2, 3), lit(dtype.Float32, dims(3), 4, 5, 6),
+			[2][]int{}, [2][]int{{0}, {0}}, ops.DefaultPrecision,
+			lit(dtype.Float32, nil, 32)),
+		dotCase("Outer",
+			lit(dtype.Int32, dims(2), 1, 2), lit(dtype.Int32, dims(3), 3, 4, 5),
+			[2][]int{}, [2][]int{}, ops.DefaultPrecision,
+			lit(dtype.Int32, dims(2, 3), 3, 4, 5, 6, 8, 10)),
+		dotCase("TransposedOperands",
+			lit(dtype.Float32, dims(3, 2), 1, 4, 2, 5, 3, 6), lit(dtype.Float32, dims(2, 3), 1, 3, 5, 2, 4, 6),
+			[2][]int{}, [2][]int{{0}, {1}}, ops.DefaultPrecision,
+			lit(dtype.Float32, dims(2, 2), 22, 28, 49, 64)),
+		dotCase("Complex",
+			clit(dtype.Complex64, dims(2), 1+1i, 2), clit(dtype.Complex64, dims(2), 1i, 3-1i),
+			[2][]int{}, [2][]int{{0}, {0}}, ops.DefaultPrecision,
+			clit(dtype.Complex64, nil, 5-1i)),
+	)
+}
+
+func controlCases() []testCase {
+	i32 := shape.Scalar(dtype.Int32)
+	f32 := shape.Scalar(dtype.Float32)
+	vec := shape.Of(dtype.Float32, 2)
+	// loop returns a while loop multiplying its state by factor and adding offset
+	// while it is less than limit.
+	loop := func(sh *shape.Shape, init, limit, factor, offset float64) func(b *builder) ops.Node {
+		return func(b *builder) ops.Node {
+			cond := b.subgraph("cond", []*shape.Shape{sh}, shape.Scalar(dtype.Bool), func(sb *builder, args []ops.Node) ops.Node {
+				return sb.node(sb.core().BinaryOp(ops.Less, args[0], sb.constant(lit(sh.DType, nil, limit))))
+			})
+			body := b.subgraph("body", []*shape.Shape{sh}, sh, func(sb *builder, args []ops.Node) ops.Node {
+				mul := sb.node(sb.core().BinaryOp(ops.Mul, args[0], sb.constant(lit(sh.DType, nil, factor))))
+				return sb.node(sb.core().BinaryOp(ops.Add, mul, sb.constant(lit(sh.DType, nil, offset))))
+			})
+			return b.node(b.core().While(cond, body, b.constant(lit(sh.DType, nil, init))))
+		}
+	}
+	cond := func(pred bool) func(b *builder) ops.Node {
+		return func(b *builder) ops.Node {
+			branch := func(op ops.BinaryOperator) *ops.Subgraph {
+				return b.subgraph("branch", []*shape.Shape{vec}, vec, func(sb *builder, args []ops.Node) ops.Node {
+					return sb.node(sb.core().BinaryOp(op, args[0], args[0]))
+				})
+			}
+			x := b.constant(lit(dtype.Float32, dims(2), 2, 3))
+			return b.node(b.core().Cond(b.constant(bools(nil, pred)), branch(ops.Add), branch(ops.Mul), x))
+		}
+	}
+	sel := func(pred literal) func(b *builder) ops.Node {
+		return func(b *builder) ops.Node {
+			onTrue := b.constant(lit(dtype.Int32, dims(3), 1, 2, 3))
+			onFalse := b.constant(lit(dtype.Int32, dims(3), 4, 5, 6))
+			return b.node(b.core().Select(b.constant(pred), onTrue, onFalse))
+		}
+	}
+	return []testCase{
+		{
+			name:  "While/int32",
+			ops:   []ops.OpID{ops.OpWhile, ops.OpSubgraph, ops.OpBinary},
+			build: loop(i32, 0, 10, 2, 1),
+			want:  lit(dtype.Int32, nil, 15),
+		},
+		{
+			name:  "While/float32",
+			ops:   []ops.OpID{ops.OpWhile, ops.OpSubgraph, ops.OpBinary},
+			build: loop(f32, 1, 100, 3, 0),
+			want:  lit(dtype.Float32, nil, 243),
+		},
+		{
+			name:  "While/no_iteration",
+			ops:   []ops.OpID{ops.OpWhile, ops.OpSubgraph, ops.OpBinary},
+			build: loop(i32, 20, 10, 2, 1),
+			want:  lit(dtype.Int32, nil, 20),
+		},
+		{
+			name:  "Cond/true",
+			ops:   []ops.OpID{ops.OpCond, ops.OpSubgraph, ops.OpBinary},
+			build: cond(true),
+			want:  lit(dtype.Float32, dims(2), 4, 6),
+		},
+		{
+			name:  "Cond/false",
+			ops:   []ops.OpID{ops.OpCond, ops.OpSubgraph, ops.OpBinary},
+			build: cond(false),
+			want:  lit(dtype.Float32, dims(2), 4, 9),
+		},
+		{
+			name:  "Select/array",
+			ops:   []ops.OpID{ops.OpSelect},
+			build: sel(bools(dims(3), true, false, true)),
+			want:  lit(dtype.Int32, dims(3), 1, 5, 3),
+		},
+		{
+			name:  "Select/atomic",
+			ops:   []ops.OpID{ops.OpSelect},
+			build: sel(bools(nil, false)),
+			want:  lit(dtype.Int32, dims(3), 4, 5, 6),
+		},
+		{
+			name: "Call",
+			ops:  []ops.OpID{ops.OpCall, ops.OpSubgraph, ops.OpBinary},
+			build: func(b *builder) ops.Node {
+				fn := b.subgraph("fn", []*shape.Shape{vec, vec}, vec, func(sb *builder, args []ops.Node) ops.Node {
+					return sb.node(sb.core().BinaryOp(ops.Sub, args[0], args[1]))
+				})
+				return b.node(b.core().Call(fn, b.constant(lit(dtype.Float32, dims(2), 5, 7)), b.constant(lit(dtype.Float32, dims(2), 1, 2))))
+			},
+			want: lit(dtype.Float32, dims(2), 4, 5),
+		},
+	}
+}
+
+func quantizeCases() []testCase {
+	roundTrip := func(x literal, quant *dtype.Quantization, want literal) testCase {
+		return testCase{
+			name: fmt.Sprintf("Quantize/%s_%s", quant.Expressed, quant.Storage),
+			ops:  []ops.OpID{ops.OpQuantize, ops.OpDequantize},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().Dequantize(b.node(b.core().Quantize(b.constant(x), quant))))
+			},
+			want: want,
+			tol:  tolerance(want.dt),
+		}
+	}
+	return []testCase{
+		roundTrip(
+			lit(dtype.Float32, dims(5), -1, 0, 0.26, 1, 20),
+			&dtype.Quantization{Storage: dtype.Int8, Expressed: dtype.Float32, Axis: dtype.PerTensor, Scales: []float64{0.1}, ZeroPoints: []int64{0}},
+			lit(dtype.Float32, dims(5), -1, 0, 0.3, 1, 12.7)),
+		roundTrip(
+			lit(dtype.Float64, dims(5), 0, 1, -2.5, 0.25, -3),
+			&dtype.Quantization{Storage: dtype.Uint8, Expressed: dtype.Float64, Axis: dtype.PerTensor, Scales: []float64{0.5}, ZeroPoints: []int64{5}},
+			lit(dtype.Float64, dims(5), 0, 1, -2.5, 0, -2.5)),
+		roundTrip(
+			lit(dtype.Float32, dims(2, 2), 1.4, 2, 0.6, 2),
+			&dtype.Quantization{Storage: dtype.Int32, Expressed: dtype.Float32, Axis: 0, Scales: []float64{1, 0.5}, ZeroPoints: []int64{0, 0}},
+			lit(dtype.Float32, dims(2, 2), 1, 2, 0.5, 2)),
+	}
+}
+
+// reduceRef is a reduction with the results of the reduction of the [2, 3] array
+// of values from 1 to 6 along axes 0, 1 and both.
+type reduceRef struct {
+	name string
+	op   ops.OpID
+	fn   func(ops.CoreBuilder, ops.Node, []int) (ops.Node, error)
+	want [3][]float64
+}
+
+var reduceRefs = []reduceRef{
+	{"ReduceSum", ops.OpReduceSum, ops.CoreBuilder.ReduceSum, [3][]float64{{5, 7, 9}, {6, 15}, {21}}},
+	{"ReduceProd", ops.OpReduceProd, ops.CoreBuilder.ReduceProd, [3][]float64{{4, 10, 18}, {6, 120}, {720}}},
+	{"ReduceMax", ops.OpReduceMax, ops.CoreBuilder.ReduceMax, [3][]float64{{4, 5, 6}, {3, 6}, {6}}},
+	{"ReduceMin", ops.OpReduceMin, ops.CoreBuilder.ReduceMin, [3][]float64{{1, 2, 3}, {1, 4}, {1}}},
+}
+
+func reduceCase(name string, op ops.OpID, fn func(ops.CoreBuilder, ops.Node, []int) (ops.Node, error), x literal, axes []int, want literal) testCase {
+	return testCase{
+		name: name,
+		ops:  []ops.OpID{op},
+		build: func(b *builder) ops.Node {
+			return b.node(fn(b.core(), b.constant(x), axes))
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+func reduceCases() []testCase {
+	var cases []testCase
+	axes := [3][]int{{0}, {1}, {0, 1}}
+	wantDims := [3][]int{{3}, {2}, nil}
+	types := []dtype.DataType{dtype.Int32, dtype.Int64, dtype.Uint32, dtype.Float32, dtype.Float64, dtype.Bfloat16, dtype.Float16}
+	for _, ref := range reduceRefs {
+		for _, dt := range types {
+			for i := range axes {
+				name := fmt.Sprintf("%s/%s/%v", ref.name, dt, axes[i])
+				cases = append(cases, reduceCase(name, ref.op, ref.fn, lit(dt, dims(2, 3), iota(6)...), axes[i], lit(dt, wantDims[i], ref.want[i]...)))
+			}
+		}
+		x := lit(dtype.Float32, dims(2), 3, -1)
+		cases = append(cases, reduceCase(ref.name+"/no_axis", ref.op, ref.fn, x, nil, x))
+	}
+	nan, inf := math.NaN(), math.Inf(1)
+	anyAll := bools(dims(2, 2), true, false, false, false)
+	bodyCase := func(name string, op ops.BinaryOperator, init, x literal, axes []int, want literal) testCase {
+		return testCase{
+			name: "Reduce/" + name,
+			ops:  []ops.OpID{ops.OpReduce, ops.OpSubgraph, ops.OpBinary},
+			build: func(b *builder) ops.Node {
+				body := b.combiner(op, init.shape())
+				return b.node(b.core().Reduce(body, b.constant(init), b.constant(x), axes))
+			},
+			want: want,
+		}
+	}
+	return append(cases,
+		reduceCase("ReduceMax/NaN", ops.OpReduceMax, ops.CoreBuilder.ReduceMax, lit(dtype.Float32, dims(3), 1, nan, 2), dims(0), lit(dtype.Float32, nil, nan)),
+		reduceCase("ReduceMin/infinity", ops.OpReduceMin, ops.CoreBuilder.ReduceMin, lit(dtype.Float32, dims(3), 1, -inf, 2), dims(0), lit(dtype.Float32, nil, -inf)),
+		reduceCase("ReduceMax/bool", ops.OpReduceMax, ops.CoreBuilder.ReduceMax, anyAll, dims(1), bools(dims(2), true, false)),
+		reduceCase("ReduceMin/bool", ops.OpReduceMin, ops.CoreBuilder.ReduceMin, anyAll, dims(0), bools(dims(2), false, false)),
+		reduceCase("ReduceSum/int8_wrap_around", ops.OpReduceSum, ops.CoreBuilder.ReduceSum, lit(dtype.Int8, dims(2), 100, 100), dims(0), lit(dtype.Int8, nil, -56)),
+		reduceCase("ReduceSum/complex64", ops.OpReduceSum, ops.CoreBuilder.ReduceSum, clit(dtype.Complex64, dims(2), 1+2i, 3-1i), dims(0), clit(dtype.Complex64, nil, 4+1i)),
+		bodyCase("Add", ops.Add, lit(dtype.Int32, nil, 0), lit(dtype.Int32, dims(2, 3), iota(6)...), dims(1), lit(dtype.Int32, dims(2), 6, 15)),
+		bodyCase("Mul", ops.Mul, lit(dtype.Float32, nil, 1), lit(dtype.Float32, dims(2, 3), iota(6)...), dims(0, 1), lit(dtype.Float32, nil, 720)),
+		bodyCase("BitXor", ops.BitXor, lit(dtype.Uint8, nil, 0), lit(dtype.Uint8, dims(2, 3), iota(6)...), dims(0), lit(dtype.Uint8, dims(3), 5, 7, 5)),
+		bodyCase("LogicalOr", ops.LogicalOr, bools(nil, false), anyAll, dims(0), bools(dims(2), true, false)),
+		testCase{
+			name: "Reduce/Max",
+			ops:  []ops.OpID{ops.OpReduce, ops.OpSubgraph, ops.OpBinary, ops.OpSelect},
+			build: func(b *builder) ops.Node {
+				sh := shape.Scalar(dtype.Float32)
+				body := b.subgraph("max", []*shape.Shape{sh, sh}, sh, func(sb *builder, args []ops.Node) ops.Node {
+					greater := sb.node(sb.core().BinaryOp(ops.Greater, args[0], args[1]))
+					return sb.node(sb.core().Select(greater, args[0], args[1]))
+				})
+				x := b.constant(lit(dtype.Float32, dims(2, 3), 1, 5, -3, 4, 2, -6))
+				return b.node(b.core().Reduce(body, b.constant(lit(dtype.Float32, nil, -inf)), x, dims(0)))
+			},
+			want: lit(dtype.Float32, dims(3), 4, 5, -3),
+		},
+	)
+}
+
+func indexingCases() []testCase {
+	gather := func(name string, x, indices literal, dims ops.GatherDims, sliceSizes []int, want literal) testCase {
+		return testCase{
+			name: "Gather/" + name,
+			ops:  []ops.OpID{ops.OpGather},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().Gather(b.constant(x), b.constant(indices), dims, sliceSizes))
+			},
+			want: want,
+		}
+	}
+	scatter := func(name string, x, indices, updates literal, dims ops.ScatterDims, want literal) testCase {
+		return testCase{
+			name: "Scatter/" + name,
+			ops:  []ops.OpID{ops.OpScatter},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().Scatter(b.constant(x), b.constant(indices), b.constant(updates), dims))
+			},
+			want: want,
+		}
+	}
+	m32 := lit(dtype.Float32, dims(3, 2), iota(6)...)
+	vec := lit(dtype.Int32, dims(4), 10, 20, 30, 40)
+	return []testCase{
+		gather("rows", m32, lit(dtype.Int32, dims(2, 1), 2, 0),
+			ops.GatherDims{OffsetAxes: []int{1}, CollapsedAxes: []int{0}, StartIndexMap: []int{0}, IndexVectorAxis: 1},
+			dims(1, 2), lit(dtype.Float32, dims(2, 2), 5, 6, 1, 2)),
+		gather("elements", vec, lit(dtype.Int32, dims(3), 3, 0, 3),
+			ops.GatherDims{CollapsedAxes: []int{0}, StartIndexMap: []int{0}, IndexVectorAxis: 1},
+			dims(1), lit(dtype.Int32, dims(3), 40, 10, 40)),
+		gather("windows", vec, lit(dtype.Int64, dims(2, 1), 0, 2),
+			ops.GatherDims{OffsetAxes: []int{1}, StartIndexMap: []int{0}, IndexVectorAxis: 1},
+			dims(2), lit(dtype.Int32, dims(2, 2), 10, 20, 30, 40)),
+		gather("points", m32, lit(dtype.Int32, dims(2, 2), 1, 1, 2, 0),
+			ops.GatherDims{CollapsedAxes: []int{0, 1}, StartIndexMap: []int{0, 1}, IndexVectorAxis: 1},
+			dims(1, 1), lit(dtype.Float32, dims(2), 4, 5)),
+		scatter("rows", m32, lit(dtype.Int32, dims(1, 1), 1), lit(dtype.Float32, dims(1, 2), 9, 8),
+			ops.ScatterDims{UpdateWindowAxes: []int{1}, InsertedWindowAxes: []int{0}, ScatterAxesToOperandAxes: []int{0}, IndexVectorAxis: 1},
+			lit(dtype.Float32, dims(3, 2), 1, 2, 9, 8, 5, 6)),
+		scatter("elements", vec, lit(dtype.Int32, dims(2), 0, 3), lit(dtype.Int32, dims(2), 7, 9),
+			ops.ScatterDims{InsertedWindowAxes: []int{0}, ScatterAxesToOperandAxes: []int{0}, IndexVectorAxis: 1},
+			lit(dtype.Int32, dims(4), 7, 20, 30, 9)),
+		scatter("points", m32, lit(dtype.Int32, dims(2, 2), 0, 1, 2, 0), lit(dtype.Float32, dims(2), -1, -2),
+			ops.ScatterDims{InsertedWindowAxes: []int{0, 1}, ScatterAxesToOperandAxes: []int{0, 1}, IndexVectorAxis: 1},
+			lit(dtype.Float32, dims(3, 2), 1, -1, 3, 4, -2, 6)),
+	}
+}
+
+func sortCases() []testCase {
+	nan, inf := math.NaN(), math.Inf(1)
+	sort := func(name string, x literal, axis int, descending bool, want literal) testCase {
+		return testCase{
+			name: "Sort/" + name,
+			ops:  []ops.OpID{ops.OpSort},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().Sort(b.constant(x), axis, descending))
+			},
+			want: want,
+		}
+	}
+	argSort := func(name string, x literal, axis int, descending bool, want literal) testCase {
+		return testCase{
+			name: "ArgSort/" + name,
+			ops:  []ops.OpID{ops.OpArgSort},
+			build: func(b *builder) ops.Node {
+				return b.node(b.core().ArgSort(b.constant(x), axis, descending))
+			},
+			want: want,
+		}
+	}
+	topK := func(name string, x literal, k, element int, want literal) testCase {
+		return testCase{
+			name: "TopK/" + name,
+			ops:  []ops.OpID{ops.OpTopK},
+			build: func(b *builder) ops.Node {
+				tpl, err := b.core().TopK(b.constant(x), k)
+				if err != nil {
+					return b.node(nil, err)
+				}
+				return b.element(tpl, element)
+			},
+			want: want,
+		}
+	}
+	floats := lit(dtype.Float32, dims(5), 3, -1, nan, 2, -inf)
+	m23 := lit(dtype.Int32, dims(2, 3), 3, 1, 2, 0, 5, 4)
+	ties := lit(dtype.Int32, dims(4), 3, 1, 2, 1)
+	topKInput := lit(dtype.Float32, dims(2, 3), 3, nan, -1, 2, 2, 5)
+	return []testCase{
+		sort("ascending", floats, 0, false, lit(dtype.Float32, dims(5), -inf, -1, 2, 3, nan)),
+		sort("descending", floats, 0, true, lit(dtype.Float32, dims(5), nan, 3, 2, -1, -inf)),
+		sort("float64", lit(dtype.Float64, dims(3), 0.5, -0.25, 0), 0, false, lit(dtype.Float64, dims(3), -0.25, 0, 0.5)),
+		sort("uint8", lit(dtype.Uint8, dims(3), 200, 3, 100), 0, false, lit(dtype.Uint8, dims(3), 3, 100, 200)),
+		sort("axis1", m23, 1, false, lit(dtype.Int32, dims(2, 3), 1, 2, 3, 0, 4, 5)),
+		sort("axis0", m23, 0, false, lit(dtype.Int32, dims(2, 3), 0, 1, 2, 3, 5, 4)),
+		sort("negative_axis", m23, -1, true, lit(dtype.Int32, dims(2, 3), 3, 2, 1, 5, 4, 0)),
+		argSort("ascending", ties, 0, false, lit(dtype.Int32, dims(4), 1, 3, 2, 0)),
+		argSort("descending", ties, 0, true, lit(dtype.Int32, dims(4), 0, 2, 1, 3)),
+		argSort("NaN", lit(dtype.Float32, dims(3), nan, 1, 0), 0, false, lit(dtype.Int32, dims(3), 2, 1, 0)),
+		argSort("axis0", m23, 0, false, lit(dtype.Int32, dims(2, 3), 1, 0, 0, 0, 1, 1)),
+		topK("values", topKInput, 2, 0, lit(dtype.Float32, dims(2, 2), nan, 3, 5, 2)),
+		topK("indices", topKInput, 2, 1, lit(dtype.Int32, dims(2, 2), 1, 0, 2, 0)),
+		topK("all", lit(dtype.Int64, dims(3), 1, 3, 2), 3, 0, lit(dtype.Int64, dims(3), 3, 2, 1)),
+	}
+}
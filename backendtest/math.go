@@ -0,0 +1,150 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+)
+
+// mathRef is a function of the math builder with a function computing reference results.
+type mathRef struct {
+	name string
+	op   ops.OpID
+	fn   func(ops.MathBuilder, ops.Node) (ops.Node, error)
+	ref  func(float64) float64
+	x    []float64
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return x
+}
+
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func rsqrt(x float64) float64 {
+	return 1 / math.Sqrt(x)
+}
+
+var mathRefs = []mathRef{
+	{"Abs", ops.OpAbs, ops.MathBuilder.Abs, math.Abs, []float64{-1.5, 0, 2, math.Inf(-1)}},
+	{"Ceil", ops.OpCeil, ops.MathBuilder.Ceil, math.Ceil, []float64{-1.5, 0.25, 2, -0.5}},
+	{"Cos", ops.OpCos, ops.MathBuilder.Cos, math.Cos, []float64{0, 0.5, -1, 3}},
+	{"Erf", ops.OpErf, ops.MathBuilder.Erf, math.Erf, []float64{-1, 0, 0.5, 2}},
+	{"Exp", ops.OpExp, ops.MathBuilder.Exp, math.Exp, []float64{-1, 0, 0.5, 2, math.Inf(-1)}},
+	{"Expm1", ops.OpExpm1, ops.MathBuilder.Expm1, math.Expm1, []float64{-1, 0, 0.001, 1}},
+	{"Floor", ops.OpFloor, ops.MathBuilder.Floor, math.Floor, []float64{-1.5, 0.25, 2, -0.5}},
+	{"Log", ops.OpLog, ops.MathBuilder.Log, math.Log, []float64{0.5, 1, 2, 0, -1}},
+	{"Log1p", ops.OpLog1p, ops.MathBuilder.Log1p, math.Log1p, []float64{-0.5, 0, 0.001, 1}},
+	{"Logistic", ops.OpLogistic, ops.MathBuilder.Logistic, logistic, []float64{-2, 0, 0.5, 3}},
+	{"Round", ops.OpRound, ops.MathBuilder.Round, math.Round, []float64{-2.5, -0.5, 0.5, 1.5, 2.5, 0.25}},
+	{"Rsqrt", ops.OpRsqrt, ops.MathBuilder.Rsqrt, rsqrt, []float64{0.25, 1, 4, 2}},
+	{"Sign", ops.OpSign, ops.MathBuilder.Sign, sign, []float64{-3, 0, 2, -0.5}},
+	{"Sin", ops.OpSin, ops.MathBuilder.Sin, math.Sin, []float64{0, 0.5, -1, 3}},
+	{"Sqrt", ops.OpSqrt, ops.MathBuilder.Sqrt, math.Sqrt, []float64{0, 1, 2, 4, -1}},
+	{"Tanh", ops.OpTanh, ops.MathBuilder.Tanh, math.Tanh, []float64{-1, 0, 0.5, 20}},
+}
+
+func mathCase(name string, op ops.OpID, build func(b *builder) ops.Node, want literal) testCase {
+	return testCase{
+		name:  name,
+		ops:   []ops.OpID{op},
+		build: build,
+		want:  want,
+		tol:   tolerance(want.dt),
+	}
+}
+
+func mathUnaryCase(name string, op ops.OpID, fn func(ops.MathBuilder, ops.Node) (ops.Node, error), x, want literal) testCase {
+	return mathCase(fmt.Sprintf("%s/%s", name, x.dt), op, func(b *builder) ops.Node {
+		return b.node(fn(b.g.Math(), b.constant(x)))
+	}, want)
+}
+
+func mathBinaryCase(name string, op ops.OpID, fn func(ops.MathBuilder, ops.Node, ops.Node) (ops.Node, error), x, y, want literal) testCase {
+	return mathCase(fmt.Sprintf("%s/%s", name, x.dt), op, func(b *builder) ops.Node {
+		return b.node(fn(b.g.Math(), b.constant(x), b.constant(y)))
+	}, want)
+}
+
+func mathCases() []testCase {
+	var cases []testCase
+	for _, ref := range mathRefs {
+		for _, dt := range slices.Concat(floatTypes, halfTypes) {
+			n := dims(len(ref.x))
+			cases = append(cases, mathUnaryCase(ref.name, ref.op, ref.fn, lit(dt, n, ref.x...), lit(dt, n, apply(ref.x, ref.ref)...)))
+		}
+	}
+	nan, inf := math.NaN(), math.Inf(1)
+	special := []float64{1, inf, -inf, nan}
+	for _, dt := range floatTypes {
+		cases = append(cases,
+			mathUnaryCase("IsInf", ops.OpIsInf, ops.MathBuilder.IsInf, lit(dt, dims(4), special...), bools(dims(4), false, true, true, false)),
+			mathUnaryCase("IsNaN", ops.OpIsNaN, ops.MathBuilder.IsNaN, lit(dt, dims(4), special...), bools(dims(4), false, false, false, true)),
+		)
+		px, py := []float64{2, 4, 9, 2, 0}, []float64{3, 0.5, 0.5, -1, 0}
+		ay, ax := []float64{1, -1, 0, 1, 1}, []float64{1, -1, -1, 0, -inf}
+		cases = append(cases,
+			mathBinaryCase("Pow", ops.OpPow, ops.MathBuilder.Pow, lit(dt, dims(5), px...), lit(dt, dims(5), py...), lit(dt, dims(5), apply2(px, py, math.Pow)...)),
+			mathBinaryCase("Pow/atomic", ops.OpPow, ops.MathBuilder.Pow, lit(dt, dims(3), 1, 2, 3), lit(dt, nil, 2), lit(dt, dims(3), 1, 4, 9)),
+			mathBinaryCase("Atan2", ops.OpAtan2, ops.MathBuilder.Atan2, lit(dt, dims(5), ay...), lit(dt, dims(5), ax...), lit(dt, dims(5), apply2(ay, ax, math.Atan2)...)),
+		)
+	}
+	for _, dt := range signedTypes {
+		cases = append(cases,
+			mathUnaryCase("Abs", ops.OpAbs, ops.MathBuilder.Abs, lit(dt, dims(3), -3, 4, 0), lit(dt, dims(3), 3, 4, 0)),
+			mathUnaryCase("Sign", ops.OpSign, ops.MathBuilder.Sign, lit(dt, dims(3), -3, 0, 5), lit(dt, dims(3), -1, 0, 1)),
+		)
+	}
+	return append(cases, complexCases()...)
+}
+
+func complexCases() []testCase {
+	var cases []testCase
+	parts := map[dtype.DataType]dtype.DataType{dtype.Complex64: dtype.Float32, dtype.Complex128: dtype.Float64}
+	x := []complex128{3 + 4i, -5, 2i}
+	conj := make([]complex128, len(x))
+	var re, im, abs []float64
+	for i, v := range x {
+		conj[i] = cmplx.Conj(v)
+		re, im, abs = append(re, real(v)), append(im, imag(v)), append(abs, cmplx.Abs(v))
+	}
+	for _, dt := range complexTypes {
+		part := parts[dt]
+		n := dims(len(x))
+		cases = append(cases,
+			mathBinaryCase("Complex", ops.OpComplex, ops.MathBuilder.Complex, lit(part, n, re...), lit(part, n, im...), clit(dt, n, x...)),
+			mathBinaryCase("Complex/atomic", ops.OpComplex, ops.MathBuilder.Complex, lit(part, n, re...), lit(part, nil, 1), clit(dt, n, 3+1i, -5+1i, 1i)),
+			mathUnaryCase("Real", ops.OpReal, ops.MathBuilder.Real, clit(dt, n, x...), lit(part, n, re...)),
+			mathUnaryCase("Imag", ops.OpImag, ops.MathBuilder.Imag, clit(dt, n, x...), lit(part, n, im...)),
+			mathUnaryCase("Conj", ops.OpConj, ops.MathBuilder.Conj, clit(dt, n, x...), clit(dt, n, conj...)),
+			mathUnaryCase("Abs", ops.OpAbs, ops.MathBuilder.Abs, clit(dt, n, x...), lit(part, n, abs...)),
+		)
+	}
+	return cases
+}
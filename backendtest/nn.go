@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+)
+
+// conv are the parameters of a convolution.
+type conv struct {
+	strides        []int
+	padding        [][2]int
+	inputDilation  []int
+	kernelDilation []int
+	groups         int
+}
+
+func convCase(name string, x, kernel literal, dims ops.ConvDims, params conv, want literal) testCase {
+	if params.groups == 0 {
+		params.groups = 1
+	}
+	return testCase{
+		name: "ConvGeneralDilated/" + name,
+		ops:  []ops.OpID{ops.OpConvGeneralDilated},
+		build: func(b *builder) ops.Node {
+			return b.node(b.g.NN().ConvGeneralDilated(b.constant(x), b.constant(kernel), dims,
+				params.strides, params.padding, params.inputDilation, params.kernelDilation, params.groups, ops.DefaultPrecision))
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+func windowCase(name string, op ops.OpID, fn func(ops.NNBuilder, ops.Node, ops.Window) (ops.Node, error), x literal, window ops.Window, want literal) testCase {
+	return testCase{
+		name: name,
+		ops:  []ops.OpID{op},
+		build: func(b *builder) ops.Node {
+			return b.node(fn(b.g.NN(), b.constant(x), window))
+		},
+		want: want,
+		tol:  tolerance(want.dt),
+	}
+}
+
+func reduceWindowCase(name string, op ops.BinaryOperator, init, x literal, window ops.Window, want literal) testCase {
+	return testCase{
+		name: "ReduceWindow/" + name,
+		ops:  []ops.OpID{ops.OpReduceWindow, ops.OpSubgraph, ops.OpBinary},
+		build: func(b *builder) ops.Node {
+			body := b.combiner(op, init.shape())
+			return b.node(b.g.NN().ReduceWindow(body, b.constant(init), b.constant(x), window))
+		},
+		want: want,
+	}
+}
+
+func nnCases() []testCase {
+	f32 := func(axisLengths []int, vals ...float64) literal { return lit(dtype.Float32, axisLengths, vals...) }
+	seq := f32(dims(1, 4, 1), 1, 2, 3, 4)
+	pair := f32(dims(2, 1, 1), 1, 1)
+	nhwc1, nhwc2 := ops.NHWC(1), ops.NHWC(2)
+	m44 := f32(dims(4, 4), iota(16)...)
+	pool := ops.Window{Dimensions: dims(2, 2), Strides: dims(2, 2)}
+	padded := ops.Window{Dimensions: dims(2), Strides: dims(1), Padding: [][2]int{{1, 0}}}
+	i32 := lit(dtype.Int32, dims(4), 1, 2, 3, 4)
+	zero := lit(dtype.Int32, nil, 0)
+	return []testCase{
+		convCase("valid", seq, pair, nhwc1, conv{}, f32(dims(1, 3, 1), 3, 5, 7)),
+		convCase("padding", seq, pair, nhwc1, conv{padding: [][2]int{{1, 1}}}, f32(dims(1, 5, 1), 1, 3, 5, 7, 4)),
+		convCase("strides", seq, pair, nhwc1, conv{strides: dims(2)}, f32(dims(1, 2, 1), 3, 7)),
+		convCase("kernel_dilation", seq, pair, nhwc1, conv{kernelDilation: dims(2)}, f32(dims(1, 2, 1), 4, 6)),
+		convCase("input_dilation", f32(dims(1, 3, 1), 1, 2, 3), pair, nhwc1, conv{inputDilation: dims(2)}, f32(dims(1, 4, 1), 1, 2, 2, 3)),
+		convCase("2d", f32(dims(1, 3, 3, 1), iota(9)...), f32(dims(2, 2, 1, 1), 1, 1, 1, 1), nhwc2, conv{}, f32(dims(1, 2, 2, 1), 12, 16, 24, 28)),
+		convCase("output_features", f32(dims(1, 3, 1), 1, 2, 3), f32(dims(1, 1, 2), 1, -1), nhwc1, conv{}, f32(dims(1, 3, 2), 1, -1, 2, -2, 3, -3)),
+		convCase("input_features", f32(dims(1, 2, 2), 1, 2, 3, 4), f32(dims(1, 2, 1), 10, 1), nhwc1, conv{}, f32(dims(1, 2, 1), 12, 34)),
+		convCase("feature_groups", f32(dims(1, 1, 2), 1, 2), f32(dims(1, 1, 2), 3, 4), nhwc1, conv{groups: 2}, f32(dims(1, 1, 2), 3, 8)),
+		convCase("batch", f32(dims(2, 2, 1), 1, 2, 3, 4), f32(dims(1, 1, 1), 2), nhwc1, conv{}, f32(dims(2, 2, 1), 2, 4, 6, 8)),
+		convCase("float64", lit(dtype.Float64, dims(1, 4, 1), 1, 2, 3, 4), lit(dtype.Float64, dims(2, 1, 1), 0.5, -1), nhwc1, conv{}, lit(dtype.Float64, dims(1, 3, 1), -1.5, -2, -2.5)),
+		windowCase("MaxPool/2d", ops.OpMaxPool, ops.NNBuilder.MaxPool, m44, pool, f32(dims(2, 2), 6, 8, 14, 16)),
+		windowCase("MaxPool/padding", ops.OpMaxPool, ops.NNBuilder.MaxPool, f32(dims(3), -3, -6, -9), padded, f32(dims(3), -3, -3, -6)),
+		windowCase("MaxPool/int32", ops.OpMaxPool, ops.NNBuilder.MaxPool, i32, ops.Window{Dimensions: dims(3)}, lit(dtype.Int32, dims(2), 3, 4)),
+		windowCase("AvgPool/2d", ops.OpAvgPool, ops.NNBuilder.AvgPool, m44, pool, f32(dims(2, 2), 3.5, 5.5, 11.5, 13.5)),
+		windowCase("AvgPool/padding", ops.OpAvgPool, ops.NNBuilder.AvgPool, f32(dims(3), 3, 6, 9), padded, f32(dims(3), 1.5, 4.5, 7.5)),
+		reduceWindowCase("sum", ops.Add, zero, i32, ops.Window{Dimensions: dims(2)}, lit(dtype.Int32, dims(3), 3, 5, 7)),
+		reduceWindowCase("strides", ops.Add, zero, i32, ops.Window{Dimensions: dims(2), Strides: dims(2)}, lit(dtype.Int32, dims(2), 3, 7)),
+		reduceWindowCase("window_dilation", ops.Add, zero, i32, ops.Window{Dimensions: dims(2), WindowDilations: dims(2)}, lit(dtype.Int32, dims(2), 4, 6)),
+		reduceWindowCase("base_dilation", ops.Add, zero, i32, ops.Window{Dimensions: dims(2), BaseDilations: dims(2)}, lit(dtype.Int32, dims(6), 1, 2, 2, 3, 3, 4)),
+		reduceWindowCase("padding", ops.Mul, f32(nil, 1), f32(dims(3), 2, 3, 4), ops.Window{Dimensions: dims(2), Padding: [][2]int{{1, 1}}}, f32(dims(4), 2, 6, 12, 4)),
+		reduceWindowCase("bit_and", ops.BitAnd, lit(dtype.Uint8, nil, math.MaxUint8), lit(dtype.Uint8, dims(2, 3), 7, 6, 3, 5, 4, 2), ops.Window{Dimensions: dims(2, 2)}, lit(dtype.Uint8, dims(1, 2), 4, 0)),
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+func numCases() []testCase {
+	iotaCase := func(sh *shape.Shape, axis int, want ...float64) testCase {
+		return testCase{
+			name: fmt.Sprintf("Iota/%s/axis%d", sh.DType, axis),
+			ops:  []ops.OpID{ops.OpIota},
+			build: func(b *builder) ops.Node {
+				return b.node(b.g.Num().Iota(sh, axis))
+			},
+			want: lit(sh.DType, sh.AxisLengths, want...),
+		}
+	}
+	axisCase := func(name string, op ops.OpID, fn func(ops.NumBuilder, ops.Node, int) (ops.Node, error), x literal, axis int, want literal) testCase {
+		return testCase{
+			name: fmt.Sprintf("%s/%s/axis%d", name, x.dt, axis),
+			ops:  []ops.OpID{op},
+			build: func(b *builder) ops.Node {
+				return b.node(fn(b.g.Num(), b.constant(x), axis))
+			},
+			want: want,
+			tol:  tolerance(want.dt),
+		}
+	}
+	nan := math.NaN()
+	floats := lit(dtype.Float32, dims(2, 3), 1, 5, 5, 7, 2, nan)
+	ints := lit(dtype.Int32, dims(2, 3), 3, 1, 3, 0, 0, 2)
+	cases := []testCase{
+		iotaCase(shape.Of(dtype.Int32, 2, 3), 1, 0, 1, 2, 0, 1, 2),
+		iotaCase(shape.Of(dtype.Int32, 2, 3), 0, 0, 0, 0, 1, 1, 1),
+		iotaCase(shape.Of(dtype.Float32, 4), 0, 0, 1, 2, 3),
+		iotaCase(shape.Of(dtype.Uint8, 3), 0, 0, 1, 2),
+		iotaCase(shape.Of(dtype.Int64, 2, 2, 2), 1, 0, 0, 1, 1, 0, 0, 1, 1),
+		axisCase("Argmax", ops.OpArgmax, ops.NumBuilder.Argmax, floats, 1, lit(dtype.Int32, dims(2), 1, 2)),
+		axisCase("Argmax", ops.OpArgmax, ops.NumBuilder.Argmax, floats, 0, lit(dtype.Int32, dims(3), 1, 0, 1)),
+		axisCase("Argmax", ops.OpArgmax, ops.NumBuilder.Argmax, ints, 1, lit(dtype.Int32, dims(2), 0, 2)),
+		axisCase("Argmin", ops.OpArgmin, ops.NumBuilder.Argmin, floats, 1, lit(dtype.Int32, dims(2), 0, 1)),
+		axisCase("Argmin", ops.OpArgmin, ops.NumBuilder.Argmin, floats, 0, lit(dtype.Int32, dims(3), 0, 1, 0)),
+		axisCase("Argmin", ops.OpArgmin, ops.NumBuilder.Argmin, ints, 1, lit(dtype.Int32, dims(2), 1, 0)),
+	}
+	for _, dt := range []dtype.DataType{dtype.Int32, dtype.Int64, dtype.Float32, dtype.Float64} {
+		x := lit(dt, dims(2, 3), iota(6)...)
+		cases = append(cases,
+			axisCase("CumSum", ops.OpCumSum, ops.NumBuilder.CumSum, x, 1, lit(dt, dims(2, 3), 1, 3, 6, 4, 9, 15)),
+			axisCase("CumSum", ops.OpCumSum, ops.NumBuilder.CumSum, x, 0, lit(dt, dims(2, 3), 1, 2, 3, 5, 7, 9)),
+			axisCase("CumProd", ops.OpCumProd, ops.NumBuilder.CumProd, x, 1, lit(dt, dims(2, 3), 1, 2, 6, 4, 20, 120)),
+			axisCase("CumProd", ops.OpCumProd, ops.NumBuilder.CumProd, x, 0, lit(dt, dims(2, 3), 1, 2, 3, 4, 10, 18)),
+		)
+	}
+	return cases
+}
+
+func dtypeCases() []testCase {
+	bitcast := func(x, want literal) testCase {
+		return testCase{
+			name: fmt.Sprintf("Bitcast/%s_to_%s", x.dt, want.dt),
+			ops:  []ops.OpID{ops.OpBitcast},
+			build: func(b *builder) ops.Node {
+				return b.node(b.g.DType().Bitcast(b.constant(x), want.dt))
+			},
+			want: want,
+		}
+	}
+	return []testCase{
+		bitcast(lit(dtype.Float32, dims(2), 1, -2), lit(dtype.Uint32, dims(2), 0x3f800000, 0xc0000000)),
+		bitcast(lit(dtype.Uint32, dims(2), 0x3f800000, 0xc0000000), lit(dtype.Float32, dims(2), 1, -2)),
+		bitcast(lit(dtype.Int32, dims(2), -1, 1), lit(dtype.Uint32, dims(2), math.MaxUint32, 1)),
+		bitcast(lit(dtype.Float64, dims(1), 1), lit(dtype.Int64, dims(1), 0x3ff0000000000000)),
+		bitcast(lit(dtype.Int64, dims(1), 0x3ff0000000000000), lit(dtype.Float64, dims(1), 1)),
+		bitcast(lit(dtype.Float16, dims(2), 1, -2), lit(dtype.Uint16, dims(2), 0x3c00, 0xc000)),
+		bitcast(lit(dtype.Bfloat16, dims(2), 1, -2), lit(dtype.Int16, dims(2), 0x3f80, -0x4000)),
+		bitcast(lit(dtype.Uint8, dims(2), 255, 1), lit(dtype.Int8, dims(2), -1, 1)),
+	}
+}
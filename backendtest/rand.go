@@ -0,0 +1,140 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backendtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// Random values depend on the backend: cases check their statistical properties and
+// that the same state always generates the same values.
+
+const numSamples = 1024
+
+var seed = lit(dtype.Uint64, dims(2), 42, 7)
+
+// generator generates random values of a shape from a state.
+type generator struct {
+	name string
+	op   ops.OpID
+	gen  func(b *builder, sh *shape.Shape, state ops.Node) ops.Node
+}
+
+func uniform(b *builder, sh *shape.Shape, state ops.Node) ops.Node {
+	return b.node(b.g.Rand().Uniform(sh, state))
+}
+
+func normal(b *builder, sh *shape.Shape, state ops.Node) ops.Node {
+	return b.node(b.g.Rand().Normal(sh, state))
+}
+
+func bits(algorithm ops.RngAlgorithm) func(b *builder, sh *shape.Shape, state ops.Node) ops.Node {
+	return func(b *builder, sh *shape.Shape, state ops.Node) ops.Node {
+		return b.node(b.g.Rand().RngBitGenerator(algorithm, state, sh))
+	}
+}
+
+// all returns true if all the elements of a boolean array are true.
+func (b *builder) all(x ops.Node) ops.Node {
+	return b.node(b.core().ReduceMin(x, []int{0}))
+}
+
+// mean returns the mean of the elements of a vector.
+func (b *builder) mean(x ops.Node, dt dtype.DataType) ops.Node {
+	sum := b.node(b.core().ReduceSum(x, []int{0}))
+	return b.node(b.core().BinaryOp(ops.Div, sum, b.constant(lit(dt, nil, numSamples))))
+}
+
+// propertyCase returns a case checking a property of the values generated by gen.
+func propertyCase(name string, gen generator, sh *shape.Shape, prop func(b *builder, state, vals ops.Node) ops.Node, want literal, tol float64, extra ...ops.OpID) testCase {
+	return testCase{
+		name: fmt.Sprintf("%s/%s/%s", gen.name, sh.DType, name),
+		ops:  append([]ops.OpID{gen.op, ops.OpTuple}, extra...),
+		build: func(b *builder) ops.Node {
+			state := b.constant(seed)
+			tpl := gen.gen(b, sh, state)
+			return prop(b, state, tpl)
+		},
+		want: want,
+		tol:  tol,
+	}
+}
+
+func randCases() []testCase {
+	var cases []testCase
+	// deterministic compares two generations from the same state.
+	deterministic := func(gen generator, sh *shape.Shape) testCase {
+		return propertyCase("deterministic", gen, sh, func(b *builder, state, first ops.Node) ops.Node {
+			second := gen.gen(b, sh, state)
+			eq := b.node(b.core().BinaryOp(ops.Equal, b.element(first, 1), b.element(second, 1)))
+			return b.all(eq)
+		}, bools(nil, true), 0, ops.OpBinary, ops.OpReduceMin)
+	}
+	// nextState checks that the next state differs from the state.
+	nextState := func(gen generator, sh *shape.Shape) testCase {
+		return propertyCase("next_state", gen, sh, func(b *builder, state, tpl ops.Node) ops.Node {
+			ne := b.node(b.core().BinaryOp(ops.NotEqual, b.element(tpl, 0), state))
+			return b.node(b.core().ReduceMax(ne, []int{0}))
+		}, bools(nil, true), 0, ops.OpBinary, ops.OpReduceMax)
+	}
+	floatGens := []generator{{"Uniform", ops.OpUniform, uniform}, {"Normal", ops.OpNormal, normal}}
+	for _, gen := range floatGens {
+		for _, dt := range floatTypes {
+			sh := shape.Of(dt, numSamples)
+			cases = append(cases, deterministic(gen, sh), nextState(gen, sh))
+		}
+	}
+	for _, dt := range floatTypes {
+		sh := shape.Of(dt, numSamples)
+		uni, norm := floatGens[0], floatGens[1]
+		cases = append(cases,
+			propertyCase("range", uni, sh, func(b *builder, _, tpl ops.Node) ops.Node {
+				vals := b.element(tpl, 1)
+				lower := b.node(b.core().BinaryOp(ops.GreaterEqual, vals, b.constant(lit(dt, nil, 0))))
+				upper := b.node(b.core().BinaryOp(ops.Less, vals, b.constant(lit(dt, nil, 1))))
+				return b.all(b.node(b.core().BinaryOp(ops.LogicalAnd, lower, upper)))
+			}, bools(nil, true), 0, ops.OpBinary, ops.OpReduceMin),
+			propertyCase("mean", uni, sh, func(b *builder, _, tpl ops.Node) ops.Node {
+				return b.mean(b.element(tpl, 1), dt)
+			}, lit(dt, nil, 0.5), 0.05, ops.OpBinary, ops.OpReduceSum),
+			propertyCase("mean", norm, sh, func(b *builder, _, tpl ops.Node) ops.Node {
+				return b.mean(b.element(tpl, 1), dt)
+			}, lit(dt, nil, 0), 0.15, ops.OpBinary, ops.OpReduceSum),
+			propertyCase("variance", norm, sh, func(b *builder, _, tpl ops.Node) ops.Node {
+				vals := b.element(tpl, 1)
+				return b.mean(b.node(b.core().BinaryOp(ops.Mul, vals, vals)), dt)
+			}, lit(dt, nil, 1), 0.15, ops.OpBinary, ops.OpReduceSum),
+		)
+	}
+	for _, algorithm := range []ops.RngAlgorithm{ops.DefaultRng, ops.ThreeFryRng, ops.PhiloxRng} {
+		gen := generator{"RngBitGenerator/" + algorithm.String(), ops.OpRngBitGenerator, bits(algorithm)}
+		for _, dt := range []dtype.DataType{dtype.Uint32, dtype.Uint64} {
+			sh := shape.Of(dt, numSamples)
+			cases = append(cases, deterministic(gen, sh), nextState(gen, sh))
+		}
+		sh := shape.Of(dtype.Uint32, numSamples)
+		cases = append(cases, propertyCase("mean", gen, sh, func(b *builder, _, tpl ops.Node) ops.Node {
+			vals := b.node(b.core().Cast(b.element(tpl, 1), dtype.Float64))
+			scaled := b.node(b.core().BinaryOp(ops.Div, vals, b.constant(lit(dtype.Float64, nil, math.MaxUint32))))
+			return b.mean(scaled, dtype.Float64)
+		}, lit(dtype.Float64, nil, 0.5), 0.05, ops.OpBinary, ops.OpCast, ops.OpReduceSum))
+	}
+	return cases
+}
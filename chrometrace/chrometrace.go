@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chrometrace converts profiler records into the Chrome trace event format,
+// which can be opened with chrome://tracing, Perfetto (ui.perfetto.dev) and TensorBoard.
+//
+// Each device is a process of the trace with one track for kernels and one track
+// for transfers. Host activities are in a separate process.
+package chrometrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+)
+
+type (
+	// Event is an event of the Chrome trace event format.
+	Event struct {
+		Name string `json:"name"`
+		// Cat is the category of the event.
+		Cat string `json:"cat,omitempty"`
+		// Ph is the phase of the event: "X" for complete events, "M" for metadata.
+		Ph string `json:"ph"`
+		// Ts is the start of the event, in microseconds.
+		Ts float64 `json:"ts"`
+		// Dur is the duration of the event, in microseconds.
+		Dur  float64        `json:"dur,omitempty"`
+		Pid  int            `json:"pid"`
+		Tid  int            `json:"tid"`
+		Args map[string]any `json:"args,omitempty"`
+	}
+
+	// Trace is a document of the Chrome trace event format.
+	Trace struct {
+		TraceEvents     []Event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}
+)
+
+// Process and thread identifiers of the trace.
+const (
+	hostPid = 0
+
+	kernelTid   = 0
+	transferTid = 1
+	hostTid     = 0
+)
+
+// pid returns the process identifier of a device.
+func pid(device int) int {
+	if device < 0 {
+		return hostPid
+	}
+	return device + 1
+}
+
+// NewTrace converts profiler records into a trace.
+// Timestamps are relative to the start of the earliest record.
+func NewTrace(records []platform.ProfileRecord) *Trace {
+	trace := &Trace{TraceEvents: []Event{}, DisplayTimeUnit: "ns"}
+	if len(records) == 0 {
+		return trace
+	}
+	origin := records[0].Start
+	var devices []int
+	for _, rec := range records {
+		if rec.Start.Before(origin) {
+			origin = rec.Start
+		}
+		dev := rec.Device
+		if rec.Kind == platform.HostRecord {
+			dev = -1
+		}
+		if !slices.Contains(devices, dev) {
+			devices = append(devices, dev)
+		}
+	}
+	slices.Sort(devices)
+	for _, dev := range devices {
+		trace.TraceEvents = append(trace.TraceEvents, metadataEvents(dev)...)
+	}
+	for _, rec := range records {
+		trace.TraceEvents = append(trace.TraceEvents, recordEvent(rec, origin))
+	}
+	return trace
+}
+
+// metadataEvents names the process and tracks of a device.
+func metadataEvents(dev int) []Event {
+	if dev < 0 {
+		return []Event{
+			{Name: "process_name", Ph: "M", Pid: hostPid, Args: map[string]any{"name": "host"}},
+			{Name: "thread_name", Ph: "M", Pid: hostPid, Tid: hostTid, Args: map[string]any{"name": "host"}},
+		}
+	}
+	return []Event{
+		{Name: "process_name", Ph: "M", Pid: pid(dev), Args: map[string]any{"name": fmt.Sprintf("device %d", dev)}},
+		{Name: "process_sort_index", Ph: "M", Pid: pid(dev), Args: map[string]any{"sort_index": pid(dev)}},
+		{Name: "thread_name", Ph: "M", Pid: pid(dev), Tid: kernelTid, Args: map[string]any{"name": "kernels"}},
+		{Name: "thread_name", Ph: "M", Pid: pid(dev), Tid: transferTid, Args: map[string]any{"name": "transfers"}},
+	}
+}
+
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+func recordEvent(rec platform.ProfileRecord, origin time.Time) Event {
+	ev := Event{
+		Name: rec.Name,
+		Cat:  rec.Kind.String(),
+		Ph:   "X",
+		Ts:   micros(rec.Start.Sub(origin)),
+		Dur:  micros(rec.Duration()),
+		Pid:  pid(rec.Device),
+	}
+	if ev.Name == "" {
+		ev.Name = rec.Kind.String()
+	}
+	switch rec.Kind {
+	case platform.KernelRecord:
+		ev.Tid = kernelTid
+	case platform.HostRecord:
+		ev.Pid, ev.Tid = hostPid, hostTid
+	default:
+		ev.Tid = transferTid
+		ev.Args = map[string]any{"bytes": rec.Bytes}
+	}
+	return ev
+}
+
+// Write converts profiler records into a trace and writes it as JSON.
+func Write(w io.Writer, records []platform.ProfileRecord) error {
+	return json.NewEncoder(w).Encode(NewTrace(records))
+}
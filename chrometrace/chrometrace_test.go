@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chrometrace_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend/chrometrace"
+	"github.com/gx-org/backend/platform"
+)
+
+func TestNewTrace(t *testing.T) {
+	origin := time.Unix(1000, 0)
+	records := []platform.ProfileRecord{
+		{Kind: platform.HostRecord, Name: "compile", Device: -1, Start: origin, End: origin.Add(2 * time.Millisecond)},
+		{Kind: platform.HostToDeviceRecord, Name: "x", Device: 1, Start: origin.Add(2 * time.Millisecond), End: origin.Add(2500 * time.Microsecond), Bytes: 24},
+		{Kind: platform.KernelRecord, Name: "main", Device: 1, Start: origin.Add(3 * time.Millisecond), End: origin.Add(4 * time.Millisecond)},
+	}
+	trace := chrometrace.NewTrace(records)
+	var events []chrometrace.Event
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph == "X" {
+			events = append(events, ev)
+		}
+	}
+	want := []chrometrace.Event{
+		{Name: "compile", Cat: "host", Ph: "X", Ts: 0, Dur: 2000, Pid: 0, Tid: 0},
+		{Name: "x", Cat: "host_to_device", Ph: "X", Ts: 2000, Dur: 500, Pid: 2, Tid: 1},
+		{Name: "main", Cat: "kernel", Ph: "X", Ts: 3000, Dur: 1000, Pid: 2, Tid: 0},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events but want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		ev.Args = nil
+		if !reflect.DeepEqual(ev, want[i]) {
+			t.Errorf("event %d: got %+v but want %+v", i, ev, want[i])
+		}
+	}
+	if got := events[1].Args["bytes"]; got != int64(24) {
+		t.Errorf("transfer event has %v bytes but want 24", got)
+	}
+	if len(trace.TraceEvents) != len(events)+6 {
+		t.Errorf("got %d metadata events but want 6", len(trace.TraceEvents)-len(events))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := chrometrace.Write(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	var trace chrometrace.Trace
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+	if trace.TraceEvents == nil || len(trace.TraceEvents) != 0 {
+		t.Errorf("got events %v but want an empty list", trace.TraceEvents)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compile
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+type (
+	// Key identifies a compiled graph in a cache.
+	Key struct {
+		// Fingerprint of the graph.
+		Fingerprint Fingerprint
+		// Platform is the name of the platform of the device.
+		Platform string
+		// Device is the ordinal of the device the graph has been compiled for.
+		Device int
+		// Params is the canonical encoding of the shapes of the parameters.
+		Params string
+	}
+
+	// Stats counts the lookups of a cache.
+	Stats struct {
+		// Hits is the number of compilations skipped because the runner was in the cache.
+		Hits int
+		// Misses is the number of graphs compiled.
+		Misses int
+		// Evictions is the number of runners removed from the cache to make room for new ones.
+		Evictions int
+	}
+
+	// Option configures a cache.
+	Option func(*Cache)
+
+	// Cache maps graph fingerprints, devices and parameter shapes to compiled runners.
+	// When the cache is full, the least recently used runner is evicted.
+	// A Cache is safe for concurrent use: concurrent compilations of the same key
+	// compile the graph once.
+	Cache struct {
+		maxEntries int
+		onEvict    func(Key, ops.Runner)
+
+		mu      sync.Mutex
+		entries map[Key]*entry
+		// lru lists the keys of the compiled entries, most recently used first.
+		lru   *list.List
+		stats Stats
+	}
+
+	entry struct {
+		key    Key
+		ready  chan struct{}
+		runner ops.Runner
+		err    error
+		elem   *list.Element
+	}
+)
+
+// WithMaxEntries limits the number of runners kept by the cache.
+// A value of 0 or less keeps all the runners.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithEviction sets a function called with the runners removed from the cache,
+// The function is called without holding the lock of the cache.
+//
+// The cache does not track the runners it has handed out: a runner returned by
+// Compile or Get can be evicted while callers still hold it or are running it.
+// onEvict must therefore not release resources that such callers still need,
+// for example by freeing the compiled executable. Use it for bookkeeping,
+// or release resources once all the callers are known to be done with the runner.
+func WithEviction(onEvict func(Key, ops.Runner)) Option {
+	return func(c *Cache) {
+		c.onEvict = onEvict
+	}
+}
+
+// NewCache returns an empty cache configured by options.
+func NewCache(opts ...Option) *Cache {
+	c := &Cache{entries: make(map[Key]*entry), lru: list.New()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewKey returns the key of a graph with a given fingerprint compiled for a device
+// and parameter shapes.
+func NewKey(fp Fingerprint, dev platform.Device, params []*shape.Shape) Key {
+	var enc []byte
+	for _, param := range params {
+		enc = param.AppendCanonical(enc)
+	}
+	return Key{Fingerprint: fp, Platform: dev.Platform().Name(), Device: dev.Ordinal(), Params: string(enc)}
+}
+
+// Compile returns the runner of a graph with the same fingerprint compiled for the same
+// device and parameter shapes, or compiles g and adds its runner to the cache.
+// Errors are not cached: a failed compilation is retried by the next call.
+// Callers can keep using the runner after it has been evicted (see WithEviction).
+func (c *Cache) Compile(g *intercept.Graph, dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	fp, err := FingerprintOf(g, output, traced)
+	if err != nil {
+		return nil, err
+	}
+	key := NewKey(fp, dev, params)
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		<-e.ready
+		if e.err != nil {
+			return nil, e.err
+		}
+		c.mu.Lock()
+		c.stats.Hits++
+		if e.elem != nil {
+			c.lru.MoveToFront(e.elem)
+		}
+		c.mu.Unlock()
+		return e.runner, nil
+	}
+	e := &entry{key: key, ready: make(chan struct{})}
+	c.entries[key] = e
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	e.runner, e.err = g.Compile(dev, output, traced, params)
+	if e.err != nil {
+		e.err = errors.Wrapf(e.err, "cannot compile graph %s", fp)
+	}
+	c.mu.Lock()
+	var evicted []*entry
+	if e.err != nil {
+		delete(c.entries, key)
+	} else {
+		e.elem = c.lru.PushFront(e)
+		evicted = c.evict()
+	}
+	c.mu.Unlock()
+	close(e.ready)
+	c.release(evicted)
+	return e.runner, e.err
+}
+
+// Get returns the runner compiled for a key, if any.
+// Callers can keep using the runner after it has been evicted (see WithEviction).
+func (c *Cache) Get(key Key) (ops.Runner, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.elem == nil {
+		return nil, false
+	}
+	c.lru.MoveToFront(e.elem)
+	return e.runner, true
+}
+
+// evict removes the least recently used entries until the cache is not over its limit.
+// It must be called with the lock held.
+func (c *Cache) evict() []*entry {
+	var evicted []*entry
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		e := c.lru.Remove(c.lru.Back()).(*entry)
+		delete(c.entries, e.key)
+		c.stats.Evictions++
+		evicted = append(evicted, e)
+	}
+	return evicted
+}
+
+func (c *Cache) release(entries []*entry) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, e := range entries {
+		c.onEvict(e.key, e.runner)
+	}
+}
+
+// Len returns the number of runners in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of hits, misses and evictions so far.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Purge removes all the compiled runners from the cache.
+// Compilations in progress are added to the cache once they complete.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	var purged []*entry
+	for c.lru.Len() > 0 {
+		e := c.lru.Remove(c.lru.Front()).(*entry)
+		delete(c.entries, e.key)
+		purged = append(purged, e)
+	}
+	c.mu.Unlock()
+	c.release(purged)
+}
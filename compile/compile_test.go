@@ -0,0 +1,196 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compile_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/compile"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type fixture struct {
+	t   *testing.T
+	b   *intercept.Backend
+	dev platform.Device
+}
+
+func newFixture(t *testing.T) *fixture {
+	inner, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { inner.Close() })
+	dev, err := inner.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fixture{t: t, b: compile.NewBackend(inner), dev: dev}
+}
+
+// build returns a graph adding a constant to its argument.
+func (f *fixture) build(name string, offset float32, n int) (*intercept.Graph, []*ops.OutputNode, []*shape.Shape) {
+	f.t.Helper()
+	g, err := f.b.NewOps(name)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	param := shape.Of(dtype.Float32, n)
+	x, err := g.Core().Argument("x", param, 0)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	c, err := g.Core().Constant(array.Scalar(offset).HostBuffer())
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	sum, err := g.Core().BinaryOp(ops.Add, x, c)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return g.(*intercept.Graph), []*ops.OutputNode{{Node: sum, Shape: param}}, []*shape.Shape{param}
+}
+
+func (f *fixture) fingerprint(name string, offset float32, n int) compile.Fingerprint {
+	f.t.Helper()
+	g, out, _ := f.build(name, offset, n)
+	fp, err := compile.FingerprintOf(g, out, nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return fp
+}
+
+func TestFingerprint(t *testing.T) {
+	f := newFixture(t)
+	ref := f.fingerprint("a", 1, 3)
+	if got := f.fingerprint("b", 1, 3); got != ref {
+		t.Errorf("graphs with the same structure but different names have different fingerprints")
+	}
+	if got := f.fingerprint("a", 2, 3); got == ref {
+		t.Errorf("graphs with different constants have the same fingerprint %s", got)
+	}
+	if got := f.fingerprint("a", 1, 4); got == ref {
+		t.Errorf("graphs with different argument shapes have the same fingerprint %s", got)
+	}
+}
+
+func TestCache(t *testing.T) {
+	f := newFixture(t)
+	var evicted []compile.Key
+	cache := compile.NewCache(compile.WithMaxEntries(1), compile.WithEviction(func(key compile.Key, _ ops.Runner) {
+		evicted = append(evicted, key)
+	}))
+	compileGraph := func(name string, offset float32) ops.Runner {
+		g, out, params := f.build(name, offset, 2)
+		runner, err := cache.Compile(g, f.dev, out, nil, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runner
+	}
+	first := compileGraph("first", 1)
+	if second := compileGraph("second", 1); second != first {
+		t.Errorf("got a new runner for a graph with the same fingerprint")
+	}
+	if got, want := cache.Stats(), (compile.Stats{Hits: 1, Misses: 1}); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+	other := compileGraph("other", 2)
+	if other == first {
+		t.Errorf("got the same runner for graphs with different fingerprints")
+	}
+	if got, want := cache.Stats(), (compile.Stats{Hits: 1, Misses: 2, Evictions: 1}); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+	if cache.Len() != 1 || len(evicted) != 1 {
+		t.Fatalf("got %d entries and %d evictions but want 1 and 1", cache.Len(), len(evicted))
+	}
+	if _, ok := cache.Get(evicted[0]); ok {
+		t.Errorf("evicted runner is still in the cache")
+	}
+	x, err := array.New([]float32{1, 2}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := other.Run([]platform.Handle{x.HostBuffer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := array.FromHandle[float32](out[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Flat(); got[0] != 3 || got[1] != 4 {
+		t.Errorf("got %v but want [3 4]", got)
+	}
+	// Callers can keep using a runner after it has been evicted.
+	if out, _, err = first.Run([]platform.Handle{x.HostBuffer()}); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = array.FromHandle[float32](out[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Flat(); got[0] != 2 || got[1] != 3 {
+		t.Errorf("evicted runner: got %v but want [2 3]", got)
+	}
+	cache.Purge()
+	if cache.Len() != 0 || len(evicted) != 2 {
+		t.Errorf("got %d entries and %d evictions after purge but want 0 and 2", cache.Len(), len(evicted))
+	}
+}
+
+func TestCacheConcurrentCompile(t *testing.T) {
+	f := newFixture(t)
+	cache := compile.NewCache()
+	type graph struct {
+		g      *intercept.Graph
+		out    []*ops.OutputNode
+		params []*shape.Shape
+	}
+	graphs := make([]graph, 8)
+	for i := range graphs {
+		g, out, params := f.build("g", 1, 2)
+		graphs[i] = graph{g, out, params}
+	}
+	var wg sync.WaitGroup
+	runners := make([]ops.Runner, len(graphs))
+	for i, gr := range graphs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if runners[i], err = cache.Compile(gr.g, f.dev, gr.out, nil, gr.params); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, r := range runners[1:] {
+		if r != runners[0] {
+			t.Errorf("concurrent compilations of the same graph returned different runners")
+		}
+	}
+	if got := cache.Stats(); got.Misses != 1 || got.Hits != len(graphs)-1 {
+		t.Errorf("got stats %+v but want 1 miss and %d hits", got, len(graphs)-1)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compile caches the runners returned by Graph.Compile.
+//
+// Compiling a graph is expensive on most backends while building it is cheap.
+// Graphs are built with a backend wrapped by NewBackend, such that the sequence of
+// builder calls creating them is recorded. The fingerprint of the recorded calls
+// identifies the structure of a graph: a Cache compiles a graph only if no graph with
+// the same fingerprint has been compiled for the same device and parameter shapes.
+package compile
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Fingerprint identifies the structure of a graph.
+// Graphs built by the same sequence of builder calls, with constants of the same content,
+// have the same fingerprint whatever the name of their root graph.
+type Fingerprint [sha256.Size]byte
+
+// String returns the fingerprint in hexadecimal.
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+// recorder is an interceptor doing nothing: the wrapped graphs record the calls.
+type recorder struct{}
+
+func (recorder) Before(*intercept.Call) error { return nil }
+
+func (recorder) After(*intercept.Call, error) {}
+
+// NewBackend returns a backend forwarding all calls to b whose graphs can be fingerprinted.
+func NewBackend(b backend.Backend) *intercept.Backend {
+	return intercept.NewBackend(b, recorder{})
+}
+
+// FingerprintOf returns the fingerprint of a root graph given its output and traced nodes.
+// The nodes must have been created by the graph or its subgraphs.
+func FingerprintOf(g *intercept.Graph, output, traced []*ops.OutputNode) (Fingerprint, error) {
+	if g.Parent() != nil {
+		return Fingerprint{}, errors.Errorf("cannot fingerprint subgraph %s: only root graphs can be fingerprinted", g.Path())
+	}
+	w := &writer{h: sha256.New()}
+	w.bool(g.Config().Debug)
+	if err := w.graph(g, ""); err != nil {
+		return Fingerprint{}, err
+	}
+	for _, outs := range [][]*ops.OutputNode{output, traced} {
+		w.int(len(outs))
+		for _, out := range outs {
+			node, ok := out.Node.(*intercept.Node)
+			if !ok {
+				if tpl, isTuple := out.Node.(*intercept.Tuple); isTuple {
+					node = tpl.Node
+				} else {
+					return Fingerprint{}, errors.Errorf("cannot fingerprint output node %T: not created by an intercepted graph", out.Node)
+				}
+			}
+			w.int(node.ID())
+			w.shape(out.Shape)
+		}
+	}
+	var fp Fingerprint
+	w.h.Sum(fp[:0])
+	return fp, nil
+}
+
+// writer writes the structure of a graph into a hash.
+// Variable-length values are prefixed by their length such that
+// different sequences of values cannot have the same encoding.
+type writer struct {
+	h   hash.Hash
+	buf []byte
+}
+
+func (w *writer) int(v int) {
+	w.buf = binary.AppendVarint(w.buf[:0], int64(v))
+	w.h.Write(w.buf)
+}
+
+func (w *writer) bool(v bool) {
+	if v {
+		w.int(1)
+	} else {
+		w.int(0)
+	}
+}
+
+func (w *writer) bytes(b []byte) {
+	w.int(len(b))
+	w.h.Write(b)
+}
+
+func (w *writer) string(s string) {
+	w.int(len(s))
+	w.h.Write([]byte(s))
+}
+
+func (w *writer) shape(sh *shape.Shape) {
+	if sh == nil {
+		w.int(-1)
+		return
+	}
+	w.bytes(sh.AppendCanonical(nil))
+}
+
+// graph writes the nodes of g and of its subgraphs. path is the path of g relative
+// to the root graph, such that the name of the root graph is not part of the fingerprint.
+func (w *writer) graph(g *intercept.Graph, path string) error {
+	w.string(path)
+	nodes := g.Nodes()
+	w.int(len(nodes))
+	for _, node := range nodes {
+		if err := w.node(node); err != nil {
+			return err
+		}
+	}
+	subs := g.Subgraphs()
+	w.int(len(subs))
+	for _, sub := range subs {
+		if err := w.graph(sub, path+"/"+sub.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *writer) node(n *intercept.Node) error {
+	call := n.Call()
+	w.int(n.ID())
+	w.string(string(call.Op))
+	w.int(len(call.Inputs))
+	for _, input := range call.Inputs {
+		w.int(input.ID())
+	}
+	w.int(len(call.Attrs))
+	for _, attr := range call.Attrs {
+		w.string(attr.Name)
+		if err := w.value(attr.Value); err != nil {
+			return errors.Wrapf(err, "cannot fingerprint attribute %s of node %s", attr.Name, n)
+		}
+	}
+	return nil
+}
+
+func (w *writer) value(v any) error {
+	switch vT := v.(type) {
+	case platform.HostBuffer:
+		data := vT.AcquireRead()
+		defer vT.ReleaseRead()
+		if data == nil {
+			return platform.ErrBufferFreed
+		}
+		w.shape(vT.Shape())
+		w.bytes(data)
+	case *shape.Shape:
+		w.shape(vT)
+	case []*shape.Shape:
+		w.int(len(vT))
+		for _, sh := range vT {
+			w.shape(sh)
+		}
+	default:
+		w.string(intercept.FormatValue(v))
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataparallel provides a backend running graphs on multiple devices
+// by splitting the batch axis of their arguments.
+//
+// Graphs are built for the arguments of a single device, that is with a batch
+// size divided by the number of devices. Compiling a graph compiles it on every
+// device. The returned runner takes arguments with the full batch size, splits
+// them along their leading axis, runs the graph on all the devices concurrently,
+// and concatenates the outputs on the first device.
+//
+// Arguments with the same shape as the parameter of the graph are replicated
+// on all the devices instead of being split.
+package dataparallel
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Backend compiles graphs on multiple devices.
+type Backend struct {
+	backend.Backend
+	devices []platform.Device
+}
+
+// New returns a backend compiling the graphs of b on all the given devices.
+func New(b backend.Backend, devices []platform.Device) (*Backend, error) {
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("data parallelism requires at least one device")
+	}
+	return &Backend{Backend: b, devices: slices.Clone(devices)}, nil
+}
+
+// Devices on which graphs are compiled.
+func (b *Backend) Devices() []platform.Device {
+	return b.devices
+}
+
+// NewOps returns a new graph compiled on all the devices of the backend.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	inner, err := b.Backend.NewOps(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &graph{Graph: inner, devices: b.devices}, nil
+}
+
+type graph struct {
+	ops.Graph
+	devices []platform.Device
+}
+
+// Compile the graph on all the devices of the backend.
+// The device passed as an argument is ignored.
+func (g *graph) Compile(_ platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	for i, out := range append(slices.Clone(output), traced...) {
+		if out.Shape.IsAtomic() {
+			return nil, fmt.Errorf("output %d of shape %s has no batch axis", i, out.Shape)
+		}
+	}
+	r := &Runner{
+		devices: g.devices,
+		params:  params,
+		output:  batchShapes(output, len(g.devices)),
+		traced:  batchShapes(traced, len(g.devices)),
+	}
+	for _, dev := range g.devices {
+		runner, err := g.Graph.Compile(dev, output, traced, params)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compile graph on device %d: %v", dev.Ordinal(), err)
+		}
+		r.runners = append(r.runners, runner)
+	}
+	return r, nil
+}
+
+// batchShapes returns the shapes of the outputs once concatenated.
+func batchShapes(outs []*ops.OutputNode, n int) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(outs))
+	for i, out := range outs {
+		axisLengths := slices.Clone(out.Shape.AxisLengths)
+		axisLengths[0] *= n
+		shapes[i] = out.Shape.WithAxes(axisLengths...)
+	}
+	return shapes
+}
+
+// Runner runs a graph on multiple devices.
+type Runner struct {
+	devices        []platform.Device
+	runners        []ops.Runner
+	params         []*shape.Shape
+	output, traced []*shape.Shape
+}
+
+var _ ops.Runner = (*Runner)(nil)
+
+// Run splits the arguments along their leading axis, runs the graph on all the devices,
+// and returns the concatenated outputs located on the first device.
+func (r *Runner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	if len(args) != len(r.params) {
+		return nil, nil, fmt.Errorf("got %d arguments but want %d", len(args), len(r.params))
+	}
+	perDevice := make([][]platform.Handle, len(r.devices))
+	var toFree []platform.DeviceHandle
+	defer func() {
+		for _, h := range toFree {
+			h.Free()
+		}
+	}()
+	for i, arg := range args {
+		shards, err := r.distribute(arg, r.params[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("argument %d: %v", i, err)
+		}
+		for d, shard := range shards {
+			perDevice[d] = append(perDevice[d], shard)
+			if shard != arg {
+				toFree = append(toFree, shard)
+			}
+		}
+	}
+	outs := make([][]platform.DeviceHandle, len(r.devices))
+	tracesPerDevice := make([][]platform.DeviceHandle, len(r.devices))
+	errs := make([]error, len(r.devices))
+	var wg sync.WaitGroup
+	for d, runner := range r.runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outs[d], tracesPerDevice[d], errs[d] = runner.Run(perDevice[d])
+		}()
+	}
+	wg.Wait()
+	for d, err := range errs {
+		if err != nil {
+			return nil, nil, fmt.Errorf("device %d: %v", r.devices[d].Ordinal(), err)
+		}
+	}
+	if out, err = r.gather(outs, r.output); err != nil {
+		return nil, nil, err
+	}
+	if traces, err = r.gather(tracesPerDevice, r.traced); err != nil {
+		return nil, nil, err
+	}
+	return out, traces, nil
+}
+
+// distribute returns the handle of an argument on each device.
+func (r *Runner) distribute(arg platform.Handle, param *shape.Shape) ([]platform.DeviceHandle, error) {
+	sh := arg.Shape()
+	if sh.Equal(param) {
+		return r.replicate(arg)
+	}
+	if sh.IsAtomic() || len(sh.AxisLengths) != len(param.AxisLengths) ||
+		sh.AxisLengths[0] != param.AxisLengths[0]*len(r.devices) ||
+		!slices.Equal(sh.AxisLengths[1:], param.AxisLengths[1:]) {
+		return nil, fmt.Errorf("shape %s is neither the parameter shape %s nor its batched shape", sh, param)
+	}
+	buf, err := platform.CurrentStagingPool().Get(sh)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+	if err := arg.ToHost(buf); err != nil {
+		return nil, err
+	}
+	data := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	sharded, err := platform.Shard(data, sh, r.batchSpec(sh))
+	if err != nil {
+		return nil, err
+	}
+	return sharded.Shards(), nil
+}
+
+func (r *Runner) replicate(arg platform.Handle) ([]platform.DeviceHandle, error) {
+	handles := make([]platform.DeviceHandle, len(r.devices))
+	for d, dev := range r.devices {
+		if h, ok := arg.(platform.DeviceHandle); ok && h.Device() == dev {
+			handles[d] = h
+			continue
+		}
+		var err error
+		if handles[d], err = arg.ToDevice(dev); err != nil {
+			return nil, err
+		}
+	}
+	return handles, nil
+}
+
+// gather concatenates the outputs of all the devices on the first device.
+func (r *Runner) gather(perDevice [][]platform.DeviceHandle, shapes []*shape.Shape) ([]platform.DeviceHandle, error) {
+	gathered := make([]platform.DeviceHandle, len(shapes))
+	for i, sh := range shapes {
+		shards := make([]platform.DeviceHandle, len(r.devices))
+		for d := range r.devices {
+			shards[d] = perDevice[d][i]
+		}
+		sharded, err := platform.NewShardedHandle(sh, r.batchSpec(sh), shards)
+		if err != nil {
+			return nil, err
+		}
+		gathered[i], err = sharded.ToDevice(r.devices[0])
+		sharded.Free()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return gathered, nil
+}
+
+// batchSpec returns a spec splitting an array along its leading axis across all the devices.
+func (r *Runner) batchSpec(sh *shape.Shape) *platform.ShardingSpec {
+	splits := make([]int, len(sh.AxisLengths))
+	for i := range splits {
+		splits[i] = 1
+	}
+	splits[0] = len(r.devices)
+	return &platform.ShardingSpec{Splits: splits, Devices: r.devices}
+}
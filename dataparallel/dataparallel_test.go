@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataparallel_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/dataparallel"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestDataParallel(t *testing.T) {
+	plat := platformtest.New(2)
+	inner := opstest.NewBackend(plat)
+	devices, err := plat.Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := dataparallel.New(inner, devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*shape.Shape{shape.Of(dtype.Float32, 2, 3), shape.Of(dtype.Float32, 3)}
+	runner, err := g.Compile(nil, []*ops.OutputNode{{Node: x, Shape: shape.Of(dtype.Float32, 2, 3)}}, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := inner.Graphs()[0].Compiled; got != 2 {
+		t.Errorf("graph compiled %d times but want 2", got)
+	}
+	batch, err := devices[0].Send(make([]byte, 4*3*4), shape.Of(dtype.Float32, 4, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	weights, err := devices[1].Send(make([]byte, 3*4), shape.Of(dtype.Float32, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := runner.Run([]platform.Handle{batch, weights})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out[0].Shape().String(), "[4][3]float32"; got != want {
+		t.Errorf("got output shape %s but want %s", got, want)
+	}
+	if out[0].Device() != devices[0] {
+		t.Errorf("output located on device %d but want device 0", out[0].Device().Ordinal())
+	}
+	// Only the arguments and the gathered output are still alive.
+	if got := plat.Counts().Live; got != 3 {
+		t.Errorf("got %d live handles but want 3", got)
+	}
+	bad, err := devices[0].Send(make([]byte, 3*3*4), shape.Of(dtype.Float32, 3, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runner.Run([]platform.Handle{bad, weights}); err == nil {
+		t.Errorf("running with a batch not divisible by the number of devices did not return an error")
+	}
+}
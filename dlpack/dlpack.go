@@ -0,0 +1,245 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dlpack exchanges device arrays with other frameworks in the same process
+// following the DLPack conventions, without copying the data.
+//
+// A Tensor mirrors the fields of a DLPack DLManagedTensor. Bindings to a foreign
+// framework, such as a Python extension, convert a Tensor to and from a DLPack
+// capsule. Platforms support the exchange by implementing Exporter on their handles
+// and Importer on their devices.
+package dlpack
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"unsafe"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// DeviceType is the type of a device as defined by DLPack.
+type DeviceType int32
+
+// Device types defined by DLPack.
+const (
+	CPU      DeviceType = 1
+	CUDA     DeviceType = 2
+	CUDAHost DeviceType = 3
+	OpenCL   DeviceType = 4
+	Vulkan   DeviceType = 7
+	Metal    DeviceType = 8
+	VPI      DeviceType = 9
+	ROCM     DeviceType = 10
+	ROCMHost DeviceType = 11
+	ExtDev   DeviceType = 12
+	OneAPI   DeviceType = 14
+)
+
+// Device locates the memory of a tensor.
+type Device struct {
+	Type DeviceType
+	ID   int32
+}
+
+// TypeCode is the kind of the elements of a tensor as defined by DLPack.
+type TypeCode uint8
+
+// Type codes defined by DLPack.
+const (
+	Int     TypeCode = 0
+	UInt    TypeCode = 1
+	Float   TypeCode = 2
+	Bfloat  TypeCode = 4
+	Complex TypeCode = 5
+	Bool    TypeCode = 6
+)
+
+// DataType is the type of the elements of a tensor as defined by DLPack.
+type DataType struct {
+	Code  TypeCode
+	Bits  uint8
+	Lanes uint16
+}
+
+// FromDType returns the DLPack data type of a data type.
+func FromDType(dt dtype.DataType) (DataType, error) {
+	switch dt {
+	case dtype.Bool:
+		return DataType{Code: Bool, Bits: 8, Lanes: 1}, nil
+	case dtype.Int, dtype.Int8, dtype.Int16, dtype.Int32, dtype.Int64:
+		return DataType{Code: Int, Bits: uint8(8 * dtype.Sizeof(dt)), Lanes: 1}, nil
+	case dtype.Uint8, dtype.Uint16, dtype.Uint32, dtype.Uint64:
+		return DataType{Code: UInt, Bits: uint8(8 * dtype.Sizeof(dt)), Lanes: 1}, nil
+	case dtype.Bfloat16:
+		return DataType{Code: Bfloat, Bits: 16, Lanes: 1}, nil
+	case dtype.Float16, dtype.Float32, dtype.Float64:
+		return DataType{Code: Float, Bits: uint8(8 * dtype.Sizeof(dt)), Lanes: 1}, nil
+	case dtype.Complex64, dtype.Complex128:
+		return DataType{Code: Complex, Bits: uint8(8 * dtype.Sizeof(dt)), Lanes: 1}, nil
+	case dtype.Int4:
+		return DataType{Code: Int, Bits: 4, Lanes: 1}, nil
+	case dtype.Uint4:
+		return DataType{Code: UInt, Bits: 4, Lanes: 1}, nil
+	}
+	return DataType{}, fmt.Errorf("data type %s not supported by DLPack", dt)
+}
+
+// DType returns the data type of a DLPack data type.
+func (dt DataType) DType() (dtype.DataType, error) {
+	if dt.Lanes != 1 {
+		return dtype.Invalid, fmt.Errorf("DLPack vector types with %d lanes not supported", dt.Lanes)
+	}
+	switch {
+	case dt.Code == Bool && dt.Bits == 8:
+		return dtype.Bool, nil
+	case dt.Code == Int && dt.Bits == 4:
+		return dtype.Int4, nil
+	case dt.Code == Int && dt.Bits == 8:
+		return dtype.Int8, nil
+	case dt.Code == Int && dt.Bits == 16:
+		return dtype.Int16, nil
+	case dt.Code == Int && dt.Bits == 32:
+		return dtype.Int32, nil
+	case dt.Code == Int && dt.Bits == 64:
+		return dtype.Int64, nil
+	case dt.Code == UInt && dt.Bits == 4:
+		return dtype.Uint4, nil
+	case dt.Code == UInt && dt.Bits == 8:
+		return dtype.Uint8, nil
+	case dt.Code == UInt && dt.Bits == 16:
+		return dtype.Uint16, nil
+	case dt.Code == UInt && dt.Bits == 32:
+		return dtype.Uint32, nil
+	case dt.Code == UInt && dt.Bits == 64:
+		return dtype.Uint64, nil
+	case dt.Code == Bfloat && dt.Bits == 16:
+		return dtype.Bfloat16, nil
+	case dt.Code == Float && dt.Bits == 16:
+		return dtype.Float16, nil
+	case dt.Code == Float && dt.Bits == 32:
+		return dtype.Float32, nil
+	case dt.Code == Float && dt.Bits == 64:
+		return dtype.Float64, nil
+	case dt.Code == Complex && dt.Bits == 64:
+		return dtype.Complex64, nil
+	case dt.Code == Complex && dt.Bits == 128:
+		return dtype.Complex128, nil
+	}
+	return dtype.Invalid, fmt.Errorf("DLPack data type %+v not supported", dt)
+}
+
+// Tensor is an array exchanged with another framework.
+type Tensor struct {
+	// Data is the address of the memory of the tensor on its device.
+	Data unsafe.Pointer
+	// Device storing the tensor.
+	Device Device
+	// DType is the type of the elements.
+	DType DataType
+	// Shape is the length of each axis.
+	Shape []int64
+	// Strides is the distance, in number of elements, between two consecutive elements
+	// along each axis. Nil for compact row-major tensors.
+	Strides []int64
+	// ByteOffset is the offset, in bytes, of the first element from Data.
+	ByteOffset uint64
+	// Deleter is called by the consumer of the tensor once it does not use it anymore.
+	// The producer must keep the memory valid until then.
+	Deleter func()
+}
+
+// ArrayShape returns the shape of the tensor.
+func (t *Tensor) ArrayShape() (*shape.Shape, error) {
+	dt, err := t.DType.DType()
+	if err != nil {
+		return nil, err
+	}
+	axisLengths := make([]int, len(t.Shape))
+	for i, length := range t.Shape {
+		axisLengths[i] = int(length)
+	}
+	sh := shape.Of(dt, axisLengths...)
+	if t.Strides == nil {
+		return sh, nil
+	}
+	strides := make([]int, len(t.Strides))
+	for i, stride := range t.Strides {
+		strides[i] = int(stride)
+	}
+	if slices.Equal(strides, sh.Strides()) {
+		return sh, nil
+	}
+	order := make([]int, len(strides))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return strides[a] - strides[b] })
+	sh = sh.WithLayout(&shape.Layout{MinorToMajor: order, Strides: strides})
+	if err := sh.Check(); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// IsCompact returns true if the elements of the tensor are stored in row-major order without gaps.
+func (t *Tensor) IsCompact() bool {
+	sh, err := t.ArrayShape()
+	return err == nil && sh.Layout == nil
+}
+
+// ErrUnsupported is returned when a handle or a device does not support DLPack.
+var ErrUnsupported = errors.New("DLPack exchange not supported")
+
+type (
+	// Exporter is implemented by device handles which can be exported as DLPack tensors.
+	Exporter interface {
+		platform.DeviceHandle
+
+		// ExportDLPack returns a tensor sharing the memory of the handle.
+		ExportDLPack() (*Tensor, error)
+	}
+
+	// Importer is implemented by devices which can import DLPack tensors.
+	Importer interface {
+		platform.Device
+
+		// ImportDLPack returns a handle sharing the memory of the tensor.
+		// The Deleter of the tensor is called when the handle is freed.
+		ImportDLPack(*Tensor) (platform.DeviceHandle, error)
+	}
+)
+
+// Export returns a tensor sharing the memory of a device handle.
+// The memory of the handle remains valid until the Deleter of the tensor is called,
+// even if the handle is freed.
+func Export(h platform.DeviceHandle) (*Tensor, error) {
+	exp, ok := h.(Exporter)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", h, ErrUnsupported)
+	}
+	return exp.ExportDLPack()
+}
+
+// Import returns a handle on a device sharing the memory of a tensor.
+func Import(dev platform.Device, t *Tensor) (platform.DeviceHandle, error) {
+	imp, ok := dev.(Importer)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", dev, ErrUnsupported)
+	}
+	return imp.ImportDLPack(t)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dlpack_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dlpack"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestDataTypes(t *testing.T) {
+	for _, dt := range []dtype.DataType{dtype.Bool, dtype.Int8, dtype.Int16, dtype.Int32, dtype.Int64, dtype.Uint8, dtype.Uint16, dtype.Uint32, dtype.Uint64, dtype.Bfloat16, dtype.Float16, dtype.Float32, dtype.Float64, dtype.Complex64, dtype.Complex128, dtype.Int4, dtype.Uint4} {
+		dl, err := dlpack.FromDType(dt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := dl.DType()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != dt {
+			t.Errorf("%s: got %s after a round trip", dt, got)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := plat.Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := []float32{1, 2, 3, 4, 5, 6}
+	src, err := dev.Send(dtype.FromSlice(vals), shape.Of(dtype.Float32, 2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tensor, err := dlpack.Export(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tensor.Shape, []int64{2, 3}) || !tensor.IsCompact() {
+		t.Errorf("got tensor shape %v and strides %v", tensor.Shape, tensor.Strides)
+	}
+	deleted := 0
+	deleter := tensor.Deleter
+	tensor.Deleter = func() {
+		deleted++
+		deleter()
+	}
+	imported, err := dlpack.Import(dev, tensor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := imported.(*cpu.Handle).Data()
+	if &data[0] != &src.(*cpu.Handle).Data()[0] {
+		t.Errorf("imported handle does not share the memory of the exported handle")
+	}
+	if got := dtype.ToSlice[float32](data); !slices.Equal(got, vals) {
+		t.Errorf("got values %v but want %v", got, vals)
+	}
+	imported.Free()
+	if deleted != 1 {
+		t.Errorf("deleter called %d times but want 1", deleted)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	h, err := dev.Send(make([]byte, 4), shape.Scalar(dtype.Float32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dlpack.Export(h); !errors.Is(err, dlpack.ErrUnsupported) {
+		t.Errorf("got error %v but want %v", err, dlpack.ErrUnsupported)
+	}
+}
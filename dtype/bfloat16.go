@@ -51,3 +51,42 @@ func (f Bfloat16T) Bits() uint16 {
 func (f Bfloat16T) String() string {
 	return strconv.FormatFloat(float64(f.Float32()), 'f', -1, 32)
 }
+
+// BFloat16sFromFloat32s converts a slice of float32 into a slice of BFloat16.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func BFloat16sFromFloat32s(dst []Bfloat16T, src []float32) []Bfloat16T {
+	if cap(dst) < len(src) {
+		dst = make([]Bfloat16T, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = Bfloat16T(math.Float32bits(x) >> 16)
+	}
+	return dst
+}
+
+// BFloat16sFromFloat64s converts a slice of float64 into a slice of BFloat16.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func BFloat16sFromFloat64s(dst []Bfloat16T, src []float64) []Bfloat16T {
+	if cap(dst) < len(src) {
+		dst = make([]Bfloat16T, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = Bfloat16T(math.Float32bits(float32(x)) >> 16)
+	}
+	return dst
+}
+
+// Float32sFromBFloat16s converts a slice of BFloat16 into a slice of float32.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func Float32sFromBFloat16s(dst []float32, src []Bfloat16T) []float32 {
+	if cap(dst) < len(src) {
+		dst = make([]float32, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = math.Float32frombits(uint32(x) << 16)
+	}
+	return dst
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBFloat16Slices(t *testing.T) {
+	src := []float32{0, 1, -2.5, 1024, 0.15625}
+	bf := BFloat16sFromFloat32s(nil, src)
+	for i, x := range src {
+		if want := BFloat16FromFloat32(x); bf[i] != want {
+			t.Errorf("element %d: got %v but want %v", i, bf[i], want)
+		}
+	}
+	got := Float32sFromBFloat16s(make([]float32, 0, len(bf)), bf)
+	if !slices.Equal(got, src) {
+		t.Errorf("round trip: got %v but want %v", got, src)
+	}
+}
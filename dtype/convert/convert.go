@@ -0,0 +1,350 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package convert converts buffers of data from one data type to another on the host.
+package convert
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// Rounding specifies how floating-point values are rounded when converted to integers.
+type Rounding int
+
+const (
+	// TowardZero truncates the fractional part, as Go conversions do.
+	TowardZero Rounding = iota
+	// NearestEven rounds to the nearest integer, ties to even.
+	NearestEven
+	// Floor rounds toward negative infinity.
+	Floor
+	// Ceil rounds toward positive infinity.
+	Ceil
+)
+
+// Options of a conversion.
+type Options struct {
+	// Saturate clamps values outside of the range of the target data type to the
+	// minimum or maximum value of that type. Values wrap around otherwise.
+	// NaN is always converted to 0 when the target is an integer type.
+	Saturate bool
+
+	// Rounding applied when converting floating-point values to integers.
+	Rounding Rounding
+}
+
+// value is an element read from a buffer.
+// Complex values are stored as floats with an imaginary part, which is dropped
+// when converting to a non-complex data type.
+type value struct {
+	kind valueKind
+	i    int64
+	u    uint64
+	f    float64
+	im   float64
+}
+
+type valueKind int
+
+const (
+	signedKind valueKind = iota
+	unsignedKind
+	floatKind
+)
+
+// Convert converts the elements of src from the data type srcType into dst with the data type dstType.
+// dst must have exactly enough space to store the same number of elements as src.
+func Convert(dst []byte, dstType dtype.DataType, src []byte, srcType dtype.DataType, opts Options) error {
+	srcType = dtype.Resolve(srcType, dtype.HostInt)
+	dstType = dtype.Resolve(dstType, dtype.HostInt)
+	load, err := loader(src, srcType)
+	if err != nil {
+		return err
+	}
+	num := len(src) / dtype.Sizeof(srcType)
+	if want := num * dtype.Sizeof(dstType); len(dst) != want {
+		return fmt.Errorf("cannot convert %d %s elements into a buffer of %d bytes: want %d bytes", num, srcType, len(dst), want)
+	}
+	store, flush, err := storer(dst, dstType, opts)
+	if err != nil {
+		return err
+	}
+	for i := range num {
+		store(i, load(i))
+	}
+	flush()
+	return nil
+}
+
+// Bytes converts the elements of src from the data type srcType and returns them in a new buffer of data type dstType.
+func Bytes(src []byte, srcType, dstType dtype.DataType, opts Options) ([]byte, error) {
+	for _, dt := range []dtype.DataType{srcType, dstType} {
+		if !dtype.IsAlgebra(dt) && dt != dtype.Bool {
+			return nil, fmt.Errorf("cannot convert data type %s", dt)
+		}
+	}
+	srcSize := dtype.Sizeof(dtype.Resolve(srcType, dtype.HostInt))
+	if len(src)%srcSize != 0 {
+		return nil, fmt.Errorf("buffer of %d bytes does not contain a whole number of %s elements", len(src), srcType)
+	}
+	dst := make([]byte, len(src)/srcSize*dtype.Sizeof(dtype.Resolve(dstType, dtype.HostInt)))
+	if err := Convert(dst, dstType, src, srcType, opts); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func loader(src []byte, dt dtype.DataType) (func(int) value, error) {
+	if !dtype.IsAlgebra(dt) && dt != dtype.Bool {
+		return nil, fmt.Errorf("cannot convert from data type %s", dt)
+	}
+	if len(src)%dtype.Sizeof(dt) != 0 {
+		return nil, fmt.Errorf("buffer of %d bytes does not contain a whole number of %s elements", len(src), dt)
+	}
+	if len(src) == 0 {
+		return func(int) value { return value{} }, nil
+	}
+	switch dt {
+	case dtype.Bool:
+		vals := dtype.ToSlice[bool](src)
+		return func(i int) value {
+			if vals[i] {
+				return value{kind: unsignedKind, u: 1}
+			}
+			return value{kind: unsignedKind}
+		}, nil
+	case dtype.Int8:
+		return signedLoader(dtype.ToSlice[int8](src)), nil
+	case dtype.Int16:
+		return signedLoader(dtype.ToSlice[int16](src)), nil
+	case dtype.Int32:
+		return signedLoader(dtype.ToSlice[int32](src)), nil
+	case dtype.Int64:
+		return signedLoader(dtype.ToSlice[int64](src)), nil
+	case dtype.Uint8:
+		return unsignedLoader(dtype.ToSlice[uint8](src)), nil
+	case dtype.Uint16:
+		return unsignedLoader(dtype.ToSlice[uint16](src)), nil
+	case dtype.Uint32:
+		return unsignedLoader(dtype.ToSlice[uint32](src)), nil
+	case dtype.Uint64:
+		return unsignedLoader(dtype.ToSlice[uint64](src)), nil
+	case dtype.Bfloat16:
+		vals := dtype.ToSlice[dtype.Bfloat16T](src)
+		return func(i int) value { return value{kind: floatKind, f: float64(vals[i].Float32())} }, nil
+	case dtype.Float16:
+		vals := dtype.ToSlice[dtype.Float16T](src)
+		return func(i int) value { return value{kind: floatKind, f: float64(vals[i].Float32())} }, nil
+	case dtype.Float32:
+		return floatLoader(dtype.ToSlice[float32](src)), nil
+	case dtype.Float64:
+		return floatLoader(dtype.ToSlice[float64](src)), nil
+	case dtype.Complex64:
+		return complexLoader(dtype.ToSlice[complex64](src)), nil
+	case dtype.Complex128:
+		return complexLoader(dtype.ToSlice[complex128](src)), nil
+	}
+	return nil, fmt.Errorf("cannot convert from data type %s", dt)
+}
+
+func signedLoader[T dtype.Signed](vals []T) func(int) value {
+	return func(i int) value { return value{kind: signedKind, i: int64(vals[i])} }
+}
+
+func unsignedLoader[T dtype.Unsigned](vals []T) func(int) value {
+	return func(i int) value { return value{kind: unsignedKind, u: uint64(vals[i])} }
+}
+
+func floatLoader[T dtype.Float](vals []T) func(int) value {
+	return func(i int) value { return value{kind: floatKind, f: float64(vals[i])} }
+}
+
+func complexLoader[T dtype.Complex](vals []T) func(int) value {
+	return func(i int) value {
+		c := complex128(vals[i])
+		return value{kind: floatKind, f: real(c), im: imag(c)}
+	}
+}
+
+// storer returns a function storing values in dst and a function to call once all values have been stored.
+func storer(dst []byte, dt dtype.DataType, opts Options) (func(int, value), func(), error) {
+	if len(dst) == 0 {
+		return func(int, value) {}, func() {}, nil
+	}
+	switch dt {
+	case dtype.Bool:
+		vals := dtype.ToSlice[bool](dst)
+		return func(i int, v value) { vals[i] = v.isNonZero() }, writeBack(dst, vals), nil
+	case dtype.Int8:
+		vals := dtype.ToSlice[int8](dst)
+		return func(i int, v value) { vals[i] = int8(v.toSigned(math.MinInt8, math.MaxInt8, opts)) }, writeBack(dst, vals), nil
+	case dtype.Int16:
+		vals := dtype.ToSlice[int16](dst)
+		return func(i int, v value) { vals[i] = int16(v.toSigned(math.MinInt16, math.MaxInt16, opts)) }, writeBack(dst, vals), nil
+	case dtype.Int32:
+		vals := dtype.ToSlice[int32](dst)
+		return func(i int, v value) { vals[i] = int32(v.toSigned(math.MinInt32, math.MaxInt32, opts)) }, writeBack(dst, vals), nil
+	case dtype.Int64:
+		vals := dtype.ToSlice[int64](dst)
+		return func(i int, v value) { vals[i] = v.toSigned(math.MinInt64, math.MaxInt64, opts) }, writeBack(dst, vals), nil
+	case dtype.Uint8:
+		vals := dtype.ToSlice[uint8](dst)
+		return func(i int, v value) { vals[i] = uint8(v.toUnsigned(math.MaxUint8, opts)) }, writeBack(dst, vals), nil
+	case dtype.Uint16:
+		vals := dtype.ToSlice[uint16](dst)
+		return func(i int, v value) { vals[i] = uint16(v.toUnsigned(math.MaxUint16, opts)) }, writeBack(dst, vals), nil
+	case dtype.Uint32:
+		vals := dtype.ToSlice[uint32](dst)
+		return func(i int, v value) { vals[i] = uint32(v.toUnsigned(math.MaxUint32, opts)) }, writeBack(dst, vals), nil
+	case dtype.Uint64:
+		vals := dtype.ToSlice[uint64](dst)
+		return func(i int, v value) { vals[i] = v.toUnsigned(math.MaxUint64, opts) }, writeBack(dst, vals), nil
+	case dtype.Bfloat16:
+		vals := dtype.ToSlice[dtype.Bfloat16T](dst)
+		return func(i int, v value) { vals[i] = dtype.BFloat16FromFloat64(v.toFloat()) }, writeBack(dst, vals), nil
+	case dtype.Float16:
+		vals := dtype.ToSlice[dtype.Float16T](dst)
+		return func(i int, v value) { vals[i] = dtype.Float16FromFloat64(v.toFloat()) }, writeBack(dst, vals), nil
+	case dtype.Float32:
+		vals := dtype.ToSlice[float32](dst)
+		return func(i int, v value) { vals[i] = float32(v.toFloat()) }, writeBack(dst, vals), nil
+	case dtype.Float64:
+		vals := dtype.ToSlice[float64](dst)
+		return func(i int, v value) { vals[i] = v.toFloat() }, writeBack(dst, vals), nil
+	case dtype.Complex64:
+		vals := dtype.ToSlice[complex64](dst)
+		return func(i int, v value) { vals[i] = complex64(v.toComplex()) }, writeBack(dst, vals), nil
+	case dtype.Complex128:
+		vals := dtype.ToSlice[complex128](dst)
+		return func(i int, v value) { vals[i] = v.toComplex() }, writeBack(dst, vals), nil
+	}
+	return nil, nil, fmt.Errorf("cannot convert to data type %s", dt)
+}
+
+func (v value) isNonZero() bool {
+	switch v.kind {
+	case signedKind:
+		return v.i != 0
+	case unsignedKind:
+		return v.u != 0
+	}
+	return v.f != 0 || v.im != 0
+}
+
+func (v value) toFloat() float64 {
+	switch v.kind {
+	case signedKind:
+		return float64(v.i)
+	case unsignedKind:
+		return float64(v.u)
+	}
+	return v.f
+}
+
+func (v value) toComplex() complex128 {
+	return complex(v.toFloat(), v.im)
+}
+
+func round(f float64, r Rounding) float64 {
+	switch r {
+	case NearestEven:
+		return math.RoundToEven(f)
+	case Floor:
+		return math.Floor(f)
+	case Ceil:
+		return math.Ceil(f)
+	}
+	return math.Trunc(f)
+}
+
+func (v value) toSigned(minVal, maxVal int64, opts Options) int64 {
+	switch v.kind {
+	case signedKind:
+		if opts.Saturate {
+			return min(max(v.i, minVal), maxVal)
+		}
+		return v.i
+	case unsignedKind:
+		if opts.Saturate && v.u > uint64(maxVal) {
+			return maxVal
+		}
+		return int64(v.u)
+	}
+	f := round(v.f, opts.Rounding)
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f <= float64(minVal):
+		if opts.Saturate {
+			return minVal
+		}
+		if f < math.MinInt64 {
+			return math.MinInt64
+		}
+	case f >= float64(maxVal):
+		if opts.Saturate {
+			return maxVal
+		}
+		if f >= math.MaxInt64 {
+			return math.MaxInt64
+		}
+	}
+	return int64(f)
+}
+
+func (v value) toUnsigned(maxVal uint64, opts Options) uint64 {
+	switch v.kind {
+	case signedKind:
+		if opts.Saturate {
+			if v.i < 0 {
+				return 0
+			}
+			return min(uint64(v.i), maxVal)
+		}
+		return uint64(v.i)
+	case unsignedKind:
+		if opts.Saturate {
+			return min(v.u, maxVal)
+		}
+		return v.u
+	}
+	f := round(v.f, opts.Rounding)
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f < 0:
+		if opts.Saturate {
+			return 0
+		}
+		if f < math.MinInt64 {
+			return uint64(1) << 63
+		}
+		return uint64(int64(f))
+	case f >= float64(maxVal):
+		if opts.Saturate {
+			return maxVal
+		}
+		if f >= math.MaxUint64 {
+			return math.MaxUint64
+		}
+	}
+	return uint64(f)
+}
+
+func writeBack[T dtype.GoDataType](dst []byte, vals []T) func() {
+	return func() { dtype.WriteBack(dst, vals) }
+}
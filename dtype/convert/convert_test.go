@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/dtype/convert"
+)
+
+func TestFloat64ToFloat32(t *testing.T) {
+	src := []float64{1, -2.5, 1e10}
+	dst, err := convert.Bytes(dtype.FromSlice(src), dtype.Float64, dtype.Float32, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[float32](dst), []float32{1, -2.5, 1e10}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
+
+func TestFloatToInt(t *testing.T) {
+	src := []float64{2.5, -2.5, 3.7, math.NaN(), 1e20, -1e20}
+	tests := []struct {
+		opts convert.Options
+		want []int32
+	}{
+		{
+			opts: convert.Options{Saturate: true},
+			want: []int32{2, -2, 3, 0, math.MaxInt32, math.MinInt32},
+		},
+		{
+			opts: convert.Options{Saturate: true, Rounding: convert.NearestEven},
+			want: []int32{2, -2, 4, 0, math.MaxInt32, math.MinInt32},
+		},
+		{
+			opts: convert.Options{Saturate: true, Rounding: convert.Floor},
+			want: []int32{2, -3, 3, 0, math.MaxInt32, math.MinInt32},
+		},
+	}
+	for i, test := range tests {
+		dst, err := convert.Bytes(dtype.FromSlice(src), dtype.Float64, dtype.Int32, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dtype.ToSlice[int32](dst); !slices.Equal(got, test.want) {
+			t.Errorf("test %d: got %v but want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestIntegers(t *testing.T) {
+	src := []int64{-1, 1 << 40}
+	wrapped, err := convert.Bytes(dtype.FromSlice(src), dtype.Int64, dtype.Uint32, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[uint32](wrapped), []uint32{math.MaxUint32, 0}; !slices.Equal(got, want) {
+		t.Errorf("wrap: got %v but want %v", got, want)
+	}
+	saturated, err := convert.Bytes(dtype.FromSlice(src), dtype.Int64, dtype.Uint32, convert.Options{Saturate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[uint32](saturated), []uint32{0, math.MaxUint32}; !slices.Equal(got, want) {
+		t.Errorf("saturate: got %v but want %v", got, want)
+	}
+}
+
+func TestSizeMismatch(t *testing.T) {
+	src := dtype.FromSlice([]float32{1, 2})
+	if err := convert.Convert(make([]byte, 3), dtype.Float64, src, dtype.Float32, convert.Options{}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestComplex(t *testing.T) {
+	src := []complex64{complex(1.5, -2), complex(0, 3)}
+	widened, err := convert.Bytes(dtype.FromSlice(src), dtype.Complex64, dtype.Complex128, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[complex128](widened), []complex128{complex(1.5, -2), complex(0, 3)}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	// Converting to a real data type drops the imaginary part.
+	reals, err := convert.Bytes(dtype.FromSlice(src), dtype.Complex64, dtype.Float32, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[float32](reals), []float32{1.5, 0}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	bools, err := convert.Bytes(dtype.FromSlice(src), dtype.Complex64, dtype.Bool, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[bool](bools), []bool{true, true}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
+
+func TestNarrowIntegers(t *testing.T) {
+	src := []int32{300, -1, 100}
+	wrapped, err := convert.Bytes(dtype.FromSlice(src), dtype.Int32, dtype.Int8, convert.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[int8](wrapped), []int8{44, -1, 100}; !slices.Equal(got, want) {
+		t.Errorf("wrap: got %v but want %v", got, want)
+	}
+	saturated, err := convert.Bytes(dtype.FromSlice(src), dtype.Int32, dtype.Uint8, convert.Options{Saturate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[uint8](saturated), []uint8{255, 0, 100}; !slices.Equal(got, want) {
+		t.Errorf("saturate: got %v but want %v", got, want)
+	}
+}
@@ -13,12 +13,15 @@
 // limitations under the License.
 
 // Package dtype defines data types that can be supported by a platform.
+//
+// By default, ToSlice and FromSlice reinterpret memory with the unsafe package.
+// Building with the purego tag, for example for WebAssembly sandboxes which forbid
+// unsafe memory accesses, replaces them with copies: see ZeroCopy and WriteBack.
 package dtype
 
 import (
 	"fmt"
-	"reflect"
-	"unsafe"
+	"math/bits"
 )
 
 // DataType is the type of an atomic value or type of the data stored in an array.
@@ -37,6 +40,17 @@ const (
 	Bfloat16
 	Float32
 	Float64
+	Int4
+	Uint4
+	Float16
+	Complex64
+	Complex128
+	Int8
+	Int16
+	Uint8
+	Uint16
+
+	lastDataType // Not a data type: marks the end of the list above.
 
 	MaxDataType = 1 << 16 // Maximum value for a datatype.
 )
@@ -46,6 +60,8 @@ func (dt DataType) String() string {
 	switch dt {
 	case Bool:
 		return "bool"
+	case Int:
+		return "int"
 	case Int32:
 		return "int32"
 	case Int64:
@@ -60,10 +76,33 @@ func (dt DataType) String() string {
 		return "float32"
 	case Float64:
 		return "float64"
+	case Int4:
+		return "int4"
+	case Uint4:
+		return "uint4"
+	case Float16:
+		return "float16"
+	case Complex64:
+		return "complex64"
+	case Complex128:
+		return "complex128"
+	case Int8:
+		return "int8"
+	case Int16:
+		return "int16"
+	case Uint8:
+		return "uint8"
+	case Uint16:
+		return "uint16"
 	}
 	return "invalid"
 }
 
+// IsValid returns true if the data type is one of the data types defined by this package.
+func IsValid(d DataType) bool {
+	return d > Invalid && d < lastDataType
+}
+
 // Float is a constraint supporting floating-point type.
 type Float interface {
 	~float32 | ~float64
@@ -74,24 +113,40 @@ func IsFloat(d DataType) bool {
 	return d == Float32 || d == Float64
 }
 
+// Complex is a constraint supporting complex types.
+type Complex interface {
+	~complex64 | ~complex128
+}
+
+// IsComplex returns true if the data type is a complex number.
+func IsComplex(d DataType) bool {
+	return d == Complex64 || d == Complex128
+}
+
+// IsHalf returns true if the data type is a 16-bit float, that is Bfloat16 or Float16.
+// Half-precision types have no Go arithmetic and are not included in IsFloat.
+func IsHalf(d DataType) bool {
+	return d == Bfloat16 || d == Float16
+}
+
 // Signed is a constraint supporting signed integer type.
 type Signed interface {
-	~int32 | ~int64
+	~int | ~int8 | ~int16 | ~int32 | ~int64
 }
 
 // IsSigned returns true if the data type is a signed integer.
 func IsSigned(d DataType) bool {
-	return d == Int32 || d == Int64
+	return d == Int || d == Int8 || d == Int16 || d == Int32 || d == Int64
 }
 
 // Unsigned is a constraint supporting unsigned integer type.
 type Unsigned interface {
-	~uint32 | ~uint64
+	~uint8 | ~uint16 | ~uint32 | ~uint64
 }
 
 // IsUnsigned returns true if the data type is a unsigned integer.
 func IsUnsigned(d DataType) bool {
-	return d == Uint32 || d == Uint64
+	return d == Uint8 || d == Uint16 || d == Uint32 || d == Uint64
 }
 
 // NonAlgebraType are types on which common algebra operations are NOT supported.
@@ -116,12 +171,12 @@ func IsInteger(d DataType) bool {
 
 // AlgebraType are types on which common algebra operations between integers and floats are supported.
 type AlgebraType interface {
-	Float | IntegerType | Bfloat16T
+	Float | IntegerType | Bfloat16T | Float16T | Complex
 }
 
 // IsAlgebra returns true if the data type is an algebra type.
 func IsAlgebra(d DataType) bool {
-	return IsFloat(d) || IsInteger(d) || d == Bfloat16
+	return IsFloat(d) || IsInteger(d) || IsHalf(d) || IsComplex(d)
 }
 
 // GoDataType that can be stored in an array.
@@ -135,16 +190,32 @@ func Generic[T GoDataType]() DataType {
 	switch (any(t)).(type) {
 	case bool:
 		return Bool
+	case int:
+		return Int
 	case Bfloat16T:
 		return Bfloat16
+	case Float16T:
+		return Float16
 	case float32:
 		return Float32
 	case float64:
 		return Float64
+	case complex64:
+		return Complex64
+	case complex128:
+		return Complex128
+	case int8:
+		return Int8
+	case int16:
+		return Int16
 	case int32:
 		return Int32
 	case int64:
 		return Int64
+	case uint8:
+		return Uint8
+	case uint16:
+		return Uint16
 	case uint32:
 		return Uint32
 	case uint64:
@@ -155,47 +226,100 @@ func Generic[T GoDataType]() DataType {
 
 // Sizes of data type (in bytes).
 const (
-	BoolSize     = 1
-	Int32Size    = 4
-	Int64Size    = 8
-	Uint32Size   = 4
-	Uint64Size   = 8
-	Bfloat16Size = 2
-	Float32Size  = 4
-	Float64Size  = 8
+	BoolSize       = 1
+	IntSize        = bits.UintSize / 8
+	Int8Size       = 1
+	Int16Size      = 2
+	Int32Size      = 4
+	Int64Size      = 8
+	Uint8Size      = 1
+	Uint16Size     = 2
+	Uint32Size     = 4
+	Uint64Size     = 8
+	Bfloat16Size   = 2
+	Float16Size    = 2
+	Float32Size    = 4
+	Float64Size    = 8
+	Complex64Size  = 8
+	Complex128Size = 16
 )
 
+// IsSubByte returns true if atomic values of the data type are smaller than a byte.
+// Such values are packed in memory and have no addressable Go equivalent.
+func IsSubByte(d DataType) bool {
+	return d == Int4 || d == Uint4
+}
+
+// BitSizeof returns the size of an atomic value of a data type in bits.
+func BitSizeof(dt DataType) int {
+	if IsSubByte(dt) {
+		return 4
+	}
+	return 8 * Sizeof(dt)
+}
+
 // Sizeof returns the size of an atomic value of a data type.
+// It panics for sub-byte data types: use BitSizeof instead.
 func Sizeof(dt DataType) int {
 	switch dt {
 	case Bool:
 		return BoolSize
+	case Int:
+		return IntSize
+	case Int8:
+		return Int8Size
+	case Int16:
+		return Int16Size
 	case Int32:
 		return Int32Size
 	case Int64:
 		return Int64Size
+	case Uint8:
+		return Uint8Size
+	case Uint16:
+		return Uint16Size
 	case Uint32:
 		return Uint32Size
 	case Uint64:
 		return Uint64Size
 	case Bfloat16:
 		return Bfloat16Size
+	case Float16:
+		return Float16Size
 	case Float32:
 		return Float32Size
 	case Float64:
 		return Float64Size
+	case Complex64:
+		return Complex64Size
+	case Complex128:
+		return Complex128Size
 	}
 	panic(fmt.Sprint("invalid datatype: ", dt))
 }
 
 // ToSlice converts a []byte buffer into a slice of a given Go type.
+// It panics if the buffer cannot be reinterpreted as a slice of T.
 func ToSlice[T any](data []byte) []T {
-	var t T
-	size := int(unsafe.Sizeof(t))
-	if len(data)%size != 0 {
-		typeName := reflect.TypeFor[T]().String()
-		panic(fmt.Sprintf("data [%d]byte cannot be casted to []%s: %d %% sizeof(%s) != 0", len(data), typeName, len(data), typeName))
+	slice, err := ToSliceErr[T](data)
+	if err != nil {
+		panic(err.Error())
 	}
-	length := len(data) / size
-	return unsafe.Slice((*T)(unsafe.Pointer(&data[0])), length)
+	return slice
+}
+
+// WriteBack writes values obtained from ToSlice back into data.
+// It does nothing when ZeroCopy is true since the values share their memory with data.
+func WriteBack[T GoDataType](data []byte, vals []T) {
+	if !ZeroCopy {
+		copy(data, FromSlice(vals))
+	}
+}
+
+// CopyFromSlice returns a copy of the bytes backing a slice of a given Go type.
+func CopyFromSlice[T GoDataType](data []T) []byte {
+	src := FromSlice(data)
+	dst := make([]byte, len(src))
+	copy(dst, src)
+	return dst
 }
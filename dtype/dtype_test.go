@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestFromSlice(t *testing.T) {
+	want := []int32{1, -2, 3}
+	buf := FromSlice(want)
+	if len(buf) != len(want)*Int32Size {
+		t.Fatalf("got buffer of %d bytes but want %d", len(buf), len(want)*Int32Size)
+	}
+	if got := ToSlice[int32](buf); !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	cpy := CopyFromSlice(want)
+	want[0] = 42
+	if got := ToSlice[int32](cpy); got[0] != 1 {
+		t.Errorf("copy shares memory with the source: got %v", got)
+	}
+	if got := FromSlice[float32](nil); len(got) != 0 {
+		t.Errorf("got %v but want an empty buffer", got)
+	}
+}
+
+func TestToSliceErr(t *testing.T) {
+	buf := make([]byte, 3*Int64Size+1)
+	if _, err := ToSliceErr[int64](buf[:3*Int64Size-1]); err == nil {
+		t.Errorf("expected an error for a buffer length which is not a multiple of the type size")
+	}
+	// Copies made without the unsafe package can be decoded from any address.
+	if _, err := ToSliceErr[int64](buf[1 : 1+2*Int64Size]); ZeroCopy && err == nil {
+		t.Errorf("expected an error for a misaligned buffer")
+	}
+	got, err := ToSliceErr[int64](buf[:3*Int64Size])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got a slice of length %d but want 3", len(got))
+	}
+	if got, err := ToSliceErr[int64](nil); err != nil || len(got) != 0 {
+		t.Errorf("got %v, %v but want an empty slice and no error", got, err)
+	}
+}
+
+func TestPackBools(t *testing.T) {
+	src := []bool{true, false, false, true, true, false, true, false, false, true}
+	packed := PackBools(nil, src)
+	if want := []byte{0b01011001, 0b10}; !slices.Equal(packed, want) {
+		t.Errorf("got %08b but want %08b", packed, want)
+	}
+	if got := UnpackBools(nil, packed, len(src)); !slices.Equal(got, src) {
+		t.Errorf("got %v but want %v", got, src)
+	}
+	checkPanics(t, "UnpackBools with a short input", func() { UnpackBools(nil, packed[:1], len(src)) })
+}
+
+// checkPanics fails the test if f does not panic.
+func checkPanics(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if recover() == nil {
+			t.Errorf("%s: expected a panic", name)
+		}
+	}()
+	f()
+}
+
+func TestNumPy(t *testing.T) {
+	for _, dt := range []DataType{Bool, Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64, Float16, Float32, Float64, Complex64, Complex128} {
+		descr, err := ToNumPy(dt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := FromNumPy(descr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != dt {
+			t.Errorf("%s: round trip through %q returned %s", dt, descr, got)
+		}
+	}
+	if _, err := FromNumPy(">f4"); err == nil {
+		t.Errorf("expected an error for a big-endian dtype")
+	}
+	if _, err := ToNumPy(Bfloat16); err == nil {
+		t.Errorf("expected an error for bfloat16")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	type config struct {
+		DType DataType
+	}
+	data, err := json.Marshal(config{DType: Float32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"DType":"float32"}`; string(data) != want {
+		t.Errorf("got %s but want %s", data, want)
+	}
+	var got config
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.DType != Float32 {
+		t.Errorf("got %s but want %s", got.DType, Float32)
+	}
+	if err := json.Unmarshal([]byte(`{"DType":"float33"}`), &got); err == nil {
+		t.Errorf("expected an error for an unknown data type")
+	}
+	if _, err := json.Marshal(config{DType: Invalid}); err == nil {
+		t.Errorf("expected an error when marshaling an invalid data type")
+	}
+}
+
+func TestInt(t *testing.T) {
+	if got := Generic[int](); got != Int {
+		t.Errorf("Generic[int]() = %s but want %s", got, Int)
+	}
+	if got := Generic[complex128](); got != Complex128 {
+		t.Errorf("Generic[complex128]() = %s but want %s", got, Complex128)
+	}
+	if got, err := Parse(Int.String()); err != nil || got != Int {
+		t.Errorf("Parse(%q) = %s, %v but want %s", Int.String(), got, err, Int)
+	}
+	if got, want := Sizeof(Int), Sizeof(HostInt); got != want {
+		t.Errorf("Sizeof(Int) = %d but want %d", got, want)
+	}
+	if got := Resolve(Int, Int32); got != Int32 {
+		t.Errorf("Resolve(Int, Int32) = %s but want %s", got, Int32)
+	}
+	if got := Resolve(Float32, Int32); got != Float32 {
+		t.Errorf("Resolve(Float32, Int32) = %s but want %s", got, Float32)
+	}
+}
+
+func TestIsAligned(t *testing.T) {
+	buf := make([]byte, 16)
+	if !IsAligned(buf, Float64) {
+		t.Errorf("allocated buffer is not aligned for %s", Float64)
+	}
+	if IsAligned(buf[1:9], Float64) {
+		t.Errorf("buffer at offset 1 is aligned for %s", Float64)
+	}
+	if !IsAligned(buf[1:2], Bool) {
+		t.Errorf("buffer at offset 1 is not aligned for %s", Bool)
+	}
+}
+
+func TestPackInt4(t *testing.T) {
+	src := []int8{-8, 7, -1}
+	packed := PackInt4(nil, src)
+	if want := []byte{0x78, 0x0f}; !slices.Equal(packed, want) {
+		t.Errorf("got %x but want %x", packed, want)
+	}
+	if got := UnpackInt4(nil, packed, len(src)); !slices.Equal(got, src) {
+		t.Errorf("got %v but want %v", got, src)
+	}
+	usrc := []uint8{15, 0, 3}
+	if got := UnpackUint4(nil, PackUint4(nil, usrc), len(usrc)); !slices.Equal(got, usrc) {
+		t.Errorf("got %v but want %v", got, usrc)
+	}
+	if got := PackedSize(Int4, 3); got != 2 {
+		t.Errorf("PackedSize(Int4, 3) = %d but want 2", got)
+	}
+	checkPanics(t, "UnpackInt4 with a short input", func() { UnpackInt4(nil, packed[:1], len(src)) })
+	checkPanics(t, "UnpackUint4 with a short input", func() { UnpackUint4(nil, nil, 1) })
+}
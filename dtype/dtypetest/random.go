@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dtypetest provides deterministic test data for each data type.
+//
+// Values are chosen to be safe for arithmetic in tests:
+// floating-point values are in [-1, 1), signed integers in [-100, 100],
+// unsigned integers in [0, 100].
+package dtypetest
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+const intRange = 100
+
+// Fill fills a buffer with pseudo-random values of a given data type.
+// The same seed always generates the same values.
+func Fill(buf []byte, dt dtype.DataType, seed uint64) error {
+	dt = dtype.Resolve(dt, dtype.HostInt)
+	if !dtype.IsAlgebra(dt) && dt != dtype.Bool {
+		return fmt.Errorf("cannot generate random values for data type %s", dt)
+	}
+	if len(buf)%dtype.Sizeof(dt) != 0 {
+		return fmt.Errorf("buffer of %d bytes does not contain a whole number of %s elements", len(buf), dt)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewPCG(seed, uint64(dt)))
+	switch dt {
+	case dtype.Bool:
+		for i := range buf {
+			if rng.IntN(2) == 1 {
+				buf[i] = 1
+			} else {
+				buf[i] = 0
+			}
+		}
+	case dtype.Int8:
+		vals := dtype.ToSlice[int8](buf)
+		fillSigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Int16:
+		vals := dtype.ToSlice[int16](buf)
+		fillSigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Int32:
+		vals := dtype.ToSlice[int32](buf)
+		fillSigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Int64:
+		vals := dtype.ToSlice[int64](buf)
+		fillSigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Uint8:
+		vals := dtype.ToSlice[uint8](buf)
+		fillUnsigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Uint16:
+		vals := dtype.ToSlice[uint16](buf)
+		fillUnsigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Uint32:
+		vals := dtype.ToSlice[uint32](buf)
+		fillUnsigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Uint64:
+		vals := dtype.ToSlice[uint64](buf)
+		fillUnsigned(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Bfloat16:
+		vals := dtype.ToSlice[dtype.Bfloat16T](buf)
+		for i := range vals {
+			vals[i] = dtype.BFloat16FromFloat64(randFloat(rng, dtype.Bfloat16))
+		}
+		dtype.WriteBack(buf, vals)
+	case dtype.Float16:
+		vals := dtype.ToSlice[dtype.Float16T](buf)
+		for i := range vals {
+			vals[i] = dtype.Float16FromFloat64(randFloat(rng, dtype.Float16))
+		}
+		dtype.WriteBack(buf, vals)
+	case dtype.Float32:
+		vals := dtype.ToSlice[float32](buf)
+		fillFloat(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Float64:
+		vals := dtype.ToSlice[float64](buf)
+		fillFloat(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Complex64:
+		vals := dtype.ToSlice[complex64](buf)
+		fillComplex(rng, vals)
+		dtype.WriteBack(buf, vals)
+	case dtype.Complex128:
+		vals := dtype.ToSlice[complex128](buf)
+		fillComplex(rng, vals)
+		dtype.WriteBack(buf, vals)
+	default:
+		return fmt.Errorf("cannot generate random values for data type %s", dt)
+	}
+	return nil
+}
+
+// Bytes returns a buffer of n pseudo-random values of a given data type.
+func Bytes(dt dtype.DataType, n int, seed uint64) ([]byte, error) {
+	if !dtype.IsAlgebra(dt) && dt != dtype.Bool {
+		return nil, fmt.Errorf("cannot generate random values for data type %s", dt)
+	}
+	buf := make([]byte, n*dtype.Sizeof(dt))
+	if err := Fill(buf, dt, seed); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Slice returns a slice of n pseudo-random values.
+func Slice[T dtype.GoDataType](n int, seed uint64) []T {
+	buf, err := Bytes(dtype.Generic[T](), n, seed)
+	if err != nil {
+		panic(err)
+	}
+	return dtype.ToSlice[T](buf)
+}
+
+// randFloat returns a value in [-1, 1) which stays below 1 once rounded to dt.
+func randFloat(rng *rand.Rand, dt dtype.DataType) float64 {
+	// The largest value below 1 is exact in dt: smaller values cannot be rounded up to 1.
+	return min(2*rng.Float64()-1, 1-math.Ldexp(1, -significandBits(dt)))
+}
+
+// significandBits returns the number of bits of the significand of a floating-point data type,
+// including the implicit leading bit.
+func significandBits(dt dtype.DataType) int {
+	switch dt {
+	case dtype.Bfloat16:
+		return 8
+	case dtype.Float16:
+		return 11
+	case dtype.Float32, dtype.Complex64:
+		return 24
+	}
+	return 53
+}
+
+func fillSigned[T dtype.Signed](rng *rand.Rand, vals []T) {
+	for i := range vals {
+		vals[i] = T(rng.IntN(2*intRange+1) - intRange)
+	}
+}
+
+func fillUnsigned[T dtype.Unsigned](rng *rand.Rand, vals []T) {
+	for i := range vals {
+		vals[i] = T(rng.IntN(intRange + 1))
+	}
+}
+
+func fillFloat[T dtype.Float](rng *rand.Rand, vals []T) {
+	for i := range vals {
+		vals[i] = T(randFloat(rng, dtype.Generic[T]()))
+	}
+}
+
+func fillComplex[T dtype.Complex](rng *rand.Rand, vals []T) {
+	for i := range vals {
+		re := randFloat(rng, dtype.Generic[T]())
+		vals[i] = T(complex(re, randFloat(rng, dtype.Generic[T]())))
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtypetest_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/dtype/dtypetest"
+)
+
+func TestDeterministic(t *testing.T) {
+	a := dtypetest.Slice[float32](10, 42)
+	b := dtypetest.Slice[float32](10, 42)
+	if !slices.Equal(a, b) {
+		t.Errorf("same seed generated different values: %v != %v", a, b)
+	}
+	for _, x := range a {
+		if x < -1 || x >= 1 {
+			t.Errorf("value %f out of range", x)
+		}
+	}
+	if c := dtypetest.Slice[float32](10, 43); slices.Equal(a, c) {
+		t.Errorf("different seeds generated the same values: %v", a)
+	}
+	for _, x := range dtypetest.Slice[int64](100, 1) {
+		if x < -100 || x > 100 {
+			t.Errorf("value %d out of range", x)
+		}
+	}
+}
+
+func TestHalfPrecisionRange(t *testing.T) {
+	for _, x := range dtypetest.Slice[dtype.Bfloat16T](100000, 1) {
+		if v := x.Float32(); v < -1 || v >= 1 {
+			t.Fatalf("bfloat16 value %f out of range", v)
+		}
+	}
+	for _, x := range dtypetest.Slice[dtype.Float16T](100000, 1) {
+		if v := x.Float32(); v < -1 || v >= 1 {
+			t.Fatalf("float16 value %f out of range", v)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"math"
+	"strconv"
+)
+
+// Float16T is the 16-bit IEEE 754 half-precision floating-point format (binary16),
+// with 5 bits of exponent and 10 bits of mantissa. Like Bfloat16T, this implementation only
+// supports conversion to/from other formats, but no arithmetic.
+//
+// Conversions round to the nearest even value. Values too large for the format become
+// infinities and values too small become subnormals or zeros.
+type Float16T uint16
+
+// Float16FromFloat32 converts a float32 to a Float16.
+func Float16FromFloat32(x float32) Float16T {
+	bits := math.Float32bits(x)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+	if exp == 0xff {
+		if mant != 0 {
+			// Keep NaNs quiet and preserve the top bits of their payload.
+			return Float16T(sign | 0x7e00 | uint16(mant>>13))
+		}
+		return Float16T(sign | 0x7c00)
+	}
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return Float16T(sign | 0x7c00)
+	}
+	if e <= 0 {
+		if e < -10 {
+			return Float16T(sign)
+		}
+		// Subnormal: shift the mantissa, including its implicit leading bit.
+		mant |= 0x800000
+		shift := uint(14 - e)
+		m := mant >> shift
+		rem, half := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > half || (rem == half && m&1 == 1) {
+			m++
+		}
+		return Float16T(sign | uint16(m))
+	}
+	h := uint16(e)<<10 | uint16(mant>>13)
+	// A carry out of the mantissa correctly increments the exponent, up to infinity.
+	if rem := mant & 0x1fff; rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++
+	}
+	return Float16T(sign | h)
+}
+
+// Float16FromFloat64 converts a float64 to a Float16, going through float32.
+func Float16FromFloat64(x float64) Float16T {
+	return Float16FromFloat32(float32(x))
+}
+
+// Float32 returns a Float16 value in float32 format. The conversion is exact.
+func (f Float16T) Float32() float32 {
+	sign := uint32(f&0x8000) << 16
+	exp := uint32(f>>10) & 0x1f
+	mant := uint32(f & 0x3ff)
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Normalize the subnormal value.
+		exp = 127 - 15 + 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		return math.Float32frombits(sign | exp<<23 | (mant&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// Bits convert Float16 to an uint16.
+func (f Float16T) Bits() uint16 {
+	return uint16(f)
+}
+
+// String implements fmt.Stringer, and prints a float representation of a Float16.
+func (f Float16T) String() string {
+	return strconv.FormatFloat(float64(f.Float32()), 'f', -1, 32)
+}
+
+// Float16sFromFloat32s converts a slice of float32 into a slice of Float16.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func Float16sFromFloat32s(dst []Float16T, src []float32) []Float16T {
+	if cap(dst) < len(src) {
+		dst = make([]Float16T, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = Float16FromFloat32(x)
+	}
+	return dst
+}
+
+// Float16sFromFloat64s converts a slice of float64 into a slice of Float16.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func Float16sFromFloat64s(dst []Float16T, src []float64) []Float16T {
+	if cap(dst) < len(src) {
+		dst = make([]Float16T, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = Float16FromFloat64(x)
+	}
+	return dst
+}
+
+// Float32sFromFloat16s converts a slice of Float16 into a slice of float32.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func Float32sFromFloat16s(dst []float32, src []Float16T) []float32 {
+	if cap(dst) < len(src) {
+		dst = make([]float32, len(src))
+	}
+	dst = dst[:len(src)]
+	for i, x := range src {
+		dst[i] = x.Float32()
+	}
+	return dst
+}
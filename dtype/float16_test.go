@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFloat16(t *testing.T) {
+	tests := []struct {
+		x    float32
+		want uint16
+	}{
+		{x: 0, want: 0x0000},
+		{x: float32(math.Copysign(0, -1)), want: 0x8000},
+		{x: 1, want: 0x3c00},
+		{x: -2, want: 0xc000},
+		{x: 65504, want: 0x7bff},
+		{x: 65520, want: 0x7c00},
+		{x: float32(math.Inf(-1)), want: 0xfc00},
+		{x: 0x1p-14, want: 0x0400},
+		{x: 0x1p-24, want: 0x0001},
+		{x: 0x1p-25, want: 0x0000},
+		{x: 0x1.8p-25, want: 0x0001},
+		{x: 1 + 0x1p-11, want: 0x3c00},
+		{x: 1 + 0x3p-11, want: 0x3c02},
+	}
+	for _, test := range tests {
+		got := Float16FromFloat32(test.x)
+		if got.Bits() != test.want {
+			t.Errorf("Float16FromFloat32(%g) = %#04x but want %#04x", test.x, got.Bits(), test.want)
+		}
+	}
+	if got := Float16FromFloat32(float32(math.NaN())).Float32(); !math.IsNaN(float64(got)) {
+		t.Errorf("NaN converted to %g", got)
+	}
+	src := []float32{0, 1, -2.5, 1024, 0.15625, 0x1p-24, 65504}
+	got := Float32sFromFloat16s(nil, Float16sFromFloat32s(nil, src))
+	if !slices.Equal(got, src) {
+		t.Errorf("round trip: got %v but want %v", got, src)
+	}
+}
+
+func TestFloat16AllValues(t *testing.T) {
+	for bits := range math.MaxUint16 + 1 {
+		f := Float16T(bits)
+		x := f.Float32()
+		if math.IsNaN(float64(x)) {
+			continue
+		}
+		if got := Float16FromFloat32(x); got != f {
+			t.Errorf("%#04x converted to %g and back to %#04x", bits, x, got.Bits())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+// The Int data type is the platform integer: a signed integer which size
+// depends on where the code runs. On the host, Int has the size of the Go int type.
+// Before a graph is built, Int is resolved to a fixed-size data type (Int32 or Int64)
+// chosen by the platform. Converting between Int and its resolved type never changes
+// a value. Converting an Int to a narrower type wraps around, following Go conversion rules.
+
+// HostInt is the fixed-size data type with the same size as the Go int type.
+var HostInt = func() DataType {
+	if IntSize == Int32Size {
+		return Int32
+	}
+	return Int64
+}()
+
+// Resolve returns the fixed-size data type of dt given the data type used by a platform
+// to represent Int. Data types other than Int are returned unchanged.
+// If intType is Invalid, Int is resolved to HostInt.
+func Resolve(dt, intType DataType) DataType {
+	if dt != Int {
+		return dt
+	}
+	if intType == Invalid {
+		return HostInt
+	}
+	return intType
+}
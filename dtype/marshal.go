@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ encoding.TextMarshaler   = Invalid
+	_ encoding.TextUnmarshaler = (*DataType)(nil)
+	_ json.Marshaler           = Invalid
+	_ json.Unmarshaler         = (*DataType)(nil)
+)
+
+// Parse returns the data type given its name, as returned by String.
+func Parse(name string) (DataType, error) {
+	for dt := Invalid + 1; dt < lastDataType; dt++ {
+		if dt.String() == name {
+			return dt, nil
+		}
+	}
+	return Invalid, fmt.Errorf("unknown data type %q", name)
+}
+
+// MarshalText encodes the data type as its name.
+func (dt DataType) MarshalText() ([]byte, error) {
+	if parsed, err := Parse(dt.String()); err != nil || parsed != dt {
+		return nil, fmt.Errorf("cannot marshal data type %d: unknown data type", uint32(dt))
+	}
+	return []byte(dt.String()), nil
+}
+
+// UnmarshalText decodes a data type from its name.
+func (dt *DataType) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*dt = parsed
+	return nil
+}
+
+// MarshalJSON encodes the data type as a JSON string.
+func (dt DataType) MarshalJSON() ([]byte, error) {
+	text, err := dt.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes a data type from a JSON string.
+func (dt *DataType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("cannot unmarshal data type: %v", err)
+	}
+	return dt.UnmarshalText([]byte(name))
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// numpyCodes maps data types to NumPy type codes, without byte order.
+var numpyCodes = map[DataType]string{
+	Bool:       "b1",
+	Int8:       "i1",
+	Int16:      "i2",
+	Int32:      "i4",
+	Int64:      "i8",
+	Uint8:      "u1",
+	Uint16:     "u2",
+	Uint32:     "u4",
+	Uint64:     "u8",
+	Float16:    "f2",
+	Float32:    "f4",
+	Float64:    "f8",
+	Complex64:  "c8",
+	Complex128: "c16",
+}
+
+// ToNumPy returns the NumPy dtype string of a data type, for example "<f4" for Float32.
+// Multi-byte types are always described as little-endian.
+// Int is described using its size on the host.
+func ToNumPy(dt DataType) (string, error) {
+	dt = Resolve(dt, HostInt)
+	code, ok := numpyCodes[dt]
+	if !ok {
+		return "", fmt.Errorf("data type %s has no NumPy equivalent", dt)
+	}
+	if Sizeof(dt) == 1 {
+		return "|" + code, nil
+	}
+	return "<" + code, nil
+}
+
+// FromNumPy returns the data type of a NumPy dtype string, for example Float32 for "<f4".
+// Big-endian dtype strings are rejected.
+func FromNumPy(descr string) (DataType, error) {
+	code := descr
+	if len(code) > 0 {
+		switch code[0] {
+		case '<', '|', '=':
+			code = code[1:]
+		case '>':
+			return Invalid, fmt.Errorf("big-endian NumPy dtype %q is not supported", descr)
+		}
+	}
+	if code == "?" {
+		return Bool, nil
+	}
+	for dt, c := range numpyCodes {
+		if c == code {
+			return dt, nil
+		}
+	}
+	if strings.TrimSpace(code) == "" {
+		return Invalid, fmt.Errorf("empty NumPy dtype")
+	}
+	return Invalid, fmt.Errorf("NumPy dtype %q is not supported", descr)
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import "fmt"
+
+// PackedBoolsSize returns the number of bytes required to store n booleans packed 8 per byte.
+func PackedBoolsSize(n int) int {
+	return (n + 7) / 8
+}
+
+// PackBools packs booleans 8 per byte.
+// The ith boolean is stored in the bit i%8 (least significant bit first) of the byte i/8.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func PackBools(dst []byte, src []bool) []byte {
+	size := PackedBoolsSize(len(src))
+	if cap(dst) < size {
+		dst = make([]byte, size)
+	}
+	dst = dst[:size]
+	clear(dst)
+	for i, b := range src {
+		if b {
+			dst[i/8] |= 1 << (i % 8)
+		}
+	}
+	return dst
+}
+
+// UnpackBools unpacks n booleans stored 8 per byte by PackBools.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+// It panics if src is shorter than PackedBoolsSize(n) bytes.
+func UnpackBools(dst []bool, src []byte, n int) []bool {
+	checkPacked(src, PackedBoolsSize(n), n, "booleans")
+	if cap(dst) < n {
+		dst = make([]bool, n)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		dst[i] = src[i/8]&(1<<(i%8)) != 0
+	}
+	return dst
+}
+
+// checkPacked panics if src is shorter than size bytes, the size storing n packed values.
+func checkPacked(src []byte, size, n int, kind string) {
+	if len(src) < size {
+		panic(fmt.Sprintf("cannot unpack %d %s from %d bytes: want %d bytes", n, kind, len(src), size))
+	}
+}
+
+// PackedSize returns the number of bytes required to store n values of a data type.
+// Sub-byte values are packed, the first value of a byte being stored in its least significant bits.
+func PackedSize(dt DataType, n int) int {
+	return (n*BitSizeof(dt) + 7) / 8
+}
+
+// PackInt4 packs signed 4-bit integers two per byte, the first value being stored
+// in the least significant nibble. Values outside of [-8, 7] are truncated to their 4 lower bits.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func PackInt4(dst []byte, src []int8) []byte {
+	return packNibbles(dst, src)
+}
+
+// UnpackInt4 unpacks n signed 4-bit integers packed by PackInt4.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+// It panics if src is shorter than PackedSize(Int4, n) bytes.
+func UnpackInt4(dst []int8, src []byte, n int) []int8 {
+	checkPacked(src, PackedSize(Int4, n), n, "signed 4-bit integers")
+	if cap(dst) < n {
+		dst = make([]int8, n)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		// Shift the nibble into the high bits then back to extend the sign.
+		dst[i] = int8(nibble(src, i)<<4) >> 4
+	}
+	return dst
+}
+
+// PackUint4 packs unsigned 4-bit integers two per byte, the first value being stored
+// in the least significant nibble. Values greater than 15 are truncated to their 4 lower bits.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+func PackUint4(dst []byte, src []uint8) []byte {
+	return packNibbles(dst, src)
+}
+
+// UnpackUint4 unpacks n unsigned 4-bit integers packed by PackUint4.
+// The result is written into dst if it has enough capacity, otherwise a new slice is allocated.
+// It panics if src is shorter than PackedSize(Uint4, n) bytes.
+func UnpackUint4(dst []uint8, src []byte, n int) []uint8 {
+	checkPacked(src, PackedSize(Uint4, n), n, "unsigned 4-bit integers")
+	if cap(dst) < n {
+		dst = make([]uint8, n)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		dst[i] = nibble(src, i)
+	}
+	return dst
+}
+
+func packNibbles[T int8 | uint8](dst []byte, src []T) []byte {
+	size := PackedSize(Uint4, len(src))
+	if cap(dst) < size {
+		dst = make([]byte, size)
+	}
+	dst = dst[:size]
+	clear(dst)
+	for i, v := range src {
+		dst[i/2] |= (byte(v) & 0xf) << (4 * (i % 2))
+	}
+	return dst
+}
+
+func nibble(src []byte, i int) uint8 {
+	return (src[i/2] >> (4 * (i % 2))) & 0xf
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PerTensor is the quantization axis used when a single scale and zero point
+// apply to all the elements of an array.
+const PerTensor = -1
+
+// Quantization describes how real values are stored using an integer data type.
+// A real value r is represented by the quantized value q such that:
+//
+//	r = (q - ZeroPoint) * Scale
+type Quantization struct {
+	// Storage is the data type used to store quantized values.
+	Storage DataType
+
+	// Expressed is the data type of the values once dequantized.
+	Expressed DataType
+
+	// Axis along which the quantization parameters vary,
+	// or PerTensor if the same parameters apply to all elements.
+	Axis int
+
+	// Scales by which quantized values are multiplied.
+	// Contains exactly one element for per-tensor quantization.
+	Scales []float64
+
+	// ZeroPoints are the quantized values representing the real value 0.
+	// Must have the same length as Scales.
+	ZeroPoints []int64
+}
+
+// IsPerAxis returns true if the quantization parameters vary along an axis.
+func (q *Quantization) IsPerAxis() bool {
+	return q.Axis != PerTensor
+}
+
+// Check returns an error if the quantization parameters are inconsistent.
+func (q *Quantization) Check() error {
+	if !IsInteger(q.Storage) && !IsSubByte(q.Storage) {
+		return fmt.Errorf("quantization storage type %s is not an integer type", q.Storage)
+	}
+	if !IsFloat(q.Expressed) && !IsHalf(q.Expressed) {
+		return fmt.Errorf("quantization expressed type %s is not a floating-point type", q.Expressed)
+	}
+	if len(q.Scales) == 0 {
+		return fmt.Errorf("quantization has no scale")
+	}
+	if len(q.Scales) != len(q.ZeroPoints) {
+		return fmt.Errorf("quantization has %d scales but %d zero points", len(q.Scales), len(q.ZeroPoints))
+	}
+	if !q.IsPerAxis() && len(q.Scales) != 1 {
+		return fmt.Errorf("per-tensor quantization requires a single scale, got %d", len(q.Scales))
+	}
+	if q.Axis < PerTensor {
+		return fmt.Errorf("invalid quantization axis %d", q.Axis)
+	}
+	return nil
+}
+
+// Equal returns true if o represents the same quantization.
+func (q *Quantization) Equal(o *Quantization) bool {
+	if q == nil || o == nil {
+		return q == o
+	}
+	return q.Storage == o.Storage &&
+		q.Expressed == o.Expressed &&
+		q.Axis == o.Axis &&
+		slices.Equal(q.Scales, o.Scales) &&
+		slices.Equal(q.ZeroPoints, o.ZeroPoints)
+}
+
+// String returns a compact representation of the quantization.
+func (q *Quantization) String() string {
+	if q.IsPerAxis() || len(q.Scales) != 1 || len(q.ZeroPoints) != 1 {
+		return fmt.Sprintf("quant<%s:%s,axis=%d,scales=%v,zeros=%v>", q.Storage, q.Expressed, q.Axis, q.Scales, q.ZeroPoints)
+	}
+	return fmt.Sprintf("quant<%s:%s,scale=%v,zero=%v>", q.Storage, q.Expressed, q.Scales[0], q.ZeroPoints[0])
+}
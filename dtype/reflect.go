@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import "reflect"
+
+var (
+	bfloat16Type = reflect.TypeFor[Bfloat16T]()
+	float16Type  = reflect.TypeFor[Float16T]()
+)
+
+// FromReflectType returns the data type of values of a Go type.
+// Slices, arrays and pointers are unwrapped to their element type.
+// Returns Invalid if the type cannot be stored in an array.
+func FromReflectType(typ reflect.Type) DataType {
+	if typ == nil {
+		return Invalid
+	}
+	for {
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Pointer:
+			typ = typ.Elem()
+			continue
+		}
+		break
+	}
+	switch typ {
+	case bfloat16Type:
+		return Bfloat16
+	case float16Type:
+		return Float16
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return Bool
+	case reflect.Int:
+		return Int
+	case reflect.Int8:
+		return Int8
+	case reflect.Int16:
+		return Int16
+	case reflect.Int32:
+		return Int32
+	case reflect.Int64:
+		return Int64
+	case reflect.Uint8:
+		return Uint8
+	case reflect.Uint16:
+		return Uint16
+	case reflect.Uint32:
+		return Uint32
+	case reflect.Uint64:
+		return Uint64
+	case reflect.Float32:
+		return Float32
+	case reflect.Float64:
+		return Float64
+	case reflect.Complex64:
+		return Complex64
+	case reflect.Complex128:
+		return Complex128
+	}
+	return Invalid
+}
+
+// FromGoValue returns the data type of a Go value.
+// Slices, arrays and pointers are unwrapped to their element type.
+// Returns Invalid if the value cannot be stored in an array.
+func FromGoValue(v any) DataType {
+	return FromReflectType(reflect.TypeOf(v))
+}
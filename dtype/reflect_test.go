@@ -0,0 +1,45 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtype
+
+import "testing"
+
+type myFloat float32
+
+func TestFromGoValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  DataType
+	}{
+		{value: true, want: Bool},
+		{value: int32(1), want: Int32},
+		{value: uint64(1), want: Uint64},
+		{value: []byte{1}, want: Uint8},
+		{value: int16(1), want: Int16},
+		{value: float32(1), want: Float32},
+		{value: myFloat(1), want: Float32},
+		{value: BFloat16FromFloat32(1), want: Bfloat16},
+		{value: []Float16T{Float16FromFloat32(1)}, want: Float16},
+		{value: []float64{1, 2}, want: Float64},
+		{value: [][2]int64{{1, 2}}, want: Int64},
+		{value: "a string", want: Invalid},
+		{value: nil, want: Invalid},
+	}
+	for i, test := range tests {
+		if got := FromGoValue(test.value); got != test.want {
+			t.Errorf("test %d: FromGoValue(%#v) = %s but want %s", i, test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !purego
+
+package dtype
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// ZeroCopy is true when ToSlice and FromSlice share memory with their input.
+// It is false when building with the purego tag, in which case they return copies.
+const ZeroCopy = true
+
+// AlignOf returns the alignment, in bytes, required by an atomic value of a data type
+// when stored in memory.
+func AlignOf(dt DataType) int {
+	switch dt {
+	case Bool:
+		return int(unsafe.Alignof(false))
+	case Int:
+		return int(unsafe.Alignof(int(0)))
+	case Int8:
+		return int(unsafe.Alignof(int8(0)))
+	case Int16:
+		return int(unsafe.Alignof(int16(0)))
+	case Int32:
+		return int(unsafe.Alignof(int32(0)))
+	case Int64:
+		return int(unsafe.Alignof(int64(0)))
+	case Uint8:
+		return int(unsafe.Alignof(uint8(0)))
+	case Uint16:
+		return int(unsafe.Alignof(uint16(0)))
+	case Uint32:
+		return int(unsafe.Alignof(uint32(0)))
+	case Uint64:
+		return int(unsafe.Alignof(uint64(0)))
+	case Bfloat16:
+		return int(unsafe.Alignof(Bfloat16T(0)))
+	case Float16:
+		return int(unsafe.Alignof(Float16T(0)))
+	case Float32:
+		return int(unsafe.Alignof(float32(0)))
+	case Float64:
+		return int(unsafe.Alignof(float64(0)))
+	case Complex64:
+		return int(unsafe.Alignof(complex64(0)))
+	case Complex128:
+		return int(unsafe.Alignof(complex128(0)))
+	case Int4, Uint4:
+		return 1
+	}
+	panic(fmt.Sprint("invalid datatype: ", dt))
+}
+
+// IsAligned returns true if a buffer is aligned in memory for a data type.
+func IsAligned(data []byte, dt DataType) bool {
+	if len(data) == 0 {
+		return true
+	}
+	return uintptr(unsafe.Pointer(unsafe.SliceData(data)))%uintptr(AlignOf(dt)) == 0
+}
+
+// ToSliceErr converts a []byte buffer into a slice of a given Go type.
+// It returns an error if the length of the buffer is not a multiple of the size of T
+// or if the buffer is not aligned in memory for T.
+func ToSliceErr[T any](data []byte) ([]T, error) {
+	var t T
+	size := int(unsafe.Sizeof(t))
+	typeName := reflect.TypeFor[T]().String()
+	if size == 0 {
+		return nil, fmt.Errorf("cannot cast data to []%s: %s has a size of 0", typeName, typeName)
+	}
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("data [%d]byte cannot be casted to []%s: %d %% sizeof(%s) != 0", len(data), typeName, len(data), typeName)
+	}
+	if len(data) == 0 {
+		return []T{}, nil
+	}
+	ptr := unsafe.Pointer(unsafe.SliceData(data))
+	if align := uintptr(unsafe.Alignof(t)); uintptr(ptr)%align != 0 {
+		return nil, fmt.Errorf("data at address %p cannot be casted to []%s: address is not aligned to %d bytes", ptr, typeName, align)
+	}
+	return unsafe.Slice((*T)(ptr), len(data)/size), nil
+}
+
+// FromSlice returns the bytes backing a slice of a given Go type.
+// The returned buffer shares its memory with the slice.
+func FromSlice[T GoDataType](data []T) []byte {
+	if len(data) == 0 {
+		return []byte{}
+	}
+	var t T
+	size := int(unsafe.Sizeof(t))
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(data))), len(data)*size)
+}
+
+// CopyToSlice copies the bytes of src into dst and returns the number of elements copied.
+func CopyToSlice[T GoDataType](dst []T, src []byte) int {
+	var t T
+	return copy(FromSlice(dst), src) / int(unsafe.Sizeof(t))
+}
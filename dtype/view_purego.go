@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build purego
+
+package dtype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// ZeroCopy is true when ToSlice and FromSlice share memory with their input.
+// It is false when building with the purego tag, in which case they return copies.
+const ZeroCopy = false
+
+// AlignOf returns the alignment, in bytes, required by an atomic value of a data type
+// when stored in memory. Without the unsafe package, the size of the data type is used,
+// which is a valid alignment on all platforms.
+func AlignOf(dt DataType) int {
+	if dt == Bool || IsSubByte(dt) {
+		return 1
+	}
+	return Sizeof(dt)
+}
+
+// IsAligned returns true if a buffer is aligned in memory for a data type.
+func IsAligned(data []byte, dt DataType) bool {
+	if len(data) == 0 {
+		return true
+	}
+	return reflect.ValueOf(data).Pointer()%uintptr(AlignOf(dt)) == 0
+}
+
+// ToSliceErr decodes a []byte buffer into a new slice of a given Go type.
+// It returns an error if the length of the buffer is not a multiple of the size of T
+// or if T does not have a fixed size.
+func ToSliceErr[T any](data []byte) ([]T, error) {
+	size := fixedSize[T]()
+	typeName := reflect.TypeFor[T]().String()
+	if size <= 0 {
+		return nil, fmt.Errorf("cannot cast data to []%s: %s does not have a fixed size", typeName, typeName)
+	}
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("data [%d]byte cannot be casted to []%s: %d %% sizeof(%s) != 0", len(data), typeName, len(data), typeName)
+	}
+	slice := make([]T, len(data)/size)
+	if ints, ok := any(slice).([]int); ok {
+		decodeInts(ints, data)
+		return slice, nil
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.NativeEndian, slice); err != nil {
+		return nil, fmt.Errorf("cannot cast data to []%s: %v", typeName, err)
+	}
+	return slice, nil
+}
+
+// FromSlice returns a copy of the bytes of a slice of a given Go type.
+func FromSlice[T GoDataType](data []T) []byte {
+	var vals any = data
+	if ints, ok := vals.([]int); ok {
+		vals = encodeInts(ints)
+	}
+	buf, err := binary.Append(nil, binary.NativeEndian, vals)
+	if err != nil {
+		panic(err.Error())
+	}
+	if buf == nil {
+		return []byte{}
+	}
+	return buf
+}
+
+// CopyToSlice copies the bytes of src into dst and returns the number of elements copied.
+func CopyToSlice[T GoDataType](dst []T, src []byte) int {
+	size := fixedSize[T]()
+	n := min(len(dst), len(src)/size)
+	vals := ToSlice[T](src[:n*size])
+	return copy(dst, vals)
+}
+
+// fixedSize returns the size of T in bytes or -1 if T does not have a fixed size.
+// Unlike binary.Size, the size of int is the size of dtype.Int.
+func fixedSize[T any]() int {
+	var t T
+	if _, ok := any(t).(int); ok {
+		return IntSize
+	}
+	return binary.Size(t)
+}
+
+func decodeInts(dst []int, data []byte) {
+	for i := range dst {
+		if IntSize == 4 {
+			dst[i] = int(int32(binary.NativeEndian.Uint32(data[4*i:])))
+		} else {
+			dst[i] = int(binary.NativeEndian.Uint64(data[8*i:]))
+		}
+	}
+}
+
+func encodeInts(src []int) any {
+	if IntSize == 4 {
+		vals := make([]int32, len(src))
+		for i, v := range src {
+			vals[i] = int32(v)
+		}
+		return vals
+	}
+	vals := make([]int64, len(src))
+	for i, v := range src {
+		vals[i] = int64(v)
+	}
+	return vals
+}
@@ -0,0 +1,319 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gguf reads tensors from GGUF files, the weight format of llama.cpp.
+//
+// Tensors stored with a data type of the backend are read as is. Half-precision
+// and block-quantized tensors are dequantized to float32 while being read.
+// The raw bytes of any tensor, including k-quantized ones, are available with ReadRaw
+// for backends implementing GGML quantization natively.
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+const magic = "GGUF"
+
+// DefaultAlignment is the alignment of tensor data when general.alignment is not set.
+const DefaultAlignment = 32
+
+// Limits protecting against corrupted headers.
+const (
+	maxStringLength = 1 << 24
+	maxArrayLength  = 1 << 28
+	maxDims         = 8
+)
+
+// ValueType is the type of a metadata value.
+type ValueType uint32
+
+// Metadata value types.
+const (
+	TypeUint8 ValueType = iota
+	TypeInt8
+	TypeUint16
+	TypeInt16
+	TypeUint32
+	TypeInt32
+	TypeFloat32
+	TypeBool
+	TypeString
+	TypeArray
+	TypeUint64
+	TypeInt64
+	TypeFloat64
+)
+
+// TensorInfo describes a tensor stored in a GGUF file.
+type TensorInfo struct {
+	// Name of the tensor.
+	Name string
+	// Dims are the dimensions of the tensor in GGUF order: the first dimension varies fastest.
+	Dims []int
+	// Type is the GGML type of the tensor.
+	Type TensorType
+	// Offset of the tensor data from the start of the data section.
+	Offset int64
+}
+
+// NumElements returns the number of elements of the tensor.
+func (t *TensorInfo) NumElements() int {
+	return shape.Size(t.Dims)
+}
+
+// ByteSize returns the number of bytes used to store the tensor.
+func (t *TensorInfo) ByteSize() (int, error) {
+	info, ok := typeInfos[t.Type]
+	if !ok {
+		return 0, fmt.Errorf("tensor %s has unknown GGML type %s", t.Name, t.Type)
+	}
+	n := t.NumElements()
+	if n%info.blockSize != 0 {
+		return 0, fmt.Errorf("tensor %s of type %s has %d elements: not a multiple of the block size %d", t.Name, t.Type, n, info.blockSize)
+	}
+	return n / info.blockSize * info.blockBytes, nil
+}
+
+// Shape returns the shape of the host buffer returned by File.Read for the tensor.
+// Axes are listed from the slowest to the fastest varying, which is the reverse of Dims.
+func (t *TensorInfo) Shape() (*shape.Shape, error) {
+	info, ok := typeInfos[t.Type]
+	if !ok || info.dtype == dtype.Invalid {
+		return nil, fmt.Errorf("tensor %s: GGML type %s not supported", t.Name, t.Type)
+	}
+	axes := slices.Clone(t.Dims)
+	slices.Reverse(axes)
+	return shape.Of(info.dtype, axes...), nil
+}
+
+// File is a GGUF file of which the header has been read.
+type File struct {
+	// Version of the GGUF format.
+	Version uint32
+	// Metadata are the key-value pairs of the file.
+	// Arrays are stored as []any.
+	Metadata map[string]any
+	// Tensors stored in the file, in file order.
+	Tensors []*TensorInfo
+
+	r          io.ReaderAt
+	dataOffset int64
+}
+
+// Open reads the header of a GGUF file. Tensor data is read on demand.
+func Open(r io.ReaderAt) (*File, error) {
+	d := &decoder{r: bufio.NewReader(io.NewSectionReader(r, 0, math.MaxInt64))}
+	if got := d.bytes(4); d.err == nil && string(got) != magic {
+		return nil, fmt.Errorf("invalid GGUF file: got magic %q", got)
+	}
+	f := &File{r: r, Metadata: make(map[string]any)}
+	f.Version = d.uint32()
+	if d.err == nil && (f.Version < 2 || f.Version > 3) {
+		return nil, fmt.Errorf("GGUF version %d not supported", f.Version)
+	}
+	numTensors := d.length()
+	numKV := d.length()
+	for i := 0; i < numKV && d.err == nil; i++ {
+		key := d.string()
+		f.Metadata[key] = d.value(ValueType(d.uint32()))
+	}
+	for i := 0; i < numTensors && d.err == nil; i++ {
+		t := &TensorInfo{Name: d.string()}
+		numDims := int(d.uint32())
+		if numDims > maxDims {
+			return nil, fmt.Errorf("invalid GGUF file: tensor %s has %d dimensions", t.Name, numDims)
+		}
+		t.Dims = make([]int, numDims)
+		for j := range t.Dims {
+			t.Dims[j] = d.length()
+		}
+		t.Type = TensorType(d.uint32())
+		t.Offset = int64(d.uint64())
+		f.Tensors = append(f.Tensors, t)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("cannot read GGUF header: %v", d.err)
+	}
+	align := int64(DefaultAlignment)
+	if a, ok := f.Metadata["general.alignment"].(uint32); ok && a > 0 {
+		align = int64(a)
+	}
+	f.dataOffset = (d.pos + align - 1) / align * align
+	return f, nil
+}
+
+// Tensor returns the description of a tensor given its name.
+func (f *File) Tensor(name string) (*TensorInfo, bool) {
+	for _, t := range f.Tensors {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// ReadRaw returns the bytes storing a tensor, without any conversion.
+func (f *File) ReadRaw(t *TensorInfo) ([]byte, error) {
+	size, err := t.ByteSize()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := f.r.ReadAt(data, f.dataOffset+t.Offset); err != nil {
+		return nil, fmt.Errorf("cannot read data of tensor %s: %v", t.Name, err)
+	}
+	return data, nil
+}
+
+// Read reads a tensor into a host buffer allocated by alloc.
+// Half-precision and block-quantized tensors are dequantized to float32.
+// 8-bit and 16-bit integers are converted to int32.
+func (f *File) Read(t *TensorInfo, alloc platform.Allocator) (platform.HostBuffer, error) {
+	sh, err := t.Shape()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := f.ReadRaw(t)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := alloc.Allocate(sh)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Acquire()
+	err = typeInfos[t.Type].convert(data, raw)
+	buf.Release()
+	if err != nil {
+		buf.Free()
+		return nil, fmt.Errorf("cannot convert tensor %s: %v", t.Name, err)
+	}
+	return buf, nil
+}
+
+// decoder reads the little-endian values of a GGUF header.
+// The first error is stored and all subsequent reads return zero values.
+type decoder struct {
+	r   *bufio.Reader
+	pos int64
+	err error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	buf := make([]byte, n)
+	if _, d.err = io.ReadFull(d.r, buf); d.err != nil {
+		return nil
+	}
+	d.pos += int64(n)
+	return buf
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// length reads a 64-bit count and checks that it is within the limits of the decoder.
+func (d *decoder) length() int {
+	n := d.uint64()
+	if n > maxArrayLength && d.err == nil {
+		d.err = fmt.Errorf("length %d too large", n)
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.uint64()
+	if n > maxStringLength && d.err == nil {
+		d.err = fmt.Errorf("string of %d bytes too large", n)
+	}
+	return string(d.bytes(int(n)))
+}
+
+func (d *decoder) value(typ ValueType) any {
+	switch typ {
+	case TypeUint8:
+		return d.uint8()
+	case TypeInt8:
+		return int8(d.uint8())
+	case TypeUint16:
+		return d.uint16()
+	case TypeInt16:
+		return int16(d.uint16())
+	case TypeUint32:
+		return d.uint32()
+	case TypeInt32:
+		return int32(d.uint32())
+	case TypeFloat32:
+		return math.Float32frombits(d.uint32())
+	case TypeBool:
+		return d.uint8() != 0
+	case TypeString:
+		return d.string()
+	case TypeArray:
+		elemType := ValueType(d.uint32())
+		n := d.length()
+		var vals []any
+		for i := 0; i < n && d.err == nil; i++ {
+			vals = append(vals, d.value(elemType))
+		}
+		return vals
+	case TypeUint64:
+		return d.uint64()
+	case TypeInt64:
+		return int64(d.uint64())
+	case TypeFloat64:
+		return math.Float64frombits(d.uint64())
+	}
+	if d.err == nil {
+		d.err = fmt.Errorf("unknown metadata value type %d", typ)
+	}
+	return nil
+}
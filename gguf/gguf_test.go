@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gguf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/gguf"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+type tensor struct {
+	name string
+	dims []int
+	typ  gguf.TensorType
+	data []byte
+}
+
+// writer encodes a GGUF file.
+type writer struct {
+	bytes.Buffer
+}
+
+func (w *writer) put(v any) {
+	binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *writer) string(s string) {
+	w.put(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func encode(tensors []tensor) []byte {
+	var w writer
+	w.WriteString("GGUF")
+	w.put(uint32(3))
+	w.put(uint64(len(tensors)))
+	w.put(uint64(2))
+	w.string("general.name")
+	w.put(uint32(gguf.TypeString))
+	w.string("tiny")
+	w.string("tokenizer.scores")
+	w.put(uint32(gguf.TypeArray))
+	w.put(uint32(gguf.TypeFloat32))
+	w.put(uint64(2))
+	w.put([]float32{0.5, -1})
+	var offset uint64
+	for _, t := range tensors {
+		w.string(t.name)
+		w.put(uint32(len(t.dims)))
+		for _, d := range t.dims {
+			w.put(uint64(d))
+		}
+		w.put(uint32(t.typ))
+		w.put(offset)
+		offset += uint64(len(t.data)+31) / 32 * 32
+	}
+	for _, t := range tensors {
+		w.Write(make([]byte, (32-w.Len()%32)%32))
+		w.Write(t.data)
+	}
+	return w.Bytes()
+}
+
+func float32s(vals ...float32) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, vals)
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	// Q8_0 block with a scale of 0.5 (0x3800 in half precision).
+	q8 := []byte{0x00, 0x38}
+	for i := range 32 {
+		q8 = append(q8, byte(int8(i-16)))
+	}
+	// Q4_0 block with a scale of 2 (0x4000 in half precision).
+	q4 := []byte{0x00, 0x40}
+	for i := range 16 {
+		q4 = append(q4, byte(i)|byte(15-i)<<4)
+	}
+	data := encode([]tensor{
+		{name: "w", dims: []int{3, 2}, typ: gguf.F32, data: float32s(1, 2, 3, 4, 5, 6)},
+		{name: "h", dims: []int{3}, typ: gguf.F16, data: []byte{0x00, 0x3c, 0x00, 0xc0, 0x01, 0x00}},
+		{name: "q8", dims: []int{32}, typ: gguf.Q8_0, data: q8},
+		{name: "q4", dims: []int{32}, typ: gguf.Q4_0, data: q4},
+	})
+	f, err := gguf.Open(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Metadata["general.name"]; got != "tiny" {
+		t.Errorf("got general.name %v but want tiny", got)
+	}
+	if got := f.Metadata["tokenizer.scores"]; !slices.Equal(got.([]any), []any{float32(0.5), float32(-1)}) {
+		t.Errorf("got tokenizer.scores %v but want [0.5 -1]", got)
+	}
+	wantQ8 := make([]float32, 32)
+	wantQ4 := make([]float32, 32)
+	for i := range 32 {
+		wantQ8[i] = float32(i-16) * 0.5
+	}
+	for i := range 16 {
+		wantQ4[i] = float32(i-8) * 2
+		wantQ4[i+16] = float32(15-i-8) * 2
+	}
+	tests := []struct {
+		name  string
+		shape string
+		want  []float32
+	}{
+		{name: "w", shape: "[2][3]float32", want: []float32{1, 2, 3, 4, 5, 6}},
+		{name: "h", shape: "[3]float32", want: []float32{1, -2, float32(math.Ldexp(1, -24))}},
+		{name: "q8", shape: "[32]float32", want: wantQ8},
+		{name: "q4", shape: "[32]float32", want: wantQ4},
+	}
+	alloc := platformtest.New(1)
+	for _, test := range tests {
+		info, ok := f.Tensor(test.name)
+		if !ok {
+			t.Errorf("tensor %s not found", test.name)
+			continue
+		}
+		buf, err := f.Read(info, alloc)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := buf.Shape().String(); got != test.shape {
+			t.Errorf("%s: got shape %s but want %s", test.name, got, test.shape)
+		}
+		got := slices.Clone(dtype.ToSlice[float32](buf.AcquireRead()))
+		buf.ReleaseRead()
+		buf.Free()
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %v but want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	f, err := gguf.Open(bytes.NewReader(encode([]tensor{
+		{name: "k", dims: []int{256}, typ: gguf.Q4_K, data: make([]byte, 144)},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := f.Tensor("k")
+	if _, err := f.Read(info, platformtest.New(1)); err == nil {
+		t.Errorf("expected an error when reading a k-quantized tensor")
+	}
+	raw, err := f.ReadRaw(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 144 {
+		t.Errorf("got %d raw bytes but want 144", len(raw))
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	data := encode([]tensor{{name: "w", dims: []int{2}, typ: gguf.F32, data: float32s(1, 2)}})
+	for _, n := range []int{0, 3, 10, 30, 60} {
+		if _, err := gguf.Open(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("truncated header of %d bytes opened without error", n)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gguf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// TensorType is the GGML type of a tensor.
+type TensorType uint32
+
+// GGML tensor types.
+const (
+	F32  TensorType = 0
+	F16  TensorType = 1
+	Q4_0 TensorType = 2
+	Q4_1 TensorType = 3
+	Q5_0 TensorType = 6
+	Q5_1 TensorType = 7
+	Q8_0 TensorType = 8
+	Q8_1 TensorType = 9
+	Q2_K TensorType = 10
+	Q3_K TensorType = 11
+	Q4_K TensorType = 12
+	Q5_K TensorType = 13
+	Q6_K TensorType = 14
+	Q8_K TensorType = 15
+	I8   TensorType = 24
+	I16  TensorType = 25
+	I32  TensorType = 26
+	I64  TensorType = 27
+	F64  TensorType = 28
+	BF16 TensorType = 30
+)
+
+// typeInfo describes how a GGML type is stored and read.
+type typeInfo struct {
+	name       string
+	blockSize  int
+	blockBytes int
+	// dtype is the data type of the host buffer of a tensor, or dtype.Invalid
+	// if the type can only be read with ReadRaw.
+	dtype dtype.DataType
+	// convert fills the host buffer from the raw tensor data.
+	convert func(dst, src []byte) error
+}
+
+var typeInfos = map[TensorType]typeInfo{
+	F32:  {"F32", 1, 4, dtype.Float32, copyRaw},
+	F16:  {"F16", 1, 2, dtype.Float32, convertF16},
+	Q4_0: {"Q4_0", 32, 18, dtype.Float32, dequantizeBlocks(18, dequantizeQ4_0)},
+	Q4_1: {"Q4_1", 32, 20, dtype.Float32, dequantizeBlocks(20, dequantizeQ4_1)},
+	Q5_0: {"Q5_0", 32, 22, dtype.Float32, dequantizeBlocks(22, dequantizeQ5_0)},
+	Q5_1: {"Q5_1", 32, 24, dtype.Float32, dequantizeBlocks(24, dequantizeQ5_1)},
+	Q8_0: {"Q8_0", 32, 34, dtype.Float32, dequantizeBlocks(34, dequantizeQ8_0)},
+	Q8_1: {"Q8_1", 32, 36, dtype.Invalid, nil},
+	Q2_K: {"Q2_K", 256, 84, dtype.Invalid, nil},
+	Q3_K: {"Q3_K", 256, 110, dtype.Invalid, nil},
+	Q4_K: {"Q4_K", 256, 144, dtype.Invalid, nil},
+	Q5_K: {"Q5_K", 256, 176, dtype.Invalid, nil},
+	Q6_K: {"Q6_K", 256, 210, dtype.Invalid, nil},
+	Q8_K: {"Q8_K", 256, 292, dtype.Invalid, nil},
+	I8:   {"I8", 1, 1, dtype.Int32, convertI8},
+	I16:  {"I16", 1, 2, dtype.Int32, convertI16},
+	I32:  {"I32", 1, 4, dtype.Int32, copyRaw},
+	I64:  {"I64", 1, 8, dtype.Int64, copyRaw},
+	F64:  {"F64", 1, 8, dtype.Float64, copyRaw},
+	BF16: {"BF16", 1, 2, dtype.Bfloat16, copyRaw},
+}
+
+func (t TensorType) String() string {
+	if info, ok := typeInfos[t]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("TensorType(%d)", uint32(t))
+}
+
+func copyRaw(dst, src []byte) error {
+	if len(dst) != len(src) {
+		return fmt.Errorf("got %d bytes but want %d", len(src), len(dst))
+	}
+	copy(dst, src)
+	return nil
+}
+
+func convertI8(dst, src []byte) error {
+	for i, v := range src {
+		binary.LittleEndian.PutUint32(dst[4*i:], uint32(int32(int8(v))))
+	}
+	return nil
+}
+
+func convertI16(dst, src []byte) error {
+	for i := range len(src) / 2 {
+		v := int16(binary.LittleEndian.Uint16(src[2*i:]))
+		binary.LittleEndian.PutUint32(dst[4*i:], uint32(int32(v)))
+	}
+	return nil
+}
+
+func convertF16(dst, src []byte) error {
+	for i := range len(src) / 2 {
+		putFloat32(dst, i, dtype.Float16T(binary.LittleEndian.Uint16(src[2*i:])).Float32())
+	}
+	return nil
+}
+
+func putFloat32(dst []byte, i int, v float32) {
+	binary.LittleEndian.PutUint32(dst[4*i:], math.Float32bits(v))
+}
+
+// dequantizeBlocks returns a function dequantizing blocks of 32 values to float32.
+func dequantizeBlocks(blockBytes int, dequantize func(out *[32]float32, block []byte)) func(dst, src []byte) error {
+	return func(dst, src []byte) error {
+		var out [32]float32
+		for b := range len(src) / blockBytes {
+			dequantize(&out, src[b*blockBytes:(b+1)*blockBytes])
+			for i, v := range out {
+				putFloat32(dst, 32*b+i, v)
+			}
+		}
+		return nil
+	}
+}
+
+func half(b []byte) float32 {
+	return dtype.Float16T(binary.LittleEndian.Uint16(b)).Float32()
+}
+
+func dequantizeQ4_0(out *[32]float32, block []byte) {
+	d, qs := half(block), block[2:]
+	for j := range 16 {
+		out[j] = float32(int(qs[j]&0xf)-8) * d
+		out[j+16] = float32(int(qs[j]>>4)-8) * d
+	}
+}
+
+func dequantizeQ4_1(out *[32]float32, block []byte) {
+	d, m, qs := half(block), half(block[2:]), block[4:]
+	for j := range 16 {
+		out[j] = float32(qs[j]&0xf)*d + m
+		out[j+16] = float32(qs[j]>>4)*d + m
+	}
+}
+
+func dequantizeQ5_0(out *[32]float32, block []byte) {
+	d, qh, qs := half(block), binary.LittleEndian.Uint32(block[2:]), block[6:]
+	for j := range 16 {
+		h0 := byte((qh>>j)<<4) & 0x10
+		h1 := byte(qh>>(j+12)) & 0x10
+		out[j] = float32(int(qs[j]&0xf|h0)-16) * d
+		out[j+16] = float32(int(qs[j]>>4|h1)-16) * d
+	}
+}
+
+func dequantizeQ5_1(out *[32]float32, block []byte) {
+	d, m, qh, qs := half(block), half(block[2:]), binary.LittleEndian.Uint32(block[4:]), block[8:]
+	for j := range 16 {
+		h0 := byte((qh>>j)<<4) & 0x10
+		h1 := byte(qh>>(j+12)) & 0x10
+		out[j] = float32(qs[j]&0xf|h0)*d + m
+		out[j+16] = float32(qs[j]>>4|h1)*d + m
+	}
+}
+
+func dequantizeQ8_0(out *[32]float32, block []byte) {
+	d, qs := half(block), block[2:]
+	for j := range 32 {
+		out[j] = float32(int8(qs[j])) * d
+	}
+}
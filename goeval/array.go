@@ -0,0 +1,360 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
+
+// value is the result of a node: an *array or a tuple.
+type value any
+
+// tuple is the value of a tuple node.
+type tuple []value
+
+// kind groups the data types computed with the same Go type.
+type kind int
+
+const (
+	boolKind kind = iota
+	signedKind
+	unsignedKind
+	floatKind
+	complexKind
+)
+
+func kindOf(dt dtype.DataType) kind {
+	switch {
+	case dtype.IsSigned(dt), dt == dtype.Int4:
+		return signedKind
+	case dtype.IsUnsigned(dt), dt == dtype.Uint4:
+		return unsignedKind
+	case dtype.IsFloat(dt), dtype.IsHalf(dt):
+		return floatKind
+	case dtype.IsComplex(dt):
+		return complexKind
+	}
+	return boolKind
+}
+
+// array is an array evaluated by the interpreter.
+//
+// Elements are stored in row-major order in the widest Go type of the kind of their
+// data type: []bool, []int64, []uint64, []float64 or []complex128. Operations compute
+// with these types and call fit to round or wrap their results to the data type.
+type array struct {
+	// dt is the data type of the elements, in which dtype.Int has been resolved.
+	dt   dtype.DataType
+	dims []int
+	data any
+}
+
+// zeros returns an array of zeros.
+func zeros(dt dtype.DataType, dims []int) *array {
+	n := shape.Size(dims)
+	a := &array{dt: dt, dims: dims}
+	switch kindOf(dt) {
+	case signedKind:
+		a.data = make([]int64, n)
+	case unsignedKind:
+		a.data = make([]uint64, n)
+	case floatKind:
+		a.data = make([]float64, n)
+	case complexKind:
+		a.data = make([]complex128, n)
+	default:
+		a.data = make([]bool, n)
+	}
+	return a
+}
+
+// newArray returns an array storing data after having fitted it to the data type.
+func newArray(dt dtype.DataType, dims []int, data any) *array {
+	fit(dt, data)
+	return &array{dt: dt, dims: dims, data: data}
+}
+
+func (a *array) size() int {
+	return shape.Size(a.dims)
+}
+
+// fit rounds floating-point values and wraps integer values to a data type, in place.
+func fit(dt dtype.DataType, data any) {
+	switch vals := data.(type) {
+	case []int64:
+		shift := 64 - dtype.BitSizeof(dt)
+		for i, v := range vals {
+			vals[i] = v << shift >> shift
+		}
+	case []uint64:
+		shift := 64 - dtype.BitSizeof(dt)
+		for i, v := range vals {
+			vals[i] = v << shift >> shift
+		}
+	case []float64:
+		var round func(float64) float64
+		switch dt {
+		case dtype.Float32:
+			round = func(v float64) float64 { return float64(float32(v)) }
+		case dtype.Float16:
+			round = func(v float64) float64 { return float64(dtype.Float16FromFloat64(v).Float32()) }
+		case dtype.Bfloat16:
+			round = func(v float64) float64 { return float64(dtype.BFloat16FromFloat64(v).Float32()) }
+		default:
+			return
+		}
+		for i, v := range vals {
+			vals[i] = round(v)
+		}
+	case []complex128:
+		if dt != dtype.Complex64 {
+			return
+		}
+		for i, v := range vals {
+			vals[i] = complex128(complex64(v))
+		}
+	}
+}
+
+// convert returns a slice converting every element of src.
+func convert[S, D any](src []S, f func(S) D) []D {
+	dst := make([]D, len(src))
+	for i, v := range src {
+		dst[i] = f(v)
+	}
+	return dst
+}
+
+// widen reads n values of type S from data and converts them.
+func widen[S dtype.GoDataType, D any](data []byte, n int, f func(S) D) []D {
+	src := make([]S, n)
+	dtype.CopyToSlice(src, data)
+	return convert(src, f)
+}
+
+func identity[T any](v T) T { return v }
+
+// decode reads an array from its memory representation.
+// dt is the data type of the elements, in which dtype.Int has been resolved.
+func decode(dt dtype.DataType, sh *shape.Shape, data []byte) (*array, error) {
+	if !sh.IsContiguous() {
+		dense := sh.WithLayout(nil)
+		buf := make([]byte, dense.ByteSize())
+		if err := shape.Relayout(buf, dense, data, sh); err != nil {
+			return nil, err
+		}
+		sh, data = dense, buf
+	}
+	if len(data) != sh.ByteSize() {
+		return nil, fmt.Errorf("got %d bytes for an array of shape %s: want %d bytes", len(data), sh, sh.ByteSize())
+	}
+	n := sh.Size()
+	a := &array{dt: dt, dims: slices.Clone(sh.AxisLengths)}
+	switch dt {
+	case dtype.Bool:
+		if sh.IsBitPacked() {
+			a.data = dtype.UnpackBools(nil, data, n)
+		} else {
+			a.data = widen(data, n, identity[bool])
+		}
+	case dtype.Int4:
+		a.data = convert(dtype.UnpackInt4(nil, data, n), func(v int8) int64 { return int64(v) })
+	case dtype.Int8:
+		a.data = widen(data, n, func(v int8) int64 { return int64(v) })
+	case dtype.Int16:
+		a.data = widen(data, n, func(v int16) int64 { return int64(v) })
+	case dtype.Int32:
+		a.data = widen(data, n, func(v int32) int64 { return int64(v) })
+	case dtype.Int64:
+		a.data = widen(data, n, identity[int64])
+	case dtype.Uint4:
+		a.data = convert(dtype.UnpackUint4(nil, data, n), func(v uint8) uint64 { return uint64(v) })
+	case dtype.Uint8:
+		a.data = widen(data, n, func(v uint8) uint64 { return uint64(v) })
+	case dtype.Uint16:
+		a.data = widen(data, n, func(v uint16) uint64 { return uint64(v) })
+	case dtype.Uint32:
+		a.data = widen(data, n, func(v uint32) uint64 { return uint64(v) })
+	case dtype.Uint64:
+		a.data = widen(data, n, identity[uint64])
+	case dtype.Bfloat16:
+		a.data = widen(data, n, func(v dtype.Bfloat16T) float64 { return float64(v.Float32()) })
+	case dtype.Float16:
+		a.data = widen(data, n, func(v dtype.Float16T) float64 { return float64(v.Float32()) })
+	case dtype.Float32:
+		a.data = widen(data, n, func(v float32) float64 { return float64(v) })
+	case dtype.Float64:
+		a.data = widen(data, n, identity[float64])
+	case dtype.Complex64:
+		a.data = widen(data, n, func(v complex64) complex128 { return complex128(v) })
+	case dtype.Complex128:
+		a.data = widen(data, n, identity[complex128])
+	default:
+		return nil, fmt.Errorf("data type %s not supported", dt)
+	}
+	return a, nil
+}
+
+// encode returns the memory representation of an array given its shape.
+func (a *array) encode(sh *shape.Shape) ([]byte, error) {
+	if !sh.IsContiguous() {
+		dense := sh.WithLayout(nil)
+		data, err := a.encode(dense)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, sh.ByteSize())
+		if err := shape.Relayout(buf, sh, data, dense); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+	switch vals := a.data.(type) {
+	case []bool:
+		if sh.IsBitPacked() {
+			return dtype.PackBools(nil, vals), nil
+		}
+		return dtype.CopyFromSlice(vals), nil
+	case []int64:
+		switch a.dt {
+		case dtype.Int4:
+			return dtype.PackInt4(nil, convert(vals, func(v int64) int8 { return int8(v) })), nil
+		case dtype.Int8:
+			return dtype.CopyFromSlice(convert(vals, func(v int64) int8 { return int8(v) })), nil
+		case dtype.Int16:
+			return dtype.CopyFromSlice(convert(vals, func(v int64) int16 { return int16(v) })), nil
+		case dtype.Int32:
+			return dtype.CopyFromSlice(convert(vals, func(v int64) int32 { return int32(v) })), nil
+		}
+		return dtype.CopyFromSlice(vals), nil
+	case []uint64:
+		switch a.dt {
+		case dtype.Uint4:
+			return dtype.PackUint4(nil, convert(vals, func(v uint64) uint8 { return uint8(v) })), nil
+		case dtype.Uint8:
+			return dtype.CopyFromSlice(convert(vals, func(v uint64) uint8 { return uint8(v) })), nil
+		case dtype.Uint16:
+			return dtype.CopyFromSlice(convert(vals, func(v uint64) uint16 { return uint16(v) })), nil
+		case dtype.Uint32:
+			return dtype.CopyFromSlice(convert(vals, func(v uint64) uint32 { return uint32(v) })), nil
+		}
+		return dtype.CopyFromSlice(vals), nil
+	case []float64:
+		switch a.dt {
+		case dtype.Bfloat16:
+			return dtype.CopyFromSlice(convert(vals, dtype.BFloat16FromFloat64)), nil
+		case dtype.Float16:
+			return dtype.CopyFromSlice(convert(vals, dtype.Float16FromFloat64)), nil
+		case dtype.Float32:
+			return dtype.CopyFromSlice(convert(vals, func(v float64) float32 { return float32(v) })), nil
+		}
+		return dtype.CopyFromSlice(vals), nil
+	case []complex128:
+		if a.dt == dtype.Complex64 {
+			return dtype.CopyFromSlice(convert(vals, func(v complex128) complex64 { return complex64(v) })), nil
+		}
+		return dtype.CopyFromSlice(vals), nil
+	}
+	return nil, fmt.Errorf("cannot write an array of %s", a.dt)
+}
+
+// take returns an array of the given axis lengths with the elements of a at the given offsets.
+// Negative offsets are replaced by the atomic value of fill, which can only be nil if
+// all offsets are positive.
+func (a *array) take(dims []int, offsets []int, fill *array) *array {
+	switch vals := a.data.(type) {
+	case []bool:
+		return &array{dt: a.dt, dims: dims, data: takeFrom(vals, offsets, fill)}
+	case []int64:
+		return &array{dt: a.dt, dims: dims, data: takeFrom(vals, offsets, fill)}
+	case []uint64:
+		return &array{dt: a.dt, dims: dims, data: takeFrom(vals, offsets, fill)}
+	case []float64:
+		return &array{dt: a.dt, dims: dims, data: takeFrom(vals, offsets, fill)}
+	case []complex128:
+		return &array{dt: a.dt, dims: dims, data: takeFrom(vals, offsets, fill)}
+	}
+	panic(fmt.Sprintf("invalid array data %T", a.data))
+}
+
+func takeFrom[T any](src []T, offsets []int, fill *array) []T {
+	dst := make([]T, len(offsets))
+	for i, off := range offsets {
+		if off < 0 {
+			dst[i] = fill.data.([]T)[0]
+			continue
+		}
+		dst[i] = src[off]
+	}
+	return dst
+}
+
+// reshape returns an array sharing the data of a with different axis lengths.
+func (a *array) reshape(dims []int) *array {
+	return &array{dt: a.dt, dims: dims, data: a.data}
+}
+
+// clone returns a copy of an array.
+func (a *array) clone() *array {
+	c := &array{dt: a.dt, dims: a.dims}
+	switch vals := a.data.(type) {
+	case []bool:
+		c.data = slices.Clone(vals)
+	case []int64:
+		c.data = slices.Clone(vals)
+	case []uint64:
+		c.data = slices.Clone(vals)
+	case []float64:
+		c.data = slices.Clone(vals)
+	case []complex128:
+		c.data = slices.Clone(vals)
+	}
+	return c
+}
+
+// scalarInt returns the value of an atomic integer array.
+func scalarInt(a *array) (int64, error) {
+	switch vals := a.data.(type) {
+	case []int64:
+		return vals[0], nil
+	case []uint64:
+		if vals[0] > math.MaxInt64 {
+			return math.MaxInt64, nil
+		}
+		return int64(vals[0]), nil
+	}
+	return 0, fmt.Errorf("%s is not an integer data type", a.dt)
+}
+
+// ints returns the values of an integer array as int64.
+func ints(a *array) ([]int64, error) {
+	switch vals := a.data.(type) {
+	case []int64:
+		return vals, nil
+	case []uint64:
+		return convert(vals, func(v uint64) int64 {
+			if v > math.MaxInt64 {
+				return math.MaxInt64
+			}
+			return int64(v)
+		}), nil
+	}
+	return nil, fmt.Errorf("%s is not an integer data type", a.dt)
+}
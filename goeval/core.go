@@ -0,0 +1,646 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+	"go/ast"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type coreBuilder struct {
+	g *Graph
+}
+
+var _ ops.CoreBuilder = coreBuilder{}
+
+func (b coreBuilder) Graph() ops.Graph {
+	return b.g
+}
+
+func (b coreBuilder) Constant(buf platform.HostBuffer) (ops.Node, error) {
+	sh := buf.Shape()
+	data := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	if data == nil {
+		return nil, platform.ErrBufferFreed
+	}
+	a, err := decode(b.g.resolve(sh.DType), sh, data)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.newNode(ops.OpConstant, typ{shape: sh}, nil, func(*frame, []value) (value, error) {
+		return a, nil
+	})
+}
+
+func (b coreBuilder) Tuple(nodes []ops.Node) (ops.Tuple, error) {
+	elems := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		var err error
+		if elems[i], err = b.g.node(n); err != nil {
+			return nil, err
+		}
+	}
+	node, err := b.g.newNode(ops.OpTuple, typ{elems: typesOf(elems)}, elems, func(_ *frame, in []value) (value, error) {
+		return tuple(in), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node.(*Tuple), nil
+}
+
+func (b coreBuilder) Call(sg *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	sub, result, err := b.g.subgraph(sg)
+	if err != nil {
+		return nil, err
+	}
+	argNodes, err := b.g.arrays(args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := sub.checkArgs(typesOf(argNodes)); err != nil {
+		return nil, err
+	}
+	return b.g.newNode(ops.OpCall, result.typ, argNodes, func(f *frame, in []value) (value, error) {
+		return sub.call(f, result, in)
+	})
+}
+
+func (b coreBuilder) Subgraph(name string, args []*shape.Shape) (ops.Graph, error) {
+	sub := &Graph{plat: b.g.plat, name: name, parent: b.g, args: args, params: make(map[int]*Node)}
+	b.g.subs = append(b.g.subs, sub)
+	return sub, nil
+}
+
+func (b coreBuilder) Argument(name string, sh *shape.Shape, index int) (ops.Node, error) {
+	if b.g.parent != nil {
+		if index < 0 || index >= len(b.g.args) {
+			return nil, fmt.Errorf("argument %s: index %d out of range [0, %d)", name, index, len(b.g.args))
+		}
+		if !sh.EqualIgnoringLayout(b.g.args[index]) {
+			return nil, fmt.Errorf("argument %s has shape %s but subgraph %s expects %s", name, sh, b.g.name, b.g.args[index])
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("argument %s: invalid index %d", name, index)
+	}
+	if err := ops.CheckStatic(sh); err != nil {
+		return nil, fmt.Errorf("argument %s: %w", name, err)
+	}
+	if arg, ok := b.g.params[index]; ok {
+		if !sh.EqualIgnoringLayout(arg.typ.shape) {
+			return nil, fmt.Errorf("argument %s has shape %s but argument %d already has shape %s", name, sh, index, arg.typ.shape)
+		}
+		return arg, nil
+	}
+	arg, err := b.g.newNode(ops.OpArgument, typ{shape: sh}, nil, func(f *frame, _ []value) (value, error) {
+		return f.args[index], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.g.params[index] = arg.(*Node)
+	return arg, nil
+}
+
+func (b coreBuilder) Unary(op *ast.UnaryExpr, x ops.Node) (ops.Node, error) {
+	return ops.UnaryFromAST(b, op, x)
+}
+
+func (b coreBuilder) Binary(op *ast.BinaryExpr, x, y ops.Node) (ops.Node, error) {
+	return ops.BinaryFromAST(b, op, x, y)
+}
+
+func (b coreBuilder) UnaryOp(op ops.UnaryOperator, x ops.Node) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.UnaryOpShape(op, nodes[0].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpUnary, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		return unaryOp(op, dt, in[0])
+	})
+}
+
+func (b coreBuilder) BinaryOp(op ops.BinaryOperator, x, y ops.Node) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, y)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.BinaryOpShape(op, nodes[0].typ.shape, nodes[1].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpBinary, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		return binaryOp(op, dt, in[0], in[1])
+	})
+}
+
+func (b coreBuilder) Reshape(x ops.Node, axisLengths []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.ReshapeShape(nodes[0].typ.shape, axisLengths)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpReshape, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return in[0].reshape(sh.AxisLengths), nil
+	})
+}
+
+func (b coreBuilder) Concat(axis int, nodes []ops.Node) (ops.Node, error) {
+	xs, err := b.g.arrays(nodes...)
+	if err != nil {
+		return nil, err
+	}
+	shapes := make([]*shape.Shape, len(xs))
+	for i, x := range xs {
+		shapes[i] = x.typ.shape
+	}
+	sh, err := shape.ConcatShape(axis, shapes...)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpConcat, sh, xs, func(_ dtype.DataType, in []*array) (*array, error) {
+		return concat(axis, sh.AxisLengths, in), nil
+	})
+}
+
+func (b coreBuilder) Cast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.CastShape(nodes[0].typ.shape, target)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpCast, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		return cast(dt, in[0]), nil
+	})
+}
+
+func (b coreBuilder) Slice(x ops.Node, index int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.SliceShape(nodes[0].typ.shape, index)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpSlice, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return slice(in[0], index), nil
+	})
+}
+
+func (b coreBuilder) Set(x, updates, index ops.Node) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, updates, index)
+	if err != nil {
+		return nil, err
+	}
+	xShape, updatesShape := nodes[0].typ.shape, nodes[1].typ.shape
+	if err := checkIndex(nodes[2].typ.shape); err != nil {
+		return nil, fmt.Errorf("cannot set a slice: %w", err)
+	}
+	want, err := shape.SliceShape(xShape, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(updatesShape.AxisLengths, want.AxisLengths) || updatesShape.DType != want.DType {
+		return nil, fmt.Errorf("cannot set a slice of %s with updates of shape %s", xShape, updatesShape)
+	}
+	return b.g.compute(ops.OpSet, xShape, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		i, err := scalarInt(in[2])
+		if err != nil {
+			return nil, err
+		}
+		return set(in[0], in[1], i), nil
+	})
+}
+
+// DotGeneral computes dot products with the highest precision: the precision is ignored.
+func (b coreBuilder) DotGeneral(x, y ops.Node, batchAxes, reduceAxes [2][]int, _ ops.Precision) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, y)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.DotGeneralShape(nodes[0].typ.shape, nodes[1].typ.shape, batchAxes, reduceAxes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpDotGeneral, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		return dotGeneral(dt, in[0], in[1], batchAxes, reduceAxes, sh.AxisLengths)
+	})
+}
+
+// unpack returns the elements of a tuple value or the value itself.
+func unpack(v value) []value {
+	if t, ok := v.(tuple); ok {
+		return t
+	}
+	return []value{v}
+}
+
+// atomicBool returns the value of an atomic boolean array.
+func atomicBool(v value) bool {
+	return v.(*array).data.([]bool)[0]
+}
+
+// isAtomicBool returns true if a type is an atomic boolean array.
+func isAtomicBool(t typ) bool {
+	return !t.isTuple() && t.shape.DType == dtype.Bool && t.shape.IsAtomic()
+}
+
+func (b coreBuilder) While(cond, body *ops.Subgraph, state ops.Node) (ops.Node, error) {
+	stateNode, err := b.g.node(state)
+	if err != nil {
+		return nil, err
+	}
+	condGraph, condResult, err := b.g.subgraph(cond)
+	if err != nil {
+		return nil, err
+	}
+	bodyGraph, bodyResult, err := b.g.subgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	if !isAtomicBool(condResult.typ) {
+		return nil, fmt.Errorf("condition %s of while loop does not return an atomic boolean", condGraph.name)
+	}
+	if !b.g.sameType(bodyResult.typ, stateNode.typ) {
+		return nil, fmt.Errorf("body %s of while loop returns %s but the state is %s", bodyGraph.name, bodyResult.typ, stateNode.typ)
+	}
+	args := []typ{stateNode.typ}
+	if stateNode.typ.isTuple() {
+		args = stateNode.typ.elems
+	}
+	for _, sub := range []*Graph{condGraph, bodyGraph} {
+		if err := sub.checkArgs(args); err != nil {
+			return nil, err
+		}
+	}
+	return b.g.newNode(ops.OpWhile, stateNode.typ, []*Node{stateNode}, func(f *frame, in []value) (value, error) {
+		state := in[0]
+		for {
+			pred, err := condGraph.call(f, condResult, unpack(state))
+			if err != nil {
+				return nil, err
+			}
+			if !atomicBool(pred) {
+				return state, nil
+			}
+			if state, err = bodyGraph.call(f, bodyResult, unpack(state)); err != nil {
+				return nil, err
+			}
+		}
+	})
+}
+
+func (b coreBuilder) Cond(pred ops.Node, trueBranch, falseBranch *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	predNode, err := b.g.node(pred)
+	if err != nil {
+		return nil, err
+	}
+	if !isAtomicBool(predNode.typ) {
+		return nil, fmt.Errorf("condition %s is not an atomic boolean", predNode)
+	}
+	argNodes, err := b.g.arrays(args...)
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]*Graph, 2)
+	results := make([]*Node, 2)
+	for i, sg := range []*ops.Subgraph{trueBranch, falseBranch} {
+		if branches[i], results[i], err = b.g.subgraph(sg); err != nil {
+			return nil, err
+		}
+		if err := branches[i].checkArgs(typesOf(argNodes)); err != nil {
+			return nil, err
+		}
+	}
+	if !b.g.sameType(results[0].typ, results[1].typ) {
+		return nil, fmt.Errorf("branches %s and %s return mismatched types %s and %s", branches[0].name, branches[1].name, results[0].typ, results[1].typ)
+	}
+	return b.g.newNode(ops.OpCond, results[0].typ, append([]*Node{predNode}, argNodes...), func(f *frame, in []value) (value, error) {
+		if atomicBool(in[0]) {
+			return branches[0].call(f, results[0], in[1:])
+		}
+		return branches[1].call(f, results[1], in[1:])
+	})
+}
+
+func (b coreBuilder) Select(pred, onTrue, onFalse ops.Node) (ops.Node, error) {
+	nodes, err := b.g.arrays(pred, onTrue, onFalse)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.SelectShape(nodes[0].typ.shape, nodes[1].typ.shape, nodes[2].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpSelect, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		pred, onTrue, onFalse := in[0].data.([]bool), in[1], in[2]
+		offsets := make([]int, onTrue.size())
+		for i := range offsets {
+			offsets[i] = i
+			if !pred[i%len(pred)] {
+				offsets[i] += len(offsets)
+			}
+		}
+		return join(onTrue.dt, []*array{onTrue, onFalse}).take(onTrue.dims, offsets, nil), nil
+	})
+}
+
+func (b coreBuilder) BroadcastInDim(x ops.Node, sh *shape.Shape, broadcastAxes []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	out, err := shape.BroadcastInDimShape(nodes[0].typ.shape, sh.AxisLengths, broadcastAxes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpBroadcastInDim, out, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return broadcastInDim(in[0], out.AxisLengths, broadcastAxes), nil
+	})
+}
+
+// quantParams returns, for every element of an array, the index of its quantization parameters.
+func quantParams(quant *dtype.Quantization, dims []int) []int {
+	if !quant.IsPerAxis() {
+		return make([]int, shape.Size(dims))
+	}
+	return mapIndices(dims, func(idx []int) int {
+		return idx[quant.Axis]
+	})
+}
+
+// Quantize rounds values to the nearest even quantized value and clamps them to
+// the range of the storage data type, as the StableHLO uniform_quantize operation.
+func (b coreBuilder) Quantize(x ops.Node, quant *dtype.Quantization) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.QuantizeShape(nodes[0].typ.shape, quant)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpQuantize, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		vals := toFloats(in[0])
+		for i, p := range quantParams(quant, in[0].dims) {
+			vals[i] = math.RoundToEven(vals[i]/quant.Scales[p]) + float64(quant.ZeroPoints[p])
+		}
+		return cast(dt, &array{dt: dtype.Float64, dims: in[0].dims, data: vals}), nil
+	})
+}
+
+func (b coreBuilder) Dequantize(x ops.Node) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	xShape := nodes[0].typ.shape
+	sh, err := intercept.DequantizeShape(xShape)
+	if err != nil {
+		return nil, err
+	}
+	quant := xShape.Quant
+	return b.g.compute(ops.OpDequantize, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		vals := toFloats(in[0])
+		for i, p := range quantParams(quant, in[0].dims) {
+			vals[i] = (vals[i] - float64(quant.ZeroPoints[p])) * quant.Scales[p]
+		}
+		return newArray(dt, in[0].dims, vals), nil
+	})
+}
+
+// reduceOp applies one of the ReduceSum, ReduceProd, ReduceMax or ReduceMin operations.
+func (b coreBuilder) reduceOp(op ops.OpID, x ops.Node, axes []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ReduceOpShape(op, nodes[0].typ.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(op, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		return reduce(op, dt, in[0], axes)
+	})
+}
+
+func (b coreBuilder) ReduceSum(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceSum, x, axes)
+}
+
+func (b coreBuilder) ReduceProd(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceProd, x, axes)
+}
+
+func (b coreBuilder) ReduceMax(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceMax, x, axes)
+}
+
+func (b coreBuilder) ReduceMin(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceMin, x, axes)
+}
+
+// combiner returns the graph of a subgraph combining two atomic values of a data type
+// and the node of its result.
+func (g *Graph) combiner(body *ops.Subgraph, dt dtype.DataType) (*Graph, *Node, error) {
+	sub, result, err := g.subgraph(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	elem := typ{shape: shape.Scalar(dt)}
+	if !g.sameType(result.typ, elem) {
+		return nil, nil, fmt.Errorf("subgraph %s returns %s but want %s", sub.name, result.typ, elem)
+	}
+	if err := sub.checkArgs([]typ{elem, elem}); err != nil {
+		return nil, nil, err
+	}
+	return sub, result, nil
+}
+
+func (b coreBuilder) Reduce(body *ops.Subgraph, init, x ops.Node, axes []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(init, x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ReduceShape(nodes[0].typ.shape, nodes[1].typ.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	sub, result, err := b.g.combiner(body, sh.DType)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpReduce, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return reduceWith(sub, result, in[0], in[1], axes)
+	})
+}
+
+func (b coreBuilder) Gather(x, indices ops.Node, dims ops.GatherDims, sliceSizes []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, indices)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.GatherShape(nodes[0].typ.shape, nodes[1].typ.shape, dims, sliceSizes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpGather, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return gather(in[0], in[1], dims, sliceSizes, sh.AxisLengths)
+	})
+}
+
+func (b coreBuilder) Scatter(x, indices, updates ops.Node, dims ops.ScatterDims) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, indices, updates)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ScatterShape(nodes[0].typ.shape, nodes[1].typ.shape, nodes[2].typ.shape, dims)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpScatter, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return scatter(in[0], in[1], in[2], dims)
+	})
+}
+
+func (b coreBuilder) Transpose(x ops.Node, permutation []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.TransposeShape(nodes[0].typ.shape, permutation)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpTranspose, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return transpose(in[0], permutation, sh.AxisLengths), nil
+	})
+}
+
+func (b coreBuilder) Reverse(x ops.Node, axes []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.ReverseShape(nodes[0].typ.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpReverse, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return reverse(in[0], axes), nil
+	})
+}
+
+func (b coreBuilder) Pad(x, val ops.Node, low, high, interior []int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, val)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.PadShape(nodes[0].typ.shape, nodes[1].typ.shape, low, high, interior)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpPad, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		return pad(in[0], in[1], sh.AxisLengths, low, interior), nil
+	})
+}
+
+// sortAxis returns the node of the array to sort and the axis along which it is sorted.
+func (b coreBuilder) sortAxis(op ops.OpID, x ops.Node, axis int) (*Node, *shape.Shape, int, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	xShape := nodes[0].typ.shape
+	sh, err := intercept.SortShape(op, xShape, axis)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	axis, err = shape.NormalizeAxis(axis, len(xShape.AxisLengths))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return nodes[0], sh, axis, nil
+}
+
+func (b coreBuilder) Sort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	xNode, sh, axis, err := b.sortAxis(ops.OpSort, x, axis)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpSort, sh, []*Node{xNode}, func(_ dtype.DataType, in []*array) (*array, error) {
+		values, _ := sortAlong(in[0], axis, descending)
+		return values, nil
+	})
+}
+
+func (b coreBuilder) ArgSort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	xNode, sh, axis, err := b.sortAxis(ops.OpArgSort, x, axis)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpArgSort, sh, []*Node{xNode}, func(_ dtype.DataType, in []*array) (*array, error) {
+		_, indices := sortAlong(in[0], axis, descending)
+		return indices, nil
+	})
+}
+
+func (b coreBuilder) TopK(x ops.Node, k int) (ops.Tuple, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.TopKShape(nodes[0].typ.shape, k)
+	if err != nil {
+		return nil, err
+	}
+	indicesShape := &shape.Shape{DType: dtype.Int32, AxisLengths: sh.AxisLengths}
+	t := typ{elems: []typ{{shape: sh}, {shape: indicesShape}}}
+	node, err := b.g.newNode(ops.OpTopK, t, nodes, func(_ *frame, in []value) (value, error) {
+		x := in[0].(*array)
+		axis := len(x.dims) - 1
+		values, indices := sortAlong(x, axis, true)
+		xStrides := strides(x.dims)
+		firstK := mapIndices(sh.AxisLengths, func(idx []int) int {
+			return offsetOf(idx, xStrides)
+		})
+		return tuple{values.take(sh.AxisLengths, firstK, nil), indices.take(sh.AxisLengths, firstK, nil)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node.(*Tuple), nil
+}
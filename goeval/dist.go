@@ -0,0 +1,295 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type distBuilder struct {
+	g *Graph
+}
+
+var (
+	_ ops.DistBuilder  = distBuilder{}
+	_ ops.DistGraph    = (*Graph)(nil)
+	_ ops.MeshCompiler = (*Graph)(nil)
+)
+
+// Dist returns the builder for collective operations.
+func (g *Graph) Dist() ops.DistBuilder {
+	return distBuilder{g: g}
+}
+
+// rootMesh returns the mesh for which the root graph is built.
+func (g *Graph) rootMesh() *platform.Mesh {
+	for g.parent != nil {
+		g = g.parent
+	}
+	return g.mesh
+}
+
+// collective is an operation exchanging arrays between the devices of the groups of a mesh.
+type collective struct {
+	op       ops.OpID
+	meshAxes []string
+	// compute returns the result of a device given its rank in its group
+	// and the arrays of all the devices of the group.
+	compute func(rank int, vals []*array) (*array, error)
+}
+
+func (c *collective) eval(f *frame, in []value) (value, error) {
+	if f.rep == nil {
+		return nil, fmt.Errorf("%s requires a graph compiled for a mesh", c.op)
+	}
+	group, err := f.rep.mesh.GroupOf(f.rep.index, c.meshAxes)
+	if err != nil {
+		return nil, err
+	}
+	rank := slices.Index(group, f.rep.index)
+	vals, err := f.rep.share(f.ctx, c, group, rank, in[0].(*array))
+	if err != nil {
+		return nil, err
+	}
+	return c.compute(rank, vals)
+}
+
+// collective adds a collective operation to the graph given a function returning
+// the shape of its result from the size of a group of devices.
+func (b distBuilder) collective(op ops.OpID, x ops.Node, meshAxes []string, infer func(mesh *platform.Mesh, groupSize int, x *shape.Shape) (*shape.Shape, error), compute func(dt dtype.DataType, dims []int, rank int, vals []*array) (*array, error)) (ops.Node, error) {
+	mesh := b.g.rootMesh()
+	if mesh == nil {
+		return nil, fmt.Errorf("%s requires a graph built for a mesh", op)
+	}
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	size, err := mesh.GroupSize(meshAxes)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := infer(mesh, size, nodes[0].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	dt := b.g.resolve(sh.DType)
+	c := &collective{op: op, meshAxes: slices.Clone(meshAxes), compute: func(rank int, vals []*array) (*array, error) {
+		return compute(dt, sh.AxisLengths, rank, vals)
+	}}
+	return b.g.newNode(op, typ{shape: sh}, nodes, c.eval)
+}
+
+func (b distBuilder) AllReduce(x ops.Node, op platform.ReduceOp, meshAxes []string) (ops.Node, error) {
+	return b.collective(ops.OpAllReduce, x, meshAxes, func(_ *platform.Mesh, _ int, x *shape.Shape) (*shape.Shape, error) {
+		return intercept.AllReduceShape(op, x)
+	}, func(dt dtype.DataType, _ []int, _ int, vals []*array) (*array, error) {
+		return allReduce(op, dt, vals)
+	})
+}
+
+func (b distBuilder) AllGather(x ops.Node, axis int, meshAxes []string) (ops.Node, error) {
+	return b.collective(ops.OpAllGather, x, meshAxes, func(_ *platform.Mesh, size int, x *shape.Shape) (*shape.Shape, error) {
+		return intercept.AllGatherShape(x, axis, size)
+	}, func(_ dtype.DataType, dims []int, _ int, vals []*array) (*array, error) {
+		return concat(axis, dims, vals), nil
+	})
+}
+
+func (b distBuilder) ReduceScatter(x ops.Node, op platform.ReduceOp, axis int, meshAxes []string) (ops.Node, error) {
+	return b.collective(ops.OpReduceScatter, x, meshAxes, func(_ *platform.Mesh, size int, x *shape.Shape) (*shape.Shape, error) {
+		return intercept.ReduceScatterShape(op, x, axis, size)
+	}, func(dt dtype.DataType, dims []int, rank int, vals []*array) (*array, error) {
+		sum, err := allReduce(op, dt, vals)
+		if err != nil {
+			return nil, err
+		}
+		return block(sum, axis, rank, dims), nil
+	})
+}
+
+func (b distBuilder) CollectivePermute(x ops.Node, meshAxis string, pairs [][2]int) (ops.Node, error) {
+	pairs = slices.Clone(pairs)
+	return b.collective(ops.OpCollectivePermute, x, []string{meshAxis}, func(mesh *platform.Mesh, _ int, x *shape.Shape) (*shape.Shape, error) {
+		return intercept.CollectivePermuteShape(x, mesh.AxisSizes()[mesh.AxisIndex(meshAxis)], pairs)
+	}, func(dt dtype.DataType, dims []int, rank int, vals []*array) (*array, error) {
+		for _, pair := range pairs {
+			if pair[1] == rank {
+				return vals[pair[0]], nil
+			}
+		}
+		return zeros(dt, dims), nil
+	})
+}
+
+// reduceOps maps the reductions of collective operations to the reductions of the core builder.
+var reduceOps = map[platform.ReduceOp]ops.OpID{
+	platform.ReduceSum:  ops.OpReduceSum,
+	platform.ReduceProd: ops.OpReduceProd,
+	platform.ReduceMin:  ops.OpReduceMin,
+	platform.ReduceMax:  ops.OpReduceMax,
+}
+
+// allReduce reduces arrays of the same shape element-wise.
+func allReduce(op platform.ReduceOp, dt dtype.DataType, vals []*array) (*array, error) {
+	reduceOp, ok := reduceOps[op]
+	if !ok {
+		return nil, fmt.Errorf("invalid all-reduce operation %s", op)
+	}
+	dims := vals[0].dims
+	stacked := make([]*array, len(vals))
+	for i, v := range vals {
+		stacked[i] = v.reshape(append([]int{1}, dims...))
+	}
+	return reduce(reduceOp, dt, concat(0, append([]int{len(vals)}, dims...), stacked), []int{0})
+}
+
+// block returns the ith block of x of the given axis lengths along an axis.
+func block(x *array, axis, i int, dims []int) *array {
+	xStrides := strides(x.dims)
+	start := i * dims[axis]
+	return x.take(dims, mapIndices(dims, func(idx []int) int {
+		idx[axis] += start
+		off := offsetOf(idx, xStrides)
+		idx[axis] -= start
+		return off
+	}), nil)
+}
+
+// replica is the evaluation of a graph on one device of a mesh.
+type replica struct {
+	mesh  *platform.Mesh
+	index int
+	ex    *exchange
+	// counts is the number of evaluations of each collective operation by the replica,
+	// such that the same operation evaluated in a loop uses a different exchange slot
+	// at every iteration.
+	counts map[*collective]int
+}
+
+// share gives x to the other devices of a group and returns the arrays of all the devices.
+func (r *replica) share(ctx context.Context, c *collective, group []int, rank int, x *array) ([]*array, error) {
+	count := r.counts[c]
+	r.counts[c]++
+	return r.ex.share(ctx, slotKey{c: c, count: count, group: group[0]}, len(group), rank, x)
+}
+
+type (
+	// exchange is the rendezvous of the replicas of a run.
+	exchange struct {
+		mu     sync.Mutex
+		slots  map[slotKey]*slot
+		once   sync.Once
+		failed chan struct{}
+		err    error
+	}
+
+	slotKey struct {
+		c     *collective
+		count int
+		// group is the index of the first device of the group.
+		group int
+	}
+
+	slot struct {
+		vals    []*array
+		arrived int
+		ready   chan struct{}
+	}
+)
+
+func newExchange() *exchange {
+	return &exchange{slots: make(map[slotKey]*slot), failed: make(chan struct{})}
+}
+
+// share waits for all the devices of a group to provide their arrays.
+func (e *exchange) share(ctx context.Context, key slotKey, size, rank int, x *array) ([]*array, error) {
+	e.mu.Lock()
+	s := e.slots[key]
+	if s == nil {
+		s = &slot{vals: make([]*array, size), ready: make(chan struct{})}
+		e.slots[key] = s
+	}
+	s.vals[rank] = x
+	if s.arrived++; s.arrived == size {
+		delete(e.slots, key)
+		close(s.ready)
+	}
+	e.mu.Unlock()
+	select {
+	case <-s.ready:
+		return s.vals, nil
+	case <-e.failed:
+		return nil, fmt.Errorf("aborted by another device: %w", e.err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// abort wakes up all the replicas waiting for an exchange.
+func (e *exchange) abort(err error) {
+	e.once.Do(func() {
+		e.err = err
+		close(e.failed)
+	})
+}
+
+// CompileMesh returns a runner evaluating the graph once for every device of a mesh.
+// All the devices of the mesh are the CPU device, possibly repeated to simulate multiple devices.
+func (g *Graph) CompileMesh(mesh *platform.Mesh, output, traced []*ops.OutputNode, params []*ops.MeshParam) (ops.MeshRunner, error) {
+	if built := g.rootMesh(); built != nil && (!slices.Equal(built.AxisNames(), mesh.AxisNames()) || !slices.Equal(built.AxisSizes(), mesh.AxisSizes())) {
+		return nil, fmt.Errorf("graph %s built for mesh %s cannot be compiled for mesh %s", g.name, built, mesh)
+	}
+	shapes := make([]*shape.Shape, len(params))
+	for i, param := range params {
+		shapes[i] = param.Shape
+	}
+	runners := make([]*runner, len(mesh.Devices()))
+	for i, dev := range mesh.Devices() {
+		r, err := g.Compile(dev, output, traced, shapes)
+		if err != nil {
+			return nil, err
+		}
+		runners[i] = r.(*runner)
+	}
+	return ops.NewSPMDRunner(mesh, output, traced, params, func(args [][]platform.Handle) (out, traces [][]platform.DeviceHandle, err error) {
+		ex := newExchange()
+		out = make([][]platform.DeviceHandle, len(runners))
+		traces = make([][]platform.DeviceHandle, len(runners))
+		errs := make([]error, len(runners))
+		var wg sync.WaitGroup
+		for i, r := range runners {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rep := &replica{mesh: mesh, index: i, ex: ex, counts: make(map[*collective]int)}
+				if out[i], traces[i], errs[i] = r.run(context.Background(), rep, nil, args[i]); errs[i] != nil {
+					ex.abort(errs[i])
+				}
+			}()
+		}
+		wg.Wait()
+		return out, traces, ops.JoinDeviceErrors(mesh, errs, out, traces)
+	})
+}
@@ -0,0 +1,555 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// zip applies f to the elements of x and y. One of x and y can have a single element
+// which is then used with all the elements of the other.
+func zip[T, R any](x, y []T, n int, f func(T, T) R) []R {
+	out := make([]R, n)
+	for i := range out {
+		out[i] = f(x[i%len(x)], y[i%len(y)])
+	}
+	return out
+}
+
+// broadcastDims returns the axis lengths of the result of an operation between
+// two arrays of the same shape, or between an array and an atomic value.
+func broadcastDims(x, y *array) []int {
+	if len(y.dims) > len(x.dims) {
+		return y.dims
+	}
+	return x.dims
+}
+
+func unaryOp(op ops.UnaryOperator, dt dtype.DataType, x *array) (*array, error) {
+	var data any
+	switch op {
+	case ops.Plus:
+		return x, nil
+	case ops.Neg:
+		switch vals := x.data.(type) {
+		case []int64:
+			data = convert(vals, func(v int64) int64 { return -v })
+		case []uint64:
+			data = convert(vals, func(v uint64) uint64 { return -v })
+		case []float64:
+			data = convert(vals, func(v float64) float64 { return -v })
+		case []complex128:
+			data = convert(vals, func(v complex128) complex128 { return -v })
+		}
+	case ops.Not, ops.BitNot:
+		switch vals := x.data.(type) {
+		case []bool:
+			data = convert(vals, func(v bool) bool { return !v })
+		case []int64:
+			data = convert(vals, func(v int64) int64 { return ^v })
+		case []uint64:
+			data = convert(vals, func(v uint64) uint64 { return ^v })
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("unary operator %s not supported on %s", op, x.dt)
+	}
+	return newArray(dt, x.dims, data), nil
+}
+
+func binaryOp(op ops.BinaryOperator, dt dtype.DataType, x, y *array) (*array, error) {
+	dims := broadcastDims(x, y)
+	n := shape.Size(dims)
+	var data any
+	switch xVals := x.data.(type) {
+	case []bool:
+		data = boolBinary(op, xVals, y.data.([]bool), n)
+	case []int64:
+		data = signedBinary(op, dtype.BitSizeof(x.dt), xVals, y.data.([]int64), n)
+	case []uint64:
+		data = unsignedBinary(op, dtype.BitSizeof(x.dt), xVals, y.data.([]uint64), n)
+	case []float64:
+		data = floatBinary(op, xVals, y.data.([]float64), n)
+	case []complex128:
+		data = complexBinary(op, xVals, y.data.([]complex128), n)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("binary operator %s not supported on %s", op, x.dt)
+	}
+	return newArray(dt, dims, data), nil
+}
+
+// compare applies a comparison operator. It returns nil if op is not a comparison.
+func compare[T cmp.Ordered](op ops.BinaryOperator, x, y []T, n int) any {
+	switch op {
+	case ops.Equal:
+		return zip(x, y, n, func(a, b T) bool { return a == b })
+	case ops.NotEqual:
+		return zip(x, y, n, func(a, b T) bool { return a != b })
+	case ops.Less:
+		return zip(x, y, n, func(a, b T) bool { return a < b })
+	case ops.LessEqual:
+		return zip(x, y, n, func(a, b T) bool { return a <= b })
+	case ops.Greater:
+		return zip(x, y, n, func(a, b T) bool { return a > b })
+	case ops.GreaterEqual:
+		return zip(x, y, n, func(a, b T) bool { return a >= b })
+	}
+	return nil
+}
+
+func boolBinary(op ops.BinaryOperator, x, y []bool, n int) any {
+	switch op {
+	case ops.LogicalAnd, ops.BitAnd:
+		return zip(x, y, n, func(a, b bool) bool { return a && b })
+	case ops.LogicalOr, ops.BitOr:
+		return zip(x, y, n, func(a, b bool) bool { return a || b })
+	case ops.BitXor, ops.NotEqual:
+		return zip(x, y, n, func(a, b bool) bool { return a != b })
+	case ops.BitAndNot:
+		return zip(x, y, n, func(a, b bool) bool { return a && !b })
+	case ops.Equal:
+		return zip(x, y, n, func(a, b bool) bool { return a == b })
+	}
+	// Other comparisons order false before true.
+	return compare(op, convert(x, boolToInt), convert(y, boolToInt), n)
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// signedBinary applies an operator to signed integers of a given number of bits.
+// As in StableHLO, dividing by zero returns -1, the remainder of a division by zero is
+// the dividend, and shifting by a negative amount or by at least the number of bits
+// shifts out all the bits.
+func signedBinary(op ops.BinaryOperator, bits int, x, y []int64, n int) any {
+	switch op {
+	case ops.Add:
+		return zip(x, y, n, func(a, b int64) int64 { return a + b })
+	case ops.Sub:
+		return zip(x, y, n, func(a, b int64) int64 { return a - b })
+	case ops.Mul:
+		return zip(x, y, n, func(a, b int64) int64 { return a * b })
+	case ops.Div:
+		return zip(x, y, n, func(a, b int64) int64 {
+			if b == 0 {
+				return -1
+			}
+			return a / b
+		})
+	case ops.Rem:
+		return zip(x, y, n, func(a, b int64) int64 {
+			if b == 0 {
+				return a
+			}
+			return a % b
+		})
+	case ops.Pow:
+		return zip(x, y, n, powSigned)
+	case ops.BitAnd:
+		return zip(x, y, n, func(a, b int64) int64 { return a & b })
+	case ops.BitOr:
+		return zip(x, y, n, func(a, b int64) int64 { return a | b })
+	case ops.BitXor:
+		return zip(x, y, n, func(a, b int64) int64 { return a ^ b })
+	case ops.BitAndNot:
+		return zip(x, y, n, func(a, b int64) int64 { return a &^ b })
+	case ops.Shl:
+		return zip(x, y, n, func(a, b int64) int64 {
+			if b < 0 || b >= int64(bits) {
+				return 0
+			}
+			return a << b
+		})
+	case ops.Shr:
+		return zip(x, y, n, func(a, b int64) int64 {
+			// Values are sign-extended to 64 bits: shifting by 63 replicates the sign bit.
+			if b < 0 || b >= int64(bits) {
+				b = 63
+			}
+			return a >> b
+		})
+	}
+	return compare(op, x, y, n)
+}
+
+// powSigned raises a to the power b by repeated squaring.
+// Negative powers return 0 unless a is 1 or -1.
+func powSigned(a, b int64) int64 {
+	if b < 0 {
+		switch {
+		case a == 1:
+			return 1
+		case a == -1 && b%2 == 0:
+			return 1
+		case a == -1:
+			return -1
+		}
+		return 0
+	}
+	r := int64(1)
+	for ; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			r *= a
+		}
+		a *= a
+	}
+	return r
+}
+
+// unsignedBinary applies an operator to unsigned integers of a given number of bits.
+// Division by zero returns the largest value and the remainder of a division by zero is the dividend.
+func unsignedBinary(op ops.BinaryOperator, bits int, x, y []uint64, n int) any {
+	switch op {
+	case ops.Add:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a + b })
+	case ops.Sub:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a - b })
+	case ops.Mul:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a * b })
+	case ops.Div:
+		return zip(x, y, n, func(a, b uint64) uint64 {
+			if b == 0 {
+				return math.MaxUint64
+			}
+			return a / b
+		})
+	case ops.Rem:
+		return zip(x, y, n, func(a, b uint64) uint64 {
+			if b == 0 {
+				return a
+			}
+			return a % b
+		})
+	case ops.Pow:
+		return zip(x, y, n, func(a, b uint64) uint64 {
+			r := uint64(1)
+			for ; b > 0; b >>= 1 {
+				if b&1 == 1 {
+					r *= a
+				}
+				a *= a
+			}
+			return r
+		})
+	case ops.BitAnd:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a & b })
+	case ops.BitOr:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a | b })
+	case ops.BitXor:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a ^ b })
+	case ops.BitAndNot:
+		return zip(x, y, n, func(a, b uint64) uint64 { return a &^ b })
+	case ops.Shl:
+		return zip(x, y, n, func(a, b uint64) uint64 {
+			if b >= uint64(bits) {
+				return 0
+			}
+			return a << b
+		})
+	case ops.Shr:
+		return zip(x, y, n, func(a, b uint64) uint64 {
+			if b >= uint64(bits) {
+				return 0
+			}
+			return a >> b
+		})
+	}
+	return compare(op, x, y, n)
+}
+
+func floatBinary(op ops.BinaryOperator, x, y []float64, n int) any {
+	switch op {
+	case ops.Add:
+		return zip(x, y, n, func(a, b float64) float64 { return a + b })
+	case ops.Sub:
+		return zip(x, y, n, func(a, b float64) float64 { return a - b })
+	case ops.Mul:
+		return zip(x, y, n, func(a, b float64) float64 { return a * b })
+	case ops.Div:
+		return zip(x, y, n, func(a, b float64) float64 { return a / b })
+	case ops.Rem:
+		return zip(x, y, n, math.Mod)
+	case ops.Pow:
+		return zip(x, y, n, math.Pow)
+	}
+	return compare(op, x, y, n)
+}
+
+func complexBinary(op ops.BinaryOperator, x, y []complex128, n int) any {
+	switch op {
+	case ops.Add:
+		return zip(x, y, n, func(a, b complex128) complex128 { return a + b })
+	case ops.Sub:
+		return zip(x, y, n, func(a, b complex128) complex128 { return a - b })
+	case ops.Mul:
+		return zip(x, y, n, func(a, b complex128) complex128 { return a * b })
+	case ops.Div:
+		return zip(x, y, n, func(a, b complex128) complex128 { return a / b })
+	case ops.Pow:
+		return zip(x, y, n, cmplx.Pow)
+	case ops.Equal:
+		return zip(x, y, n, func(a, b complex128) bool { return a == b })
+	case ops.NotEqual:
+		return zip(x, y, n, func(a, b complex128) bool { return a != b })
+	}
+	return nil
+}
+
+// signedRange returns the smallest and the largest value of a signed integer type.
+func signedRange(dt dtype.DataType) (int64, int64) {
+	bits := dtype.BitSizeof(dt)
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// unsignedMax returns the largest value of an unsigned integer type.
+func unsignedMax(dt dtype.DataType) uint64 {
+	return math.MaxUint64 >> (64 - dtype.BitSizeof(dt))
+}
+
+// cast converts the elements of an array to a data type.
+// Floating-point values are truncated toward zero and saturated when cast to integers.
+func cast(dt dtype.DataType, x *array) *array {
+	var data any
+	switch kindOf(dt) {
+	case boolKind:
+		switch vals := x.data.(type) {
+		case []bool:
+			data = convert(vals, identity[bool])
+		case []int64:
+			data = convert(vals, func(v int64) bool { return v != 0 })
+		case []uint64:
+			data = convert(vals, func(v uint64) bool { return v != 0 })
+		case []float64:
+			data = convert(vals, func(v float64) bool { return v != 0 })
+		case []complex128:
+			data = convert(vals, func(v complex128) bool { return v != 0 })
+		}
+	case signedKind:
+		lo, hi := signedRange(dt)
+		toInt := func(v float64) int64 {
+			switch {
+			case math.IsNaN(v):
+				return 0
+			case v <= float64(lo):
+				return lo
+			case v >= float64(hi):
+				return hi
+			}
+			return int64(v)
+		}
+		switch vals := x.data.(type) {
+		case []bool:
+			data = convert(vals, boolToInt)
+		case []int64:
+			data = convert(vals, identity[int64])
+		case []uint64:
+			data = convert(vals, func(v uint64) int64 { return int64(v) })
+		case []float64:
+			data = convert(vals, toInt)
+		case []complex128:
+			data = convert(vals, func(v complex128) int64 { return toInt(real(v)) })
+		}
+	case unsignedKind:
+		hi := unsignedMax(dt)
+		toUint := func(v float64) uint64 {
+			switch {
+			case math.IsNaN(v), v <= 0:
+				return 0
+			case v >= float64(hi):
+				return hi
+			}
+			return uint64(v)
+		}
+		switch vals := x.data.(type) {
+		case []bool:
+			data = convert(vals, func(v bool) uint64 { return uint64(boolToInt(v)) })
+		case []int64:
+			data = convert(vals, func(v int64) uint64 { return uint64(v) })
+		case []uint64:
+			data = convert(vals, identity[uint64])
+		case []float64:
+			data = convert(vals, toUint)
+		case []complex128:
+			data = convert(vals, func(v complex128) uint64 { return toUint(real(v)) })
+		}
+	case floatKind:
+		data = toFloats(x)
+	case complexKind:
+		if vals, ok := x.data.([]complex128); ok {
+			data = convert(vals, identity[complex128])
+		} else {
+			data = convert(toFloats(x), func(v float64) complex128 { return complex(v, 0) })
+		}
+	}
+	return newArray(dt, x.dims, data)
+}
+
+// toFloats returns a copy of the elements of an array converted to float64.
+// Complex values are converted to their real part.
+func toFloats(x *array) []float64 {
+	switch vals := x.data.(type) {
+	case []bool:
+		return convert(vals, func(v bool) float64 { return float64(boolToInt(v)) })
+	case []int64:
+		return convert(vals, func(v int64) float64 { return float64(v) })
+	case []uint64:
+		return convert(vals, func(v uint64) float64 { return float64(v) })
+	case []float64:
+		return convert(vals, identity[float64])
+	case []complex128:
+		return convert(vals, func(v complex128) float64 { return real(v) })
+	}
+	return nil
+}
+
+var floatFuncs = map[ops.OpID]func(float64) float64{
+	ops.OpAbs:   math.Abs,
+	ops.OpCeil:  math.Ceil,
+	ops.OpCos:   math.Cos,
+	ops.OpErf:   math.Erf,
+	ops.OpExp:   math.Exp,
+	ops.OpExpm1: math.Expm1,
+	ops.OpFloor: math.Floor,
+	ops.OpLog:   math.Log,
+	ops.OpLog1p: math.Log1p,
+	ops.OpLogistic: func(v float64) float64 {
+		return 1 / (1 + math.Exp(-v))
+	},
+	ops.OpRound: math.Round,
+	ops.OpRsqrt: func(v float64) float64 {
+		return 1 / math.Sqrt(v)
+	},
+	ops.OpSign: func(v float64) float64 {
+		if v == 0 || math.IsNaN(v) {
+			return v
+		}
+		return math.Copysign(1, v)
+	},
+	ops.OpSin:  math.Sin,
+	ops.OpSqrt: math.Sqrt,
+	ops.OpTanh: math.Tanh,
+}
+
+var complexFuncs = map[ops.OpID]func(complex128) complex128{
+	ops.OpConj: cmplx.Conj,
+	ops.OpCos:  cmplx.Cos,
+	ops.OpExp:  cmplx.Exp,
+	ops.OpLog:  cmplx.Log,
+	ops.OpRsqrt: func(v complex128) complex128 {
+		return 1 / cmplx.Sqrt(v)
+	},
+	ops.OpSign: func(v complex128) complex128 {
+		if v == 0 || cmplx.IsNaN(v) {
+			return v
+		}
+		return v / complex(cmplx.Abs(v), 0)
+	},
+	ops.OpSin:  cmplx.Sin,
+	ops.OpSqrt: cmplx.Sqrt,
+	ops.OpTanh: cmplx.Tanh,
+}
+
+// mathUnary applies a function of the math builder element-wise.
+func mathUnary(op ops.OpID, dt dtype.DataType, x *array) (*array, error) {
+	var data any
+	switch vals := x.data.(type) {
+	case []float64:
+		if f := floatFuncs[op]; f != nil {
+			data = convert(vals, f)
+		}
+	case []complex128:
+		switch op {
+		case ops.OpAbs:
+			data = convert(vals, cmplx.Abs)
+		case ops.OpReal:
+			data = convert(vals, func(v complex128) float64 { return real(v) })
+		case ops.OpImag:
+			data = convert(vals, func(v complex128) float64 { return imag(v) })
+		default:
+			if f := complexFuncs[op]; f != nil {
+				data = convert(vals, f)
+			}
+		}
+	case []int64:
+		switch op {
+		case ops.OpAbs:
+			data = convert(vals, func(v int64) int64 { return max(v, -v) })
+		case ops.OpSign:
+			data = convert(vals, func(v int64) int64 { return int64(cmp.Compare(v, 0)) })
+		}
+	case []uint64:
+		switch op {
+		case ops.OpAbs:
+			data = convert(vals, identity[uint64])
+		case ops.OpSign:
+			data = convert(vals, func(v uint64) uint64 { return min(v, 1) })
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s not supported on %s", op, x.dt)
+	}
+	return newArray(dt, x.dims, data), nil
+}
+
+// mathBinary applies a function of two arguments of the math builder element-wise.
+func mathBinary(op ops.OpID, dt dtype.DataType, x, y *array) (*array, error) {
+	dims := broadcastDims(x, y)
+	n := shape.Size(dims)
+	var data any
+	switch xVals := x.data.(type) {
+	case []float64:
+		switch op {
+		case ops.OpAtan2:
+			data = zip(xVals, y.data.([]float64), n, math.Atan2)
+		case ops.OpPow:
+			data = zip(xVals, y.data.([]float64), n, math.Pow)
+		case ops.OpComplex:
+			data = zip(xVals, y.data.([]float64), n, func(re, im float64) complex128 { return complex(re, im) })
+		}
+	case []complex128:
+		if op == ops.OpPow {
+			data = zip(xVals, y.data.([]complex128), n, cmplx.Pow)
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s not supported on %s", op, x.dt)
+	}
+	return newArray(dt, dims, data), nil
+}
+
+// mathPredicate applies IsInf or IsNaN element-wise.
+func mathPredicate(op ops.OpID, x *array) (*array, error) {
+	vals, ok := x.data.([]float64)
+	if !ok {
+		return nil, fmt.Errorf("%s not supported on %s", op, x.dt)
+	}
+	f := func(v float64) bool { return math.IsInf(v, 0) }
+	if op == ops.OpIsNaN {
+		f = math.IsNaN
+	}
+	return &array{dt: dtype.Bool, dims: x.dims, data: convert(vals, f)}, nil
+}
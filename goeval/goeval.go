@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goeval implements a backend evaluating graphs with a pure Go interpreter.
+//
+// The backend is slow but correct and has no dependency: it is a reference to test code
+// built on the ops interfaces and to run examples without an accelerator. Arrays are
+// stored on the platform of the cpu package.
+//
+// Graphs are not compiled: running a graph evaluates its nodes one after the other.
+// Floating-point values are computed in float64 and rounded to the data type of each
+// operation, and integer values are wrapped around the size of their data type.
+//
+// Graphs compiled for a mesh are evaluated concurrently once per device of the mesh.
+// Since the CPU platform has a single device, a mesh repeating the CPU device can be
+// used to test programs using collective operations.
+//
+// The backend is registered as "goeval".
+package goeval
+
+import (
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+)
+
+// Name of the backend in the backend registry.
+const Name = "goeval"
+
+func init() {
+	backend.Register(Name, func(cfg platform.Config) (backend.Backend, error) {
+		return New(cfg)
+	})
+}
+
+// Backend evaluates graphs on a CPU platform.
+type Backend struct {
+	plat *cpu.Platform
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+// New returns a backend evaluating graphs on a new CPU platform.
+func New(cfg platform.Config) (*Backend, error) {
+	plat, err := cpu.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{plat: plat}, nil
+}
+
+// Platform of the backend.
+func (b *Backend) Platform() platform.Platform {
+	return b.plat
+}
+
+// NewOps returns a new graph. Options other than the mesh of the graph are ignored.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	g := NewGraph(b.plat, name)
+	g.mesh = ops.NewGraphConfig(opts...).Mesh
+	return g, nil
+}
+
+// Capabilities reports that all data types and operations are supported.
+func (b *Backend) Capabilities() ops.Capabilities {
+	return ops.AllCapabilities{}
+}
+
+// Close the backend and its platform.
+func (b *Backend) Close() error {
+	return b.plat.Close()
+}
+
+// Release the backend.
+//
+// Deprecated: use Close.
+func (b *Backend) Release() error {
+	return b.Close()
+}
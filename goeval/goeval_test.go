@@ -0,0 +1,600 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval_test
+
+import (
+	"context"
+	"errors"
+	"go/token"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/chrometrace"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+func newGraph(t *testing.T) (*goeval.Backend, ops.Graph) {
+	t.Helper()
+	b, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, g
+}
+
+func constant[T dtype.GoDataType](t *testing.T, g ops.Graph, data []T, axisLengths ...int) ops.Node {
+	t.Helper()
+	a, err := array.New(data, axisLengths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := g.Core().Constant(a.HostBuffer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// run compiles a graph returning a node of the shape sh, runs it with args and returns
+// the elements of the result.
+func run[T dtype.GoDataType](t *testing.T, b *goeval.Backend, g ops.Graph, n ops.Node, sh *shape.Shape, args ...platform.Handle) []T {
+	t.Helper()
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := make([]*shape.Shape, len(args))
+	for i, arg := range args {
+		params[i] = arg.Shape()
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: n, Shape: sh}}, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := runner.Run(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out[0].Free()
+	res, err := array.FromHandle[T](out[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Flat()
+}
+
+// checker returns a function failing the test if a node cannot be built.
+func checker(t *testing.T) func(ops.Node, error) ops.Node {
+	return func(n ops.Node, err error) ops.Node {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	b, err := backend.FromConfig(backend.Config{Name: goeval.Name, Device: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if _, ok := b.(*goeval.Backend); !ok {
+		t.Errorf("got backend %T but want %T", b, &goeval.Backend{})
+	}
+}
+
+func TestArgumentDotGeneral(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	xShape := shape.Of(dtype.Float32, 2, 3)
+	x := check(core.Argument("x", xShape, 0))
+	y := constant(t, g, []float32{1, 0, 0, 1, 1, 1}, 3, 2)
+	dot := check(core.DotGeneral(x, y, [2][]int{}, [2][]int{{1}, {0}}, ops.DefaultPrecision))
+	arg, err := array.New([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := run[float32](t, b, g, dot, shape.Of(dtype.Float32, 2, 2), arg.HostBuffer())
+	if want := []float32{4, 5, 10, 11}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
+
+func TestColumnMajor(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	colMajor := shape.Of(dtype.Float32, 2, 3).WithLayout(shape.ColumnMajor(2))
+	x := check(g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0))
+	row := constant(t, g, []float32{10, 20, 30}, 3)
+	bcast := check(g.Core().BroadcastInDim(row, shape.Of(dtype.Float32, 2, 3), []int{1}))
+	sum := check(g.Core().BinaryOp(ops.Add, x, bcast))
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: sum, Shape: colMajor}}, nil, []*shape.Shape{colMajor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Elements of [[1, 2, 3], [4, 5, 6]] in column-major order.
+	arg, err := dev.Send(dtype.CopyFromSlice([]float32{1, 4, 2, 5, 3, 6}), colMajor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arg.Free()
+	out, _, err := runner.Run([]platform.Handle{arg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out[0].Free()
+	buf, err := platform.Borrow(make([]byte, colMajor.ByteSize()), colMajor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := out[0].ToHost(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	if got, want := dtype.ToSlice[float32](data), []float32{11, 14, 22, 25, 33, 36}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
+
+func TestIntegerWrapAround(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	x := constant(t, g, []int8{100, -100}, 2)
+	sum := check(g.Core().BinaryOp(ops.Add, x, x))
+	got := run[int8](t, b, g, sum, shape.Of(dtype.Int8, 2))
+	if want := []int8{-56, 56}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
+
+func TestWhile(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	scalar := shape.Scalar(dtype.Int32)
+	cond, err := core.Subgraph("cond", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := check(cond.Core().Argument("i", scalar, 0))
+	limit := constant(t, cond, []int32{10})
+	less := check(cond.Core().BinaryOp(ops.Less, i, check(cond.Core().Reshape(limit, nil))))
+	body, err := core.Subgraph("body", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := check(body.Core().Argument("i", scalar, 0))
+	twice := check(body.Core().BinaryOp(ops.Add, j, j))
+	one := check(body.Core().Reshape(constant(t, body, []int32{1}), nil))
+	next := check(body.Core().BinaryOp(ops.Add, twice, one))
+	loop := check(core.While(
+		&ops.Subgraph{Graph: cond, Result: ops.OutputNode{Node: less, Shape: shape.Scalar(dtype.Bool)}},
+		&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: next, Shape: scalar}},
+		check(core.Reshape(constant(t, g, []int32{0}), nil)),
+	))
+	if got := run[int32](t, b, g, loop, scalar); got[0] != 15 {
+		t.Errorf("got %d but want 15", got[0])
+	}
+}
+
+func TestRunProfiled(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	scalar := shape.Scalar(dtype.Int32)
+	cond, err := core.Subgraph("cond", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := check(cond.Core().Argument("i", scalar, 0))
+	limit := constant(t, cond, []int32{10})
+	less := check(cond.Core().BinaryOp(ops.Less, i, check(cond.Core().Reshape(limit, nil))))
+	body, err := core.Subgraph("body", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := check(body.Core().Argument("i", scalar, 0))
+	twice := check(body.Core().BinaryOp(ops.Add, j, j))
+	one := check(body.Core().Reshape(constant(t, body, []int32{1}), nil))
+	next := check(body.Core().BinaryOp(ops.Add, twice, one))
+	loop := check(core.While(
+		&ops.Subgraph{Graph: cond, Result: ops.OutputNode{Node: less, Shape: shape.Scalar(dtype.Bool)}},
+		&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: next, Shape: scalar}},
+		check(core.Reshape(constant(t, g, []int32{0}), nil)),
+	))
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst := &ops.ExecutionProfile{}
+	runner, err := ops.CompileInstrumented(g, dev, []*ops.OutputNode{{Node: loop, Shape: scalar}}, nil, nil, inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, prof, err := ops.RunProfiled(runner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out[0].Free()
+	if prof.Wall <= 0 || prof.Device <= 0 || prof.Device > prof.Wall {
+		t.Errorf("invalid profile times: wall %s, device %s", prof.Wall, prof.Device)
+	}
+	for _, p := range []*ops.ExecutionProfile{prof, inst} {
+		if got := p.Nodes[loop].Count; got != 1 {
+			t.Errorf("while evaluated %d times but want 1", got)
+		}
+		if got := p.Nodes[twice]; got.Count != 4 || got.Op != ops.OpBinary {
+			t.Errorf("got timing %+v for the loop body but want 4 evaluations of %s", got, ops.OpBinary)
+		}
+	}
+}
+
+func TestPlatformProfiler(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	x := constant(t, g, []float32{1, 2, 3}, 3)
+	sum := check(g.Core().BinaryOp(ops.Add, x, x))
+	prof, err := platform.NewProfiler(b.Platform())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prof.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if got := run[float32](t, b, g, sum, shape.Of(dtype.Float32, 3)); !slices.Equal(got, []float32{2, 4, 6}) {
+		t.Errorf("got %v but want [2 4 6]", got)
+	}
+	records, err := prof.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[platform.RecordKind]int)
+	for _, rec := range records {
+		kinds[rec.Kind]++
+		if rec.Kind == platform.KernelRecord && rec.Name != string(ops.OpConstant) && rec.Name != string(ops.OpBinary) {
+			t.Errorf("unexpected kernel %q", rec.Name)
+		}
+	}
+	if kinds[platform.KernelRecord] != 2 {
+		t.Errorf("got %d kernel records but want 2", kinds[platform.KernelRecord])
+	}
+	if kinds[platform.HostToDeviceRecord] != 1 || kinds[platform.DeviceToHostRecord] != 1 {
+		t.Errorf("got %d transfers to the device and %d transfers to the host but want 1 and 1",
+			kinds[platform.HostToDeviceRecord], kinds[platform.DeviceToHostRecord])
+	}
+	var traced []string
+	for _, ev := range chrometrace.NewTrace(records).TraceEvents {
+		if ev.Ph == "X" && ev.Cat == platform.KernelRecord.String() {
+			traced = append(traced, ev.Name)
+		}
+	}
+	if want := []string{string(ops.OpConstant), string(ops.OpBinary)}; !slices.Equal(traced, want) {
+		t.Errorf("got kernels %v in the trace but want %v", traced, want)
+	}
+}
+
+func TestDump(t *testing.T) {
+	_, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	scalar := shape.Scalar(dtype.Int32)
+	cond, err := core.Subgraph("cond", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := check(cond.Core().Argument("i", scalar, 0))
+	less := check(cond.Core().BinaryOp(ops.Less, i, i))
+	body, err := core.Subgraph("body", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := check(body.Core().Argument("i", scalar, 0))
+	x := check(core.Argument("x", scalar, 0))
+	check(core.While(
+		&ops.Subgraph{Graph: cond, Result: ops.OutputNode{Node: less, Shape: shape.Scalar(dtype.Bool)}},
+		&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: j, Shape: scalar}},
+		x,
+	))
+	want := `graph main {
+  %0 = core.Argument() -> int32
+  %1 = core.While(%0) -> int32
+  graph cond {
+    %2 = core.Argument() -> int32
+    %3 = core.Binary(%2, %2) -> bool
+  }
+  graph body {
+    %4 = core.Argument() -> int32
+  }
+}
+`
+	if got := ops.Dump(g); got != want {
+		t.Errorf("got dump:\n%s\nbut want:\n%s", got, want)
+	}
+	var dot strings.Builder
+	if err := ops.WriteDOT(&dot, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"subgraph cluster_1 {", `n1 [label="core.While\nint32"];`, "n2 -> n3;"} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("DOT output does not contain %q:\n%s", want, dot.String())
+		}
+	}
+}
+
+func TestRunAsyncCancel(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	scalar := shape.Scalar(dtype.Int32)
+	cond, err := core.Subgraph("cond", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := check(cond.Core().Argument("i", scalar, 0))
+	forever := check(cond.Core().BinaryOp(ops.Equal, i, i))
+	body, err := core.Subgraph("body", []*shape.Shape{scalar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := check(body.Core().Argument("i", scalar, 0))
+	loop := check(core.While(
+		&ops.Subgraph{Graph: cond, Result: ops.OutputNode{Node: forever, Shape: shape.Scalar(dtype.Bool)}},
+		&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: j, Shape: scalar}},
+		check(core.Reshape(constant(t, g, []int32{0}), nil)),
+	))
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := ops.CompileContext(context.Background(), g, dev, []*ops.OutputNode{{Node: loop, Shape: scalar}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := ops.RunContext(ctx, runner, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v but want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDonation(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	core := g.Core()
+	sh := shape.Vector(dtype.Float32, 4)
+	x := check(core.Argument("x", sh, 0))
+	twice := check(core.BinaryOp(ops.Add, x, x))
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := ops.CompileDonated(g, dev, []*ops.OutputNode{{Node: twice, Shape: sh}}, nil, []*shape.Shape{sh}, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := dev.Send(dtype.FromSlice([]float32{1, 2, 3, 4}), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := platform.DeviceMemoryStats(dev)
+	donated := platform.Donate(arg)
+	out, _, err := runner.Run([]platform.Handle{donated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out[0].Free()
+	if out[0] != arg {
+		t.Errorf("output has not been stored in the donated argument")
+	}
+	if after, _ := platform.DeviceMemoryStats(dev); after.Allocated != before.Allocated {
+		t.Errorf("got %d bytes allocated after the run but want %d", after.Allocated, before.Allocated)
+	}
+	res, err := array.FromHandle[float32](out[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Flat(), []float32{2, 4, 6, 8}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	if _, _, err := runner.Run([]platform.Handle{donated}); !errors.Is(err, platform.ErrHandleConsumed) {
+		t.Errorf("got error %v but want %v", err, platform.ErrHandleConsumed)
+	}
+}
+
+func TestMeshCollectives(t *testing.T) {
+	b, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a 2x2 mesh with the CPU device.
+	mesh, err := platform.NewMesh([]platform.Device{dev, dev, dev, dev}, []string{"data", "model"}, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.NewOps("main", ops.WithMesh(mesh))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := checker(t)
+	dist, err := ops.Dist(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shardShape := shape.Of(dtype.Float32, 2)
+	x := check(g.Core().Argument("x", shardShape, 0))
+	sum := check(dist.AllReduce(x, platform.ReduceSum, []string{"data"}))
+	gathered := check(dist.AllGather(sum, 0, []string{"model"}))
+	scattered := check(dist.ReduceScatter(x, platform.ReduceSum, 0, []string{"data"}))
+	permuted := check(dist.CollectivePermute(x, "data", [][2]int{{0, 1}}))
+	runner, err := ops.CompileMesh(g, mesh, []*ops.OutputNode{
+		{Node: gathered, Shape: shape.Of(dtype.Float32, 4)},
+		{Node: scattered, Shape: shape.Of(dtype.Float32, 1), Sharding: platform.PartitionSpec{"data"}},
+		{Node: permuted, Shape: shardShape, Sharding: platform.PartitionSpec{"data"}},
+	}, nil, []*ops.MeshParam{{Shape: shardShape, Sharding: platform.PartitionSpec{"data"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := array.New([]float32{1, 2, 3, 4}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := runner.Run([]platform.Handle{arg.HostBuffer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wants := [][]float32{{4, 6, 4, 6}, {4, 6}, {0, 0, 1, 2}}
+	for i, want := range wants {
+		got, err := array.FromHandle[float32](out[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i].Free()
+		if !slices.Equal(got.Flat(), want) {
+			t.Errorf("output %d: got %v but want %v", i, got.Flat(), want)
+		}
+	}
+	single, err := g.Compile(dev, []*ops.OutputNode{{Node: sum, Shape: shardShape}}, nil, []*shape.Shape{shardShape})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shard, err := array.New([]float32{1, 2}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := single.Run([]platform.Handle{shard.HostBuffer()}); err == nil {
+		t.Errorf("expected an error when running a collective operation on a single device")
+	}
+}
+
+func TestSortAndTopK(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	nan := float32(math.NaN())
+	x := constant(t, g, []float32{3, nan, -1, 2, 2, 5}, 2, 3)
+	sorted := check(g.Core().Sort(x, -1, false))
+	got := run[float32](t, b, g, sorted, shape.Of(dtype.Float32, 2, 3))
+	if want := []float32{-1, 3, nan, 2, 2, 5}; !slices.EqualFunc(got, want, func(a, b float32) bool {
+		return a == b || (a != a && b != b)
+	}) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	topK, err := g.Core().TopK(x, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices := check(topK.Element(1))
+	if got, want := run[int32](t, b, g, indices, shape.Of(dtype.Int32, 2, 2)), []int32{1, 0, 2, 0}; !slices.Equal(got, want) {
+		t.Errorf("got indices %v but want %v", got, want)
+	}
+}
+
+func TestRandIsDeterministic(t *testing.T) {
+	b, g := newGraph(t)
+	check := checker(t)
+	state := constant(t, g, []uint64{42, 0}, 2)
+	sh := shape.Of(dtype.Float32, 1000)
+	gen := func() ops.Node {
+		tuple := check(g.Rand().Uniform(sh, state)).(ops.Tuple)
+		return check(tuple.Element(1))
+	}
+	first := run[float32](t, b, g, gen(), sh)
+	second := run[float32](t, b, g, gen(), sh)
+	if !slices.Equal(first, second) {
+		t.Errorf("the same state generated different values")
+	}
+	var sum float64
+	for _, v := range first {
+		if v < 0 || v >= 1 {
+			t.Fatalf("value %v out of [0, 1)", v)
+		}
+		sum += float64(v)
+	}
+	if mean := sum / float64(len(first)); math.Abs(mean-0.5) > 0.05 {
+		t.Errorf("got mean %v but want about 0.5", mean)
+	}
+}
+
+func TestNodeProvenance(t *testing.T) {
+	b, g := newGraph(t)
+	info := ops.NodeInfo{Name: "x", Pos: token.Position{Filename: "main.gx", Line: 3, Column: 5}}
+	ops.SetNodeInfo(g, info)
+	x := constant(t, g, []float32{1, 2}, 2)
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: shape.Of(dtype.Float32, 3)}}, nil, nil)
+	prov, ok := ops.ProvenanceOf(err)
+	if !ok {
+		t.Fatalf("got error %v but want an error annotated with the provenance of the node", err)
+	}
+	if prov.Node != x || prov.Name != info.Name || prov.Pos != info.Pos || prov.Op != ops.OpConstant {
+		t.Errorf("got provenance %+v", prov)
+	}
+	if !strings.HasPrefix(err.Error(), `main: core.Constant "x" at main.gx:3:5: `) {
+		t.Errorf("error %q does not start with the provenance of the node", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	b, g := newGraph(t)
+	x := constant(t, g, []float32{1, 2}, 2)
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: shape.Of(dtype.Float64, 2)}}, nil, nil); err == nil {
+		t.Errorf("expected an error when the output shape does not match")
+	}
+	_, other := newGraph(t)
+	y := constant(t, other, []float32{1, 2}, 2)
+	if _, err := g.Core().BinaryOp(ops.Add, x, y); err == nil {
+		t.Errorf("expected an error when using a node of another graph")
+	}
+	dynamic := shape.OfAxes(dtype.Float32, shape.Dynamic("n", 8))
+	if _, err := g.Core().Argument("x", dynamic, 0); !errors.Is(err, ops.ErrDynamicShapes) {
+		t.Errorf("got error %v but want %v", err, ops.ErrDynamicShapes)
+	}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: shape.Of(dtype.Float32, 2)}}, nil, []*shape.Shape{dynamic}); !errors.Is(err, ops.ErrDynamicShapes) {
+		t.Errorf("got error %v but want %v", err, ops.ErrDynamicShapes)
+	}
+}
@@ -0,0 +1,684 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/shape"
+)
+
+// typ is the type of a node: the shape of an array or the types of the elements of a tuple.
+type typ struct {
+	shape *shape.Shape
+	elems []typ
+}
+
+func (t typ) isTuple() bool {
+	return t.shape == nil
+}
+
+func (t typ) String() string {
+	if !t.isTuple() {
+		return t.shape.String()
+	}
+	return fmt.Sprint(t.elems)
+}
+
+// evalFunc computes the value of a node from the values of its inputs.
+type evalFunc func(f *frame, in []value) (value, error)
+
+// Node is an operation of a graph.
+type Node struct {
+	graph  *Graph
+	op     ops.OpID
+	typ    typ
+	inputs []*Node
+	eval   evalFunc
+	info   ops.NodeInfo
+}
+
+var _ ops.InspectableNode = (*Node)(nil)
+
+// Graph returns the graph owning the node.
+func (n *Node) Graph() ops.Graph {
+	return n.graph
+}
+
+// Shape returns the shape of the node or nil if the node is a tuple.
+func (n *Node) Shape() *shape.Shape {
+	return n.typ.shape
+}
+
+// Op returns the operation computing the node.
+func (n *Node) Op() ops.OpID {
+	return n.op
+}
+
+// Operands returns the nodes from which the node is computed.
+func (n *Node) Operands() []ops.Node {
+	operands := make([]ops.Node, len(n.inputs))
+	for i, input := range n.inputs {
+		operands[i] = input
+	}
+	return operands
+}
+
+// String returns the operation of the node and its type.
+func (n *Node) String() string {
+	return fmt.Sprintf("%s: %s", n.op, n.typ)
+}
+
+// provenance describes the node to annotate its errors.
+func (n *Node) provenance() ops.Provenance {
+	prov := ops.Provenance{
+		Op:        n.op,
+		Name:      n.info.Name,
+		Pos:       n.info.Pos,
+		Node:      n,
+		GraphPath: n.graph.path(),
+	}
+	if len(n.inputs) > 0 {
+		prov.OperandShapes = make([]*shape.Shape, len(n.inputs))
+		for i, in := range n.inputs {
+			prov.OperandShapes[i] = in.typ.shape
+		}
+	}
+	return prov
+}
+
+// Tuple is a node returning a tuple.
+type Tuple struct {
+	*Node
+}
+
+var _ ops.Tuple = (*Tuple)(nil)
+
+// Element returns a node representing the ith element of the tuple.
+func (t *Tuple) Element(i int) (ops.Node, error) {
+	if i < 0 || i >= t.Size() {
+		return nil, fmt.Errorf("tuple element %d out of range [0, %d)", i, t.Size())
+	}
+	return t.graph.newNode(intercept.OpElement, t.typ.elems[i], []*Node{t.Node}, func(_ *frame, in []value) (value, error) {
+		return in[0].(tuple)[i], nil
+	})
+}
+
+// Size returns the number of elements in the tuple.
+func (t *Tuple) Size() int {
+	return len(t.typ.elems)
+}
+
+// Unpack returns the elements of the tuple.
+func (t *Tuple) Unpack() ([]ops.Node, error) {
+	nodes := make([]ops.Node, t.Size())
+	for i := range nodes {
+		var err error
+		if nodes[i], err = t.Element(i); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// Graph records the operations to evaluate.
+type Graph struct {
+	plat   *cpu.Platform
+	name   string
+	parent *Graph
+	// args are the shapes of the arguments of a subgraph.
+	args   []*shape.Shape
+	params map[int]*Node
+	// mesh for which a root graph is built, nil if the graph is built for a single device.
+	mesh *platform.Mesh
+	// nodes and subgraphs created by the graph, in creation order.
+	nodes []*Node
+	subs  []*Graph
+	// info is attached to new nodes of a root graph and its subgraphs.
+	info ops.NodeInfo
+}
+
+var (
+	_ ops.InspectableGraph     = (*Graph)(nil)
+	_ ops.DonationCompiler     = (*Graph)(nil)
+	_ ops.InstrumentedCompiler = (*Graph)(nil)
+	_ ops.NodeInfoRecorder     = (*Graph)(nil)
+)
+
+// NewGraph returns a root graph evaluated on a CPU platform.
+func NewGraph(plat *cpu.Platform, name string) *Graph {
+	return &Graph{plat: plat, name: name, params: make(map[int]*Node)}
+}
+
+// Name of the graph.
+func (g *Graph) Name() string {
+	return g.name
+}
+
+// Inspect returns the nodes and the subgraphs created by the graph, in creation order.
+func (g *Graph) Inspect() (nodes []ops.Node, subgraphs []ops.Graph) {
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+	for _, sub := range g.subs {
+		subgraphs = append(subgraphs, sub)
+	}
+	return nodes, subgraphs
+}
+
+// Platform used by the graph.
+func (g *Graph) Platform() platform.Platform {
+	return g.plat
+}
+
+// Core returns the builder for core operations.
+func (g *Graph) Core() ops.CoreBuilder {
+	return coreBuilder{g: g}
+}
+
+// Num returns the builder for functions of the num package.
+func (g *Graph) Num() ops.NumBuilder {
+	return numBuilder{g: g}
+}
+
+// Math returns the builder for functions of the math package.
+func (g *Graph) Math() ops.MathBuilder {
+	return mathBuilder{g: g}
+}
+
+// DType returns the builder for functions of the dtype package.
+func (g *Graph) DType() ops.DTypeBuilder {
+	return dtypeBuilder{g: g}
+}
+
+// NN returns the builder for neural network operations.
+func (g *Graph) NN() ops.NNBuilder {
+	return nnBuilder{g: g}
+}
+
+// Rand returns the builder for random number generation.
+func (g *Graph) Rand() ops.RandBuilder {
+	return randBuilder{g: g}
+}
+
+// resolve returns the data type used to compute values of a data type.
+func (g *Graph) resolve(dt dtype.DataType) dtype.DataType {
+	return platform.Resolve(g.plat, dt)
+}
+
+// Compile returns a runner evaluating the graph on the CPU device.
+func (g *Graph) Compile(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	if g.parent != nil {
+		return nil, fmt.Errorf("cannot compile subgraph %s", g.name)
+	}
+	cpuDev, ok := dev.(*cpu.Device)
+	if !ok || cpuDev.Platform() != platform.Platform(g.plat) {
+		return nil, fmt.Errorf("cannot compile graph %s for device %T of another platform", g.name, dev)
+	}
+	if err := ops.CheckStatic(params...); err != nil {
+		return nil, fmt.Errorf("cannot compile graph %s: %w", g.name, err)
+	}
+	for index, arg := range g.params {
+		if index >= len(params) {
+			return nil, fmt.Errorf("argument %d not in the %d parameters of graph %s", index, len(params), g.name)
+		}
+		if !g.sameArray(arg.typ.shape, params[index]) {
+			return nil, fmt.Errorf("argument %d has shape %s but parameter %d has shape %s", index, arg.typ.shape, index, params[index])
+		}
+	}
+	r := &runner{graph: g, dev: cpuDev, params: params, numOutputs: len(output)}
+	for _, out := range append(append([]*ops.OutputNode{}, output...), traced...) {
+		node, err := g.node(out.Node)
+		if err != nil {
+			return nil, err
+		}
+		if node.typ.isTuple() {
+			return nil, fmt.Errorf("cannot return tuple %s from graph %s", node, g.name)
+		}
+		if !g.sameArray(node.typ.shape, out.Shape) {
+			return nil, ops.WithProvenance(fmt.Errorf("node %s returned as %s", node, out.Shape), node.provenance())
+		}
+		r.results = append(r.results, node)
+		r.shapes = append(r.shapes, out.Shape)
+	}
+	return r, nil
+}
+
+// CompileDonated returns a runner storing its outputs in the memory of the donated arguments
+// with the same shape.
+func (g *Graph) CompileDonated(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape, donated []int) (ops.Runner, error) {
+	if err := ops.CheckDonated(donated, len(params)); err != nil {
+		return nil, err
+	}
+	r, err := g.Compile(dev, output, traced, params)
+	if err != nil {
+		return nil, err
+	}
+	r.(*runner).donated = slices.Clone(donated)
+	return r, nil
+}
+
+// CompileInstrumented returns a runner notifying inst each time it has evaluated a node.
+func (g *Graph) CompileInstrumented(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape, inst ops.Instrumentation) (ops.Runner, error) {
+	r, err := g.Compile(dev, output, traced, params)
+	if err != nil {
+		return nil, err
+	}
+	r.(*runner).inst = inst
+	return r, nil
+}
+
+// SetNodeInfo sets the information attached to the nodes created from now on
+// in the graph and in its subgraphs.
+func (g *Graph) SetNodeInfo(info ops.NodeInfo) {
+	g.root().info = info
+}
+
+// NodeInfo returns the information attached to a node of the graph.
+func (g *Graph) NodeInfo(n ops.Node) (ops.NodeInfo, bool) {
+	node, err := g.node(n)
+	if err != nil {
+		return ops.NodeInfo{}, false
+	}
+	return node.info, true
+}
+
+// sameArray returns true if two shapes have the same axis lengths and data types
+// once dtype.Int has been resolved.
+func (g *Graph) sameArray(x, y *shape.Shape) bool {
+	return g.resolve(x.DType) == g.resolve(y.DType) && slices.Equal(x.AxisLengths, y.AxisLengths)
+}
+
+// node returns the node of a node of the graph.
+func (g *Graph) node(n ops.Node) (*Node, error) {
+	var node *Node
+	switch nT := n.(type) {
+	case *Node:
+		node = nT
+	case *Tuple:
+		node = nT.Node
+	default:
+		return nil, fmt.Errorf("node %T has not been created by a goeval graph", n)
+	}
+	if node.graph != g {
+		return nil, fmt.Errorf("node %s of graph %s used in graph %s", node, node.graph.name, g.name)
+	}
+	return node, nil
+}
+
+// arrays returns the nodes of the graph, checking that they are not tuples.
+func (g *Graph) arrays(ns ...ops.Node) ([]*Node, error) {
+	res := make([]*Node, len(ns))
+	for i, n := range ns {
+		var err error
+		if res[i], err = g.node(n); err != nil {
+			return nil, err
+		}
+		if res[i].typ.isTuple() {
+			return nil, fmt.Errorf("tuple %s used as an array", res[i])
+		}
+	}
+	return res, nil
+}
+
+// newNode adds a node to the graph. A Tuple is returned if the node returns a tuple.
+func (g *Graph) newNode(op ops.OpID, t typ, inputs []*Node, eval evalFunc) (ops.Node, error) {
+	node := &Node{graph: g, op: op, typ: t, inputs: inputs, eval: eval, info: g.root().info}
+	g.nodes = append(g.nodes, node)
+	if t.isTuple() {
+		return &Tuple{Node: node}, nil
+	}
+	return node, nil
+}
+
+// compute adds a node computing an array of the shape sh from the arrays of its inputs.
+// dt is the data type of the result, in which dtype.Int has been resolved.
+func (g *Graph) compute(op ops.OpID, sh *shape.Shape, inputs []*Node, f func(dt dtype.DataType, in []*array) (*array, error)) (ops.Node, error) {
+	dt := g.resolve(sh.DType)
+	return g.newNode(op, typ{shape: sh}, inputs, func(_ *frame, in []value) (value, error) {
+		arrays := make([]*array, len(in))
+		for i, v := range in {
+			arrays[i] = v.(*array)
+		}
+		return f(dt, arrays)
+	})
+}
+
+// subgraph returns the graph of a subgraph and the node of its result.
+func (g *Graph) subgraph(sg *ops.Subgraph) (*Graph, *Node, error) {
+	sub, ok := sg.Graph.(*Graph)
+	if !ok || sub.root() != g.root() {
+		return nil, nil, fmt.Errorf("subgraph %T has not been created by the goeval graph %s", sg.Graph, g.name)
+	}
+	result, err := sub.node(sg.Result.Node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sub, result, nil
+}
+
+// path returns the name of the graph and its parents separated by slashes.
+func (g *Graph) path() string {
+	if g.parent == nil {
+		return g.name
+	}
+	return g.parent.path() + "/" + g.name
+}
+
+func (g *Graph) root() *Graph {
+	for g.parent != nil {
+		g = g.parent
+	}
+	return g
+}
+
+// checkArgs returns an error if values of given types cannot be passed as the arguments of a subgraph.
+func (g *Graph) checkArgs(args []typ) error {
+	if len(args) != len(g.args) {
+		return fmt.Errorf("subgraph %s called with %d arguments but has %d parameters", g.name, len(args), len(g.args))
+	}
+	for i, arg := range args {
+		if arg.isTuple() || !g.sameArray(arg.shape, g.args[i]) {
+			return fmt.Errorf("argument %d of subgraph %s is %s but want %s", i, g.name, arg, g.args[i])
+		}
+	}
+	return nil
+}
+
+// sameType returns true if two types are the same once dtype.Int has been resolved.
+func (g *Graph) sameType(x, y typ) bool {
+	if x.isTuple() || y.isTuple() {
+		return x.isTuple() == y.isTuple() && slices.EqualFunc(x.elems, y.elems, g.sameType)
+	}
+	return g.sameArray(x.shape, y.shape)
+}
+
+// typesOf returns the types of nodes.
+func typesOf(nodes []*Node) []typ {
+	types := make([]typ, len(nodes))
+	for i, n := range nodes {
+		types[i] = n.typ
+	}
+	return types
+}
+
+// call evaluates the result of the graph given the values of its arguments.
+// The evaluation shares the context and the replica of the calling frame, which can be nil.
+func (g *Graph) call(caller *frame, result *Node, args []value) (value, error) {
+	f := newFrame(context.Background(), nil, args)
+	if caller != nil {
+		f.ctx, f.rep, f.inst = caller.ctx, caller.rep, caller.inst
+	}
+	return f.eval(result)
+}
+
+// frame holds the values of the nodes of a graph during one evaluation.
+type frame struct {
+	// ctx stops the evaluation when done.
+	ctx context.Context
+	// rep is the replica evaluated on a device of a mesh, nil if the graph is compiled for a single device.
+	rep *replica
+	// inst is notified each time a node has been evaluated, nil if the run is not instrumented.
+	inst   ops.Instrumentation
+	args   []value
+	values map[*Node]value
+}
+
+func newFrame(ctx context.Context, rep *replica, args []value) *frame {
+	return &frame{ctx: ctx, rep: rep, args: args, values: make(map[*Node]value)}
+}
+
+// eval returns the value of a node, evaluating its inputs first.
+func (f *frame) eval(n *Node) (value, error) {
+	if v, ok := f.values[n]; ok {
+		return v, nil
+	}
+	if err := f.ctx.Err(); err != nil {
+		return nil, err
+	}
+	in := make([]value, len(n.inputs))
+	for i, input := range n.inputs {
+		var err error
+		if in[i], err = f.eval(input); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	v, err := n.eval(f, in)
+	if err != nil {
+		return nil, ops.WithProvenance(err, n.provenance())
+	}
+	if f.inst != nil {
+		f.inst.NodeEvaluated(n, n.op, time.Since(start))
+	}
+	f.values[n] = v
+	return v, nil
+}
+
+// runner evaluates the outputs of a graph.
+type runner struct {
+	graph      *Graph
+	dev        *cpu.Device
+	params     []*shape.Shape
+	results    []*Node
+	shapes     []*shape.Shape
+	numOutputs int
+	// donated are the indices of the parameters whose arguments can be consumed.
+	donated []int
+	// inst is notified each time a node has been evaluated, nil if the runner is not instrumented.
+	inst ops.Instrumentation
+}
+
+var (
+	_ ops.AsyncRunner     = (*runner)(nil)
+	_ ops.ProfilingRunner = (*runner)(nil)
+)
+
+// Run evaluates the graph. The values of all the nodes are discarded after the run.
+func (r *runner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	return r.run(context.Background(), nil, nil, args)
+}
+
+// RunProfiled evaluates the graph and times the evaluation of every node.
+// The time of a node evaluating subgraphs, for example While, includes the time of the subgraphs.
+func (r *runner) RunProfiled(args []platform.Handle) (out, traces []platform.DeviceHandle, prof *ops.ExecutionProfile, err error) {
+	prof = &ops.ExecutionProfile{}
+	start := time.Now()
+	if out, traces, err = r.run(context.Background(), nil, prof, args); err != nil {
+		return nil, nil, nil, err
+	}
+	prof.Wall = time.Since(start)
+	return out, traces, prof, nil
+}
+
+// RunAsync evaluates the graph in a separate goroutine.
+// The evaluation stops before the next node when ctx is done.
+func (r *runner) RunAsync(ctx context.Context, args []platform.Handle) (ops.Execution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	exec := ops.NewHostExecution(cancel)
+	go func() {
+		exec.Complete(r.run(ctx, nil, nil, args))
+	}()
+	return exec, nil
+}
+
+// run evaluates the graph. If prof is not nil, the time spent by the run is added to prof.
+func (r *runner) run(ctx context.Context, rep *replica, prof *ops.ExecutionProfile, args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	if len(args) != len(r.params) {
+		return nil, nil, fmt.Errorf("graph %s called with %d arguments but has %d parameters", r.graph.name, len(args), len(r.params))
+	}
+	args, consumed, err := ops.ConsumeDonated(args, r.donated)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Consumed handles are freed once the run completes, unless they store an output.
+	defer func() {
+		for _, h := range consumed {
+			if h != nil {
+				h.Free()
+			}
+		}
+	}()
+	var transfer, device time.Duration
+	start := time.Now()
+	vals := make([]value, len(args))
+	for i, arg := range args {
+		if !r.graph.sameArray(arg.Shape(), r.params[i]) {
+			return nil, nil, fmt.Errorf("argument %d of graph %s has shape %s but want %s", i, r.graph.name, arg.Shape(), r.params[i])
+		}
+		if vals[i], err = r.read(arg); err != nil {
+			return nil, nil, fmt.Errorf("cannot read argument %d of graph %s: %w", i, r.graph.name, err)
+		}
+	}
+	transfer += time.Since(start)
+	f := newFrame(ctx, rep, vals)
+	f.inst = r.instrumentation(prof)
+	handles := make([]platform.DeviceHandle, 0, len(r.results))
+	defer func() {
+		if err != nil {
+			for _, h := range handles {
+				h.Free()
+			}
+		}
+	}()
+	for i, result := range r.results {
+		start = time.Now()
+		v, err := f.eval(result)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := v.(*array).encode(r.shapes[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		device += time.Since(start)
+		start = time.Now()
+		h, err := r.store(data, r.shapes[i], consumed)
+		if err != nil {
+			return nil, nil, err
+		}
+		transfer += time.Since(start)
+		handles = append(handles, h)
+	}
+	if prof != nil {
+		prof.Device += device
+		prof.Transfer += transfer
+	}
+	return handles[:r.numOutputs], handles[r.numOutputs:], nil
+}
+
+// instrumentation returns the instrumentation notified by a run, nil if the run is not instrumented.
+// Node evaluations are recorded as kernels by the profiler of the platform while it is started.
+func (r *runner) instrumentation(prof *ops.ExecutionProfile) ops.Instrumentation {
+	var ins instruments
+	if r.inst != nil {
+		ins = append(ins, r.inst)
+	}
+	if prof != nil {
+		ins = append(ins, prof)
+	}
+	if collector := r.graph.plat.Profiler(); collector.Active() {
+		ins = append(ins, kernelRecorder{collector: collector})
+	}
+	switch len(ins) {
+	case 0:
+		return nil
+	case 1:
+		return ins[0]
+	}
+	return ins
+}
+
+// instruments notifies several instrumentations.
+type instruments []ops.Instrumentation
+
+func (ins instruments) NodeEvaluated(node ops.Node, op ops.OpID, d time.Duration) {
+	for _, inst := range ins {
+		inst.NodeEvaluated(node, op, d)
+	}
+}
+
+// kernelRecorder adds a kernel record to a profiler for each node evaluation.
+type kernelRecorder struct {
+	collector *platform.Collector
+}
+
+func (k kernelRecorder) NodeEvaluated(node ops.Node, op ops.OpID, d time.Duration) {
+	end := time.Now()
+	k.collector.Add(platform.ProfileRecord{
+		Kind:   platform.KernelRecord,
+		Name:   string(op),
+		Device: 0,
+		Start:  end.Add(-d),
+		End:    end,
+	})
+}
+
+// store returns a handle storing the data of an output. The memory of a consumed handle
+// of the same shape is reused if possible, in which case the handle is removed from consumed.
+func (r *runner) store(data []byte, sh *shape.Shape, consumed []platform.DeviceHandle) (platform.DeviceHandle, error) {
+	for i, c := range consumed {
+		h, ok := c.(*cpu.Handle)
+		if !ok || h.Device() != platform.Device(r.dev) || !h.Shape().Equal(sh) {
+			continue
+		}
+		if err := h.Write(data); err != nil {
+			return nil, err
+		}
+		consumed[i] = nil
+		return h, nil
+	}
+	return r.dev.Send(data, sh)
+}
+
+// read returns the content of a handle as an array.
+func (r *runner) read(h platform.Handle) (*array, error) {
+	sh := h.Shape()
+	dt := r.graph.resolve(sh.DType)
+	switch hT := h.(type) {
+	case *cpu.Handle:
+		data := hT.Data()
+		if data == nil {
+			return nil, platform.ErrBufferFreed
+		}
+		return decode(dt, sh, data)
+	case platform.HostBuffer:
+		data := hT.AcquireRead()
+		defer hT.ReleaseRead()
+		if data == nil {
+			return nil, platform.ErrBufferFreed
+		}
+		return decode(dt, sh, data)
+	}
+	buf, err := platform.Borrow(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.ToHost(buf); err != nil {
+		return nil, err
+	}
+	return r.read(buf)
+}
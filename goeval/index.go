@@ -0,0 +1,348 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// strides returns the row-major strides of an array.
+func strides(dims []int) []int {
+	s := make([]int, len(dims))
+	stride := 1
+	for axis := len(dims) - 1; axis >= 0; axis-- {
+		s[axis] = stride
+		stride *= dims[axis]
+	}
+	return s
+}
+
+// offsetOf returns the offset of an element given its indices and the strides of the array.
+func offsetOf(idx, strides []int) int {
+	off := 0
+	for axis, i := range idx {
+		off += i * strides[axis]
+	}
+	return off
+}
+
+// next increments the indices of an element in row-major order.
+func next(idx, dims []int) {
+	for axis := len(idx) - 1; axis >= 0; axis-- {
+		idx[axis]++
+		if idx[axis] < dims[axis] {
+			return
+		}
+		idx[axis] = 0
+	}
+}
+
+// forIndices calls f with the position and the indices of every element of an array
+// in row-major order. The indices passed to f are reused between calls.
+func forIndices(dims []int, f func(i int, idx []int)) {
+	idx := make([]int, len(dims))
+	for i := range shape.Size(dims) {
+		f(i, idx)
+		next(idx, dims)
+	}
+}
+
+// mapIndices returns the offsets computed by f from the indices of every element
+// of an array in row-major order.
+func mapIndices(dims []int, f func(idx []int) int) []int {
+	offsets := make([]int, shape.Size(dims))
+	forIndices(dims, func(i int, idx []int) {
+		offsets[i] = f(idx)
+	})
+	return offsets
+}
+
+// join returns the concatenation of the elements of arrays of the same data type.
+func join(dt dtype.DataType, arrays []*array) *array {
+	n := 0
+	for _, a := range arrays {
+		n += a.size()
+	}
+	res := zeros(dt, []int{n})
+	off := 0
+	for _, a := range arrays {
+		switch vals := res.data.(type) {
+		case []bool:
+			off += copy(vals[off:], a.data.([]bool))
+		case []int64:
+			off += copy(vals[off:], a.data.([]int64))
+		case []uint64:
+			off += copy(vals[off:], a.data.([]uint64))
+		case []float64:
+			off += copy(vals[off:], a.data.([]float64))
+		case []complex128:
+			off += copy(vals[off:], a.data.([]complex128))
+		}
+	}
+	return res
+}
+
+func transpose(x *array, perm []int, dims []int) *array {
+	xStrides := strides(x.dims)
+	return x.take(dims, mapIndices(dims, func(idx []int) int {
+		off := 0
+		for axis, i := range idx {
+			off += i * xStrides[perm[axis]]
+		}
+		return off
+	}), nil)
+}
+
+func broadcastInDim(x *array, dims, axes []int) *array {
+	xStrides := strides(x.dims)
+	return x.take(dims, mapIndices(dims, func(idx []int) int {
+		off := 0
+		for xAxis, axis := range axes {
+			if x.dims[xAxis] != 1 {
+				off += idx[axis] * xStrides[xAxis]
+			}
+		}
+		return off
+	}), nil)
+}
+
+func reverse(x *array, axes []int) *array {
+	xStrides := strides(x.dims)
+	return x.take(x.dims, mapIndices(x.dims, func(idx []int) int {
+		off := offsetOf(idx, xStrides)
+		for _, axis := range axes {
+			off += (x.dims[axis] - 1 - 2*idx[axis]) * xStrides[axis]
+		}
+		return off
+	}), nil)
+}
+
+func pad(x, value *array, dims, low, interior []int) *array {
+	xStrides := strides(x.dims)
+	return x.take(dims, mapIndices(dims, func(idx []int) int {
+		off := 0
+		for axis, i := range idx {
+			p, step := i-low[axis], interior[axis]+1
+			if p < 0 || p%step != 0 || p/step >= x.dims[axis] {
+				return -1
+			}
+			off += p / step * xStrides[axis]
+		}
+		return off
+	}), value)
+}
+
+func concat(axis int, dims []int, xs []*array) *array {
+	joined := join(xs[0].dt, xs)
+	bases := make([]int, len(xs))
+	starts := make([]int, len(xs))
+	for i := 1; i < len(xs); i++ {
+		bases[i] = bases[i-1] + xs[i-1].size()
+		starts[i] = starts[i-1] + xs[i-1].dims[axis]
+	}
+	allStrides := make([][]int, len(xs))
+	for i, x := range xs {
+		allStrides[i] = strides(x.dims)
+	}
+	return joined.take(dims, mapIndices(dims, func(idx []int) int {
+		k := 0
+		for k+1 < len(xs) && idx[axis] >= starts[k+1] {
+			k++
+		}
+		idx[axis] -= starts[k]
+		off := bases[k] + offsetOf(idx, allStrides[k])
+		idx[axis] += starts[k]
+		return off
+	}), nil)
+}
+
+// slice returns the element at index along the first axis of x.
+func slice(x *array, index int) *array {
+	dims := x.dims[1:]
+	inner := shape.Size(dims)
+	offsets := make([]int, inner)
+	for i := range offsets {
+		offsets[i] = index*inner + i
+	}
+	return x.take(dims, offsets, nil)
+}
+
+// set replaces the element at index along the first axis of x. As in a dynamic update
+// slice, the index is clamped such that the element is within the bounds of x.
+func set(x, updates *array, index int64) *array {
+	index = min(max(index, 0), int64(x.dims[0]-1))
+	inner := updates.size()
+	offsets := make([]int, x.size())
+	for i := range offsets {
+		offsets[i] = i
+		if i/inner == int(index) {
+			offsets[i] = x.size() + i%inner
+		}
+	}
+	return join(x.dt, []*array{x, updates}).take(x.dims, offsets, nil)
+}
+
+// indexVector returns the index vector of indices for a batch index.
+func indexVector(indices []int64, indicesDims, indicesStrides []int, vectorAxis int, batch []int, length int) []int64 {
+	idx := make([]int, 0, len(indicesDims))
+	idx = append(idx, batch[:min(vectorAxis, len(batch))]...)
+	if vectorAxis < len(indicesDims) {
+		idx = append(idx, 0)
+	}
+	idx = append(idx, batch[min(vectorAxis, len(batch)):]...)
+	vector := make([]int64, length)
+	for k := range vector {
+		if vectorAxis < len(indicesDims) {
+			idx[vectorAxis] = k
+		}
+		vector[k] = indices[offsetOf(idx, indicesStrides)]
+	}
+	return vector
+}
+
+// notIn returns the integers in [0, n) which are not in excluded.
+func notIn(n int, excluded []int) []int {
+	var res []int
+	for i := range n {
+		if !slices.Contains(excluded, i) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// gather implements the StableHLO gather operation. Start indices are clamped
+// such that the slices are within the bounds of x.
+func gather(x, indices *array, dims ops.GatherDims, sliceSizes []int, outDims []int) (*array, error) {
+	idxVals, err := ints(indices)
+	if err != nil {
+		return nil, err
+	}
+	xStrides := strides(x.dims)
+	indicesStrides := strides(indices.dims)
+	batchAxes := notIn(len(outDims), dims.OffsetAxes)
+	sliceAxes := notIn(len(x.dims), dims.CollapsedAxes)
+	batch := make([]int, len(batchAxes))
+	start := make([]int, len(x.dims))
+	return x.take(outDims, mapIndices(outDims, func(idx []int) int {
+		for i, axis := range batchAxes {
+			batch[i] = idx[axis]
+		}
+		clear(start)
+		vector := indexVector(idxVals, indices.dims, indicesStrides, dims.IndexVectorAxis, batch, len(dims.StartIndexMap))
+		for k, axis := range dims.StartIndexMap {
+			start[axis] = int(min(max(vector[k], 0), int64(x.dims[axis]-sliceSizes[axis])))
+		}
+		for i, axis := range dims.OffsetAxes {
+			start[sliceAxes[i]] += idx[axis]
+		}
+		return offsetOf(start, xStrides)
+	}), nil), nil
+}
+
+// scatter implements the StableHLO scatter operation replacing the elements of x.
+// Elements of the updates outside of the bounds of x are ignored.
+func scatter(x, indices, updates *array, dims ops.ScatterDims) (*array, error) {
+	idxVals, err := ints(indices)
+	if err != nil {
+		return nil, err
+	}
+	xStrides := strides(x.dims)
+	indicesStrides := strides(indices.dims)
+	batchAxes := notIn(len(updates.dims), dims.UpdateWindowAxes)
+	windowAxes := notIn(len(x.dims), dims.InsertedWindowAxes)
+	offsets := make([]int, x.size())
+	for i := range offsets {
+		offsets[i] = i
+	}
+	batch := make([]int, len(batchAxes))
+	pos := make([]int, len(x.dims))
+	updatesStrides := strides(updates.dims)
+	forIndices(updates.dims, func(_ int, idx []int) {
+		for i, axis := range batchAxes {
+			batch[i] = idx[axis]
+		}
+		clear(pos)
+		vector := indexVector(idxVals, indices.dims, indicesStrides, dims.IndexVectorAxis, batch, len(dims.ScatterAxesToOperandAxes))
+		for k, axis := range dims.ScatterAxesToOperandAxes {
+			pos[axis] = int(min(max(vector[k], math.MinInt32), math.MaxInt32))
+		}
+		for i, axis := range dims.UpdateWindowAxes {
+			pos[windowAxes[i]] += idx[axis]
+		}
+		for axis, p := range pos {
+			if p < 0 || p >= x.dims[axis] {
+				return
+			}
+		}
+		offsets[offsetOf(pos, xStrides)] = x.size() + offsetOf(idx, updatesStrides)
+	})
+	return join(x.dt, []*array{x, updates}).take(x.dims, offsets, nil), nil
+}
+
+// reduceOffsets returns, for every element of an array, the offset of the element
+// of the reduction along axes in which it is accumulated.
+func reduceOffsets(dims, axes []int) ([]int, []int) {
+	var outDims []int
+	outAxes := make([]int, len(dims))
+	for axis, length := range dims {
+		outAxes[axis] = -1
+		if !slices.Contains(axes, axis) {
+			outAxes[axis] = len(outDims)
+			outDims = append(outDims, length)
+		}
+	}
+	outStrides := strides(outDims)
+	return outDims, mapIndices(dims, func(idx []int) int {
+		off := 0
+		for axis, i := range idx {
+			if outAxes[axis] >= 0 {
+				off += i * outStrides[outAxes[axis]]
+			}
+		}
+		return off
+	})
+}
+
+// lines returns the offsets of the elements of every vector of an array along an axis.
+func lines(dims []int, axis int) [][]int {
+	inner := shape.Size(dims[axis+1:])
+	outer := shape.Size(dims[:axis])
+	res := make([][]int, 0, outer*inner)
+	for o := range outer {
+		for i := range inner {
+			line := make([]int, dims[axis])
+			for j := range line {
+				line[j] = (o*dims[axis]+j)*inner + i
+			}
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// checkIndex returns an error if an index is not an atomic integer.
+func checkIndex(sh *shape.Shape) error {
+	if !sh.IsAtomic() || !dtype.IsInteger(sh.DType) {
+		return fmt.Errorf("index of shape %s is not an atomic integer", sh)
+	}
+	return nil
+}
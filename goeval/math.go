@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type mathBuilder struct {
+	g *Graph
+}
+
+var _ ops.MathBuilder = mathBuilder{}
+
+// apply adds a node applying a math function to arrays given the shape of its result.
+func (b mathBuilder) apply(op ops.OpID, xs []ops.Node, infer func([]*shape.Shape) (*shape.Shape, error), f func(dtype.DataType, []*array) (*array, error)) (ops.Node, error) {
+	nodes, err := b.g.arrays(xs...)
+	if err != nil {
+		return nil, err
+	}
+	shapes := make([]*shape.Shape, len(nodes))
+	for i, n := range nodes {
+		shapes[i] = n.typ.shape
+	}
+	sh, err := infer(shapes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(op, sh, nodes, f)
+}
+
+// unary applies an element-wise math function.
+func (b mathBuilder) unary(op ops.OpID, x ops.Node, infer func(*shape.Shape) (*shape.Shape, error)) (ops.Node, error) {
+	return b.apply(op, []ops.Node{x}, func(shapes []*shape.Shape) (*shape.Shape, error) {
+		return infer(shapes[0])
+	}, func(dt dtype.DataType, in []*array) (*array, error) {
+		return mathUnary(op, dt, in[0])
+	})
+}
+
+// sameShape returns the shape of x.
+func sameShape(x *shape.Shape) (*shape.Shape, error) {
+	return x, nil
+}
+
+func (b mathBuilder) Abs(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpAbs, x, func(x *shape.Shape) (*shape.Shape, error) {
+		return intercept.AbsShape(x), nil
+	})
+}
+
+// binary applies an element-wise math function of two arguments.
+func (b mathBuilder) binary(op ops.OpID, x, y ops.Node) (ops.Node, error) {
+	return b.apply(op, []ops.Node{x, y}, func(shapes []*shape.Shape) (*shape.Shape, error) {
+		if op == ops.OpComplex {
+			return intercept.ComplexShape(shapes[0], shapes[1])
+		}
+		return intercept.MathBinaryShape(op, shapes[0], shapes[1])
+	}, func(dt dtype.DataType, in []*array) (*array, error) {
+		return mathBinary(op, dt, in[0], in[1])
+	})
+}
+
+// predicate applies an element-wise math function returning booleans.
+func (b mathBuilder) predicate(op ops.OpID, x ops.Node) (ops.Node, error) {
+	return b.apply(op, []ops.Node{x}, func(shapes []*shape.Shape) (*shape.Shape, error) {
+		return intercept.MathPredicateShape(op, shapes[0])
+	}, func(_ dtype.DataType, in []*array) (*array, error) {
+		return mathPredicate(op, in[0])
+	})
+}
+
+// complexPart applies one of the Real, Imag or Conj math functions.
+func (b mathBuilder) complexPart(op ops.OpID, x ops.Node) (ops.Node, error) {
+	return b.unary(op, x, func(x *shape.Shape) (*shape.Shape, error) {
+		return intercept.ComplexPartShape(op, x)
+	})
+}
+
+func (b mathBuilder) Atan2(y, x ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpAtan2, y, x)
+}
+
+func (b mathBuilder) Complex(re, im ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpComplex, re, im)
+}
+
+func (b mathBuilder) Conj(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpConj, x)
+}
+
+func (b mathBuilder) Imag(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpImag, x)
+}
+
+func (b mathBuilder) IsInf(x ops.Node) (ops.Node, error) {
+	return b.predicate(ops.OpIsInf, x)
+}
+
+func (b mathBuilder) IsNaN(x ops.Node) (ops.Node, error) {
+	return b.predicate(ops.OpIsNaN, x)
+}
+
+func (b mathBuilder) Pow(x, y ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpPow, x, y)
+}
+
+func (b mathBuilder) Real(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpReal, x)
+}
+
+func (b mathBuilder) Ceil(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpCeil, x, sameShape)
+}
+
+func (b mathBuilder) Cos(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpCos, x, sameShape)
+}
+
+func (b mathBuilder) Erf(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpErf, x, sameShape)
+}
+
+func (b mathBuilder) Exp(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpExp, x, sameShape)
+}
+
+func (b mathBuilder) Expm1(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpExpm1, x, sameShape)
+}
+
+func (b mathBuilder) Floor(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpFloor, x, sameShape)
+}
+
+func (b mathBuilder) Log(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLog, x, sameShape)
+}
+
+func (b mathBuilder) Log1p(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLog1p, x, sameShape)
+}
+
+func (b mathBuilder) Logistic(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLogistic, x, sameShape)
+}
+
+func (b mathBuilder) Round(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpRound, x, sameShape)
+}
+
+func (b mathBuilder) Rsqrt(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpRsqrt, x, sameShape)
+}
+
+func (b mathBuilder) Sign(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSign, x, sameShape)
+}
+
+func (b mathBuilder) Sin(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSin, x, sameShape)
+}
+
+func (b mathBuilder) Sqrt(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSqrt, x, sameShape)
+}
+
+func (b mathBuilder) Tanh(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpTanh, x, sameShape)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type nnBuilder struct {
+	g *Graph
+}
+
+var _ ops.NNBuilder = nnBuilder{}
+
+// orDefault returns the ith value of vals or def if vals is nil.
+func orDefault[T any](vals []T, i int, def T) T {
+	if vals == nil {
+		return def
+	}
+	return vals[i]
+}
+
+// dilatedIndex returns the index of an element of an axis of a given length once the axis
+// has been padded by low elements and dilated. It returns false for padded elements.
+func dilatedIndex(pos, low, dilation, length int) (int, bool) {
+	pos -= low
+	if pos < 0 || pos%dilation != 0 || pos/dilation >= length {
+		return 0, false
+	}
+	return pos / dilation, true
+}
+
+func (b nnBuilder) ConvGeneralDilated(x, kernel ops.Node, dims ops.ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int, _ ops.Precision) (ops.Node, error) {
+	nodes, err := b.g.arrays(x, kernel)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ConvShape(nodes[0].typ.shape, nodes[1].typ.shape, dims, strides, padding, inputDilation, kernelDilation, featureGroupCount)
+	if err != nil {
+		return nil, err
+	}
+	c := &conv{dims: dims, strides: strides, padding: padding, inputDilation: inputDilation, kernelDilation: kernelDilation, groups: featureGroupCount, outDims: sh.AxisLengths}
+	return b.g.compute(ops.OpConvGeneralDilated, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		var data any
+		switch xVals := in[0].data.(type) {
+		case []int64:
+			data = convNumbers(c, in[0], in[1], xVals, in[1].data.([]int64))
+		case []uint64:
+			data = convNumbers(c, in[0], in[1], xVals, in[1].data.([]uint64))
+		case []float64:
+			data = convNumbers(c, in[0], in[1], xVals, in[1].data.([]float64))
+		case []complex128:
+			data = convNumbers(c, in[0], in[1], xVals, in[1].data.([]complex128))
+		default:
+			return nil, fmt.Errorf("convolution not supported on %s", in[0].dt)
+		}
+		return newArray(dt, sh.AxisLengths, data), nil
+	})
+}
+
+// conv are the parameters of a convolution.
+type conv struct {
+	dims                          ops.ConvDims
+	strides                       []int
+	padding                       [][2]int
+	inputDilation, kernelDilation []int
+	groups                        int
+	outDims                       []int
+}
+
+func convNumbers[T number](c *conv, x, kernel *array, xVals, kVals []T) []T {
+	xStrides, kStrides := strides(x.dims), strides(kernel.dims)
+	kInFeatures := kernel.dims[c.dims.KernelInputFeatureAxis]
+	outPerGroup := kernel.dims[c.dims.KernelOutputFeatureAxis] / c.groups
+	kSpatialDims := make([]int, len(c.dims.KernelSpatialAxes))
+	for i, axis := range c.dims.KernelSpatialAxes {
+		kSpatialDims[i] = kernel.dims[axis]
+	}
+	out := make([]T, shape.Size(c.outDims))
+	forIndices(c.outDims, func(k int, idx []int) {
+		batch, feature := idx[c.dims.OutputBatchAxis], idx[c.dims.OutputFeatureAxis]
+		group := feature / outPerGroup
+		var sum T
+		forIndices(kSpatialDims, func(_ int, kIdx []int) {
+			xOff := batch * xStrides[c.dims.InputBatchAxis]
+			kOff := feature * kStrides[c.dims.KernelOutputFeatureAxis]
+			for i, axis := range c.dims.InputSpatialAxes {
+				pos := idx[c.dims.OutputSpatialAxes[i]]*orDefault(c.strides, i, 1) + kIdx[i]*orDefault(c.kernelDilation, i, 1)
+				p, ok := dilatedIndex(pos, orDefault(c.padding, i, [2]int{})[0], orDefault(c.inputDilation, i, 1), x.dims[axis])
+				if !ok {
+					return
+				}
+				xOff += p * xStrides[axis]
+				kOff += kIdx[i] * kStrides[c.dims.KernelSpatialAxes[i]]
+			}
+			for f := range kInFeatures {
+				inFeature := group*kInFeatures + f
+				sum += xVals[xOff+inFeature*xStrides[c.dims.InputFeatureAxis]] * kVals[kOff+f*kStrides[c.dims.KernelInputFeatureAxis]]
+			}
+		})
+		out[k] = sum
+	})
+	return out
+}
+
+// windows returns the elements of the windows of x as an array of shape [n, w] where n is
+// the number of windows and w the number of elements in a window. Padded elements are
+// replaced by the atomic value fill.
+func windows(x *array, window ops.Window, outDims []int, fill *array) *array {
+	xStrides := strides(x.dims)
+	var offsets []int
+	forIndices(outDims, func(_ int, idx []int) {
+		forIndices(window.Dimensions, func(_ int, wIdx []int) {
+			off := 0
+			for axis, i := range idx {
+				pos := i*orDefault(window.Strides, axis, 1) + wIdx[axis]*orDefault(window.WindowDilations, axis, 1)
+				p, ok := dilatedIndex(pos, orDefault(window.Padding, axis, [2]int{})[0], orDefault(window.BaseDilations, axis, 1), x.dims[axis])
+				if !ok {
+					off = -1
+					break
+				}
+				off += p * xStrides[axis]
+			}
+			offsets = append(offsets, off)
+		})
+	})
+	return x.take([]int{shape.Size(outDims), shape.Size(window.Dimensions)}, offsets, fill)
+}
+
+func (b nnBuilder) ReduceWindow(body *ops.Subgraph, init, x ops.Node, window ops.Window) (ops.Node, error) {
+	nodes, err := b.g.arrays(init, x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ReduceWindowShape(nodes[0].typ.shape, nodes[1].typ.shape, window)
+	if err != nil {
+		return nil, err
+	}
+	sub, result, err := b.g.combiner(body, sh.DType)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpReduceWindow, sh, nodes, func(_ dtype.DataType, in []*array) (*array, error) {
+		init, x := in[0], in[1]
+		res, err := reduceWith(sub, result, init, windows(x, window, sh.AxisLengths, init), []int{1})
+		if err != nil {
+			return nil, err
+		}
+		return res.reshape(sh.AxisLengths), nil
+	})
+}
+
+// pool reduces the windows of x with one of the ReduceSum or ReduceMax operations.
+// Padded elements are the initial values of the reductions.
+func (b nnBuilder) pool(op, reduceOp ops.OpID, x ops.Node, window ops.Window) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.PoolShape(op, nodes[0].typ.shape, window)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(op, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		x := in[0]
+		init, err := reduceInit(reduceOp, x.dt)
+		if err != nil {
+			return nil, err
+		}
+		res, err := reduce(reduceOp, dt, windows(x, window, sh.AxisLengths, init), []int{1})
+		if err != nil {
+			return nil, err
+		}
+		if op == ops.OpAvgPool {
+			size := float64(shape.Size(window.Dimensions))
+			vals := res.data.([]float64)
+			for i := range vals {
+				vals[i] /= size
+			}
+			fit(dt, vals)
+		}
+		return res.reshape(sh.AxisLengths), nil
+	})
+}
+
+func (b nnBuilder) MaxPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	return b.pool(ops.OpMaxPool, ops.OpReduceMax, x, window)
+}
+
+func (b nnBuilder) AvgPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	return b.pool(ops.OpAvgPool, ops.OpReduceSum, x, window)
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type dtypeBuilder struct {
+	g *Graph
+}
+
+var _ ops.DTypeBuilder = dtypeBuilder{}
+
+// Bitcast reinterprets the bits of x. If the sizes of the data types differ,
+// the innermost axis is added or removed as in StableHLO.
+func (b dtypeBuilder) Bitcast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	src := nodes[0].typ.shape
+	srcType, dstType := b.g.resolve(src.DType), b.g.resolve(target)
+	if !dtype.IsValid(srcType) || !dtype.IsValid(dstType) || dtype.IsSubByte(srcType) || dtype.IsSubByte(dstType) {
+		return nil, fmt.Errorf("cannot bitcast %s to %s", src, target)
+	}
+	srcSize, dstSize := dtype.Sizeof(srcType), dtype.Sizeof(dstType)
+	axisLengths := slices.Clone(src.AxisLengths)
+	switch {
+	case srcSize > dstSize:
+		axisLengths = append(axisLengths, srcSize/dstSize)
+	case srcSize < dstSize:
+		if len(axisLengths) == 0 || axisLengths[len(axisLengths)-1]*srcSize != dstSize {
+			return nil, fmt.Errorf("cannot bitcast %s to %s: innermost axis must have length %d", src, target, dstSize/srcSize)
+		}
+		axisLengths = axisLengths[:len(axisLengths)-1]
+	}
+	sh := shape.Of(target, axisLengths...)
+	return b.g.compute(ops.OpBitcast, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		data, err := in[0].encode(shape.Of(in[0].dt, in[0].dims...))
+		if err != nil {
+			return nil, err
+		}
+		return decode(dt, shape.Of(dt, axisLengths...), data)
+	})
+}
+
+type numBuilder struct {
+	g *Graph
+}
+
+var _ ops.NumBuilder = numBuilder{}
+
+func (b numBuilder) Iota(sh *shape.Shape, iotaAxis int) (ops.Node, error) {
+	axis, err := shape.NormalizeAxis(iotaAxis, len(sh.AxisLengths))
+	if err != nil {
+		return nil, err
+	}
+	return b.g.compute(ops.OpIota, sh, nil, func(dt dtype.DataType, _ []*array) (*array, error) {
+		vals := make([]int64, sh.Size())
+		forIndices(sh.AxisLengths, func(i int, idx []int) {
+			vals[i] = int64(idx[axis])
+		})
+		return cast(dt, &array{dt: dtype.Int64, dims: sh.AxisLengths, data: vals}), nil
+	})
+}
+
+// arg applies one of the Argmax or Argmin operations.
+func (b numBuilder) arg(op ops.OpID, x ops.Node, axis int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	xShape := nodes[0].typ.shape
+	sh, err := intercept.ArgShape(op, xShape, axis)
+	if err != nil {
+		return nil, err
+	}
+	if axis, err = shape.NormalizeAxis(axis, len(xShape.AxisLengths)); err != nil {
+		return nil, err
+	}
+	sign := 1
+	if op == ops.OpArgmin {
+		sign = -1
+	}
+	return b.g.compute(op, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		x := in[0]
+		compare := comparator(x)
+		stride := strides(x.dims)[axis]
+		var indices []int64
+		for _, line := range lines(x.dims, axis) {
+			best := line[0]
+			for _, off := range line[1:] {
+				if sign*compare(off, best) > 0 {
+					best = off
+				}
+			}
+			indices = append(indices, int64((best-line[0])/stride))
+		}
+		return &array{dt: dt, dims: sh.AxisLengths, data: indices}, nil
+	})
+}
+
+func (b numBuilder) Argmax(x ops.Node, axis int) (ops.Node, error) {
+	return b.arg(ops.OpArgmax, x, axis)
+}
+
+func (b numBuilder) Argmin(x ops.Node, axis int) (ops.Node, error) {
+	return b.arg(ops.OpArgmin, x, axis)
+}
+
+// cumulate applies one of the CumSum or CumProd operations.
+func (b numBuilder) cumulate(op ops.OpID, x ops.Node, axis int) (ops.Node, error) {
+	nodes, err := b.g.arrays(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.CumShape(op, nodes[0].typ.shape, axis)
+	if err != nil {
+		return nil, err
+	}
+	if axis, err = shape.NormalizeAxis(axis, len(sh.AxisLengths)); err != nil {
+		return nil, err
+	}
+	return b.g.compute(op, sh, nodes, func(dt dtype.DataType, in []*array) (*array, error) {
+		x := in[0]
+		var data any
+		switch vals := x.data.(type) {
+		case []int64:
+			data = cumulateNumbers(op, vals, lines(x.dims, axis))
+		case []uint64:
+			data = cumulateNumbers(op, vals, lines(x.dims, axis))
+		case []float64:
+			data = cumulateNumbers(op, vals, lines(x.dims, axis))
+		case []complex128:
+			data = cumulateNumbers(op, vals, lines(x.dims, axis))
+		default:
+			return nil, fmt.Errorf("%s not supported on %s", op, x.dt)
+		}
+		return newArray(dt, x.dims, data), nil
+	})
+}
+
+// cumulateNumbers returns the cumulative sums or products of the elements of vals along lines.
+func cumulateNumbers[T number](op ops.OpID, vals []T, lines [][]int) []T {
+	f := add[T]
+	if op == ops.OpCumProd {
+		f = mul[T]
+	}
+	res := slices.Clone(vals)
+	for _, line := range lines {
+		for j := 1; j < len(line); j++ {
+			res[line[j]] = f(res[line[j-1]], res[line[j]])
+		}
+	}
+	return res
+}
+
+func (b numBuilder) CumSum(x ops.Node, axis int) (ops.Node, error) {
+	return b.cumulate(ops.OpCumSum, x, axis)
+}
+
+func (b numBuilder) CumProd(x ops.Node, axis int) (ops.Node, error) {
+	return b.cumulate(ops.OpCumProd, x, axis)
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type randBuilder struct {
+	g *Graph
+}
+
+var _ ops.RandBuilder = randBuilder{}
+
+// threefry2x32 computes the 20 rounds of the Threefry-2x32 block cipher of the Random123 library.
+func threefry2x32(key [2]uint32, ctr [2]uint32) [2]uint32 {
+	rotations := [8]int{13, 15, 26, 6, 17, 29, 16, 24}
+	ks := [3]uint32{key[0], key[1], 0x1BD11BDA ^ key[0] ^ key[1]}
+	x0, x1 := ctr[0]+ks[0], ctr[1]+ks[1]
+	for r := range 20 {
+		x0 += x1
+		x1 = bits.RotateLeft32(x1, rotations[r%8])
+		x1 ^= x0
+		if r%4 == 3 {
+			i := uint32(r/4 + 1)
+			x0 += ks[i%3]
+			x1 += ks[(i+1)%3] + i
+		}
+	}
+	return [2]uint32{x0, x1}
+}
+
+// philox4x32 computes the 10 rounds of the Philox-4x32 generator of the Random123 library.
+func philox4x32(key [2]uint32, ctr [4]uint32) [4]uint32 {
+	for range 10 {
+		hi0, lo0 := bits.Mul32(0xD2511F53, ctr[0])
+		hi1, lo1 := bits.Mul32(0xCD9E8D57, ctr[2])
+		ctr = [4]uint32{hi1 ^ ctr[1] ^ key[0], lo1, hi0 ^ ctr[3] ^ key[1], lo0}
+		key[0] += 0x9E3779B9
+		key[1] += 0xBB67AE85
+	}
+	return ctr
+}
+
+// randomWords generates n random 32-bit words from a state: the key is the first element
+// of the state and the counter is the second. It returns the next state, in which
+// the counter has been incremented by the number of blocks generated.
+func randomWords(algorithm ops.RngAlgorithm, state []uint64, n int) ([]uint64, []uint32, error) {
+	key := [2]uint32{uint32(state[0]), uint32(state[0] >> 32)}
+	ctr := state[1]
+	words := make([]uint32, 0, n+3)
+	for len(words) < n {
+		lo, hi := uint32(ctr), uint32(ctr>>32)
+		switch algorithm {
+		case ops.DefaultRng, ops.ThreeFryRng:
+			block := threefry2x32(key, [2]uint32{lo, hi})
+			words = append(words, block[:]...)
+		case ops.PhiloxRng:
+			block := philox4x32(key, [4]uint32{lo, hi, 0, 0})
+			words = append(words, block[:]...)
+		default:
+			return nil, nil, fmt.Errorf("random number generator algorithm %s not supported", algorithm)
+		}
+		ctr++
+	}
+	return []uint64{state[0], ctr}, words[:n], nil
+}
+
+// randomBits generates an array of random bits of the data type Uint32 or Uint64.
+func randomBits(algorithm ops.RngAlgorithm, state *array, dt dtype.DataType, dims []int) (*array, *array, error) {
+	n := shape.Size(dims)
+	wordsPerValue := dtype.Sizeof(dt) / dtype.Uint32Size
+	next, words, err := randomWords(algorithm, state.data.([]uint64), n*wordsPerValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals := make([]uint64, n)
+	for i := range vals {
+		vals[i] = uint64(words[i*wordsPerValue])
+		if wordsPerValue == 2 {
+			vals[i] |= uint64(words[2*i+1]) << 32
+		}
+	}
+	return &array{dt: dtype.Uint64, dims: state.dims, data: next}, &array{dt: dt, dims: dims, data: vals}, nil
+}
+
+// uniform generates values in [0, 1) by setting the mantissa of 1 to random bits and subtracting 1.
+func uniform(state *array, dt dtype.DataType, dims []int) (*array, []float64, error) {
+	bitsType := dtype.Uint32
+	if dt == dtype.Float64 {
+		bitsType = dtype.Uint64
+	}
+	next, random, err := randomBits(ops.ThreeFryRng, state, bitsType, dims)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals := convert(random.data.([]uint64), func(v uint64) float64 {
+		if dt == dtype.Float64 {
+			return math.Float64frombits(v>>12|math.Float64bits(1)) - 1
+		}
+		return float64(math.Float32frombits(uint32(v)>>9|math.Float32bits(1)) - 1)
+	})
+	return next, vals, nil
+}
+
+// generate adds a node returning a tuple of the next state and of the generated values.
+func (b randBuilder) generate(op ops.OpID, state ops.Node, sh *shape.Shape, f func(state *array, dt dtype.DataType) (next, values *array, err error)) (ops.Node, error) {
+	nodes, err := b.g.arrays(state)
+	if err != nil {
+		return nil, err
+	}
+	stateShape := nodes[0].typ.shape
+	if _, err := intercept.RandShape(op, stateShape, sh); err != nil {
+		return nil, err
+	}
+	dt := b.g.resolve(sh.DType)
+	t := typ{elems: []typ{{shape: stateShape}, {shape: sh}}}
+	return b.g.newNode(op, t, nodes, func(_ *frame, in []value) (value, error) {
+		next, values, err := f(in[0].(*array), dt)
+		if err != nil {
+			return nil, err
+		}
+		return tuple{next, values}, nil
+	})
+}
+
+func (b randBuilder) RngBitGenerator(algorithm ops.RngAlgorithm, state ops.Node, sh *shape.Shape) (ops.Node, error) {
+	return b.generate(ops.OpRngBitGenerator, state, sh, func(state *array, dt dtype.DataType) (*array, *array, error) {
+		return randomBits(algorithm, state, dt, sh.AxisLengths)
+	})
+}
+
+func (b randBuilder) Uniform(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.generate(ops.OpUniform, state, sh, func(state *array, dt dtype.DataType) (*array, *array, error) {
+		next, vals, err := uniform(state, dt, sh.AxisLengths)
+		if err != nil {
+			return nil, nil, err
+		}
+		return next, newArray(dt, sh.AxisLengths, vals), nil
+	})
+}
+
+// Normal uses the Box-Muller transform: sqrt(-2*log(1-u1))*cos(2*pi*u2)
+// is normally distributed if u1 and u2 are uniformly distributed in [0, 1).
+func (b randBuilder) Normal(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.generate(ops.OpNormal, state, sh, func(state *array, dt dtype.DataType) (*array, *array, error) {
+		next, u1, err := uniform(state, dt, sh.AxisLengths)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, u2, err := uniform(next, dt, sh.AxisLengths)
+		if err != nil {
+			return nil, nil, err
+		}
+		vals := make([]float64, len(u1))
+		for i := range vals {
+			vals[i] = math.Sqrt(-2*math.Log(1-u1[i])) * math.Cos(2*math.Pi*u2[i])
+		}
+		return next, newArray(dt, sh.AxisLengths, vals), nil
+	})
+}
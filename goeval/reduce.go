@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// accumulate combines the elements of vals into the elements of a slice of length n
+// given, for every element, the offset of the element in which it is accumulated.
+func accumulate[T any](vals []T, offsets []int, n int, init T, f func(T, T) T) []T {
+	acc := make([]T, n)
+	for i := range acc {
+		acc[i] = init
+	}
+	for i, off := range offsets {
+		acc[off] = f(acc[off], vals[i])
+	}
+	return acc
+}
+
+func add[T number](x, y T) T { return x + y }
+
+func mul[T number](x, y T) T { return x * y }
+
+// reduce applies one of the ReduceSum, ReduceProd, ReduceMax or ReduceMin operations.
+// Initial values are the identities of the operations, as in StableHLO.
+func reduce(op ops.OpID, dt dtype.DataType, x *array, axes []int) (*array, error) {
+	outDims, offsets := reduceOffsets(x.dims, axes)
+	n := shape.Size(outDims)
+	var data any
+	switch vals := x.data.(type) {
+	case []bool:
+		switch op {
+		case ops.OpReduceMax:
+			data = accumulate(vals, offsets, n, false, func(a, b bool) bool { return a || b })
+		case ops.OpReduceMin:
+			data = accumulate(vals, offsets, n, true, func(a, b bool) bool { return a && b })
+		}
+	case []int64:
+		lo, hi := signedRange(x.dt)
+		data = reduceNumbers(op, vals, offsets, n, lo, hi)
+	case []uint64:
+		data = reduceNumbers(op, vals, offsets, n, 0, unsignedMax(x.dt))
+	case []float64:
+		data = reduceNumbers(op, vals, offsets, n, math.Inf(-1), math.Inf(1))
+	case []complex128:
+		switch op {
+		case ops.OpReduceSum:
+			data = accumulate(vals, offsets, n, 0, add)
+		case ops.OpReduceProd:
+			data = accumulate(vals, offsets, n, 1, mul)
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s not supported on %s", op, x.dt)
+	}
+	return newArray(dt, outDims, data), nil
+}
+
+// reduceInit returns the atomic initial value of one of the ReduceSum, ReduceProd,
+// ReduceMax or ReduceMin operations: the reduction of an empty array.
+func reduceInit(op ops.OpID, dt dtype.DataType) (*array, error) {
+	return reduce(op, dt, zeros(dt, []int{0}), []int{0})
+}
+
+// reduceWith reduces x along a set of axes from init by calling a subgraph combining
+// two atomic values. Elements are combined in row-major order.
+func reduceWith(sub *Graph, result *Node, init, x *array, axes []int) (*array, error) {
+	outDims, offsets := reduceOffsets(x.dims, axes)
+	accs := make([]*array, shape.Size(outDims))
+	for i := range accs {
+		accs[i] = init
+	}
+	for i, off := range offsets {
+		v, err := sub.call(nil, result, []value{accs[off], x.take(nil, []int{i}, nil)})
+		if err != nil {
+			return nil, err
+		}
+		accs[off] = v.(*array)
+	}
+	return join(init.dt, accs).reshape(outDims), nil
+}
+
+// reduceNumbers reduces ordered numbers given their lowest and highest values.
+// The maximum and minimum of floating-point values propagate NaNs.
+func reduceNumbers[T interface {
+	cmp.Ordered
+	number
+}](op ops.OpID, vals []T, offsets []int, n int, lowest, highest T) []T {
+	switch op {
+	case ops.OpReduceSum:
+		return accumulate(vals, offsets, n, 0, add)
+	case ops.OpReduceProd:
+		return accumulate(vals, offsets, n, 1, mul)
+	case ops.OpReduceMax:
+		return accumulate(vals, offsets, n, lowest, func(a, b T) T { return max(a, b) })
+	case ops.OpReduceMin:
+		return accumulate(vals, offsets, n, highest, func(a, b T) T { return min(a, b) })
+	}
+	return nil
+}
+
+// number is a constraint for the Go types in which arrays compute numbers.
+type number interface {
+	int64 | uint64 | float64 | complex128
+}
+
+func dotGeneral(dt dtype.DataType, x, y *array, batchAxes, reduceAxes [2][]int, outDims []int) (*array, error) {
+	var data any
+	switch xVals := x.data.(type) {
+	case []int64:
+		data = dotNumbers(x, y, xVals, y.data.([]int64), batchAxes, reduceAxes, outDims)
+	case []uint64:
+		data = dotNumbers(x, y, xVals, y.data.([]uint64), batchAxes, reduceAxes, outDims)
+	case []float64:
+		data = dotNumbers(x, y, xVals, y.data.([]float64), batchAxes, reduceAxes, outDims)
+	case []complex128:
+		data = dotNumbers(x, y, xVals, y.data.([]complex128), batchAxes, reduceAxes, outDims)
+	default:
+		return nil, fmt.Errorf("dot product not supported on %s", x.dt)
+	}
+	return newArray(dt, outDims, data), nil
+}
+
+// dotNumbers computes a general dot product. The axes of the result are the batch axes,
+// then the free axes of x, then the free axes of y.
+func dotNumbers[T number](x, y *array, xVals, yVals []T, batchAxes, reduceAxes [2][]int, outDims []int) []T {
+	xStrides, yStrides := strides(x.dims), strides(y.dims)
+	xFree := notIn(len(x.dims), slices.Concat(batchAxes[0], reduceAxes[0]))
+	yFree := notIn(len(y.dims), slices.Concat(batchAxes[1], reduceAxes[1]))
+	reduceDims := make([]int, len(reduceAxes[0]))
+	for i, axis := range reduceAxes[0] {
+		reduceDims[i] = x.dims[axis]
+	}
+	reduceOffsets := func(axes []int, strides []int) []int {
+		return mapIndices(reduceDims, func(idx []int) int {
+			off := 0
+			for i, r := range idx {
+				off += r * strides[axes[i]]
+			}
+			return off
+		})
+	}
+	xReduce, yReduce := reduceOffsets(reduceAxes[0], xStrides), reduceOffsets(reduceAxes[1], yStrides)
+	out := make([]T, shape.Size(outDims))
+	numBatch := len(batchAxes[0])
+	forIndices(outDims, func(k int, idx []int) {
+		xOff, yOff := 0, 0
+		for i, b := range idx[:numBatch] {
+			xOff += b * xStrides[batchAxes[0][i]]
+			yOff += b * yStrides[batchAxes[1][i]]
+		}
+		for i, axis := range xFree {
+			xOff += idx[numBatch+i] * xStrides[axis]
+		}
+		for i, axis := range yFree {
+			yOff += idx[numBatch+len(xFree)+i] * yStrides[axis]
+		}
+		var sum T
+		for r := range xReduce {
+			sum += xVals[xOff+xReduce[r]] * yVals[yOff+yReduce[r]]
+		}
+		out[k] = sum
+	})
+	return out
+}
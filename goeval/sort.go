@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goeval
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// compareFloats compares floating-point values with a total order in which
+// -0 is less than +0 and NaNs are greater than all other values.
+func compareFloats(a, b float64) int {
+	switch aNaN, bNaN := math.IsNaN(a), math.IsNaN(b); {
+	case aNaN || bNaN:
+		return cmp.Compare(boolToInt(aNaN), boolToInt(bNaN))
+	case a == 0 && b == 0:
+		return cmp.Compare(boolToInt(!math.Signbit(a)), boolToInt(!math.Signbit(b)))
+	}
+	return cmp.Compare(a, b)
+}
+
+// comparator returns a function comparing two elements of an array given their offsets.
+// Complex numbers are compared by their real parts first, then by their imaginary parts.
+func comparator(x *array) func(i, j int) int {
+	switch vals := x.data.(type) {
+	case []bool:
+		return func(i, j int) int { return cmp.Compare(boolToInt(vals[i]), boolToInt(vals[j])) }
+	case []int64:
+		return func(i, j int) int { return cmp.Compare(vals[i], vals[j]) }
+	case []uint64:
+		return func(i, j int) int { return cmp.Compare(vals[i], vals[j]) }
+	case []float64:
+		return func(i, j int) int { return compareFloats(vals[i], vals[j]) }
+	case []complex128:
+		return func(i, j int) int {
+			if c := compareFloats(real(vals[i]), real(vals[j])); c != 0 {
+				return c
+			}
+			return compareFloats(imag(vals[i]), imag(vals[j]))
+		}
+	}
+	panic("invalid array data")
+}
+
+// sortAlong sorts the vectors of x along an axis. It returns the sorted values and
+// the int32 indices of the values along the axis. The sort is stable.
+func sortAlong(x *array, axis int, descending bool) (values, indices *array) {
+	compare := comparator(x)
+	if descending {
+		ascending := compare
+		compare = func(i, j int) int { return ascending(j, i) }
+	}
+	stride := strides(x.dims)[axis]
+	sorted := make([]int, x.size())
+	positions := make([]int64, x.size())
+	for _, line := range lines(x.dims, axis) {
+		order := slices.Clone(line)
+		slices.SortStableFunc(order, compare)
+		for j, off := range line {
+			sorted[off] = order[j]
+			positions[off] = int64((order[j] - line[0]) / stride)
+		}
+	}
+	return x.take(x.dims, sorted, nil), &array{dt: dtype.Int32, dims: x.dims, data: positions}
+}
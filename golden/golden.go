@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package golden provides a backend recording the calls made by the interpreter
+// to compare them with a golden file in regression tests.
+//
+// A typical test builds a graph with the recording backend and checks the record:
+//
+//	b, rec := golden.New(backend)
+//	... build and compile graphs with b ...
+//	if err := golden.Compare("testdata/graph.golden", rec.String(), *update); err != nil {
+//		t.Error(err)
+//	}
+package golden
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops/intercept"
+)
+
+// Recorder is an interceptor recording one line per call.
+// A Recorder is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+var _ intercept.Interceptor = (*Recorder)(nil)
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// New returns a backend forwarding all calls to b and recording them.
+func New(b backend.Backend) (backend.Backend, *Recorder) {
+	rec := NewRecorder()
+	return intercept.NewBackend(b, rec), rec
+}
+
+// Before does nothing: calls are recorded once they complete.
+func (r *Recorder) Before(*intercept.Call) error {
+	return nil
+}
+
+// After records the call.
+func (r *Recorder) After(call *intercept.Call, err error) {
+	line := call.String()
+	if err != nil {
+		line += fmt.Sprintf(" error: %v", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, line)
+}
+
+// Lines returns the recorded calls.
+func (r *Recorder) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.lines...)
+}
+
+// String returns the recorded calls, one per line.
+func (r *Recorder) String() string {
+	lines := r.Lines()
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Reset removes all the recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = nil
+}
+
+// Compare compares a record with the content of a golden file.
+// If update is true, the golden file is overwritten with the record instead.
+// The returned error includes a diff between the golden file and the record.
+func Compare(path, got string, update bool) error {
+	if update {
+		return os.WriteFile(path, []byte(got), 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read golden file: %v", err)
+	}
+	if diff := Diff(string(want), got); diff != "" {
+		return fmt.Errorf("record does not match golden file %s (-want +got):\n%s", path, diff)
+	}
+	return nil
+}
+
+// Diff returns a line-based diff between two records, or an empty string if they are equal.
+// Removed lines are prefixed with "-", added lines with "+" and common lines with " ".
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a, b := splitLines(want), splitLines(got)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&out, " %s\n", a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&out, "+%s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&out, "-%s\n", a[i])
+			i++
+		}
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
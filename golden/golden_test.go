@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden_test
+
+import (
+	"flag"
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/golden"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+	b, rec := golden.New(opstest.NewBackend(platformtest.New(1)))
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Math().Tanh(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := g.Core().Binary(&ast.BinaryExpr{Op: token.MUL}, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := g.Platform().Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: z, Shape: shape.Of(dtype.Float32, 2, 3)}}, nil, []*shape.Shape{shape.Of(dtype.Float32, 2, 3)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := golden.Compare("testdata/graph.golden", rec.String(), *update); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := golden.Diff("a\nb\nc\n", "a\nc\nd\n")
+	want := " a\n-b\n c\n+d\n"
+	if got != want {
+		t.Errorf("got diff:\n%s\nwant:\n%s", got, want)
+	}
+	if diff := golden.Diff("a\n", "a\n"); diff != "" {
+		t.Errorf("got diff %q for equal records", diff)
+	}
+}
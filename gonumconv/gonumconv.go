@@ -0,0 +1,165 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gonumconv converts between gonum matrices and vectors and the arrays
+// and host buffers of the backend.
+//
+// The interfaces of this package are subsets of the interfaces of gonum.org/v1/gonum/mat,
+// such that gonum values can be passed directly without this module depending on gonum.
+// Converting back to gonum is done with mat.NewDense and mat.NewVecDense, for example:
+//
+//	r, c, data, err := gonumconv.MatrixData(a)
+//	m := mat.NewDense(r, c, data)
+package gonumconv
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Matrix is implemented by gonum mat.Matrix.
+	Matrix interface {
+		Dims() (r, c int)
+		At(i, j int) float64
+	}
+
+	// Mutable is implemented by gonum mat.Mutable, for example *mat.Dense.
+	Mutable interface {
+		Matrix
+		Set(i, j int, v float64)
+	}
+
+	// Vector is implemented by gonum mat.Vector.
+	Vector interface {
+		Len() int
+		AtVec(i int) float64
+	}
+
+	// rowViewer is implemented by *mat.Dense to access its rows without copy.
+	rowViewer interface {
+		RawRowView(i int) []float64
+	}
+)
+
+// FromMatrix copies a matrix into a new array of shape [r][c].
+func FromMatrix(m Matrix) *array.Dense[float64] {
+	r, c := m.Dims()
+	a := array.Zeros[float64](r, c)
+	data := a.Flat()
+	if rv, ok := m.(rowViewer); ok {
+		for i := range r {
+			copy(data[i*c:(i+1)*c], rv.RawRowView(i))
+		}
+		return a
+	}
+	for i := range r {
+		for j := range c {
+			data[i*c+j] = m.At(i, j)
+		}
+	}
+	return a
+}
+
+// FromVector copies a vector into a new array of shape [n].
+func FromVector(v Vector) *array.Dense[float64] {
+	a := array.Zeros[float64](v.Len())
+	data := a.Flat()
+	for i := range data {
+		data[i] = v.AtVec(i)
+	}
+	return a
+}
+
+// MatrixToHostBuffer copies a matrix into a host buffer of shape [r][c]float64.
+func MatrixToHostBuffer(m Matrix, alloc platform.Allocator) (platform.HostBuffer, error) {
+	return FromMatrix(m).ToHostBuffer(alloc)
+}
+
+// VectorToHostBuffer copies a vector into a host buffer of shape [n]float64.
+func VectorToHostBuffer(v Vector, alloc platform.Allocator) (platform.HostBuffer, error) {
+	return FromVector(v).ToHostBuffer(alloc)
+}
+
+// SliceToHostBuffer copies a slice into a host buffer of shape [n]float64.
+func SliceToHostBuffer(vals []float64, alloc platform.Allocator) (platform.HostBuffer, error) {
+	a, err := array.New(vals, len(vals))
+	if err != nil {
+		return nil, err
+	}
+	return a.ToHostBuffer(alloc)
+}
+
+// MatrixData returns the dimensions and the row-major data of an array of rank 2,
+// as expected by mat.NewDense. The returned data is shared with the array.
+func MatrixData(a shape.ArrayI[float64]) (r, c int, data []float64, err error) {
+	dims := a.Shape()
+	if len(dims) != 2 {
+		return 0, 0, nil, fmt.Errorf("cannot convert an array of rank %d to a matrix", len(dims))
+	}
+	return dims[0], dims[1], a.Flat(), nil
+}
+
+// CopyToMatrix copies an array of shape [r][c] stored in a handle into a matrix
+// of the same dimensions. The array must store float32 or float64 values.
+func CopyToMatrix(dst Mutable, handle platform.Handle) error {
+	sh := handle.Shape()
+	r, c := dst.Dims()
+	if len(sh.AxisLengths) != 2 || sh.AxisLengths[0] != r || sh.AxisLengths[1] != c {
+		return fmt.Errorf("cannot copy an array of shape %s into a %dx%d matrix", sh, r, c)
+	}
+	vals, err := toFloat64s(handle)
+	if err != nil {
+		return err
+	}
+	for i := range r {
+		for j := range c {
+			dst.Set(i, j, vals[i*c+j])
+		}
+	}
+	return nil
+}
+
+// ToSlice copies the values of an array of float32 or float64 stored in a handle into a slice,
+// for example to create a gonum vector with mat.NewVecDense.
+func ToSlice(handle platform.Handle) ([]float64, error) {
+	return toFloat64s(handle)
+}
+
+func toFloat64s(handle platform.Handle) ([]float64, error) {
+	switch dt := handle.Shape().DType; dt {
+	case dtype.Float64:
+		a, err := array.FromHandle[float64](handle)
+		if err != nil {
+			return nil, err
+		}
+		return a.Flat(), nil
+	case dtype.Float32:
+		a, err := array.FromHandle[float32](handle)
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]float64, len(a.Flat()))
+		for i, v := range a.Flat() {
+			vals[i] = float64(v)
+		}
+		return vals, nil
+	default:
+		return nil, fmt.Errorf("cannot convert an array of %s to float64 values", dt)
+	}
+}
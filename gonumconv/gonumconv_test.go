@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gonumconv_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/gonumconv"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+// dense mimics gonum mat.Dense.
+type dense struct {
+	r, c int
+	data []float64
+}
+
+func (m *dense) Dims() (int, int) {
+	return m.r, m.c
+}
+
+func (m *dense) At(i, j int) float64 {
+	return m.data[i*m.c+j]
+}
+
+func (m *dense) Set(i, j int, v float64) {
+	m.data[i*m.c+j] = v
+}
+
+// transpose mimics a gonum matrix without direct access to its rows.
+type transpose struct {
+	m *dense
+}
+
+func (t transpose) Dims() (int, int) {
+	return t.m.c, t.m.r
+}
+
+func (t transpose) At(i, j int) float64 {
+	return t.m.At(j, i)
+}
+
+func TestMatrix(t *testing.T) {
+	m := &dense{r: 2, c: 3, data: []float64{1, 2, 3, 4, 5, 6}}
+	a := gonumconv.FromMatrix(transpose{m})
+	if got, want := a.Flat(), []float64{1, 4, 2, 5, 3, 6}; !slices.Equal(got, want) || !slices.Equal(a.Shape(), []int{3, 2}) {
+		t.Errorf("got %v with shape %v but want %v with shape [3 2]", got, a.Shape(), want)
+	}
+	r, c, data, err := gonumconv.MatrixData(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != 3 || c != 2 || len(data) != 6 {
+		t.Errorf("got %dx%d matrix of %d elements but want 3x2", r, c, len(data))
+	}
+	buf, err := gonumconv.MatrixToHostBuffer(m, platformtest.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	if got, want := buf.Shape().String(), "[2][3]float64"; got != want {
+		t.Errorf("got shape %s but want %s", got, want)
+	}
+	dst := &dense{r: 2, c: 3, data: make([]float64, 6)}
+	if err := gonumconv.CopyToMatrix(dst, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dst.data, m.data) {
+		t.Errorf("got matrix %v but want %v", dst.data, m.data)
+	}
+	if err := gonumconv.CopyToMatrix(&dense{r: 3, c: 2, data: make([]float64, 6)}, buf); err == nil {
+		t.Errorf("expected an error when copying into a matrix of different dimensions")
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	a, err := array.New([]float32{1.5, 2.5}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := a.ToHostBuffer(platformtest.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	got, err := gonumconv.ToSlice(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1.5, 2.5}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+}
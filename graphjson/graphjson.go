@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphjson exports graphs recorded by ops/intercept in a stable JSON format
+// meant for tooling and diffing.
+//
+// Operations are listed in the order of their node identifiers and all maps
+// are encoded with sorted keys, such that the same sequence of builder calls
+// always produces the same document. Field names must not change.
+package graphjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Graph is the JSON representation of a graph and its subgraphs.
+	Graph struct {
+		Name     string            `json:"name"`
+		Metadata map[string]string `json:"metadata,omitempty"`
+		Params   []*shape.Shape    `json:"params,omitempty"`
+		Ops      []*Op             `json:"ops"`
+		Outputs  []*OutputNode     `json:"outputs,omitempty"`
+		Traced   []*OutputNode     `json:"traced,omitempty"`
+	}
+
+	// Op is a builder call creating a node.
+	Op struct {
+		// ID of the node, unique within the root graph.
+		ID int `json:"id"`
+		// Op is the operation.
+		Op ops.OpID `json:"op"`
+		// Graph is the path of the graph in which the node has been created.
+		Graph string `json:"graph"`
+		// Inputs are the identifiers of the input nodes.
+		Inputs []int `json:"inputs,omitempty"`
+		// Attrs are the other parameters of the operation, in call order.
+		Attrs []Attr `json:"attrs,omitempty"`
+		// Shape is the inferred shape of the node, or nil if unknown.
+		Shape *shape.Shape `json:"shape,omitempty"`
+		// ShapeError is the error returned by the shape inference, if any.
+		ShapeError string `json:"shape_error,omitempty"`
+	}
+
+	// Attr is a parameter of an operation which is not a node, formatted as text.
+	Attr struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// OutputNode is an output of a graph.
+	OutputNode struct {
+		Node  int          `json:"node"`
+		Shape *shape.Shape `json:"shape"`
+	}
+)
+
+// FromGraph returns the JSON representation of a graph and its subgraphs.
+// The output nodes must have been created by the graph.
+func FromGraph(g *intercept.Graph, outputs, traced []*ops.OutputNode, params []*shape.Shape) (*Graph, error) {
+	js := &Graph{
+		Name:     g.Name(),
+		Metadata: g.Config().Metadata,
+		Params:   params,
+		Ops:      []*Op{},
+	}
+	appendOps(js, g)
+	slices.SortFunc(js.Ops, func(a, b *Op) int { return a.ID - b.ID })
+	var err error
+	if js.Outputs, err = outputNodes(outputs); err != nil {
+		return nil, err
+	}
+	if js.Traced, err = outputNodes(traced); err != nil {
+		return nil, err
+	}
+	return js, nil
+}
+
+func appendOps(js *Graph, g *intercept.Graph) {
+	for _, node := range g.Nodes() {
+		call := node.Call()
+		op := &Op{
+			ID:    node.ID(),
+			Op:    call.Op,
+			Graph: g.Path(),
+			Shape: call.Shape,
+		}
+		if call.ShapeErr != nil {
+			op.ShapeError = call.ShapeErr.Error()
+		}
+		for _, input := range call.Inputs {
+			op.Inputs = append(op.Inputs, input.ID())
+		}
+		for _, attr := range call.Attrs {
+			op.Attrs = append(op.Attrs, Attr{Name: attr.Name, Value: intercept.FormatValue(attr.Value)})
+		}
+		js.Ops = append(js.Ops, op)
+	}
+	for _, sub := range g.Subgraphs() {
+		appendOps(js, sub)
+	}
+}
+
+func outputNodes(outs []*ops.OutputNode) ([]*OutputNode, error) {
+	var res []*OutputNode
+	for _, out := range outs {
+		node, ok := out.Node.(*intercept.Node)
+		if !ok {
+			return nil, fmt.Errorf("cannot export output node %T: not created by an intercepted graph", out.Node)
+		}
+		res = append(res, &OutputNode{Node: node.ID(), Shape: out.Shape})
+	}
+	return res, nil
+}
+
+// Marshal encodes a graph as an indented JSON document.
+func Marshal(g *Graph) ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// Write writes the JSON document of a recorded graph.
+func Write(w io.Writer, g *intercept.Graph, outputs, traced []*ops.OutputNode, params []*shape.Shape) error {
+	js, err := FromGraph(g, outputs, traced, params)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(js)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Unmarshal decodes a JSON document encoded by Marshal.
+func Unmarshal(data []byte) (*Graph, error) {
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("cannot decode graph: %v", err)
+	}
+	return &g, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphjson_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/graphjson"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+type nopInterceptor struct{}
+
+func (nopInterceptor) Before(*intercept.Call) error { return nil }
+
+func (nopInterceptor) After(*intercept.Call, error) {}
+
+const want = `{
+  "name": "test",
+  "metadata": {
+    "source": "main.gx"
+  },
+  "params": [
+    {
+      "dtype": "float32",
+      "axes": [
+        6
+      ]
+    }
+  ],
+  "ops": [
+    {
+      "id": 0,
+      "op": "core.Argument",
+      "graph": "test",
+      "attrs": [
+        {
+          "name": "name",
+          "value": "x"
+        },
+        {
+          "name": "shape",
+          "value": "[6]float32"
+        },
+        {
+          "name": "index",
+          "value": "0"
+        }
+      ],
+      "shape": {
+        "dtype": "float32",
+        "axes": [
+          6
+        ]
+      }
+    },
+    {
+      "id": 1,
+      "op": "core.Reshape",
+      "graph": "test",
+      "inputs": [
+        0
+      ],
+      "attrs": [
+        {
+          "name": "axisLengths",
+          "value": "[2 3]"
+        }
+      ],
+      "shape": {
+        "dtype": "float32",
+        "axes": [
+          2,
+          3
+        ]
+      }
+    }
+  ],
+  "outputs": [
+    {
+      "node": 1,
+      "shape": {
+        "dtype": "float32",
+        "axes": [
+          2,
+          3
+        ]
+      }
+    }
+  ]
+}
+`
+
+func TestWrite(t *testing.T) {
+	b := intercept.NewBackend(opstest.NewBackend(platformtest.New(1)), nopInterceptor{})
+	graph, err := b.NewOps("test", ops.WithMetadata("source", "main.gx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := graph.(*intercept.Graph)
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Core().Reshape(x, []int{2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := []*ops.OutputNode{{Node: y, Shape: shape.Of(dtype.Float32, 2, 3)}}
+	var buf bytes.Buffer
+	if err := graphjson.Write(&buf, g, outputs, nil, []*shape.Shape{shape.Of(dtype.Float32, 6)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	decoded, err := graphjson.Unmarshal(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencoded, err := graphjson.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(string(reencoded)+"\n", want) {
+		t.Errorf("decoded graph encoded as:\n%s\nwant:\n%s", reencoded, want)
+	}
+}
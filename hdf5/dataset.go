@@ -0,0 +1,643 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hdf5
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// DefaultChunkBytes is the default number of bytes read from a dataset at once.
+const DefaultChunkBytes = 4 << 20
+
+// Dataset is a multi-dimensional array stored in an HDF5 file.
+type Dataset struct {
+	f         *File
+	name      string
+	dims      []int
+	dtype     dtype.DataType
+	elemSize  int
+	bigEndian bool
+	// fill is the value of the elements which have not been written, nil for zeros.
+	fill  []byte
+	store storage
+}
+
+func (f *File) dataset(name string, msgs []message) (*Dataset, error) {
+	ds := &Dataset{f: f, name: name}
+	for _, typ := range []uint16{msgDataspace, msgDatatype, msgLayout} {
+		msg, ok := find(msgs, typ)
+		if !ok {
+			return nil, fmt.Errorf("object header has no message of type %#x", typ)
+		}
+		if msg.flags&msgFlagShared != 0 {
+			return nil, fmt.Errorf("shared message of type %#x not supported", typ)
+		}
+	}
+	var err error
+	var maxDims []int
+	msg, _ := find(msgs, msgDataspace)
+	if ds.dims, maxDims, err = f.dataspace(msg.data); err != nil {
+		return nil, fmt.Errorf("invalid dataspace message: %v", err)
+	}
+	msg, _ = find(msgs, msgDatatype)
+	if ds.dtype, ds.elemSize, ds.bigEndian, err = datatype(msg.data); err != nil {
+		return nil, fmt.Errorf("invalid datatype message: %v", err)
+	}
+	if ds.fill, err = fillValue(msgs); err != nil {
+		return nil, fmt.Errorf("invalid fill value message: %v", err)
+	}
+	if ds.fill != nil && len(ds.fill) != ds.elemSize {
+		return nil, fmt.Errorf("fill value of %d bytes for elements of %d bytes", len(ds.fill), ds.elemSize)
+	}
+	var filters []filter
+	if msg, ok := find(msgs, msgFilters); ok {
+		if filters, err = f.filters(msg.data); err != nil {
+			return nil, fmt.Errorf("invalid filter pipeline message: %v", err)
+		}
+	}
+	msg, _ = find(msgs, msgLayout)
+	if ds.store, err = f.layout(msg.data, ds.dims, maxDims, ds.elemSize, filters); err != nil {
+		return nil, fmt.Errorf("invalid layout message: %v", err)
+	}
+	return ds, nil
+}
+
+// Name returns the path of the dataset in the file.
+func (ds *Dataset) Name() string {
+	return ds.name
+}
+
+// Dims returns the dimensions of the dataset.
+func (ds *Dataset) Dims() []int {
+	return slices.Clone(ds.dims)
+}
+
+// DType returns the data type in which the dataset is read.
+func (ds *Dataset) DType() dtype.DataType {
+	return ds.dtype
+}
+
+// Shape returns the shape of the host buffer storing the dataset.
+func (ds *Dataset) Shape() (*shape.Shape, error) {
+	sh := shape.Of(ds.dtype, ds.dims...)
+	if err := sh.Check(); err != nil {
+		return nil, fmt.Errorf("dataset %s: %v", ds.name, err)
+	}
+	return sh, nil
+}
+
+// rowBytes returns the number of bytes of a row along the first dimension.
+func (ds *Dataset) rowBytes() int {
+	n := ds.elemSize
+	for _, dim := range ds.dims[min(1, len(ds.dims)):] {
+		n *= dim
+	}
+	return n
+}
+
+// rows returns the number of rows along the first dimension. Scalar datasets have a single row.
+func (ds *Dataset) rows() int {
+	if len(ds.dims) == 0 {
+		return 1
+	}
+	return ds.dims[0]
+}
+
+// ReadRows reads count consecutive rows along the first dimension, starting at row start,
+// into dst in row-major order. Scalar datasets have a single row.
+func (ds *Dataset) ReadRows(dst []byte, start, count int) error {
+	if start < 0 || count < 0 || start > ds.rows()-count {
+		return fmt.Errorf("rows [%d, %d) out of the %d rows of dataset %s", start, start+count, ds.rows(), ds.name)
+	}
+	if want := count * ds.rowBytes(); len(dst) != want {
+		return fmt.Errorf("buffer of %d bytes cannot store %d rows of %d bytes", len(dst), count, ds.rowBytes())
+	}
+	if err := ds.store.read(ds, dst, start, count); err != nil {
+		return err
+	}
+	if ds.bigEndian {
+		size := ds.elemSize
+		if dtype.IsComplex(ds.dtype) {
+			// The real and imaginary parts are swapped separately.
+			size /= 2
+		}
+		swapBytes(dst, size)
+	}
+	return nil
+}
+
+// fillRows sets all the elements of dst to the fill value.
+func (ds *Dataset) fillRows(dst []byte) {
+	if ds.fill == nil {
+		clear(dst)
+		return
+	}
+	for i := 0; i < len(dst); i += len(ds.fill) {
+		copy(dst[i:], ds.fill)
+	}
+}
+
+func swapBytes(data []byte, size int) {
+	for i := 0; i+size <= len(data); i += size {
+		elem := data[i : i+size]
+		for j := range size / 2 {
+			elem[j], elem[size-1-j] = elem[size-1-j], elem[j]
+		}
+	}
+}
+
+// Options configures how datasets are read.
+type Options struct {
+	// ChunkBytes is the maximum number of bytes read from the dataset at once.
+	ChunkBytes int
+}
+
+// Option configures how datasets are read.
+type Option func(*Options)
+
+// WithChunkBytes sets the maximum number of bytes read from the dataset at once.
+func WithChunkBytes(n int) Option {
+	return func(opts *Options) {
+		opts.ChunkBytes = n
+	}
+}
+
+// Read reads the dataset into a host buffer allocated by alloc.
+// The dataset is read in chunks of rows of at most ChunkBytes bytes, rounded to whole chunks
+// of the file if the dataset is chunked, such that each chunk of the file is decoded once.
+func (ds *Dataset) Read(alloc platform.Allocator, opts ...Option) (platform.HostBuffer, error) {
+	options := Options{ChunkBytes: DefaultChunkBytes}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sh, err := ds.Shape()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := alloc.Allocate(sh)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Acquire()
+	err = ds.readAll(data, options.ChunkBytes)
+	buf.Release()
+	if err != nil {
+		buf.Free()
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (ds *Dataset) readAll(data []byte, chunkBytes int) error {
+	rows, rowBytes := ds.rows(), ds.rowBytes()
+	if rows == 0 || rowBytes == 0 {
+		return nil
+	}
+	chunkRows := max(chunkBytes/rowBytes, 1)
+	if step := ds.store.rowAlignment(); step > 1 {
+		chunkRows = max(chunkRows/step, 1) * step
+	}
+	for start := 0; start < rows; start += chunkRows {
+		count := min(chunkRows, rows-start)
+		if err := ds.ReadRows(data[start*rowBytes:(start+count)*rowBytes], start, count); err != nil {
+			return fmt.Errorf("cannot read rows [%d, %d) of dataset %s: %v", start, start+count, ds.name, err)
+		}
+	}
+	return nil
+}
+
+// dataspace decodes the dimensions and the maximum dimensions of a dataspace message.
+// Unlimited maximum dimensions are set to -1.
+func (f *File) dataspace(data []byte) (dims, maxDims []int, err error) {
+	d := f.decoder(data)
+	version := d.uint8()
+	rank := int(d.uint8())
+	flags := d.uint8()
+	switch version {
+	case 1:
+		d.skip(5)
+	case 2:
+		if typ := d.uint8(); typ == 2 && d.err == nil {
+			return nil, nil, fmt.Errorf("null dataspaces not supported")
+		}
+	default:
+		if d.err == nil {
+			return nil, nil, fmt.Errorf("dataspace message version %d not supported", version)
+		}
+	}
+	if rank > maxRank {
+		return nil, nil, fmt.Errorf("rank %d too large", rank)
+	}
+	dims = make([]int, rank)
+	for i := range dims {
+		dims[i] = d.int()
+	}
+	maxDims = slices.Clone(dims)
+	if flags&0x01 != 0 {
+		unlimited := uint64(math.MaxUint64) >> (64 - 8*min(f.lengthSize, 8))
+		for i := range maxDims {
+			if v := d.length(); v == unlimited {
+				maxDims[i] = -1
+			} else {
+				maxDims[i] = int(min(v, math.MaxInt))
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, nil, d.err
+	}
+	if _, err := shape.Size64(dims); err != nil {
+		return nil, nil, err
+	}
+	return dims, maxDims, nil
+}
+
+// Classes of datatype messages.
+const (
+	classFixedPoint = 0
+	classFloat      = 1
+	classCompound   = 6
+	classEnum       = 8
+)
+
+// datatype decodes a datatype message. It returns the data type of the elements,
+// their size in bytes, and whether they are stored in big-endian order.
+func datatype(data []byte) (dtype.DataType, int, bool, error) {
+	d := &decoder{buf: data}
+	dt, size, bigEndian := decodeDatatype(d)
+	return dt, size, bigEndian, d.err
+}
+
+func decodeDatatype(d *decoder) (dtype.DataType, int, bool) {
+	classVersion := d.uint8()
+	class, version := classVersion&0x0f, classVersion>>4
+	bitField := d.bytes(3)
+	size := int(d.uint32())
+	if d.err != nil {
+		return dtype.Invalid, 0, false
+	}
+	bigEndian := bitField[0]&0x01 != 0
+	switch class {
+	case classFixedPoint:
+		d.skip(4) // Bit offset and precision.
+		signed := bitField[0]&0x08 != 0
+		dt, ok := integers[[2]int{size, btoi(signed)}]
+		if !ok {
+			d.fail("integers of %d bytes not supported", size)
+		}
+		return dt, size, bigEndian
+	case classFloat:
+		d.skip(4) // Bit offset and precision.
+		d.skip(2) // Exponent location and size.
+		d.skip(1) // Mantissa location.
+		mantissa := d.uint8()
+		d.skip(4) // Exponent bias.
+		if bitField[0]&0x40 != 0 {
+			d.fail("VAX floating-point numbers not supported")
+		}
+		dt, ok := floats[[2]int{size, int(mantissa)}]
+		if !ok {
+			d.fail("floating-point numbers of %d bytes with a mantissa of %d bits not supported", size, mantissa)
+		}
+		return dt, size, bigEndian
+	case classCompound:
+		return decodeComplex(d, version, int(bitField[0])|int(bitField[1])<<8, size)
+	case classEnum:
+		return decodeEnum(d, version, int(bitField[0])|int(bitField[1])<<8)
+	}
+	d.fail("datatype class %d not supported", class)
+	return dtype.Invalid, 0, false
+}
+
+var integers = map[[2]int]dtype.DataType{
+	{1, 1}: dtype.Int8,
+	{2, 1}: dtype.Int16,
+	{4, 1}: dtype.Int32,
+	{8, 1}: dtype.Int64,
+	{1, 0}: dtype.Uint8,
+	{2, 0}: dtype.Uint16,
+	{4, 0}: dtype.Uint32,
+	{8, 0}: dtype.Uint64,
+}
+
+var floats = map[[2]int]dtype.DataType{
+	{2, 10}: dtype.Float16,
+	{2, 7}:  dtype.Bfloat16,
+	{4, 23}: dtype.Float32,
+	{8, 52}: dtype.Float64,
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// memberName reads the name of a member of a compound or enum datatype.
+func memberName(d *decoder, version uint8) string {
+	if version >= 3 {
+		return d.cstring(0)
+	}
+	return d.cstring(8)
+}
+
+// decodeComplex decodes a compound datatype storing complex numbers, as written by h5py:
+// a real and an imaginary part of the same floating-point type.
+func decodeComplex(d *decoder, version uint8, members, size int) (dtype.DataType, int, bool) {
+	if members != 2 {
+		d.fail("compound datatypes with %d members not supported", members)
+		return dtype.Invalid, 0, false
+	}
+	var part dtype.DataType
+	var bigEndian bool
+	for i := range members {
+		memberName(d, version)
+		var offset int
+		switch version {
+		case 1:
+			offset = int(d.uint32())
+			d.skip(28) // Dimensionality, permutation and dimensions of array members.
+		case 2:
+			offset = int(d.uint32())
+		case 3:
+			offset = int(d.uintN(offsetBytes(size)))
+		default:
+			d.fail("compound datatype version %d not supported", version)
+		}
+		dt, partSize, partBigEndian := decodeDatatype(d)
+		if d.err != nil {
+			return dtype.Invalid, 0, false
+		}
+		if (dt != dtype.Float32 && dt != dtype.Float64) || offset != i*partSize || 2*partSize != size {
+			d.fail("compound datatypes other than complex numbers not supported")
+			return dtype.Invalid, 0, false
+		}
+		part, bigEndian = dt, partBigEndian
+	}
+	if part == dtype.Float32 {
+		return dtype.Complex64, size, bigEndian
+	}
+	return dtype.Complex128, size, bigEndian
+}
+
+// offsetBytes returns the number of bytes storing offsets of members in compounds of a given size.
+func offsetBytes(size int) int {
+	n := 1
+	for size >= 1<<(8*n) && n < 8 {
+		n++
+	}
+	return n
+}
+
+// decodeEnum decodes an enum datatype. Enums of which the members are FALSE and TRUE
+// are read as booleans, as written by h5py. Other enums are read as their base type.
+func decodeEnum(d *decoder, version uint8, members int) (dtype.DataType, int, bool) {
+	dt, size, bigEndian := decodeDatatype(d)
+	names := make([]string, members)
+	for i := range names {
+		names[i] = memberName(d, version)
+	}
+	values := d.bytes(members * size)
+	if d.err != nil {
+		return dtype.Invalid, 0, false
+	}
+	if size == 1 && slices.Equal(names, []string{"FALSE", "TRUE"}) && slices.Equal(values, []byte{0, 1}) {
+		dt = dtype.Bool
+	}
+	return dt, size, bigEndian
+}
+
+// fillValue returns the fill value of a dataset, nil if it is not defined.
+func fillValue(msgs []message) ([]byte, error) {
+	if msg, ok := find(msgs, msgFillValue); ok {
+		d := &decoder{buf: msg.data}
+		version := d.uint8()
+		defined := false
+		switch version {
+		case 1, 2:
+			d.skip(2) // Space allocation and fill value write times.
+			defined = d.uint8() != 0 || version == 1
+		case 3:
+			defined = d.uint8()&0x20 != 0
+		default:
+			if d.err == nil {
+				return nil, fmt.Errorf("fill value message version %d not supported", version)
+			}
+		}
+		var fill []byte
+		if defined {
+			fill = d.bytes(int(d.uint32()))
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		if len(fill) == 0 {
+			return nil, nil
+		}
+		return fill, nil
+	}
+	if msg, ok := find(msgs, msgFillValueOld); ok {
+		d := &decoder{buf: msg.data}
+		fill := d.bytes(int(d.uint32()))
+		if d.err != nil || len(fill) == 0 {
+			return nil, d.err
+		}
+		return fill, nil
+	}
+	return nil, nil
+}
+
+// Filters of the filter pipeline.
+const (
+	filterDeflate    = 1
+	filterShuffle    = 2
+	filterFletcher32 = 3
+)
+
+// filter is a filter applied to the chunks of a dataset.
+type filter struct {
+	id     uint16
+	params []uint32
+}
+
+// filters decodes a filter pipeline message.
+func (f *File) filters(data []byte) ([]filter, error) {
+	d := f.decoder(data)
+	version := d.uint8()
+	n := int(d.uint8())
+	switch version {
+	case 1:
+		d.skip(6)
+	case 2:
+	default:
+		if d.err == nil {
+			return nil, fmt.Errorf("filter pipeline message version %d not supported", version)
+		}
+	}
+	filters := make([]filter, n)
+	for i := range filters {
+		flt := &filters[i]
+		flt.id = d.uint16()
+		nameLength := 0
+		if version == 1 || flt.id >= 256 {
+			nameLength = int(d.uint16())
+		}
+		d.skip(2) // Flags.
+		params := int(d.uint16())
+		if version == 1 {
+			nameLength = (nameLength + 7) / 8 * 8
+		}
+		d.skip(nameLength)
+		for range params {
+			flt.params = append(flt.params, d.uint32())
+		}
+		if version == 1 && params%2 == 1 {
+			d.skip(4)
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		switch flt.id {
+		case filterDeflate, filterShuffle, filterFletcher32:
+		default:
+			return nil, fmt.Errorf("filter %d not supported", flt.id)
+		}
+	}
+	return filters, d.err
+}
+
+// Classes of layout messages.
+const (
+	layoutCompact    = 0
+	layoutContiguous = 1
+	layoutChunked    = 2
+)
+
+// Types of chunk indices of version 4 layout messages.
+const (
+	indexSingle     = 1
+	indexImplicit   = 2
+	indexFixedArray = 3
+)
+
+// layout decodes a layout message and returns where the data of the dataset is stored.
+func (f *File) layout(data []byte, dims, maxDims []int, elemSize int, filters []filter) (storage, error) {
+	d := f.decoder(data)
+	version := d.uint8()
+	if version != 3 && version != 4 {
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, fmt.Errorf("layout message version %d not supported", version)
+	}
+	values := []uint64{uint64(elemSize)}
+	for _, dim := range dims {
+		values = append(values, uint64(dim))
+	}
+	size, err := checkedSize(values)
+	if err != nil {
+		return nil, err
+	}
+	class := d.uint8()
+	switch class {
+	case layoutCompact:
+		n := int(d.uint16())
+		raw := d.bytes(n)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return newCompact(raw, size)
+	case layoutContiguous:
+		addr := d.offset()
+		n := d.length()
+		if d.err != nil {
+			return nil, d.err
+		}
+		return newContiguous(addr, n, size)
+	case layoutChunked:
+	default:
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, fmt.Errorf("layout class %d not supported", class)
+	}
+	var chunkDims []uint64
+	if version == 3 {
+		// Chunks of version 3 messages are indexed by a version 1 B-tree.
+		rank := int(d.uint8())
+		addr := d.offset()
+		for range rank {
+			chunkDims = append(chunkDims, uint64(d.uint32()))
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		c, err := newChunked(dims, maxDims, elemSize, chunkDims, filters, false)
+		if err != nil {
+			return nil, err
+		}
+		if c.index, err = f.btreeIndex(addr, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	flags := d.uint8()
+	rank := int(d.uint8())
+	dimBytes := int(d.uint8())
+	for range rank {
+		chunkDims = append(chunkDims, d.uintN(dimBytes))
+	}
+	typ := d.uint8()
+	if d.err != nil {
+		return nil, d.err
+	}
+	c, err := newChunked(dims, maxDims, elemSize, chunkDims, filters, flags&0x01 != 0)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case indexSingle:
+		ref := chunkRef{size: uint64(c.chunkBytes)}
+		if flags&0x02 != 0 {
+			ref.size = d.length()
+			ref.mask = d.uint32()
+		}
+		ref.addr = d.offset()
+		c.index = singleIndex{ref: ref}
+	case indexImplicit:
+		c.index = implicitIndex{addr: d.offset(), chunkBytes: uint64(c.chunkBytes)}
+	case indexFixedArray:
+		d.skip(1) // Number of bits of the number of elements of data block pages.
+		addr := d.offset()
+		if d.err == nil {
+			if c.index, err = f.fixedArrayIndex(addr, c); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("chunk index type %d not supported", typ)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return c, nil
+}
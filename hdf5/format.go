@@ -0,0 +1,526 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hdf5
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// signature starts the superblock of HDF5 files.
+const signature = "\x89HDF\r\n\x1a\n"
+
+// undefined is the address of objects which have not been allocated.
+const undefined = math.MaxUint64
+
+// Limits protecting against corrupted files.
+const (
+	maxBlockSize = 1 << 28
+	maxRank      = 32
+	maxTreeDepth = 64
+	maxLinkDepth = 16
+	maxBlocks    = 1 << 16
+)
+
+// Types of the messages of object headers.
+const (
+	msgNil          = 0x00
+	msgDataspace    = 0x01
+	msgLinkInfo     = 0x02
+	msgDatatype     = 0x03
+	msgFillValueOld = 0x04
+	msgFillValue    = 0x05
+	msgLink         = 0x06
+	msgLayout       = 0x08
+	msgFilters      = 0x0b
+	msgContinuation = 0x10
+	msgSymbolTable  = 0x11
+)
+
+// msgFlagShared is set on messages stored in another object header or in the shared message heap.
+const msgFlagShared = 0x02
+
+// message is a message of an object header.
+type message struct {
+	typ   uint16
+	flags uint8
+	data  []byte
+}
+
+// decoder reads little-endian values from a block of metadata.
+// The first error is stored and all subsequent reads return zero values.
+type decoder struct {
+	buf        []byte
+	pos        int
+	offsetSize int
+	lengthSize int
+	err        error
+}
+
+func (f *File) decoder(buf []byte) *decoder {
+	return &decoder{buf: buf, offsetSize: f.offsetSize, lengthSize: f.lengthSize}
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (d *decoder) remaining() int {
+	return len(d.buf) - d.pos
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > d.remaining() {
+		d.fail("unexpected end of block: cannot read %d bytes at byte %d of %d", n, d.pos, len(d.buf))
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) skip(n int) {
+	d.bytes(n)
+}
+
+func (d *decoder) signature(sig string) {
+	if b := d.bytes(len(sig)); b != nil && string(b) != sig {
+		d.fail("got signature %q but want %q", b, sig)
+	}
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// uintN reads an unsigned integer stored in n bytes.
+func (d *decoder) uintN(n int) uint64 {
+	if n > 8 {
+		d.fail("integers of %d bytes not supported", n)
+		return 0
+	}
+	var v uint64
+	b := d.bytes(n)
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v
+}
+
+// offset reads an address. Addresses with all their bits set are returned as undefined.
+func (d *decoder) offset() uint64 {
+	v := d.uintN(d.offsetSize)
+	if d.offsetSize < 8 && v == 1<<(8*d.offsetSize)-1 {
+		return undefined
+	}
+	return v
+}
+
+func (d *decoder) length() uint64 {
+	return d.uintN(d.lengthSize)
+}
+
+// int reads a length and checks that it fits in an int.
+func (d *decoder) int() int {
+	v := d.length()
+	if v > math.MaxInt {
+		d.fail("length %d too large", v)
+		return 0
+	}
+	return int(v)
+}
+
+// cstring reads a null-terminated string. If pad is set, the string and its terminator
+// are padded to a multiple of pad bytes.
+func (d *decoder) cstring(pad int) string {
+	if d.err != nil {
+		return ""
+	}
+	end := d.pos
+	for end < len(d.buf) && d.buf[end] != 0 {
+		end++
+	}
+	if end == len(d.buf) {
+		d.fail("unterminated string at byte %d", d.pos)
+		return ""
+	}
+	s := string(d.buf[d.pos:end])
+	n := end + 1 - d.pos
+	if pad > 0 {
+		n = (n + pad - 1) / pad * pad
+	}
+	d.skip(n)
+	return s
+}
+
+// checksum returns the Jenkins lookup3 hash, with an initial value of 0,
+// which HDF5 stores after the metadata blocks of the newer versions of the format.
+func checksum(data []byte) uint32 {
+	a := 0xdeadbeef + uint32(len(data))
+	b, c := a, a
+	for len(data) > 12 {
+		a += binary.LittleEndian.Uint32(data)
+		b += binary.LittleEndian.Uint32(data[4:])
+		c += binary.LittleEndian.Uint32(data[8:])
+		a -= c
+		a ^= bits.RotateLeft32(c, 4)
+		c += b
+		b -= a
+		b ^= bits.RotateLeft32(a, 6)
+		a += c
+		c -= b
+		c ^= bits.RotateLeft32(b, 8)
+		b += a
+		a -= c
+		a ^= bits.RotateLeft32(c, 16)
+		c += b
+		b -= a
+		b ^= bits.RotateLeft32(a, 19)
+		a += c
+		c -= b
+		c ^= bits.RotateLeft32(b, 4)
+		b += a
+		data = data[12:]
+	}
+	if len(data) == 0 {
+		return c
+	}
+	var tail [12]byte
+	copy(tail[:], data)
+	a += binary.LittleEndian.Uint32(tail[:])
+	b += binary.LittleEndian.Uint32(tail[4:])
+	c += binary.LittleEndian.Uint32(tail[8:])
+	c ^= b
+	c -= bits.RotateLeft32(b, 14)
+	a ^= c
+	a -= bits.RotateLeft32(c, 11)
+	b ^= a
+	b -= bits.RotateLeft32(a, 25)
+	c ^= b
+	c -= bits.RotateLeft32(b, 16)
+	a ^= c
+	a -= bits.RotateLeft32(c, 4)
+	b ^= a
+	b -= bits.RotateLeft32(a, 14)
+	c ^= b
+	c -= bits.RotateLeft32(b, 24)
+	return c
+}
+
+// verify checks the checksum stored in the last 4 bytes of a metadata block.
+func verify(block []byte) error {
+	n := len(block) - 4
+	if n < 0 {
+		return fmt.Errorf("block of %d bytes too small to store a checksum", len(block))
+	}
+	if got, want := checksum(block[:n]), binary.LittleEndian.Uint32(block[n:]); got != want {
+		return fmt.Errorf("checksum mismatch: got %#08x but the file stores %#08x", got, want)
+	}
+	return nil
+}
+
+// readAt reads len(buf) bytes at an absolute position in the file.
+func (f *File) readAt(buf []byte, pos uint64) error {
+	if pos > math.MaxInt64-uint64(len(buf)) {
+		return fmt.Errorf("position %d out of range", pos)
+	}
+	n, err := f.r.ReadAt(buf, int64(pos))
+	if n == len(buf) {
+		return nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// abs returns the absolute position in the file of an address.
+func (f *File) abs(addr uint64) (uint64, error) {
+	if addr == undefined {
+		return 0, fmt.Errorf("cannot read from an undefined address")
+	}
+	pos := f.base + addr
+	if pos < addr {
+		return 0, fmt.Errorf("address %d out of range", addr)
+	}
+	return pos, nil
+}
+
+// read reads n bytes at an address relative to the base address of the file.
+func (f *File) read(addr uint64, n uint64) ([]byte, error) {
+	if n > maxBlockSize {
+		return nil, fmt.Errorf("block of %d bytes at address %d too large", n, addr)
+	}
+	pos, err := f.abs(addr)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if err := f.readAt(buf, pos); err != nil {
+		return nil, fmt.Errorf("cannot read %d bytes at address %d: %v", n, addr, err)
+	}
+	return buf, nil
+}
+
+// readPrefix reads at most n bytes at an address, fewer if the end of the file is reached.
+// It is used to read the fixed-size part of structures of which the total size is not known yet.
+func (f *File) readPrefix(addr uint64, n int) ([]byte, error) {
+	pos, err := f.abs(addr)
+	if err != nil {
+		return nil, err
+	}
+	if pos > math.MaxInt64-uint64(n) {
+		return nil, fmt.Errorf("address %d out of range", addr)
+	}
+	buf := make([]byte, n)
+	read, err := f.r.ReadAt(buf, int64(pos))
+	if read == 0 && err != nil {
+		return nil, fmt.Errorf("cannot read at address %d: %v", addr, err)
+	}
+	return buf[:read], nil
+}
+
+// objectHeader returns the messages of the object header at an address,
+// including the messages stored in continuation blocks.
+func (f *File) objectHeader(addr uint64) ([]message, error) {
+	prefix, err := f.readPrefix(addr, 34)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefix) >= 4 && string(prefix[:4]) == "OHDR" {
+		return f.objectHeaderV2(addr, prefix)
+	}
+	if len(prefix) > 0 && prefix[0] == 1 {
+		return f.objectHeaderV1(addr, prefix)
+	}
+	return nil, fmt.Errorf("no object header at address %d", addr)
+}
+
+// block is a range of the file storing messages of an object header.
+type block struct {
+	addr, size uint64
+}
+
+// continuation decodes a continuation message.
+func (f *File) continuation(msg message) (block, error) {
+	d := f.decoder(msg.data)
+	blk := block{addr: d.offset(), size: d.length()}
+	return blk, d.err
+}
+
+func (f *File) objectHeaderV1(addr uint64, prefix []byte) ([]message, error) {
+	d := f.decoder(prefix)
+	d.skip(2) // Version and reserved byte.
+	numMessages := int(d.uint16())
+	d.skip(4) // Reference count.
+	size := d.uint32()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid object header at address %d: %v", addr, d.err)
+	}
+	// Messages are aligned on 8 bytes: the 12 bytes of the prefix are followed by 4 bytes of padding.
+	var msgs []message
+	for blocks := []block{{addr: addr + 16, size: uint64(size)}}; len(blocks) > 0; blocks = blocks[1:] {
+		if len(blocks) > maxBlocks {
+			return nil, fmt.Errorf("object header at address %d has too many continuation blocks", addr)
+		}
+		buf, err := f.read(blocks[0].addr, blocks[0].size)
+		if err != nil {
+			return nil, err
+		}
+		d := f.decoder(buf)
+		for d.remaining() >= 8 && len(msgs) < numMessages {
+			msg := message{typ: d.uint16()}
+			size := int(d.uint16())
+			msg.flags = d.uint8()
+			d.skip(3)
+			msg.data = d.bytes(size)
+			if d.err != nil {
+				return nil, fmt.Errorf("invalid object header at address %d: %v", addr, d.err)
+			}
+			if msg.typ == msgContinuation {
+				blk, err := f.continuation(msg)
+				if err != nil {
+					return nil, fmt.Errorf("invalid continuation message in object header at address %d: %v", addr, err)
+				}
+				blocks = append(blocks, blk)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+func (f *File) objectHeaderV2(addr uint64, prefix []byte) ([]message, error) {
+	d := f.decoder(prefix)
+	d.skip(4)
+	if version := d.uint8(); version != 2 && d.err == nil {
+		return nil, fmt.Errorf("object header version %d at address %d not supported", version, addr)
+	}
+	flags := d.uint8()
+	if flags&0x20 != 0 {
+		d.skip(16) // Access, modification, change and birth times.
+	}
+	if flags&0x10 != 0 {
+		d.skip(4) // Maximum number of compact and minimum number of dense attributes.
+	}
+	chunkSize := d.uintN(1 << (flags & 0x03))
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid object header at address %d: %v", addr, d.err)
+	}
+	start := d.pos
+	buf, err := f.read(addr, uint64(start)+chunkSize+4)
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(buf); err != nil {
+		return nil, fmt.Errorf("object header at address %d: %v", addr, err)
+	}
+	// Messages store their creation order if attribute creation order is tracked.
+	headerSize := 4
+	if flags&0x04 != 0 {
+		headerSize = 6
+	}
+	var msgs []message
+	chunk := buf[start : len(buf)-4]
+	for n, blocks := 0, []block{}; ; n, blocks = n+1, blocks[1:] {
+		d := f.decoder(chunk)
+		for d.remaining() >= headerSize {
+			msg := message{typ: uint16(d.uint8())}
+			size := int(d.uint16())
+			msg.flags = d.uint8()
+			d.skip(headerSize - 4)
+			msg.data = d.bytes(size)
+			if d.err != nil {
+				return nil, fmt.Errorf("invalid object header at address %d: %v", addr, d.err)
+			}
+			if msg.typ == msgContinuation {
+				blk, err := f.continuation(msg)
+				if err != nil {
+					return nil, fmt.Errorf("invalid continuation message in object header at address %d: %v", addr, err)
+				}
+				blocks = append(blocks, blk)
+			}
+			msgs = append(msgs, msg)
+		}
+		if len(blocks) == 0 {
+			return msgs, nil
+		}
+		if n+len(blocks) > maxBlocks {
+			return nil, fmt.Errorf("object header at address %d has too many continuation blocks", addr)
+		}
+		buf, err := f.read(blocks[0].addr, blocks[0].size)
+		if err != nil {
+			return nil, err
+		}
+		d = f.decoder(buf)
+		if d.signature("OCHK"); d.err == nil && len(buf) < 8 {
+			d.fail("block of %d bytes too small", len(buf))
+		}
+		if d.err == nil {
+			d.err = verify(buf)
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("invalid continuation block at address %d: %v", blocks[0].addr, d.err)
+		}
+		chunk = buf[4 : len(buf)-4]
+	}
+}
+
+// find returns the first message of a given type.
+func find(msgs []message, typ uint16) (message, bool) {
+	for _, msg := range msgs {
+		if msg.typ == typ {
+			return msg, true
+		}
+	}
+	return message{}, false
+}
+
+// btreeV1 calls visit for each child of the leaves of a version 1 B-tree with the key preceding it.
+func (f *File) btreeV1(addr uint64, nodeType uint8, keySize int, visit func(key []byte, child uint64) error) error {
+	return f.btreeNodeV1(addr, nodeType, keySize, make(map[uint64]bool), 0, visit)
+}
+
+func (f *File) btreeNodeV1(addr uint64, nodeType uint8, keySize int, visited map[uint64]bool, depth int, visit func(key []byte, child uint64) error) error {
+	if depth > maxTreeDepth {
+		return fmt.Errorf("B-tree at address %d too deep", addr)
+	}
+	if visited[addr] {
+		return fmt.Errorf("B-tree node at address %d referenced more than once", addr)
+	}
+	visited[addr] = true
+	headerSize := 8 + 2*f.offsetSize
+	header, err := f.read(addr, uint64(headerSize))
+	if err != nil {
+		return err
+	}
+	d := f.decoder(header)
+	d.signature("TREE")
+	typ := d.uint8()
+	level := d.uint8()
+	entries := int(d.uint16())
+	if d.err != nil {
+		return fmt.Errorf("invalid B-tree node at address %d: %v", addr, d.err)
+	}
+	if typ != nodeType {
+		return fmt.Errorf("B-tree node at address %d has type %d but want %d", addr, typ, nodeType)
+	}
+	body, err := f.read(addr+uint64(headerSize), uint64((entries+1)*keySize+entries*f.offsetSize))
+	if err != nil {
+		return err
+	}
+	d = f.decoder(body)
+	for range entries {
+		key := d.bytes(keySize)
+		child := d.offset()
+		if d.err != nil {
+			return fmt.Errorf("invalid B-tree node at address %d: %v", addr, d.err)
+		}
+		if level > 0 {
+			err = f.btreeNodeV1(child, nodeType, keySize, visited, depth+1, visit)
+		} else {
+			err = visit(key, child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hdf5 reads datasets from HDF5 files into host buffers.
+//
+// The reader is written in Go and does not depend on the HDF5 C library. It supports
+// superblock versions 0 to 3, groups stored in symbol tables or in compact link messages,
+// and datasets of integers, floating-point numbers, booleans (stored as enums by h5py)
+// and complex numbers (stored as compounds by h5py). Datasets can use the compact,
+// contiguous or chunked layouts. Chunks can be indexed by a version 1 B-tree, by a fixed
+// array, or be stored as a single chunk or without index, and compressed with the deflate,
+// shuffle and Fletcher-32 filters. Checksums of the Fletcher-32 filter are not verified.
+//
+// Datasets are read into host buffers in chunks of rows, such that large datasets are
+// streamed without decoding the whole file or keeping an intermediate copy.
+package hdf5
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// File is an HDF5 file of which the superblock has been read.
+// Objects are read on demand.
+type File struct {
+	// Version of the superblock.
+	Version int
+
+	r          io.ReaderAt
+	base       uint64
+	offsetSize int
+	lengthSize int
+	root       uint64
+}
+
+// Open reads the superblock of an HDF5 file.
+// The superblock is searched at the start of the file and, for files with a user block,
+// at every power of two from 512 bytes.
+func Open(r io.ReaderAt) (*File, error) {
+	for pos := uint64(0); ; pos = max(512, 2*pos) {
+		sig := make([]byte, len(signature))
+		n, _ := r.ReadAt(sig, int64(pos))
+		if n < len(sig) {
+			return nil, fmt.Errorf("invalid HDF5 file: no superblock")
+		}
+		if string(sig) == signature {
+			return open(r, pos)
+		}
+	}
+}
+
+// open reads the superblock at a position of the file. Addresses are relative to the
+// superblock, which the HDF5 library uses as the base address whatever the file stores.
+func open(r io.ReaderAt, pos uint64) (*File, error) {
+	f := &File{r: r, offsetSize: 8, lengthSize: 8}
+	buf, err := f.readPrefix(pos, 256)
+	if err != nil {
+		return nil, err
+	}
+	f.base = pos
+	d := f.decoder(buf)
+	d.skip(len(signature))
+	f.Version = int(d.uint8())
+	switch f.Version {
+	case 0, 1:
+		d.skip(4) // Versions of the free-space storage, root group symbol table and shared header formats.
+		f.offsetSize = int(d.uint8())
+		f.lengthSize = int(d.uint8())
+		d.skip(1)
+		d.skip(8) // Group leaf and internal node K and file consistency flags.
+		if f.Version == 1 {
+			d.skip(4) // Indexed storage internal node K.
+		}
+		d.offsetSize, d.lengthSize = f.offsetSize, f.lengthSize
+		d.skip(4 * f.offsetSize) // Base, free-space, end of file and driver information addresses.
+		// The root group symbol table entry starts with the offset of its name.
+		d.skip(f.offsetSize)
+		f.root = d.offset()
+	case 2, 3:
+		f.offsetSize = int(d.uint8())
+		f.lengthSize = int(d.uint8())
+		d.skip(1) // File consistency flags.
+		d.offsetSize, d.lengthSize = f.offsetSize, f.lengthSize
+		d.skip(3 * f.offsetSize) // Base, superblock extension and end of file addresses.
+		f.root = d.offset()
+		if d.err == nil {
+			d.err = verify(buf[:d.pos+4])
+		}
+	default:
+		return nil, fmt.Errorf("HDF5 superblock version %d not supported", f.Version)
+	}
+	if d.err == nil && (!validSize(f.offsetSize) || !validSize(f.lengthSize)) {
+		d.fail("offsets of %d bytes and lengths of %d bytes not supported", f.offsetSize, f.lengthSize)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid HDF5 superblock: %v", d.err)
+	}
+	return f, nil
+}
+
+func validSize(n int) bool {
+	return n == 2 || n == 4 || n == 8
+}
+
+// link is an entry of a group.
+type link struct {
+	name string
+	// addr is the address of the object header of hard links.
+	addr uint64
+	// soft is the path targeted by soft links.
+	soft string
+}
+
+// isGroup returns true if the messages of an object header describe a group.
+func isGroup(msgs []message) bool {
+	return slices.ContainsFunc(msgs, func(msg message) bool {
+		return msg.typ == msgSymbolTable || msg.typ == msgLinkInfo || msg.typ == msgLink
+	})
+}
+
+// isDataset returns true if the messages of an object header describe a dataset.
+func isDataset(msgs []message) bool {
+	_, ok := find(msgs, msgLayout)
+	return ok
+}
+
+// links returns the entries of the group described by the messages of an object header.
+func (f *File) links(msgs []message) ([]link, error) {
+	if msg, ok := find(msgs, msgSymbolTable); ok {
+		d := f.decoder(msg.data)
+		btree, heap := d.offset(), d.offset()
+		if d.err != nil {
+			return nil, fmt.Errorf("invalid symbol table message: %v", d.err)
+		}
+		return f.symbolTable(btree, heap)
+	}
+	if msg, ok := find(msgs, msgLinkInfo); ok {
+		d := f.decoder(msg.data)
+		d.skip(1) // Version.
+		if flags := d.uint8(); flags&0x01 != 0 {
+			d.skip(8) // Maximum creation index.
+		}
+		if heap := d.offset(); heap != undefined && d.err == nil {
+			return nil, fmt.Errorf("groups with dense link storage not supported")
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("invalid link info message: %v", d.err)
+		}
+	}
+	var links []link
+	for _, msg := range msgs {
+		if msg.typ != msgLink {
+			continue
+		}
+		l, err := f.link(msg.data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link message: %v", err)
+		}
+		if l != nil {
+			links = append(links, *l)
+		}
+	}
+	return links, nil
+}
+
+// link decodes a link message. It returns nil for links to other files.
+func (f *File) link(data []byte) (*link, error) {
+	d := f.decoder(data)
+	if version := d.uint8(); version != 1 && d.err == nil {
+		return nil, fmt.Errorf("link message version %d not supported", version)
+	}
+	flags := d.uint8()
+	var typ uint8
+	if flags&0x08 != 0 {
+		typ = d.uint8()
+	}
+	if flags&0x04 != 0 {
+		d.skip(8) // Creation order.
+	}
+	if flags&0x10 != 0 {
+		d.skip(1) // Character set of the name.
+	}
+	l := &link{name: string(d.bytes(int(d.uintN(1 << (flags & 0x03)))))}
+	switch typ {
+	case 0:
+		l.addr = d.offset()
+	case 1:
+		l.soft = string(d.bytes(int(d.uint16())))
+	default:
+		l = nil
+	}
+	return l, d.err
+}
+
+// symbolTable returns the entries of a group stored in a symbol table.
+func (f *File) symbolTable(btree, heapAddr uint64) ([]link, error) {
+	heap, err := f.localHeap(heapAddr)
+	if err != nil {
+		return nil, err
+	}
+	var links []link
+	err = f.btreeV1(btree, 0, f.lengthSize, func(_ []byte, child uint64) error {
+		return f.symbolNode(child, heap, &links)
+	})
+	return links, err
+}
+
+// localHeap returns the data segment of a local heap storing the names of a group.
+func (f *File) localHeap(addr uint64) ([]byte, error) {
+	header, err := f.read(addr, uint64(8+2*f.lengthSize+f.offsetSize))
+	if err != nil {
+		return nil, err
+	}
+	d := f.decoder(header)
+	d.signature("HEAP")
+	d.skip(4) // Version and reserved bytes.
+	size := d.length()
+	d.skip(f.lengthSize) // Offset to the head of the free list.
+	data := d.offset()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid local heap at address %d: %v", addr, d.err)
+	}
+	return f.read(data, size)
+}
+
+// heapString returns the null-terminated string at an offset of a local heap.
+func heapString(heap []byte, offset uint64) (string, error) {
+	if offset >= uint64(len(heap)) {
+		return "", fmt.Errorf("offset %d out of the local heap of %d bytes", offset, len(heap))
+	}
+	s := heap[offset:]
+	end := slices.Index(s, 0)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string at offset %d of the local heap", offset)
+	}
+	return string(s[:end]), nil
+}
+
+// symbolNode appends the entries of a symbol table node to links.
+func (f *File) symbolNode(addr uint64, heap []byte, links *[]link) error {
+	header, err := f.read(addr, 8)
+	if err != nil {
+		return err
+	}
+	d := f.decoder(header)
+	d.signature("SNOD")
+	d.skip(2) // Version and reserved byte.
+	n := int(d.uint16())
+	if d.err != nil {
+		return fmt.Errorf("invalid symbol table node at address %d: %v", addr, d.err)
+	}
+	entrySize := 2*f.offsetSize + 24
+	body, err := f.read(addr+8, uint64(n*entrySize))
+	if err != nil {
+		return err
+	}
+	d = f.decoder(body)
+	for range n {
+		nameOffset := d.offset()
+		l := link{addr: d.offset()}
+		cacheType := d.uint32()
+		d.skip(4)
+		scratch := d.bytes(16)
+		if d.err != nil {
+			return fmt.Errorf("invalid symbol table node at address %d: %v", addr, d.err)
+		}
+		if l.name, err = heapString(heap, nameOffset); err != nil {
+			return err
+		}
+		// The scratch pad of soft links stores the offset of their target in the local heap.
+		if cacheType == 2 {
+			target := uint64(scratch[0]) | uint64(scratch[1])<<8 | uint64(scratch[2])<<16 | uint64(scratch[3])<<24
+			if l.soft, err = heapString(heap, target); err != nil {
+				return err
+			}
+		}
+		*links = append(*links, l)
+	}
+	return nil
+}
+
+// resolve returns the address of the object header of the object at a path.
+func (f *File) resolve(path string, depth int) (uint64, error) {
+	if depth > maxLinkDepth {
+		return 0, fmt.Errorf("too many levels of soft links")
+	}
+	addr, group := f.root, ""
+	for _, name := range strings.Split(path, "/") {
+		if name == "" || name == "." {
+			continue
+		}
+		msgs, err := f.objectHeader(addr)
+		if err != nil {
+			return 0, err
+		}
+		if !isGroup(msgs) {
+			return 0, fmt.Errorf("%s is not a group", group)
+		}
+		links, err := f.links(msgs)
+		if err != nil {
+			return 0, fmt.Errorf("group %s: %v", group+"/", err)
+		}
+		i := slices.IndexFunc(links, func(l link) bool { return l.name == name })
+		if i < 0 {
+			return 0, fmt.Errorf("%s/%s not found", group, name)
+		}
+		switch l := links[i]; {
+		case l.soft == "":
+			addr = l.addr
+		case strings.HasPrefix(l.soft, "/"):
+			addr, err = f.resolve(l.soft, depth+1)
+		default:
+			addr, err = f.resolve(group+"/"+l.soft, depth+1)
+		}
+		if err != nil {
+			return 0, err
+		}
+		group += "/" + name
+	}
+	return addr, nil
+}
+
+// Dataset returns a dataset given its path in the file, for example "/group/data".
+// Soft links are followed.
+func (f *File) Dataset(path string) (*Dataset, error) {
+	addr, err := f.resolve(path, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find dataset %s: %v", path, err)
+	}
+	msgs, err := f.objectHeader(addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read dataset %s: %v", path, err)
+	}
+	if !isDataset(msgs) {
+		return nil, fmt.Errorf("%s is not a dataset", path)
+	}
+	ds, err := f.dataset(path, msgs)
+	if err != nil {
+		return nil, fmt.Errorf("dataset %s: %v", path, err)
+	}
+	return ds, nil
+}
+
+// Datasets returns the paths of all the datasets of the file, sorted in depth-first order.
+// Soft links are not followed.
+func (f *File) Datasets() ([]string, error) {
+	var paths []string
+	visited := make(map[uint64]bool)
+	var walk func(path string, addr uint64) error
+	walk = func(path string, addr uint64) error {
+		if visited[addr] {
+			return nil
+		}
+		visited[addr] = true
+		msgs, err := f.objectHeader(addr)
+		if err != nil {
+			return err
+		}
+		if isDataset(msgs) {
+			paths = append(paths, path)
+			return nil
+		}
+		if !isGroup(msgs) {
+			return nil
+		}
+		links, err := f.links(msgs)
+		if err != nil {
+			return fmt.Errorf("group %s: %v", path+"/", err)
+		}
+		slices.SortFunc(links, func(a, b link) int { return strings.Compare(a.name, b.name) })
+		for _, l := range links {
+			if l.soft != "" {
+				continue
+			}
+			if err := walk(path+"/"+l.name, l.addr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk("", f.root); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
@@ -0,0 +1,705 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hdf5
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+// le encodes values in little-endian order.
+func le(vals ...any) []byte {
+	var buf bytes.Buffer
+	for _, v := range vals {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	return buf.Bytes()
+}
+
+func cat(parts ...[]byte) []byte {
+	return slices.Concat(parts...)
+}
+
+// writer encodes an HDF5 file with offsets and lengths of 8 bytes.
+type writer struct {
+	bytes.Buffer
+}
+
+// block writes data aligned on 8 bytes and returns its address.
+func (w *writer) block(data ...[]byte) uint64 {
+	w.Write(make([]byte, (8-w.Len()%8)%8))
+	addr := uint64(w.Len())
+	for _, b := range data {
+		w.Write(b)
+	}
+	return addr
+}
+
+func withChecksum(data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(data, checksum(data))
+}
+
+// superblockV0 writes a version 0 superblock given the address of the root group.
+func (w *writer) superblockV0(root uint64) {
+	copy(w.Bytes(), cat(
+		[]byte(signature),
+		le(uint8(0), uint8(0), uint8(0), uint8(0), uint8(0), uint8(8), uint8(8), uint8(0)),
+		le(uint16(4), uint16(16), uint32(0)),
+		le(uint64(0), uint64(undefined), uint64(w.Len()), uint64(undefined)),
+		le(uint64(0), root, uint32(0), uint32(0), [16]byte{}),
+	))
+}
+
+// superblockV2 writes a version 2 superblock given the address of the root group.
+func (w *writer) superblockV2(root uint64) {
+	copy(w.Bytes(), withChecksum(cat(
+		[]byte(signature),
+		le(uint8(2), uint8(8), uint8(8), uint8(0)),
+		le(uint64(0), uint64(undefined), uint64(w.Len()), root),
+	)))
+}
+
+type msg struct {
+	typ  uint16
+	data []byte
+}
+
+func messagesV1(msgs []msg) []byte {
+	var buf []byte
+	for _, m := range msgs {
+		data := cat(m.data, make([]byte, (8-len(m.data)%8)%8))
+		buf = cat(buf, le(m.typ, uint16(len(data)), uint8(0), [3]byte{}), data)
+	}
+	return buf
+}
+
+// headerV1 writes a version 1 object header. The messages of rest are stored in a continuation block.
+func (w *writer) headerV1(msgs []msg, rest ...msg) uint64 {
+	n := len(msgs)
+	if len(rest) > 0 {
+		cont := messagesV1(rest)
+		addr := w.block(cont)
+		msgs = append(slices.Clone(msgs), msg{msgContinuation, le(addr, uint64(len(cont)))})
+		n += len(rest) + 1
+	}
+	body := messagesV1(msgs)
+	return w.block(le(uint8(1), uint8(0), uint16(n), uint32(1), uint32(len(body)), uint32(0)), body)
+}
+
+func messagesV2(msgs []msg) []byte {
+	var buf []byte
+	for _, m := range msgs {
+		buf = cat(buf, le(uint8(m.typ), uint16(len(m.data)), uint8(0)), m.data)
+	}
+	return buf
+}
+
+// headerV2 writes a version 2 object header. The messages of rest are stored in a continuation block.
+func (w *writer) headerV2(msgs []msg, rest ...msg) uint64 {
+	if len(rest) > 0 {
+		cont := withChecksum(cat([]byte("OCHK"), messagesV2(rest)))
+		addr := w.block(cont)
+		msgs = append(slices.Clone(msgs), msg{msgContinuation, le(addr, uint64(len(cont)))})
+	}
+	body := messagesV2(msgs)
+	// The size of the first chunk is stored in 4 bytes.
+	return w.block(withChecksum(cat([]byte("OHDR"), le(uint8(2), uint8(0x02), uint32(len(body))), body)))
+}
+
+// entry is an entry of a group. Soft links have a target.
+type entry struct {
+	name   string
+	addr   uint64
+	target string
+}
+
+// symbolTable writes the local heap, B-tree and symbol table node of a group
+// and returns its symbol table message.
+func (w *writer) symbolTable(entries ...entry) msg {
+	heap := make([]byte, 8)
+	str := func(s string) uint64 {
+		offset := len(heap)
+		heap = cat(heap, []byte(s), make([]byte, 8-len(s)%8))
+		return uint64(offset)
+	}
+	var node []byte
+	var last uint64
+	for _, e := range entries {
+		last = str(e.name)
+		cacheType, scratch := uint32(0), make([]byte, 16)
+		if e.target != "" {
+			cacheType = 2
+			binary.LittleEndian.PutUint32(scratch, uint32(str(e.target)))
+		}
+		node = cat(node, le(last, e.addr, cacheType, uint32(0)), scratch)
+	}
+	data := w.block(heap)
+	heapAddr := w.block([]byte("HEAP"), le(uint8(0), [3]byte{}, uint64(len(heap)), uint64(undefined), data))
+	nodeAddr := w.block([]byte("SNOD"), le(uint8(1), uint8(0), uint16(len(entries))), node)
+	btree := w.block([]byte("TREE"), le(uint8(0), uint8(0), uint16(1), uint64(undefined), uint64(undefined)), le(uint64(0), nodeAddr, last))
+	return msg{msgSymbolTable, le(btree, heapAddr)}
+}
+
+func hardLink(name string, addr uint64) msg {
+	return msg{msgLink, cat(le(uint8(1), uint8(0), uint8(len(name))), []byte(name), le(addr))}
+}
+
+func softLink(name, target string) msg {
+	return msg{msgLink, cat(le(uint8(1), uint8(0x08), uint8(1), uint8(len(name))), []byte(name), le(uint16(len(target))), []byte(target))}
+}
+
+var linkInfo = msg{msgLinkInfo, le(uint8(0), uint8(0), uint64(undefined), uint64(undefined))}
+
+func dataspace(dims ...int) msg {
+	data := le(uint8(1), uint8(len(dims)), uint8(0), [5]byte{})
+	for _, dim := range dims {
+		data = cat(data, le(uint64(dim)))
+	}
+	return msg{msgDataspace, data}
+}
+
+func dataspaceV2(dims, maxDims []int) msg {
+	data := le(uint8(2), uint8(len(dims)), uint8(1), uint8(1))
+	for _, dim := range slices.Concat(dims, maxDims) {
+		data = cat(data, le(uint64(dim)))
+	}
+	return msg{msgDataspace, data}
+}
+
+var (
+	float32Type = le(uint8(0x11), [3]byte{0x20, 0x1f}, uint32(4), uint16(0), uint16(32), uint8(23), uint8(8), uint8(0), uint8(23), uint32(127))
+	float64Type = le(uint8(0x11), [3]byte{0x20, 0x3f}, uint32(8), uint16(0), uint16(64), uint8(52), uint8(11), uint8(0), uint8(52), uint32(1023))
+	int8Type    = le(uint8(0x10), [3]byte{0x08}, uint32(1), uint16(0), uint16(8))
+	int16BEType = le(uint8(0x10), [3]byte{0x09}, uint32(2), uint16(0), uint16(16))
+	int32Type   = le(uint8(0x10), [3]byte{0x08}, uint32(4), uint16(0), uint16(32))
+	uint8Type   = le(uint8(0x10), [3]byte{}, uint32(1), uint16(0), uint16(8))
+	// boolType is the enum written by h5py for booleans.
+	boolType = cat(le(uint8(0x38), [3]byte{2}, uint32(1)), int8Type, []byte("FALSE\x00TRUE\x00"), []byte{0, 1})
+	// complexType is the compound written by h5py for complex numbers.
+	complexType = cat(le(uint8(0x36), [3]byte{2}, uint32(8)), []byte("r\x00"), []byte{0}, float32Type, []byte("i\x00"), []byte{4}, float32Type)
+)
+
+func datatypeMsg(data []byte) msg {
+	return msg{msgDatatype, data}
+}
+
+func fillMsg(v float32) msg {
+	return msg{msgFillValue, le(uint8(2), uint8(2), uint8(0), uint8(1), uint32(4), v)}
+}
+
+func contiguousLayout(addr uint64, size int) msg {
+	return msg{msgLayout, le(uint8(3), uint8(layoutContiguous), addr, uint64(size))}
+}
+
+func compactLayout(data []byte) msg {
+	return msg{msgLayout, cat(le(uint8(3), uint8(layoutCompact), uint16(len(data))), data)}
+}
+
+// filtersMsg returns a filter pipeline message with the shuffle, deflate and Fletcher-32 filters.
+func filtersMsg(elemSize int) msg {
+	return msg{msgFilters, le(
+		uint8(2), uint8(3),
+		uint16(filterShuffle), uint16(0), uint16(1), uint32(elemSize),
+		uint16(filterDeflate), uint16(0), uint16(1), uint32(6),
+		uint16(filterFletcher32), uint16(0), uint16(0),
+	)}
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// encodeChunk applies the filters of the filtersMsg message to a chunk.
+func encodeChunk(data []byte, size int) []byte {
+	n := len(data) / size
+	shuffled := make([]byte, len(data))
+	for i := range n {
+		for j := range size {
+			shuffled[j*n+i] = data[i*size+j]
+		}
+	}
+	// The Fletcher-32 checksum is not verified by the reader.
+	return cat(deflate(shuffled), []byte{0xde, 0xad, 0xbe, 0xef})
+}
+
+// chunkTree writes chunks indexed by a version 1 B-tree given their offsets.
+func (w *writer) chunkTree(offsets [][]uint64, chunks [][]byte) uint64 {
+	var body []byte
+	for i, chunk := range chunks {
+		addr := w.block(chunk)
+		body = cat(body, le(uint32(len(chunk)), uint32(0), offsets[i], uint64(0), addr))
+	}
+	body = cat(body, le(uint32(0), uint32(0), make([]uint64, len(offsets[0])+1)))
+	return w.block([]byte("TREE"), le(uint8(1), uint8(0), uint16(len(chunks)), uint64(undefined), uint64(undefined)), body)
+}
+
+// writeV0 returns a file with a version 0 superblock, version 1 object headers
+// and groups stored in symbol tables.
+func writeV0() []byte {
+	w := &writer{}
+	w.Write(make([]byte, 96))
+	data := w.headerV1([]msg{
+		dataspace(3, 4),
+		datatypeMsg(float32Type),
+		contiguousLayout(w.block(le([]float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})), 48),
+	})
+	// A dataset of 5x3 elements in chunks of 2x2 elements, with the chunk (1, 1) never written.
+	var offsets [][]uint64
+	var chunks [][]byte
+	for ci := range 3 {
+		for cj := range 2 {
+			if ci == 1 && cj == 1 {
+				continue
+			}
+			chunk := make([]float32, 4)
+			for a := range 2 {
+				for b := range 2 {
+					if i, j := 2*ci+a, 2*cj+b; i < 5 && j < 3 {
+						chunk[2*a+b] = float32(3*i + j)
+					}
+				}
+			}
+			offsets = append(offsets, []uint64{uint64(2 * ci), uint64(2 * cj)})
+			chunks = append(chunks, encodeChunk(le(chunk), 4))
+		}
+	}
+	chunked := w.headerV1([]msg{
+		dataspace(5, 3),
+		datatypeMsg(float32Type),
+		fillMsg(-1),
+		filtersMsg(4),
+		{msgLayout, le(uint8(3), uint8(layoutChunked), uint8(3), w.chunkTree(offsets, chunks), uint32(2), uint32(2), uint32(4))},
+	})
+	be := w.headerV1([]msg{
+		dataspace(4),
+		datatypeMsg(int16BEType),
+		compactLayout([]byte{0xff, 0xfe, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01}),
+	})
+	scalar := w.headerV1([]msg{
+		dataspace(),
+		datatypeMsg(float64Type),
+		contiguousLayout(w.block(le(2.5)), 8),
+	})
+	flags := w.headerV1([]msg{
+		dataspace(3),
+		datatypeMsg(boolType),
+		contiguousLayout(w.block([]byte{1, 0, 1}), 3),
+	})
+	complex := w.headerV1([]msg{
+		dataspace(2),
+		datatypeMsg(complexType),
+		contiguousLayout(w.block(le([]float32{1, 2, 3, -4})), 16),
+	})
+	unwritten := w.headerV1([]msg{
+		dataspace(2),
+		datatypeMsg(float32Type),
+		fillMsg(7),
+		contiguousLayout(undefined, 8),
+	})
+	group := w.headerV1([]msg{w.symbolTable(
+		entry{name: "be", addr: be},
+		entry{name: "chunked", addr: chunked},
+		entry{name: "complex", addr: complex},
+		entry{name: "flags", addr: flags},
+		entry{name: "scalar", addr: scalar},
+		entry{name: "unwritten", addr: unwritten},
+	)})
+	// The symbol table of the root group is stored in a continuation block.
+	root := w.headerV1(nil, w.symbolTable(
+		entry{name: "data", addr: data},
+		entry{name: "g", addr: group},
+		entry{name: "link", addr: undefined, target: "/g/chunked"},
+	))
+	w.superblockV0(root)
+	return w.Bytes()
+}
+
+// writeV2 returns a file with a version 2 superblock, version 2 object headers,
+// groups stored in link messages, and datasets of which the chunks are indexed by
+// version 4 layout messages.
+func writeV2() []byte {
+	w := &writer{}
+	w.Write(make([]byte, 48))
+	// A single chunk compressed with deflate.
+	chunk := deflate(le([]int32{0, 1, 2, 3, 4, 5}))
+	single := w.headerV2([]msg{
+		dataspaceV2([]int{2, 3}, []int{2, 3}),
+		datatypeMsg(int32Type),
+		{msgFilters, le(uint8(2), uint8(1), uint16(filterDeflate), uint16(0), uint16(1), uint32(6))},
+		{msgLayout, le(uint8(4), uint8(layoutChunked), uint8(0x02), uint8(3), uint8(4), uint32(2), uint32(3), uint32(4),
+			uint8(indexSingle), uint64(len(chunk)), uint32(0), w.block(chunk))},
+	})
+	// A dataset of 3x3 elements with a maximum of 3x6 elements, in chunks of 2x2 elements
+	// stored without index for the grid of 2x3 chunks of the maximum dimensions.
+	implicit := make([]byte, 6*4)
+	for i := range 3 {
+		for j := range 3 {
+			ci, cj := i/2, j/2
+			implicit[(3*ci+cj)*4+2*(i%2)+j%2] = byte(3*i + j)
+		}
+	}
+	implicitDS := w.headerV2([]msg{
+		dataspaceV2([]int{3, 3}, []int{3, 6}),
+		datatypeMsg(uint8Type),
+		{msgLayout, le(uint8(4), uint8(layoutChunked), uint8(0), uint8(3), uint8(1), []uint8{2, 2, 1},
+			uint8(indexImplicit), w.block(implicit))},
+	})
+	// A dataset of 7 elements in chunks of 1 element, indexed by a fixed array with pages
+	// of 2 elements. The third page has never been written.
+	var elems [7]uint64
+	for i := range elems {
+		elems[i] = w.block(le(float32(i) + 0.5))
+	}
+	header := w.block(make([]byte, 28))
+	block := w.block(
+		withChecksum(cat([]byte("FADB"), le(uint8(0), uint8(0), header), []byte{0xd0})),
+		withChecksum(le(elems[0], elems[1])),
+		withChecksum(le(elems[2], elems[3])),
+		make([]byte, 20),
+		withChecksum(le(elems[6])),
+	)
+	copy(w.Bytes()[header:], withChecksum(cat([]byte("FAHD"), le(uint8(0), uint8(0), uint8(8), uint8(1), uint64(7), block))))
+	farray := w.headerV2([]msg{
+		dataspaceV2([]int{7}, []int{7}),
+		datatypeMsg(float32Type),
+		{msgLayout, le(uint8(4), uint8(layoutChunked), uint8(0), uint8(2), uint8(4), uint32(1), uint32(4),
+			uint8(indexFixedArray), uint8(1), header)},
+	})
+	// The links of the root group are stored in a continuation block.
+	root := w.headerV2([]msg{linkInfo},
+		hardLink("single", single),
+		hardLink("implicit", implicitDS),
+		hardLink("farray", farray),
+		softLink("soft", "single"),
+	)
+	w.superblockV2(root)
+	return w.Bytes()
+}
+
+func float32s(vals ...float32) []byte {
+	return le(vals)
+}
+
+func TestRead(t *testing.T) {
+	bigEndian := le([]int16{-2, -1, 0, 1})
+	tests := []struct {
+		file  []byte
+		path  string
+		shape string
+		want  []byte
+	}{
+		{file: writeV0(), path: "/data", shape: "[3][4]float32", want: float32s(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)},
+		{file: writeV0(), path: "g/chunked", shape: "[5][3]float32", want: float32s(0, 1, 2, 3, 4, 5, 6, 7, -1, 9, 10, -1, 12, 13, 14)},
+		{file: writeV0(), path: "/link", shape: "[5][3]float32", want: float32s(0, 1, 2, 3, 4, 5, 6, 7, -1, 9, 10, -1, 12, 13, 14)},
+		{file: writeV0(), path: "/g/be", shape: "[4]int16", want: bigEndian},
+		{file: writeV0(), path: "/g/scalar", shape: "float64", want: le(2.5)},
+		{file: writeV0(), path: "/g/flags", shape: "[3]bool", want: []byte{1, 0, 1}},
+		{file: writeV0(), path: "/g/complex", shape: "[2]complex64", want: float32s(1, 2, 3, -4)},
+		{file: writeV0(), path: "/g/unwritten", shape: "[2]float32", want: float32s(7, 7)},
+		{file: writeV2(), path: "/single", shape: "[2][3]int32", want: le([]int32{0, 1, 2, 3, 4, 5})},
+		{file: writeV2(), path: "/soft", shape: "[2][3]int32", want: le([]int32{0, 1, 2, 3, 4, 5})},
+		{file: writeV2(), path: "/implicit", shape: "[3][3]uint8", want: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{file: writeV2(), path: "/farray", shape: "[7]float32", want: float32s(0.5, 1.5, 2.5, 3.5, 0, 0, 6.5)},
+		// Files can start with a user block of 512 bytes.
+		{file: cat(make([]byte, 512), writeV0()), path: "/data", shape: "[3][4]float32", want: float32s(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)},
+	}
+	for _, test := range tests {
+		f, err := Open(bytes.NewReader(test.file))
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		ds, err := f.Dataset(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		for _, chunkBytes := range []int{1, 8, DefaultChunkBytes} {
+			plat := platformtest.New(1)
+			buf, err := ds.Read(plat, WithChunkBytes(chunkBytes))
+			if err != nil {
+				t.Errorf("%s: %v", test.path, err)
+				continue
+			}
+			if got := buf.Shape().String(); got != test.shape {
+				t.Errorf("%s: got shape %s but want %s", test.path, got, test.shape)
+			}
+			if got := buf.AcquireRead(); !bytes.Equal(got, test.want) {
+				t.Errorf("%s: read in chunks of %d bytes: got %v but want %v", test.path, chunkBytes, got, test.want)
+			}
+			buf.ReleaseRead()
+			buf.Free()
+			if live := plat.Counts().Live; live != 0 {
+				t.Errorf("%s: %d buffers alive after free", test.path, live)
+			}
+		}
+	}
+}
+
+func TestReadRows(t *testing.T) {
+	f, err := Open(bytes.NewReader(writeV0()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := f.Dataset("/g/chunked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ds.Name(), "/g/chunked"; got != want {
+		t.Errorf("got name %s but want %s", got, want)
+	}
+	if got, want := ds.Dims(), []int{5, 3}; !slices.Equal(got, want) {
+		t.Errorf("got dims %v but want %v", got, want)
+	}
+	if got, want := ds.DType(), dtype.Float32; got != want {
+		t.Errorf("got data type %s but want %s", got, want)
+	}
+	if got, want := ds.store.rowAlignment(), 2; got != want {
+		t.Errorf("got row alignment %d but want %d", got, want)
+	}
+	dst := make([]byte, 3*3*4)
+	if err := ds.ReadRows(dst, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if want := float32s(3, 4, 5, 6, 7, -1, 9, 10, -1); !bytes.Equal(dst, want) {
+		t.Errorf("got %v but want %v", dst, want)
+	}
+	for _, test := range []struct {
+		size, start, count int
+	}{
+		{size: 12, start: 4, count: 2},
+		{size: 12, start: -1, count: 1},
+		{size: 8, start: 0, count: 1},
+	} {
+		if err := ds.ReadRows(make([]byte, test.size), test.start, test.count); err == nil {
+			t.Errorf("reading %d rows from row %d into %d bytes: expected an error", test.count, test.start, test.size)
+		}
+	}
+}
+
+func TestDatasets(t *testing.T) {
+	tests := []struct {
+		file []byte
+		want []string
+	}{
+		{file: writeV0(), want: []string{"/data", "/g/be", "/g/chunked", "/g/complex", "/g/flags", "/g/scalar", "/g/unwritten"}},
+		{file: writeV2(), want: []string{"/farray", "/implicit", "/single"}},
+	}
+	for _, test := range tests {
+		f, err := Open(bytes.NewReader(test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := f.Datasets()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("got datasets %v but want %v", got, test.want)
+		}
+	}
+}
+
+func TestDatasetErrors(t *testing.T) {
+	f, err := Open(bytes.NewReader(writeV0()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{path: "/g", err: "not a dataset"},
+		{path: "/g/missing", err: "/g/missing not found"},
+		{path: "/data/x", err: "/data is not a group"},
+	}
+	for _, test := range tests {
+		_, err := f.Dataset(test.path)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v but want an error containing %q", test.path, err, test.err)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	file := writeV0()
+	f, err := Open(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := f.Dataset("/g/chunked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plat := platformtest.New(1)
+	plat.FailAllocationAt(1)
+	if _, err := ds.Read(plat); err == nil {
+		t.Errorf("expected an error when the allocation fails")
+	}
+	// Corrupt the compressed data of the first chunk.
+	chunk, _, err := ds.store.(*chunked).index.chunk(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := slices.Clone(file)
+	clear(corrupted[chunk.addr : chunk.addr+4])
+	f, err = Open(bytes.NewReader(corrupted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds, err = f.Dataset("/g/chunked"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Read(plat); err == nil || !strings.Contains(err.Error(), "rows [0, 5)") {
+		t.Errorf("got error %v but want an error reading rows [0, 5)", err)
+	}
+	if live := plat.Counts().Live; live != 0 {
+		t.Errorf("%d buffers alive after a failed read", live)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	corrupted := writeV2()
+	corrupted[len(signature)+4] ^= 0xff
+	unsupported := writeV0()
+	unsupported[len(signature)] = 4
+	tests := []struct {
+		name string
+		file []byte
+		err  string
+	}{
+		{name: "empty", file: nil, err: "no superblock"},
+		{name: "not HDF5", file: make([]byte, 2048), err: "no superblock"},
+		{name: "truncated", file: []byte(signature), err: "invalid HDF5 superblock"},
+		{name: "checksum", file: corrupted, err: "checksum mismatch"},
+		{name: "version", file: unsupported, err: "superblock version 4 not supported"},
+	}
+	for _, test := range tests {
+		_, err := Open(bytes.NewReader(test.file))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v but want an error containing %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	plat := platformtest.New(1)
+	for _, file := range [][]byte{writeV0(), writeV2()} {
+		for n := range len(file) {
+			// Truncated files are read without panicking.
+			f, err := Open(bytes.NewReader(file[:n]))
+			if err != nil {
+				continue
+			}
+			paths, _ := f.Datasets()
+			for _, path := range paths {
+				if ds, err := f.Dataset(path); err == nil {
+					if buf, err := ds.Read(plat); err == nil {
+						buf.Free()
+					}
+				}
+			}
+		}
+	}
+	if live := plat.Counts().Live; live != 0 {
+		t.Errorf("%d buffers alive after reading truncated files", live)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []msg
+		err  string
+	}{
+		{
+			name: "filter",
+			msgs: []msg{
+				dataspace(2), datatypeMsg(float32Type),
+				{msgFilters, le(uint8(2), uint8(1), uint16(4), uint16(0), uint16(0))},
+				contiguousLayout(undefined, 8),
+			},
+			err: "filter 4 not supported",
+		},
+		{
+			name: "datatype",
+			msgs: []msg{dataspace(2), datatypeMsg(le(uint8(0x13), [3]byte{}, uint32(4))), contiguousLayout(undefined, 8)},
+			err:  "datatype class 3 not supported",
+		},
+		{
+			name: "layout",
+			msgs: []msg{dataspace(2), datatypeMsg(float32Type), {msgLayout, le(uint8(3), uint8(3))}},
+			err:  "layout class 3 not supported",
+		},
+		{
+			name: "index",
+			msgs: []msg{
+				dataspace(2), datatypeMsg(float32Type),
+				{msgLayout, le(uint8(4), uint8(layoutChunked), uint8(0), uint8(2), uint8(1), []uint8{1, 4}, uint8(5))},
+			},
+			err: "chunk index type 5 not supported",
+		},
+	}
+	for _, test := range tests {
+		w := &writer{}
+		w.Write(make([]byte, 96))
+		ds := w.headerV1(test.msgs)
+		w.superblockV0(w.headerV1([]msg{w.symbolTable(entry{name: "ds", addr: ds})}))
+		f, err := Open(bytes.NewReader(w.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Dataset("ds"); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v but want an error containing %q", test.name, err, test.err)
+		}
+	}
+	// Groups of which the links are stored in a fractal heap.
+	w := &writer{}
+	w.Write(make([]byte, 48))
+	w.superblockV2(w.headerV2([]msg{{msgLinkInfo, le(uint8(0), uint8(0), uint64(64), uint64(undefined))}}))
+	f, err := Open(bytes.NewReader(w.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Datasets(); err == nil || !strings.Contains(err.Error(), "dense link storage not supported") {
+		t.Errorf("got error %v but want an error about dense link storage", err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint32
+	}{
+		{data: "", want: 0xdeadbeef},
+		{data: "Four score and seven years ago", want: 0x17770551},
+	}
+	for _, test := range tests {
+		if got := checksum([]byte(test.data)); got != test.want {
+			t.Errorf("checksum(%q) = %#08x but want %#08x", test.data, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,523 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hdf5
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// storage reads the rows of a dataset from where they are stored in the file.
+type storage interface {
+	// read reads count rows, starting at row start, into dst in the byte order of the file.
+	read(ds *Dataset, dst []byte, start, count int) error
+
+	// rowAlignment returns the number of rows of which reads should be a multiple
+	// such that the chunks of the file are decoded once.
+	rowAlignment() int
+}
+
+// compact storage stores the data in the object header of the dataset.
+type compact struct {
+	data []byte
+}
+
+func newCompact(raw []byte, size int) (storage, error) {
+	if len(raw) < size {
+		return nil, fmt.Errorf("compact data of %d bytes cannot store %d bytes", len(raw), size)
+	}
+	return compact{data: raw[:size]}, nil
+}
+
+func (s compact) read(ds *Dataset, dst []byte, start, count int) error {
+	copy(dst, s.data[start*ds.rowBytes():])
+	return nil
+}
+
+func (compact) rowAlignment() int {
+	return 1
+}
+
+// contiguous storage stores the data in a single block of the file.
+type contiguous struct {
+	addr uint64
+}
+
+func newContiguous(addr, n uint64, size int) (storage, error) {
+	if addr != undefined && n < uint64(size) {
+		return nil, fmt.Errorf("contiguous data of %d bytes cannot store %d bytes", n, size)
+	}
+	return contiguous{addr: addr}, nil
+}
+
+func (s contiguous) read(ds *Dataset, dst []byte, start, count int) error {
+	if s.addr == undefined {
+		// The data has never been written.
+		ds.fillRows(dst)
+		return nil
+	}
+	pos, err := ds.f.abs(s.addr + uint64(start*ds.rowBytes()))
+	if err != nil {
+		return err
+	}
+	return ds.f.readAt(dst, pos)
+}
+
+func (contiguous) rowAlignment() int {
+	return 1
+}
+
+// chunkRef references a chunk stored in the file.
+type chunkRef struct {
+	addr, size uint64
+	// mask has its bit i set if the filter i of the pipeline has not been applied to the chunk.
+	mask uint32
+}
+
+// chunkIndex finds the chunks of a dataset in the file.
+type chunkIndex interface {
+	// chunk returns the chunk at a linear index of the grid of chunks,
+	// false if the chunk has not been allocated.
+	chunk(i int) (chunkRef, bool, error)
+}
+
+// chunked storage stores the data in chunks of the same dimensions, found using an index.
+type chunked struct {
+	dims      []int
+	chunkDims []int
+	grid      []int
+	// indexGrid is the grid of chunks of the maximum dimensions of the dataset, if they are
+	// not unlimited. Chunks are indexed by their linear position in this grid.
+	indexGrid  []int
+	elemSize   int
+	chunkBytes int
+	numChunks  int
+	filters    []filter
+	// partialUnfiltered is set if the filters are not applied to chunks crossing the
+	// boundaries of the dataset.
+	partialUnfiltered bool
+	index             chunkIndex
+}
+
+// newChunked returns a chunked storage given the dimensions of the chunks stored in layout
+// messages, of which the last is the size of the elements.
+func newChunked(dims, maxDims []int, elemSize int, chunkDims []uint64, filters []filter, partialUnfiltered bool) (*chunked, error) {
+	if len(dims) == 0 || len(chunkDims) != len(dims)+1 {
+		return nil, fmt.Errorf("chunks of rank %d for a dataset of rank %d", len(chunkDims)-1, len(dims))
+	}
+	if last := chunkDims[len(dims)]; last != uint64(elemSize) {
+		return nil, fmt.Errorf("chunks of elements of %d bytes for elements of %d bytes", last, elemSize)
+	}
+	c := &chunked{
+		dims:              dims,
+		elemSize:          elemSize,
+		filters:           filters,
+		partialUnfiltered: partialUnfiltered,
+	}
+	chunkBytes, err := checkedSize(chunkDims)
+	if err != nil {
+		return nil, err
+	}
+	if chunkBytes == 0 || chunkBytes > maxBlockSize {
+		return nil, fmt.Errorf("chunks of dimensions %v not supported", chunkDims[:len(dims)])
+	}
+	c.chunkBytes = chunkBytes
+	indexGrid := make([]uint64, len(dims))
+	for i, dim := range dims {
+		chunkDim := int(chunkDims[i])
+		c.chunkDims = append(c.chunkDims, chunkDim)
+		c.grid = append(c.grid, (dim+chunkDim-1)/chunkDim)
+		maxDim := max(maxDims[i], dim)
+		if maxDims[i] < 0 {
+			maxDim = dim
+		}
+		indexGrid[i] = uint64(maxDim/chunkDim + min(maxDim%chunkDim, 1))
+		c.indexGrid = append(c.indexGrid, int(indexGrid[i]))
+	}
+	if c.numChunks, err = checkedSize(indexGrid); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *chunked) rowAlignment() int {
+	return c.chunkDims[0]
+}
+
+func (c *chunked) read(ds *Dataset, dst []byte, start, count int) error {
+	if count == 0 || slices.Contains(c.grid, 0) {
+		return nil
+	}
+	rank := len(c.dims)
+	dstDims := append([]int{count}, c.dims[1:]...)
+	coords := make([]int, rank)
+	coords[0] = start / c.chunkDims[0]
+	last := (start + count - 1) / c.chunkDims[0]
+	dstOrigin := make([]int, rank)
+	srcOrigin := make([]int, rank)
+	extent := make([]int, rank)
+	var fill []byte
+	for {
+		i, partial := 0, false
+		for k := range rank {
+			i = i*c.indexGrid[k] + coords[k]
+			origin := coords[k] * c.chunkDims[k]
+			lo, hi := origin, min(origin+c.chunkDims[k], c.dims[k])
+			partial = partial || origin+c.chunkDims[k] > c.dims[k]
+			dstOrigin[k] = lo
+			if k == 0 {
+				lo, hi = max(lo, start), min(hi, start+count)
+				dstOrigin[k] = lo - start
+			}
+			srcOrigin[k], extent[k] = lo-origin, hi-lo
+		}
+		ref, ok, err := c.index.chunk(i)
+		if err != nil {
+			return err
+		}
+		var src []byte
+		if ok {
+			if src, err = c.decode(ds.f, ref, partial); err != nil {
+				return fmt.Errorf("chunk %v: %v", coords, err)
+			}
+		} else {
+			// The chunk has never been written.
+			if fill == nil {
+				fill = make([]byte, c.chunkBytes)
+				ds.fillRows(fill)
+			}
+			src = fill
+		}
+		copyBox(dst, dstDims, dstOrigin, src, c.chunkDims, srcOrigin, extent, c.elemSize)
+		// Move to the next chunk in row-major order.
+		k := rank - 1
+		for ; k >= 0; k-- {
+			coords[k]++
+			if k == 0 && coords[k] <= last || k > 0 && coords[k] < c.grid[k] {
+				break
+			}
+			coords[k] = 0
+		}
+		if k < 0 {
+			return nil
+		}
+	}
+}
+
+// decode reads a chunk and reverts the filters applied to it.
+func (c *chunked) decode(f *File, ref chunkRef, partial bool) ([]byte, error) {
+	data, err := f.read(ref.addr, ref.size)
+	if err != nil {
+		return nil, err
+	}
+	if !partial || !c.partialUnfiltered {
+		for i := len(c.filters) - 1; i >= 0; i-- {
+			if i < 32 && ref.mask&(1<<i) != 0 {
+				continue
+			}
+			if data, err = c.unfilter(c.filters[i], data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(data) != c.chunkBytes {
+		return nil, fmt.Errorf("chunk of %d bytes but want %d bytes", len(data), c.chunkBytes)
+	}
+	return data, nil
+}
+
+func (c *chunked) unfilter(flt filter, data []byte) ([]byte, error) {
+	switch flt.id {
+	case filterDeflate:
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decompress chunk: %v", err)
+		}
+		// Filters applied after the compression can only add a checksum.
+		limit := c.chunkBytes + 4*len(c.filters)
+		out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decompress chunk: %v", err)
+		}
+		if len(out) > limit {
+			return nil, fmt.Errorf("decompressed chunk larger than %d bytes", limit)
+		}
+		return out, nil
+	case filterShuffle:
+		size := c.elemSize
+		if len(flt.params) > 0 {
+			size = int(flt.params[0])
+		}
+		if size <= 1 {
+			return data, nil
+		}
+		return unshuffle(data, size), nil
+	case filterFletcher32:
+		if len(data) < 4 {
+			return nil, fmt.Errorf("chunk of %d bytes too small to store a Fletcher-32 checksum", len(data))
+		}
+		return data[:len(data)-4], nil
+	}
+	return nil, fmt.Errorf("filter %d not supported", flt.id)
+}
+
+// unshuffle reverts the shuffle filter, which stores the byte j of all the elements
+// before the byte j+1.
+func unshuffle(data []byte, size int) []byte {
+	n := len(data) / size
+	out := make([]byte, len(data))
+	for j := range size {
+		for i := range n {
+			out[i*size+j] = data[j*n+i]
+		}
+	}
+	copy(out[n*size:], data[n*size:])
+	return out
+}
+
+// copyBox copies a box of elements between two row-major arrays given their dimensions
+// and the origin of the box in each array.
+func copyBox(dst []byte, dstDims, dstOrigin []int, src []byte, srcDims, srcOrigin []int, extent []int, elemSize int) {
+	rank := len(extent)
+	dstStrides, srcStrides := strides(dstDims, elemSize), strides(srcDims, elemSize)
+	line := extent[rank-1] * elemSize
+	index := make([]int, rank)
+	for {
+		dstPos, srcPos := 0, 0
+		for k := range rank {
+			dstPos += (dstOrigin[k] + index[k]) * dstStrides[k]
+			srcPos += (srcOrigin[k] + index[k]) * srcStrides[k]
+		}
+		copy(dst[dstPos:dstPos+line], src[srcPos:srcPos+line])
+		k := rank - 2
+		for ; k >= 0; k-- {
+			index[k]++
+			if index[k] < extent[k] {
+				break
+			}
+			index[k] = 0
+		}
+		if k < 0 {
+			return
+		}
+	}
+}
+
+func strides(dims []int, elemSize int) []int {
+	strides := make([]int, len(dims))
+	stride := elemSize
+	for k := len(dims) - 1; k >= 0; k-- {
+		strides[k] = stride
+		stride *= dims[k]
+	}
+	return strides
+}
+
+// singleIndex indexes datasets stored in a single chunk.
+type singleIndex struct {
+	ref chunkRef
+}
+
+func (x singleIndex) chunk(int) (chunkRef, bool, error) {
+	return x.ref, x.ref.addr != undefined, nil
+}
+
+// implicitIndex indexes chunks stored contiguously in row-major order.
+type implicitIndex struct {
+	addr       uint64
+	chunkBytes uint64
+}
+
+func (x implicitIndex) chunk(i int) (chunkRef, bool, error) {
+	if x.addr == undefined {
+		return chunkRef{}, false, nil
+	}
+	return chunkRef{addr: x.addr + uint64(i)*x.chunkBytes, size: x.chunkBytes}, true, nil
+}
+
+// btreeIndex indexes chunks with a version 1 B-tree.
+// The leaves of the tree are read when the dataset is opened.
+type btreeIndex map[int]chunkRef
+
+func (x btreeIndex) chunk(i int) (chunkRef, bool, error) {
+	ref, ok := x[i]
+	return ref, ok, nil
+}
+
+func (f *File) btreeIndex(addr uint64, c *chunked) (btreeIndex, error) {
+	x := make(btreeIndex)
+	if addr == undefined {
+		return x, nil
+	}
+	rank := len(c.dims)
+	err := f.btreeV1(addr, 1, 8*(rank+2), func(key []byte, child uint64) error {
+		d := f.decoder(key)
+		ref := chunkRef{addr: child, size: uint64(d.uint32()), mask: d.uint32()}
+		i, outside := 0, false
+		for k := range rank {
+			offset := d.uintN(8)
+			chunkDim := uint64(c.chunkDims[k])
+			if offset%chunkDim != 0 {
+				return fmt.Errorf("invalid chunk offset %d along axis %d", offset, k)
+			}
+			// Chunks outside of the dataset are kept by the file when it shrinks.
+			outside = outside || offset/chunkDim >= uint64(c.grid[k])
+			i = i*c.indexGrid[k] + int(offset/chunkDim)
+		}
+		if d.err != nil {
+			return d.err
+		}
+		if !outside {
+			x[i] = ref
+		}
+		return nil
+	})
+	return x, err
+}
+
+// fixedArrayIndex indexes chunks with a fixed array. Arrays with more elements than
+// a page store their elements in pages, which are read when accessed.
+type fixedArrayIndex struct {
+	f *File
+	// filtered is set if the elements store the size and filter mask of the chunks.
+	filtered   bool
+	chunkBytes uint64
+	entrySize  int
+	sizeBytes  int
+	n          int
+	// entries are the elements of arrays which are not paged.
+	entries []byte
+	// pageSize is the number of elements of a page, bitmap has its bits set
+	// for the pages which have been written, and pages is the address of the first page.
+	pageSize int
+	bitmap   []byte
+	pages    uint64
+}
+
+func (f *File) fixedArrayIndex(addr uint64, c *chunked) (chunkIndex, error) {
+	header, err := f.read(addr, uint64(12+f.offsetSize+f.lengthSize))
+	if err != nil {
+		return nil, err
+	}
+	d := f.decoder(header)
+	d.signature("FAHD")
+	d.skip(1) // Version.
+	x := &fixedArrayIndex{f: f, chunkBytes: uint64(c.chunkBytes)}
+	x.filtered = d.uint8() == 1
+	x.entrySize = int(d.uint8())
+	pageBits := d.uint8()
+	x.n = d.int()
+	block := d.offset()
+	if d.err == nil {
+		d.err = verify(header)
+	}
+	x.sizeBytes = x.entrySize - f.offsetSize - 4
+	switch {
+	case d.err != nil:
+	case !x.filtered && x.entrySize != f.offsetSize, x.filtered && (x.sizeBytes < 1 || x.sizeBytes > 8):
+		d.fail("elements of %d bytes not supported", x.entrySize)
+	case x.n < c.numChunks:
+		d.fail("%d elements for %d chunks", x.n, c.numChunks)
+	case pageBits > 30:
+		d.fail("pages of 2^%d elements not supported", pageBits)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid fixed array header at address %d: %v", addr, d.err)
+	}
+	if block == undefined {
+		return x, nil
+	}
+	prefix := 6 + f.offsetSize
+	x.pageSize = 1 << pageBits
+	size := prefix + x.n*x.entrySize + 4
+	if paged := x.n > x.pageSize; paged {
+		pages := (x.n + x.pageSize - 1) / x.pageSize
+		size = prefix + (pages+7)/8 + 4
+	}
+	if size > maxBlockSize {
+		return nil, fmt.Errorf("fixed array data block of %d bytes at address %d too large", size, block)
+	}
+	buf, err := f.read(block, uint64(size))
+	if err != nil {
+		return nil, err
+	}
+	d = f.decoder(buf)
+	d.signature("FADB")
+	d.skip(2) // Version and client identifier.
+	if headerAddr := d.offset(); headerAddr != addr && d.err == nil {
+		d.fail("data block of the fixed array at address %d", headerAddr)
+	}
+	if d.err == nil {
+		d.err = verify(buf)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid fixed array data block at address %d: %v", block, d.err)
+	}
+	if x.n > x.pageSize {
+		x.bitmap = buf[prefix : len(buf)-4]
+		x.pages = block + uint64(size)
+	} else {
+		x.entries = buf[prefix : len(buf)-4]
+	}
+	return x, nil
+}
+
+func (x *fixedArrayIndex) chunk(i int) (chunkRef, bool, error) {
+	var entry []byte
+	switch {
+	case x.entries != nil:
+		entry = x.entries[i*x.entrySize:]
+	case x.bitmap != nil:
+		page := i / x.pageSize
+		if x.bitmap[page/8]&(0x80>>(page%8)) == 0 {
+			return chunkRef{}, false, nil
+		}
+		n := min(x.pageSize, x.n-page*x.pageSize)
+		addr := x.pages + uint64(page)*uint64(x.pageSize*x.entrySize+4)
+		buf, err := x.f.read(addr, uint64(n*x.entrySize+4))
+		if err != nil {
+			return chunkRef{}, false, err
+		}
+		if err := verify(buf); err != nil {
+			return chunkRef{}, false, fmt.Errorf("invalid fixed array page at address %d: %v", addr, err)
+		}
+		entry = buf[(i%x.pageSize)*x.entrySize:]
+	default:
+		return chunkRef{}, false, nil
+	}
+	d := x.f.decoder(entry)
+	ref := chunkRef{addr: d.offset(), size: x.chunkBytes}
+	if x.filtered {
+		ref.size = d.uintN(x.sizeBytes)
+		ref.mask = d.uint32()
+	}
+	return ref, ref.addr != undefined, d.err
+}
+
+// checkedSize returns the product of values, or an error if it overflows an int.
+func checkedSize(values []uint64) (int, error) {
+	n := uint64(1)
+	for _, v := range values {
+		if v != 0 && n > math.MaxInt/v {
+			return 0, fmt.Errorf("size of %v overflows", values)
+		}
+		n *= v
+	}
+	return int(n), nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides a backend reporting usage metrics to a pluggable sink,
+// such as the number of compilations, runs and bytes transferred.
+package metrics
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+)
+
+// Metric is the name of a metric.
+type Metric string
+
+// Counters reported with Sink.Add.
+const (
+	// GraphsBuilt is the number of graphs created.
+	GraphsBuilt Metric = "graphs_built"
+	// Compiles is the number of graphs compiled, including failed compilations.
+	Compiles Metric = "compiles"
+	// CompileErrors is the number of failed compilations.
+	CompileErrors Metric = "compile_errors"
+	// CompileCacheHits is the number of compilations served by a cache.
+	// It is reported by the compile caches given the sink.
+	CompileCacheHits Metric = "compile_cache_hits"
+	// Runs is the number of executions of compiled graphs, including failed executions.
+	Runs Metric = "runs"
+	// RunErrors is the number of failed executions.
+	RunErrors Metric = "run_errors"
+	// BytesToDevice is the number of bytes transferred from the host to the devices.
+	BytesToDevice Metric = "bytes_to_device"
+	// BytesToHost is the number of bytes transferred from the devices to the host.
+	BytesToHost Metric = "bytes_to_host"
+)
+
+// Durations reported with Sink.Observe.
+const (
+	// CompileTime is the duration of a compilation.
+	CompileTime Metric = "compile_time"
+	// RunTime is the duration of an execution.
+	RunTime Metric = "run_time"
+)
+
+// Sink receives the metrics of a backend.
+// Sinks are called synchronously and must be safe for concurrent use.
+type Sink interface {
+	// Add adds a delta to a counter.
+	Add(m Metric, delta int64)
+
+	// Observe records a duration.
+	Observe(m Metric, d time.Duration)
+}
+
+// Backend reports the metrics of a backend to a sink.
+type Backend struct {
+	*intercept.Backend
+	sink       Sink
+	removeHook func()
+}
+
+// New returns a backend forwarding all calls to b and reporting metrics to sink.
+func New(b backend.Backend, sink Sink) *Backend {
+	name := b.Platform().Name()
+	return &Backend{
+		Backend: intercept.NewBackend(b, interceptor{sink: sink}),
+		sink:    sink,
+		removeHook: platform.AddHook(func(ev platform.HookEvent) {
+			if ev.Platform != name || ev.Err != nil {
+				return
+			}
+			switch ev.Op {
+			case platform.SendOp:
+				sink.Add(BytesToDevice, ev.Bytes)
+			case platform.ToHostOp:
+				sink.Add(BytesToHost, ev.Bytes)
+			}
+		}),
+	}
+}
+
+// Sink returns the sink receiving the metrics.
+func (b *Backend) Sink() Sink {
+	return b.sink
+}
+
+// NewOps returns a new graph reporting metrics.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	g, err := b.Backend.NewOps(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b.sink.Add(GraphsBuilt, 1)
+	return g, nil
+}
+
+// Close stops reporting metrics and closes the wrapped backend.
+func (b *Backend) Close() error {
+	b.removeHook()
+	return b.Backend.Close()
+}
+
+// Release stops reporting metrics and closes the wrapped backend.
+//
+// Deprecated: use Close.
+func (b *Backend) Release() error {
+	return b.Close()
+}
+
+type interceptor struct {
+	sink Sink
+}
+
+func (interceptor) Before(*intercept.Call) error {
+	return nil
+}
+
+func (i interceptor) After(call *intercept.Call, err error) {
+	switch call.Op {
+	case intercept.OpCompile:
+		i.report(Compiles, CompileErrors, CompileTime, call, err)
+	case intercept.OpRun:
+		i.report(Runs, RunErrors, RunTime, call, err)
+	}
+}
+
+func (i interceptor) report(count, errs, duration Metric, call *intercept.Call, err error) {
+	i.sink.Add(count, 1)
+	if err != nil {
+		i.sink.Add(errs, 1)
+	}
+	i.sink.Observe(duration, call.Duration())
+}
+
+// Counters is a sink accumulating the metrics in memory.
+type Counters struct {
+	mu        sync.Mutex
+	counters  map[Metric]int64
+	durations map[Metric]time.Duration
+}
+
+var _ Sink = (*Counters)(nil)
+
+// NewCounters returns a sink accumulating metrics.
+func NewCounters() *Counters {
+	return &Counters{
+		counters:  make(map[Metric]int64),
+		durations: make(map[Metric]time.Duration),
+	}
+}
+
+// Add adds a delta to a counter.
+func (c *Counters) Add(m Metric, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counters[m] += delta
+}
+
+// Observe adds a duration to the total duration of a metric.
+func (c *Counters) Observe(m Metric, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.durations[m] += d
+}
+
+// Value returns the value of a counter.
+func (c *Counters) Value(m Metric) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counters[m]
+}
+
+// Total returns the total duration recorded for a metric.
+func (c *Counters) Total(m Metric) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.durations[m]
+}
+
+// Snapshot returns a copy of all the counters.
+func (c *Counters) Snapshot() map[Metric]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counters)
+}
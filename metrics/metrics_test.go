@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/metrics"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestMetrics(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := opstest.NewBackend(plat)
+	counters := metrics.NewCounters()
+	b := metrics.New(inner, counters)
+	defer b.Close()
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Of(dtype.Float32, 6)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := plat.Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: sh}}, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := dev.Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runner.Run([]platform.Handle{arg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runner.Run(nil); err == nil {
+		t.Fatal("running without arguments did not return an error")
+	}
+	inner.FailCompile(errors.New("compilation failure"))
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: sh}}, nil, []*shape.Shape{sh}); err == nil {
+		t.Fatal("failed compilation did not return an error")
+	}
+	want := map[metrics.Metric]int64{
+		metrics.GraphsBuilt:   1,
+		metrics.Compiles:      2,
+		metrics.CompileErrors: 1,
+		metrics.Runs:          2,
+		metrics.RunErrors:     1,
+	}
+	for m, v := range want {
+		if got := counters.Value(m); got != v {
+			t.Errorf("%s: got %d but want %d", m, got, v)
+		}
+	}
+	if got := counters.Value(metrics.BytesToDevice); got < int64(sh.ByteSize()) {
+		t.Errorf("%s: got %d but want at least %d", metrics.BytesToDevice, got, sh.ByteSize())
+	}
+}
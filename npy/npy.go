@@ -0,0 +1,264 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package npy reads and writes arrays in the NumPy .npy and .npz formats.
+//
+// Arrays are read directly into host buffers. Arrays stored in Fortran order
+// are read with a column-major layout.
+package npy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+const magic = "\x93NUMPY"
+
+// headerAlignment is the alignment of the data in a .npy file.
+const headerAlignment = 64
+
+var descrs = map[dtype.DataType]string{
+	dtype.Bool:       "|b1",
+	dtype.Int8:       "|i1",
+	dtype.Int16:      "<i2",
+	dtype.Int32:      "<i4",
+	dtype.Int64:      "<i8",
+	dtype.Uint8:      "|u1",
+	dtype.Uint16:     "<u2",
+	dtype.Uint32:     "<u4",
+	dtype.Uint64:     "<u8",
+	dtype.Float16:    "<f2",
+	dtype.Float32:    "<f4",
+	dtype.Float64:    "<f8",
+	dtype.Complex64:  "<c8",
+	dtype.Complex128: "<c16",
+}
+
+// Descr returns the NumPy type descriptor of a data type.
+func Descr(dt dtype.DataType) (string, error) {
+	if dt == dtype.Int {
+		dt = dtype.Int64
+		if dtype.IntSize == 4 {
+			dt = dtype.Int32
+		}
+	}
+	descr, ok := descrs[dt]
+	if !ok {
+		return "", fmt.Errorf("data type %s not supported by the npy format", dt)
+	}
+	return descr, nil
+}
+
+// DataType returns the data type of a NumPy type descriptor and
+// whether the data is stored in big-endian order.
+func DataType(descr string) (dt dtype.DataType, bigEndian bool, err error) {
+	if len(descr) < 2 {
+		return dtype.Invalid, false, fmt.Errorf("invalid type descriptor %q", descr)
+	}
+	order, kind := descr[0], descr[1:]
+	switch order {
+	case '<', '=', '|':
+	case '>':
+		bigEndian = true
+	default:
+		return dtype.Invalid, false, fmt.Errorf("invalid byte order in type descriptor %q", descr)
+	}
+	for dt, d := range descrs {
+		if d[1:] == kind {
+			return dt, bigEndian, nil
+		}
+	}
+	return dtype.Invalid, false, fmt.Errorf("type descriptor %q not supported", descr)
+}
+
+// Write writes the content of a host buffer in the .npy format.
+func Write(w io.Writer, buf platform.HostBuffer) error {
+	sh := buf.Shape()
+	header, err := header(sh)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	data := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	if data == nil {
+		return platform.ErrBufferFreed
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func header(sh *shape.Shape) ([]byte, error) {
+	descr, err := Descr(sh.DType)
+	if err != nil {
+		return nil, err
+	}
+	fortran := "False"
+	switch {
+	case sh.Layout == nil || sh.Layout.Equal(shape.RowMajor(len(sh.AxisLengths))):
+	case sh.Layout.Equal(shape.ColumnMajor(len(sh.AxisLengths))):
+		fortran = "True"
+	default:
+		return nil, fmt.Errorf("cannot write array of shape %s: layout not supported by the npy format", sh)
+	}
+	if sh.Quant != nil || sh.IsBitPacked() {
+		return nil, fmt.Errorf("cannot write array of shape %s: quantized or packed data not supported by the npy format", sh)
+	}
+	dims := make([]string, len(sh.AxisLengths))
+	for i, length := range sh.AxisLengths {
+		dims[i] = strconv.Itoa(length)
+	}
+	shapeStr := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shapeStr += ","
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': (%s), }", descr, fortran, shapeStr)
+	// Version 1.0 stores the header length on 2 bytes, version 2.0 on 4 bytes.
+	major, lenSize := byte(1), 2
+	if len(magic)+2+lenSize+len(dict)+1 > 0xffff {
+		major, lenSize = 2, 4
+	}
+	preamble := len(magic) + 2 + lenSize
+	padded := (preamble + len(dict) + 1 + headerAlignment - 1) / headerAlignment * headerAlignment
+	dict += strings.Repeat(" ", padded-preamble-len(dict)-1) + "\n"
+	var b bytes.Buffer
+	b.WriteString(magic)
+	b.Write([]byte{major, 0})
+	if lenSize == 2 {
+		binary.Write(&b, binary.LittleEndian, uint16(len(dict)))
+	} else {
+		binary.Write(&b, binary.LittleEndian, uint32(len(dict)))
+	}
+	b.WriteString(dict)
+	return b.Bytes(), nil
+}
+
+var (
+	descrRE   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	fortranRE = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	shapeRE   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// Read reads an array in the .npy format into a host buffer allocated by alloc.
+func Read(r io.Reader, alloc platform.Allocator) (platform.HostBuffer, error) {
+	sh, bigEndian, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := alloc.Allocate(sh)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Acquire()
+	_, err = io.ReadFull(r, data)
+	if err == nil && bigEndian {
+		size := dtype.Sizeof(sh.DType)
+		if dtype.IsComplex(sh.DType) {
+			// The real and imaginary parts are swapped separately.
+			size /= 2
+		}
+		swapBytes(data, size)
+	}
+	buf.Release()
+	if err != nil {
+		buf.Free()
+		return nil, fmt.Errorf("cannot read data of array %s: %v", sh, err)
+	}
+	return buf, nil
+}
+
+func readHeader(r io.Reader) (*shape.Shape, bool, error) {
+	preamble := make([]byte, len(magic)+2)
+	if _, err := io.ReadFull(r, preamble); err != nil {
+		return nil, false, fmt.Errorf("cannot read npy header: %v", err)
+	}
+	if string(preamble[:len(magic)]) != magic {
+		return nil, false, fmt.Errorf("invalid npy magic string %q", preamble[:len(magic)])
+	}
+	var headerLen int
+	switch major := preamble[len(magic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, false, err
+		}
+		headerLen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, false, err
+		}
+		headerLen = int(n)
+	default:
+		return nil, false, fmt.Errorf("npy format version %d not supported", major)
+	}
+	dict := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, dict); err != nil {
+		return nil, false, fmt.Errorf("cannot read npy header: %v", err)
+	}
+	return parseHeader(string(dict))
+}
+
+func parseHeader(dict string) (*shape.Shape, bool, error) {
+	descr := descrRE.FindStringSubmatch(dict)
+	fortran := fortranRE.FindStringSubmatch(dict)
+	dims := shapeRE.FindStringSubmatch(dict)
+	if descr == nil || fortran == nil || dims == nil {
+		return nil, false, fmt.Errorf("invalid npy header %q", dict)
+	}
+	dt, bigEndian, err := DataType(descr[1])
+	if err != nil {
+		return nil, false, err
+	}
+	var axisLengths []int
+	for _, field := range strings.Split(dims[1], ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		length, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid shape in npy header %q: %v", dict, err)
+		}
+		axisLengths = append(axisLengths, length)
+	}
+	sh := shape.Of(dt, axisLengths...)
+	if fortran[1] == "True" && len(axisLengths) > 1 {
+		sh = sh.WithLayout(shape.ColumnMajor(len(axisLengths)))
+	}
+	if err := sh.Check(); err != nil {
+		return nil, false, err
+	}
+	return sh, bigEndian, nil
+}
+
+func swapBytes(data []byte, size int) {
+	for i := 0; i+size <= len(data); i += size {
+		elem := data[i : i+size]
+		for j := range size / 2 {
+			elem[j], elem[size-1-j] = elem[size-1-j], elem[j]
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package npy_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/npy"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func newBuffer(t *testing.T, alloc platform.Allocator, sh *shape.Shape, vals []float32) platform.HostBuffer {
+	t.Helper()
+	buf, err := alloc.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(buf.Acquire(), dtype.FromSlice(vals))
+	buf.Release()
+	return buf
+}
+
+func TestRoundTrip(t *testing.T) {
+	alloc := platformtest.New(1)
+	src := newBuffer(t, alloc, shape.Of(dtype.Float32, 2, 3), []float32{1, 2, 3, 4, 5, 6})
+	var b bytes.Buffer
+	if err := npy.Write(&b, src); err != nil {
+		t.Fatal(err)
+	}
+	header := b.String()[:strings.IndexByte(b.String(), '\n')+1]
+	if len(header)%64 != 0 {
+		t.Errorf("header of %d bytes is not aligned on 64 bytes", len(header))
+	}
+	if !strings.Contains(header, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }") {
+		t.Errorf("unexpected header %q", header)
+	}
+	got, err := npy.Read(&b, alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Shape().Equal(src.Shape()) {
+		t.Errorf("got shape %s but want %s", got.Shape(), src.Shape())
+	}
+	if vals := dtype.ToSlice[float32](got.AcquireRead()); !slices.Equal(vals, []float32{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("got values %v", vals)
+	}
+	got.ReleaseRead()
+}
+
+func TestReadBigEndianFortran(t *testing.T) {
+	dict := "{'descr': '>i4', 'fortran_order': True, 'shape': (2, 2), }\n"
+	var b bytes.Buffer
+	b.WriteString("\x93NUMPY\x01\x00")
+	b.Write([]byte{byte(len(dict)), 0})
+	b.WriteString(dict)
+	b.Write([]byte{0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0, 4})
+	buf, err := npy.Read(&b, platformtest.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !buf.Shape().Layout.Equal(shape.ColumnMajor(2)) {
+		t.Errorf("got layout %v but want column-major", buf.Shape().Layout)
+	}
+	if vals := dtype.ToSlice[int32](buf.AcquireRead()); !slices.Equal(vals, []int32{1, 2, 3, 4}) {
+		t.Errorf("got values %v", vals)
+	}
+	buf.ReleaseRead()
+}
+
+func TestNPZ(t *testing.T) {
+	alloc := platformtest.New(1)
+	arrays := map[string]platform.HostBuffer{
+		"weights": newBuffer(t, alloc, shape.Of(dtype.Float32, 3), []float32{1, 2, 3}),
+		"bias":    newBuffer(t, alloc, shape.Scalar(dtype.Float32), []float32{4}),
+	}
+	var b bytes.Buffer
+	if err := npy.WriteNPZ(&b, arrays); err != nil {
+		t.Fatal(err)
+	}
+	got, err := npy.ReadNPZ(bytes.NewReader(b.Bytes()), int64(b.Len()), alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range arrays {
+		if !got[name].Shape().Equal(want.Shape()) {
+			t.Errorf("%s: got shape %s but want %s", name, got[name].Shape(), want.Shape())
+		}
+	}
+}
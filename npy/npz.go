@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package npy
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/backend/platform"
+)
+
+// WriteNPZ writes arrays in the .npz format, that is a zip archive with one .npy file
+// per array. Arrays are written sorted by name.
+func WriteNPZ(w io.Writer, arrays map[string]platform.HostBuffer) error {
+	zw := zip.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(arrays)) {
+		f, err := zw.Create(name + ".npy")
+		if err != nil {
+			return err
+		}
+		if err := Write(f, arrays[name]); err != nil {
+			return fmt.Errorf("cannot write array %q: %v", name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// ReadNPZ reads all the arrays of a .npz archive into host buffers allocated by alloc.
+// Arrays are indexed by their name without the .npy extension.
+func ReadNPZ(r io.ReaderAt, size int64, alloc platform.Allocator) (map[string]platform.HostBuffer, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	arrays := make(map[string]platform.HostBuffer, len(zr.File))
+	for _, f := range zr.File {
+		name, ok := strings.CutSuffix(f.Name, ".npy")
+		if !ok {
+			continue
+		}
+		buf, err := readEntry(f, alloc)
+		if err != nil {
+			for _, buf := range arrays {
+				buf.Free()
+			}
+			return nil, fmt.Errorf("cannot read array %q: %v", name, err)
+		}
+		arrays[name] = buf
+	}
+	return arrays, nil
+}
+
+func readEntry(f *zip.File, alloc platform.Allocator) (platform.HostBuffer, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return Read(rc, alloc)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+const defaultArenaBlockSize = 1024
+
+// Arena allocates values in blocks to reduce the number of allocations, and the
+// pressure on the garbage collector, when building graphs with many nodes.
+//
+// The values of an arena are only released once all the values allocated in the same
+// block are unreachable. Graph implementations typically allocate their nodes in an arena
+// owned by the root graph such that all the nodes are freed together when the graph is dropped.
+// An Arena is not safe for concurrent use.
+type Arena[T any] struct {
+	blockSize int
+	block     []T
+	len       int
+}
+
+// NewArena returns an arena allocating blocks of blockSize values.
+// A default size is used if blockSize is not positive.
+func NewArena[T any](blockSize int) *Arena[T] {
+	if blockSize <= 0 {
+		blockSize = defaultArenaBlockSize
+	}
+	return &Arena[T]{blockSize: blockSize}
+}
+
+// New returns a pointer to a new zero value.
+func (a *Arena[T]) New() *T {
+	if len(a.block) == cap(a.block) {
+		a.block = make([]T, 0, a.blockSize)
+	}
+	a.block = a.block[:len(a.block)+1]
+	a.len++
+	return &a.block[len(a.block)-1]
+}
+
+// Len returns the number of values allocated by the arena.
+func (a *Arena[T]) Len() int {
+	return a.len
+}
+
+// Reset drops the reference of the arena to its current block.
+// Values already allocated remain valid as long as they are reachable.
+func (a *Arena[T]) Reset() {
+	a.block = nil
+	a.len = 0
+}
+
+// SliceArena allocates small slices, such as axis lengths or attributes, in large blocks.
+// Slices larger than a block are allocated individually.
+// A SliceArena is not safe for concurrent use.
+type SliceArena[T any] struct {
+	blockSize int
+	block     []T
+}
+
+// NewSliceArena returns an arena allocating slices from blocks of blockSize elements.
+// A default size is used if blockSize is not positive.
+func NewSliceArena[T any](blockSize int) *SliceArena[T] {
+	if blockSize <= 0 {
+		blockSize = defaultArenaBlockSize
+	}
+	return &SliceArena[T]{blockSize: blockSize}
+}
+
+// Make returns a zeroed slice of length and capacity n.
+func (a *SliceArena[T]) Make(n int) []T {
+	if n > a.blockSize/4 {
+		return make([]T, n)
+	}
+	if cap(a.block)-len(a.block) < n {
+		a.block = make([]T, 0, a.blockSize)
+	}
+	start := len(a.block)
+	a.block = a.block[:start+n]
+	return a.block[start : start+n : start+n]
+}
+
+// Clone returns a copy of a slice allocated in the arena.
+// It returns nil if s is nil.
+func (a *SliceArena[T]) Clone(s []T) []T {
+	if s == nil {
+		return nil
+	}
+	c := a.Make(len(s))
+	copy(c, s)
+	return c
+}
+
+// Reset drops the reference of the arena to its current block.
+// Slices already allocated remain valid as long as they are reachable.
+func (a *SliceArena[T]) Reset() {
+	a.block = nil
+}
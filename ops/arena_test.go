@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/ops"
+)
+
+func TestArena(t *testing.T) {
+	a := ops.NewArena[[2]int](4)
+	var ptrs []*[2]int
+	for i := range 10 {
+		p := a.New()
+		p[0] = i
+		ptrs = append(ptrs, p)
+	}
+	for i, p := range ptrs {
+		if p[0] != i {
+			t.Errorf("value %d has been overwritten: got %d", i, p[0])
+		}
+	}
+	if got := a.Len(); got != 10 {
+		t.Errorf("got length %d but want 10", got)
+	}
+	a.Reset()
+	if got := a.Len(); got != 0 {
+		t.Errorf("got length %d after reset but want 0", got)
+	}
+}
+
+func TestSliceArena(t *testing.T) {
+	a := ops.NewSliceArena[int](16)
+	x := a.Clone([]int{1, 2})
+	y := a.Make(3)
+	if x = append(x, 3); !slices.Equal(x, []int{1, 2, 3}) || !slices.Equal(y, []int{0, 0, 0}) {
+		t.Errorf("appending to a slice modified the next slice: got %v and %v", x, y)
+	}
+	if got := a.Clone(nil); got != nil {
+		t.Errorf("got %v but want nil", got)
+	}
+	if got := len(a.Make(100)); got != 100 {
+		t.Errorf("got length %d but want 100", got)
+	}
+}
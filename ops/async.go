@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Execution is a run of a compiled graph which may not have completed yet.
+	Execution interface {
+		// Done returns a channel closed when the execution has completed or has been cancelled.
+		Done() <-chan struct{}
+
+		// Await blocks until the execution has completed and returns its results.
+		// If the execution has been cancelled before completing, Await returns the error
+		// of its context and the results of the run, if any, are freed.
+		Await() (out, traces []platform.DeviceHandle, err error)
+
+		// Cancel requests the execution to stop. It has no effect once the execution has completed.
+		Cancel()
+	}
+
+	// AsyncRunner is implemented by runners able to run without blocking the calling goroutine
+	// and to stop a run before it completes.
+	AsyncRunner interface {
+		Runner
+
+		// RunAsync starts a run of the compiled graph. The run is cancelled when ctx is done.
+		RunAsync(ctx context.Context, args []platform.Handle) (Execution, error)
+	}
+
+	// ContextCompiler is implemented by graphs able to stop a compilation before it completes.
+	ContextCompiler interface {
+		// CompileContext compiles the graph as Compile does. The compilation is
+		// cancelled when ctx is done.
+		CompileContext(ctx context.Context, dev platform.Device, output, traced []*OutputNode, params []*shape.Shape) (Runner, error)
+	}
+)
+
+// HostExecution is an execution completed by the host.
+// It can be used by backends to implement AsyncRunner.
+type HostExecution struct {
+	once   sync.Once
+	done   chan struct{}
+	cancel context.CancelFunc
+	out    []platform.DeviceHandle
+	traces []platform.DeviceHandle
+	err    error
+}
+
+var _ Execution = (*HostExecution)(nil)
+
+// NewHostExecution returns an execution which has not completed.
+// cancel is called by Cancel and once the execution has completed.
+func NewHostExecution(cancel context.CancelFunc) *HostExecution {
+	return &HostExecution{done: make(chan struct{}), cancel: cancel}
+}
+
+// Complete sets the results of the execution. Only the first call has an effect:
+// the handles passed to later calls are freed.
+func (e *HostExecution) Complete(out, traces []platform.DeviceHandle, err error) {
+	completed := false
+	e.once.Do(func() {
+		e.out, e.traces, e.err = out, traces, err
+		completed = true
+		close(e.done)
+	})
+	if !completed {
+		freeHandles(out)
+		freeHandles(traces)
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// Done returns a channel closed when the execution has completed.
+func (e *HostExecution) Done() <-chan struct{} {
+	return e.done
+}
+
+// Await blocks until the execution has completed and returns its results.
+func (e *HostExecution) Await() (out, traces []platform.DeviceHandle, err error) {
+	<-e.done
+	return e.out, e.traces, e.err
+}
+
+// Cancel calls the cancel function of the execution.
+func (e *HostExecution) Cancel() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// RunAsync starts a run of a compiled graph. If the runner does not implement AsyncRunner,
+// the run executes in a separate goroutine: cancelling the context does not interrupt it,
+// but the execution completes with the error of the context and the results of the run
+// are freed once it returns.
+func RunAsync(ctx context.Context, runner Runner, args []platform.Handle) (Execution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ar, ok := runner.(AsyncRunner); ok {
+		return ar.RunAsync(ctx, args)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	exec := NewHostExecution(cancel)
+	go func() {
+		exec.Complete(runner.Run(args))
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			exec.Complete(nil, nil, ctx.Err())
+		case <-exec.Done():
+		}
+	}()
+	return exec, nil
+}
+
+// RunContext runs a compiled graph and waits for its results or for ctx to be done.
+func RunContext(ctx context.Context, runner Runner, args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	exec, err := RunAsync(ctx, runner, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exec.Await()
+}
+
+// CompileContext compiles a graph for a device. If the graph does not implement
+// ContextCompiler, the context is only checked before the compilation starts.
+func CompileContext(ctx context.Context, g Graph, dev platform.Device, output, traced []*OutputNode, params []*shape.Shape) (Runner, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cc, ok := g.(ContextCompiler); ok {
+		return cc.CompileContext(ctx, dev, output, traced, params)
+	}
+	return g.Compile(dev, output, traced, params)
+}
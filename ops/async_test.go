@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// blocked runs another runner once release is closed.
+type blocked struct {
+	ops.Runner
+	release chan struct{}
+	ran     chan struct{}
+}
+
+func (r *blocked) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	<-r.release
+	defer close(r.ran)
+	return r.Runner.Run(args)
+}
+
+func TestRunAsync(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	x, err := dev.Send([]byte{1}, shape.Scalar(dtype.Bool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &increment{dev: dev}
+	out, _, err := ops.RunContext(context.Background(), runner, []platform.Handle{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out[0].(*platformtest.Handle).Data()[0]; got != 2 {
+		t.Errorf("got %d but want 2", got)
+	}
+	out[0].Free()
+
+	slow := &blocked{Runner: runner, release: make(chan struct{}), ran: make(chan struct{})}
+	exec, err := ops.RunAsync(context.Background(), slow, []platform.Handle{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.Cancel()
+	<-exec.Done()
+	if _, _, err := exec.Await(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+	close(slow.release)
+	<-slow.ran
+	// The results of the run are freed once the run completes.
+	for deadline := time.Now().Add(time.Second); plat.Counts().Live != 1; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d live handles but want 1", plat.Counts().Live)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ops.RunAsync(ctx, runner, []platform.Handle{x}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autodiff computes gradients of graphs recorded by ops/intercept
+// with reverse-mode differentiation.
+//
+// The calls recorded by an intercepted graph are replayed backward from an output:
+// the vector-Jacobian product (VJP) rule of every operation builds, with the builders
+// of the same graph, the nodes propagating the cotangent of its result to its operands.
+// The gradient is thus computed by any backend wrapped by intercept.
+//
+// Only floating-point nodes are differentiated: other nodes, such as comparisons or
+// indices, stop the propagation. Nodes of subgraphs are not differentiated, such that
+// operations calling subgraphs (While, Cond, Call, Reduce) have no gradient rule.
+package autodiff
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/graphx"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+// rule returns the cotangents of the operands of a node given the cotangent ct of its result.
+// Operands without gradient have a cotangent without node.
+type rule func(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value
+
+var rules map[ops.OpID]rule
+
+// differentiable returns true if nodes of a data type are differentiated.
+func differentiable(dt dtype.DataType) bool {
+	return dtype.IsFloat(dt) || dtype.IsHalf(dt)
+}
+
+// Gradient returns the gradient of an atomic floating-point output with respect to the wrt nodes.
+// All the nodes must have been created by the same intercepted graph, in which the nodes
+// computing the gradient are created.
+func Gradient(output ops.Node, wrt []ops.Node) ([]ops.Node, error) {
+	out, err := recorded(output)
+	if err != nil {
+		return nil, err
+	}
+	if out.Shape() == nil || len(out.Shape().AxisLengths) != 0 {
+		return nil, fmt.Errorf("cannot compute the gradient of a non-atomic output of shape %s", out.Shape())
+	}
+	b := graphx.New(out.Graph())
+	one := b.Scalar(out.Shape().DType, 1)
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return VJP(output, one.Node(), wrt)
+}
+
+// VJP returns the vector-Jacobian products of an output with a cotangent of the shape of the
+// output, that is the gradients of the sum of output*cotangent, with respect to the wrt nodes.
+// Nodes in wrt on which the output does not depend have a zero gradient.
+func VJP(output, cotangent ops.Node, wrt []ops.Node) ([]ops.Node, error) {
+	out, err := recorded(output)
+	if err != nil {
+		return nil, err
+	}
+	g := out.Graph().(*intercept.Graph)
+	t := &transform{b: graphx.New(g), cts: make(map[*intercept.Node]graphx.Value)}
+	wrtNodes := make([]*intercept.Node, len(wrt))
+	for i, node := range wrt {
+		if wrtNodes[i], err = recorded(node); err != nil {
+			return nil, fmt.Errorf("wrt node %d: %w", i, err)
+		}
+		if wrtNodes[i].Graph() != ops.Graph(g) {
+			return nil, fmt.Errorf("wrt node %d has not been created by graph %s", i, g.Name())
+		}
+		if sh := wrtNodes[i].Shape(); sh == nil || !differentiable(sh.DType) {
+			return nil, fmt.Errorf("wrt node %d of shape %s cannot be differentiated", i, sh)
+		}
+	}
+	if sh := out.Shape(); sh == nil || !differentiable(sh.DType) {
+		return nil, fmt.Errorf("output of shape %s cannot be differentiated", sh)
+	}
+	nodes := g.Nodes()
+	// Only the nodes depending on a wrt node are differentiated.
+	depends := make(map[*intercept.Node]bool)
+	for _, node := range wrtNodes {
+		depends[node] = true
+	}
+	for _, node := range nodes {
+		for _, input := range node.Call().Inputs {
+			if depends[input] {
+				depends[node] = true
+				break
+			}
+		}
+	}
+	t.cts[out] = t.b.Wrap(cotangent)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		ct, ok := t.cts[node]
+		if !ok || !depends[node] || len(node.Call().Inputs) == 0 {
+			continue
+		}
+		if err := t.backward(node, ct); err != nil {
+			return nil, err
+		}
+	}
+	grads := make([]ops.Node, len(wrtNodes))
+	for i, node := range wrtNodes {
+		ct, ok := t.cts[node]
+		if !ok {
+			ct = t.zeros(node.Shape())
+		}
+		grads[i] = ct.Node()
+	}
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	return grads, nil
+}
+
+// recorded returns the intercepted node of a node.
+func recorded(node ops.Node) (*intercept.Node, error) {
+	switch nT := node.(type) {
+	case *intercept.Node:
+		return nT, nil
+	case *intercept.Tuple:
+		return nil, fmt.Errorf("cannot differentiate tuple %s", nT.Node)
+	}
+	return nil, fmt.Errorf("node %T has not been recorded by an intercepted graph", node)
+}
+
+// transform accumulates the cotangents of the nodes of a graph.
+type transform struct {
+	b   *graphx.Builder
+	cts map[*intercept.Node]graphx.Value
+	// err is the first error of a rule not reported by the builder.
+	err error
+}
+
+// fail records the error of a rule.
+func (t *transform) fail(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// set returns x in which the slice at index has been replaced by updates.
+// graphx does not provide Set since its index is a node.
+func (t *transform) set(x, updates, index graphx.Value) graphx.Value {
+	if t.err != nil || t.b.Err() != nil {
+		return graphx.Value{}
+	}
+	node, err := t.b.Graph().Core().Set(x.Node(), updates.Node(), index.Node())
+	if err != nil {
+		t.fail(err)
+		return graphx.Value{}
+	}
+	return t.b.Wrap(node)
+}
+
+// check returns the first error of the builder or of the rules.
+func (t *transform) check() error {
+	if err := t.b.Err(); err != nil {
+		return err
+	}
+	return t.err
+}
+
+// backward propagates the cotangent of a node to its operands.
+func (t *transform) backward(n *intercept.Node, ct graphx.Value) error {
+	call := n.Call()
+	if n.Shape() == nil {
+		return fmt.Errorf("cannot differentiate %s: unknown shape", n)
+	}
+	for i, input := range call.Inputs {
+		if input.Shape() == nil {
+			return fmt.Errorf("cannot differentiate %s: unknown shape of operand %d", n, i)
+		}
+	}
+	r, ok := rules[call.Op]
+	if !ok {
+		return fmt.Errorf("cannot differentiate %s: no gradient rule for %s", n, call.Op)
+	}
+	operandCts := r(t, n, ct)
+	if err := t.check(); err != nil {
+		return fmt.Errorf("cannot differentiate %s: %w", n, err)
+	}
+	for i, input := range call.Inputs {
+		if i >= len(operandCts) || operandCts[i].Node() == nil || !differentiable(input.Shape().DType) {
+			continue
+		}
+		if prev, ok := t.cts[input]; ok {
+			t.cts[input] = prev.Add(operandCts[i])
+		} else {
+			t.cts[input] = operandCts[i]
+		}
+	}
+	return t.check()
+}
+
+// operand returns the value of the ith operand of a node.
+func (t *transform) operand(n *intercept.Node, i int) graphx.Value {
+	return t.b.Wrap(n.Call().Inputs[i])
+}
+
+// result returns the value of a node.
+func (t *transform) result(n *intercept.Node) graphx.Value {
+	return t.b.Wrap(n)
+}
+
+// scalar returns an atomic constant of the data type of a node.
+func (t *transform) scalar(n *intercept.Node, v float64) graphx.Value {
+	return t.b.Scalar(n.Shape().DType, v)
+}
+
+// zeros returns an array of zeros.
+func (t *transform) zeros(sh *shape.Shape) graphx.Value {
+	return t.b.Scalar(sh.DType, 0).BroadcastInDim(sh)
+}
+
+// unbroadcast sums a cotangent over all its axes if the operand i of a node is atomic
+// and has been broadcast to the shape of the result.
+func (t *transform) unbroadcast(n *intercept.Node, i int, ct graphx.Value) graphx.Value {
+	if ct.Node() == nil {
+		return ct
+	}
+	rank := len(n.Shape().AxisLengths)
+	if len(n.Call().Inputs[i].Shape().AxisLengths) > 0 || rank == 0 {
+		return ct
+	}
+	return ct.ReduceSum(axisRange(0, rank)...)
+}
+
+// axisRange returns the axes from start to end excluded.
+func axisRange(start, end int) []int {
+	axes := make([]int, 0, end-start)
+	for axis := start; axis < end; axis++ {
+		axes = append(axes, axis)
+	}
+	return axes
+}
+
+// remaining returns the axes of an array of a given rank which are not in any of the exclude lists.
+func remaining(rank int, exclude ...[]int) []int {
+	var axes []int
+	for axis := range rank {
+		excluded := false
+		for _, list := range exclude {
+			excluded = excluded || slices.Contains(list, axis)
+		}
+		if !excluded {
+			axes = append(axes, axis)
+		}
+	}
+	return axes
+}
+
+// argsort returns the indices which sort a list of distinct integers.
+func argsort(list []int) []int {
+	indices := axisRange(0, len(list))
+	slices.SortFunc(indices, func(a, b int) int { return list[a] - list[b] })
+	return indices
+}
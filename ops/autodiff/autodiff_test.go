@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodiff_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/autodiff"
+	"github.com/gx-org/backend/ops/graphx"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type nop struct{}
+
+func (nop) Before(*intercept.Call) error { return nil }
+
+func (nop) After(*intercept.Call, error) {}
+
+func newBuilder(t *testing.T) (*graphx.Builder, platform.Device) {
+	t.Helper()
+	b, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	g, err := intercept.NewBackend(b, nop{}).NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graphx.New(g), dev
+}
+
+// loss builds an atomic value from the arguments of a graph.
+type loss func(b *graphx.Builder, args []graphx.Value) graphx.Value
+
+// checkGradient compares the gradient of a loss computed by autodiff with central finite differences.
+func checkGradient(t *testing.T, f loss, args ...*array.Dense[float64]) {
+	t.Helper()
+	b, dev := newBuilder(t)
+	vals := make([]graphx.Value, len(args))
+	wrt := make([]ops.Node, len(args))
+	params := make([]*shape.Shape, len(args))
+	for i, arg := range args {
+		params[i] = arg.FullShape()
+		vals[i] = b.Arg("x", params[i], i)
+		wrt[i] = vals[i].Node()
+	}
+	out := f(b, vals)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	grads, err := autodiff.Gradient(out.Node(), wrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := []*ops.OutputNode{{Node: out.Node(), Shape: shape.Scalar(dtype.Float64)}}
+	for i, grad := range grads {
+		outputs = append(outputs, &ops.OutputNode{Node: grad, Shape: params[i]})
+	}
+	runner, err := b.Graph().Compile(dev, outputs, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func() []*array.Dense[float64] {
+		handles := make([]platform.Handle, len(args))
+		for i, arg := range args {
+			handles[i] = arg.HostBuffer()
+		}
+		out, _, err := runner.Run(handles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := make([]*array.Dense[float64], len(out))
+		for i, h := range out {
+			if res[i], err = array.FromHandle[float64](h); err != nil {
+				t.Fatal(err)
+			}
+			h.Free()
+		}
+		return res
+	}
+	got := run()
+	const eps = 1e-6
+	for i, arg := range args {
+		data := arg.Flat()
+		for j := range data {
+			orig := data[j]
+			data[j] = orig + eps
+			plus := run()[0].Flat()[0]
+			data[j] = orig - eps
+			minus := run()[0].Flat()[0]
+			data[j] = orig
+			want := (plus - minus) / (2 * eps)
+			if g := got[i+1].Flat()[j]; math.Abs(g-want) > 1e-5*(1+math.Abs(want)) {
+				t.Errorf("argument %d element %d: got gradient %g but want %g", i, j, g, want)
+			}
+		}
+	}
+}
+
+func vector(t *testing.T, data ...float64) *array.Dense[float64] {
+	t.Helper()
+	a, err := array.New(data, len(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func matrix(t *testing.T, data []float64, axisLengths ...int) *array.Dense[float64] {
+	t.Helper()
+	a, err := array.New(data, axisLengths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// sum returns the sum of all the elements of a value of a given rank.
+func sum(v graphx.Value, rank int) graphx.Value {
+	axes := make([]int, rank)
+	for i := range axes {
+		axes[i] = i
+	}
+	return v.ReduceSum(axes...)
+}
+
+func TestElementwise(t *testing.T) {
+	checkGradient(t, func(b *graphx.Builder, args []graphx.Value) graphx.Value {
+		x, y := args[0], args[1]
+		two := b.Scalar(dtype.Float64, 2)
+		terms := []graphx.Value{
+			x.Exp().Mul(x.Sin()),
+			x.Log().Div(y),
+			x.Tanh().Mul(x.Sqrt()),
+			x.Logistic().Sub(y.Cos()),
+			x.Erf().Add(y.Expm1()),
+			x.Pow(y),
+			x.Atan2(y),
+			x.Rsqrt().Mul(y.Log1p()),
+			x.Sub(y).Abs(),
+			x.Rem(y).Mul(two),
+			x.Neg().Mul(y.Floor()),
+			two.Pow(y),
+		}
+		total := terms[0]
+		for _, term := range terms[1:] {
+			total = total.Add(term)
+		}
+		return sum(total, 1)
+	}, vector(t, 0.5, 1.5, 2.5), vector(t, 0.75, 0.35, 1.1))
+}
+
+func TestDotGeneral(t *testing.T) {
+	x := make([]float64, 24)
+	y := make([]float64, 40)
+	for i := range x {
+		x[i] = math.Sin(float64(i))
+	}
+	for i := range y {
+		y[i] = math.Cos(float64(i))
+	}
+	t.Run("batch", func(t *testing.T) {
+		checkGradient(t, func(b *graphx.Builder, args []graphx.Value) graphx.Value {
+			dot := args[0].DotGeneral(args[1], [2][]int{{0}, {0}}, [2][]int{{2}, {1}}, ops.DefaultPrecision)
+			return sum(dot.Mul(dot), 3)
+		}, matrix(t, x, 2, 3, 4), matrix(t, y, 2, 4, 5))
+	})
+	t.Run("unsorted", func(t *testing.T) {
+		checkGradient(t, func(b *graphx.Builder, args []graphx.Value) graphx.Value {
+			dot := args[0].DotGeneral(args[1], [2][]int{}, [2][]int{{1, 2}, {2, 0}}, ops.DefaultPrecision)
+			return sum(dot.Mul(dot), 2)
+		}, matrix(t, x, 3, 4, 2), matrix(t, y[:40], 2, 5, 4))
+	})
+}
+
+func TestStructural(t *testing.T) {
+	data := []float64{0.3, -1.2, 2.5, 0.7, 1.9, -0.4}
+	xs := func() *array.Dense[float64] { return matrix(t, append([]float64{}, data...), 2, 3) }
+	tests := []struct {
+		name string
+		f    func(b *graphx.Builder, x graphx.Value) graphx.Value
+	}{
+		{"reshape", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return sum(x.Reshape(3, 2).Exp().Mul(b.Const([][]float64{{1, 2}, {3, 4}, {5, 6}})), 2)
+		}},
+		{"transpose", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return sum(x.Transpose(1, 0).Mul(b.Const([][]float64{{1, 2}, {3, 4}, {5, 6}})), 2)
+		}},
+		{"reverse", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return sum(x.Reverse(1).Mul(x), 2)
+		}},
+		{"concat", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			c := b.Concat(1, x, x.Mul(x), x.Exp())
+			return sum(c.Mul(c.Sin()), 2)
+		}},
+		{"slice", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return sum(x.Slice(1).Exp(), 1)
+		}},
+		{"pad", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			p := x.Pad(b.Scalar(dtype.Float64, 0), []int{1, -1}, []int{2, 1}, []int{0, 0})
+			return sum(p.Mul(p.Exp()), 2)
+		}},
+		{"broadcast", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			bc := x.Reshape(2, 1, 3).BroadcastInDim(shape.Of(dtype.Float64, 3, 2, 4, 3), 1, 2, 3)
+			return sum(bc.Mul(bc.Cos()), 4)
+		}},
+		{"transposed broadcast", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			bc := x.BroadcastInDim(shape.Of(dtype.Float64, 3, 4, 2), 2, 0)
+			return sum(bc.Mul(bc.Sin()), 3)
+		}},
+		{"reductions", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return x.ReduceMax(1).Mul(x.ReduceMin(0).ReduceSum(0)).Add(x.ReduceProd(0).ReduceSum(0)).ReduceSum(0)
+		}},
+		{"cumulative", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			return sum(x.CumSum(1).Mul(x.CumProd(0)).Sin(), 2)
+		}},
+		{"select", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			pred := x.Greater(b.Scalar(dtype.Float64, 0.5))
+			return sum(pred.Select(x.Exp(), x.Mul(x)), 2)
+		}},
+		{"atomic", func(b *graphx.Builder, x graphx.Value) graphx.Value {
+			s := x.ReduceSum(0, 1)
+			return sum(s.Mul(x).Sub(x.Div(s)), 2)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkGradient(t, func(b *graphx.Builder, args []graphx.Value) graphx.Value {
+				return test.f(b, args[0])
+			}, xs())
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	checkGradient(t, func(b *graphx.Builder, args []graphx.Value) graphx.Value {
+		index := b.Const(int32(1))
+		if err := b.Err(); err != nil {
+			t.Fatal(err)
+		}
+		set, err := b.Graph().Core().Set(args[0].Node(), args[1].Exp().Node(), index.Node())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sum(b.Wrap(set).Mul(args[0]), 2)
+	}, matrix(t, []float64{1, 2, 3, 4, 5, 6}, 3, 2), vector(t, 0.5, -0.5))
+}
+
+func TestErrors(t *testing.T) {
+	b, _ := newBuilder(t)
+	x := b.Arg("x", shape.Of(dtype.Float64, 3), 0)
+	sorted := x.Sort(0, false)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := autodiff.Gradient(sorted.Node(), []ops.Node{x.Node()}); err == nil || !strings.Contains(err.Error(), "non-atomic") {
+		t.Errorf("got error %v but want an error about a non-atomic output", err)
+	}
+	out := sum(sorted, 1)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := autodiff.Gradient(out.Node(), []ops.Node{x.Node()}); err == nil || !strings.Contains(err.Error(), "no gradient rule for core.Sort") {
+		t.Errorf("got error %v but want an error about the missing rule of core.Sort", err)
+	}
+	// Nodes on which the output does not depend do not need a rule.
+	grads, err := autodiff.Gradient(sum(x.Exp(), 1).Node(), []ops.Node{x.Node()})
+	if err != nil || len(grads) != 1 {
+		t.Errorf("got gradients %v and error %v but want one gradient", grads, err)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodiff
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/graphx"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+func init() {
+	rules = map[ops.OpID]rule{
+		// Core operations.
+		ops.OpUnary:          unaryRule,
+		ops.OpBinary:         binaryRule,
+		ops.OpReshape:        reshapeRule,
+		ops.OpConcat:         concatRule,
+		ops.OpCast:           castRule,
+		ops.OpSlice:          sliceRule,
+		ops.OpSet:            setRule,
+		ops.OpDotGeneral:     dotGeneralRule,
+		ops.OpSelect:         selectRule,
+		ops.OpBroadcastInDim: broadcastInDimRule,
+		ops.OpReduceSum:      reduceSumRule,
+		ops.OpReduceProd:     reduceProdRule,
+		ops.OpReduceMax:      reduceExtremumRule,
+		ops.OpReduceMin:      reduceExtremumRule,
+		ops.OpTranspose:      transposeRule,
+		ops.OpReverse:        reverseRule,
+		ops.OpPad:            padRule,
+
+		// Num operations.
+		ops.OpCumSum:  cumSumRule,
+		ops.OpCumProd: cumProdRule,
+
+		// Math operations.
+		ops.OpAbs: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(x.Sign())
+		}),
+		ops.OpAtan2: atan2Rule,
+		ops.OpCos: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(x.Sin()).Neg()
+		}),
+		ops.OpErf: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			scale := t.scalar(n, 2/math.Sqrt(math.Pi))
+			return ct.Mul(scale).Mul(x.Mul(x).Neg().Exp())
+		}),
+		ops.OpExp: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(out)
+		}),
+		ops.OpExpm1: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(out.Add(t.scalar(n, 1)))
+		}),
+		ops.OpLog: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Div(x)
+		}),
+		ops.OpLog1p: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Div(x.Add(t.scalar(n, 1)))
+		}),
+		ops.OpLogistic: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(out).Mul(t.scalar(n, 1).Sub(out))
+		}),
+		ops.OpPow: powRule,
+		ops.OpRsqrt: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(out).Div(x).Mul(t.scalar(n, -0.5))
+		}),
+		ops.OpSin: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(x.Cos())
+		}),
+		ops.OpSqrt: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Div(out).Mul(t.scalar(n, 0.5))
+		}),
+		ops.OpTanh: elementwise(func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value {
+			return ct.Mul(t.scalar(n, 1).Sub(out.Mul(out)))
+		}),
+	}
+	// Piecewise constant functions have a zero gradient.
+	for _, op := range []ops.OpID{ops.OpCeil, ops.OpFloor, ops.OpRound, ops.OpSign} {
+		rules[op] = zeroRule
+	}
+}
+
+// elementwise returns the rule of an element-wise function of one operand given its derivative.
+func elementwise(vjp func(t *transform, n *intercept.Node, x, out, ct graphx.Value) graphx.Value) rule {
+	return func(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+		return []graphx.Value{vjp(t, n, t.operand(n, 0), t.result(n), ct)}
+	}
+}
+
+func zeroRule(*transform, *intercept.Node, graphx.Value) []graphx.Value {
+	return nil
+}
+
+func unaryRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	switch n.Call().Attr("op").(ops.UnaryOperator) {
+	case ops.Plus:
+		return []graphx.Value{ct}
+	case ops.Neg:
+		return []graphx.Value{ct.Neg()}
+	}
+	return nil
+}
+
+func binaryRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	x, y, out := t.operand(n, 0), t.operand(n, 1), t.result(n)
+	var ctX, ctY graphx.Value
+	switch n.Call().Attr("op").(ops.BinaryOperator) {
+	case ops.Add:
+		ctX, ctY = ct, ct
+	case ops.Sub:
+		ctX, ctY = ct, ct.Neg()
+	case ops.Mul:
+		ctX, ctY = ct.Mul(y), ct.Mul(x)
+	case ops.Div:
+		ctX, ctY = ct.Div(y), ct.Mul(out).Div(y).Neg()
+	case ops.Rem:
+		// x = q*y + out where q is x/y truncated toward zero.
+		ctX, ctY = ct, ct.Mul(x.Sub(out).Div(y)).Neg()
+	case ops.Pow:
+		return powRule(t, n, ct)
+	default:
+		return nil
+	}
+	return []graphx.Value{t.unbroadcast(n, 0, ctX), t.unbroadcast(n, 1, ctY)}
+}
+
+func powRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	x, y, out := t.operand(n, 0), t.operand(n, 1), t.result(n)
+	one := t.scalar(n, 1)
+	ctX := ct.Mul(y).Mul(x.Pow(y.Sub(one)))
+	ctY := ct.Mul(out).Mul(x.Log())
+	return []graphx.Value{t.unbroadcast(n, 0, ctX), t.unbroadcast(n, 1, ctY)}
+}
+
+func atan2Rule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	y, x := t.operand(n, 0), t.operand(n, 1)
+	norm := x.Mul(x).Add(y.Mul(y))
+	return []graphx.Value{
+		t.unbroadcast(n, 0, ct.Mul(x).Div(norm)),
+		t.unbroadcast(n, 1, ct.Mul(y).Div(norm).Neg()),
+	}
+}
+
+func reshapeRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{ct.Reshape(n.Call().Inputs[0].Shape().AxisLengths...)}
+}
+
+func castRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{ct.Cast(n.Call().Inputs[0].Shape().DType)}
+}
+
+func transposeRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{ct.Transpose(argsort(n.Call().Attr("permutation").([]int))...)}
+}
+
+func reverseRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{ct.Reverse(n.Call().Attr("axes").([]int)...)}
+}
+
+// pad pads a cotangent with zeros: low elements before and high elements after each axis.
+// Negative values remove elements.
+func (t *transform) pad(n *intercept.Node, ct graphx.Value, low, high []int) graphx.Value {
+	return ct.Pad(t.scalar(n, 0), low, high, make([]int, len(low)))
+}
+
+// negate returns the opposite of a list of values.
+func negate(vals []int) []int {
+	res := make([]int, len(vals))
+	for i, v := range vals {
+		res[i] = -v
+	}
+	return res
+}
+
+func concatRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	axis := n.Call().Attr("axis").(int)
+	total := n.Shape().AxisLengths[axis]
+	cts := make([]graphx.Value, len(n.Call().Inputs))
+	offset := 0
+	for i, input := range n.Call().Inputs {
+		length := input.Shape().AxisLengths[axis]
+		low, high := make([]int, len(n.Shape().AxisLengths)), make([]int, len(n.Shape().AxisLengths))
+		low[axis], high[axis] = -offset, -(total - offset - length)
+		cts[i] = t.pad(n, ct, low, high)
+		offset += length
+	}
+	return cts
+}
+
+func padRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	call := n.Call()
+	if slices.ContainsFunc(call.Attr("interior").([]int), func(v int) bool { return v != 0 }) {
+		t.fail(fmt.Errorf("interior padding is not supported"))
+		return nil
+	}
+	ctX := t.pad(n, ct, negate(call.Attr("low").([]int)), negate(call.Attr("high").([]int)))
+	all := axisRange(0, len(n.Shape().AxisLengths))
+	ctValue := ct.ReduceSum(all...).Sub(ctX.ReduceSum(all...))
+	return []graphx.Value{ctX, ctValue}
+}
+
+func sliceRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	xShape := n.Call().Inputs[0].Shape()
+	index := n.Call().Attr("index").(int)
+	rank := len(xShape.AxisLengths)
+	low, high := make([]int, rank), make([]int, rank)
+	low[0], high[0] = index, xShape.AxisLengths[0]-index-1
+	expanded := ct.Reshape(append([]int{1}, xShape.AxisLengths[1:]...)...)
+	return []graphx.Value{t.pad(n, expanded, low, high)}
+}
+
+func setRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	xShape, updatesShape, indexShape := n.Call().Inputs[0].Shape(), n.Call().Inputs[1].Shape(), n.Call().Inputs[2].Shape()
+	index := t.operand(n, 2)
+	ctX := t.set(ct, t.zeros(updatesShape), index)
+	// The cotangent of the updates is the slice of ct at index, selected with a one-hot mask.
+	positions := t.b.Iota(&shape.Shape{DType: indexShape.DType, AxisLengths: xShape.AxisLengths[:1]}, 0)
+	mask := positions.Equal(index.Reshape()).Cast(xShape.DType).BroadcastInDim(xShape, 0)
+	return []graphx.Value{ctX, ct.Mul(mask).ReduceSum(0)}
+}
+
+func selectRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	pred, zeros := t.operand(n, 0), t.zeros(n.Shape())
+	return []graphx.Value{{}, pred.Select(ct, zeros), pred.Select(zeros, ct)}
+}
+
+func dotGeneralRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	call := n.Call()
+	batch, contract := call.Attr("batchAxes").([2][]int), call.Attr("reduceAxes").([2][]int)
+	precision := call.Attr("precision").(ops.Precision)
+	x, y := t.operand(n, 0), t.operand(n, 1)
+	xRank, yRank := len(call.Inputs[0].Shape().AxisLengths), len(call.Inputs[1].Shape().AxisLengths)
+	// The axes of the result are the batch axes followed by the kept axes of x and of y.
+	xKept := remaining(xRank, contract[0], batch[0])
+	yKept := remaining(yRank, contract[1], batch[1])
+	ctBatch := axisRange(0, len(batch[0]))
+	ctX := axisRange(len(batch[0]), len(batch[0])+len(xKept))
+	ctY := axisRange(len(batch[0])+len(xKept), len(n.Shape().AxisLengths))
+	return []graphx.Value{
+		transposeDot(ct, y, ctBatch, batch[1], ctY, yKept, batch[0], xKept, contract[0], contract[1], precision),
+		transposeDot(ct, x, ctBatch, batch[0], ctX, xKept, batch[1], yKept, contract[1], contract[0], precision),
+	}
+}
+
+// transposeDot returns the cotangent of an operand of a dot product given the cotangent ct
+// of the result and the other operand. ctOther are the axes of ct corresponding to the kept
+// axes of other. The cotangent is the dot product of ct and other along these axes,
+// transposed back to the axes of the operand.
+func transposeDot(ct, other graphx.Value, ctBatch, otherBatch, ctOther, otherKept, batch, kept, contract, otherContract []int, precision ops.Precision) graphx.Value {
+	prod := ct.DotGeneral(other, [2][]int{ctBatch, otherBatch}, [2][]int{ctOther, otherKept}, precision)
+	// The axes of prod are the batch axes, the kept axes of the operand and
+	// the contracted axes of the operand sorted in the order of the axes of other.
+	contractSorted := make([]int, len(contract))
+	for i, j := range argsort(otherContract) {
+		contractSorted[i] = contract[j]
+	}
+	axes := append(append(slices.Clone(batch), kept...), contractSorted...)
+	return prod.Transpose(argsort(axes)...)
+}
+
+func broadcastInDimRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	xShape := n.Call().Inputs[0].Shape()
+	axes := n.Call().Attr("broadcastAxes").([]int)
+	rank := len(n.Shape().AxisLengths)
+	// Sum over the new axes and over the axes of length 1 which have been expanded.
+	var summed []int
+	for axis := range rank {
+		i := slices.Index(axes, axis)
+		if i < 0 || (xShape.AxisLengths[i] == 1 && n.Shape().AxisLengths[axis] != 1) {
+			summed = append(summed, axis)
+		}
+	}
+	if len(summed) > 0 {
+		ct = ct.ReduceSum(summed...)
+	}
+	// The remaining axes are in increasing order of the axes of the result.
+	var kept []int
+	for i, axis := range axes {
+		if !slices.Contains(summed, axis) {
+			kept = append(kept, i)
+		}
+	}
+	keptAxes := make([]int, len(kept))
+	for j, i := range kept {
+		keptAxes[j] = axes[i]
+	}
+	if !slices.IsSorted(keptAxes) {
+		ct = ct.Transpose(argsort(argsort(keptAxes))...)
+	}
+	return []graphx.Value{ct.Reshape(xShape.AxisLengths...)}
+}
+
+// expand broadcasts the result of a reduction of the operand of a node back to the shape of the operand.
+func (t *transform) expand(n *intercept.Node, v graphx.Value) graphx.Value {
+	xShape := n.Call().Inputs[0].Shape()
+	kept := remaining(len(xShape.AxisLengths), n.Call().Attr("axes").([]int))
+	return v.BroadcastInDim(xShape, kept...)
+}
+
+func reduceSumRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{t.expand(n, ct)}
+}
+
+func reduceProdRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	return []graphx.Value{t.expand(n, ct.Mul(t.result(n))).Div(t.operand(n, 0))}
+}
+
+// reduceExtremumRule distributes the cotangent equally between the elements equal to the extremum.
+func reduceExtremumRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	x := t.operand(n, 0)
+	axes := n.Call().Attr("axes").([]int)
+	mask := x.Equal(t.expand(n, t.result(n))).Cast(n.Shape().DType)
+	count := mask.ReduceSum(axes...)
+	return []graphx.Value{mask.Mul(t.expand(n, ct.Div(count)))}
+}
+
+func cumSumRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	axis := n.Call().Attr("axis").(int)
+	return []graphx.Value{ct.Reverse(axis).CumSum(axis).Reverse(axis)}
+}
+
+// cumProdRule assumes that the elements of the operand are not zero.
+func cumProdRule(t *transform, n *intercept.Node, ct graphx.Value) []graphx.Value {
+	axis := n.Call().Attr("axis").(int)
+	sum := ct.Mul(t.result(n)).Reverse(axis).CumSum(axis).Reverse(axis)
+	return []graphx.Value{sum.Div(t.operand(n, 0))}
+}
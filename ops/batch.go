@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/platform"
+)
+
+// BatchRunner is implemented by runners able to execute many independent argument sets
+// in a single call, for example by pipelining the transfers and the launches of every run.
+type BatchRunner interface {
+	Runner
+
+	// RunBatch runs the graph once for each argument set and returns the results in the same order.
+	// No handles are returned if an error occurs.
+	RunBatch(args [][]platform.Handle) ([]RunResult, error)
+}
+
+// RunBatch runs a graph once for each argument set and returns the results in the same order.
+// If the runner does not implement BatchRunner, the argument sets are run one after the other.
+// No handles are returned if an error occurs.
+func RunBatch(runner Runner, args [][]platform.Handle) ([]RunResult, error) {
+	if br, ok := runner.(BatchRunner); ok {
+		return br.RunBatch(args)
+	}
+	results := make([]RunResult, 0, len(args))
+	for i, set := range args {
+		out, traces, err := runner.Run(set)
+		if err != nil {
+			freeResults(results)
+			return nil, fmt.Errorf("cannot run argument set %d: %w", i, err)
+		}
+		results = append(results, RunResult{Out: out, Traces: traces})
+	}
+	return results, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import "github.com/gx-org/backend/dtype"
+
+// OpID identifies an operation of a builder.
+// Its value is the name of the builder followed by the name of the method.
+type OpID string
+
+// Operations of the core builder.
+const (
+	OpConstant       OpID = "core.Constant"
+	OpTuple          OpID = "core.Tuple"
+	OpCall           OpID = "core.Call"
+	OpSubgraph       OpID = "core.Subgraph"
+	OpArgument       OpID = "core.Argument"
+	OpUnary          OpID = "core.Unary"
+	OpBinary         OpID = "core.Binary"
+	OpReshape        OpID = "core.Reshape"
+	OpConcat         OpID = "core.Concat"
+	OpCast           OpID = "core.Cast"
+	OpSlice          OpID = "core.Slice"
+	OpSet            OpID = "core.Set"
+	OpDotGeneral     OpID = "core.DotGeneral"
+	OpWhile          OpID = "core.While"
+	OpBroadcastInDim OpID = "core.BroadcastInDim"
+	OpQuantize       OpID = "core.Quantize"
+	OpDequantize     OpID = "core.Dequantize"
+	OpReduceSum      OpID = "core.ReduceSum"
+	OpReduceProd     OpID = "core.ReduceProd"
+	OpReduceMax      OpID = "core.ReduceMax"
+	OpReduceMin      OpID = "core.ReduceMin"
+	OpReduce         OpID = "core.Reduce"
+	OpGather         OpID = "core.Gather"
+	OpScatter        OpID = "core.Scatter"
+	OpTranspose      OpID = "core.Transpose"
+	OpReverse        OpID = "core.Reverse"
+	OpPad            OpID = "core.Pad"
+	OpCond           OpID = "core.Cond"
+	OpSelect         OpID = "core.Select"
+	OpSort           OpID = "core.Sort"
+	OpArgSort        OpID = "core.ArgSort"
+	OpTopK           OpID = "core.TopK"
+)
+
+// Operations of the dtype builder.
+const (
+	OpBitcast OpID = "dtype.Bitcast"
+)
+
+// Operations of the num builder.
+const (
+	OpIota    OpID = "num.Iota"
+	OpArgmax  OpID = "num.Argmax"
+	OpArgmin  OpID = "num.Argmin"
+	OpCumSum  OpID = "num.CumSum"
+	OpCumProd OpID = "num.CumProd"
+)
+
+// Operations of the nn builder.
+const (
+	OpConvGeneralDilated OpID = "nn.ConvGeneralDilated"
+	OpReduceWindow       OpID = "nn.ReduceWindow"
+	OpMaxPool            OpID = "nn.MaxPool"
+	OpAvgPool            OpID = "nn.AvgPool"
+)
+
+// Operations of the rand builder.
+const (
+	OpRngBitGenerator OpID = "rand.RngBitGenerator"
+	OpUniform         OpID = "rand.Uniform"
+	OpNormal          OpID = "rand.Normal"
+)
+
+// Operations of the math builder.
+const (
+	OpAbs      OpID = "math.Abs"
+	OpAtan2    OpID = "math.Atan2"
+	OpCeil     OpID = "math.Ceil"
+	OpComplex  OpID = "math.Complex"
+	OpConj     OpID = "math.Conj"
+	OpCos      OpID = "math.Cos"
+	OpErf      OpID = "math.Erf"
+	OpExp      OpID = "math.Exp"
+	OpExpm1    OpID = "math.Expm1"
+	OpFloor    OpID = "math.Floor"
+	OpImag     OpID = "math.Imag"
+	OpIsInf    OpID = "math.IsInf"
+	OpIsNaN    OpID = "math.IsNaN"
+	OpLog      OpID = "math.Log"
+	OpLog1p    OpID = "math.Log1p"
+	OpLogistic OpID = "math.Logistic"
+	OpPow      OpID = "math.Pow"
+	OpReal     OpID = "math.Real"
+	OpRound    OpID = "math.Round"
+	OpRsqrt    OpID = "math.Rsqrt"
+	OpSign     OpID = "math.Sign"
+	OpSin      OpID = "math.Sin"
+	OpSqrt     OpID = "math.Sqrt"
+	OpTanh     OpID = "math.Tanh"
+)
+
+// Operations of the dist builder.
+const (
+	OpAllReduce         OpID = "dist.AllReduce"
+	OpAllGather         OpID = "dist.AllGather"
+	OpReduceScatter     OpID = "dist.ReduceScatter"
+	OpCollectivePermute OpID = "dist.CollectivePermute"
+)
+
+// Capabilities reports what a backend supports, such that programs can be
+// rejected or decomposed before being compiled.
+type Capabilities interface {
+	// SupportsDType returns true if arrays of the data type can be created and computed.
+	SupportsDType(dtype.DataType) bool
+
+	// SupportsOp returns true if the operation is implemented.
+	SupportsOp(OpID) bool
+
+	// MaxRank returns the maximum number of axes of an array, or -1 if there is no limit.
+	MaxRank() int
+}
+
+// AllCapabilities reports that every data type and operation is supported without rank limit.
+type AllCapabilities struct{}
+
+var _ Capabilities = AllCapabilities{}
+
+// SupportsDType returns true for every valid data type.
+func (AllCapabilities) SupportsDType(dt dtype.DataType) bool {
+	return dtype.IsValid(dt)
+}
+
+// SupportsOp returns true for all operations.
+func (AllCapabilities) SupportsOp(OpID) bool {
+	return true
+}
+
+// MaxRank returns -1.
+func (AllCapabilities) MaxRank() int {
+	return -1
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/platform"
+)
+
+type (
+	// RunResult are the handles returned by a run.
+	RunResult struct {
+		Out, Traces []platform.DeviceHandle
+	}
+
+	// Capture records a sequence of runs.
+	Capture interface {
+		// Run records a run of a runner.
+		// Handles returned by earlier runs of the same capture can be passed as arguments.
+		// The returned handles are owned by the replayable. Depending on the backend,
+		// they may only hold valid data once the capture has been replayed.
+		Run(runner Runner, args []platform.Handle) (out, traces []platform.DeviceHandle, err error)
+	}
+
+	// Replayable runs again a captured sequence of runs.
+	Replayable interface {
+		// Replay runs the captured sequence and returns the results of every run, in capture order.
+		// Arguments which have not been returned by a captured run are read again at each replay.
+		// The returned handles are owned by the replayable: they are valid until the next replay
+		// or until the replayable is freed.
+		Replay() ([]RunResult, error)
+
+		// Free releases the replayable and the handles of its last replay.
+		Free()
+	}
+
+	// CaptureRunner is implemented by runners able to capture a sequence of runs into a single
+	// executable, for example with CUDA Graphs, removing the launch overhead of every run.
+	CaptureRunner interface {
+		Runner
+
+		// Capture calls record to record runs of the runner and of other runners of the same backend.
+		Capture(record func(Capture) error) (Replayable, error)
+	}
+)
+
+// CaptureRuns records the runs made by record into a replayable sequence.
+// If runner does not implement CaptureRunner, the runs are executed while being recorded
+// and every replay runs them again one after the other.
+func CaptureRuns(runner Runner, record func(Capture) error) (Replayable, error) {
+	if cr, ok := runner.(CaptureRunner); ok {
+		return cr.Capture(record)
+	}
+	seq := &sequence{}
+	if err := record(seq); err != nil {
+		seq.Free()
+		return nil, err
+	}
+	return seq, nil
+}
+
+// argRef refers to the argument of a captured run.
+type argRef struct {
+	// handle is the argument if it has not been returned by a captured run.
+	handle platform.Handle
+	// run is the index of the run returning the argument, -1 for external arguments.
+	run int
+	// traced is true if the argument is a trace of the run.
+	traced bool
+	// index of the argument in the outputs or the traces of the run.
+	index int
+}
+
+type capturedRun struct {
+	runner Runner
+	args   []argRef
+}
+
+// sequence records runs to replay them one after the other.
+type sequence struct {
+	runs    []capturedRun
+	results []RunResult
+	freed   bool
+}
+
+var (
+	_ Capture    = (*sequence)(nil)
+	_ Replayable = (*sequence)(nil)
+)
+
+func (s *sequence) ref(arg platform.Handle) argRef {
+	for run, res := range s.results {
+		for i, h := range res.Out {
+			if platform.Handle(h) == arg {
+				return argRef{run: run, index: i}
+			}
+		}
+		for i, h := range res.Traces {
+			if platform.Handle(h) == arg {
+				return argRef{run: run, traced: true, index: i}
+			}
+		}
+	}
+	return argRef{handle: arg, run: -1}
+}
+
+func (s *sequence) Run(runner Runner, args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	run := capturedRun{runner: runner, args: make([]argRef, len(args))}
+	for i, arg := range args {
+		run.args[i] = s.ref(arg)
+	}
+	if out, traces, err = runner.Run(args); err != nil {
+		return nil, nil, err
+	}
+	s.runs = append(s.runs, run)
+	s.results = append(s.results, RunResult{Out: out, Traces: traces})
+	return out, traces, nil
+}
+
+func (s *sequence) Replay() ([]RunResult, error) {
+	if s.freed {
+		return nil, fmt.Errorf("cannot replay a freed capture")
+	}
+	results := make([]RunResult, 0, len(s.runs))
+	for i, run := range s.runs {
+		args := make([]platform.Handle, len(run.args))
+		for j, ref := range run.args {
+			switch {
+			case ref.run < 0:
+				args[j] = ref.handle
+			case ref.traced:
+				args[j] = results[ref.run].Traces[ref.index]
+			default:
+				args[j] = results[ref.run].Out[ref.index]
+			}
+		}
+		out, traces, err := run.runner.Run(args)
+		if err != nil {
+			freeResults(results)
+			return nil, fmt.Errorf("cannot replay run %d: %w", i, err)
+		}
+		results = append(results, RunResult{Out: out, Traces: traces})
+	}
+	freeResults(s.results)
+	s.results = results
+	return results, nil
+}
+
+func (s *sequence) Free() {
+	if s.freed {
+		return
+	}
+	s.freed = true
+	freeResults(s.results)
+	s.results = nil
+}
+
+func freeResults(results []RunResult) {
+	for _, res := range results {
+		freeHandles(res.Out)
+		freeHandles(res.Traces)
+	}
+}
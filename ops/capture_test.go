@@ -0,0 +1,180 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// increment returns its argument plus one.
+type increment struct {
+	dev  *platformtest.Device
+	runs int
+}
+
+func (r *increment) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	r.runs++
+	x := args[0].(*platformtest.Handle).Data()[0]
+	y, err := r.dev.Send([]byte{x + 1}, shape.Scalar(dtype.Bool))
+	if err != nil {
+		return nil, nil, err
+	}
+	return []platform.DeviceHandle{y}, nil, nil
+}
+
+func TestCaptureRuns(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	x, err := dev.Send([]byte{1}, shape.Scalar(dtype.Bool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &increment{dev: dev}
+	replay, err := ops.CaptureRuns(runner, func(c ops.Capture) error {
+		y, _, err := c.Run(runner, []platform.Handle{x})
+		if err != nil {
+			return err
+		}
+		_, _, err = c.Run(runner, []platform.Handle{y[0]})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		results, err := replay.Replay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := results[1].Out[0].(*platformtest.Handle).Data()[0]; got != 3 {
+			t.Errorf("got %d but want 3", got)
+		}
+	}
+	if runner.runs != 6 {
+		t.Errorf("got %d runs but want 6", runner.runs)
+	}
+	replay.Free()
+	if live := plat.Counts().Live; live != 1 {
+		t.Errorf("got %d live handles but want 1", live)
+	}
+	if _, err := replay.Replay(); err == nil {
+		t.Errorf("expected an error when replaying a freed capture")
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	runner := &increment{dev: dev}
+	var args [][]platform.Handle
+	for i := range 3 {
+		x, err := dev.Send([]byte{byte(i)}, shape.Scalar(dtype.Bool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, []platform.Handle{x})
+	}
+	results, err := ops.RunBatch(runner, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		if got, want := res.Out[0].(*platformtest.Handle).Data()[0], byte(i+1); got != want {
+			t.Errorf("argument set %d: got %d but want %d", i, got, want)
+		}
+	}
+	if runner.runs != 3 {
+		t.Errorf("got %d runs but want 3", runner.runs)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	plat := platformtest.New(2)
+	stages := []ops.Stage{
+		{Runner: &increment{dev: plat.MockDevice(0)}, Device: plat.MockDevice(0)},
+		{Runner: &increment{dev: plat.MockDevice(1)}, Device: plat.MockDevice(1)},
+	}
+	pipe, err := ops.NewPipeline(stages, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args [][]platform.Handle
+	for i := range 5 {
+		x, err := plat.MockDevice(0).Send([]byte{byte(i)}, shape.Scalar(dtype.Bool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer x.Free()
+		args = append(args, []platform.Handle{x})
+	}
+	results, err := pipe.Run(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		out := res.Out[0].(*platformtest.Handle)
+		if got, want := out.Data()[0], byte(i+2); got != want {
+			t.Errorf("argument set %d: got %d but want %d", i, got, want)
+		}
+		if got := out.Device().Ordinal(); got != 1 {
+			t.Errorf("argument set %d: result on device %d but want 1", i, got)
+		}
+		out.Free()
+	}
+	if got, want := plat.Counts().Live, len(args); got != want {
+		t.Errorf("got %d live handles but want %d", got, want)
+	}
+	if _, err := ops.NewPipeline(nil, 0); err == nil {
+		t.Errorf("expected an error for a pipeline without stages")
+	}
+}
+
+func TestRunToHost(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	x, err := dev.Send([]byte{1}, shape.Scalar(dtype.Bool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Free()
+	out, err := plat.Allocate(shape.Scalar(dtype.Bool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Free()
+	runner := &increment{dev: dev}
+	for range 3 {
+		if _, err := ops.RunToHost(runner, []platform.Handle{x}, []platform.HostBuffer{out}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := out.AcquireRead()[0]; got != 2 {
+		t.Errorf("got %d but want 2", got)
+	}
+	out.ReleaseRead()
+	if got := plat.Counts().Live; got != 1 {
+		t.Errorf("got %d live handles but want 1", got)
+	}
+	if _, err := ops.RunInto(runner, []platform.Handle{x}, nil); !errors.Is(err, ops.ErrOutputsUnsupported) {
+		t.Errorf("got error %v but want %v", err, ops.ErrOutputsUnsupported)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package constant creates constant nodes from Go values.
+//
+// Values can be scalars of a Go data type, or nested slices or arrays of such scalars,
+// for example float32(1), []int64{1, 2} or [2][3]float64{...}. Nested slices must be rectangular.
+package constant
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Buffer returns a host buffer holding a Go value.
+// The buffer is allocated by alloc or, if alloc is nil, in Go memory.
+func Buffer(alloc platform.Allocator, v any) (platform.HostBuffer, error) {
+	switch dt := dtype.FromReflectType(reflect.TypeOf(v)); dt {
+	case dtype.Bool:
+		return buffer[bool](alloc, v)
+	case dtype.Int:
+		return buffer[int](alloc, v)
+	case dtype.Int8:
+		return buffer[int8](alloc, v)
+	case dtype.Int16:
+		return buffer[int16](alloc, v)
+	case dtype.Int32:
+		return buffer[int32](alloc, v)
+	case dtype.Int64:
+		return buffer[int64](alloc, v)
+	case dtype.Uint8:
+		return buffer[uint8](alloc, v)
+	case dtype.Uint16:
+		return buffer[uint16](alloc, v)
+	case dtype.Uint32:
+		return buffer[uint32](alloc, v)
+	case dtype.Uint64:
+		return buffer[uint64](alloc, v)
+	case dtype.Bfloat16:
+		return buffer[dtype.Bfloat16T](alloc, v)
+	case dtype.Float16:
+		return buffer[dtype.Float16T](alloc, v)
+	case dtype.Float32:
+		return buffer[float32](alloc, v)
+	case dtype.Float64:
+		return buffer[float64](alloc, v)
+	case dtype.Complex64:
+		return buffer[complex64](alloc, v)
+	case dtype.Complex128:
+		return buffer[complex128](alloc, v)
+	}
+	return nil, fmt.Errorf("cannot create a constant from a value of type %T", v)
+}
+
+func buffer[T dtype.GoDataType](alloc platform.Allocator, v any) (platform.HostBuffer, error) {
+	a, err := array.FromNested[T](v)
+	if err != nil {
+		return nil, err
+	}
+	if alloc == nil {
+		return a.HostBuffer(), nil
+	}
+	return a.ToHostBuffer(alloc)
+}
+
+// Shape returns the shape of the constant created from a Go value.
+func Shape(v any) (*shape.Shape, error) {
+	typ := reflect.TypeOf(v)
+	dt := dtype.FromReflectType(typ)
+	if dt == dtype.Invalid {
+		return nil, fmt.Errorf("cannot create a constant from a value of type %T", v)
+	}
+	var axisLengths []int
+	for val := reflect.ValueOf(v); val.Kind() == reflect.Slice || val.Kind() == reflect.Array; {
+		axisLengths = append(axisLengths, val.Len())
+		if val.Len() == 0 {
+			break
+		}
+		val = val.Index(0)
+	}
+	return shape.Of(dt, axisLengths...), nil
+}
+
+// New returns a constant node holding a Go value in a graph.
+// The host buffer of the constant is allocated by the platform of the graph
+// if the platform implements platform.Allocator.
+func New(g ops.Graph, v any) (ops.Node, error) {
+	alloc, _ := g.Platform().(platform.Allocator)
+	buf, err := Buffer(alloc, v)
+	if err != nil {
+		return nil, err
+	}
+	return g.Core().Constant(buf)
+}
+
+// Scalar returns an atomic constant of a given data type converted from a float64.
+func Scalar(g ops.Graph, dt dtype.DataType, v float64) (ops.Node, error) {
+	val, err := convert(dt, v)
+	if err != nil {
+		return nil, err
+	}
+	return New(g, val)
+}
+
+func convert(dt dtype.DataType, v float64) (any, error) {
+	switch dt {
+	case dtype.Bool:
+		return v != 0, nil
+	case dtype.Int:
+		return int(v), nil
+	case dtype.Int8:
+		return int8(v), nil
+	case dtype.Int16:
+		return int16(v), nil
+	case dtype.Int32:
+		return int32(v), nil
+	case dtype.Int64:
+		return int64(v), nil
+	case dtype.Uint8:
+		return uint8(v), nil
+	case dtype.Uint16:
+		return uint16(v), nil
+	case dtype.Uint32:
+		return uint32(v), nil
+	case dtype.Uint64:
+		return uint64(v), nil
+	case dtype.Bfloat16:
+		return dtype.BFloat16FromFloat64(v), nil
+	case dtype.Float16:
+		return dtype.Float16FromFloat64(v), nil
+	case dtype.Float32:
+		return float32(v), nil
+	case dtype.Float64:
+		return v, nil
+	case dtype.Complex64:
+		return complex(float32(v), 0), nil
+	case dtype.Complex128:
+		return complex(v, 0), nil
+	}
+	return nil, fmt.Errorf("cannot convert a scalar to data type %s", dt)
+}
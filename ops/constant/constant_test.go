@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package constant_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/constant"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+func TestBuffer(t *testing.T) {
+	plat := platformtest.New(1)
+	tests := []struct {
+		value any
+		shape string
+		data  []byte
+	}{
+		{value: float32(2), shape: "float32", data: dtype.CopyFromSlice([]float32{2})},
+		{value: []int64{1, 2}, shape: "[2]int64", data: dtype.CopyFromSlice([]int64{1, 2})},
+		{value: [][]bool{{true}, {false}}, shape: "[2][1]bool", data: []byte{1, 0}},
+		{value: [2][3]uint32{{1, 2, 3}, {4, 5, 6}}, shape: "[2][3]uint32", data: dtype.CopyFromSlice([]uint32{1, 2, 3, 4, 5, 6})},
+	}
+	for _, test := range tests {
+		sh, err := constant.Shape(test.value)
+		if err != nil {
+			t.Errorf("%T: %v", test.value, err)
+			continue
+		}
+		if sh.String() != test.shape {
+			t.Errorf("%T: got shape %s but want %s", test.value, sh, test.shape)
+		}
+		buf, err := constant.Buffer(plat, test.value)
+		if err != nil {
+			t.Errorf("%T: %v", test.value, err)
+			continue
+		}
+		if got := buf.Shape().String(); got != test.shape {
+			t.Errorf("%T: got buffer shape %s but want %s", test.value, got, test.shape)
+		}
+		if got := buf.AcquireRead(); !slices.Equal(got, test.data) {
+			t.Errorf("%T: got data %v but want %v", test.value, got, test.data)
+		}
+		buf.ReleaseRead()
+		buf.Free()
+	}
+	for _, bad := range []any{"x", [][]float32{{1}, {2, 3}}, []any{1}} {
+		if _, err := constant.Buffer(nil, bad); err == nil {
+			t.Errorf("%T: expected an error", bad)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	b := opstest.NewBackend(platformtest.New(1))
+	g, err := b.NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := constant.New(g, [][]float64{{1, 2}, {3, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := constant.Scalar(g, dtype.Int32, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := constant.Scalar(g, dtype.Int4, 3); err == nil {
+		t.Errorf("expected an error for data type %s", dtype.Int4)
+	}
+	if got, want := b.Graphs()[0].Ops(), []ops.OpID{ops.OpConstant, ops.OpConstant}; !slices.Equal(got, want) {
+		t.Errorf("got ops %v but want %v", got, want)
+	}
+}
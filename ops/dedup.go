@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/gx-org/backend/platform"
+)
+
+type (
+	// DedupStats counts the nodes reused by a Dedup.
+	DedupStats struct {
+		// Constants is the number of distinct constants created in the graph.
+		Constants int
+		// ReusedConstants is the number of calls to Constant returning an existing node.
+		ReusedConstants int
+		// BytesSaved is the total size of the constants which have not been created again.
+		BytesSaved int64
+		// Subgraphs is the number of distinct subgraphs created in the graph.
+		Subgraphs int
+		// ReusedSubgraphs is the number of calls to Subgraph returning an existing subgraph.
+		ReusedSubgraphs int
+	}
+
+	// Dedup creates constants and subgraphs in a graph, returning the existing node
+	// when a constant with the same shape and content, or a subgraph with the same key,
+	// has already been created.
+	//
+	// Nodes cannot be shared across graphs: a Dedup is bound to a single graph
+	// and a new Dedup must be used for each subgraph.
+	Dedup struct {
+		g      Graph
+		consts map[[sha256.Size]byte]Node
+		subs   map[string]*Subgraph
+		stats  DedupStats
+	}
+)
+
+// NewDedup returns a helper deduplicating the constants and subgraphs of a graph.
+func NewDedup(g Graph) *Dedup {
+	return &Dedup{
+		g:      g,
+		consts: make(map[[sha256.Size]byte]Node),
+		subs:   make(map[string]*Subgraph),
+	}
+}
+
+// Graph returns the graph in which the nodes are created.
+func (d *Dedup) Graph() Graph {
+	return d.g
+}
+
+// Constant returns a node representing a constant value. The node of a previous call
+// is returned if its value has the same shape and the same content.
+// The value is not used by the graph if an existing node is returned.
+func (d *Dedup) Constant(value platform.HostBuffer) (Node, error) {
+	key, err := constantKey(value)
+	if err != nil {
+		return nil, err
+	}
+	if node, ok := d.consts[key]; ok {
+		d.stats.ReusedConstants++
+		d.stats.BytesSaved += int64(value.Shape().ByteSize())
+		return node, nil
+	}
+	node, err := d.g.Core().Constant(value)
+	if err != nil {
+		return nil, err
+	}
+	d.consts[key] = node
+	d.stats.Constants++
+	return node, nil
+}
+
+// Subgraph returns the subgraph previously built for a key or calls build to create it.
+// The caller is responsible for choosing keys identifying subgraphs with the same content,
+// for example the name of the function and the shapes of its arguments.
+func (d *Dedup) Subgraph(key string, build func(Graph) (*Subgraph, error)) (*Subgraph, error) {
+	if sg, ok := d.subs[key]; ok {
+		d.stats.ReusedSubgraphs++
+		return sg, nil
+	}
+	sg, err := build(d.g)
+	if err != nil {
+		return nil, err
+	}
+	d.subs[key] = sg
+	d.stats.Subgraphs++
+	return sg, nil
+}
+
+// Stats returns the number of nodes created and reused so far.
+func (d *Dedup) Stats() DedupStats {
+	return d.stats
+}
+
+// constantKey hashes the shape and the content of a host buffer.
+func constantKey(value platform.HostBuffer) ([sha256.Size]byte, error) {
+	data := value.AcquireRead()
+	defer value.ReleaseRead()
+	if data == nil {
+		return [sha256.Size]byte{}, fmt.Errorf("cannot create a constant from a freed buffer: %w", platform.ErrBufferFreed)
+	}
+	h := sha256.New()
+	h.Write(value.Shape().AppendCanonical(nil))
+	h.Write(data)
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestDedup(t *testing.T) {
+	b := opstest.NewBackend(platformtest.New(1))
+	g, err := b.NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := ops.NewDedup(g)
+	vec := shape.Vector(dtype.Float32, 2)
+	values := []struct {
+		data []byte
+		sh   *shape.Shape
+	}{
+		{dtype.CopyFromSlice([]float32{1, 2}), vec},
+		{dtype.CopyFromSlice([]float32{1, 2}), vec},
+		{dtype.CopyFromSlice([]float32{1, 3}), vec},
+		{dtype.CopyFromSlice([]int32{1, 2}), shape.Vector(dtype.Int32, 2)},
+	}
+	var nodes []ops.Node
+	for _, value := range values {
+		buf, err := platform.Borrow(value.data, value.sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := d.Constant(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, node)
+	}
+	if nodes[0] != nodes[1] {
+		t.Errorf("identical constants have not been deduplicated")
+	}
+	if nodes[0] == nodes[2] || nodes[0] == nodes[3] {
+		t.Errorf("different constants share the same node")
+	}
+	builds := 0
+	build := func(g ops.Graph) (*ops.Subgraph, error) {
+		builds++
+		sub, err := g.Core().Subgraph("f", nil)
+		if err != nil {
+			return nil, err
+		}
+		return &ops.Subgraph{Graph: sub}, nil
+	}
+	for range 3 {
+		if _, err := d.Subgraph("f()", build); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if builds != 1 {
+		t.Errorf("subgraph built %d times but want 1", builds)
+	}
+	want := ops.DedupStats{Constants: 3, ReusedConstants: 1, BytesSaved: 8, Subgraphs: 1, ReusedSubgraphs: 2}
+	if got := d.Stats(); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+	if got := len(b.Graphs()[0].Nodes()); got != 3 {
+		t.Errorf("got %d nodes in the graph but want 3", got)
+	}
+}
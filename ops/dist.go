@@ -0,0 +1,281 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// DistBuilder creates collective operations exchanging data between the devices of a mesh.
+	//
+	// A graph compiled for a mesh runs on every device of the mesh, each device computing
+	// with its own shards of the arguments. Collective operations exchange data within groups
+	// of devices which differ only by their coordinates along a set of mesh axes, or along
+	// all the mesh axes if the set is empty. Devices of a group are ordered in the row-major
+	// order of the mesh.
+	DistBuilder interface {
+		// AllReduce reduces x over the devices of each group and returns the result on every device.
+		AllReduce(x Node, op platform.ReduceOp, meshAxes []string) (Node, error)
+
+		// AllGather concatenates x along an axis over the devices of each group
+		// and returns the result on every device.
+		AllGather(x Node, axis int, meshAxes []string) (Node, error)
+
+		// ReduceScatter reduces x over the devices of each group, splits the result along
+		// an axis in one block per device, and returns the ith block on the ith device of the group.
+		ReduceScatter(x Node, op platform.ReduceOp, axis int, meshAxes []string) (Node, error)
+
+		// CollectivePermute sends x between devices along a mesh axis. pairs lists
+		// the coordinates of a source and of a target device along the mesh axis:
+		// each target receives the value of x of its source. Devices which are not
+		// a target receive zeros.
+		CollectivePermute(x Node, meshAxis string, pairs [][2]int) (Node, error)
+	}
+
+	// DistGraph is implemented by graphs supporting collective operations.
+	// Collective operations can only be built if the graph has been created with WithMesh.
+	DistGraph interface {
+		Graph
+
+		// Dist returns the builder for collective operations.
+		Dist() DistBuilder
+	}
+
+	// MeshParam is a parameter of a graph compiled for a mesh.
+	MeshParam struct {
+		// Shape of the argument on each device.
+		Shape *shape.Shape
+
+		// Sharding of the argument over the devices of the mesh.
+		// A nil sharding replicates the argument.
+		Sharding platform.PartitionSpec
+	}
+
+	// MeshRunner runs a graph compiled for a mesh.
+	// Run takes arguments of their global shapes and returns outputs assembled on the first
+	// device of the mesh.
+	MeshRunner interface {
+		Runner
+
+		// RunSharded runs the graph on all the devices of the mesh and returns
+		// the outputs distributed as specified by their sharding.
+		// Arguments are split as specified by the sharding of the parameters,
+		// unless they already are sharded handles distributed as specified.
+		RunSharded(args []platform.Handle) (out, traces []*platform.ShardedHandle, err error)
+	}
+
+	// MeshCompiler is implemented by graphs able to compile for a mesh of devices.
+	MeshCompiler interface {
+		// CompileMesh compiles the graph for all the devices of a mesh.
+		CompileMesh(mesh *platform.Mesh, output, traced []*OutputNode, params []*MeshParam) (MeshRunner, error)
+	}
+)
+
+// Dist returns the builder for collective operations of a graph.
+// It returns an error if the graph does not support collective operations.
+func Dist(g Graph) (DistBuilder, error) {
+	dg, ok := g.(DistGraph)
+	if !ok {
+		return nil, fmt.Errorf("graph %T does not support collective operations", g)
+	}
+	return dg.Dist(), nil
+}
+
+// CompileMesh compiles a graph for all the devices of a mesh. If the graph does not implement
+// MeshCompiler, the graph is compiled for every device of the mesh and the runner runs
+// the compiled graphs concurrently: the graph cannot use collective operations.
+func CompileMesh(g Graph, mesh *platform.Mesh, output, traced []*OutputNode, params []*MeshParam) (MeshRunner, error) {
+	if mc, ok := g.(MeshCompiler); ok {
+		return mc.CompileMesh(mesh, output, traced, params)
+	}
+	shapes := make([]*shape.Shape, len(params))
+	for i, param := range params {
+		shapes[i] = param.Shape
+	}
+	compiled := make(map[platform.Device]Runner)
+	runners := make([]Runner, len(mesh.Devices()))
+	for i, dev := range mesh.Devices() {
+		if runners[i] = compiled[dev]; runners[i] != nil {
+			continue
+		}
+		runner, err := g.Compile(dev, output, traced, shapes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compile graph on device %d: %w", dev.Ordinal(), err)
+		}
+		compiled[dev], runners[i] = runner, runner
+	}
+	return NewSPMDRunner(mesh, output, traced, params, func(args [][]platform.Handle) (out, traces [][]platform.DeviceHandle, err error) {
+		out = make([][]platform.DeviceHandle, len(runners))
+		traces = make([][]platform.DeviceHandle, len(runners))
+		errs := make([]error, len(runners))
+		var wg sync.WaitGroup
+		for i, runner := range runners {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out[i], traces[i], errs[i] = runner.Run(args[i])
+			}()
+		}
+		wg.Wait()
+		return out, traces, JoinDeviceErrors(mesh, errs, out, traces)
+	})
+}
+
+// SPMDFunc runs a graph on all the devices of a mesh given the arguments of each device,
+// in the row-major order of the mesh, and returns the outputs and the traces of each device.
+type SPMDFunc func(args [][]platform.Handle) (out, traces [][]platform.DeviceHandle, err error)
+
+// NewSPMDRunner returns a runner distributing its arguments over the devices of a mesh,
+// calling run, and collecting the results of every device. It can be used by backends
+// to implement MeshCompiler. It returns an error if the shardings of the parameters or of
+// the outputs cannot be used on the mesh.
+func NewSPMDRunner(mesh *platform.Mesh, output, traced []*OutputNode, params []*MeshParam, run SPMDFunc) (MeshRunner, error) {
+	for i, param := range params {
+		if _, err := mesh.GlobalShape(param.Sharding, param.Shape); err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+	}
+	for i, out := range append(slices.Clone(output), traced...) {
+		if _, err := mesh.GlobalShape(out.Sharding, out.Shape); err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+	}
+	return &spmdRunner{mesh: mesh, output: output, traced: traced, params: params, run: run}, nil
+}
+
+// JoinDeviceErrors returns the first error of the devices of a mesh annotated with the device
+// ordinal, or nil if there are no errors. If there is an error, the handles returned by all
+// the devices are freed.
+func JoinDeviceErrors(mesh *platform.Mesh, errs []error, results ...[][]platform.DeviceHandle) error {
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		for _, res := range results {
+			freeAll(res)
+		}
+		return fmt.Errorf("device %d: %w", mesh.Devices()[i].Ordinal(), err)
+	}
+	return nil
+}
+
+type spmdRunner struct {
+	mesh           *platform.Mesh
+	output, traced []*OutputNode
+	params         []*MeshParam
+	run            SPMDFunc
+}
+
+func (r *spmdRunner) RunSharded(args []platform.Handle) (out, traces []*platform.ShardedHandle, err error) {
+	if len(args) != len(r.params) {
+		return nil, nil, fmt.Errorf("got %d arguments but want %d", len(args), len(r.params))
+	}
+	perDevice := make([][]platform.Handle, len(r.mesh.Devices()))
+	for i, arg := range args {
+		handles, release, err := r.mesh.Distribute(arg, r.params[i].Sharding, r.params[i].Shape)
+		if err != nil {
+			return nil, nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		defer release()
+		for d, h := range handles {
+			perDevice[d] = append(perDevice[d], h)
+		}
+	}
+	outs, tracesPerDevice, err := r.run(perDevice)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out, err = r.collect(outs, r.output); err != nil {
+		freeAll(tracesPerDevice)
+		return nil, nil, err
+	}
+	if traces, err = r.collect(tracesPerDevice, r.traced); err != nil {
+		freeSharded(out)
+		return nil, nil, err
+	}
+	return out, traces, nil
+}
+
+// collect collects the results of every device into sharded handles.
+// All handles are freed if an error occurs.
+func (r *spmdRunner) collect(perDevice [][]platform.DeviceHandle, outs []*OutputNode) ([]*platform.ShardedHandle, error) {
+	res := make([]*platform.ShardedHandle, 0, len(outs))
+	for i, out := range outs {
+		handles := make([]platform.DeviceHandle, len(perDevice))
+		for d := range perDevice {
+			handles[d] = perDevice[d][i]
+		}
+		sharded, err := r.mesh.Collect(handles, out.Sharding)
+		if err != nil {
+			freeSharded(res)
+			for _, devHandles := range perDevice {
+				freeHandles(devHandles[i+1:])
+			}
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		res = append(res, sharded)
+	}
+	return res, nil
+}
+
+// Run runs the graph on all the devices of the mesh and assembles the results on the first device.
+func (r *spmdRunner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	shardedOut, shardedTraces, err := r.RunSharded(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer freeSharded(shardedOut)
+	defer freeSharded(shardedTraces)
+	dev := r.mesh.Devices()[0]
+	if out, err = assemble(shardedOut, dev); err != nil {
+		return nil, nil, err
+	}
+	if traces, err = assemble(shardedTraces, dev); err != nil {
+		freeHandles(out)
+		return nil, nil, err
+	}
+	return out, traces, nil
+}
+
+func assemble(sharded []*platform.ShardedHandle, dev platform.Device) ([]platform.DeviceHandle, error) {
+	handles := make([]platform.DeviceHandle, 0, len(sharded))
+	for _, h := range sharded {
+		handle, err := h.ToDevice(dev)
+		if err != nil {
+			freeHandles(handles)
+			return nil, err
+		}
+		handles = append(handles, handle)
+	}
+	return handles, nil
+}
+
+func freeAll(perDevice [][]platform.DeviceHandle) {
+	for _, handles := range perDevice {
+		freeHandles(handles)
+	}
+}
+
+func freeSharded(handles []*platform.ShardedHandle) {
+	for _, h := range handles {
+		h.Free()
+	}
+}
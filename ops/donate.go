@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// DonationCompiler is implemented by graphs able to reuse the memory of arguments to store outputs.
+type DonationCompiler interface {
+	// CompileDonated compiles the graph as Compile does. The runner consumes the arguments
+	// of the parameters listed in donated which have been donated with platform.Donate:
+	// their memory can be reused to store outputs of the same shape.
+	CompileDonated(dev platform.Device, output, traced []*OutputNode, params []*shape.Shape, donated []int) (Runner, error)
+}
+
+// CheckDonated returns an error if the indices of donated parameters are out of range or repeated.
+func CheckDonated(donated []int, numParams int) error {
+	for i, index := range donated {
+		if index < 0 || index >= numParams {
+			return fmt.Errorf("donated parameter %d out of range [0, %d)", index, numParams)
+		}
+		if slices.Index(donated, index) != i {
+			return fmt.Errorf("parameter %d donated more than once", index)
+		}
+	}
+	return nil
+}
+
+// CompileDonated compiles a graph for a device, donating the arguments of some parameters.
+// If the graph does not implement DonationCompiler, the runner consumes the donated arguments
+// and frees them once the run has completed, without reusing their memory.
+func CompileDonated(g Graph, dev platform.Device, output, traced []*OutputNode, params []*shape.Shape, donated []int) (Runner, error) {
+	if err := CheckDonated(donated, len(params)); err != nil {
+		return nil, err
+	}
+	if dc, ok := g.(DonationCompiler); ok {
+		return dc.CompileDonated(dev, output, traced, params, donated)
+	}
+	runner, err := g.Compile(dev, output, traced, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(donated) == 0 {
+		return runner, nil
+	}
+	return &donatingRunner{Runner: runner, donated: slices.Clone(donated)}, nil
+}
+
+// ConsumeDonated consumes the arguments of donated parameters which have been donated
+// with platform.Donate. It returns the arguments in which the consumed handles have been
+// replaced by their underlying handles, and the consumed handles, nil for the arguments
+// which have not been consumed. It returns platform.ErrHandleConsumed if an argument
+// has already been consumed.
+func ConsumeDonated(args []platform.Handle, donated []int) ([]platform.Handle, []platform.DeviceHandle, error) {
+	args = slices.Clone(args)
+	consumed := make([]platform.DeviceHandle, len(args))
+	for _, index := range donated {
+		if index >= len(args) {
+			continue
+		}
+		handle, ok := args[index].(*platform.DonatedHandle)
+		if !ok {
+			continue
+		}
+		h, err := handle.Consume()
+		if err != nil {
+			freeHandles(consumed)
+			return nil, nil, fmt.Errorf("argument %d: %w", index, err)
+		}
+		args[index], consumed[index] = h, h
+	}
+	return args, consumed, nil
+}
+
+// donatingRunner frees the donated arguments once a run has completed.
+type donatingRunner struct {
+	Runner
+	donated []int
+}
+
+func (r *donatingRunner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	args, consumed, err := ConsumeDonated(args, r.donated)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer freeHandles(consumed)
+	return r.Runner.Run(args)
+}
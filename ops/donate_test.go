@@ -0,0 +1,140 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestCheckDonated(t *testing.T) {
+	tests := []struct {
+		donated []int
+		ok      bool
+	}{
+		{donated: nil, ok: true},
+		{donated: []int{1, 0}, ok: true},
+		{donated: []int{2}},
+		{donated: []int{-1}},
+		{donated: []int{1, 1}},
+	}
+	for _, test := range tests {
+		if err := ops.CheckDonated(test.donated, 2); (err == nil) != test.ok {
+			t.Errorf("CheckDonated(%v, 2) returned error %v", test.donated, err)
+		}
+	}
+}
+
+func TestCompileDonated(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	g, err := opstest.NewBackend(plat).NewOps("donate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Of(dtype.Float32, 2)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := ops.CompileDonated(g, dev, []*ops.OutputNode{{Node: x, Shape: sh}}, nil, []*shape.Shape{sh}, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func() *platformtest.Handle {
+		h, err := dev.Send(dtype.FromSlice([]float32{1, 2}), sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h.(*platformtest.Handle)
+	}
+
+	// A handle which has not been donated is not consumed: the output is a copy.
+	kept := send()
+	defer kept.Free()
+	for range 2 {
+		out, _, err := runner.Run([]platform.Handle{kept})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out[0] == platform.DeviceHandle(kept) {
+			t.Errorf("output stored in an argument which has not been donated")
+		}
+		out[0].Free()
+		if kept.Freed() {
+			t.Fatalf("argument which has not been donated has been freed by the run")
+		}
+	}
+
+	// A donated handle is consumed by the run and cannot be used afterwards.
+	arg := send()
+	donated := platform.Donate(arg)
+	out, _, err := runner.Run([]platform.Handle{donated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out[0].Free()
+	if !arg.Freed() {
+		t.Errorf("donated argument has not been released by the run")
+	}
+	if _, _, err := runner.Run([]platform.Handle{donated}); !errors.Is(err, platform.ErrHandleConsumed) {
+		t.Errorf("got error %v but want %v", err, platform.ErrHandleConsumed)
+	}
+	if live := plat.Counts().Live; live != 1 {
+		t.Errorf("got %d live handles but want 1", live)
+	}
+}
+
+func TestConsumeDonated(t *testing.T) {
+	plat := platformtest.New(1)
+	sh := shape.Of(dtype.Float32, 2)
+	var handles []platform.DeviceHandle
+	for range 3 {
+		h, err := plat.MockDevice(0).Send(dtype.FromSlice([]float32{1, 2}), sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer h.Free()
+		handles = append(handles, h)
+	}
+	donated := platform.Donate(handles[0])
+	args := []platform.Handle{donated, handles[1], platform.Donate(handles[2])}
+	got, consumed, err := ops.ConsumeDonated(args, []int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != handles[0] || consumed[0] != handles[0] {
+		t.Errorf("donated argument 0 has not been consumed")
+	}
+	if got[1] != handles[1] || consumed[1] != nil {
+		t.Errorf("argument 1 which has not been donated has been consumed")
+	}
+	if got[2] != args[2] || consumed[2] != nil || !platform.IsDonated(args[2]) {
+		t.Errorf("argument 2 of a parameter which is not donated has been consumed")
+	}
+	if args[0] != donated {
+		t.Errorf("ConsumeDonated modified its arguments")
+	}
+	if _, _, err := ops.ConsumeDonated(args, []int{0}); !errors.Is(err, platform.ErrHandleConsumed) {
+		t.Errorf("got error %v but want %v", err, platform.ErrHandleConsumed)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// ErrDynamicShapes is returned by backends which cannot build or compile graphs
+// with dynamic axis lengths (see shape.AxisLength).
+var ErrDynamicShapes = errors.New("backend does not support dynamic shapes")
+
+// CheckStatic returns an error wrapping ErrDynamicShapes if one of the shapes has a dynamic axis.
+// Backends without dynamic shapes call it on the shapes of arguments and parameters.
+func CheckStatic(shapes ...*shape.Shape) error {
+	for _, sh := range shapes {
+		if sh != nil && sh.IsDynamic() {
+			return fmt.Errorf("shape %s: %w", sh, ErrDynamicShapes)
+		}
+	}
+	return nil
+}
+
+// Bindings returns the values of the symbols of the parameters of a graph given
+// the arguments of a run. Backends with dynamic shapes call it before every run.
+func Bindings(params []*shape.Shape, args []platform.Handle) (map[string]int, error) {
+	if len(args) != len(params) {
+		return nil, fmt.Errorf("got %d arguments but want %d", len(args), len(params))
+	}
+	values := make(map[string]int)
+	for i, param := range params {
+		if _, err := param.Bindings(args[i].Shape(), values); err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i, err)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import "fmt"
+
+// GatherDims are the dimension numbers of a gather operation.
+//
+// The indices array holds index vectors along IndexVectorAxis: each vector is the start of
+// a slice of the operand. The other axes of indices are batch axes, which are also axes of
+// the output. The output has one axis per batch axis of indices and one axis per axis of
+// the slices which has not been collapsed.
+type GatherDims struct {
+	// OffsetAxes are the axes of the output indexing into the slices, in increasing order.
+	OffsetAxes []int
+	// CollapsedAxes are the axes of the operand removed from the slices.
+	// The size of the slices along these axes must be 1.
+	CollapsedAxes []int
+	// StartIndexMap maps the elements of an index vector to axes of the operand.
+	StartIndexMap []int
+	// IndexVectorAxis is the axis of indices holding the index vectors.
+	// If it is equal to the rank of indices, the index vectors have a single element.
+	IndexVectorAxis int
+}
+
+// String returns a string representation of the dimension numbers.
+func (d GatherDims) String() string {
+	return fmt.Sprintf("{offset:%v collapsed:%v start:%v index:%d}", d.OffsetAxes, d.CollapsedAxes, d.StartIndexMap, d.IndexVectorAxis)
+}
+
+// ScatterDims are the dimension numbers of a scatter operation.
+//
+// The indices array holds index vectors along IndexVectorAxis: each vector is the start of
+// a window of the operand which is replaced by a window of the updates. The other axes of
+// indices are batch axes, which are also the axes of updates not in UpdateWindowAxes.
+type ScatterDims struct {
+	// UpdateWindowAxes are the axes of updates indexing into the windows, in increasing order.
+	UpdateWindowAxes []int
+	// InsertedWindowAxes are the axes of the operand of size 1 in the windows
+	// which are not in the updates.
+	InsertedWindowAxes []int
+	// ScatterAxesToOperandAxes maps the elements of an index vector to axes of the operand.
+	ScatterAxesToOperandAxes []int
+	// IndexVectorAxis is the axis of indices holding the index vectors.
+	// If it is equal to the rank of indices, the index vectors have a single element.
+	IndexVectorAxis int
+}
+
+// String returns a string representation of the dimension numbers.
+func (d ScatterDims) String() string {
+	return fmt.Sprintf("{window:%v inserted:%v scatter:%v index:%d}", d.UpdateWindowAxes, d.InsertedWindowAxes, d.ScatterAxesToOperandAxes, d.IndexVectorAxis)
+}
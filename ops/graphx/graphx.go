@@ -0,0 +1,189 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphx provides chainable wrappers over the builders of a graph.
+//
+// Errors are accumulated by a Builder instead of being returned by every call:
+// once a call has failed, all the following calls do nothing and Err returns
+// the first error. For example:
+//
+//	b := graphx.New(g)
+//	x := b.Arg("x", shape.Scalar(dtype.Float32), 0)
+//	y := b.Const(float32(2)).Mul(x).Add(x).Exp()
+//	if err := b.Err(); err != nil {
+//		return err
+//	}
+//	node := y.Node()
+package graphx
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/constant"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Builder creates nodes in a graph and records the first error.
+	// A Builder is not safe for concurrent use.
+	Builder struct {
+		g   ops.Graph
+		err error
+	}
+
+	// Value is a node created by a Builder.
+	// Its methods create new nodes using the value as their first operand.
+	Value struct {
+		b    *Builder
+		node ops.Node
+	}
+
+	// hostBufferer is implemented by arrays, such as array.Dense, sharing their memory as a host buffer.
+	hostBufferer interface {
+		HostBuffer() platform.HostBuffer
+	}
+)
+
+// New returns a builder creating nodes in a graph.
+func New(g ops.Graph) *Builder {
+	return &Builder{g: g}
+}
+
+// Graph returns the graph in which the nodes are created.
+func (b *Builder) Graph() ops.Graph {
+	return b.g
+}
+
+// Err returns the first error encountered by the builder, or nil.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Wrap returns a value for a node created outside of the builder.
+func (b *Builder) Wrap(node ops.Node) Value {
+	return Value{b: b, node: node}
+}
+
+// build calls f if no error has been recorded and records its error otherwise.
+func (b *Builder) build(name string, f func() (ops.Node, error)) Value {
+	if b.err != nil {
+		return Value{b: b}
+	}
+	node, err := f()
+	if err != nil {
+		b.err = fmt.Errorf("graphx: %s: %w", name, err)
+		return Value{b: b}
+	}
+	return Value{b: b, node: node}
+}
+
+// Arg returns an argument of the graph.
+func (b *Builder) Arg(name string, sh *shape.Shape, index int) Value {
+	return b.build("Argument", func() (ops.Node, error) {
+		return b.g.Core().Argument(name, sh, index)
+	})
+}
+
+// Const returns a constant. The value can be a scalar or nested slices of a Go data type
+// (for example float32 or [][]int64), a host buffer, or an array sharing its memory
+// as a host buffer such as array.Dense. Untyped constants, such as 2.0, are float64.
+func (b *Builder) Const(v any) Value {
+	return b.build("Constant", func() (ops.Node, error) {
+		buf, err := hostBuffer(v)
+		if err != nil {
+			return nil, err
+		}
+		return b.g.Core().Constant(buf)
+	})
+}
+
+// Scalar returns an atomic constant of a given data type converted from a float64.
+func (b *Builder) Scalar(dt dtype.DataType, v float64) Value {
+	return b.build("Constant", func() (ops.Node, error) {
+		return constant.Scalar(b.g, dt, v)
+	})
+}
+
+// Tuple returns a tuple of values.
+func (b *Builder) Tuple(vals ...Value) Value {
+	return b.build("Tuple", func() (ops.Node, error) {
+		return b.g.Core().Tuple(nodes(vals))
+	})
+}
+
+// Cond calls trueBranch with args if pred is true, falseBranch otherwise.
+func (b *Builder) Cond(pred Value, trueBranch, falseBranch *ops.Subgraph, args ...Value) Value {
+	return b.build("Cond", func() (ops.Node, error) {
+		return b.g.Core().Cond(pred.node, trueBranch, falseBranch, nodes(args)...)
+	})
+}
+
+// Concat concatenates values along an axis.
+func (b *Builder) Concat(axis int, vals ...Value) Value {
+	return b.build("Concat", func() (ops.Node, error) {
+		return b.g.Core().Concat(axis, nodes(vals))
+	})
+}
+
+// Iota returns an array filled with increasing values along an axis.
+func (b *Builder) Iota(sh *shape.Shape, axis int) Value {
+	return b.build("Iota", func() (ops.Node, error) {
+		return b.g.Num().Iota(sh, axis)
+	})
+}
+
+// RngBitGenerator returns the next state of a random number generator and random bits.
+func (b *Builder) RngBitGenerator(algorithm ops.RngAlgorithm, state Value, sh *shape.Shape) (next, bits Value) {
+	res := b.build("RngBitGenerator", func() (ops.Node, error) {
+		return b.g.Rand().RngBitGenerator(algorithm, state.node, sh)
+	})
+	return res.Element(0), res.Element(1)
+}
+
+// Uniform returns the next state of a random number generator and values uniformly distributed in [0, 1).
+func (b *Builder) Uniform(sh *shape.Shape, state Value) (next, values Value) {
+	res := b.build("Uniform", func() (ops.Node, error) {
+		return b.g.Rand().Uniform(sh, state.node)
+	})
+	return res.Element(0), res.Element(1)
+}
+
+// Normal returns the next state of a random number generator and values of the standard normal distribution.
+func (b *Builder) Normal(sh *shape.Shape, state Value) (next, values Value) {
+	res := b.build("Normal", func() (ops.Node, error) {
+		return b.g.Rand().Normal(sh, state.node)
+	})
+	return res.Element(0), res.Element(1)
+}
+
+func nodes(vals []Value) []ops.Node {
+	res := make([]ops.Node, len(vals))
+	for i, v := range vals {
+		res[i] = v.node
+	}
+	return res
+}
+
+func hostBuffer(v any) (platform.HostBuffer, error) {
+	switch vT := v.(type) {
+	case platform.HostBuffer:
+		return vT, nil
+	case hostBufferer:
+		return vT.HostBuffer(), nil
+	}
+	return constant.Buffer(nil, v)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphx_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/graphx"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+type nop struct{}
+
+func (nop) Before(*intercept.Call) error { return nil }
+
+func (nop) After(*intercept.Call, error) {}
+
+func newBuilder(t *testing.T) (*graphx.Builder, *opstest.Backend) {
+	t.Helper()
+	inner := opstest.NewBackend(platformtest.New(1))
+	g, err := intercept.NewBackend(inner, nop{}).NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graphx.New(g), inner
+}
+
+func TestChain(t *testing.T) {
+	b, inner := newBuilder(t)
+	x := b.Arg("x", shape.Vector(dtype.Float32, 3), 0)
+	y := b.Const([]float32{1, 2, 3}).Mul(x).Add(b.Scalar(dtype.Float32, 2)).Exp().Less(x)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := y.Node().(*intercept.Node).Shape().String(), "[3]bool"; got != want {
+		t.Errorf("got shape %s but want %s", got, want)
+	}
+	want := []ops.OpID{ops.OpArgument, ops.OpConstant, ops.OpBinary, ops.OpConstant, ops.OpBinary, ops.OpExp, ops.OpBinary}
+	if got := inner.Graphs()[0].Ops(); !slices.Equal(got, want) {
+		t.Errorf("got ops %v but want %v", got, want)
+	}
+}
+
+func TestError(t *testing.T) {
+	b, inner := newBuilder(t)
+	x := b.Arg("x", shape.Vector(dtype.Float32, 3), 0)
+	y := x.Add(b.Const("two")).Neg().Reshape(3, 1)
+	if y.Node() != nil {
+		t.Errorf("got node %v after an error", y.Node())
+	}
+	if err := b.Err(); err == nil || !strings.Contains(err.Error(), "Constant") {
+		t.Errorf("got error %v but want an error about Constant", err)
+	}
+	if got := len(inner.Graphs()[0].Nodes()); got != 1 {
+		t.Errorf("got %d nodes but want 1", got)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	b, _ := newBuilder(t)
+	x := graphx.TypedArg[float32](b, "x", 0, 3)
+	y := x.Mul(graphx.TypedConst[float32](b, 1, 2, 3)).Less(x)
+	n := graphx.Cast[int32](y)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n.Node().(*intercept.Node).Shape().String(), "[3]int32"; got != want {
+		t.Errorf("got shape %s but want %s", got, want)
+	}
+	if graphx.As[float32](n.Value); b.Err() == nil {
+		t.Errorf("expected an error converting an int32 value to float32")
+	}
+}
+
+func TestReduce(t *testing.T) {
+	b, inner := newBuilder(t)
+	x := b.Arg("x", shape.Of(dtype.Float32, 2, 3), 0)
+	sum := x.ReduceSum(1).ReduceMax(0)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sum.Node().(*intercept.Node).Shape().String(), "float32"; got != want {
+		t.Errorf("got shape %s but want %s", got, want)
+	}
+	want := []ops.OpID{ops.OpArgument, ops.OpReduceSum, ops.OpReduceMax}
+	if got := inner.Graphs()[0].Ops(); !slices.Equal(got, want) {
+		t.Errorf("got ops %v but want %v", got, want)
+	}
+}
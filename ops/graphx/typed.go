@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphx
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Typed is a value whose elements have the Go type T, such that operations
+	// mixing data types are rejected by the Go compiler.
+	Typed[T dtype.GoDataType] struct {
+		Value
+	}
+
+	// shaper is implemented by nodes reporting their shape.
+	shaper interface {
+		Shape() *shape.Shape
+	}
+)
+
+// As returns a typed value. An error is recorded by the builder if the node
+// of the value reports a shape with a data type other than T.
+func As[T dtype.GoDataType](v Value) Typed[T] {
+	if sh, ok := v.node.(shaper); ok && sh.Shape() != nil {
+		if got, want := sh.Shape().DType, dtype.Generic[T](); got != want {
+			v = v.b.build("As", func() (ops.Node, error) {
+				return nil, fmt.Errorf("got a value of data type %s but want %s", got, want)
+			})
+		}
+	}
+	return Typed[T]{Value: v}
+}
+
+// TypedArg returns an argument of the graph with elements of type T.
+func TypedArg[T dtype.GoDataType](b *Builder, name string, index int, axisLengths ...int) Typed[T] {
+	return Typed[T]{Value: b.Arg(name, shape.Of(dtype.Generic[T](), axisLengths...), index)}
+}
+
+// TypedConst returns an atomic constant, or a vector if more than one value is given.
+func TypedConst[T dtype.GoDataType](b *Builder, vals ...T) Typed[T] {
+	if len(vals) == 1 {
+		return Typed[T]{Value: b.Const(vals[0])}
+	}
+	return Typed[T]{Value: b.Const(vals)}
+}
+
+// Cast converts the elements of a typed value to another Go type.
+func Cast[U, T dtype.GoDataType](x Typed[T]) Typed[U] {
+	return Typed[U]{Value: x.Value.Cast(dtype.Generic[U]())}
+}
+
+// Neg returns -x.
+func (x Typed[T]) Neg() Typed[T] { return Typed[T]{x.Value.Neg()} }
+
+// Add returns x + y.
+func (x Typed[T]) Add(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Add(y.Value)} }
+
+// Sub returns x - y.
+func (x Typed[T]) Sub(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Sub(y.Value)} }
+
+// Mul returns x * y.
+func (x Typed[T]) Mul(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Mul(y.Value)} }
+
+// Div returns x / y.
+func (x Typed[T]) Div(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Div(y.Value)} }
+
+// Rem returns x % y.
+func (x Typed[T]) Rem(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Rem(y.Value)} }
+
+// Pow returns x raised to the power y.
+func (x Typed[T]) Pow(y Typed[T]) Typed[T] { return Typed[T]{x.Value.Pow(y.Value)} }
+
+// Equal returns x == y.
+func (x Typed[T]) Equal(y Typed[T]) Typed[bool] { return Typed[bool]{x.Value.Equal(y.Value)} }
+
+// NotEqual returns x != y.
+func (x Typed[T]) NotEqual(y Typed[T]) Typed[bool] { return Typed[bool]{x.Value.NotEqual(y.Value)} }
+
+// Less returns x < y.
+func (x Typed[T]) Less(y Typed[T]) Typed[bool] { return Typed[bool]{x.Value.Less(y.Value)} }
+
+// LessEqual returns x <= y.
+func (x Typed[T]) LessEqual(y Typed[T]) Typed[bool] { return Typed[bool]{x.Value.LessEqual(y.Value)} }
+
+// Greater returns x > y.
+func (x Typed[T]) Greater(y Typed[T]) Typed[bool] { return Typed[bool]{x.Value.Greater(y.Value)} }
+
+// GreaterEqual returns x >= y.
+func (x Typed[T]) GreaterEqual(y Typed[T]) Typed[bool] {
+	return Typed[bool]{x.Value.GreaterEqual(y.Value)}
+}
+
+// Reshape returns x with different axis lengths.
+func (x Typed[T]) Reshape(axisLengths ...int) Typed[T] {
+	return Typed[T]{x.Value.Reshape(axisLengths...)}
+}
+
+// ReduceSum sums the elements of x along a set of axes.
+func (x Typed[T]) ReduceSum(axes ...int) Typed[T] { return Typed[T]{x.Value.ReduceSum(axes...)} }
+
+// ReduceProd multiplies the elements of x along a set of axes.
+func (x Typed[T]) ReduceProd(axes ...int) Typed[T] { return Typed[T]{x.Value.ReduceProd(axes...)} }
+
+// ReduceMax returns the maximum of the elements of x along a set of axes.
+func (x Typed[T]) ReduceMax(axes ...int) Typed[T] { return Typed[T]{x.Value.ReduceMax(axes...)} }
+
+// ReduceMin returns the minimum of the elements of x along a set of axes.
+func (x Typed[T]) ReduceMin(axes ...int) Typed[T] { return Typed[T]{x.Value.ReduceMin(axes...)} }
+
+// Transpose permutes the axes of x.
+func (x Typed[T]) Transpose(permutation ...int) Typed[T] {
+	return Typed[T]{x.Value.Transpose(permutation...)}
+}
+
+// Reverse reverses the order of the elements of x along a set of axes.
+func (x Typed[T]) Reverse(axes ...int) Typed[T] { return Typed[T]{x.Value.Reverse(axes...)} }
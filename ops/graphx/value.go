@@ -0,0 +1,389 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphx
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+// Node returns the node of the value, or nil if an error has been recorded by the builder.
+func (v Value) Node() ops.Node {
+	return v.node
+}
+
+// Builder returns the builder which created the value.
+func (v Value) Builder() *Builder {
+	return v.b
+}
+
+// Output returns the value as an output of the graph with a given shape.
+func (v Value) Output(sh *shape.Shape) *ops.OutputNode {
+	return &ops.OutputNode{Node: v.node, Shape: sh}
+}
+
+func (v Value) unary(op ops.UnaryOperator) Value {
+	return v.b.build(op.String(), func() (ops.Node, error) {
+		return v.b.g.Core().UnaryOp(op, v.node)
+	})
+}
+
+func (v Value) binary(op ops.BinaryOperator, y Value) Value {
+	return v.b.build(op.String(), func() (ops.Node, error) {
+		return v.b.g.Core().BinaryOp(op, v.node, y.node)
+	})
+}
+
+// Neg returns -v.
+func (v Value) Neg() Value { return v.unary(ops.Neg) }
+
+// Not returns !v.
+func (v Value) Not() Value { return v.unary(ops.Not) }
+
+// BitNot returns ^v.
+func (v Value) BitNot() Value { return v.unary(ops.BitNot) }
+
+// Add returns v + y.
+func (v Value) Add(y Value) Value { return v.binary(ops.Add, y) }
+
+// Sub returns v - y.
+func (v Value) Sub(y Value) Value { return v.binary(ops.Sub, y) }
+
+// Mul returns v * y.
+func (v Value) Mul(y Value) Value { return v.binary(ops.Mul, y) }
+
+// Div returns v / y.
+func (v Value) Div(y Value) Value { return v.binary(ops.Div, y) }
+
+// Rem returns v % y.
+func (v Value) Rem(y Value) Value { return v.binary(ops.Rem, y) }
+
+// Pow returns v raised to the power y.
+func (v Value) Pow(y Value) Value { return v.binary(ops.Pow, y) }
+
+// And returns v && y for booleans.
+func (v Value) And(y Value) Value { return v.binary(ops.LogicalAnd, y) }
+
+// Or returns v || y for booleans.
+func (v Value) Or(y Value) Value { return v.binary(ops.LogicalOr, y) }
+
+// Equal returns v == y.
+func (v Value) Equal(y Value) Value { return v.binary(ops.Equal, y) }
+
+// NotEqual returns v != y.
+func (v Value) NotEqual(y Value) Value { return v.binary(ops.NotEqual, y) }
+
+// Less returns v < y.
+func (v Value) Less(y Value) Value { return v.binary(ops.Less, y) }
+
+// LessEqual returns v <= y.
+func (v Value) LessEqual(y Value) Value { return v.binary(ops.LessEqual, y) }
+
+// Greater returns v > y.
+func (v Value) Greater(y Value) Value { return v.binary(ops.Greater, y) }
+
+// GreaterEqual returns v >= y.
+func (v Value) GreaterEqual(y Value) Value { return v.binary(ops.GreaterEqual, y) }
+
+// Reshape returns v with different axis lengths.
+func (v Value) Reshape(axisLengths ...int) Value {
+	return v.b.build("Reshape", func() (ops.Node, error) {
+		return v.b.g.Core().Reshape(v.node, axisLengths)
+	})
+}
+
+// Cast converts the values of v to another data type.
+func (v Value) Cast(target dtype.DataType) Value {
+	return v.b.build("Cast", func() (ops.Node, error) {
+		return v.b.g.Core().Cast(v.node, target)
+	})
+}
+
+// Slice returns the element at an index of the outermost axis of v.
+func (v Value) Slice(index int) Value {
+	return v.b.build("Slice", func() (ops.Node, error) {
+		return v.b.g.Core().Slice(v.node, index)
+	})
+}
+
+// BroadcastInDim broadcasts v to a shape, the axes of v being mapped to broadcastAxes.
+func (v Value) BroadcastInDim(sh *shape.Shape, broadcastAxes ...int) Value {
+	return v.b.build("BroadcastInDim", func() (ops.Node, error) {
+		return v.b.g.Core().BroadcastInDim(v.node, sh, broadcastAxes)
+	})
+}
+
+// DotGeneral returns a general dot product between v and y.
+func (v Value) DotGeneral(y Value, batchAxes, reduceAxes [2][]int, precision ops.Precision) Value {
+	return v.b.build("DotGeneral", func() (ops.Node, error) {
+		return v.b.g.Core().DotGeneral(v.node, y.node, batchAxes, reduceAxes, precision)
+	})
+}
+
+// ReduceSum sums the elements of v along a set of axes.
+func (v Value) ReduceSum(axes ...int) Value {
+	return v.b.build("ReduceSum", func() (ops.Node, error) {
+		return v.b.g.Core().ReduceSum(v.node, axes)
+	})
+}
+
+// ReduceProd multiplies the elements of v along a set of axes.
+func (v Value) ReduceProd(axes ...int) Value {
+	return v.b.build("ReduceProd", func() (ops.Node, error) {
+		return v.b.g.Core().ReduceProd(v.node, axes)
+	})
+}
+
+// ReduceMax returns the maximum of the elements of v along a set of axes.
+func (v Value) ReduceMax(axes ...int) Value {
+	return v.b.build("ReduceMax", func() (ops.Node, error) {
+		return v.b.g.Core().ReduceMax(v.node, axes)
+	})
+}
+
+// ReduceMin returns the minimum of the elements of v along a set of axes.
+func (v Value) ReduceMin(axes ...int) Value {
+	return v.b.build("ReduceMin", func() (ops.Node, error) {
+		return v.b.g.Core().ReduceMin(v.node, axes)
+	})
+}
+
+// Reduce reduces the elements of v along a set of axes with a subgraph combining
+// two atomic values, starting from init.
+func (v Value) Reduce(body *ops.Subgraph, init Value, axes ...int) Value {
+	return v.b.build("Reduce", func() (ops.Node, error) {
+		return v.b.g.Core().Reduce(body, init.node, v.node, axes)
+	})
+}
+
+// Gather reads slices of v starting at the index vectors of indices.
+func (v Value) Gather(indices Value, dims ops.GatherDims, sliceSizes ...int) Value {
+	return v.b.build("Gather", func() (ops.Node, error) {
+		return v.b.g.Core().Gather(v.node, indices.node, dims, sliceSizes)
+	})
+}
+
+// Scatter replaces windows of v starting at the index vectors of indices with updates.
+func (v Value) Scatter(indices, updates Value, dims ops.ScatterDims) Value {
+	return v.b.build("Scatter", func() (ops.Node, error) {
+		return v.b.g.Core().Scatter(v.node, indices.node, updates.node, dims)
+	})
+}
+
+// Transpose permutes the axes of v.
+// The axis i of the result is the axis permutation[i] of v.
+func (v Value) Transpose(permutation ...int) Value {
+	return v.b.build("Transpose", func() (ops.Node, error) {
+		return v.b.g.Core().Transpose(v.node, permutation)
+	})
+}
+
+// Reverse reverses the order of the elements of v along a set of axes.
+func (v Value) Reverse(axes ...int) Value {
+	return v.b.build("Reverse", func() (ops.Node, error) {
+		return v.b.g.Core().Reverse(v.node, axes)
+	})
+}
+
+// Pad pads v with an atomic value: low elements before, high elements after
+// and interior elements between each element along each axis.
+func (v Value) Pad(value Value, low, high, interior []int) Value {
+	return v.b.build("Pad", func() (ops.Node, error) {
+		return v.b.g.Core().Pad(v.node, value.node, low, high, interior)
+	})
+}
+
+// Select chooses element-wise between onTrue where v is true and onFalse elsewhere.
+// v is a boolean array of the shape of onTrue and onFalse, or an atomic boolean.
+func (v Value) Select(onTrue, onFalse Value) Value {
+	return v.b.build("Select", func() (ops.Node, error) {
+		return v.b.g.Core().Select(v.node, onTrue.node, onFalse.node)
+	})
+}
+
+// Argmax returns the int32 indices of the maximum elements of v along an axis.
+func (v Value) Argmax(axis int) Value {
+	return v.b.build("Argmax", func() (ops.Node, error) {
+		return v.b.g.Num().Argmax(v.node, axis)
+	})
+}
+
+// Argmin returns the int32 indices of the minimum elements of v along an axis.
+func (v Value) Argmin(axis int) Value {
+	return v.b.build("Argmin", func() (ops.Node, error) {
+		return v.b.g.Num().Argmin(v.node, axis)
+	})
+}
+
+// CumSum returns the cumulative sum of v along an axis.
+func (v Value) CumSum(axis int) Value {
+	return v.b.build("CumSum", func() (ops.Node, error) {
+		return v.b.g.Num().CumSum(v.node, axis)
+	})
+}
+
+// CumProd returns the cumulative product of v along an axis.
+func (v Value) CumProd(axis int) Value {
+	return v.b.build("CumProd", func() (ops.Node, error) {
+		return v.b.g.Num().CumProd(v.node, axis)
+	})
+}
+
+// Sort sorts the elements of v along an axis.
+func (v Value) Sort(axis int, descending bool) Value {
+	return v.b.build("Sort", func() (ops.Node, error) {
+		return v.b.g.Core().Sort(v.node, axis, descending)
+	})
+}
+
+// ArgSort returns the int32 indices sorting the elements of v along an axis.
+func (v Value) ArgSort(axis int, descending bool) Value {
+	return v.b.build("ArgSort", func() (ops.Node, error) {
+		return v.b.g.Core().ArgSort(v.node, axis, descending)
+	})
+}
+
+// TopK returns the k largest elements of v along its last axis and their int32 indices.
+func (v Value) TopK(k int) (values, indices Value) {
+	res := v.b.build("TopK", func() (ops.Node, error) {
+		return v.b.g.Core().TopK(v.node, k)
+	})
+	return res.Element(0), res.Element(1)
+}
+
+// Conv convolves v with a kernel. See ops.NNBuilder.ConvGeneralDilated for the parameters.
+func (v Value) Conv(kernel Value, dims ops.ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int) Value {
+	return v.b.build("Conv", func() (ops.Node, error) {
+		return v.b.g.NN().ConvGeneralDilated(v.node, kernel.node, dims, strides, padding, inputDilation, kernelDilation, featureGroupCount, ops.DefaultPrecision)
+	})
+}
+
+// ReduceWindow reduces the windows of v with a subgraph combining two atomic values, starting from init.
+func (v Value) ReduceWindow(body *ops.Subgraph, init Value, window ops.Window) Value {
+	return v.b.build("ReduceWindow", func() (ops.Node, error) {
+		return v.b.g.NN().ReduceWindow(body, init.node, v.node, window)
+	})
+}
+
+// MaxPool returns the maximum of the windows of v.
+func (v Value) MaxPool(window ops.Window) Value {
+	return v.b.build("MaxPool", func() (ops.Node, error) {
+		return v.b.g.NN().MaxPool(v.node, window)
+	})
+}
+
+// AvgPool returns the average of the windows of v.
+func (v Value) AvgPool(window ops.Window) Value {
+	return v.b.build("AvgPool", func() (ops.Node, error) {
+		return v.b.g.NN().AvgPool(v.node, window)
+	})
+}
+
+// Element returns the ith element of a tuple.
+func (v Value) Element(i int) Value {
+	return v.b.build("Element", func() (ops.Node, error) {
+		tuple, ok := v.node.(ops.Tuple)
+		if !ok {
+			return nil, fmt.Errorf("node %T is not a tuple", v.node)
+		}
+		return tuple.Element(i)
+	})
+}
+
+func (v Value) math(name string, f func(ops.MathBuilder, ops.Node) (ops.Node, error)) Value {
+	return v.b.build(name, func() (ops.Node, error) {
+		return f(v.b.g.Math(), v.node)
+	})
+}
+
+// Abs returns the absolute value of v.
+func (v Value) Abs() Value { return v.math("Abs", ops.MathBuilder.Abs) }
+
+// Atan2 returns the arc tangent of v/x.
+func (v Value) Atan2(x Value) Value {
+	return v.b.build("Atan2", func() (ops.Node, error) {
+		return v.b.g.Math().Atan2(v.node, x.node)
+	})
+}
+
+// Ceil returns the ceiling of v.
+func (v Value) Ceil() Value { return v.math("Ceil", ops.MathBuilder.Ceil) }
+
+// Complex returns the complex number v + i*im.
+func (v Value) Complex(im Value) Value {
+	return v.b.build("Complex", func() (ops.Node, error) {
+		return v.b.g.Math().Complex(v.node, im.node)
+	})
+}
+
+// Conj returns the complex conjugate of v.
+func (v Value) Conj() Value { return v.math("Conj", ops.MathBuilder.Conj) }
+
+// Cos returns the cosine of v.
+func (v Value) Cos() Value { return v.math("Cos", ops.MathBuilder.Cos) }
+
+// Erf returns the error function of v.
+func (v Value) Erf() Value { return v.math("Erf", ops.MathBuilder.Erf) }
+
+// Exp returns the exponential of v.
+func (v Value) Exp() Value { return v.math("Exp", ops.MathBuilder.Exp) }
+
+// Expm1 returns Exp(v)-1.
+func (v Value) Expm1() Value { return v.math("Expm1", ops.MathBuilder.Expm1) }
+
+// Floor returns the floor of v.
+func (v Value) Floor() Value { return v.math("Floor", ops.MathBuilder.Floor) }
+
+// Imag returns the imaginary part of v.
+func (v Value) Imag() Value { return v.math("Imag", ops.MathBuilder.Imag) }
+
+// IsInf returns true where v is positive or negative infinity.
+func (v Value) IsInf() Value { return v.math("IsInf", ops.MathBuilder.IsInf) }
+
+// IsNaN returns true where v is NaN.
+func (v Value) IsNaN() Value { return v.math("IsNaN", ops.MathBuilder.IsNaN) }
+
+// Log returns the natural logarithm of v.
+func (v Value) Log() Value { return v.math("Log", ops.MathBuilder.Log) }
+
+// Log1p returns log(1+v).
+func (v Value) Log1p() Value { return v.math("Log1p", ops.MathBuilder.Log1p) }
+
+// Logistic returns 1/(1+exp(-v)).
+func (v Value) Logistic() Value { return v.math("Logistic", ops.MathBuilder.Logistic) }
+
+// Real returns the real part of v.
+func (v Value) Real() Value { return v.math("Real", ops.MathBuilder.Real) }
+
+// Round returns the nearest integer of v.
+func (v Value) Round() Value { return v.math("Round", ops.MathBuilder.Round) }
+
+// Rsqrt returns 1/sqrt(v).
+func (v Value) Rsqrt() Value { return v.math("Rsqrt", ops.MathBuilder.Rsqrt) }
+
+// Sign returns the sign of v.
+func (v Value) Sign() Value { return v.math("Sign", ops.MathBuilder.Sign) }
+
+// Sin returns the sine of v.
+func (v Value) Sin() Value { return v.math("Sin", ops.MathBuilder.Sin) }
+
+// Sqrt returns the square root of v.
+func (v Value) Sqrt() Value { return v.math("Sqrt", ops.MathBuilder.Sqrt) }
+
+// Tanh returns the hyperbolic tangent of v.
+func (v Value) Tanh() Value { return v.math("Tanh", ops.MathBuilder.Tanh) }
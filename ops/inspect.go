@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// InspectableNode is implemented by nodes reporting how they have been built.
+	InspectableNode interface {
+		Node
+
+		// Op returns the operation computing the node.
+		Op() OpID
+
+		// Operands returns the nodes from which the node is computed.
+		Operands() []Node
+
+		// Shape returns the shape of the node, nil if it is unknown or if the node is a tuple.
+		Shape() *shape.Shape
+	}
+
+	// InspectableGraph is implemented by graphs listing their content.
+	InspectableGraph interface {
+		Graph
+
+		// Name of the graph.
+		Name() string
+
+		// Inspect returns the nodes and the subgraphs created by the graph, in creation order.
+		Inspect() (nodes []Node, subgraphs []Graph)
+	}
+)
+
+// inspection numbers the nodes of a graph and of its subgraphs.
+type inspection struct {
+	ids map[Node]int
+}
+
+func inspect(g InspectableGraph) *inspection {
+	in := &inspection{ids: make(map[Node]int)}
+	in.number(g)
+	return in
+}
+
+func (in *inspection) number(g Graph) {
+	ig, ok := g.(InspectableGraph)
+	if !ok {
+		return
+	}
+	nodes, subs := ig.Inspect()
+	for _, node := range nodes {
+		in.ids[node] = len(in.ids)
+	}
+	for _, sub := range subs {
+		in.number(sub)
+	}
+}
+
+// ref returns the reference to a node in a dump.
+func (in *inspection) ref(n Node) string {
+	if id, ok := in.ids[n]; ok {
+		return fmt.Sprintf("%%%d", id)
+	}
+	return "%?"
+}
+
+// describe returns the operation and the operands of a node, and the shape of its result.
+func (in *inspection) describe(n Node) (op, operands, result string) {
+	inode, ok := n.(InspectableNode)
+	if !ok {
+		return fmt.Sprintf("%T", n), "", ""
+	}
+	refs := make([]string, len(inode.Operands()))
+	for i, operand := range inode.Operands() {
+		refs[i] = in.ref(operand)
+	}
+	if sh := inode.Shape(); sh != nil {
+		result = sh.String()
+	}
+	return string(inode.Op()), strings.Join(refs, ", "), result
+}
+
+// Dump returns a text description of a graph and of its subgraphs, one node per line.
+// Nodes are numbered in creation order, starting with the nodes of the root graph.
+func Dump(g Graph) string {
+	ig, ok := g.(InspectableGraph)
+	if !ok {
+		return fmt.Sprintf("graph %T cannot be inspected\n", g)
+	}
+	var b strings.Builder
+	inspect(ig).dump(&b, ig, "")
+	return b.String()
+}
+
+func (in *inspection) dump(b *strings.Builder, g InspectableGraph, indent string) {
+	fmt.Fprintf(b, "%sgraph %s {\n", indent, g.Name())
+	nodes, subs := g.Inspect()
+	for _, node := range nodes {
+		op, operands, result := in.describe(node)
+		fmt.Fprintf(b, "%s  %s = %s(%s)", indent, in.ref(node), op, operands)
+		if result != "" {
+			fmt.Fprintf(b, " -> %s", result)
+		}
+		b.WriteString("\n")
+	}
+	for _, sub := range subs {
+		if isub, ok := sub.(InspectableGraph); ok {
+			in.dump(b, isub, indent+"  ")
+		} else {
+			fmt.Fprintf(b, "%s  graph %T cannot be inspected\n", indent, sub)
+		}
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// WriteDOT writes a graph and its subgraphs in the DOT language of Graphviz.
+// Every graph is rendered as a cluster and every node is labelled with its operation
+// and the shape of its result.
+func WriteDOT(w io.Writer, g Graph) error {
+	ig, ok := g.(InspectableGraph)
+	if !ok {
+		return fmt.Errorf("graph %T cannot be inspected", g)
+	}
+	var b strings.Builder
+	in := inspect(ig)
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(ig.Name()))
+	var edges []string
+	clusters := 0
+	var write func(g InspectableGraph, indent string)
+	write = func(g InspectableGraph, indent string) {
+		fmt.Fprintf(&b, "%ssubgraph cluster_%d {\n", indent, clusters)
+		clusters++
+		fmt.Fprintf(&b, "%s  label=%s;\n", indent, strconv.Quote(g.Name()))
+		nodes, subs := g.Inspect()
+		for _, node := range nodes {
+			id := in.ids[node]
+			op, _, result := in.describe(node)
+			label := op
+			if result != "" {
+				label += "\n" + result
+			}
+			fmt.Fprintf(&b, "%s  n%d [label=%s];\n", indent, id, strconv.Quote(label))
+			inode, ok := node.(InspectableNode)
+			if !ok {
+				continue
+			}
+			for _, operand := range inode.Operands() {
+				if from, ok := in.ids[operand]; ok {
+					edges = append(edges, fmt.Sprintf("  n%d -> n%d;\n", from, id))
+				}
+			}
+		}
+		for _, sub := range subs {
+			if isub, ok := sub.(InspectableGraph); ok {
+				write(isub, indent+"  ")
+			}
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	write(ig, "  ")
+	for _, edge := range edges {
+		b.WriteString(edge)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+)
+
+// Backend wraps a backend such that all the graphs it creates notify an interceptor.
+type Backend struct {
+	backend.Backend
+	icpt Interceptor
+}
+
+var _ backend.Versioned = (*Backend)(nil)
+
+// NewBackend returns a backend forwarding all calls to inner and notifying icpt
+// of all the calls made on the graphs it creates.
+func NewBackend(inner backend.Backend, icpt Interceptor) *Backend {
+	return &Backend{Backend: inner, icpt: icpt}
+}
+
+// Inner returns the wrapped backend.
+func (b *Backend) Inner() backend.Backend {
+	return b.Backend
+}
+
+// Interceptor returns the interceptor notified by the graphs of the backend.
+func (b *Backend) Interceptor() Interceptor {
+	return b.icpt
+}
+
+// NewOps returns a new graph notifying the interceptor.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	inner, err := b.Backend.NewOps(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	g := Wrap(inner, name, b.icpt)
+	g.config = ops.NewGraphConfig(opts...)
+	return g, nil
+}
+
+// InterfaceVersion returns the revision of the interfaces implemented by the wrapped backend.
+func (b *Backend) InterfaceVersion() backend.Version {
+	return backend.VersionOf(b.Backend)
+}
+
+// Features returns the optional features implemented by the wrapped backend.
+func (b *Backend) Features() []backend.Feature {
+	if v, ok := b.Backend.(backend.Versioned); ok {
+		return v.Features()
+	}
+	return nil
+}
@@ -0,0 +1,705 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"go/ast"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type coreBuilder struct {
+	g *Graph
+}
+
+var _ ops.CoreBuilder = coreBuilder{}
+
+func (b coreBuilder) Graph() ops.Graph {
+	return b.g
+}
+
+func (b coreBuilder) inner() ops.CoreBuilder {
+	return b.g.inner.Core()
+}
+
+func (b coreBuilder) Constant(value platform.HostBuffer) (ops.Node, error) {
+	return b.g.apply(ops.OpConstant, nil, []Attr{{"value", value}}, func() (*shape.Shape, error) {
+		return value.Shape(), nil
+	}, func() (ops.Node, error) {
+		return b.inner().Constant(value)
+	})
+}
+
+func (b coreBuilder) Tuple(nodes []ops.Node) (ops.Tuple, error) {
+	inputs, err := b.g.unwrapAll(nodes)
+	if err != nil {
+		return nil, err
+	}
+	node, err := b.g.apply(ops.OpTuple, inputs, nil, nil, func() (ops.Node, error) {
+		return b.inner().Tuple(inners(inputs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node.(*Tuple), nil
+}
+
+func (b coreBuilder) Call(sg *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll(args)
+	if err != nil {
+		return nil, err
+	}
+	innerSG, wrapped, err := b.g.unwrapSubgraph(sg)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpCall, inputs, []Attr{{"subgraph", wrapped.name}}, func() (*shape.Shape, error) {
+		return sg.Result.Shape, nil
+	}, func() (ops.Node, error) {
+		return b.inner().Call(innerSG, inners(inputs)...)
+	})
+}
+
+func (b coreBuilder) Subgraph(name string, args []*shape.Shape) (ops.Graph, error) {
+	call := b.g.newCall(ops.OpSubgraph, nil, []Attr{{"name", name}, {"args", args}})
+	if err := b.g.icpt.Before(call); err != nil {
+		return nil, b.g.fail(call, err)
+	}
+	inner, err := b.inner().Subgraph(name, args)
+	if err != nil {
+		return nil, b.g.fail(call, err)
+	}
+	sub := &Graph{inner: inner, icpt: b.g.icpt, name: name, parent: b.g, ids: b.g.ids}
+	call.Subgraph = sub
+	b.g.subs = append(b.g.subs, sub)
+	b.g.icpt.After(call, nil)
+	return sub, nil
+}
+
+func (b coreBuilder) Argument(name string, sh *shape.Shape, index int) (ops.Node, error) {
+	return b.g.apply(ops.OpArgument, nil, []Attr{{"name", name}, {"shape", sh}, {"index", index}}, func() (*shape.Shape, error) {
+		return sh, nil
+	}, func() (ops.Node, error) {
+		return b.inner().Argument(name, sh, index)
+	})
+}
+
+func (b coreBuilder) Unary(op *ast.UnaryExpr, x ops.Node) (ops.Node, error) {
+	return ops.UnaryFromAST(b, op, x)
+}
+
+func (b coreBuilder) Binary(op *ast.BinaryExpr, x, y ops.Node) (ops.Node, error) {
+	return ops.BinaryFromAST(b, op, x, y)
+}
+
+func (b coreBuilder) UnaryOp(op ops.UnaryOperator, x ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpUnary, inputs, []Attr{{"op", op}}, func() (*shape.Shape, error) {
+		return UnaryOpShape(op, inputs[0].shape)
+	}, func() (ops.Node, error) {
+		return b.inner().UnaryOp(op, inputs[0].inner)
+	})
+}
+
+func (b coreBuilder) BinaryOp(op ops.BinaryOperator, x, y ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpBinary, inputs, []Attr{{"op", op}}, func() (*shape.Shape, error) {
+		return BinaryOpShape(op, inputs[0].shape, inputs[1].shape)
+	}, func() (ops.Node, error) {
+		return b.inner().BinaryOp(op, inputs[0].inner, inputs[1].inner)
+	})
+}
+
+func (b coreBuilder) Reshape(x ops.Node, axisLengths []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpReshape, inputs, []Attr{{"axisLengths", axisLengths}}, func() (*shape.Shape, error) {
+		return shape.ReshapeShape(inputs[0].shape, axisLengths)
+	}, func() (ops.Node, error) {
+		return b.inner().Reshape(inputs[0].inner, axisLengths)
+	})
+}
+
+func (b coreBuilder) Concat(axis int, nodes []ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll(nodes)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpConcat, inputs, []Attr{{"axis", axis}}, func() (*shape.Shape, error) {
+		return shape.ConcatShape(axis, shapesOf(inputs)...)
+	}, func() (ops.Node, error) {
+		return b.inner().Concat(axis, inners(inputs))
+	})
+}
+
+func (b coreBuilder) Cast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpCast, inputs, []Attr{{"target", target}}, func() (*shape.Shape, error) {
+		return shape.CastShape(inputs[0].shape, target)
+	}, func() (ops.Node, error) {
+		return b.inner().Cast(inputs[0].inner, target)
+	})
+}
+
+func (b coreBuilder) Slice(x ops.Node, index int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpSlice, inputs, []Attr{{"index", index}}, func() (*shape.Shape, error) {
+		return shape.SliceShape(inputs[0].shape, index)
+	}, func() (ops.Node, error) {
+		return b.inner().Slice(inputs[0].inner, index)
+	})
+}
+
+func (b coreBuilder) Set(x, updates, index ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, updates, index})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpSet, inputs, nil, func() (*shape.Shape, error) {
+		return inputs[0].shape, nil
+	}, func() (ops.Node, error) {
+		return b.inner().Set(inputs[0].inner, inputs[1].inner, inputs[2].inner)
+	})
+}
+
+func (b coreBuilder) DotGeneral(x, y ops.Node, batchAxes, reduceAxes [2][]int, precision ops.Precision) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"batchAxes", batchAxes}, {"reduceAxes", reduceAxes}, {"precision", precision}}
+	return b.g.apply(ops.OpDotGeneral, inputs, attrs, func() (*shape.Shape, error) {
+		return shape.DotGeneralShape(inputs[0].shape, inputs[1].shape, batchAxes, reduceAxes)
+	}, func() (ops.Node, error) {
+		return b.inner().DotGeneral(inputs[0].inner, inputs[1].inner, batchAxes, reduceAxes, precision)
+	})
+}
+
+func (b coreBuilder) While(cond, body *ops.Subgraph, state ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{state})
+	if err != nil {
+		return nil, err
+	}
+	innerCond, condGraph, err := b.g.unwrapSubgraph(cond)
+	if err != nil {
+		return nil, err
+	}
+	innerBody, bodyGraph, err := b.g.unwrapSubgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"cond", condGraph.name}, {"body", bodyGraph.name}}
+	return b.g.apply(ops.OpWhile, inputs, attrs, func() (*shape.Shape, error) {
+		return inputs[0].shape, nil
+	}, func() (ops.Node, error) {
+		return b.inner().While(innerCond, innerBody, inputs[0].inner)
+	})
+}
+
+func (b coreBuilder) Cond(pred ops.Node, trueBranch, falseBranch *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll(append([]ops.Node{pred}, args...))
+	if err != nil {
+		return nil, err
+	}
+	innerTrue, trueGraph, err := b.g.unwrapSubgraph(trueBranch)
+	if err != nil {
+		return nil, err
+	}
+	innerFalse, falseGraph, err := b.g.unwrapSubgraph(falseBranch)
+	if err != nil {
+		return nil, err
+	}
+	trueResult, err := trueGraph.unwrap(trueBranch.Result.Node)
+	if err != nil {
+		return nil, err
+	}
+	falseResult, err := falseGraph.unwrap(falseBranch.Result.Node)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"true", trueGraph.name}, {"false", falseGraph.name}}
+	return b.g.apply(ops.OpCond, inputs, attrs, func() (*shape.Shape, error) {
+		return CondShape(inputs[0].shape, trueResult.shape, falseResult.shape)
+	}, func() (ops.Node, error) {
+		return b.inner().Cond(inputs[0].inner, innerTrue, innerFalse, inners(inputs[1:])...)
+	})
+}
+
+func (b coreBuilder) Select(pred, onTrue, onFalse ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{pred, onTrue, onFalse})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpSelect, inputs, nil, func() (*shape.Shape, error) {
+		return SelectShape(inputs[0].shape, inputs[1].shape, inputs[2].shape)
+	}, func() (ops.Node, error) {
+		return b.inner().Select(inputs[0].inner, inputs[1].inner, inputs[2].inner)
+	})
+}
+
+func (b coreBuilder) BroadcastInDim(x ops.Node, sh *shape.Shape, broadcastAxes []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"shape", sh}, {"broadcastAxes", broadcastAxes}}
+	return b.g.apply(ops.OpBroadcastInDim, inputs, attrs, func() (*shape.Shape, error) {
+		return shape.BroadcastInDimShape(inputs[0].shape, sh.AxisLengths, broadcastAxes)
+	}, func() (ops.Node, error) {
+		return b.inner().BroadcastInDim(inputs[0].inner, sh, broadcastAxes)
+	})
+}
+
+func (b coreBuilder) Quantize(x ops.Node, quant *dtype.Quantization) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpQuantize, inputs, []Attr{{"quant", quant}}, func() (*shape.Shape, error) {
+		return QuantizeShape(inputs[0].shape, quant)
+	}, func() (ops.Node, error) {
+		return b.inner().Quantize(inputs[0].inner, quant)
+	})
+}
+
+func (b coreBuilder) Dequantize(x ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpDequantize, inputs, nil, func() (*shape.Shape, error) {
+		return DequantizeShape(inputs[0].shape)
+	}, func() (ops.Node, error) {
+		return b.inner().Dequantize(inputs[0].inner)
+	})
+}
+
+// reduce applies one of the ReduceSum, ReduceProd, ReduceMax or ReduceMin operations.
+func (b coreBuilder) reduce(op ops.OpID, x ops.Node, axes []int, build func(ops.CoreBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, []Attr{{"axes", axes}}, func() (*shape.Shape, error) {
+		return ReduceOpShape(op, inputs[0].shape, axes)
+	}, func() (ops.Node, error) {
+		return build(b.inner(), inputs[0].inner)
+	})
+}
+
+func (b coreBuilder) ReduceSum(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduce(ops.OpReduceSum, x, axes, func(inner ops.CoreBuilder, x ops.Node) (ops.Node, error) {
+		return inner.ReduceSum(x, axes)
+	})
+}
+
+func (b coreBuilder) ReduceProd(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduce(ops.OpReduceProd, x, axes, func(inner ops.CoreBuilder, x ops.Node) (ops.Node, error) {
+		return inner.ReduceProd(x, axes)
+	})
+}
+
+func (b coreBuilder) ReduceMax(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduce(ops.OpReduceMax, x, axes, func(inner ops.CoreBuilder, x ops.Node) (ops.Node, error) {
+		return inner.ReduceMax(x, axes)
+	})
+}
+
+func (b coreBuilder) ReduceMin(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduce(ops.OpReduceMin, x, axes, func(inner ops.CoreBuilder, x ops.Node) (ops.Node, error) {
+		return inner.ReduceMin(x, axes)
+	})
+}
+
+func (b coreBuilder) Reduce(body *ops.Subgraph, init, x ops.Node, axes []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{init, x})
+	if err != nil {
+		return nil, err
+	}
+	innerBody, bodyGraph, err := b.g.unwrapSubgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"body", bodyGraph.name}, {"axes", axes}}
+	return b.g.apply(ops.OpReduce, inputs, attrs, func() (*shape.Shape, error) {
+		return ReduceShape(inputs[0].shape, inputs[1].shape, axes)
+	}, func() (ops.Node, error) {
+		return b.inner().Reduce(innerBody, inputs[0].inner, inputs[1].inner, axes)
+	})
+}
+
+func (b coreBuilder) Gather(x, indices ops.Node, dims ops.GatherDims, sliceSizes []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, indices})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"dims", dims}, {"sliceSizes", sliceSizes}}
+	return b.g.apply(ops.OpGather, inputs, attrs, func() (*shape.Shape, error) {
+		return GatherShape(inputs[0].shape, inputs[1].shape, dims, sliceSizes)
+	}, func() (ops.Node, error) {
+		return b.inner().Gather(inputs[0].inner, inputs[1].inner, dims, sliceSizes)
+	})
+}
+
+func (b coreBuilder) Scatter(x, indices, updates ops.Node, dims ops.ScatterDims) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, indices, updates})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpScatter, inputs, []Attr{{"dims", dims}}, func() (*shape.Shape, error) {
+		return ScatterShape(inputs[0].shape, inputs[1].shape, inputs[2].shape, dims)
+	}, func() (ops.Node, error) {
+		return b.inner().Scatter(inputs[0].inner, inputs[1].inner, inputs[2].inner, dims)
+	})
+}
+
+func (b coreBuilder) Transpose(x ops.Node, permutation []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpTranspose, inputs, []Attr{{"permutation", permutation}}, func() (*shape.Shape, error) {
+		return shape.TransposeShape(inputs[0].shape, permutation)
+	}, func() (ops.Node, error) {
+		return b.inner().Transpose(inputs[0].inner, permutation)
+	})
+}
+
+func (b coreBuilder) Reverse(x ops.Node, axes []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpReverse, inputs, []Attr{{"axes", axes}}, func() (*shape.Shape, error) {
+		return shape.ReverseShape(inputs[0].shape, axes)
+	}, func() (ops.Node, error) {
+		return b.inner().Reverse(inputs[0].inner, axes)
+	})
+}
+
+func (b coreBuilder) Pad(x, value ops.Node, low, high, interior []int) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, value})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"low", low}, {"high", high}, {"interior", interior}}
+	return b.g.apply(ops.OpPad, inputs, attrs, func() (*shape.Shape, error) {
+		return PadShape(inputs[0].shape, inputs[1].shape, low, high, interior)
+	}, func() (ops.Node, error) {
+		return b.inner().Pad(inputs[0].inner, inputs[1].inner, low, high, interior)
+	})
+}
+
+func (b coreBuilder) Sort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"axis", axis}, {"descending", descending}}
+	return b.g.apply(ops.OpSort, inputs, attrs, func() (*shape.Shape, error) {
+		return SortShape(ops.OpSort, inputs[0].shape, axis)
+	}, func() (ops.Node, error) {
+		return b.inner().Sort(inputs[0].inner, axis, descending)
+	})
+}
+
+func (b coreBuilder) ArgSort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"axis", axis}, {"descending", descending}}
+	return b.g.apply(ops.OpArgSort, inputs, attrs, func() (*shape.Shape, error) {
+		return SortShape(ops.OpArgSort, inputs[0].shape, axis)
+	}, func() (ops.Node, error) {
+		return b.inner().ArgSort(inputs[0].inner, axis, descending)
+	})
+}
+
+func (b coreBuilder) TopK(x ops.Node, k int) (ops.Tuple, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	// The result is a tuple: only the validity of the operation is checked.
+	node, err := b.g.apply(ops.OpTopK, inputs, []Attr{{"k", k}}, func() (*shape.Shape, error) {
+		_, err := TopKShape(inputs[0].shape, k)
+		return nil, err
+	}, func() (ops.Node, error) {
+		return b.inner().TopK(inputs[0].inner, k)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node.(*Tuple), nil
+}
+
+type dtypeBuilder struct {
+	g *Graph
+}
+
+var _ ops.DTypeBuilder = dtypeBuilder{}
+
+func (b dtypeBuilder) Bitcast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	// The shape of a bitcast depends on the size of the data types: it is left unknown.
+	return b.g.apply(ops.OpBitcast, inputs, []Attr{{"target", target}}, nil, func() (ops.Node, error) {
+		return b.g.inner.DType().Bitcast(inputs[0].inner, target)
+	})
+}
+
+type numBuilder struct {
+	g *Graph
+}
+
+var _ ops.NumBuilder = numBuilder{}
+
+func (b numBuilder) Iota(sh *shape.Shape, iotaAxis int) (ops.Node, error) {
+	return b.g.apply(ops.OpIota, nil, []Attr{{"shape", sh}, {"axis", iotaAxis}}, func() (*shape.Shape, error) {
+		if _, err := shape.NormalizeAxis(iotaAxis, len(sh.AxisLengths)); err != nil {
+			return nil, err
+		}
+		return sh, nil
+	}, func() (ops.Node, error) {
+		return b.g.inner.Num().Iota(sh, iotaAxis)
+	})
+}
+
+// axisOp applies one of the Argmax, Argmin, CumSum or CumProd operations along an axis.
+func (b numBuilder) axisOp(op ops.OpID, x ops.Node, axis int, infer func(ops.OpID, *shape.Shape, int) (*shape.Shape, error), build func(ops.NumBuilder, ops.Node, int) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, []Attr{{"axis", axis}}, func() (*shape.Shape, error) {
+		return infer(op, inputs[0].shape, axis)
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Num(), inputs[0].inner, axis)
+	})
+}
+
+func (b numBuilder) Argmax(x ops.Node, axis int) (ops.Node, error) {
+	return b.axisOp(ops.OpArgmax, x, axis, ArgShape, ops.NumBuilder.Argmax)
+}
+
+func (b numBuilder) Argmin(x ops.Node, axis int) (ops.Node, error) {
+	return b.axisOp(ops.OpArgmin, x, axis, ArgShape, ops.NumBuilder.Argmin)
+}
+
+func (b numBuilder) CumSum(x ops.Node, axis int) (ops.Node, error) {
+	return b.axisOp(ops.OpCumSum, x, axis, CumShape, ops.NumBuilder.CumSum)
+}
+
+func (b numBuilder) CumProd(x ops.Node, axis int) (ops.Node, error) {
+	return b.axisOp(ops.OpCumProd, x, axis, CumShape, ops.NumBuilder.CumProd)
+}
+
+type mathBuilder struct {
+	g *Graph
+}
+
+var _ ops.MathBuilder = mathBuilder{}
+
+// unary applies an element-wise math function.
+func (b mathBuilder) unary(op ops.OpID, x ops.Node, build func(ops.MathBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, nil, func() (*shape.Shape, error) {
+		return inputs[0].shape, nil
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Math(), inputs[0].inner)
+	})
+}
+
+func (b mathBuilder) Abs(x ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpAbs, inputs, nil, func() (*shape.Shape, error) {
+		return AbsShape(inputs[0].shape), nil
+	}, func() (ops.Node, error) {
+		return b.g.inner.Math().Abs(inputs[0].inner)
+	})
+}
+
+// binary applies an element-wise math function of two arguments.
+func (b mathBuilder) binary(op ops.OpID, x, y ops.Node, build func(ops.MathBuilder, ops.Node, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, nil, func() (*shape.Shape, error) {
+		return MathBinaryShape(op, inputs[0].shape, inputs[1].shape)
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Math(), inputs[0].inner, inputs[1].inner)
+	})
+}
+
+// predicate applies an element-wise math function returning booleans.
+func (b mathBuilder) predicate(op ops.OpID, x ops.Node, build func(ops.MathBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, nil, func() (*shape.Shape, error) {
+		return MathPredicateShape(op, inputs[0].shape)
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Math(), inputs[0].inner)
+	})
+}
+
+func (b mathBuilder) Atan2(y, x ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpAtan2, y, x, ops.MathBuilder.Atan2)
+}
+
+// complexPart applies one of the Real, Imag or Conj math functions.
+func (b mathBuilder) complexPart(op ops.OpID, x ops.Node, build func(ops.MathBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, nil, func() (*shape.Shape, error) {
+		return ComplexPartShape(op, inputs[0].shape)
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Math(), inputs[0].inner)
+	})
+}
+
+func (b mathBuilder) Ceil(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpCeil, x, ops.MathBuilder.Ceil)
+}
+
+func (b mathBuilder) Complex(re, im ops.Node) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{re, im})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(ops.OpComplex, inputs, nil, func() (*shape.Shape, error) {
+		return ComplexShape(inputs[0].shape, inputs[1].shape)
+	}, func() (ops.Node, error) {
+		return b.g.inner.Math().Complex(inputs[0].inner, inputs[1].inner)
+	})
+}
+
+func (b mathBuilder) Conj(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpConj, x, ops.MathBuilder.Conj)
+}
+
+func (b mathBuilder) Cos(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpCos, x, ops.MathBuilder.Cos)
+}
+
+func (b mathBuilder) Erf(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpErf, x, ops.MathBuilder.Erf)
+}
+
+func (b mathBuilder) Exp(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpExp, x, ops.MathBuilder.Exp)
+}
+
+func (b mathBuilder) Expm1(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpExpm1, x, ops.MathBuilder.Expm1)
+}
+
+func (b mathBuilder) Floor(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpFloor, x, ops.MathBuilder.Floor)
+}
+
+func (b mathBuilder) Imag(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpImag, x, ops.MathBuilder.Imag)
+}
+
+func (b mathBuilder) IsInf(x ops.Node) (ops.Node, error) {
+	return b.predicate(ops.OpIsInf, x, ops.MathBuilder.IsInf)
+}
+
+func (b mathBuilder) IsNaN(x ops.Node) (ops.Node, error) {
+	return b.predicate(ops.OpIsNaN, x, ops.MathBuilder.IsNaN)
+}
+
+func (b mathBuilder) Log(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLog, x, ops.MathBuilder.Log)
+}
+
+func (b mathBuilder) Log1p(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLog1p, x, ops.MathBuilder.Log1p)
+}
+
+func (b mathBuilder) Logistic(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpLogistic, x, ops.MathBuilder.Logistic)
+}
+
+func (b mathBuilder) Pow(x, y ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpPow, x, y, ops.MathBuilder.Pow)
+}
+
+func (b mathBuilder) Real(x ops.Node) (ops.Node, error) {
+	return b.complexPart(ops.OpReal, x, ops.MathBuilder.Real)
+}
+
+func (b mathBuilder) Round(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpRound, x, ops.MathBuilder.Round)
+}
+
+func (b mathBuilder) Rsqrt(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpRsqrt, x, ops.MathBuilder.Rsqrt)
+}
+
+func (b mathBuilder) Sign(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSign, x, ops.MathBuilder.Sign)
+}
+
+func (b mathBuilder) Sin(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSin, x, ops.MathBuilder.Sin)
+}
+
+func (b mathBuilder) Sqrt(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpSqrt, x, ops.MathBuilder.Sqrt)
+}
+
+func (b mathBuilder) Tanh(x ops.Node) (ops.Node, error) {
+	return b.unary(ops.OpTanh, x, ops.MathBuilder.Tanh)
+}
+
+func shapesOf(nodes []*Node) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(nodes))
+	for i, n := range nodes {
+		shapes[i] = n.shape
+	}
+	return shapes
+}
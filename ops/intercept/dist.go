@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type distBuilder struct {
+	g *Graph
+}
+
+var (
+	_ ops.DistBuilder  = distBuilder{}
+	_ ops.DistGraph    = (*Graph)(nil)
+	_ ops.MeshCompiler = (*Graph)(nil)
+)
+
+// Dist returns the builder for collective operations.
+// Its operations fail if the graph of the backend does not support collective operations.
+func (g *Graph) Dist() ops.DistBuilder {
+	return distBuilder{g: g}
+}
+
+// mesh returns the mesh for which the graph is built.
+func (b distBuilder) mesh() (*platform.Mesh, error) {
+	mesh := b.g.Config().Mesh
+	if mesh == nil {
+		return nil, fmt.Errorf("graph %s has not been built for a mesh", b.g.Path())
+	}
+	return mesh, nil
+}
+
+// collective applies a collective operation given a function inferring its shape on a mesh.
+func (b distBuilder) collective(op ops.OpID, x ops.Node, attrs []Attr, infer func(*platform.Mesh, *shape.Shape) (*shape.Shape, error), build func(ops.DistBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, attrs, func() (*shape.Shape, error) {
+		mesh, err := b.mesh()
+		if err != nil {
+			return nil, err
+		}
+		return infer(mesh, inputs[0].shape)
+	}, func() (ops.Node, error) {
+		inner, err := ops.Dist(b.g.inner)
+		if err != nil {
+			return nil, err
+		}
+		return build(inner, inputs[0].inner)
+	})
+}
+
+func (b distBuilder) AllReduce(x ops.Node, op platform.ReduceOp, meshAxes []string) (ops.Node, error) {
+	attrs := []Attr{{"op", op}, {"meshAxes", meshAxes}}
+	return b.collective(ops.OpAllReduce, x, attrs, func(mesh *platform.Mesh, x *shape.Shape) (*shape.Shape, error) {
+		if _, err := mesh.GroupSize(meshAxes); err != nil {
+			return nil, err
+		}
+		return AllReduceShape(op, x)
+	}, func(inner ops.DistBuilder, x ops.Node) (ops.Node, error) {
+		return inner.AllReduce(x, op, meshAxes)
+	})
+}
+
+func (b distBuilder) AllGather(x ops.Node, axis int, meshAxes []string) (ops.Node, error) {
+	attrs := []Attr{{"axis", axis}, {"meshAxes", meshAxes}}
+	return b.collective(ops.OpAllGather, x, attrs, func(mesh *platform.Mesh, x *shape.Shape) (*shape.Shape, error) {
+		size, err := mesh.GroupSize(meshAxes)
+		if err != nil {
+			return nil, err
+		}
+		return AllGatherShape(x, axis, size)
+	}, func(inner ops.DistBuilder, x ops.Node) (ops.Node, error) {
+		return inner.AllGather(x, axis, meshAxes)
+	})
+}
+
+func (b distBuilder) ReduceScatter(x ops.Node, op platform.ReduceOp, axis int, meshAxes []string) (ops.Node, error) {
+	attrs := []Attr{{"op", op}, {"axis", axis}, {"meshAxes", meshAxes}}
+	return b.collective(ops.OpReduceScatter, x, attrs, func(mesh *platform.Mesh, x *shape.Shape) (*shape.Shape, error) {
+		size, err := mesh.GroupSize(meshAxes)
+		if err != nil {
+			return nil, err
+		}
+		return ReduceScatterShape(op, x, axis, size)
+	}, func(inner ops.DistBuilder, x ops.Node) (ops.Node, error) {
+		return inner.ReduceScatter(x, op, axis, meshAxes)
+	})
+}
+
+func (b distBuilder) CollectivePermute(x ops.Node, meshAxis string, pairs [][2]int) (ops.Node, error) {
+	attrs := []Attr{{"meshAxis", meshAxis}, {"pairs", pairs}}
+	return b.collective(ops.OpCollectivePermute, x, attrs, func(mesh *platform.Mesh, x *shape.Shape) (*shape.Shape, error) {
+		axis := mesh.AxisIndex(meshAxis)
+		if axis < 0 {
+			return nil, fmt.Errorf("mesh %s has no axis %q", mesh, meshAxis)
+		}
+		return CollectivePermuteShape(x, mesh.AxisSizes()[axis], pairs)
+	}, func(inner ops.DistBuilder, x ops.Node) (ops.Node, error) {
+		return inner.CollectivePermute(x, meshAxis, pairs)
+	})
+}
+
+// CompileMesh compiles the graph for all the devices of a mesh.
+func (g *Graph) CompileMesh(mesh *platform.Mesh, output, traced []*ops.OutputNode, params []*ops.MeshParam) (ops.MeshRunner, error) {
+	shapes := make([]*shape.Shape, len(params))
+	for i, param := range params {
+		shapes[i] = param.Shape
+	}
+	attrs := []Attr{
+		{"mesh", mesh},
+		{"outputs", outputShapes(output)},
+		{"traced", outputShapes(traced)},
+		{"params", shapes},
+	}
+	runner, err := g.compile(attrs, output, traced, func(output, traced []*ops.OutputNode) (ops.Runner, error) {
+		return ops.CompileMesh(g.inner, mesh, output, traced, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meshRunner{Runner: &Runner{inner: runner, graph: g, params: shapes}, mesh: runner.(ops.MeshRunner)}, nil
+}
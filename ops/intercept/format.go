@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// String returns a single-line description of the call.
+// The description does not depend on timing or memory addresses: the same sequence
+// of calls is always described by the same lines.
+func (c *Call) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] ", c.Graph.Path())
+	if c.Node != nil {
+		fmt.Fprintf(&b, "%%%d = ", c.Node.ID())
+	}
+	b.WriteString(string(c.Op))
+	b.WriteString("(")
+	for i, input := range c.Inputs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%%%d", input.ID())
+	}
+	b.WriteString(")")
+	for _, attr := range c.Attrs {
+		fmt.Fprintf(&b, " %s=%s", attr.Name, FormatValue(attr.Value))
+	}
+	if c.Shape != nil {
+		fmt.Fprintf(&b, " -> %s", c.Shape)
+	}
+	if c.ShapeErr != nil {
+		fmt.Fprintf(&b, " (shape: %v)", c.ShapeErr)
+	}
+	return b.String()
+}
+
+// FormatValue formats the value of an attribute without printing the content of arrays.
+func FormatValue(v any) string {
+	switch vT := v.(type) {
+	case platform.Handle:
+		return fmt.Sprintf("handle(%s)", vT.Shape())
+	case platform.Stream:
+		return "stream"
+	case []*shape.Shape:
+		strs := make([]string, len(vT))
+		for i, sh := range vT {
+			strs[i] = fmt.Sprint(sh)
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	case fmt.Stringer:
+		return vT.String()
+	}
+	return fmt.Sprint(v)
+}
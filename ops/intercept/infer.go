@@ -0,0 +1,656 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// UnaryShape returns the shape of a unary operator, given as a Go token, applied to x.
+//
+// Deprecated: use UnaryOpShape.
+func UnaryShape(tok token.Token, x *shape.Shape) (*shape.Shape, error) {
+	op, err := ops.UnaryOperatorOf(tok)
+	if err != nil {
+		return nil, err
+	}
+	return UnaryOpShape(op, x)
+}
+
+// UnaryOpShape returns the shape of a unary operator applied to x.
+func UnaryOpShape(op ops.UnaryOperator, x *shape.Shape) (*shape.Shape, error) {
+	if op == ops.Not && x.DType != dtype.Bool {
+		return nil, fmt.Errorf("operator %s not supported on data type %s", op, x.DType)
+	}
+	return x, nil
+}
+
+// BinaryShape returns the shape of a binary operator, given as a Go token, applied to x and y.
+//
+// Deprecated: use BinaryOpShape.
+func BinaryShape(tok token.Token, x, y *shape.Shape) (*shape.Shape, error) {
+	op, err := ops.BinaryOperatorOf(tok)
+	if err != nil {
+		return nil, err
+	}
+	return BinaryOpShape(op, x, y)
+}
+
+// BinaryOpShape returns the shape of a binary operator applied to x and y.
+// Both operands must have the same shape or one of them must be atomic.
+// Comparison operators return booleans.
+func BinaryOpShape(op ops.BinaryOperator, x, y *shape.Shape) (*shape.Shape, error) {
+	if x.DType != y.DType {
+		return nil, fmt.Errorf("operator %s applied to mismatched data types %s and %s", op, x.DType, y.DType)
+	}
+	var out *shape.Shape
+	switch {
+	case x.IsAtomic():
+		out = y
+	case y.IsAtomic():
+		out = x
+	case slices.Equal(x.AxisLengths, y.AxisLengths):
+		out = x
+	default:
+		return nil, fmt.Errorf("operator %s applied to mismatched shapes %s and %s", op, x, y)
+	}
+	switch {
+	case op.IsComparison():
+		return &shape.Shape{DType: dtype.Bool, AxisLengths: slices.Clone(out.AxisLengths), AxisNames: slices.Clone(out.AxisNames)}, nil
+	case op.IsLogical():
+		if x.DType != dtype.Bool {
+			return nil, fmt.Errorf("operator %s not supported on data type %s", op, x.DType)
+		}
+	}
+	return out, nil
+}
+
+// MathBinaryShape returns the shape of one of the Atan2 or Pow math functions applied to x and y.
+// Both operands must have the same shape or one of them must be atomic.
+func MathBinaryShape(op ops.OpID, x, y *shape.Shape) (*shape.Shape, error) {
+	if !dtype.IsFloat(x.DType) && !dtype.IsHalf(x.DType) {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	if x.DType != y.DType {
+		return nil, fmt.Errorf("%s applied to mismatched data types %s and %s", op, x.DType, y.DType)
+	}
+	switch {
+	case x.IsAtomic():
+		return y, nil
+	case y.IsAtomic(), slices.Equal(x.AxisLengths, y.AxisLengths):
+		return x, nil
+	}
+	return nil, fmt.Errorf("%s applied to mismatched shapes %s and %s", op, x, y)
+}
+
+// MathPredicateShape returns the shape of one of the IsInf or IsNaN math functions applied to x.
+func MathPredicateShape(op ops.OpID, x *shape.Shape) (*shape.Shape, error) {
+	if !dtype.IsFloat(x.DType) && !dtype.IsHalf(x.DType) {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	return &shape.Shape{DType: dtype.Bool, AxisLengths: slices.Clone(x.AxisLengths)}, nil
+}
+
+// complexParts maps complex data types to the data type of their real and imaginary parts.
+var complexParts = map[dtype.DataType]dtype.DataType{
+	dtype.Complex64:  dtype.Float32,
+	dtype.Complex128: dtype.Float64,
+}
+
+// ComplexShape returns the shape of the complex numbers built from re and im.
+// Both operands must have the same shape or one of them must be atomic.
+func ComplexShape(re, im *shape.Shape) (*shape.Shape, error) {
+	if re.DType != im.DType {
+		return nil, fmt.Errorf("%s applied to mismatched data types %s and %s", ops.OpComplex, re.DType, im.DType)
+	}
+	var dt dtype.DataType
+	for cplx, part := range complexParts {
+		if part == re.DType {
+			dt = cplx
+		}
+	}
+	if dt == dtype.Invalid {
+		return nil, fmt.Errorf("%s not supported on data type %s", ops.OpComplex, re.DType)
+	}
+	var out *shape.Shape
+	switch {
+	case re.IsAtomic():
+		out = im
+	case im.IsAtomic(), slices.Equal(re.AxisLengths, im.AxisLengths):
+		out = re
+	default:
+		return nil, fmt.Errorf("%s applied to mismatched shapes %s and %s", ops.OpComplex, re, im)
+	}
+	return &shape.Shape{DType: dt, AxisLengths: slices.Clone(out.AxisLengths)}, nil
+}
+
+// ComplexPartShape returns the shape of one of the Real, Imag or Conj math functions applied to x.
+func ComplexPartShape(op ops.OpID, x *shape.Shape) (*shape.Shape, error) {
+	part, ok := complexParts[x.DType]
+	if !ok {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	if op == ops.OpConj {
+		return x, nil
+	}
+	return &shape.Shape{DType: part, AxisLengths: slices.Clone(x.AxisLengths)}, nil
+}
+
+// AbsShape returns the shape of the absolute value of x: the modulus of complex numbers is a float.
+func AbsShape(x *shape.Shape) *shape.Shape {
+	if part, ok := complexParts[x.DType]; ok {
+		return &shape.Shape{DType: part, AxisLengths: slices.Clone(x.AxisLengths)}
+	}
+	return x
+}
+
+// QuantizeShape returns the shape of x quantized with given parameters.
+func QuantizeShape(x *shape.Shape, quant *dtype.Quantization) (*shape.Shape, error) {
+	if err := quant.Check(); err != nil {
+		return nil, err
+	}
+	if x.DType != quant.Expressed {
+		return nil, fmt.Errorf("cannot quantize %s values into values expressed as %s", x.DType, quant.Expressed)
+	}
+	return &shape.Shape{DType: quant.Storage, AxisLengths: slices.Clone(x.AxisLengths), AxisNames: slices.Clone(x.AxisNames), Quant: quant}, nil
+}
+
+// DequantizeShape returns the shape of x converted back to real values.
+func DequantizeShape(x *shape.Shape) (*shape.Shape, error) {
+	if x.Quant == nil {
+		return nil, fmt.Errorf("cannot dequantize array of shape %s: array is not quantized", x)
+	}
+	return &shape.Shape{DType: x.Quant.Expressed, AxisLengths: slices.Clone(x.AxisLengths), AxisNames: slices.Clone(x.AxisNames)}, nil
+}
+
+// ReduceOpShape returns the shape of x reduced along a set of axes by one of the
+// ReduceSum, ReduceProd, ReduceMax or ReduceMin operations.
+// Sums and products are not supported on booleans.
+func ReduceOpShape(op ops.OpID, x *shape.Shape, axes []int) (*shape.Shape, error) {
+	if (op == ops.OpReduceSum || op == ops.OpReduceProd) && dtype.IsNonAlgebra(x.DType) {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	return shape.ReduceShape(x, axes)
+}
+
+// ReduceShape returns the shape of x reduced along a set of axes from the initial value init.
+func ReduceShape(init, x *shape.Shape, axes []int) (*shape.Shape, error) {
+	if !init.IsAtomic() {
+		return nil, fmt.Errorf("initial value %s of a reduction is not atomic", init)
+	}
+	if init.DType != x.DType {
+		return nil, fmt.Errorf("cannot reduce %s values from an initial value of type %s", x.DType, init.DType)
+	}
+	return shape.ReduceShape(x, axes)
+}
+
+// CondShape returns the shape of a conditional given the shapes returned by its branches.
+// It returns nil if the shape of a branch is unknown, for example when it returns a tuple.
+func CondShape(pred, onTrue, onFalse *shape.Shape) (*shape.Shape, error) {
+	if pred.DType != dtype.Bool || !pred.IsAtomic() {
+		return nil, fmt.Errorf("condition %s is not an atomic boolean", pred)
+	}
+	if onTrue == nil || onFalse == nil {
+		return nil, nil
+	}
+	if !onTrue.EqualIgnoringLayout(onFalse) {
+		return nil, fmt.Errorf("branches return mismatched shapes %s and %s", onTrue, onFalse)
+	}
+	return onTrue, nil
+}
+
+// SelectShape returns the shape of an element-wise selection between onTrue and onFalse.
+func SelectShape(pred, onTrue, onFalse *shape.Shape) (*shape.Shape, error) {
+	if pred.DType != dtype.Bool {
+		return nil, fmt.Errorf("predicate of type %s is not a boolean", pred.DType)
+	}
+	if onTrue.DType != onFalse.DType || !slices.Equal(onTrue.AxisLengths, onFalse.AxisLengths) {
+		return nil, fmt.Errorf("cannot select between mismatched shapes %s and %s", onTrue, onFalse)
+	}
+	if !pred.IsAtomic() && !slices.Equal(pred.AxisLengths, onTrue.AxisLengths) {
+		return nil, fmt.Errorf("predicate %s does not match the shape %s of its operands", pred, onTrue)
+	}
+	return onTrue, nil
+}
+
+// SortShape returns the shape of one of the Sort or ArgSort operations applied to x along an axis.
+// ArgSort returns int32 indices.
+func SortShape(op ops.OpID, x *shape.Shape, axis int) (*shape.Shape, error) {
+	if _, err := shape.NormalizeAxis(axis, len(x.AxisLengths)); err != nil {
+		return nil, err
+	}
+	if op == ops.OpArgSort {
+		return &shape.Shape{DType: dtype.Int32, AxisLengths: slices.Clone(x.AxisLengths)}, nil
+	}
+	return x, nil
+}
+
+// ArgShape returns the shape of the indices computed by one of the Argmax or Argmin operations
+// applied to x along an axis.
+func ArgShape(op ops.OpID, x *shape.Shape, axis int) (*shape.Shape, error) {
+	axis, err := shape.NormalizeAxis(axis, len(x.AxisLengths))
+	if err != nil {
+		return nil, err
+	}
+	if x.AxisLengths[axis] == 0 {
+		return nil, fmt.Errorf("%s applied to empty axis %d of %s", op, axis, x)
+	}
+	return &shape.Shape{DType: dtype.Int32, AxisLengths: slices.Delete(slices.Clone(x.AxisLengths), axis, axis+1)}, nil
+}
+
+// CumShape returns the shape of one of the CumSum or CumProd operations applied to x along an axis.
+// Cumulative operations are not supported on booleans.
+func CumShape(op ops.OpID, x *shape.Shape, axis int) (*shape.Shape, error) {
+	if _, err := shape.NormalizeAxis(axis, len(x.AxisLengths)); err != nil {
+		return nil, err
+	}
+	if x.DType == dtype.Bool {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	return x, nil
+}
+
+// TopKShape returns the shape of the k largest elements of x along its last axis.
+// The shape of the indices is the same with the data type int32.
+func TopKShape(x *shape.Shape, k int) (*shape.Shape, error) {
+	rank := len(x.AxisLengths)
+	if rank == 0 {
+		return nil, fmt.Errorf("cannot select the top elements of atomic value %s", x)
+	}
+	if k < 0 || k > x.AxisLengths[rank-1] {
+		return nil, fmt.Errorf("k=%d out of range [0, %d]", k, x.AxisLengths[rank-1])
+	}
+	axisLengths := slices.Clone(x.AxisLengths)
+	axisLengths[rank-1] = k
+	return &shape.Shape{DType: x.DType, AxisLengths: axisLengths}, nil
+}
+
+// PadShape returns the shape of x padded with the atomic value.
+func PadShape(x, value *shape.Shape, low, high, interior []int) (*shape.Shape, error) {
+	if !value.IsAtomic() {
+		return nil, fmt.Errorf("padding value %s is not atomic", value)
+	}
+	if value.DType != x.DType {
+		return nil, fmt.Errorf("cannot pad %s values with a value of type %s", x.DType, value.DType)
+	}
+	return shape.PadShape(x, low, high, interior)
+}
+
+// checkAxisList returns an error if an axis of a list is out of [0, rank) or is repeated.
+func checkAxisList(name string, axes []int, rank int) error {
+	for i, axis := range axes {
+		if axis < 0 || axis >= rank {
+			return fmt.Errorf("%s axis %d out of range [0, %d)", name, axis, rank)
+		}
+		if slices.Contains(axes[:i], axis) {
+			return fmt.Errorf("%s axis %d specified more than once in %v", name, axis, axes)
+		}
+	}
+	return nil
+}
+
+// indexBatch returns the lengths of the batch axes of indices and the length of its index vectors.
+func indexBatch(indices *shape.Shape, indexVectorAxis int) ([]int, int, error) {
+	if !dtype.IsInteger(indices.DType) {
+		return nil, 0, fmt.Errorf("indices of type %s are not integers", indices.DType)
+	}
+	rank := len(indices.AxisLengths)
+	switch {
+	case indexVectorAxis == rank:
+		return slices.Clone(indices.AxisLengths), 1, nil
+	case indexVectorAxis < 0 || indexVectorAxis > rank:
+		return nil, 0, fmt.Errorf("index vector axis %d out of range [0, %d]", indexVectorAxis, rank)
+	}
+	batch := slices.Delete(slices.Clone(indices.AxisLengths), indexVectorAxis, indexVectorAxis+1)
+	return batch, indices.AxisLengths[indexVectorAxis], nil
+}
+
+// GatherShape returns the shape of the slices of x gathered at indices.
+func GatherShape(x, indices *shape.Shape, dims ops.GatherDims, sliceSizes []int) (*shape.Shape, error) {
+	rank := len(x.AxisLengths)
+	if len(sliceSizes) != rank {
+		return nil, fmt.Errorf("got %d slice sizes to gather from %s", len(sliceSizes), x)
+	}
+	for axis, size := range sliceSizes {
+		if size < 0 || size > x.AxisLengths[axis] {
+			return nil, fmt.Errorf("slice size %d out of range [0, %d] along axis %d", size, x.AxisLengths[axis], axis)
+		}
+	}
+	if err := checkAxisList("collapsed", dims.CollapsedAxes, rank); err != nil {
+		return nil, err
+	}
+	if err := checkAxisList("start index", dims.StartIndexMap, rank); err != nil {
+		return nil, err
+	}
+	batch, vectorLength, err := indexBatch(indices, dims.IndexVectorAxis)
+	if err != nil {
+		return nil, err
+	}
+	if vectorLength != len(dims.StartIndexMap) {
+		return nil, fmt.Errorf("index vectors of length %d do not match start index map %v", vectorLength, dims.StartIndexMap)
+	}
+	var offsetLengths []int
+	for axis, size := range sliceSizes {
+		if !slices.Contains(dims.CollapsedAxes, axis) {
+			offsetLengths = append(offsetLengths, size)
+		} else if size != 1 {
+			return nil, fmt.Errorf("cannot collapse axis %d of slice size %d", axis, size)
+		}
+	}
+	outRank := len(batch) + len(offsetLengths)
+	if len(dims.OffsetAxes) != len(offsetLengths) {
+		return nil, fmt.Errorf("got %d offset axes but slices have %d axes", len(dims.OffsetAxes), len(offsetLengths))
+	}
+	if err := checkAxisList("offset", dims.OffsetAxes, outRank); err != nil {
+		return nil, err
+	}
+	if !slices.IsSorted(dims.OffsetAxes) {
+		return nil, fmt.Errorf("offset axes %v are not sorted", dims.OffsetAxes)
+	}
+	axisLengths := make([]int, outRank)
+	offsetAxes := dims.OffsetAxes
+	for axis := range axisLengths {
+		if len(offsetAxes) > 0 && offsetAxes[0] == axis {
+			axisLengths[axis], offsetLengths = offsetLengths[0], offsetLengths[1:]
+			offsetAxes = offsetAxes[1:]
+			continue
+		}
+		axisLengths[axis], batch = batch[0], batch[1:]
+	}
+	return &shape.Shape{DType: x.DType, AxisLengths: axisLengths}, nil
+}
+
+// ScatterShape returns the shape of x with windows replaced by updates at indices,
+// which is the shape of x.
+func ScatterShape(x, indices, updates *shape.Shape, dims ops.ScatterDims) (*shape.Shape, error) {
+	if x.DType != updates.DType {
+		return nil, fmt.Errorf("cannot scatter %s values into %s values", updates.DType, x.DType)
+	}
+	rank := len(x.AxisLengths)
+	if err := checkAxisList("inserted window", dims.InsertedWindowAxes, rank); err != nil {
+		return nil, err
+	}
+	if err := checkAxisList("scatter", dims.ScatterAxesToOperandAxes, rank); err != nil {
+		return nil, err
+	}
+	batch, vectorLength, err := indexBatch(indices, dims.IndexVectorAxis)
+	if err != nil {
+		return nil, err
+	}
+	if vectorLength != len(dims.ScatterAxesToOperandAxes) {
+		return nil, fmt.Errorf("index vectors of length %d do not match scatter axes %v", vectorLength, dims.ScatterAxesToOperandAxes)
+	}
+	updatesRank := len(updates.AxisLengths)
+	if updatesRank != len(batch)+len(dims.UpdateWindowAxes) {
+		return nil, fmt.Errorf("updates %s do not have %d batch axes and %d window axes", updates, len(batch), len(dims.UpdateWindowAxes))
+	}
+	if err := checkAxisList("update window", dims.UpdateWindowAxes, updatesRank); err != nil {
+		return nil, err
+	}
+	if !slices.IsSorted(dims.UpdateWindowAxes) {
+		return nil, fmt.Errorf("update window axes %v are not sorted", dims.UpdateWindowAxes)
+	}
+	if len(dims.UpdateWindowAxes)+len(dims.InsertedWindowAxes) != rank {
+		return nil, fmt.Errorf("%d window axes and %d inserted axes do not match operand %s", len(dims.UpdateWindowAxes), len(dims.InsertedWindowAxes), x)
+	}
+	var windowAxes []int
+	for axis := range rank {
+		if !slices.Contains(dims.InsertedWindowAxes, axis) {
+			windowAxes = append(windowAxes, axis)
+		}
+	}
+	for axis, length := range updates.AxisLengths {
+		if i := slices.Index(dims.UpdateWindowAxes, axis); i >= 0 {
+			if length > x.AxisLengths[windowAxes[i]] {
+				return nil, fmt.Errorf("update window of length %d along axis %d larger than operand %s", length, axis, x)
+			}
+			continue
+		}
+		if length != batch[0] {
+			return nil, fmt.Errorf("updates %s do not match the batch axes of indices %s", updates, indices)
+		}
+		batch = batch[1:]
+	}
+	return x, nil
+}
+
+// windowedLength returns the number of positions of a window sliding over an axis.
+func windowedLength(length, window, stride int, padding [2]int, baseDilation, windowDilation int) (int, error) {
+	if window <= 0 || stride <= 0 || baseDilation <= 0 || windowDilation <= 0 {
+		return 0, fmt.Errorf("window %d, stride %d and dilations %d, %d must be positive", window, stride, baseDilation, windowDilation)
+	}
+	dilated := 0
+	if length > 0 {
+		dilated = (length-1)*baseDilation + 1
+	}
+	padded := padding[0] + dilated + padding[1]
+	span := (window-1)*windowDilation + 1
+	if padded < span {
+		return 0, nil
+	}
+	return (padded-span)/stride + 1, nil
+}
+
+// orDefault returns the ith value of vals or def if vals is nil.
+func orDefault[T any](vals []T, i int, def T) T {
+	if vals == nil {
+		return def
+	}
+	return vals[i]
+}
+
+// checkLengths returns an error if a list of parameters is not nil and does not have n elements.
+func checkLengths(n int, params map[string]int) error {
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if length := params[name]; length >= 0 && length != n {
+			return fmt.Errorf("got %d %s for %d axes", length, name, n)
+		}
+	}
+	return nil
+}
+
+// lengthOf returns the length of a list of parameters or -1 if it is nil.
+func lengthOf[T any](vals []T) int {
+	if vals == nil {
+		return -1
+	}
+	return len(vals)
+}
+
+// WindowShape returns the shape of the reduction of the windows of x.
+func WindowShape(x *shape.Shape, window ops.Window) (*shape.Shape, error) {
+	rank := len(x.AxisLengths)
+	if err := checkLengths(rank, map[string]int{
+		"window dimensions": len(window.Dimensions),
+		"strides":           lengthOf(window.Strides),
+		"paddings":          lengthOf(window.Padding),
+		"base dilations":    lengthOf(window.BaseDilations),
+		"window dilations":  lengthOf(window.WindowDilations),
+	}); err != nil {
+		return nil, fmt.Errorf("invalid window for %s: %v", x, err)
+	}
+	axisLengths := make([]int, rank)
+	for axis, length := range x.AxisLengths {
+		var err error
+		if axisLengths[axis], err = windowedLength(length, window.Dimensions[axis],
+			orDefault(window.Strides, axis, 1),
+			orDefault(window.Padding, axis, [2]int{}),
+			orDefault(window.BaseDilations, axis, 1),
+			orDefault(window.WindowDilations, axis, 1)); err != nil {
+			return nil, fmt.Errorf("invalid window for axis %d of %s: %v", axis, x, err)
+		}
+	}
+	return &shape.Shape{DType: x.DType, AxisLengths: axisLengths}, nil
+}
+
+// ReduceWindowShape returns the shape of the reduction of the windows of x from the initial value init.
+func ReduceWindowShape(init, x *shape.Shape, window ops.Window) (*shape.Shape, error) {
+	if !init.IsAtomic() {
+		return nil, fmt.Errorf("initial value %s of a reduction is not atomic", init)
+	}
+	if init.DType != x.DType {
+		return nil, fmt.Errorf("cannot reduce %s values from an initial value of type %s", x.DType, init.DType)
+	}
+	return WindowShape(x, window)
+}
+
+// ConvShape returns the shape of the convolution of x with a kernel.
+func ConvShape(x, kernel *shape.Shape, dims ops.ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int) (*shape.Shape, error) {
+	if x.DType != kernel.DType {
+		return nil, fmt.Errorf("cannot convolve %s values with a kernel of %s values", x.DType, kernel.DType)
+	}
+	spatialRank := len(dims.InputSpatialAxes)
+	rank := spatialRank + 2
+	if len(x.AxisLengths) != rank || len(kernel.AxisLengths) != rank {
+		return nil, fmt.Errorf("input %s and kernel %s do not have %d axes", x, kernel, rank)
+	}
+	if err := checkLengths(spatialRank, map[string]int{
+		"kernel spatial axes": len(dims.KernelSpatialAxes),
+		"output spatial axes": len(dims.OutputSpatialAxes),
+		"strides":             lengthOf(strides),
+		"paddings":            lengthOf(padding),
+		"input dilations":     lengthOf(inputDilation),
+		"kernel dilations":    lengthOf(kernelDilation),
+	}); err != nil {
+		return nil, err
+	}
+	for _, axes := range []struct {
+		name string
+		axes []int
+	}{
+		{"input", append([]int{dims.InputBatchAxis, dims.InputFeatureAxis}, dims.InputSpatialAxes...)},
+		{"kernel", append([]int{dims.KernelInputFeatureAxis, dims.KernelOutputFeatureAxis}, dims.KernelSpatialAxes...)},
+		{"output", append([]int{dims.OutputBatchAxis, dims.OutputFeatureAxis}, dims.OutputSpatialAxes...)},
+	} {
+		if err := checkAxisList(axes.name, axes.axes, rank); err != nil {
+			return nil, err
+		}
+	}
+	if featureGroupCount <= 0 {
+		return nil, fmt.Errorf("feature group count %d is not positive", featureGroupCount)
+	}
+	inFeatures := x.AxisLengths[dims.InputFeatureAxis]
+	outFeatures := kernel.AxisLengths[dims.KernelOutputFeatureAxis]
+	if inFeatures != kernel.AxisLengths[dims.KernelInputFeatureAxis]*featureGroupCount {
+		return nil, fmt.Errorf("input with %d features does not match kernel %s with %d feature groups", inFeatures, kernel, featureGroupCount)
+	}
+	if outFeatures%featureGroupCount != 0 {
+		return nil, fmt.Errorf("%d output features cannot be split into %d groups", outFeatures, featureGroupCount)
+	}
+	axisLengths := make([]int, rank)
+	axisLengths[dims.OutputBatchAxis] = x.AxisLengths[dims.InputBatchAxis]
+	axisLengths[dims.OutputFeatureAxis] = outFeatures
+	for i, axis := range dims.InputSpatialAxes {
+		var err error
+		if axisLengths[dims.OutputSpatialAxes[i]], err = windowedLength(
+			x.AxisLengths[axis], kernel.AxisLengths[dims.KernelSpatialAxes[i]],
+			orDefault(strides, i, 1),
+			orDefault(padding, i, [2]int{}),
+			orDefault(inputDilation, i, 1),
+			orDefault(kernelDilation, i, 1)); err != nil {
+			return nil, fmt.Errorf("invalid convolution along spatial axis %d: %v", i, err)
+		}
+	}
+	return &shape.Shape{DType: x.DType, AxisLengths: axisLengths}, nil
+}
+
+// PoolShape returns the shape of one of the MaxPool or AvgPool operations applied to x.
+// Averages are only supported on floating-point values.
+func PoolShape(op ops.OpID, x *shape.Shape, window ops.Window) (*shape.Shape, error) {
+	if op == ops.OpAvgPool && !dtype.IsFloat(x.DType) && !dtype.IsHalf(x.DType) {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, x.DType)
+	}
+	return WindowShape(x, window)
+}
+
+// RandShape returns the shape of the values generated by one of the RngBitGenerator,
+// Uniform or Normal operations from a state.
+func RandShape(op ops.OpID, state, sh *shape.Shape) (*shape.Shape, error) {
+	if want := ops.RngStateShape(); !state.EqualIgnoringLayout(want) {
+		return nil, fmt.Errorf("random number generator state %s does not have shape %s", state, want)
+	}
+	if op == ops.OpRngBitGenerator {
+		if sh.DType != dtype.Uint32 && sh.DType != dtype.Uint64 {
+			return nil, fmt.Errorf("%s not supported on data type %s", op, sh.DType)
+		}
+	} else if !dtype.IsFloat(sh.DType) {
+		return nil, fmt.Errorf("%s not supported on data type %s", op, sh.DType)
+	}
+	return sh, nil
+}
+
+// AllReduceShape returns the shape of x reduced over a group of devices.
+func AllReduceShape(op platform.ReduceOp, x *shape.Shape) (*shape.Shape, error) {
+	switch {
+	case (op == platform.ReduceSum || op == platform.ReduceProd) && !dtype.IsAlgebra(x.DType):
+		return nil, fmt.Errorf("all-reduce %s not supported on data type %s", op, x.DType)
+	case (op == platform.ReduceMin || op == platform.ReduceMax) && dtype.IsComplex(x.DType):
+		return nil, fmt.Errorf("all-reduce %s not supported on data type %s", op, x.DType)
+	}
+	return x, nil
+}
+
+// AllGatherShape returns the shape of x concatenated along an axis over a group of devices.
+func AllGatherShape(x *shape.Shape, axis, groupSize int) (*shape.Shape, error) {
+	if axis < 0 || axis >= len(x.AxisLengths) {
+		return nil, fmt.Errorf("axis %d out of range for an array of rank %d", axis, len(x.AxisLengths))
+	}
+	axisLengths := slices.Clone(x.AxisLengths)
+	axisLengths[axis] *= groupSize
+	return x.WithAxes(axisLengths...), nil
+}
+
+// ReduceScatterShape returns the shape of the block of x received by each device of a group
+// once x has been reduced over the group and split along an axis.
+func ReduceScatterShape(op platform.ReduceOp, x *shape.Shape, axis, groupSize int) (*shape.Shape, error) {
+	if _, err := AllReduceShape(op, x); err != nil {
+		return nil, err
+	}
+	if axis < 0 || axis >= len(x.AxisLengths) {
+		return nil, fmt.Errorf("axis %d out of range for an array of rank %d", axis, len(x.AxisLengths))
+	}
+	if x.AxisLengths[axis]%groupSize != 0 {
+		return nil, fmt.Errorf("axis %d of length %d cannot be split in %d blocks of equal sizes", axis, x.AxisLengths[axis], groupSize)
+	}
+	axisLengths := slices.Clone(x.AxisLengths)
+	axisLengths[axis] /= groupSize
+	return x.WithAxes(axisLengths...), nil
+}
+
+// CollectivePermuteShape returns the shape of x sent between the devices along a mesh axis
+// of a given size. Each device can be the target of at most one pair.
+func CollectivePermuteShape(x *shape.Shape, axisSize int, pairs [][2]int) (*shape.Shape, error) {
+	targets := make(map[int]bool)
+	for _, pair := range pairs {
+		for _, c := range pair {
+			if c < 0 || c >= axisSize {
+				return nil, fmt.Errorf("coordinate %d of pair %v out of range [0, %d)", c, pair, axisSize)
+			}
+		}
+		if targets[pair[1]] {
+			return nil, fmt.Errorf("device %d is the target of more than one pair", pair[1])
+		}
+		targets[pair[1]] = true
+	}
+	return x, nil
+}
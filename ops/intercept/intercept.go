@@ -0,0 +1,562 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package intercept wraps the graph of a backend to observe or check every builder call.
+//
+// A wrapped graph forwards all the calls to the graph of the backend. Before and after
+// each call, an Interceptor is notified with a description of the call, including the
+// shape of the result inferred from the shapes of the inputs.
+package intercept
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Operations which are not builder methods.
+const (
+	// OpElement extracts an element from a tuple.
+	OpElement ops.OpID = "tuple.Element"
+	// OpCompile compiles a graph.
+	OpCompile ops.OpID = "graph.Compile"
+	// OpRun runs a compiled graph.
+	OpRun ops.OpID = "runner.Run"
+)
+
+type (
+	// Attr is a named parameter of a call which is not a node.
+	Attr struct {
+		Name  string
+		Value any
+	}
+
+	// Call describes a call to a builder, to Compile or to Run.
+	Call struct {
+		// Op is the operation.
+		Op ops.OpID
+		// Graph in which the call is made.
+		Graph *Graph
+		// Inputs of the operation.
+		Inputs []*Node
+		// Attrs are the other parameters of the operation.
+		Attrs []Attr
+		// Shape is the inferred shape of the result or nil if it cannot be inferred.
+		Shape *shape.Shape
+		// ShapeErr is the error returned by the shape inference, if any.
+		ShapeErr error
+		// Source is the GX source location of the call, as set by Graph.SetSource
+		// or Graph.SetNodeInfo, or the source of the graph if no location has been set.
+		Source string
+		// Info is the GX source information of the call, as set by Graph.SetNodeInfo.
+		Info ops.NodeInfo
+		// Start is the time at which the call started.
+		Start time.Time
+		// Node created by the call. Only set after a successful builder call.
+		Node *Node
+		// Subgraph created by the call. Only set after a successful call to Subgraph.
+		Subgraph *Graph
+	}
+
+	// Interceptor is notified of the calls made on a graph.
+	Interceptor interface {
+		// Before is called before the call is forwarded to the backend.
+		// The call is aborted if an error is returned.
+		Before(call *Call) error
+
+		// After is called once the call has completed with the error of the call, if any.
+		After(call *Call, err error)
+	}
+)
+
+// Attr returns the value of an attribute of the call or nil if the attribute does not exist.
+func (c *Call) Attr(name string) any {
+	for _, attr := range c.Attrs {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+// Provenance returns a description of the call used to annotate its errors.
+func (c *Call) Provenance() ops.Provenance {
+	prov := ops.Provenance{Op: c.Op, Source: c.Source, Pos: c.Info.Pos}
+	if name, ok := c.Attr("name").(string); ok {
+		prov.Name = name
+	} else {
+		prov.Name = c.Info.Name
+	}
+	if c.Graph != nil {
+		prov.GraphPath = c.Graph.Path()
+	}
+	if len(c.Inputs) > 0 {
+		prov.OperandShapes = make([]*shape.Shape, len(c.Inputs))
+		for i, in := range c.Inputs {
+			prov.OperandShapes[i] = in.shape
+		}
+	}
+	return prov
+}
+
+// Duration returns the time elapsed since the start of the call.
+func (c *Call) Duration() time.Duration {
+	return time.Since(c.Start)
+}
+
+// Node wraps a node of the backend graph.
+type Node struct {
+	inner ops.Node
+	graph *Graph
+	id    int
+	call  *Call
+	shape *shape.Shape
+}
+
+var _ ops.InspectableNode = (*Node)(nil)
+
+// Graph returns the wrapped graph owning the node.
+func (n *Node) Graph() ops.Graph {
+	return n.graph
+}
+
+// Inner returns the node of the backend graph.
+func (n *Node) Inner() ops.Node {
+	return n.inner
+}
+
+// ID returns the identifier of the node, unique within the root graph.
+func (n *Node) ID() int {
+	return n.id
+}
+
+// Call returns the call which created the node.
+func (n *Node) Call() *Call {
+	return n.call
+}
+
+// Op returns the operation which created the node.
+func (n *Node) Op() ops.OpID {
+	return n.call.Op
+}
+
+// Operands returns the input nodes of the call which created the node.
+func (n *Node) Operands() []ops.Node {
+	operands := make([]ops.Node, len(n.call.Inputs))
+	for i, input := range n.call.Inputs {
+		operands[i] = input
+	}
+	return operands
+}
+
+// Shape returns the inferred shape of the node or nil if it is unknown.
+func (n *Node) Shape() *shape.Shape {
+	return n.shape
+}
+
+// String returns the identifier and the operation of the node.
+func (n *Node) String() string {
+	return fmt.Sprintf("%%%d = %s", n.id, n.call.Op)
+}
+
+// Tuple wraps a tuple of the backend graph.
+type Tuple struct {
+	*Node
+	tuple ops.Tuple
+}
+
+var _ ops.Tuple = (*Tuple)(nil)
+
+// Element returns a node representing the ith element of the tuple.
+func (t *Tuple) Element(i int) (ops.Node, error) {
+	return t.graph.apply(OpElement, []*Node{t.Node}, []Attr{{"index", i}}, nil, func() (ops.Node, error) {
+		return t.tuple.Element(i)
+	})
+}
+
+// Size returns the number of elements in the tuple.
+func (t *Tuple) Size() int {
+	return t.tuple.Size()
+}
+
+// Unpack returns the elements of the tuple.
+func (t *Tuple) Unpack() ([]ops.Node, error) {
+	nodes := make([]ops.Node, t.Size())
+	for i := range nodes {
+		var err error
+		if nodes[i], err = t.Element(i); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// counter generates node identifiers, allocates nodes, and tracks the current
+// source location for a graph and its subgraphs.
+type counter struct {
+	next   int
+	nodes  *ops.Arena[Node]
+	source string
+	info   ops.NodeInfo
+}
+
+// Graph wraps the graph of a backend.
+type Graph struct {
+	inner  ops.Graph
+	icpt   Interceptor
+	name   string
+	parent *Graph
+	ids    *counter
+	nodes  []*Node
+	subs   []*Graph
+	config ops.GraphConfig
+}
+
+var (
+	_ ops.InspectableGraph = (*Graph)(nil)
+	_ ops.DonationCompiler = (*Graph)(nil)
+	_ ops.NodeInfoRecorder = (*Graph)(nil)
+)
+
+// Wrap returns a graph forwarding all calls to a backend graph and notifying an interceptor.
+func Wrap(inner ops.Graph, name string, icpt Interceptor) *Graph {
+	return &Graph{inner: inner, icpt: icpt, name: name, ids: &counter{nodes: ops.NewArena[Node](0)}}
+}
+
+// Inner returns the graph of the backend.
+func (g *Graph) Inner() ops.Graph {
+	return g.inner
+}
+
+// Name of the graph.
+func (g *Graph) Name() string {
+	return g.name
+}
+
+// Parent returns the graph in which the subgraph has been created, or nil for a root graph.
+func (g *Graph) Parent() *Graph {
+	return g.parent
+}
+
+// Path returns the names of the graph and its parents separated by slashes.
+func (g *Graph) Path() string {
+	if g.parent == nil {
+		return g.name
+	}
+	return g.parent.Path() + "/" + g.name
+}
+
+// SetSource sets the GX source location, for example "main.gx:12:3", attached to
+// the calls made from now on in the graph and its subgraphs. Errors returned by
+// these calls are annotated with the location.
+func (g *Graph) SetSource(loc string) {
+	g.ids.source = loc
+}
+
+// SetNodeInfo sets the GX source information attached to the calls and nodes created
+// from now on in the graph and its subgraphs. The position of the information becomes
+// the source location of the calls. The information is also forwarded to the backend graph.
+func (g *Graph) SetNodeInfo(info ops.NodeInfo) {
+	g.ids.info = info
+	g.ids.source = ""
+	if info.Pos.Filename != "" || info.Pos.IsValid() {
+		g.ids.source = info.Pos.String()
+	}
+	ops.SetNodeInfo(g.inner, info)
+}
+
+// NodeInfo returns the GX source information attached to a node of the graph.
+func (g *Graph) NodeInfo(n ops.Node) (ops.NodeInfo, bool) {
+	node, err := g.unwrap(n)
+	if err != nil || node.call == nil {
+		return ops.NodeInfo{}, false
+	}
+	return node.call.Info, true
+}
+
+// outerProvenance replaces the backend node in the provenance of an error by its wrapper
+// and completes the provenance with the information recorded by the call of the wrapper.
+func (g *Graph) outerProvenance(err error) error {
+	var pErr *ops.ProvenanceError
+	if !errors.As(err, &pErr) || pErr.Node == nil {
+		return err
+	}
+	outer, ok := g.wrappedNodes()[pErr.Node].(*Node)
+	if !ok {
+		return err
+	}
+	pErr.Node = outer
+	if outer.call == nil {
+		return err
+	}
+	if pErr.Name == "" {
+		pErr.Name = outer.call.Info.Name
+	}
+	if pErr.Source == "" && !pErr.Pos.IsValid() {
+		pErr.Source = outer.call.Source
+	}
+	return err
+}
+
+// Source returns the GX source location attached to new calls.
+func (g *Graph) Source() string {
+	if g.ids.source != "" {
+		return g.ids.source
+	}
+	return g.Config().Metadata[ops.MetadataSource]
+}
+
+// newCall returns a call made in the graph.
+func (g *Graph) newCall(op ops.OpID, inputs []*Node, attrs []Attr) *Call {
+	return &Call{Op: op, Graph: g, Inputs: inputs, Attrs: attrs, Source: g.Source(), Info: g.ids.info, Start: time.Now()}
+}
+
+// fail annotates the error of a call with its provenance and notifies the interceptor.
+func (g *Graph) fail(call *Call, err error) error {
+	err = ops.WithProvenance(err, call.Provenance())
+	g.icpt.After(call, err)
+	return err
+}
+
+// Config returns the configuration with which the root graph has been created.
+func (g *Graph) Config() ops.GraphConfig {
+	if g.parent != nil {
+		return g.parent.Config()
+	}
+	return g.config
+}
+
+// Subgraphs returns the subgraphs created by the graph, in creation order.
+func (g *Graph) Subgraphs() []*Graph {
+	return g.subs
+}
+
+// Nodes returns all the nodes created in the graph, in creation order.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Inspect returns the nodes and the subgraphs created by the graph, in creation order.
+func (g *Graph) Inspect() (nodes []ops.Node, subgraphs []ops.Graph) {
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+	for _, sub := range g.subs {
+		subgraphs = append(subgraphs, sub)
+	}
+	return nodes, subgraphs
+}
+
+// Platform used by the graph.
+func (g *Graph) Platform() platform.Platform {
+	return g.inner.Platform()
+}
+
+// Core returns the builder for core operations.
+func (g *Graph) Core() ops.CoreBuilder {
+	return coreBuilder{g: g}
+}
+
+// Num returns the builder for functions of the num package.
+func (g *Graph) Num() ops.NumBuilder {
+	return numBuilder{g: g}
+}
+
+// Math returns the builder for functions of the math package.
+func (g *Graph) Math() ops.MathBuilder {
+	return mathBuilder{g: g}
+}
+
+// DType returns the builder for functions of the dtype package.
+func (g *Graph) DType() ops.DTypeBuilder {
+	return dtypeBuilder{g: g}
+}
+
+// NN returns the builder for neural network operations.
+func (g *Graph) NN() ops.NNBuilder {
+	return nnBuilder{g: g}
+}
+
+// Rand returns the builder for random number generation.
+func (g *Graph) Rand() ops.RandBuilder {
+	return randBuilder{g: g}
+}
+
+// Compile the graph for a given device.
+func (g *Graph) Compile(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	attrs := []Attr{
+		{"device", dev.Ordinal()},
+		{"outputs", outputShapes(output)},
+		{"traced", outputShapes(traced)},
+		{"params", params},
+	}
+	runner, err := g.compile(attrs, output, traced, func(output, traced []*ops.OutputNode) (ops.Runner, error) {
+		return g.inner.Compile(dev, output, traced, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wrapRunner(g, runner, params), nil
+}
+
+// CompileDonated compiles the graph for a given device, donating the arguments of some parameters.
+func (g *Graph) CompileDonated(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape, donated []int) (ops.Runner, error) {
+	attrs := []Attr{
+		{"device", dev.Ordinal()},
+		{"outputs", outputShapes(output)},
+		{"traced", outputShapes(traced)},
+		{"params", params},
+		{"donated", donated},
+	}
+	runner, err := g.compile(attrs, output, traced, func(output, traced []*ops.OutputNode) (ops.Runner, error) {
+		return ops.CompileDonated(g.inner, dev, output, traced, params, donated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wrapRunner(g, runner, params), nil
+}
+
+// compile notifies the interceptor and calls compile with the outputs of the backend graph.
+func (g *Graph) compile(attrs []Attr, output, traced []*ops.OutputNode, compile func(output, traced []*ops.OutputNode) (ops.Runner, error)) (ops.Runner, error) {
+	call := g.newCall(OpCompile, nil, attrs)
+	for _, out := range append(append([]*ops.OutputNode{}, output...), traced...) {
+		node, err := g.unwrap(out.Node)
+		if err != nil {
+			return nil, err
+		}
+		call.Inputs = append(call.Inputs, node)
+	}
+	if err := g.icpt.Before(call); err != nil {
+		return nil, g.fail(call, err)
+	}
+	innerOutput, err := g.unwrapOutputs(output)
+	if err != nil {
+		return nil, g.fail(call, err)
+	}
+	innerTraced, err := g.unwrapOutputs(traced)
+	if err != nil {
+		return nil, g.fail(call, err)
+	}
+	runner, err := compile(innerOutput, innerTraced)
+	if err != nil {
+		return nil, g.fail(call, g.outerProvenance(err))
+	}
+	g.icpt.After(call, nil)
+	return runner, nil
+}
+
+func outputShapes(outs []*ops.OutputNode) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(outs))
+	for i, out := range outs {
+		shapes[i] = out.Shape
+	}
+	return shapes
+}
+
+// unwrap returns the wrapper of a node. It returns an error if the node has not been
+// created by a wrapped graph.
+func (g *Graph) unwrap(n ops.Node) (*Node, error) {
+	switch nT := n.(type) {
+	case *Node:
+		return nT, nil
+	case *Tuple:
+		return nT.Node, nil
+	}
+	return nil, fmt.Errorf("node %T has not been created by an intercepted graph", n)
+}
+
+func (g *Graph) unwrapAll(nodes []ops.Node) ([]*Node, error) {
+	res := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		var err error
+		if res[i], err = g.unwrap(n); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (g *Graph) unwrapOutputs(outs []*ops.OutputNode) ([]*ops.OutputNode, error) {
+	res := make([]*ops.OutputNode, len(outs))
+	for i, out := range outs {
+		node, err := g.unwrap(out.Node)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = &ops.OutputNode{Node: node.inner, Shape: out.Shape, Sharding: out.Sharding}
+	}
+	return res, nil
+}
+
+func (g *Graph) unwrapSubgraph(sg *ops.Subgraph) (*ops.Subgraph, *Graph, error) {
+	wrapped, ok := sg.Graph.(*Graph)
+	if !ok {
+		return nil, nil, fmt.Errorf("subgraph %T has not been created by an intercepted graph", sg.Graph)
+	}
+	result, err := wrapped.unwrap(sg.Result.Node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ops.Subgraph{
+		Graph:  wrapped.inner,
+		Result: ops.OutputNode{Node: result.inner, Shape: sg.Result.Shape},
+	}, wrapped, nil
+}
+
+// apply notifies the interceptor, forwards a builder call to the backend, and wraps the result.
+func (g *Graph) apply(op ops.OpID, inputs []*Node, attrs []Attr, infer func() (*shape.Shape, error), build func() (ops.Node, error)) (ops.Node, error) {
+	call := g.newCall(op, inputs, attrs)
+	if infer != nil && knownShapes(inputs) {
+		call.Shape, call.ShapeErr = infer()
+	}
+	if err := g.icpt.Before(call); err != nil {
+		return nil, g.fail(call, err)
+	}
+	inner, err := build()
+	if err != nil {
+		return nil, g.fail(call, err)
+	}
+	node := g.ids.nodes.New()
+	*node = Node{inner: inner, graph: g, id: g.ids.next, call: call, shape: call.Shape}
+	g.ids.next++
+	g.nodes = append(g.nodes, node)
+	call.Node = node
+	g.icpt.After(call, nil)
+	if tuple, ok := inner.(ops.Tuple); ok {
+		return &Tuple{Node: node, tuple: tuple}, nil
+	}
+	return node, nil
+}
+
+func knownShapes(nodes []*Node) bool {
+	for _, n := range nodes {
+		if n.shape == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func inners(nodes []*Node) []ops.Node {
+	res := make([]ops.Node, len(nodes))
+	for i, n := range nodes {
+		res[i] = n.inner
+	}
+	return res
+}
@@ -0,0 +1,574 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+type recorder struct {
+	calls  []*intercept.Call
+	reject ops.OpID
+}
+
+func (r *recorder) Before(call *intercept.Call) error {
+	if call.Op == r.reject {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func (r *recorder) After(call *intercept.Call, err error) {
+	if err == nil {
+		r.calls = append(r.calls, call)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	rec := &recorder{}
+	b := intercept.NewBackend(inner, rec)
+	g, err := b.NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Math().Exp(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmp, err := g.Core().Binary(&ast.BinaryExpr{Op: token.LSS}, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cmp.(*intercept.Node).Shape().String(), "[2][3]bool"; got != want {
+		t.Errorf("comparison has shape %s but want %s", got, want)
+	}
+	if got, want := inner.Graphs()[0].Ops(), []ops.OpID{ops.OpArgument, ops.OpExp, ops.OpBinary}; !slices.Equal(got, want) {
+		t.Errorf("backend got ops %v but want %v", got, want)
+	}
+	dev, err := g.Platform().Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: cmp, Shape: shape.Of(dtype.Bool, 2, 3)}}, nil, []*shape.Shape{shape.Of(dtype.Float32, 2, 3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := dev.Send(make([]byte, 24), shape.Of(dtype.Float32, 2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runner.Run([]platform.Handle{arg}); err != nil {
+		t.Fatal(err)
+	}
+	var got []ops.OpID
+	for _, call := range rec.calls {
+		got = append(got, call.Op)
+	}
+	want := []ops.OpID{ops.OpArgument, ops.OpExp, ops.OpBinary, intercept.OpCompile, intercept.OpRun}
+	if !slices.Equal(got, want) {
+		t.Errorf("interceptor got calls %v but want %v", got, want)
+	}
+}
+
+func TestReject(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	b := intercept.NewBackend(inner, &recorder{reject: ops.OpReshape})
+	g, err := b.NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Core().Reshape(x, []int{2, 3}); err == nil {
+		t.Errorf("rejected call did not return an error")
+	}
+	if got := len(inner.Graphs()[0].Nodes()); got != 1 {
+		t.Errorf("rejected call forwarded to the backend: got %d nodes but want 1", got)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	b := intercept.NewBackend(inner, &recorder{reject: ops.OpReshape})
+	g, err := b.NewOps("main", ops.WithMetadata(ops.MetadataSource, "main.gx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.(*intercept.Graph).SetSource("main.gx:3:5")
+	_, err = g.Core().Reshape(x, []int{2, 3})
+	prov, ok := ops.ProvenanceOf(err)
+	if !ok {
+		t.Fatalf("error %v has no provenance", err)
+	}
+	if prov.Op != ops.OpReshape || prov.Source != "main.gx:3:5" || prov.GraphPath != "main" {
+		t.Errorf("got provenance %+v", prov)
+	}
+	if got, want := err.Error(), "main: core.Reshape([6]float32) at main.gx:3:5: rejected"; got != want {
+		t.Errorf("got error %q but want %q", got, want)
+	}
+}
+
+func TestNodeInfo(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	b := intercept.NewBackend(inner, &recorder{reject: ops.OpReshape})
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := ops.NodeInfo{Name: "x", Pos: token.Position{Filename: "main.gx", Line: 3, Column: 5}}
+	if !ops.SetNodeInfo(g, info) {
+		t.Fatalf("graph %T does not record node information", g)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := ops.NodeInfoOf(x); !ok || got != info {
+		t.Errorf("got node information %+v, %v but want %+v", got, ok, info)
+	}
+	ops.SetNodeInfo(g, ops.NodeInfo{Name: "y", Pos: token.Position{Filename: "main.gx", Line: 4, Column: 2}})
+	_, err = g.Core().Reshape(x, []int{2, 3})
+	if prov, ok := ops.ProvenanceOf(err); !ok || prov.Source != "main.gx:4:2" || prov.Name != "y" {
+		t.Errorf("got provenance %+v, %v", prov, ok)
+	}
+}
+
+func TestBackendNodeProvenance(t *testing.T) {
+	inner, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inner.Close()
+	b := intercept.NewBackend(inner, &recorder{})
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := ops.NodeInfo{Name: "x", Pos: token.Position{Filename: "main.gx", Line: 3, Column: 5}}
+	ops.SetNodeInfo(g, info)
+	param := shape.Of(dtype.Float32, 2)
+	x, err := g.Core().Argument("x", param, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := inner.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: shape.Of(dtype.Float32, 3)}}, nil, []*shape.Shape{param})
+	prov, ok := ops.ProvenanceOf(err)
+	if !ok {
+		t.Fatalf("got error %v but want an error annotated with the provenance of the node", err)
+	}
+	if prov.Node != x || prov.Name != info.Name || prov.Pos != info.Pos {
+		t.Errorf("got provenance %+v but want node %v with %+v", prov, x, info)
+	}
+}
+
+func TestShapes(t *testing.T) {
+	f32 := shape.Of(dtype.Float32, 2, 3)
+	tests := []struct {
+		name    string
+		infer   func() (*shape.Shape, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "binary scalar",
+			infer: func() (*shape.Shape, error) {
+				return intercept.BinaryShape(token.ADD, shape.Scalar(dtype.Float32), f32)
+			},
+			want: "[2][3]float32",
+		},
+		{
+			name: "binary mismatch",
+			infer: func() (*shape.Shape, error) {
+				return intercept.BinaryShape(token.ADD, shape.Of(dtype.Float32, 3, 2), f32)
+			},
+			wantErr: true,
+		},
+		{
+			name: "binary dtype",
+			infer: func() (*shape.Shape, error) {
+				return intercept.BinaryShape(token.ADD, shape.Of(dtype.Int32, 2, 3), f32)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not on floats",
+			infer:   func() (*shape.Shape, error) { return intercept.UnaryShape(token.NOT, f32) },
+			wantErr: true,
+		},
+		{
+			name:    "dequantize",
+			infer:   func() (*shape.Shape, error) { return intercept.DequantizeShape(f32) },
+			wantErr: true,
+		},
+		{
+			name:  "reduce sum",
+			infer: func() (*shape.Shape, error) { return intercept.ReduceOpShape(ops.OpReduceSum, f32, []int{1}) },
+			want:  "[2]float32",
+		},
+		{
+			name: "reduce sum on booleans",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ReduceOpShape(ops.OpReduceSum, shape.Of(dtype.Bool, 2), []int{0})
+			},
+			wantErr: true,
+		},
+		{
+			name: "reduce",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ReduceShape(shape.Scalar(dtype.Float32), f32, []int{0, 1})
+			},
+			want: "float32",
+		},
+		{
+			name:    "reduce non-atomic init",
+			infer:   func() (*shape.Shape, error) { return intercept.ReduceShape(f32, f32, []int{0}) },
+			wantErr: true,
+		},
+		{
+			name: "gather rows",
+			infer: func() (*shape.Shape, error) {
+				dims := ops.GatherDims{OffsetAxes: []int{1}, CollapsedAxes: []int{0}, StartIndexMap: []int{0}, IndexVectorAxis: 1}
+				return intercept.GatherShape(shape.Of(dtype.Float32, 10, 4), shape.Of(dtype.Int32, 3), dims, []int{1, 4})
+			},
+			want: "[3][4]float32",
+		},
+		{
+			name: "gather collapsing a slice larger than 1",
+			infer: func() (*shape.Shape, error) {
+				dims := ops.GatherDims{OffsetAxes: []int{1}, CollapsedAxes: []int{0}, StartIndexMap: []int{0}, IndexVectorAxis: 1}
+				return intercept.GatherShape(shape.Of(dtype.Float32, 10, 4), shape.Of(dtype.Int32, 3), dims, []int{2, 4})
+			},
+			wantErr: true,
+		},
+		{
+			name: "scatter rows",
+			infer: func() (*shape.Shape, error) {
+				dims := ops.ScatterDims{UpdateWindowAxes: []int{1}, InsertedWindowAxes: []int{0}, ScatterAxesToOperandAxes: []int{0}, IndexVectorAxis: 1}
+				return intercept.ScatterShape(shape.Of(dtype.Float32, 10, 4), shape.Of(dtype.Int32, 3), shape.Of(dtype.Float32, 3, 4), dims)
+			},
+			want: "[10][4]float32",
+		},
+		{
+			name: "scatter batch mismatch",
+			infer: func() (*shape.Shape, error) {
+				dims := ops.ScatterDims{UpdateWindowAxes: []int{1}, InsertedWindowAxes: []int{0}, ScatterAxesToOperandAxes: []int{0}, IndexVectorAxis: 1}
+				return intercept.ScatterShape(shape.Of(dtype.Float32, 10, 4), shape.Of(dtype.Int32, 3), shape.Of(dtype.Float32, 2, 4), dims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "pad with an array",
+			infer: func() (*shape.Shape, error) {
+				return intercept.PadShape(f32, f32, []int{0, 0}, []int{1, 1}, []int{0, 0})
+			},
+			wantErr: true,
+		},
+		{
+			name: "select atomic predicate",
+			infer: func() (*shape.Shape, error) {
+				return intercept.SelectShape(shape.Scalar(dtype.Bool), f32, f32)
+			},
+			want: "[2][3]float32",
+		},
+		{
+			name: "select predicate mismatch",
+			infer: func() (*shape.Shape, error) {
+				return intercept.SelectShape(shape.Of(dtype.Bool, 3), f32, f32)
+			},
+			wantErr: true,
+		},
+		{
+			name: "cond branch mismatch",
+			infer: func() (*shape.Shape, error) {
+				return intercept.CondShape(shape.Scalar(dtype.Bool), f32, shape.Of(dtype.Float32, 3))
+			},
+			wantErr: true,
+		},
+		{
+			name: "reduce axis out of range",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ReduceOpShape(ops.OpReduceMax, f32, []int{2})
+			},
+			wantErr: true,
+		},
+		{
+			name: "convolution with stride and padding",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ConvShape(shape.Of(dtype.Float32, 1, 5, 5, 2), shape.Of(dtype.Float32, 3, 3, 2, 4), ops.NHWC(2), []int{2, 2}, [][2]int{{1, 1}, {1, 1}}, nil, nil, 1)
+			},
+			want: "[1][3][3][4]float32",
+		},
+		{
+			name: "convolution feature mismatch",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ConvShape(shape.Of(dtype.Float32, 1, 5, 5, 2), shape.Of(dtype.Float32, 3, 3, 3, 4), ops.NHWC(2), nil, nil, nil, nil, 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "window with dilation",
+			infer: func() (*shape.Shape, error) {
+				return intercept.WindowShape(f32, ops.Window{Dimensions: []int{1, 2}, WindowDilations: []int{1, 2}})
+			},
+			want: "[2][1]float32",
+		},
+		{
+			name: "average pool of integers",
+			infer: func() (*shape.Shape, error) {
+				return intercept.PoolShape(ops.OpAvgPool, shape.Of(dtype.Int32, 2, 3), ops.Window{Dimensions: []int{1, 1}})
+			},
+			wantErr: true,
+		},
+		{
+			name: "random bits",
+			infer: func() (*shape.Shape, error) {
+				return intercept.RandShape(ops.OpRngBitGenerator, ops.RngStateShape(), shape.Of(dtype.Uint32, 2, 3))
+			},
+			want: "[2][3]uint32",
+		},
+		{
+			name: "uniform integers",
+			infer: func() (*shape.Shape, error) {
+				return intercept.RandShape(ops.OpUniform, ops.RngStateShape(), shape.Of(dtype.Int32, 2))
+			},
+			wantErr: true,
+		},
+		{
+			name: "normal with an invalid state",
+			infer: func() (*shape.Shape, error) {
+				return intercept.RandShape(ops.OpNormal, shape.Of(dtype.Uint32, 2), f32)
+			},
+			wantErr: true,
+		},
+		{
+			name: "argsort",
+			infer: func() (*shape.Shape, error) {
+				return intercept.SortShape(ops.OpArgSort, f32, -1)
+			},
+			want: "[2][3]int32",
+		},
+		{
+			name: "sort axis out of range",
+			infer: func() (*shape.Shape, error) {
+				return intercept.SortShape(ops.OpSort, f32, 2)
+			},
+			wantErr: true,
+		},
+		{
+			name: "top k",
+			infer: func() (*shape.Shape, error) {
+				return intercept.TopKShape(f32, 2)
+			},
+			want: "[2][2]float32",
+		},
+		{
+			name: "top k of an atomic value",
+			infer: func() (*shape.Shape, error) {
+				return intercept.TopKShape(shape.Scalar(dtype.Float32), 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "pow with an atomic exponent",
+			infer: func() (*shape.Shape, error) {
+				return intercept.MathBinaryShape(ops.OpPow, f32, shape.Scalar(dtype.Float32))
+			},
+			want: "[2][3]float32",
+		},
+		{
+			name: "atan2 of integers",
+			infer: func() (*shape.Shape, error) {
+				return intercept.MathBinaryShape(ops.OpAtan2, shape.Of(dtype.Int32, 2), shape.Of(dtype.Int32, 2))
+			},
+			wantErr: true,
+		},
+		{
+			name: "isnan",
+			infer: func() (*shape.Shape, error) {
+				return intercept.MathPredicateShape(ops.OpIsNaN, f32)
+			},
+			want: "[2][3]bool",
+		},
+		{
+			name: "complex with an atomic imaginary part",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ComplexShape(f32, shape.Scalar(dtype.Float32))
+			},
+			want: "[2][3]complex64",
+		},
+		{
+			name: "complex of integers",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ComplexShape(shape.Of(dtype.Int32, 2), shape.Of(dtype.Int32, 2))
+			},
+			wantErr: true,
+		},
+		{
+			name: "imaginary part",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ComplexPartShape(ops.OpImag, shape.Of(dtype.Complex128, 4))
+			},
+			want: "[4]float64",
+		},
+		{
+			name: "conjugate of a float",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ComplexPartShape(ops.OpConj, f32)
+			},
+			wantErr: true,
+		},
+		{
+			name: "argmax",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ArgShape(ops.OpArgmax, f32, 1)
+			},
+			want: "[2]int32",
+		},
+		{
+			name: "argmin along an empty axis",
+			infer: func() (*shape.Shape, error) {
+				return intercept.ArgShape(ops.OpArgmin, shape.Of(dtype.Float32, 0, 3), 0)
+			},
+			wantErr: true,
+		},
+		{
+			name: "cumsum",
+			infer: func() (*shape.Shape, error) {
+				return intercept.CumShape(ops.OpCumSum, f32, -1)
+			},
+			want: "[2][3]float32",
+		},
+		{
+			name: "cumprod of booleans",
+			infer: func() (*shape.Shape, error) {
+				return intercept.CumShape(ops.OpCumProd, shape.Of(dtype.Bool, 2), 0)
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		got, err := test.infer()
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error but got shape %s", test.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("%s: got %s but want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGraphOptions(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	b := intercept.NewBackend(inner, &recorder{})
+	if _, err := b.NewOps("test", ops.WithDebug(), ops.WithMetadata("source", "main.gx")); err != nil {
+		t.Fatal(err)
+	}
+	cfg := inner.Graphs()[0].Config()
+	if !cfg.Debug || cfg.Metadata["source"] != "main.gx" {
+		t.Errorf("options not forwarded to the backend: got %+v", cfg)
+	}
+}
+
+func TestCompileMesh(t *testing.T) {
+	plat := platformtest.New(2)
+	rec := &recorder{}
+	b := intercept.NewBackend(opstest.NewBackend(plat), rec)
+	devices, err := plat.Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh, err := platform.NewMesh(devices, []string{"data"}, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := b.NewOps("test", ops.WithMesh(mesh))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shardShape := shape.Of(dtype.Float32, 2)
+	x, err := g.Core().Argument("x", shardShape, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dist, err := ops.Dist(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dist.AllReduce(x, platform.ReduceSum, nil); err == nil {
+		t.Errorf("expected an error for a backend without collective operations")
+	}
+	runner, err := ops.CompileMesh(g, mesh,
+		[]*ops.OutputNode{{Node: x, Shape: shardShape, Sharding: platform.PartitionSpec{"data"}}}, nil,
+		[]*ops.MeshParam{{Shape: shardShape, Sharding: platform.PartitionSpec{"data"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := devices[0].Send(make([]byte, 16), shape.Of(dtype.Float32, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := runner.RunSharded([]platform.Handle{arg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, shard := range out[0].Shards() {
+		if shard.Device() != devices[i] {
+			t.Errorf("shard %d on device %d but want device %d", i, shard.Device().Ordinal(), i)
+		}
+	}
+	out[0].Free()
+	arg.Free()
+	if live := plat.Counts().Live; live != 0 {
+		t.Errorf("got %d live handles but want 0", live)
+	}
+	if got := rec.calls[len(rec.calls)-1].Op; got != intercept.OpRun {
+		t.Errorf("got last call %s but want %s", got, intercept.OpRun)
+	}
+}
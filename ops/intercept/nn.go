@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+type nnBuilder struct {
+	g *Graph
+}
+
+var _ ops.NNBuilder = nnBuilder{}
+
+func (b nnBuilder) ConvGeneralDilated(x, kernel ops.Node, dims ops.ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int, precision ops.Precision) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x, kernel})
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{
+		{"dims", dims},
+		{"strides", strides},
+		{"padding", padding},
+		{"inputDilation", inputDilation},
+		{"kernelDilation", kernelDilation},
+		{"featureGroupCount", featureGroupCount},
+		{"precision", precision},
+	}
+	return b.g.apply(ops.OpConvGeneralDilated, inputs, attrs, func() (*shape.Shape, error) {
+		return ConvShape(inputs[0].shape, inputs[1].shape, dims, strides, padding, inputDilation, kernelDilation, featureGroupCount)
+	}, func() (ops.Node, error) {
+		return b.g.inner.NN().ConvGeneralDilated(inputs[0].inner, inputs[1].inner, dims, strides, padding, inputDilation, kernelDilation, featureGroupCount, precision)
+	})
+}
+
+func (b nnBuilder) ReduceWindow(body *ops.Subgraph, init, x ops.Node, window ops.Window) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{init, x})
+	if err != nil {
+		return nil, err
+	}
+	innerBody, bodyGraph, err := b.g.unwrapSubgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []Attr{{"body", bodyGraph.name}, {"window", window}}
+	return b.g.apply(ops.OpReduceWindow, inputs, attrs, func() (*shape.Shape, error) {
+		return ReduceWindowShape(inputs[0].shape, inputs[1].shape, window)
+	}, func() (ops.Node, error) {
+		return b.g.inner.NN().ReduceWindow(innerBody, inputs[0].inner, inputs[1].inner, window)
+	})
+}
+
+// pool applies one of the MaxPool or AvgPool operations.
+func (b nnBuilder) pool(op ops.OpID, x ops.Node, window ops.Window, build func(ops.NNBuilder, ops.Node, ops.Window) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{x})
+	if err != nil {
+		return nil, err
+	}
+	return b.g.apply(op, inputs, []Attr{{"window", window}}, func() (*shape.Shape, error) {
+		return PoolShape(op, inputs[0].shape, window)
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.NN(), inputs[0].inner, window)
+	})
+}
+
+func (b nnBuilder) MaxPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	return b.pool(ops.OpMaxPool, x, window, ops.NNBuilder.MaxPool)
+}
+
+func (b nnBuilder) AvgPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	return b.pool(ops.OpAvgPool, x, window, ops.NNBuilder.AvgPool)
+}
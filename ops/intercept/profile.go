@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"time"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+var (
+	_ ops.InstrumentedCompiler = (*Graph)(nil)
+	_ ops.ProfilingRunner      = (*Runner)(nil)
+)
+
+// CompileInstrumented compiles the graph for a given device. inst is notified with
+// the nodes of the intercepted graph each time the backend has evaluated a node.
+func (g *Graph) CompileInstrumented(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape, inst ops.Instrumentation) (ops.Runner, error) {
+	attrs := []Attr{
+		{"device", dev.Ordinal()},
+		{"outputs", outputShapes(output)},
+		{"traced", outputShapes(traced)},
+		{"params", params},
+		{"instrumented", true},
+	}
+	wrapped := instrumentation{inst: inst, nodes: g.wrappedNodes()}
+	runner, err := g.compile(attrs, output, traced, func(output, traced []*ops.OutputNode) (ops.Runner, error) {
+		return ops.CompileInstrumented(g.inner, dev, output, traced, params, wrapped)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wrapRunner(g, runner, params), nil
+}
+
+// RunProfiled runs the compiled graph and returns its profile.
+// The timings of the profile are keyed by the nodes of the intercepted graph.
+func (r *Runner) RunProfiled(args []platform.Handle) (out, traces []platform.DeviceHandle, prof *ops.ExecutionProfile, err error) {
+	out, traces, err = runWith(r, args, nil, func(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+		out, traces, prof, err = ops.RunProfiled(r.inner, args)
+		return out, traces, err
+	})
+	if err != nil || prof.Nodes == nil {
+		return out, traces, prof, err
+	}
+	nodes := r.graph.wrappedNodes()
+	timings := prof.Nodes
+	prof.Nodes = make(map[ops.Node]ops.NodeTiming, len(timings))
+	for node, timing := range timings {
+		if wrapped, ok := nodes[node]; ok {
+			node = wrapped
+		}
+		prof.Nodes[node] = timing
+	}
+	return out, traces, prof, nil
+}
+
+// wrappedNodes maps the nodes of the backend to the nodes of the graph and of its subgraphs.
+func (g *Graph) wrappedNodes() map[ops.Node]ops.Node {
+	nodes := make(map[ops.Node]ops.Node)
+	var add func(*Graph)
+	add = func(g *Graph) {
+		for _, node := range g.nodes {
+			nodes[node.inner] = node
+		}
+		for _, sub := range g.subs {
+			add(sub)
+		}
+	}
+	add(g)
+	return nodes
+}
+
+// instrumentation notifies an instrumentation with the nodes of the intercepted graph.
+type instrumentation struct {
+	inst  ops.Instrumentation
+	nodes map[ops.Node]ops.Node
+}
+
+func (in instrumentation) NodeEvaluated(node ops.Node, op ops.OpID, d time.Duration) {
+	if wrapped, ok := in.nodes[node]; ok {
+		node = wrapped
+	}
+	in.inst.NodeEvaluated(node, op, d)
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/shape"
+)
+
+type randBuilder struct {
+	g *Graph
+}
+
+var _ ops.RandBuilder = randBuilder{}
+
+// generate applies one of the RngBitGenerator, Uniform or Normal operations.
+// The result is a tuple: its shape is not recorded.
+func (b randBuilder) generate(op ops.OpID, state ops.Node, sh *shape.Shape, attrs []Attr, build func(ops.RandBuilder, ops.Node) (ops.Node, error)) (ops.Node, error) {
+	inputs, err := b.g.unwrapAll([]ops.Node{state})
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, Attr{"shape", sh})
+	return b.g.apply(op, inputs, attrs, func() (*shape.Shape, error) {
+		_, err := RandShape(op, inputs[0].shape, sh)
+		return nil, err
+	}, func() (ops.Node, error) {
+		return build(b.g.inner.Rand(), inputs[0].inner)
+	})
+}
+
+func (b randBuilder) RngBitGenerator(algorithm ops.RngAlgorithm, state ops.Node, sh *shape.Shape) (ops.Node, error) {
+	return b.generate(ops.OpRngBitGenerator, state, sh, []Attr{{"algorithm", algorithm}}, func(inner ops.RandBuilder, state ops.Node) (ops.Node, error) {
+		return inner.RngBitGenerator(algorithm, state, sh)
+	})
+}
+
+func (b randBuilder) Uniform(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.generate(ops.OpUniform, state, sh, nil, func(inner ops.RandBuilder, state ops.Node) (ops.Node, error) {
+		return inner.Uniform(sh, state)
+	})
+}
+
+func (b randBuilder) Normal(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.generate(ops.OpNormal, state, sh, nil, func(inner ops.RandBuilder, state ops.Node) (ops.Node, error) {
+		return inner.Normal(sh, state)
+	})
+}
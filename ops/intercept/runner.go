@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intercept
+
+import (
+	"context"
+	"time"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Runner wraps the runner of a compiled graph to notify the interceptor of every run.
+	Runner struct {
+		inner  ops.Runner
+		graph  *Graph
+		params []*shape.Shape
+	}
+
+	// streamRunner is a runner also able to run on a stream.
+	streamRunner struct {
+		*Runner
+		stream ops.StreamRunner
+	}
+
+	// meshRunner is a runner compiled for a mesh.
+	meshRunner struct {
+		*Runner
+		mesh ops.MeshRunner
+	}
+)
+
+var (
+	_ ops.AsyncRunner  = (*Runner)(nil)
+	_ ops.StreamRunner = streamRunner{}
+	_ ops.MeshRunner   = meshRunner{}
+)
+
+func wrapRunner(g *Graph, inner ops.Runner, params []*shape.Shape) ops.Runner {
+	r := &Runner{inner: inner, graph: g, params: params}
+	if sr, ok := inner.(ops.StreamRunner); ok {
+		return streamRunner{Runner: r, stream: sr}
+	}
+	return r
+}
+
+// Inner returns the runner of the backend.
+func (r *Runner) Inner() ops.Runner {
+	return r.inner
+}
+
+// Params returns the shapes of the parameters for which the graph has been compiled.
+func (r *Runner) Params() []*shape.Shape {
+	return r.params
+}
+
+// Run the compiled graph.
+func (r *Runner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	return runWith(r, args, nil, r.inner.Run)
+}
+
+// RunAsync starts a run of the compiled graph. The interceptor is notified once the run has completed.
+func (r *Runner) RunAsync(ctx context.Context, args []platform.Handle) (ops.Execution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	exec := ops.NewHostExecution(cancel)
+	go func() {
+		exec.Complete(runWith(r, args, nil, func(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+			return ops.RunContext(ctx, r.inner, args)
+		}))
+	}()
+	return exec, nil
+}
+
+// runWith notifies the interceptor of a run made by calling run.
+func runWith[H platform.Handle](r *Runner, args []platform.Handle, stream platform.Stream, run func([]platform.Handle) (out, traces []H, err error)) (out, traces []H, err error) {
+	call := &Call{
+		Op:    OpRun,
+		Graph: r.graph,
+		Attrs: []Attr{{"args", handleShapes(args)}, {"params", r.params}},
+		Start: time.Now(),
+	}
+	if stream != nil {
+		call.Attrs = append(call.Attrs, Attr{"stream", stream})
+	}
+	if err := r.graph.icpt.Before(call); err != nil {
+		r.graph.icpt.After(call, err)
+		return nil, nil, err
+	}
+	out, traces, err = run(args)
+	err = r.graph.outerProvenance(err)
+	if err == nil {
+		call.Attrs = append(call.Attrs,
+			Attr{"outputs", handleShapes(out)},
+			Attr{"traces", handleShapes(traces)},
+		)
+	}
+	r.graph.icpt.After(call, err)
+	return out, traces, err
+}
+
+func (r streamRunner) RunOnStream(stream platform.Stream, args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	return runWith(r.Runner, args, stream, func(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+		return r.stream.RunOnStream(stream, args)
+	})
+}
+
+func (r meshRunner) RunSharded(args []platform.Handle) (out, traces []*platform.ShardedHandle, err error) {
+	return runWith(r.Runner, args, nil, r.mesh.RunSharded)
+}
+
+func handleShapes[H platform.Handle](handles []H) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(handles))
+	for i, h := range handles {
+		shapes[i] = h.Shape()
+	}
+	return shapes
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import "fmt"
+
+// ConvDims are the dimension numbers of a convolution: the roles of the axes of the input,
+// of the kernel and of the output.
+type ConvDims struct {
+	// InputBatchAxis is the axis of the input indexing the examples of a batch.
+	InputBatchAxis int
+	// InputFeatureAxis is the axis of the input indexing the input features.
+	InputFeatureAxis int
+	// InputSpatialAxes are the axes of the input over which the kernel slides.
+	InputSpatialAxes []int
+
+	// KernelInputFeatureAxis is the axis of the kernel indexing the input features.
+	KernelInputFeatureAxis int
+	// KernelOutputFeatureAxis is the axis of the kernel indexing the output features.
+	KernelOutputFeatureAxis int
+	// KernelSpatialAxes are the spatial axes of the kernel, in the order of InputSpatialAxes.
+	KernelSpatialAxes []int
+
+	// OutputBatchAxis is the axis of the output indexing the examples of a batch.
+	OutputBatchAxis int
+	// OutputFeatureAxis is the axis of the output indexing the output features.
+	OutputFeatureAxis int
+	// OutputSpatialAxes are the spatial axes of the output, in the order of InputSpatialAxes.
+	OutputSpatialAxes []int
+}
+
+// NHWC returns the dimension numbers of a convolution over inputs and outputs with
+// a batch axis, spatialRank spatial axes and a feature axis, and a kernel with
+// spatialRank spatial axes followed by an input feature and an output feature axis.
+// This is the layout used by TensorFlow.
+func NHWC(spatialRank int) ConvDims {
+	spatial := make([]int, spatialRank)
+	for i := range spatial {
+		spatial[i] = i + 1
+	}
+	kernelSpatial := make([]int, spatialRank)
+	for i := range kernelSpatial {
+		kernelSpatial[i] = i
+	}
+	return ConvDims{
+		InputBatchAxis:          0,
+		InputFeatureAxis:        spatialRank + 1,
+		InputSpatialAxes:        spatial,
+		KernelInputFeatureAxis:  spatialRank,
+		KernelOutputFeatureAxis: spatialRank + 1,
+		KernelSpatialAxes:       kernelSpatial,
+		OutputBatchAxis:         0,
+		OutputFeatureAxis:       spatialRank + 1,
+		OutputSpatialAxes:       spatial,
+	}
+}
+
+// String returns a string representation of the dimension numbers.
+func (d ConvDims) String() string {
+	return fmt.Sprintf("{input:b%d f%d s%v kernel:i%d o%d s%v output:b%d f%d s%v}",
+		d.InputBatchAxis, d.InputFeatureAxis, d.InputSpatialAxes,
+		d.KernelInputFeatureAxis, d.KernelOutputFeatureAxis, d.KernelSpatialAxes,
+		d.OutputBatchAxis, d.OutputFeatureAxis, d.OutputSpatialAxes)
+}
+
+// Window is a window sliding over every axis of an array, as used by pooling operations.
+// Nil strides, padding and dilations use the default values.
+type Window struct {
+	// Dimensions are the lengths of the window along each axis.
+	Dimensions []int
+	// Strides are the steps of the window along each axis. Defaults to 1.
+	Strides []int
+	// Padding are the number of elements added before and after each axis. Defaults to 0.
+	Padding [][2]int
+	// BaseDilations are the dilation factors of the array along each axis. Defaults to 1.
+	BaseDilations []int
+	// WindowDilations are the dilation factors of the window along each axis. Defaults to 1.
+	WindowDilations []int
+}
+
+// String returns a string representation of the window.
+func (w Window) String() string {
+	return fmt.Sprintf("{dims:%v strides:%v padding:%v base:%v window:%v}", w.Dimensions, w.Strides, w.Padding, w.BaseDilations, w.WindowDilations)
+}
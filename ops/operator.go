@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// UnaryOperator is an operator applied element-wise to a single array.
+type UnaryOperator int
+
+// Unary operators.
+const (
+	InvalidUnary UnaryOperator = iota
+	// Plus returns x unchanged (+x).
+	Plus
+	// Neg negates x (-x).
+	Neg
+	// Not returns the logical negation of booleans (!x).
+	Not
+	// BitNot complements the bits of integers, or negates booleans (^x).
+	BitNot
+)
+
+// BinaryOperator is an operator applied element-wise to two arrays.
+type BinaryOperator int
+
+// Binary operators.
+const (
+	InvalidBinary BinaryOperator = iota
+	Add
+	Sub
+	Mul
+	Div
+	// Rem is the remainder of the division, with the sign of the dividend.
+	Rem
+	// Pow raises x to the power y.
+	Pow
+	BitAnd
+	BitOr
+	BitXor
+	// BitAndNot clears the bits of x set in y (x &^ y).
+	BitAndNot
+	Shl
+	// Shr shifts right, replicating the sign bit for signed integers.
+	Shr
+	LogicalAnd
+	LogicalOr
+	Equal
+	NotEqual
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
+)
+
+var unaryTokens = [...]token.Token{
+	Plus:   token.ADD,
+	Neg:    token.SUB,
+	Not:    token.NOT,
+	BitNot: token.XOR,
+}
+
+var binaryTokens = [...]token.Token{
+	Add:          token.ADD,
+	Sub:          token.SUB,
+	Mul:          token.MUL,
+	Div:          token.QUO,
+	Rem:          token.REM,
+	BitAnd:       token.AND,
+	BitOr:        token.OR,
+	BitXor:       token.XOR,
+	BitAndNot:    token.AND_NOT,
+	Shl:          token.SHL,
+	Shr:          token.SHR,
+	LogicalAnd:   token.LAND,
+	LogicalOr:    token.LOR,
+	Equal:        token.EQL,
+	NotEqual:     token.NEQ,
+	Less:         token.LSS,
+	LessEqual:    token.LEQ,
+	Greater:      token.GTR,
+	GreaterEqual: token.GEQ,
+}
+
+// UnaryOperatorOf returns the operator corresponding to a Go token.
+func UnaryOperatorOf(tok token.Token) (UnaryOperator, error) {
+	for op, opTok := range unaryTokens {
+		if opTok == tok && opTok != token.ILLEGAL && op != int(InvalidUnary) {
+			return UnaryOperator(op), nil
+		}
+	}
+	return InvalidUnary, fmt.Errorf("unary operator %s not supported", tok)
+}
+
+// BinaryOperatorOf returns the operator corresponding to a Go token.
+func BinaryOperatorOf(tok token.Token) (BinaryOperator, error) {
+	for op, opTok := range binaryTokens {
+		if opTok == tok && opTok != token.ILLEGAL && op != int(InvalidBinary) {
+			return BinaryOperator(op), nil
+		}
+	}
+	return InvalidBinary, fmt.Errorf("binary operator %s not supported", tok)
+}
+
+// Token returns the Go token of the operator or token.ILLEGAL if the operator has no Go equivalent.
+func (op UnaryOperator) Token() token.Token {
+	if op <= InvalidUnary || int(op) >= len(unaryTokens) {
+		return token.ILLEGAL
+	}
+	return unaryTokens[op]
+}
+
+// String returns the Go symbol of the operator.
+func (op UnaryOperator) String() string {
+	if tok := op.Token(); tok != token.ILLEGAL {
+		return tok.String()
+	}
+	return fmt.Sprintf("UnaryOperator(%d)", int(op))
+}
+
+// Token returns the Go token of the operator or token.ILLEGAL if the operator has no Go equivalent.
+func (op BinaryOperator) Token() token.Token {
+	if op <= InvalidBinary || int(op) >= len(binaryTokens) {
+		return token.ILLEGAL
+	}
+	return binaryTokens[op]
+}
+
+// String returns the Go symbol of the operator, or its name if Go has no such operator.
+func (op BinaryOperator) String() string {
+	if op == Pow {
+		return "pow"
+	}
+	if tok := op.Token(); tok != token.ILLEGAL {
+		return tok.String()
+	}
+	return fmt.Sprintf("BinaryOperator(%d)", int(op))
+}
+
+// IsComparison returns true if the operator compares its operands and returns booleans.
+func (op BinaryOperator) IsComparison() bool {
+	return op >= Equal && op <= GreaterEqual
+}
+
+// IsLogical returns true if the operator only applies to booleans.
+func (op BinaryOperator) IsLogical() bool {
+	return op == LogicalAnd || op == LogicalOr
+}
+
+// UnaryFromAST builds a unary operation from a Go expression.
+// It is the implementation of the deprecated CoreBuilder.Unary method.
+func UnaryFromAST(b CoreBuilder, expr *ast.UnaryExpr, x Node) (Node, error) {
+	op, err := UnaryOperatorOf(expr.Op)
+	if err != nil {
+		return nil, err
+	}
+	return b.UnaryOp(op, x)
+}
+
+// BinaryFromAST builds a binary operation from a Go expression.
+// It is the implementation of the deprecated CoreBuilder.Binary method.
+func BinaryFromAST(b CoreBuilder, expr *ast.BinaryExpr, x, y Node) (Node, error) {
+	op, err := BinaryOperatorOf(expr.Op)
+	if err != nil {
+		return nil, err
+	}
+	return b.BinaryOp(op, x, y)
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops_test
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/gx-org/backend/ops"
+)
+
+func TestOperatorTokens(t *testing.T) {
+	for op := ops.Plus; op <= ops.BitNot; op++ {
+		got, err := ops.UnaryOperatorOf(op.Token())
+		if err != nil {
+			t.Errorf("%s: %v", op, err)
+			continue
+		}
+		if got != op {
+			t.Errorf("token %s: got operator %d but want %d", op.Token(), got, op)
+		}
+	}
+	for op := ops.Add; op <= ops.GreaterEqual; op++ {
+		if op == ops.Pow {
+			if tok := op.Token(); tok != token.ILLEGAL {
+				t.Errorf("%s has token %s but want %s", op, tok, token.ILLEGAL)
+			}
+			continue
+		}
+		got, err := ops.BinaryOperatorOf(op.Token())
+		if err != nil {
+			t.Errorf("%s: %v", op, err)
+			continue
+		}
+		if got != op {
+			t.Errorf("token %s: got operator %d but want %d", op.Token(), got, op)
+		}
+	}
+	for _, tok := range []token.Token{token.ARROW, token.ILLEGAL} {
+		if op, err := ops.BinaryOperatorOf(tok); err == nil {
+			t.Errorf("BinaryOperatorOf(%s) = %s but want an error", tok, op)
+		}
+		if op, err := ops.UnaryOperatorOf(tok); err == nil {
+			t.Errorf("UnaryOperatorOf(%s) = %s but want an error", tok, op)
+		}
+	}
+	if got, want := ops.LessEqual.String(), "<="; got != want {
+		t.Errorf("got %q but want %q", got, want)
+	}
+	if !ops.NotEqual.IsComparison() || ops.Sub.IsComparison() || !ops.LogicalOr.IsLogical() {
+		t.Errorf("wrong operator classes")
+	}
+}
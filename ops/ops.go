@@ -49,10 +49,25 @@ type (
 		Run([]platform.Handle) (out, traces []platform.DeviceHandle, err error)
 	}
 
+	// StreamRunner is implemented by runners able to enqueue their execution on a stream.
+	StreamRunner interface {
+		Runner
+
+		// RunOnStream enqueues an execution of the graph on a stream.
+		// The returned handles can be used by other operations enqueued on the same stream.
+		// Their content is only valid once the stream has been synchronized.
+		RunOnStream(stream platform.Stream, args []platform.Handle) (out, traces []platform.DeviceHandle, err error)
+	}
+
 	// OutputNode is an output node in the graph.
 	OutputNode struct {
 		Node  Node
 		Shape *shape.Shape
+
+		// Sharding of the output over the devices of a mesh when the graph is compiled
+		// with CompileMesh, in which case Shape is the shape of the shard of each device.
+		// A nil sharding replicates the output. It is ignored by Compile.
+		Sharding platform.PartitionSpec
 	}
 
 	// Graph implemented by a backend.
@@ -73,8 +88,16 @@ type (
 		// DType returns the implementation for functions in the dtype package.
 		DType() DTypeBuilder
 
+		// NN returns the builder for neural network operations.
+		NN() NNBuilder
+
+		// Rand returns the builder for random number generation.
+		Rand() RandBuilder
+
 		// Compile the graph for a given device.
 		// The graph is not supposed to be modified once it has been compiled.
+		// The layouts of params and of the output shapes are the layouts of the arguments
+		// and of the returned handles. Backends not supporting a layout return an error.
 		Compile(dev platform.Device, output, traced []*OutputNode, params []*shape.Shape) (Runner, error)
 	}
 
@@ -105,11 +128,22 @@ type (
 		Argument(name string, shape *shape.Shape, index int) (Node, error)
 
 		// Unary returns a node applying a unary operator to a node.
+		//
+		// Deprecated: use UnaryOp.
 		Unary(op *ast.UnaryExpr, x Node) (Node, error)
 
 		// Binary returns a node applying a binary operator between two nodes.
+		//
+		// Deprecated: use BinaryOp.
 		Binary(op *ast.BinaryExpr, x, y Node) (Node, error)
 
+		// UnaryOp returns a node applying a unary operator to a node.
+		UnaryOp(op UnaryOperator, x Node) (Node, error)
+
+		// BinaryOp returns a node applying a binary operator between two nodes.
+		// One of the operands can be atomic, in which case it is broadcast to the shape of the other.
+		BinaryOp(op BinaryOperator, x, y Node) (Node, error)
+
 		// Reshape returns a reshape operator node.
 		Reshape(x Node, axisLengths []int) (Node, error)
 
@@ -117,6 +151,11 @@ type (
 		Concat(axis int, nodes []Node) (Node, error)
 
 		// Cast returns a cast/convert operator node.
+		// Between integer types, widening sign-extends signed values and zero-extends
+		// unsigned values, and narrowing keeps the low-order bits, wrapping around like
+		// Go conversions: casting int32(300) to Int8 returns 44. Floats are truncated toward
+		// zero when cast to integers; the result of out-of-range floats is backend dependent.
+		// Non-zero values cast to Bool are true.
 		Cast(x Node, target dtype.DataType) (Node, error)
 
 		// Slice returns a slice on a node.
@@ -126,13 +165,82 @@ type (
 		Set(x, updates, index Node) (Node, error)
 
 		// DotGeneral returns a general dot operator node.
-		DotGeneral(x, y Node, batchAxes, reduceAxes [2][]int) (Node, error)
+		// The precision specifies how floating-point inputs are rounded before being multiplied.
+		DotGeneral(x, y Node, batchAxes, reduceAxes [2][]int, precision Precision) (Node, error)
 
 		// While returns a while loop node.
 		While(cond, body *Subgraph, state Node) (Node, error)
 
+		// Cond returns a node calling trueBranch with args if pred is true, falseBranch otherwise.
+		// pred is an atomic boolean and both branches return values of the same shape.
+		// Only the selected branch is executed.
+		Cond(pred Node, trueBranch, falseBranch *Subgraph, args ...Node) (Node, error)
+
+		// Select returns a node choosing element-wise between onTrue and onFalse.
+		// pred is a boolean array of the shape of onTrue and onFalse, or an atomic boolean.
+		Select(pred, onTrue, onFalse Node) (Node, error)
+
 		// BroadcastInDim broadcasts data across a given set of axis.
 		BroadcastInDim(x Node, shape *shape.Shape, broadcastAxes []int) (Node, error)
+
+		// Quantize returns a node converting real values into quantized values.
+		Quantize(x Node, quant *dtype.Quantization) (Node, error)
+
+		// Dequantize returns a node converting quantized values back into real values.
+		Dequantize(x Node) (Node, error)
+
+		// ReduceSum returns a node summing the elements of x along a set of axes.
+		ReduceSum(x Node, axes []int) (Node, error)
+
+		// ReduceProd returns a node multiplying the elements of x along a set of axes.
+		ReduceProd(x Node, axes []int) (Node, error)
+
+		// ReduceMax returns a node computing the maximum of the elements of x along a set of axes.
+		ReduceMax(x Node, axes []int) (Node, error)
+
+		// ReduceMin returns a node computing the minimum of the elements of x along a set of axes.
+		ReduceMin(x Node, axes []int) (Node, error)
+
+		// Reduce returns a node reducing the elements of x along a set of axes.
+		// The body subgraph combines two atomic values of the data type of x into one.
+		// init is the atomic initial value of the reduction: it must be an identity of body.
+		Reduce(body *Subgraph, init, x Node, axes []int) (Node, error)
+
+		// Gather returns a node reading slices of x starting at the index vectors of indices.
+		// sliceSizes are the sizes of the slices along every axis of x.
+		Gather(x, indices Node, dims GatherDims, sliceSizes []int) (Node, error)
+
+		// Scatter returns a node replacing windows of x starting at the index vectors
+		// of indices with the values of updates.
+		Scatter(x, indices, updates Node, dims ScatterDims) (Node, error)
+
+		// Transpose returns a node permuting the axes of x.
+		// The axis i of the result is the axis permutation[i] of x.
+		Transpose(x Node, permutation []int) (Node, error)
+
+		// Reverse returns a node reversing the order of the elements of x along a set of axes.
+		Reverse(x Node, axes []int) (Node, error)
+
+		// Pad returns a node padding x with an atomic value: low elements before, high elements
+		// after and interior elements between each element along each axis.
+		// low and high can be negative to remove elements.
+		Pad(x, value Node, low, high, interior []int) (Node, error)
+
+		// Sort returns a node sorting the elements of x along an axis, in ascending order
+		// or in descending order if descending is true. Every vector along the axis is sorted
+		// independently. The sort is stable: equal elements keep their order.
+		// Floating-point values are compared with a total order in which NaNs are greater
+		// than all other values.
+		Sort(x Node, axis int, descending bool) (Node, error)
+
+		// ArgSort returns a node computing the int32 indices along an axis which sort x
+		// as Sort does. Indices of equal elements are in increasing order.
+		ArgSort(x Node, axis int, descending bool) (Node, error)
+
+		// TopK returns a tuple of the k largest elements of x along its last axis,
+		// in descending order, and of their int32 indices along that axis.
+		// Equal elements are ordered by increasing index.
+		TopK(x Node, k int) (Tuple, error)
 	}
 
 	// DTypeBuilder creates node related to data types.
@@ -145,45 +253,165 @@ type (
 	NumBuilder interface {
 		// Iota returns a node filling an array with values from 0 to number of elements-1.
 		Iota(sh *shape.Shape, iotaAxis int) (Node, error)
+
+		// Argmax returns a node computing the int32 indices of the maximum elements of x
+		// along an axis, which is removed from the result. The first index is returned if
+		// there are several maxima. Floating-point values are compared as in Sort.
+		Argmax(x Node, axis int) (Node, error)
+
+		// Argmin returns a node computing the int32 indices of the minimum elements of x
+		// along an axis, which is removed from the result. The first index is returned if
+		// there are several minima. Floating-point values are compared as in Sort.
+		Argmin(x Node, axis int) (Node, error)
+
+		// CumSum returns a node computing the inclusive cumulative sum of x along an axis:
+		// every element of the result is the sum of the elements of x up to the same index.
+		CumSum(x Node, axis int) (Node, error)
+
+		// CumProd returns a node computing the inclusive cumulative product of x along an axis.
+		CumProd(x Node, axis int) (Node, error)
+	}
+
+	// NNBuilder creates nodes for neural network operations, such as convolutions and pooling.
+	NNBuilder interface {
+		// ConvGeneralDilated returns a node convolving x with a kernel.
+		// strides, padding, inputDilation and kernelDilation have one element per spatial axis:
+		// nil values use a stride of 1, no padding and no dilation.
+		// The input features are split into featureGroupCount groups, each convolved with
+		// a group of output features: 1 is a regular convolution.
+		ConvGeneralDilated(x, kernel Node, dims ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int, precision Precision) (Node, error)
+
+		// ReduceWindow returns a node reducing the windows of x with a body subgraph combining
+		// two atomic values of the data type of x, starting from the atomic value init.
+		ReduceWindow(body *Subgraph, init, x Node, window Window) (Node, error)
+
+		// MaxPool returns a node computing the maximum of the windows of x.
+		MaxPool(x Node, window Window) (Node, error)
+
+		// AvgPool returns a node computing the average of the windows of x.
+		// Padded elements are zeros counted in the average.
+		AvgPool(x Node, window Window) (Node, error)
+	}
+
+	// RandBuilder creates nodes generating random numbers from an explicit state.
+	// The state is an array of the shape returned by RngStateShape. Every method returns
+	// a tuple of the next state and of the generated values, such that the same state always
+	// generates the same values.
+	RandBuilder interface {
+		// RngBitGenerator returns a node generating an array of the shape sh filled with
+		// random bits. The data type of sh is Uint32 or Uint64.
+		RngBitGenerator(algorithm RngAlgorithm, state Node, sh *shape.Shape) (Node, error)
+
+		// Uniform returns a node generating an array of the shape sh filled with values
+		// uniformly distributed in [0, 1). The data type of sh is a floating-point type.
+		// Values are generated with the ThreeFryRng algorithm.
+		Uniform(sh *shape.Shape, state Node) (Node, error)
+
+		// Normal returns a node generating an array of the shape sh filled with values of
+		// the standard normal distribution. The data type of sh is a floating-point type.
+		// Values are generated with the ThreeFryRng algorithm.
+		Normal(sh *shape.Shape, state Node) (Node, error)
 	}
 
 	// MathBuilder creates node in the graph for functions in the max package from the standard library.
+	//
+	// Functions apply element-wise to arrays of floating-point values and follow the special
+	// cases of the functions of the same name in the Go math package: for example, NaN inputs
+	// return NaN and Log(0) returns -Inf. Results are expected to be within a few ULPs of the
+	// exact values; backends may flush subnormal values to zero.
 	MathBuilder interface {
 		// Abs returns the absolute value of x.
+		// The absolute value of a complex number is its modulus, a float.
 		Abs(x Node) (Node, error)
-		// Ceil returns the ceiling of x.
+		// Atan2 returns the arc tangent of y/x in [-Pi, Pi], using the signs of y and x
+		// to determine the quadrant. y and x have the same shape or one of them is atomic.
+		Atan2(y, x Node) (Node, error)
+		// Ceil returns the least integer value greater than or equal to x.
 		Ceil(x Node) (Node, error)
-		// Cos returns the cosine of x.
+		// Complex returns the complex number re + i*im. re and im are Float32 or Float64 arrays
+		// of the same data type. They have the same shape or one of them is atomic.
+		Complex(re, im Node) (Node, error)
+		// Conj returns the complex conjugate of x.
+		Conj(x Node) (Node, error)
+		// Cos returns the cosine of x, in radians.
 		Cos(x Node) (Node, error)
 		// Erf returns the error function of x.
 		Erf(x Node) (Node, error)
 		// Exp returns the exponential of x.
 		Exp(x Node) (Node, error)
-		// Expm1 returns Exp(x)-1.
+		// Expm1 returns Exp(x)-1, accurate when x is near zero.
 		Expm1(x Node) (Node, error)
-		// Floor returns the floor of x.
+		// Floor returns the greatest integer value less than or equal to x.
 		Floor(x Node) (Node, error)
-		// Log returns the natural logarithm of x.
+		// Imag returns the imaginary part of a complex x as a float.
+		Imag(x Node) (Node, error)
+		// IsInf returns a boolean array true where x is positive or negative infinity.
+		IsInf(x Node) (Node, error)
+		// IsNaN returns a boolean array true where x is NaN.
+		IsNaN(x Node) (Node, error)
+		// Log returns the natural logarithm of x. Negative values return NaN.
 		Log(x Node) (Node, error)
-		// Log1p returns log(1+x).
+		// Log1p returns log(1+x), accurate when x is near zero.
 		Log1p(x Node) (Node, error)
-		// Logistic returns 1/(1+exp(-x)).
+		// Logistic returns the sigmoid function 1/(1+exp(-x)).
 		Logistic(x Node) (Node, error)
-		// Round returns the nearest integer of x.
+		// Pow returns x to the power y. x and y have the same shape or one of them is atomic.
+		Pow(x, y Node) (Node, error)
+		// Real returns the real part of a complex x as a float.
+		Real(x Node) (Node, error)
+		// Round returns the nearest integer of x, rounding half away from zero.
 		Round(x Node) (Node, error)
 		// Rsqrt returns 1/sqrt(x).
 		Rsqrt(x Node) (Node, error)
-		// Sign returns the sign of x.
+		// Sign returns -1, 0 or 1 depending on the sign of x. Zeros keep their sign.
 		Sign(x Node) (Node, error)
-		// Sin returns the sine of x.
+		// Sin returns the sine of x, in radians.
 		Sin(x Node) (Node, error)
-		// Sqrt returns sqrt(x).
+		// Sqrt returns sqrt(x). Negative values return NaN.
 		Sqrt(x Node) (Node, error)
 		// Tanh returns the hyperbolic tangent of x.
 		Tanh(x Node) (Node, error)
 	}
 )
 
+// RunOnStream runs a runner on a stream.
+// If the runner does not implement StreamRunner, it runs synchronously.
+func RunOnStream(runner Runner, stream platform.Stream, args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	if sr, ok := runner.(StreamRunner); ok {
+		return sr.RunOnStream(stream, args)
+	}
+	return runner.Run(args)
+}
+
+// Precision of the floating-point arithmetic used by an operator.
+type Precision int
+
+const (
+	// DefaultPrecision lets the backend choose the precision, favoring speed.
+	DefaultPrecision Precision = iota
+
+	// TF32Precision rounds float32 inputs to TensorFloat-32 (1 sign bit, 8 exponent bits
+	// and 10 mantissa bits) before multiplying them and accumulates results in float32.
+	// Backends without TF32 support use a precision at least as high.
+	TF32Precision
+
+	// HighestPrecision uses the full precision of the input data type.
+	HighestPrecision
+)
+
+// String returns a string representation of the precision.
+func (p Precision) String() string {
+	switch p {
+	case DefaultPrecision:
+		return "default"
+	case TF32Precision:
+		return "tf32"
+	case HighestPrecision:
+		return "highest"
+	}
+	return fmt.Sprintf("Precision(%d)", int(p))
+}
+
 // String representation of an output node.
 func (out *OutputNode) String() string {
 	return fmt.Sprintf("%s: %v", out.Shape.String(), out.Node)
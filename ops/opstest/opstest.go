@@ -0,0 +1,506 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opstest provides a backend recording the nodes it builds to unit test
+// code built on the ops interfaces.
+//
+// Nodes carry no computation: compiled graphs return arrays of zeros.
+package opstest
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Backend creates recording graphs.
+type Backend struct {
+	plat       platform.Platform
+	graphs     []*Graph
+	failOn     map[ops.OpID]error
+	compileErr *error
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+// NewBackend returns a backend creating recording graphs on a platform.
+func NewBackend(plat platform.Platform) *Backend {
+	return &Backend{plat: plat, failOn: make(map[ops.OpID]error), compileErr: new(error)}
+}
+
+// Platform of the backend.
+func (b *Backend) Platform() platform.Platform {
+	return b.plat
+}
+
+// NewOps returns a new recording graph.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	g := &Graph{plat: b.plat, name: name, config: ops.NewGraphConfig(opts...), failOn: b.failOn, compileErr: b.compileErr}
+	b.graphs = append(b.graphs, g)
+	return g, nil
+}
+
+// Graphs returns all the root graphs created by the backend.
+func (b *Backend) Graphs() []*Graph {
+	return b.graphs
+}
+
+// FailOn makes all the graphs of the backend return err when building op.
+func (b *Backend) FailOn(op ops.OpID, err error) {
+	b.failOn[op] = err
+}
+
+// FailCompile makes all the graphs of the backend return err when compiled.
+func (b *Backend) FailCompile(err error) {
+	*b.compileErr = err
+}
+
+// Capabilities reports that all operations are supported.
+func (b *Backend) Capabilities() ops.Capabilities {
+	return ops.AllCapabilities{}
+}
+
+// Close the platform of the backend.
+func (b *Backend) Close() error {
+	return b.plat.Close()
+}
+
+// Release the platform of the backend.
+//
+// Deprecated: use Close.
+func (b *Backend) Release() error {
+	return b.Close()
+}
+
+// Node is a recorded builder call.
+type Node struct {
+	graph *Graph
+	// Op is the operation which created the node.
+	Op ops.OpID
+	// Inputs of the operation.
+	Inputs []ops.Node
+}
+
+// Graph returns the graph owning the node.
+func (n *Node) Graph() ops.Graph {
+	return n.graph
+}
+
+// String returns the operation of the node.
+func (n *Node) String() string {
+	return string(n.Op)
+}
+
+// Tuple is a recorded tuple.
+type Tuple struct {
+	*Node
+}
+
+// Element returns a node representing the ith element of the tuple.
+func (t *Tuple) Element(i int) (ops.Node, error) {
+	if i < 0 || i >= len(t.Inputs) {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", i, len(t.Inputs))
+	}
+	return t.Inputs[i], nil
+}
+
+// Size returns the number of elements in the tuple.
+func (t *Tuple) Size() int {
+	return len(t.Inputs)
+}
+
+// Unpack returns the elements of the tuple.
+func (t *Tuple) Unpack() ([]ops.Node, error) {
+	return t.Inputs, nil
+}
+
+// Graph records the nodes built by the interpreter.
+type Graph struct {
+	plat       platform.Platform
+	name       string
+	config     ops.GraphConfig
+	failOn     map[ops.OpID]error
+	compileErr *error
+	nodes      []*Node
+	// Compiled is the number of times the graph has been compiled.
+	Compiled int
+}
+
+var _ ops.Graph = (*Graph)(nil)
+
+// Name of the graph.
+func (g *Graph) Name() string {
+	return g.name
+}
+
+// Config returns the configuration with which the graph has been created.
+func (g *Graph) Config() ops.GraphConfig {
+	return g.config
+}
+
+// Nodes returns the nodes built in the graph, in creation order.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Ops returns the operations of the nodes built in the graph, in creation order.
+func (g *Graph) Ops() []ops.OpID {
+	ids := make([]ops.OpID, len(g.nodes))
+	for i, n := range g.nodes {
+		ids[i] = n.Op
+	}
+	return ids
+}
+
+func (g *Graph) add(op ops.OpID, inputs ...ops.Node) (ops.Node, error) {
+	if err := g.failOn[op]; err != nil {
+		return nil, err
+	}
+	n := &Node{graph: g, Op: op, Inputs: inputs}
+	g.nodes = append(g.nodes, n)
+	return n, nil
+}
+
+// Platform used by the graph.
+func (g *Graph) Platform() platform.Platform {
+	return g.plat
+}
+
+// Core returns the builder for core operations.
+func (g *Graph) Core() ops.CoreBuilder {
+	return coreBuilder{g}
+}
+
+// Num returns the builder for functions of the num package.
+func (g *Graph) Num() ops.NumBuilder {
+	return numBuilder{g}
+}
+
+// Math returns the builder for functions of the math package.
+func (g *Graph) Math() ops.MathBuilder {
+	return mathBuilder{g}
+}
+
+// DType returns the builder for functions of the dtype package.
+func (g *Graph) DType() ops.DTypeBuilder {
+	return dtypeBuilder{g}
+}
+
+// NN returns the builder for neural network operations.
+func (g *Graph) NN() ops.NNBuilder {
+	return nnBuilder{g}
+}
+
+// Rand returns the builder for random number generation.
+func (g *Graph) Rand() ops.RandBuilder {
+	return randBuilder{g}
+}
+
+// Compile returns a runner returning arrays of zeros of the output shapes.
+func (g *Graph) Compile(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	if err := *g.compileErr; err != nil {
+		return nil, err
+	}
+	g.Compiled++
+	return &Runner{dev: dev, output: output, traced: traced, params: params}, nil
+}
+
+// Runner returns arrays of zeros.
+type Runner struct {
+	dev            platform.Device
+	output, traced []*ops.OutputNode
+	params         []*shape.Shape
+}
+
+// Run checks the number of arguments and returns arrays of zeros.
+func (r *Runner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	if len(args) != len(r.params) {
+		return nil, nil, fmt.Errorf("got %d arguments but want %d", len(args), len(r.params))
+	}
+	if out, err = r.zeros(r.output); err != nil {
+		return nil, nil, err
+	}
+	if traces, err = r.zeros(r.traced); err != nil {
+		return nil, nil, err
+	}
+	return out, traces, nil
+}
+
+func (r *Runner) zeros(outs []*ops.OutputNode) ([]platform.DeviceHandle, error) {
+	handles := make([]platform.DeviceHandle, len(outs))
+	for i, out := range outs {
+		var err error
+		if handles[i], err = r.dev.Send(make([]byte, out.Shape.ByteSize()), out.Shape); err != nil {
+			return nil, err
+		}
+	}
+	return handles, nil
+}
+
+type coreBuilder struct {
+	g *Graph
+}
+
+func (b coreBuilder) Graph() ops.Graph { return b.g }
+
+func (b coreBuilder) Constant(platform.HostBuffer) (ops.Node, error) {
+	return b.g.add(ops.OpConstant)
+}
+
+func (b coreBuilder) Tuple(nodes []ops.Node) (ops.Tuple, error) {
+	n, err := b.g.add(ops.OpTuple, nodes...)
+	if err != nil {
+		return nil, err
+	}
+	return &Tuple{Node: n.(*Node)}, nil
+}
+
+func (b coreBuilder) Call(_ *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpCall, args...)
+}
+
+func (b coreBuilder) Subgraph(name string, _ []*shape.Shape) (ops.Graph, error) {
+	if err := b.g.failOn[ops.OpSubgraph]; err != nil {
+		return nil, err
+	}
+	return &Graph{plat: b.g.plat, name: name, config: b.g.config, failOn: b.g.failOn, compileErr: b.g.compileErr}, nil
+}
+
+func (b coreBuilder) Argument(string, *shape.Shape, int) (ops.Node, error) {
+	return b.g.add(ops.OpArgument)
+}
+
+func (b coreBuilder) Unary(op *ast.UnaryExpr, x ops.Node) (ops.Node, error) {
+	return ops.UnaryFromAST(b, op, x)
+}
+
+func (b coreBuilder) Binary(op *ast.BinaryExpr, x, y ops.Node) (ops.Node, error) {
+	return ops.BinaryFromAST(b, op, x, y)
+}
+
+func (b coreBuilder) UnaryOp(_ ops.UnaryOperator, x ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpUnary, x)
+}
+
+func (b coreBuilder) BinaryOp(_ ops.BinaryOperator, x, y ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpBinary, x, y)
+}
+
+func (b coreBuilder) Reshape(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReshape, x)
+}
+
+func (b coreBuilder) Concat(_ int, nodes []ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpConcat, nodes...)
+}
+
+func (b coreBuilder) Cast(x ops.Node, _ dtype.DataType) (ops.Node, error) {
+	return b.g.add(ops.OpCast, x)
+}
+
+func (b coreBuilder) Slice(x ops.Node, _ int) (ops.Node, error) {
+	return b.g.add(ops.OpSlice, x)
+}
+
+func (b coreBuilder) Set(x, updates, index ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpSet, x, updates, index)
+}
+
+func (b coreBuilder) DotGeneral(x, y ops.Node, _, _ [2][]int, _ ops.Precision) (ops.Node, error) {
+	return b.g.add(ops.OpDotGeneral, x, y)
+}
+
+func (b coreBuilder) While(_, _ *ops.Subgraph, state ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpWhile, state)
+}
+
+func (b coreBuilder) Cond(pred ops.Node, _, _ *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpCond, append([]ops.Node{pred}, args...)...)
+}
+
+func (b coreBuilder) Select(pred, onTrue, onFalse ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpSelect, pred, onTrue, onFalse)
+}
+
+func (b coreBuilder) Sort(x ops.Node, _ int, _ bool) (ops.Node, error) {
+	return b.g.add(ops.OpSort, x)
+}
+
+func (b coreBuilder) ArgSort(x ops.Node, _ int, _ bool) (ops.Node, error) {
+	return b.g.add(ops.OpArgSort, x)
+}
+
+func (b coreBuilder) TopK(x ops.Node, _ int) (ops.Tuple, error) {
+	n, err := b.g.add(ops.OpTopK, x)
+	if err != nil {
+		return nil, err
+	}
+	return &Tuple{Node: n.(*Node)}, nil
+}
+
+func (b coreBuilder) BroadcastInDim(x ops.Node, _ *shape.Shape, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpBroadcastInDim, x)
+}
+
+func (b coreBuilder) Quantize(x ops.Node, _ *dtype.Quantization) (ops.Node, error) {
+	return b.g.add(ops.OpQuantize, x)
+}
+
+func (b coreBuilder) Dequantize(x ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpDequantize, x)
+}
+
+func (b coreBuilder) ReduceSum(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReduceSum, x)
+}
+
+func (b coreBuilder) ReduceProd(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReduceProd, x)
+}
+
+func (b coreBuilder) ReduceMax(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReduceMax, x)
+}
+
+func (b coreBuilder) ReduceMin(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReduceMin, x)
+}
+
+func (b coreBuilder) Reduce(_ *ops.Subgraph, init, x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReduce, init, x)
+}
+
+func (b coreBuilder) Gather(x, indices ops.Node, _ ops.GatherDims, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpGather, x, indices)
+}
+
+func (b coreBuilder) Scatter(x, indices, updates ops.Node, _ ops.ScatterDims) (ops.Node, error) {
+	return b.g.add(ops.OpScatter, x, indices, updates)
+}
+
+func (b coreBuilder) Transpose(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpTranspose, x)
+}
+
+func (b coreBuilder) Reverse(x ops.Node, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpReverse, x)
+}
+
+func (b coreBuilder) Pad(x, value ops.Node, _, _, _ []int) (ops.Node, error) {
+	return b.g.add(ops.OpPad, x, value)
+}
+
+type dtypeBuilder struct {
+	g *Graph
+}
+
+func (b dtypeBuilder) Bitcast(x ops.Node, _ dtype.DataType) (ops.Node, error) {
+	return b.g.add(ops.OpBitcast, x)
+}
+
+type numBuilder struct {
+	g *Graph
+}
+
+func (b numBuilder) Iota(*shape.Shape, int) (ops.Node, error) {
+	return b.g.add(ops.OpIota)
+}
+
+func (b numBuilder) Argmax(x ops.Node, _ int) (ops.Node, error) {
+	return b.g.add(ops.OpArgmax, x)
+}
+
+func (b numBuilder) Argmin(x ops.Node, _ int) (ops.Node, error) {
+	return b.g.add(ops.OpArgmin, x)
+}
+
+func (b numBuilder) CumSum(x ops.Node, _ int) (ops.Node, error) {
+	return b.g.add(ops.OpCumSum, x)
+}
+
+func (b numBuilder) CumProd(x ops.Node, _ int) (ops.Node, error) {
+	return b.g.add(ops.OpCumProd, x)
+}
+
+type nnBuilder struct {
+	g *Graph
+}
+
+func (b nnBuilder) ConvGeneralDilated(x, kernel ops.Node, _ ops.ConvDims, _ []int, _ [][2]int, _, _ []int, _ int, _ ops.Precision) (ops.Node, error) {
+	return b.g.add(ops.OpConvGeneralDilated, x, kernel)
+}
+
+func (b nnBuilder) ReduceWindow(_ *ops.Subgraph, init, x ops.Node, _ ops.Window) (ops.Node, error) {
+	return b.g.add(ops.OpReduceWindow, init, x)
+}
+
+func (b nnBuilder) MaxPool(x ops.Node, _ ops.Window) (ops.Node, error) {
+	return b.g.add(ops.OpMaxPool, x)
+}
+
+func (b nnBuilder) AvgPool(x ops.Node, _ ops.Window) (ops.Node, error) {
+	return b.g.add(ops.OpAvgPool, x)
+}
+
+type randBuilder struct {
+	g *Graph
+}
+
+func (b randBuilder) RngBitGenerator(_ ops.RngAlgorithm, state ops.Node, _ *shape.Shape) (ops.Node, error) {
+	return b.g.add(ops.OpRngBitGenerator, state)
+}
+
+func (b randBuilder) Uniform(_ *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpUniform, state)
+}
+
+func (b randBuilder) Normal(_ *shape.Shape, state ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpNormal, state)
+}
+
+type mathBuilder struct {
+	g *Graph
+}
+
+func (b mathBuilder) Abs(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpAbs, x) }
+func (b mathBuilder) Atan2(y, x ops.Node) (ops.Node, error) { return b.g.add(ops.OpAtan2, y, x) }
+func (b mathBuilder) Ceil(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpCeil, x) }
+func (b mathBuilder) Complex(re, im ops.Node) (ops.Node, error) {
+	return b.g.add(ops.OpComplex, re, im)
+}
+func (b mathBuilder) Conj(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpConj, x) }
+func (b mathBuilder) Cos(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpCos, x) }
+func (b mathBuilder) Erf(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpErf, x) }
+func (b mathBuilder) Exp(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpExp, x) }
+func (b mathBuilder) Expm1(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpExpm1, x) }
+func (b mathBuilder) Floor(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpFloor, x) }
+func (b mathBuilder) Imag(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpImag, x) }
+func (b mathBuilder) IsInf(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpIsInf, x) }
+func (b mathBuilder) IsNaN(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpIsNaN, x) }
+func (b mathBuilder) Log(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpLog, x) }
+func (b mathBuilder) Log1p(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpLog1p, x) }
+func (b mathBuilder) Logistic(x ops.Node) (ops.Node, error) { return b.g.add(ops.OpLogistic, x) }
+func (b mathBuilder) Pow(x, y ops.Node) (ops.Node, error)   { return b.g.add(ops.OpPow, x, y) }
+func (b mathBuilder) Real(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpReal, x) }
+func (b mathBuilder) Round(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpRound, x) }
+func (b mathBuilder) Rsqrt(x ops.Node) (ops.Node, error)    { return b.g.add(ops.OpRsqrt, x) }
+func (b mathBuilder) Sign(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpSign, x) }
+func (b mathBuilder) Sin(x ops.Node) (ops.Node, error)      { return b.g.add(ops.OpSin, x) }
+func (b mathBuilder) Sqrt(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpSqrt, x) }
+func (b mathBuilder) Tanh(x ops.Node) (ops.Node, error)     { return b.g.add(ops.OpTanh, x) }
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"maps"
+
+	"github.com/gx-org/backend/platform"
+)
+
+// GraphConfig is passed to a backend to create a graph.
+// Backends ignore the fields they do not support.
+type GraphConfig struct {
+	// Debug requests the backend to check its inputs and to keep debug information,
+	// possibly at the expense of performance.
+	Debug bool
+
+	// Metadata is attached by default to every node of the graph, for example to
+	// identify the source of the graph in profiles and error messages.
+	Metadata map[string]string
+
+	// DeterministicIDs requests the backend to assign identifiers to nodes and
+	// subgraphs which only depend on the sequence of builder calls, such that
+	// dumps of the graph can be compared across runs.
+	DeterministicIDs bool
+
+	// Mesh is the mesh of devices for which the graph is built.
+	// It is required to build collective operations (see DistBuilder).
+	Mesh *platform.Mesh
+}
+
+// GraphOption configures the creation of a graph.
+type GraphOption func(*GraphConfig)
+
+// NewGraphConfig returns a configuration given a list of options.
+func NewGraphConfig(opts ...GraphOption) GraphConfig {
+	var cfg GraphConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithDebug enables the debug mode of the graph.
+func WithDebug() GraphOption {
+	return func(cfg *GraphConfig) {
+		cfg.Debug = true
+	}
+}
+
+// WithMetadata attaches a key-value pair to every node of the graph.
+func WithMetadata(key, value string) GraphOption {
+	return func(cfg *GraphConfig) {
+		if cfg.Metadata == nil {
+			cfg.Metadata = make(map[string]string)
+		}
+		cfg.Metadata[key] = value
+	}
+}
+
+// WithDeterministicIDs requests identifiers which only depend on the sequence of builder calls.
+func WithDeterministicIDs() GraphOption {
+	return func(cfg *GraphConfig) {
+		cfg.DeterministicIDs = true
+	}
+}
+
+// WithMesh builds the graph for a mesh of devices.
+func WithMesh(mesh *platform.Mesh) GraphOption {
+	return func(cfg *GraphConfig) {
+		cfg.Mesh = mesh
+	}
+}
+
+// WithGraphConfig replaces the configuration with a copy of cfg.
+func WithGraphConfig(cfg GraphConfig) GraphOption {
+	return func(dst *GraphConfig) {
+		*dst = cfg
+		dst.Metadata = maps.Clone(cfg.Metadata)
+	}
+}
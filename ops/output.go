@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gx-org/backend/platform"
+)
+
+// ErrOutputsUnsupported is returned by RunInto when a runner cannot write its outputs
+// into caller-provided device handles.
+var ErrOutputsUnsupported = errors.New("runner does not support caller-provided outputs")
+
+// OutputRunner is implemented by runners able to write their outputs into handles
+// allocated by the caller, such that serving loops can run without allocating device memory.
+type OutputRunner interface {
+	Runner
+
+	// RunInto runs the graph and writes its outputs into out.
+	// The handles of out must be located on the device for which the graph has been compiled
+	// and have the shapes of the outputs. The caller keeps the ownership of out.
+	RunInto(args []platform.Handle, out []platform.DeviceHandle) (traces []platform.DeviceHandle, err error)
+}
+
+// RunInto runs a graph and writes its outputs into caller-provided device handles.
+// It returns an error wrapping ErrOutputsUnsupported if the runner does not implement OutputRunner.
+func RunInto(runner Runner, args []platform.Handle, out []platform.DeviceHandle) (traces []platform.DeviceHandle, err error) {
+	or, ok := runner.(OutputRunner)
+	if !ok {
+		return nil, fmt.Errorf("cannot run %T: %w", runner, ErrOutputsUnsupported)
+	}
+	return or.RunInto(args, out)
+}
+
+// RunToHost runs a graph and fetches its outputs into caller-provided host buffers.
+// The device handles of the outputs are freed once their data has been fetched.
+func RunToHost(runner Runner, args []platform.Handle, out []platform.HostBuffer) (traces []platform.DeviceHandle, err error) {
+	results, traces, err := runner.Run(args)
+	if err != nil {
+		return nil, err
+	}
+	defer freeHandles(results)
+	if len(results) != len(out) {
+		freeHandles(traces)
+		return nil, fmt.Errorf("got %d host buffers for %d outputs", len(out), len(results))
+	}
+	for i, res := range results {
+		if err := res.ToHost(out[i]); err != nil {
+			freeHandles(traces)
+			return nil, fmt.Errorf("cannot fetch output %d: %w", i, err)
+		}
+	}
+	return traces, nil
+}
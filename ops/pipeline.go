@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gx-org/backend/platform"
+)
+
+const defaultPipelineDepth = 2
+
+type (
+	// Stage is a compiled graph run by a pipeline on a device.
+	Stage struct {
+		// Runner runs the graph of the stage.
+		Runner Runner
+		// Device for which the graph has been compiled. Arguments located on another
+		// device are copied to this device before the run. Arguments are not moved if nil.
+		Device platform.Device
+	}
+
+	// Pipeline runs a chain of stages, the outputs of a stage being the arguments of the next one.
+	// Every stage runs in its own goroutine such that different stages process different
+	// argument sets concurrently, for example to run the layers of a model split across devices.
+	Pipeline struct {
+		stages []Stage
+		depth  int
+	}
+
+	// pipeItem is an argument set flowing through a pipeline.
+	pipeItem struct {
+		index  int
+		args   []platform.Handle
+		owned  []platform.DeviceHandle
+		traces []platform.DeviceHandle
+	}
+
+	// pipeError records the first error of a pipeline.
+	pipeError struct {
+		mu  sync.Mutex
+		err error
+	}
+)
+
+// NewPipeline returns a pipeline running a chain of stages.
+// Depth is the number of argument sets buffered between two stages. It defaults to 2,
+// such that a stage can run while the outputs of its previous run are consumed by the next stage.
+func NewPipeline(stages []Stage, depth int) (*Pipeline, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("cannot create a pipeline without stages")
+	}
+	for i, stage := range stages {
+		if stage.Runner == nil {
+			return nil, fmt.Errorf("stage %d of the pipeline has no runner", i)
+		}
+	}
+	if depth <= 0 {
+		depth = defaultPipelineDepth
+	}
+	return &Pipeline{stages: stages, depth: depth}, nil
+}
+
+// Stages returns the stages of the pipeline.
+func (p *Pipeline) Stages() []Stage {
+	return p.stages
+}
+
+// Run feeds every argument set through the stages of the pipeline and returns, for each set,
+// the outputs of the last stage and the traces of all the stages in stage order.
+// Intermediate outputs are freed once consumed by the next stage.
+// No handles are returned if an error occurs.
+func (p *Pipeline) Run(args [][]platform.Handle) ([]RunResult, error) {
+	errs := &pipeError{}
+	feed := make(chan pipeItem, p.depth)
+	go func() {
+		defer close(feed)
+		for i, set := range args {
+			if errs.failed() {
+				return
+			}
+			feed <- pipeItem{index: i, args: set}
+		}
+	}()
+	var in <-chan pipeItem = feed
+	for i, stage := range p.stages {
+		out := make(chan pipeItem, p.depth)
+		go stage.run(i, in, out, errs)
+		in = out
+	}
+	results := make([]RunResult, len(args))
+	for item := range in {
+		results[item.index] = RunResult{Out: item.owned, Traces: item.traces}
+	}
+	if err := errs.first(); err != nil {
+		freeResults(results)
+		return nil, err
+	}
+	return results, nil
+}
+
+// run processes the argument sets received from in and sends its results to out.
+func (s Stage) run(index int, in <-chan pipeItem, out chan<- pipeItem, errs *pipeError) {
+	defer close(out)
+	for item := range in {
+		if errs.failed() {
+			item.free()
+			continue
+		}
+		next, err := s.runItem(item)
+		if err != nil {
+			errs.set(fmt.Errorf("stage %d cannot run argument set %d: %w", index, item.index, err))
+			continue
+		}
+		out <- next
+	}
+}
+
+// runItem runs the stage on an argument set. The item is freed once the stage has run.
+func (s Stage) runItem(item pipeItem) (pipeItem, error) {
+	args := item.args
+	var moved []platform.DeviceHandle
+	defer func() {
+		freeHandles(moved)
+		freeHandles(item.owned)
+	}()
+	if s.Device != nil {
+		args = make([]platform.Handle, len(item.args))
+		for i, arg := range item.args {
+			args[i] = arg
+			dh, ok := arg.(platform.DeviceHandle)
+			if !ok || dh.Device() == s.Device {
+				continue
+			}
+			copied, err := platform.CopyTo(dh, s.Device)
+			if err != nil {
+				freeHandles(item.traces)
+				return pipeItem{}, fmt.Errorf("cannot copy argument %d to device %d: %w", i, s.Device.Ordinal(), err)
+			}
+			moved = append(moved, copied)
+			args[i] = copied
+		}
+	}
+	out, traces, err := s.Runner.Run(args)
+	if err != nil {
+		freeHandles(item.traces)
+		return pipeItem{}, err
+	}
+	next := pipeItem{
+		index:  item.index,
+		args:   make([]platform.Handle, len(out)),
+		owned:  out,
+		traces: append(item.traces, traces...),
+	}
+	for i, h := range out {
+		next.args[i] = h
+	}
+	return next, nil
+}
+
+func (item pipeItem) free() {
+	freeHandles(item.owned)
+	freeHandles(item.traces)
+}
+
+func (e *pipeError) set(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *pipeError) first() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+func (e *pipeError) failed() bool {
+	return e.first() != nil
+}
+
+func freeHandles(handles []platform.DeviceHandle) {
+	for _, h := range handles {
+		if h != nil {
+			h.Free()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// NodeTiming is the time spent evaluating a node during a run.
+	NodeTiming struct {
+		// Op is the operation of the node.
+		Op OpID
+		// Count is the number of evaluations of the node, greater than one for nodes of loop bodies.
+		Count int
+		// Total is the time spent in all the evaluations of the node.
+		Total time.Duration
+	}
+
+	// ExecutionProfile measures the time spent by a run.
+	ExecutionProfile struct {
+		// Wall is the time between the call to the runner and the availability of its results.
+		Wall time.Duration
+		// Device is the time spent executing the graph on the device, zero if unknown.
+		Device time.Duration
+		// Transfer is the time spent moving the arguments and the results, zero if unknown.
+		Transfer time.Duration
+		// Nodes are the timings of the nodes of the graph and of its subgraphs.
+		// It is nil if the backend does not time nodes.
+		Nodes map[Node]NodeTiming
+
+		mu sync.Mutex
+	}
+
+	// ProfilingRunner is implemented by runners able to measure where the time of a run is spent.
+	ProfilingRunner interface {
+		Runner
+
+		// RunProfiled runs the compiled graph as Run does and returns the profile of the run.
+		RunProfiled(args []platform.Handle) (out, traces []platform.DeviceHandle, prof *ExecutionProfile, err error)
+	}
+
+	// Instrumentation is notified by instrumented runners each time a node has been evaluated.
+	// Runners can be used concurrently: implementations must be safe for concurrent use.
+	Instrumentation interface {
+		// NodeEvaluated is called once a node has been evaluated in d.
+		NodeEvaluated(node Node, op OpID, d time.Duration)
+	}
+
+	// InstrumentedCompiler is implemented by graphs able to compile runners notifying an Instrumentation.
+	InstrumentedCompiler interface {
+		// CompileInstrumented compiles the graph as Compile does. The runner calls inst
+		// each time it has evaluated a node.
+		CompileInstrumented(dev platform.Device, output, traced []*OutputNode, params []*shape.Shape, inst Instrumentation) (Runner, error)
+	}
+)
+
+var _ Instrumentation = (*ExecutionProfile)(nil)
+
+// NodeEvaluated adds an evaluation of a node to the profile. It is safe for concurrent use,
+// such that a profile can collect the timings of an instrumented runner.
+func (p *ExecutionProfile) NodeEvaluated(node Node, op OpID, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Nodes == nil {
+		p.Nodes = make(map[Node]NodeTiming)
+	}
+	timing := p.Nodes[node]
+	timing.Op = op
+	timing.Count++
+	timing.Total += d
+	p.Nodes[node] = timing
+}
+
+// RunProfiled runs a compiled graph and returns the profile of the run.
+// If the runner does not implement ProfilingRunner, the profile only measures the wall time.
+func RunProfiled(runner Runner, args []platform.Handle) (out, traces []platform.DeviceHandle, prof *ExecutionProfile, err error) {
+	if pr, ok := runner.(ProfilingRunner); ok {
+		return pr.RunProfiled(args)
+	}
+	start := time.Now()
+	if out, traces, err = runner.Run(args); err != nil {
+		return nil, nil, nil, err
+	}
+	return out, traces, &ExecutionProfile{Wall: time.Since(start)}, nil
+}
+
+// CompileInstrumented compiles a graph for a device such that inst is notified each time a node
+// has been evaluated. If the graph does not implement InstrumentedCompiler, the graph is compiled
+// with Compile and inst is never called.
+func CompileInstrumented(g Graph, dev platform.Device, output, traced []*OutputNode, params []*shape.Shape, inst Instrumentation) (Runner, error) {
+	if ic, ok := g.(InstrumentedCompiler); ok && inst != nil {
+		return ic.CompileInstrumented(dev, output, traced, params, inst)
+	}
+	return g.Compile(dev, output, traced, params)
+}
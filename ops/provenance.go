@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// MetadataSource is the key of the graph metadata storing the GX source of the graph,
+// for example "main.gx". It is used as the source location of nodes without a more precise one.
+const MetadataSource = "source"
+
+type (
+	// Provenance describes the node involved in an error, such that the error
+	// can be mapped back to the GX code which created the node.
+	Provenance struct {
+		// Op is the operation creating the node.
+		Op OpID
+		// Name of the node, if any. For example, the name of an argument
+		// or of the GX expression creating the node (see NodeInfo).
+		Name string
+		// Source is the GX source location of the node, for example "main.gx:12:3".
+		Source string
+		// Pos is the position of the GX expression creating the node, as recorded by
+		// a NodeInfoRecorder. It is used as the source location when Source is empty.
+		Pos token.Position
+		// Node raising the error, nil if unknown.
+		Node Node
+		// OperandShapes are the shapes of the operands, nil when unknown.
+		OperandShapes []*shape.Shape
+		// GraphPath is the name of the graph and its parents separated by slashes.
+		GraphPath string
+	}
+
+	// ProvenanceError is an error annotated with the provenance of the node which caused it.
+	ProvenanceError struct {
+		Provenance
+		Err error
+	}
+
+	// NodeInfo is the GX source information of a node.
+	// Backends recording node information annotate the errors raised by a node
+	// with its information using WithProvenance, such that the GX expression
+	// which created the node can be reported.
+	NodeInfo struct {
+		// Name of the GX expression creating the node, if any. For example, a variable name.
+		Name string
+		// Pos is the position of the GX expression creating the node.
+		Pos token.Position
+	}
+
+	// NodeInfoRecorder is implemented by graphs recording the GX source information of their nodes.
+	NodeInfoRecorder interface {
+		// SetNodeInfo sets the information attached to the nodes created from now on
+		// in the graph and in its subgraphs.
+		SetNodeInfo(info NodeInfo)
+
+		// NodeInfo returns the information attached to a node of the graph.
+		NodeInfo(n Node) (NodeInfo, bool)
+	}
+)
+
+// WithProvenance annotates an error with the provenance of a node.
+// It returns nil if err is nil. Errors already annotated are returned unchanged
+// since the innermost annotation is the most precise.
+func WithProvenance(err error, prov Provenance) error {
+	if err == nil {
+		return nil
+	}
+	var pErr *ProvenanceError
+	if errors.As(err, &pErr) {
+		return err
+	}
+	return &ProvenanceError{Provenance: prov, Err: err}
+}
+
+// ProvenanceOf returns the provenance of the first annotated error in the chain of err.
+func ProvenanceOf(err error) (Provenance, bool) {
+	var pErr *ProvenanceError
+	if !errors.As(err, &pErr) {
+		return Provenance{}, false
+	}
+	return pErr.Provenance, true
+}
+
+// String formats the provenance as path: op name(operand shapes) at source.
+func (p Provenance) String() string {
+	var b strings.Builder
+	if p.GraphPath != "" {
+		b.WriteString(p.GraphPath)
+		b.WriteString(": ")
+	}
+	b.WriteString(string(p.Op))
+	if p.Name != "" {
+		fmt.Fprintf(&b, " %q", p.Name)
+	}
+	if p.OperandShapes != nil {
+		b.WriteByte('(')
+		for i, sh := range p.OperandShapes {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if sh == nil {
+				b.WriteByte('?')
+				continue
+			}
+			b.WriteString(sh.String())
+		}
+		b.WriteByte(')')
+	}
+	if src := p.source(); src != "" {
+		b.WriteString(" at ")
+		b.WriteString(src)
+	}
+	return b.String()
+}
+
+// source returns the source location of the node, or an empty string if unknown.
+func (p Provenance) source() string {
+	if p.Source != "" {
+		return p.Source
+	}
+	if p.Pos.Filename != "" || p.Pos.IsValid() {
+		return p.Pos.String()
+	}
+	return ""
+}
+
+// Error returns the provenance followed by the error message.
+func (e *ProvenanceError) Error() string {
+	return e.Provenance.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e *ProvenanceError) Unwrap() error {
+	return e.Err
+}
+
+// SetNodeInfo sets the information attached to the nodes created from now on in a graph.
+// It returns false if the graph does not implement NodeInfoRecorder.
+func SetNodeInfo(g Graph, info NodeInfo) bool {
+	rec, ok := g.(NodeInfoRecorder)
+	if ok {
+		rec.SetNodeInfo(info)
+	}
+	return ok
+}
+
+// NodeInfoOf returns the information attached to a node by the graph owning it.
+func NodeInfoOf(n Node) (NodeInfo, bool) {
+	rec, ok := n.Graph().(NodeInfoRecorder)
+	if !ok {
+		return NodeInfo{}, false
+	}
+	return rec.NodeInfo(n)
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ops
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
+
+// RngAlgorithm is the algorithm used by a random number generator to compute
+// random bits and the next state from a state.
+type RngAlgorithm int
+
+const (
+	// DefaultRng lets the backend choose the algorithm.
+	// Generated values may differ between backends.
+	DefaultRng RngAlgorithm = iota
+
+	// ThreeFryRng is the Threefry counter-based algorithm.
+	ThreeFryRng
+
+	// PhiloxRng is the Philox counter-based algorithm.
+	PhiloxRng
+)
+
+// String returns a string representation of the algorithm.
+func (a RngAlgorithm) String() string {
+	switch a {
+	case DefaultRng:
+		return "default"
+	case ThreeFryRng:
+		return "threefry"
+	case PhiloxRng:
+		return "philox"
+	}
+	return fmt.Sprintf("RngAlgorithm(%d)", int(a))
+}
+
+// RngStateShape returns the shape of the state of a random number generator:
+// an array of two uint64.
+func RngStateShape() *shape.Shape {
+	return shape.Of(dtype.Uint64, 2)
+}
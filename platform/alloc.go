@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// AllocOptions are the options of an allocation.
+	AllocOptions struct {
+		// Pinned requests page-locked memory which devices can access directly,
+		// enabling full-bandwidth DMA transfers.
+		Pinned bool
+
+		// Alignment is the minimum alignment, in bytes, of the start of the buffer.
+		// It must be zero or a power of two. Zero means the default alignment of the data type.
+		Alignment int
+
+		// Unified requests memory accessible by both the host and a device, for example on
+		// integrated GPUs. Allocators supporting it return a UnifiedBuffer.
+		Unified bool
+
+		// Padding is the number of bytes allocated after the end of the array.
+		// The padding is accessible by extending the slice returned by Acquire up to its capacity.
+		Padding int
+	}
+
+	// AllocOption sets an option of an allocation.
+	AllocOption func(*AllocOptions)
+
+	// PinnedBuffer is implemented by host buffers which can report if their memory is page-locked.
+	PinnedBuffer interface {
+		HostBuffer
+
+		// IsPinned returns true if the memory of the buffer is page-locked.
+		IsPinned() bool
+	}
+
+	// UnifiedBuffer is a buffer in unified memory: it is both a host buffer
+	// and a handle on a device, such that no transfer is required between the two.
+	UnifiedBuffer interface {
+		HostBuffer
+		DeviceHandle
+	}
+)
+
+// Pinned requests page-locked memory.
+// Allocators unable to pin memory return regular memory: use IsPinned to check the result.
+func Pinned() AllocOption {
+	return func(opts *AllocOptions) {
+		opts.Pinned = true
+	}
+}
+
+// Unified requests memory accessible by both the host and a device.
+// Allocators unable to allocate unified memory return regular host memory:
+// use AsDeviceHandle to check the result.
+func Unified() AllocOption {
+	return func(opts *AllocOptions) {
+		opts.Unified = true
+	}
+}
+
+// AsDeviceHandle returns the host buffer as a handle on a device without any transfer
+// if the buffer is in memory unified with that device.
+func AsDeviceHandle(buf HostBuffer, dev Device) (DeviceHandle, bool) {
+	unified, ok := buf.(UnifiedBuffer)
+	if !ok || unified.Device() != dev {
+		return nil, false
+	}
+	return unified, true
+}
+
+// Aligned requests memory aligned on a number of bytes, for example to satisfy DMA requirements
+// or to reinterpret the buffer with a wider data type.
+func Aligned(alignment int) AllocOption {
+	return func(opts *AllocOptions) {
+		opts.Alignment = alignment
+	}
+}
+
+// Padded requests extra bytes after the end of the array.
+func Padded(padding int) AllocOption {
+	return func(opts *AllocOptions) {
+		opts.Padding = padding
+	}
+}
+
+// NewAllocOptions returns the options resulting from applying a list of options.
+func NewAllocOptions(opts ...AllocOption) AllocOptions {
+	var res AllocOptions
+	for _, opt := range opts {
+		opt(&res)
+	}
+	return res
+}
+
+// IsPinned returns true if the memory of a host buffer is page-locked.
+func IsPinned(buf HostBuffer) bool {
+	pinned, ok := buf.(PinnedBuffer)
+	return ok && pinned.IsPinned()
+}
+
+// Check returns an error if the options are invalid.
+func (opts AllocOptions) Check() error {
+	if opts.Alignment < 0 || opts.Alignment&(opts.Alignment-1) != 0 {
+		return errors.Errorf("alignment %d is not a power of two", opts.Alignment)
+	}
+	if opts.Padding < 0 {
+		return errors.Errorf("negative padding %d", opts.Padding)
+	}
+	return nil
+}
+
+// AlignedBytes returns a slice of size bytes from the Go heap satisfying the alignment
+// and padding of the options. The capacity of the slice includes the padding.
+// Allocators can use it to implement the options.
+func AlignedBytes(size int, opts AllocOptions) ([]byte, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	align := max(opts.Alignment, 1)
+	full := size + opts.Padding
+	raw := make([]byte, full+align-1)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(unsafe.SliceData(raw))) % uintptr(align)); rem != 0 {
+		offset = align - rem
+	}
+	return raw[offset : offset+size : offset+full], nil
+}
+
+// Capacity returns the number of bytes usable in a host buffer, including its padding.
+// It returns 0 if the buffer has been freed.
+func Capacity(buf HostBuffer) int {
+	data := buf.AcquireRead()
+	defer buf.ReleaseRead()
+	return cap(data)
+}
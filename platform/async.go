@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+// AsyncHandle is implemented by handles able to transfer their data without blocking the host.
+type AsyncHandle interface {
+	Handle
+
+	// ToHostAsync starts fetching the data into a buffer.
+	// The buffer must not be accessed until the returned event has completed.
+	ToHostAsync(buffer HostBuffer) Event
+
+	// ToDeviceAsync starts transferring the handle to a device.
+	ToDeviceAsync(Device) *PendingHandle
+}
+
+// PendingHandle is a device handle which may not be available yet.
+type PendingHandle struct {
+	ev     *HostEvent
+	handle DeviceHandle
+}
+
+var _ Event = (*PendingHandle)(nil)
+
+// NewPendingHandle returns a pending handle to be resolved by the caller.
+func NewPendingHandle() *PendingHandle {
+	return &PendingHandle{ev: NewHostEvent()}
+}
+
+// Resolve sets the handle or the error of the transfer and completes the pending handle.
+// Only the first call has an effect.
+func (p *PendingHandle) Resolve(handle DeviceHandle, err error) {
+	p.ev.once.Do(func() {
+		p.handle = handle
+		p.ev.err = err
+		close(p.ev.done)
+	})
+}
+
+// Done returns a channel closed when the handle is available.
+func (p *PendingHandle) Done() <-chan struct{} {
+	return p.ev.Done()
+}
+
+// Query returns true if the transfer has completed.
+func (p *PendingHandle) Query() (bool, error) {
+	return p.ev.Query()
+}
+
+// Wait blocks until the transfer has completed.
+func (p *PendingHandle) Wait() error {
+	return p.ev.Wait()
+}
+
+// Handle blocks until the transfer has completed and returns the device handle.
+func (p *PendingHandle) Handle() (DeviceHandle, error) {
+	if err := p.ev.Wait(); err != nil {
+		return nil, err
+	}
+	return p.handle, nil
+}
+
+// ToHostAsync starts fetching the data of a handle into a buffer and returns an event
+// completing when the transfer is done. If the handle does not implement AsyncHandle,
+// the transfer runs in a separate goroutine.
+func ToHostAsync(h Handle, buffer HostBuffer) Event {
+	if async, ok := h.(AsyncHandle); ok {
+		return async.ToHostAsync(buffer)
+	}
+	ev := NewHostEvent()
+	go func() {
+		ev.Complete(h.ToHost(buffer))
+	}()
+	return ev
+}
+
+// ToDeviceAsync starts transferring a handle to a device. If the handle does not implement
+// AsyncHandle, the transfer runs in a separate goroutine.
+func ToDeviceAsync(h Handle, dev Device) *PendingHandle {
+	if async, ok := h.(AsyncHandle); ok {
+		return async.ToDeviceAsync(dev)
+	}
+	p := NewPendingHandle()
+	go func() {
+		p.Resolve(h.ToDevice(dev))
+	}()
+	return p
+}
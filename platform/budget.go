@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "sync"
+
+// Budget limits the memory used on a device.
+// Platforms reserve memory from the budget before allocating device memory
+// and release it when the memory is freed. A Budget is safe for concurrent use.
+type Budget struct {
+	device int
+	limit  int64
+
+	mu   sync.Mutex
+	used int64
+	peak int64
+}
+
+// NewBudget returns a budget of limit bytes for a device.
+// A limit of zero or less means no limit.
+func NewBudget(device int, limit int64) *Budget {
+	return &Budget{device: device, limit: limit}
+}
+
+// Reserve n bytes from the budget. It returns an *OutOfMemoryError if the budget would be exceeded.
+// The label identifies the array in the error, if any.
+func (b *Budget) Reserve(n int64, label string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.used+n > b.limit {
+		return &OutOfMemoryError{
+			Device:    b.device,
+			Requested: n,
+			Available: b.limit - b.used,
+			Label:     label,
+		}
+	}
+	b.used += n
+	b.peak = max(b.peak, b.used)
+	return nil
+}
+
+// Release n bytes previously reserved.
+func (b *Budget) Release(n int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+}
+
+// Used returns the number of bytes currently reserved.
+func (b *Budget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// Peak returns the maximum number of bytes reserved at the same time
+// since the budget has been created or since the last call to ResetPeak.
+func (b *Budget) Peak() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.peak
+}
+
+// ResetPeak resets the peak to the number of bytes currently reserved.
+func (b *Budget) ResetPeak() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peak = b.used
+}
+
+// Limit returns the maximum number of bytes of the budget, or zero or less if there is no limit.
+func (b *Budget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// bytesBuffer is a host buffer storing its data in a Go slice.
+type bytesBuffer struct {
+	Labeled
+	mu    sync.RWMutex
+	shape *shape.Shape
+	data  []byte
+}
+
+func newBytesBuffer(data []byte, sh *shape.Shape) *bytesBuffer {
+	return &bytesBuffer{shape: sh, data: data}
+}
+
+func (b *bytesBuffer) Shape() *shape.Shape {
+	return b.shape
+}
+
+func (b *bytesBuffer) ToDevice(dev Device) (DeviceHandle, error) {
+	data := b.AcquireRead()
+	defer b.ReleaseRead()
+	return dev.Send(data, b.shape)
+}
+
+func (b *bytesBuffer) ToHost(dst HostBuffer) error {
+	return HostTransfer(dst, b)
+}
+
+func (b *bytesBuffer) Acquire() []byte {
+	b.mu.Lock()
+	return b.data
+}
+
+func (b *bytesBuffer) Release() {
+	b.mu.Unlock()
+}
+
+func (b *bytesBuffer) AcquireRead() []byte {
+	b.mu.RLock()
+	return b.data
+}
+
+func (b *bytesBuffer) ReleaseRead() {
+	b.mu.RUnlock()
+}
+
+func (b *bytesBuffer) Free() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = nil
+}
+
+// Borrow returns a host buffer using an existing slice as its storage without copying it.
+//
+// The caller keeps ownership of the slice: Free only drops the reference held by the buffer
+// and never releases the memory. The slice must not be modified while the buffer is acquired
+// by another user, or while an asynchronous transfer from or to the buffer is in flight.
+// The slice is regular Go memory and is therefore not pinned: platforms may need to stage
+// it through pinned memory before a transfer (see IsPinned).
+func Borrow(data []byte, sh *shape.Shape) (HostBuffer, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrapf(ErrInvalidShape, "cannot borrow data: %v", err)
+	}
+	if len(data) != sh.ByteSize() {
+		return nil, errors.Wrapf(ErrInvalidShape, "cannot borrow %d bytes for an array of shape %s: want %d bytes", len(data), sh, sh.ByteSize())
+	}
+	if !dtype.IsAligned(data, sh.DType) {
+		return nil, errors.Errorf("cannot borrow data for an array of shape %s: data is not aligned on %d bytes", sh, dtype.AlignOf(sh.DType))
+	}
+	return newBytesBuffer(data, sh), nil
+}
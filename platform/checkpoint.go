@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"slices"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// checkpointMagic identifies a checkpoint stream.
+// A checkpoint is the magic, followed by the number of arrays, followed by each array.
+// An array is its name, its shape encoded in JSON, and its data.
+// Each field is prefixed by its length, encoded as a little-endian uint64.
+const checkpointMagic = "GXCKPT01"
+
+// maxCheckpointField is the maximum size of a name or a shape in a checkpoint.
+const maxCheckpointField = 1 << 20
+
+// SaveCheckpoint writes the content of a set of named handles to a writer.
+// Arrays are written in the order of their names.
+func SaveCheckpoint(w io.Writer, handles map[string]Handle) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(checkpointMagic); err != nil {
+		return err
+	}
+	if err := writeUint64(bw, uint64(len(handles))); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(handles))
+	for name := range handles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := saveHandle(bw, name, handles[name]); err != nil {
+			return errors.Errorf("cannot checkpoint array %q: %v", name, err)
+		}
+	}
+	return bw.Flush()
+}
+
+func saveHandle(w io.Writer, name string, h Handle) error {
+	sh := h.Shape()
+	shapeJSON, err := sh.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	staging, err := stageToHost(h)
+	if err != nil {
+		return err
+	}
+	defer staging.release()
+	for _, field := range [][]byte{[]byte(name), shapeJSON, staging.data} {
+		if err := writeField(w, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadCheckpoint reads arrays written by SaveCheckpoint and sends them to a device.
+func LoadCheckpoint(r io.Reader, dev Device) (map[string]DeviceHandle, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(checkpointMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, errors.Errorf("cannot read checkpoint header: %v", err)
+	}
+	if string(magic) != checkpointMagic {
+		return nil, errors.Errorf("invalid checkpoint header %q", magic)
+	}
+	n, err := readUint64(br)
+	if err != nil {
+		return nil, errors.Errorf("cannot read the number of arrays: %v", err)
+	}
+	handles := make(map[string]DeviceHandle)
+	for i := range n {
+		name, h, err := loadHandle(br, dev)
+		if err != nil {
+			for _, h := range handles {
+				h.Free()
+			}
+			return nil, errors.Errorf("cannot load array %d: %v", i, err)
+		}
+		handles[name] = h
+	}
+	return handles, nil
+}
+
+func loadHandle(r io.Reader, dev Device) (string, DeviceHandle, error) {
+	name, err := readField(r, maxCheckpointField)
+	if err != nil {
+		return "", nil, err
+	}
+	shapeJSON, err := readField(r, maxCheckpointField)
+	if err != nil {
+		return "", nil, err
+	}
+	sh := &shape.Shape{}
+	if err := sh.UnmarshalJSON(shapeJSON); err != nil {
+		return "", nil, errors.Wrap(ErrInvalidShape, err.Error())
+	}
+	size, err := sh.CheckedByteSize()
+	if err != nil {
+		return "", nil, errors.Wrap(ErrInvalidShape, err.Error())
+	}
+	data, err := readField(r, uint64(size))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) != size {
+		return "", nil, errors.Wrapf(ErrInvalidShape, "array %q has %d bytes but its shape %s requires %d bytes", name, len(data), sh, size)
+	}
+	h, err := dev.Send(data, sh)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(name), h, nil
+}
+
+func writeUint64(w io.Writer, v uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+func readUint64(r io.Reader) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func writeField(w io.Writer, field []byte) error {
+	if err := writeUint64(w, uint64(len(field))); err != nil {
+		return err
+	}
+	_, err := w.Write(field)
+	return err
+}
+
+func readField(r io.Reader, maxSize uint64) ([]byte, error) {
+	size, err := readUint64(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, errors.Errorf("field of %d bytes exceeds the maximum of %d bytes", size, maxSize)
+	}
+	field := make([]byte, size)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestCheckpoint(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	weights, err := dev.Send(dtype.FromSlice([]float32{1, 2, 3, 4}), shape.Matrix(dtype.Float32, 2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, err := dev.Send(dtype.FromSlice([]int64{7}), shape.Scalar(dtype.Int64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := platform.SaveCheckpoint(&buf, map[string]platform.Handle{"weights": weights, "step": step}); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := platform.LoadCheckpoint(&buf, dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 2 {
+		t.Fatalf("got %d arrays but want 2", len(restored))
+	}
+	got := restored["weights"].(*platformtest.Handle)
+	if !got.Shape().Equal(weights.Shape()) {
+		t.Errorf("got shape %s but want %s", got.Shape(), weights.Shape())
+	}
+	if want := []float32{1, 2, 3, 4}; !slices.Equal(dtype.ToSlice[float32](got.Data()), want) {
+		t.Errorf("got %v but want %v", dtype.ToSlice[float32](got.Data()), want)
+	}
+	if _, err := platform.LoadCheckpoint(bytes.NewReader([]byte("garbage!")), dev); err == nil {
+		t.Errorf("expected an error for an invalid checkpoint")
+	}
+}
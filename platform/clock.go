@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// clockSamples is the number of samples used to synchronize a device clock.
+const clockSamples = 8
+
+// ClockSync maps timestamps of a device clock to the host clock.
+type ClockSync struct {
+	// Device is the ordinal of the device.
+	Device int
+	// Epoch is the host time corresponding to a device timestamp of zero.
+	Epoch time.Time
+	// Uncertainty is the maximum error of the mapping.
+	Uncertainty time.Duration
+}
+
+// SyncClock estimates the offset between the clock of a device and the host clock.
+// The device clock is sampled multiple times and the sample with the smallest round trip is kept.
+func SyncClock(dev Device) (*ClockSync, error) {
+	var best *ClockSync
+	for range clockSamples {
+		before := time.Now()
+		deviceTime, err := dev.Now()
+		if err != nil {
+			return nil, errors.Errorf("cannot read the clock of device %d: %v", dev.Ordinal(), err)
+		}
+		roundTrip := time.Since(before)
+		// Assume the device clock was read in the middle of the round trip.
+		mid := before.Add(roundTrip / 2)
+		sync := &ClockSync{
+			Device:      dev.Ordinal(),
+			Epoch:       mid.Add(-deviceTime),
+			Uncertainty: roundTrip / 2,
+		}
+		if best == nil || sync.Uncertainty < best.Uncertainty {
+			best = sync
+		}
+	}
+	return best, nil
+}
+
+// HostTime converts a device timestamp to the host clock.
+func (s *ClockSync) HostTime(deviceTime time.Duration) time.Time {
+	return s.Epoch.Add(deviceTime)
+}
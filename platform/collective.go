@@ -0,0 +1,276 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// ReduceOp is the reduction applied by an all-reduce.
+type ReduceOp int
+
+const (
+	// ReduceSum sums the arrays.
+	ReduceSum ReduceOp = iota
+	// ReduceProd multiplies the arrays.
+	ReduceProd
+	// ReduceMin computes the element-wise minimum of the arrays.
+	ReduceMin
+	// ReduceMax computes the element-wise maximum of the arrays.
+	ReduceMax
+)
+
+func (op ReduceOp) String() string {
+	switch op {
+	case ReduceSum:
+		return "sum"
+	case ReduceProd:
+		return "prod"
+	case ReduceMin:
+		return "min"
+	case ReduceMax:
+		return "max"
+	}
+	return fmt.Sprintf("ReduceOp(%d)", int(op))
+}
+
+type (
+	// Communicator exchanges data between a group of devices outside of compiled graphs.
+	// For all operations taking a slice of handles, the ith handle must be located
+	// on the ith device of the group.
+	Communicator interface {
+		// Devices returns the group of devices of the communicator.
+		Devices() []Device
+
+		// AllReduce reduces the arrays of all the devices and returns the result on each device.
+		AllReduce(op ReduceOp, handles []DeviceHandle) ([]DeviceHandle, error)
+
+		// Broadcast copies an array to all the devices of the group.
+		Broadcast(src DeviceHandle) ([]DeviceHandle, error)
+
+		// AllGather concatenates the arrays of all the devices along an axis
+		// and returns the result on each device.
+		AllGather(axis int, handles []DeviceHandle) ([]DeviceHandle, error)
+	}
+
+	// CommunicatorPlatform is implemented by platforms providing collective operations
+	// between their devices.
+	CommunicatorPlatform interface {
+		Platform
+
+		// NewCommunicator returns a communicator for a group of devices.
+		NewCommunicator(devices []Device) (Communicator, error)
+	}
+)
+
+// NewCommunicator returns a communicator for a group of devices.
+// The platform communicator is used if the platform implements CommunicatorPlatform.
+// Otherwise, data is exchanged through host memory.
+func NewCommunicator(plat Platform, devices []Device) (Communicator, error) {
+	if len(devices) == 0 {
+		return nil, errors.Errorf("cannot create a communicator without devices")
+	}
+	if comm, ok := plat.(CommunicatorPlatform); ok {
+		return comm.NewCommunicator(devices)
+	}
+	return &hostCommunicator{devices: devices}, nil
+}
+
+// hostCommunicator stages all the data through the host.
+type hostCommunicator struct {
+	devices []Device
+}
+
+func (c *hostCommunicator) Devices() []Device {
+	return c.devices
+}
+
+// fetch transfers all the handles to the host and checks that they are located on the group devices.
+// The caller must call the returned function once the data is not used anymore.
+func (c *hostCommunicator) fetch(handles []DeviceHandle) ([][]byte, func(), error) {
+	if len(handles) != len(c.devices) {
+		return nil, nil, errors.Errorf("got %d handles for a group of %d devices", len(handles), len(c.devices))
+	}
+	var stagings []*staged
+	release := func() {
+		for _, s := range stagings {
+			s.release()
+		}
+	}
+	data := make([][]byte, len(handles))
+	for i, handle := range handles {
+		if handle.Device() != c.devices[i] {
+			release()
+			return nil, nil, errors.Errorf("handle %d is located on device %d but want device %d", i, handle.Device().Ordinal(), c.devices[i].Ordinal())
+		}
+		staging, err := stageToHost(handle)
+		if err != nil {
+			release()
+			return nil, nil, errors.Errorf("cannot fetch array %d from device %d: %v", i, handle.Device().Ordinal(), err)
+		}
+		stagings = append(stagings, staging)
+		data[i] = staging.data
+	}
+	return data, release, nil
+}
+
+func (c *hostCommunicator) sendAll(data []byte, sh *shape.Shape) ([]DeviceHandle, error) {
+	handles := make([]DeviceHandle, len(c.devices))
+	for i, dev := range c.devices {
+		var err error
+		if handles[i], err = dev.Send(data, sh); err != nil {
+			for _, h := range handles[:i] {
+				h.Free()
+			}
+			return nil, errors.Wrapf(err, "cannot send array to device %d", dev.Ordinal())
+		}
+	}
+	return handles, nil
+}
+
+func (c *hostCommunicator) AllReduce(op ReduceOp, handles []DeviceHandle) ([]DeviceHandle, error) {
+	data, release, err := c.fetch(handles)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sh := handles[0].Shape()
+	for i, handle := range handles[1:] {
+		if !handle.Shape().Equal(sh) {
+			return nil, errors.Errorf("cannot reduce array %d of shape %s with an array of shape %s", i+1, handle.Shape(), sh)
+		}
+	}
+	acc := append([]byte{}, data[0]...)
+	for _, other := range data[1:] {
+		if err := reduceInto(op, sh.DType, acc, other); err != nil {
+			return nil, err
+		}
+	}
+	return c.sendAll(acc, sh)
+}
+
+func (c *hostCommunicator) Broadcast(src DeviceHandle) ([]DeviceHandle, error) {
+	staging, err := stageToHost(src)
+	if err != nil {
+		return nil, errors.Errorf("cannot fetch array from device %d: %v", src.Device().Ordinal(), err)
+	}
+	defer staging.release()
+	return c.sendAll(staging.data, src.Shape())
+}
+
+func (c *hostCommunicator) AllGather(axis int, handles []DeviceHandle) ([]DeviceHandle, error) {
+	data, release, err := c.fetch(handles)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	shapes := make([]*shape.Shape, len(handles))
+	for i, handle := range handles {
+		shapes[i] = handle.Shape()
+	}
+	out, err := shape.ConcatShape(axis, shapes...)
+	if err != nil {
+		return nil, err
+	}
+	if dtype.IsSubByte(out.DType) || out.IsBitPacked() {
+		return nil, errors.Errorf("cannot gather arrays of packed data type %s", out.DType)
+	}
+	outer := shape.Size(out.AxisLengths[:axis])
+	gathered := make([]byte, 0, out.ByteSize())
+	for o := range outer {
+		for i, sh := range shapes {
+			chunk := shape.Size(sh.AxisLengths[axis:]) * dtype.Sizeof(sh.DType)
+			gathered = append(gathered, data[i][o*chunk:(o+1)*chunk]...)
+		}
+	}
+	return c.sendAll(gathered, out)
+}
+
+// reduceInto applies a reduction element-wise and stores the result in acc.
+func reduceInto(op ReduceOp, dt dtype.DataType, acc, other []byte) error {
+	switch dt {
+	case dtype.Bool:
+		return reduceBools(op, acc, other)
+	case dtype.Int:
+		return reduceSlices[int](op, acc, other)
+	case dtype.Int8:
+		return reduceSlices[int8](op, acc, other)
+	case dtype.Int16:
+		return reduceSlices[int16](op, acc, other)
+	case dtype.Int32:
+		return reduceSlices[int32](op, acc, other)
+	case dtype.Int64:
+		return reduceSlices[int64](op, acc, other)
+	case dtype.Uint8:
+		return reduceSlices[uint8](op, acc, other)
+	case dtype.Uint16:
+		return reduceSlices[uint16](op, acc, other)
+	case dtype.Uint32:
+		return reduceSlices[uint32](op, acc, other)
+	case dtype.Uint64:
+		return reduceSlices[uint64](op, acc, other)
+	case dtype.Float32:
+		return reduceSlices[float32](op, acc, other)
+	case dtype.Float64:
+		return reduceSlices[float64](op, acc, other)
+	}
+	return errors.Errorf("all-reduce on the host not supported for data type %s", dt)
+}
+
+func reduceSlices[T dtype.Float | dtype.IntegerType](op ReduceOp, accB, otherB []byte) error {
+	acc, other := dtype.ToSlice[T](accB), dtype.ToSlice[T](otherB)
+	switch op {
+	case ReduceSum:
+		for i := range acc {
+			acc[i] += other[i]
+		}
+	case ReduceProd:
+		for i := range acc {
+			acc[i] *= other[i]
+		}
+	case ReduceMin:
+		for i := range acc {
+			acc[i] = min(acc[i], other[i])
+		}
+	case ReduceMax:
+		for i := range acc {
+			acc[i] = max(acc[i], other[i])
+		}
+	default:
+		return errors.Errorf("reduction %s not supported", op)
+	}
+	dtype.WriteBack(accB, acc)
+	return nil
+}
+
+func reduceBools(op ReduceOp, acc, other []byte) error {
+	switch op {
+	case ReduceSum, ReduceMax:
+		for i := range acc {
+			acc[i] |= other[i]
+		}
+	case ReduceProd, ReduceMin:
+		for i := range acc {
+			acc[i] &= other[i]
+		}
+	default:
+		return errors.Errorf("reduction %s not supported", op)
+	}
+	return nil
+}
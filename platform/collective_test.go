@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestAllReduce(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := [][]float32{{1, -2, 3}, {4, 5, -6}, {-7, 8, 9}, {10, -11, 12}}
+	tests := []struct {
+		op   platform.ReduceOp
+		n    int
+		want []float32
+	}{
+		{op: platform.ReduceSum, n: 2, want: []float32{5, 3, -3}},
+		{op: platform.ReduceSum, n: 3, want: []float32{-2, 11, 6}},
+		{op: platform.ReduceSum, n: 4, want: []float32{8, 0, 18}},
+		{op: platform.ReduceMax, n: 2, want: []float32{4, 5, 3}},
+		{op: platform.ReduceMax, n: 3, want: []float32{4, 8, 9}},
+		{op: platform.ReduceMax, n: 4, want: []float32{10, 8, 12}},
+	}
+	sh := shape.Vector(dtype.Float32, 3)
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s/%d", test.op, test.n), func(t *testing.T) {
+			devices := make([]platform.Device, test.n)
+			handles := make([]platform.DeviceHandle, test.n)
+			for i := range test.n {
+				devices[i] = dev
+				if handles[i], err = dev.Send(dtype.CopyFromSlice(inputs[i]), sh); err != nil {
+					t.Fatal(err)
+				}
+				defer handles[i].Free()
+			}
+			comm, err := platform.NewCommunicator(plat, devices)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := comm.AllReduce(test.op, handles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) != test.n {
+				t.Fatalf("got %d results but want %d", len(out), test.n)
+			}
+			for i, h := range out {
+				buf, err := plat.Allocate(sh)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := h.ToHost(buf); err != nil {
+					t.Fatal(err)
+				}
+				got := slices.Clone(dtype.ToSlice[float32](buf.AcquireRead()))
+				buf.ReleaseRead()
+				buf.Free()
+				h.Free()
+				if !slices.Equal(got, test.want) {
+					t.Errorf("result %d: got %v but want %v", i, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAllReduceErrors(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := dev.Send(dtype.CopyFromSlice([]float32{1, 2, 3}), shape.Vector(dtype.Float32, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Free()
+	y, err := dev.Send(dtype.CopyFromSlice([]float32{1, 2}), shape.Vector(dtype.Float32, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer y.Free()
+	comm, err := platform.NewCommunicator(plat, []platform.Device{dev, dev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := comm.AllReduce(platform.ReduceSum, []platform.DeviceHandle{x, y}); err == nil {
+		t.Errorf("expected an error when reducing arrays of different shapes")
+	}
+	if _, err := comm.AllReduce(platform.ReduceSum, []platform.DeviceHandle{x}); err == nil {
+		t.Errorf("expected an error when the number of handles does not match the group")
+	}
+	if _, err := platform.NewCommunicator(plat, nil); err == nil {
+		t.Errorf("expected an error for an empty group")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// Config is passed to a factory to create a platform.
+// Platforms ignore the fields they do not support.
+type Config struct {
+	// VisibleDevices restricts the platform to the devices with the given ordinals.
+	// All devices are visible if empty.
+	VisibleDevices []int
+
+	// MemoryFraction is the fraction, in (0, 1], of the memory of each device the platform
+	// can use. The platform chooses if zero.
+	MemoryFraction float64
+
+	// MemoryLimit is the maximum number of bytes the platform can allocate on each device.
+	// There is no limit if zero.
+	MemoryLimit int64
+
+	// Allocator is the name of the allocator used by the platform, for example "pool".
+	// The platform chooses if empty.
+	Allocator string
+
+	// Deterministic requests the platform to produce bit-exact results across runs,
+	// possibly at the expense of performance.
+	Deterministic bool
+
+	// DevicePolicy selects the default device of the platform.
+	// The device with the lowest ordinal is selected if nil.
+	DevicePolicy DevicePolicy
+
+	// Params are platform-specific parameters.
+	Params map[string]string
+}
+
+// Option configures the creation of a platform.
+type Option func(*Config)
+
+// NewConfig returns a configuration given a list of options.
+func NewConfig(opts ...Option) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Check returns an error if the configuration is invalid.
+func (cfg Config) Check() error {
+	if !(cfg.MemoryFraction >= 0 && cfg.MemoryFraction <= 1) {
+		return errors.Errorf("memory fraction %g not in (0, 1]", cfg.MemoryFraction)
+	}
+	if cfg.MemoryLimit < 0 {
+		return errors.Errorf("negative memory limit %d", cfg.MemoryLimit)
+	}
+	for i, ordinal := range cfg.VisibleDevices {
+		if ordinal < 0 {
+			return errors.Errorf("invalid visible device ordinal %d at position %d", ordinal, i)
+		}
+		if slices.Index(cfg.VisibleDevices, ordinal) != i {
+			return errors.Errorf("visible device %d listed more than once in %v", ordinal, cfg.VisibleDevices)
+		}
+	}
+	return nil
+}
+
+// IsVisible returns true if the device with the given ordinal is visible to the platform.
+func (cfg Config) IsVisible(ordinal int) bool {
+	if len(cfg.VisibleDevices) == 0 {
+		return true
+	}
+	for _, visible := range cfg.VisibleDevices {
+		if visible == ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+// WithVisibleDevices restricts the platform to a list of devices.
+func WithVisibleDevices(ordinals ...int) Option {
+	return func(cfg *Config) {
+		cfg.VisibleDevices = append(cfg.VisibleDevices, ordinals...)
+	}
+}
+
+// WithMemoryFraction sets the fraction of the device memory used by the platform.
+func WithMemoryFraction(fraction float64) Option {
+	return func(cfg *Config) {
+		cfg.MemoryFraction = fraction
+	}
+}
+
+// WithMemoryLimit sets the maximum number of bytes the platform can allocate on each device.
+func WithMemoryLimit(bytes int64) Option {
+	return func(cfg *Config) {
+		cfg.MemoryLimit = bytes
+	}
+}
+
+// WithAllocator sets the name of the allocator used by the platform.
+func WithAllocator(name string) Option {
+	return func(cfg *Config) {
+		cfg.Allocator = name
+	}
+}
+
+// WithDeterminism requests the platform to produce deterministic results.
+func WithDeterminism() Option {
+	return func(cfg *Config) {
+		cfg.Deterministic = true
+	}
+}
+
+// WithDevicePolicy sets the policy selecting the default device of the platform.
+func WithDevicePolicy(policy DevicePolicy) Option {
+	return func(cfg *Config) {
+		cfg.DevicePolicy = policy
+	}
+}
+
+// WithParam sets a platform-specific parameter.
+func WithParam(key, value string) Option {
+	return func(cfg *Config) {
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string)
+		}
+		cfg.Params[key] = value
+	}
+}
+
+// WithConfig copies all the fields of an existing configuration.
+func WithConfig(src Config) Option {
+	return func(cfg *Config) {
+		*cfg = src
+		cfg.VisibleDevices = append([]int(nil), src.VisibleDevices...)
+		cfg.Params = maps.Clone(src.Params)
+	}
+}
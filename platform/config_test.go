@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/platform"
+)
+
+func TestConfigCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []platform.Option
+		err  string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "valid",
+			opts: []platform.Option{
+				platform.WithVisibleDevices(2, 0),
+				platform.WithMemoryFraction(1),
+				platform.WithMemoryLimit(1 << 20),
+				platform.WithAllocator("pool"),
+			},
+		},
+		{
+			name: "negative memory fraction",
+			opts: []platform.Option{platform.WithMemoryFraction(-0.5)},
+			err:  "memory fraction -0.5 not in (0, 1]",
+		},
+		{
+			name: "memory fraction above one",
+			opts: []platform.Option{platform.WithMemoryFraction(1.5)},
+			err:  "memory fraction 1.5 not in (0, 1]",
+		},
+		{
+			name: "NaN memory fraction",
+			opts: []platform.Option{platform.WithMemoryFraction(math.NaN())},
+			err:  "memory fraction NaN not in (0, 1]",
+		},
+		{
+			name: "negative memory limit",
+			opts: []platform.Option{platform.WithMemoryLimit(-1)},
+			err:  "negative memory limit -1",
+		},
+		{
+			name: "negative visible device",
+			opts: []platform.Option{platform.WithVisibleDevices(0, -1)},
+			err:  "invalid visible device ordinal -1 at position 1",
+		},
+		{
+			name: "duplicate visible device",
+			opts: []platform.Option{platform.WithVisibleDevices(1, 0), platform.WithVisibleDevices(1)},
+			err:  "visible device 1 listed more than once in [1 0 1]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := platform.NewConfig(test.opts...).Check()
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %v but want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestIsVisible(t *testing.T) {
+	tests := []struct {
+		visible []int
+		ordinal int
+		want    bool
+	}{
+		{visible: nil, ordinal: 0, want: true},
+		{visible: nil, ordinal: 3, want: true},
+		{visible: []int{1, 3}, ordinal: 3, want: true},
+		{visible: []int{1, 3}, ordinal: 0, want: false},
+		{visible: []int{1, 3}, ordinal: 2, want: false},
+	}
+	for _, test := range tests {
+		cfg := platform.NewConfig(platform.WithVisibleDevices(test.visible...))
+		if got := cfg.IsVisible(test.ordinal); got != test.want {
+			t.Errorf("visible devices %v: IsVisible(%d) = %v but want %v", test.visible, test.ordinal, got, test.want)
+		}
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	src := platform.NewConfig(platform.WithVisibleDevices(0), platform.WithParam("threads", "4"))
+	cfg := platform.NewConfig(platform.WithConfig(src))
+	cfg.VisibleDevices[0] = 1
+	cfg.Params["threads"] = "8"
+	if src.VisibleDevices[0] != 0 || src.Params["threads"] != "4" {
+		t.Errorf("changing a copied configuration changed the source configuration to %+v", src)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "context"
+
+// ContextTransferer is implemented by handles able to stop a transfer to a device before it completes.
+// See ProgressSender and ProgressFetcher for transfers of raw data and transfers to the host.
+type ContextTransferer interface {
+	Handle
+
+	// ToDeviceContext transfers the handle to a device as ToDevice does.
+	// The transfer is aborted when the context is done.
+	ToDeviceContext(ctx context.Context, dev Device) (DeviceHandle, error)
+}
+
+// ToDeviceContext transfers a handle to a device, enforcing the context deadline.
+// If the handle does not implement ContextTransferer, the transfer runs as ToDeviceAsync does.
+// If the context is done before the transfer completes, the error of the context is returned
+// and the handle is freed once the transfer completes.
+func ToDeviceContext(ctx context.Context, h Handle, dev Device) (DeviceHandle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ct, ok := h.(ContextTransferer); ok {
+		return ct.ToDeviceContext(ctx, dev)
+	}
+	p := ToDeviceAsync(h, dev)
+	select {
+	case <-p.Done():
+		return p.Handle()
+	case <-ctx.Done():
+		go func() {
+			if handle, err := p.Handle(); err == nil {
+				handle.Free()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
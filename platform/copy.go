@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "github.com/pkg/errors"
+
+// PeerCopier is implemented by device handles which can be copied
+// to another device without going through the host.
+type PeerCopier interface {
+	DeviceHandle
+
+	// CopyTo copies the array to another device.
+	// It returns ErrPeerCopyUnsupported if the destination device cannot be reached directly.
+	CopyTo(Device) (DeviceHandle, error)
+}
+
+// ErrPeerCopyUnsupported is returned by PeerCopier.CopyTo when a direct copy is not possible.
+var ErrPeerCopyUnsupported = errors.New("peer-to-peer copy not supported")
+
+// CopyTo copies the array of a device handle into a new handle on another device.
+// The copy is peer-to-peer if the handle implements PeerCopier and supports
+// the destination device. Otherwise, the data is staged through host memory.
+func CopyTo(src DeviceHandle, dst Device) (DeviceHandle, error) {
+	if peer, ok := src.(PeerCopier); ok {
+		handle, err := peer.CopyTo(dst)
+		if err == nil {
+			return handle, nil
+		}
+		if !errors.Is(err, ErrPeerCopyUnsupported) {
+			return nil, err
+		}
+	}
+	staging, err := stageToHost(src)
+	if err != nil {
+		return nil, err
+	}
+	defer staging.release()
+	return dst.Send(staging.data, src.Shape())
+}
+
+// PeerAccessPlatform is implemented by platforms on which devices can directly
+// access the memory of other devices.
+type PeerAccessPlatform interface {
+	Platform
+
+	// CanAccessPeer returns true if device a can directly access the memory of device b.
+	CanAccessPeer(a, b Device) bool
+
+	// EnablePeerAccess enables direct accesses from device a to the memory of device b.
+	EnablePeerAccess(a, b Device) error
+}
+
+// CanAccessPeer returns true if device a can directly access the memory of device b.
+// A device can always access its own memory. It returns false for all other devices
+// if the platform does not implement PeerAccessPlatform.
+func CanAccessPeer(plat Platform, a, b Device) bool {
+	if a == b {
+		return true
+	}
+	peer, ok := plat.(PeerAccessPlatform)
+	return ok && peer.CanAccessPeer(a, b)
+}
+
+// EnablePeerAccess enables direct accesses from device a to the memory of device b.
+// It returns an error if peer access is not supported between the two devices.
+func EnablePeerAccess(plat Platform, a, b Device) error {
+	if a == b {
+		return nil
+	}
+	peer, ok := plat.(PeerAccessPlatform)
+	if !ok || !peer.CanAccessPeer(a, b) {
+		return errors.Errorf("platform %s does not support peer access from device %d to device %d", plat.Name(), a.Ordinal(), b.Ordinal())
+	}
+	return peer.EnablePeerAccess(a, b)
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// peerHandle is a device handle with a scripted peer-to-peer copy.
+type peerHandle struct {
+	*platformtest.Handle
+	err    error
+	copies int
+}
+
+func (h *peerHandle) CopyTo(dev platform.Device) (platform.DeviceHandle, error) {
+	h.copies++
+	if h.err != nil {
+		return nil, h.err
+	}
+	return h.ToDevice(dev)
+}
+
+// peerPlatform is a platform on which device 0 can access the memory of device 1.
+type peerPlatform struct {
+	*platformtest.Platform
+	enabled bool
+}
+
+func (p *peerPlatform) CanAccessPeer(a, b platform.Device) bool {
+	return a.Ordinal() == 0 && b.Ordinal() == 1
+}
+
+func (p *peerPlatform) EnablePeerAccess(a, b platform.Device) error {
+	p.enabled = true
+	return nil
+}
+
+func TestCopyTo(t *testing.T) {
+	errCopy := errors.New("copy failed")
+	tests := []struct {
+		name       string
+		peer       bool
+		peerErr    error
+		wantErr    error
+		wantCopies int
+		wantToHost int
+	}{
+		{name: "staged", wantToHost: 1},
+		{name: "peer", peer: true, wantCopies: 1},
+		{name: "peer unsupported", peer: true, peerErr: platform.ErrPeerCopyUnsupported, wantCopies: 1, wantToHost: 1},
+		{name: "peer error", peer: true, peerErr: errCopy, wantErr: errCopy, wantCopies: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plat := platformtest.New(2)
+			vals := []float32{1, 2, 3}
+			src, err := plat.MockDevice(0).Send(dtype.CopyFromSlice(vals), shape.Of(dtype.Float32, len(vals)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer src.Free()
+			var handle platform.DeviceHandle = src
+			peer := &peerHandle{Handle: src.(*platformtest.Handle), err: test.peerErr}
+			if test.peer {
+				handle = peer
+			}
+			dst, err := platform.CopyTo(handle, plat.MockDevice(1))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v but want %v", err, test.wantErr)
+			}
+			if got := plat.Counts().ToHosts; got != test.wantToHost {
+				t.Errorf("got %d transfers to the host but want %d", got, test.wantToHost)
+			}
+			if peer.copies != test.wantCopies {
+				t.Errorf("got %d peer copies but want %d", peer.copies, test.wantCopies)
+			}
+			if err != nil {
+				return
+			}
+			defer dst.Free()
+			if got := dst.Device().Ordinal(); got != 1 {
+				t.Errorf("array copied to device %d but want device 1", got)
+			}
+			if got := dtype.ToSlice[float32](dst.(*platformtest.Handle).Data()); !slices.Equal(got, vals) {
+				t.Errorf("got %v but want %v", got, vals)
+			}
+		})
+	}
+}
+
+func TestPeerAccess(t *testing.T) {
+	mock := platformtest.New(2)
+	dev0, dev1 := mock.MockDevice(0), mock.MockDevice(1)
+	if !platform.CanAccessPeer(mock, dev0, dev0) {
+		t.Errorf("a device cannot access its own memory")
+	}
+	if platform.CanAccessPeer(mock, dev0, dev1) {
+		t.Errorf("peer access supported by a platform not implementing PeerAccessPlatform")
+	}
+	if err := platform.EnablePeerAccess(mock, dev0, dev1); err == nil {
+		t.Errorf("expected an error when enabling peer access on a platform not implementing PeerAccessPlatform")
+	}
+	plat := &peerPlatform{Platform: mock}
+	if !platform.CanAccessPeer(plat, dev0, dev1) {
+		t.Errorf("device 0 cannot access the memory of device 1")
+	}
+	if err := platform.EnablePeerAccess(plat, dev1, dev0); err == nil {
+		t.Errorf("expected an error when enabling peer access from device 1 to device 0")
+	}
+	if err := platform.EnablePeerAccess(plat, dev0, dev1); err != nil {
+		t.Fatal(err)
+	}
+	if !plat.enabled {
+		t.Errorf("peer access not enabled on the platform")
+	}
+}
@@ -0,0 +1,601 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cpu implements a platform storing arrays in host memory.
+//
+// The platform has a single device. Sending data to the device copies it
+// in Go memory. The platform is registered as "cpu".
+//
+// The platform provides a profiler recording transfers. Backends evaluating graphs
+// on the platform add kernel records to the same profiler.
+package cpu
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Name of the platform in the platform registry.
+const Name = "cpu"
+
+func init() {
+	platform.Register(Name, func(cfg platform.Config) (platform.Platform, error) {
+		return New(cfg)
+	})
+}
+
+// Platform is a host platform.
+type Platform struct {
+	life          platform.Lifecycle
+	cfg           platform.Config
+	dev           *Device
+	alloc         platform.Allocator
+	removePurging func()
+	profiler      platform.Collector
+}
+
+var (
+	_ platform.Platform         = (*Platform)(nil)
+	_ platform.Allocator        = (*Platform)(nil)
+	_ platform.IntDataTyper     = (*Platform)(nil)
+	_ platform.ProfilerPlatform = (*Platform)(nil)
+)
+
+// New returns a new CPU platform.
+// Setting the allocator to "pool" in the configuration recycles freed host buffers.
+// Setting it to "shapepool" only recycles buffers for allocations of the same shape.
+// Setting it to "heap" recycles freed buffers by size class (see platform.HeapAllocator).
+func New(cfg platform.Config) (*Platform, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	if !cfg.IsVisible(0) {
+		return nil, errors.Errorf("CPU platform has a single device with ordinal 0: got visible devices %v", cfg.VisibleDevices)
+	}
+	p := &Platform{cfg: cfg}
+	p.dev = &Device{plat: p, budget: platform.NewBudget(0, cfg.MemoryLimit)}
+	p.alloc = allocator{}
+	switch cfg.Allocator {
+	case "", "go":
+	case "pool":
+		p.alloc = p.pool(platform.NewPoolAllocator(p.alloc, 1<<30))
+	case "shapepool":
+		p.alloc = p.pool(platform.NewShapePoolAllocator(p.alloc, 1<<30))
+	case "heap":
+		heap := platform.NewHeapAllocator(1 << 30)
+		p.removePurging = platform.OnMemoryPressure(heap.PressureCallback())
+		p.alloc = heap
+	default:
+		return nil, errors.Errorf("unknown allocator %q: want \"go\", \"pool\", \"shapepool\" or \"heap\"", cfg.Allocator)
+	}
+	p.alloc = platform.RetryingAllocator{Allocator: p.alloc}
+	return p, nil
+}
+
+// pool purges the buffers retained by a pool when the memory is under pressure.
+func (p *Platform) pool(pool *platform.PoolAllocator) platform.Allocator {
+	p.removePurging = platform.OnMemoryPressure(pool.PressureCallback())
+	return pool
+}
+
+// Name of the platform.
+func (p *Platform) Name() string {
+	return Name
+}
+
+// Device returns the CPU device. The only valid ordinal is 0.
+func (p *Platform) Device(ordinal int) (platform.Device, error) {
+	if ordinal != 0 {
+		return nil, errors.Errorf("device %d out of range: CPU platform has a single device", ordinal)
+	}
+	return p.dev, nil
+}
+
+// CPU returns the CPU device.
+func (p *Platform) CPU() *Device {
+	return p.dev
+}
+
+// NumDevices returns 1.
+func (p *Platform) NumDevices() int {
+	return 1
+}
+
+// Devices returns the CPU device.
+func (p *Platform) Devices() ([]platform.Device, error) {
+	return []platform.Device{p.dev}, nil
+}
+
+// DefaultDevice returns the CPU device.
+func (p *Platform) DefaultDevice() (platform.Device, error) {
+	return platform.SelectDevice(p, p.cfg.DevicePolicy)
+}
+
+// IntDataType returns the data type used to represent dtype.Int.
+func (p *Platform) IntDataType() dtype.DataType {
+	return dtype.HostInt
+}
+
+// NewProfiler returns the profiler of the platform. Transfers are recorded by the platform
+// and backends evaluating graphs on the platform add kernel records (see Profiler).
+// All the profilers returned by this function share the same records:
+// only one of them can be started at a time.
+func (p *Platform) NewProfiler() (platform.Profiler, error) {
+	return &p.profiler, nil
+}
+
+// Profiler returns the collector storing the profile records of the platform.
+func (p *Platform) Profiler() *platform.Collector {
+	return &p.profiler
+}
+
+// profile starts measuring an activity of the device and returns a function to call
+// when the activity completes. Only successful activities are recorded.
+func (p *Platform) profile(kind platform.RecordKind, name string, bytes int64) func(error) {
+	if !p.profiler.Active() {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		if err != nil {
+			return
+		}
+		p.profiler.Add(platform.ProfileRecord{
+			Kind:   kind,
+			Name:   name,
+			Device: 0,
+			Start:  start,
+			End:    time.Now(),
+			Bytes:  bytes,
+		})
+	}
+}
+
+// Allocate a host buffer.
+// Buffers allocated with platform.Unified are also handles on the CPU device.
+func (p *Platform) Allocate(sh *shape.Shape, opts ...platform.AllocOption) (platform.HostBuffer, error) {
+	if err := p.life.Enter(); err != nil {
+		return nil, err
+	}
+	defer p.life.Exit()
+	done := platform.Observe(platform.AllocateOp, Name, -1, int64(sh.ByteSize()))
+	buf, err := p.alloc.Allocate(sh, opts...)
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	if platform.NewAllocOptions(opts...).Unified {
+		buf = &unifiedBuffer{HostBuffer: buf, dev: p.dev}
+	}
+	if platform.LeakDetectionEnabled() {
+		buf = platform.TrackHostBuffer(buf)
+	}
+	return buf, nil
+}
+
+// Close the platform. Pending sends complete before the host buffers retained
+// by the platform are released.
+func (p *Platform) Close() error {
+	return p.life.Close(p.release)
+}
+
+// Release the platform.
+//
+// Deprecated: use Close.
+func (p *Platform) Release() error {
+	return p.Close()
+}
+
+func (p *Platform) release() error {
+	if p.removePurging != nil {
+		p.removePurging()
+		p.removePurging = nil
+	}
+	if retrying, ok := p.alloc.(platform.RetryingAllocator); ok {
+		if pool, ok := retrying.Allocator.(interface{ Purge() }); ok {
+			pool.Purge()
+		}
+	}
+	return nil
+}
+
+// allocator allocates host buffers in Go memory.
+type allocator struct{}
+
+func (allocator) Allocate(sh *shape.Shape, opts ...platform.AllocOption) (platform.HostBuffer, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	options := platform.NewAllocOptions(opts...)
+	if options.Alignment == 0 {
+		options.Alignment = dtype.AlignOf(sh.DType)
+	}
+	data, err := platform.AlignedBytes(sh.ByteSize(), options)
+	if err != nil {
+		return nil, err
+	}
+	return platform.Borrow(data, sh)
+}
+
+// unifiedBuffer is a host buffer which is also a handle on the CPU device.
+type unifiedBuffer struct {
+	platform.HostBuffer
+	dev *Device
+}
+
+var _ platform.UnifiedBuffer = (*unifiedBuffer)(nil)
+
+func (b *unifiedBuffer) Device() platform.Device {
+	return b.dev
+}
+
+// ToDevice returns the buffer itself for the CPU device without copying.
+func (b *unifiedBuffer) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	if dev == platform.Device(b.dev) {
+		return b, nil
+	}
+	return b.HostBuffer.ToDevice(dev)
+}
+
+// Device is the CPU of the host.
+type Device struct {
+	plat   *Platform
+	budget *platform.Budget
+
+	mu sync.Mutex
+	// live are the handles allocated by the device and not freed yet.
+	live map[*Handle]struct{}
+}
+
+var (
+	_ platform.Device         = (*Device)(nil)
+	_ platform.ChunkedSender  = (*Device)(nil)
+	_ platform.MemoryReporter = (*Device)(nil)
+)
+
+// Platform owning the device.
+func (d *Device) Platform() platform.Platform {
+	return d.plat
+}
+
+// Send copies data into a new handle.
+func (d *Device) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	done := platform.Observe(platform.SendOp, Name, 0, int64(len(buf)))
+	profiled := d.plat.profile(platform.HostToDeviceRecord, "", int64(len(buf)))
+	handle, err := d.send(buf, sh)
+	profiled(err)
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	if platform.LeakDetectionEnabled() {
+		return platform.Track(handle), nil
+	}
+	return handle, nil
+}
+
+func (d *Device) send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	if err := d.plat.life.Enter(); err != nil {
+		return nil, err
+	}
+	defer d.plat.life.Exit()
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	if len(buf) != sh.ByteSize() {
+		return nil, errors.Wrapf(platform.ErrInvalidShape, "got %d bytes for shape %s: want %d bytes", len(buf), sh, sh.ByteSize())
+	}
+	if err := d.budget.Reserve(int64(len(buf)), ""); err != nil {
+		return nil, err
+	}
+	data, err := platform.AlignedBytes(len(buf), platform.NewAllocOptions(platform.Aligned(dtype.AlignOf(sh.DType))))
+	if err != nil {
+		d.budget.Release(int64(len(buf)))
+		return nil, err
+	}
+	copy(data, buf)
+	return d.track(&Handle{dev: d, shape: sh, data: data, reserved: int64(len(buf))}), nil
+}
+
+// track records a handle allocated by the device until it is freed.
+func (d *Device) track(h *Handle) *Handle {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.live == nil {
+		d.live = make(map[*Handle]struct{})
+	}
+	d.live[h] = struct{}{}
+	return h
+}
+
+func (d *Device) untrack(h *Handle) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.live, h)
+}
+
+// MemoryStats returns the memory reserved from the budget of the device
+// and the memory of the handles allocated by the device.
+func (d *Device) MemoryStats() platform.MemoryStats {
+	stats := platform.MemoryStats{
+		Allocated: d.budget.Used(),
+		Peak:      d.budget.Peak(),
+		Limit:     d.budget.Limit(),
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for h := range d.live {
+		stats.Handles = append(stats.Handles, platform.HandleMemory{Shape: h.shape, Label: h.Label(), Bytes: h.reserved})
+	}
+	return stats
+}
+
+// ResetPeakMemory resets the peak of the device budget.
+func (d *Device) ResetPeakMemory() {
+	d.budget.ResetPeak()
+}
+
+// NewUpload allocates an array to be assembled from chunks written concurrently.
+func (d *Device) NewUpload(sh *shape.Shape) (platform.Upload, error) {
+	if err := d.plat.life.Enter(); err != nil {
+		return nil, err
+	}
+	defer d.plat.life.Exit()
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	size := int64(sh.ByteSize())
+	if err := d.budget.Reserve(size, ""); err != nil {
+		return nil, err
+	}
+	data, err := platform.AlignedBytes(int(size), platform.NewAllocOptions(platform.Aligned(dtype.AlignOf(sh.DType))))
+	if err != nil {
+		d.budget.Release(size)
+		return nil, err
+	}
+	return &upload{handle: d.track(&Handle{dev: d, shape: sh, data: data, reserved: size})}, nil
+}
+
+// upload writes chunks directly into the memory of a handle.
+type upload struct {
+	handle *Handle
+	done   atomic.Bool
+}
+
+func (u *upload) WriteAt(p []byte, off int64) (int, error) {
+	if u.done.Load() {
+		return 0, errors.Errorf("cannot write to a finished upload")
+	}
+	data := u.handle.data
+	if off < 0 || off+int64(len(p)) > int64(len(data)) {
+		return 0, errors.Errorf("cannot write %d bytes at offset %d in an array of %d bytes", len(p), off, len(data))
+	}
+	return copy(data[off:], p), nil
+}
+
+func (u *upload) Finish() (platform.DeviceHandle, error) {
+	if u.done.Swap(true) {
+		return nil, errors.Errorf("upload already finished")
+	}
+	if platform.LeakDetectionEnabled() {
+		return platform.Track(u.handle), nil
+	}
+	return u.handle, nil
+}
+
+func (u *upload) Abort() {
+	if !u.done.Swap(true) {
+		u.handle.Free()
+	}
+}
+
+// Budget returns the memory budget of the device.
+func (d *Device) Budget() *platform.Budget {
+	return d.budget
+}
+
+// NewHandle returns a handle using data as storage without copying it.
+// The caller must not modify data after the call.
+func (d *Device) NewHandle(data []byte, sh *shape.Shape) (*Handle, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	if len(data) != sh.ByteSize() {
+		return nil, errors.Wrapf(platform.ErrInvalidShape, "got %d bytes for shape %s: want %d bytes", len(data), sh, sh.ByteSize())
+	}
+	return &Handle{dev: d, shape: sh, data: data}, nil
+}
+
+// Ordinal of the device.
+func (d *Device) Ordinal() int {
+	return 0
+}
+
+// Description of the device.
+func (d *Device) Description() *platform.Description {
+	return &platform.Description{
+		Kind:          platform.CPU,
+		Name:          runtime.GOARCH,
+		Ordinal:       0,
+		UnifiedMemory: true,
+		Attributes: map[string]string{
+			"os":       runtime.GOOS,
+			"num_cpus": fmt.Sprint(runtime.NumCPU()),
+		},
+	}
+}
+
+// HealthCheck always returns nil.
+func (d *Device) HealthCheck() error {
+	return nil
+}
+
+// Reset does nothing.
+func (d *Device) Reset() error {
+	return nil
+}
+
+// epoch of the CPU device clock.
+var epoch = time.Now()
+
+// Now returns the time elapsed since the package was initialized.
+func (d *Device) Now() (time.Duration, error) {
+	return time.Since(epoch), nil
+}
+
+// Handle is an array stored in host memory.
+type Handle struct {
+	platform.Labeled
+	dev   *Device
+	shape *shape.Shape
+	data  []byte
+	freed atomic.Bool
+
+	// reserved is the number of bytes reserved from the device budget.
+	reserved int64
+	// onFree is called when the handle is freed, if not nil.
+	onFree func()
+}
+
+var (
+	_ platform.DeviceHandle   = (*Handle)(nil)
+	_ platform.ChunkedFetcher = (*Handle)(nil)
+)
+
+// Shape of the array.
+func (h *Handle) Shape() *shape.Shape {
+	return h.shape
+}
+
+// Device storing the array.
+func (h *Handle) Device() platform.Device {
+	return h.dev
+}
+
+// Data returns the memory of the array. The data must not be modified.
+// Returns nil if the handle has been freed.
+func (h *Handle) Data() []byte {
+	if h.freed.Load() {
+		return nil
+	}
+	return h.data
+}
+
+// Write overwrites the content of the array with data.
+// Backends use it to store an output in the memory of a donated handle.
+func (h *Handle) Write(data []byte) error {
+	dst := h.Data()
+	if dst == nil {
+		return errors.Errorf("cannot write to a freed handle")
+	}
+	if len(data) != len(dst) {
+		return errors.Errorf("cannot write %d bytes to an array of %d bytes", len(data), len(dst))
+	}
+	copy(dst, data)
+	return nil
+}
+
+// ToDevice transfers the array to a device.
+func (h *Handle) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	data := h.Data()
+	if data == nil {
+		return nil, errors.Errorf("cannot transfer a freed handle")
+	}
+	return dev.Send(data, h.shape)
+}
+
+// ToHost copies the array into a host buffer.
+func (h *Handle) ToHost(dst platform.HostBuffer) error {
+	done := platform.Observe(platform.ToHostOp, Name, 0, int64(len(h.data)))
+	profiled := h.dev.plat.profile(platform.DeviceToHostRecord, h.Label(), int64(len(h.data)))
+	err := h.toHost(dst)
+	profiled(err)
+	done(err)
+	return err
+}
+
+func (h *Handle) toHost(dst platform.HostBuffer) error {
+	data := h.Data()
+	if data == nil {
+		return errors.Errorf("cannot transfer a freed handle")
+	}
+	if !dst.Shape().Equal(h.shape) {
+		return errors.Wrapf(platform.ErrInvalidShape, "cannot transfer an array of shape %s to a buffer of shape %s", h.shape, dst.Shape())
+	}
+	return platform.CopyToHostBuffer(dst, data)
+}
+
+// ReadAt copies the data of the array at byte offset off into p.
+func (h *Handle) ReadAt(p []byte, off int64) (int, error) {
+	data := h.Data()
+	if data == nil {
+		return 0, errors.Errorf("cannot transfer a freed handle")
+	}
+	if off < 0 || off > int64(len(data)) {
+		return 0, errors.Errorf("cannot read at offset %d in an array of %d bytes", off, len(data))
+	}
+	n := copy(p, data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// View returns a handle aliasing a contiguous sub-region of the array.
+// Only views selecting a range of the outermost axis, and full inner axes, alias the memory
+// of the array. Other views return an error wrapping platform.ErrViewUnsupported.
+func (h *Handle) View(offsets, sizes []int) (platform.DeviceHandle, error) {
+	sub, err := platform.ViewShape(h.shape, offsets, sizes)
+	if err != nil {
+		return nil, err
+	}
+	data := h.Data()
+	if data == nil {
+		return nil, errors.Errorf("cannot view a freed handle")
+	}
+	if len(sizes) == 0 || dtype.IsSubByte(h.shape.DType) || h.shape.IsBitPacked() || !h.shape.IsContiguous() ||
+		!slices.Equal(sizes[1:], h.shape.AxisLengths[1:]) {
+		return nil, errors.Wrapf(platform.ErrViewUnsupported, "view %v+%v of shape %s", offsets, sizes, h.shape)
+	}
+	rowSize := shape.Size(h.shape.AxisLengths[1:]) * dtype.Sizeof(h.shape.DType)
+	start, end := offsets[0]*rowSize, (offsets[0]+sizes[0])*rowSize
+	return &Handle{dev: h.dev, shape: sub, data: data[start:end:end]}, nil
+}
+
+// Free drops the reference to the memory of the array.
+func (h *Handle) Free() {
+	if h.freed.Swap(true) {
+		return
+	}
+	h.dev.budget.Release(h.reserved)
+	h.dev.untrack(h)
+	if h.onFree != nil {
+		h.onFree()
+	}
+}
+
+// String returns a description of the handle.
+func (h *Handle) String() string {
+	return fmt.Sprintf("CPUHandle(%s)", h.shape)
+}
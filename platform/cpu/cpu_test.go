@@ -0,0 +1,392 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/shape"
+)
+
+func fetch[T dtype.GoDataType](t *testing.T, plat platform.Allocator, h platform.Handle) []T {
+	t.Helper()
+	buf, err := plat.Allocate(h.Shape())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ToHost(buf); err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Release()
+	return slices.Clone(dtype.ToSlice[T](buf.Acquire()))
+}
+
+func TestSendToHost(t *testing.T) {
+	plat, err := platform.New(cpu.Name, platform.WithAllocator("pool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := []float32{1, 2, 3, 4, 5, 6}
+	handle, err := dev.Send(dtype.FromSlice(src), shape.Matrix(dtype.Float32, 3, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src[0] = 42
+	alloc := plat.(platform.Allocator)
+	if got, want := fetch[float32](t, alloc, handle), []float32{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	view, err := platform.View(handle, []int{1, 0}, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fetch[float32](t, alloc, view), []float32{3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("view: got %v but want %v", got, want)
+	}
+	col, err := platform.View(handle, []int{0, 1}, []int{3, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fetch[float32](t, alloc, col), []float32{2, 4, 6}; !slices.Equal(got, want) {
+		t.Errorf("column view: got %v but want %v", got, want)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  platform.Option
+		err  string
+	}{
+		{
+			name: "unknown allocator",
+			opt:  platform.WithAllocator("arena"),
+			err:  `unknown allocator "arena"`,
+		},
+		{
+			name: "negative memory limit",
+			opt:  platform.WithMemoryLimit(-16),
+			err:  "negative memory limit -16",
+		},
+		{
+			name: "invalid memory fraction",
+			opt:  platform.WithMemoryFraction(2),
+			err:  "memory fraction 2 not in (0, 1]",
+		},
+		{
+			name: "no visible device",
+			opt:  platform.WithVisibleDevices(1),
+			err:  "CPU platform has a single device with ordinal 0",
+		},
+		{
+			name: "duplicate visible device",
+			opt:  platform.WithVisibleDevices(0, 0),
+			err:  "visible device 0 listed more than once",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plat, err := cpu.New(platform.NewConfig(test.opt))
+			if err == nil {
+				plat.Close()
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %q but want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	plat, err := cpu.New(platform.NewConfig(platform.WithMemoryLimit(16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Vector(dtype.Float32, 3)
+	first, err := plat.CPU().Send(make([]byte, 12), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = plat.CPU().Send(make([]byte, 12), sh)
+	if !errors.Is(err, platform.ErrOutOfMemory) {
+		t.Fatalf("got error %v but want %v", err, platform.ErrOutOfMemory)
+	}
+	first.Free()
+	if _, err := plat.CPU().Send(make([]byte, 12), sh); err != nil {
+		t.Errorf("send after free: %v", err)
+	}
+}
+
+func TestParallelTransfer(t *testing.T) {
+	plat, err := cpu.New(platform.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	src := make([]int32, 1000)
+	for i := range src {
+		src[i] = int32(i)
+	}
+	sh := shape.Vector(dtype.Int32, len(src))
+	opts := platform.ParallelOptions{ChunkBytes: 300, Streams: 3}
+	handle, err := platform.SendParallel(plat.CPU(), dtype.FromSlice(src), sh, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handle.Free()
+	buf, err := plat.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	if err := platform.ToHostParallel(handle, buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	got := slices.Clone(dtype.ToSlice[int32](buf.AcquireRead()))
+	buf.ReleaseRead()
+	if !slices.Equal(got, src) {
+		t.Errorf("got %v but want %v", got[:10], src[:10])
+	}
+	if _, err := platform.SendParallel(plat.CPU(), make([]byte, 3000), sh, opts); !errors.Is(err, platform.ErrInvalidShape) {
+		t.Errorf("got error %v but want %v", err, platform.ErrInvalidShape)
+	}
+}
+
+func TestMemoryStats(t *testing.T) {
+	plat, err := cpu.New(platform.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	dev := plat.CPU()
+	sh := shape.Vector(dtype.Float32, 4)
+	first, err := dev.Send(make([]byte, 16), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := dev.Send(make([]byte, 16), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, ok := platform.DeviceMemoryStats(dev)
+	if !ok {
+		t.Fatalf("device %T does not report its memory", dev)
+	}
+	if stats.Allocated != 32 || stats.Peak != 32 || len(stats.Handles) != 2 {
+		t.Errorf("got %+v but want 32 bytes allocated by 2 handles", stats)
+	}
+	first.Free()
+	second.Free()
+	stats = dev.MemoryStats()
+	if stats.Allocated != 0 || stats.Peak != 32 || len(stats.Handles) != 0 {
+		t.Errorf("after free: got %+v but want a peak of 32 bytes and nothing allocated", stats)
+	}
+	dev.ResetPeakMemory()
+	if got := dev.MemoryStats().Peak; got != 0 {
+		t.Errorf("peak after reset: got %d but want 0", got)
+	}
+}
+
+func TestProfiler(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	prof, err := platform.NewProfiler(plat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Of(dtype.Float32, 4)
+	data := make([]byte, sh.ByteSize())
+	// Activities are not recorded before the profiler starts.
+	before, err := plat.CPU().Send(data, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before.Free()
+	if err := prof.Start(); err != nil {
+		t.Fatal(err)
+	}
+	handle, err := plat.CPU().Send(data, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handle.Free()
+	platform.SetLabel(handle, "weights")
+	fetch[float32](t, plat, handle)
+	if _, err := plat.CPU().Send(data[1:], sh); err == nil {
+		t.Errorf("expected an error when sending data of the wrong size")
+	}
+	records, err := prof.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []platform.ProfileRecord{
+		{Kind: platform.HostToDeviceRecord, Bytes: int64(len(data))},
+		{Kind: platform.DeviceToHostRecord, Name: "weights", Bytes: int64(len(data))},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records but want %d", len(records), len(want))
+	}
+	for i, rec := range records {
+		if rec.Kind != want[i].Kind || rec.Name != want[i].Name || rec.Bytes != want[i].Bytes || rec.Device != 0 || rec.End.Before(rec.Start) {
+			t.Errorf("record %d: got %+v", i, rec)
+		}
+	}
+}
+
+func TestProfilerSession(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	first, err := platform.NewProfiler(plat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := plat.NewProfiler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// Only one profiler of the platform can be started at a time.
+	if err := second.Start(); err == nil {
+		t.Errorf("expected an error when starting a second profiler")
+	}
+	if !plat.Profiler().Active() {
+		t.Errorf("platform collector not active while profiling")
+	}
+	sh := shape.Of(dtype.Int32, 3)
+	discarded, err := plat.CPU().Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	discarded.Free()
+	if _, err := second.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	// Stopping any profiler of the platform ends the session.
+	if records, err := first.Stop(); err == nil {
+		t.Errorf("got %d records from a profiler already stopped but want an error", len(records))
+	}
+	if err := first.Start(); err != nil {
+		t.Fatal(err)
+	}
+	h, err := plat.CPU().Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Free()
+	platform.SetLabel(h, "weights")
+	fetch[int32](t, plat, h)
+	records, err := first.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The records of the previous session have been discarded.
+	want := []platform.ProfileRecord{
+		{Kind: platform.HostToDeviceRecord, Bytes: int64(sh.ByteSize())},
+		{Kind: platform.DeviceToHostRecord, Name: "weights", Bytes: int64(sh.ByteSize())},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records but want %d", len(records), len(want))
+	}
+	for i, rec := range records {
+		if rec.Kind != want[i].Kind || rec.Name != want[i].Name || rec.Bytes != want[i].Bytes || rec.Device != 0 || rec.End.Before(rec.Start) {
+			t.Errorf("record %d: got %+v but want %+v", i, rec, want[i])
+		}
+	}
+	if plat.Profiler().Active() {
+		t.Errorf("platform collector active after the session")
+	}
+}
+
+func TestProfilerConcurrentTransfers(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	prof, err := plat.NewProfiler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prof.Start(); err != nil {
+		t.Fatal(err)
+	}
+	const numTransfers = 8
+	sh := shape.Of(dtype.Int32, 3)
+	var wg sync.WaitGroup
+	for i := range numTransfers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h, err := plat.CPU().Send(dtype.FromSlice([]int32{int32(i), 1, 2}), sh)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer h.Free()
+			platform.SetLabel(h, fmt.Sprintf("array%d", i))
+			fetch[int32](t, plat, h)
+		}()
+	}
+	wg.Wait()
+	records, err := prof.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sends int
+	var names []string
+	for _, rec := range records {
+		switch rec.Kind {
+		case platform.HostToDeviceRecord:
+			sends++
+		case platform.DeviceToHostRecord:
+			names = append(names, rec.Name)
+		default:
+			t.Errorf("unexpected record %+v", rec)
+		}
+		if rec.Bytes != int64(sh.ByteSize()) {
+			t.Errorf("record %+v: got %d bytes but want %d", rec, rec.Bytes, sh.ByteSize())
+		}
+	}
+	slices.Sort(names)
+	var want []string
+	for i := range numTransfers {
+		want = append(want, fmt.Sprintf("array%d", i))
+	}
+	if sends != numTransfers || !slices.Equal(names, want) {
+		t.Errorf("got %d host to device records and device to host records %v but want %d and %v", sends, names, numTransfers, want)
+	}
+}
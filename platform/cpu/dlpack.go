@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/gx-org/backend/dlpack"
+	"github.com/gx-org/backend/platform"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ dlpack.Exporter = (*Handle)(nil)
+	_ dlpack.Importer = (*Device)(nil)
+)
+
+// ExportDLPack returns a tensor sharing the memory of the handle.
+// The memory is pinned until the Deleter of the tensor is called.
+func (h *Handle) ExportDLPack() (*dlpack.Tensor, error) {
+	data := h.Data()
+	if data == nil {
+		return nil, errors.Errorf("cannot export a freed handle")
+	}
+	if h.shape.IsTiled() || h.shape.IsBitPacked() {
+		return nil, errors.Wrapf(dlpack.ErrUnsupported, "cannot export an array of shape %s", h.shape)
+	}
+	dt, err := dlpack.FromDType(h.shape.DType)
+	if err != nil {
+		return nil, err
+	}
+	t := &dlpack.Tensor{
+		Device: dlpack.Device{Type: dlpack.CPU},
+		DType:  dt,
+		Shape:  make([]int64, len(h.shape.AxisLengths)),
+	}
+	for i, length := range h.shape.AxisLengths {
+		t.Shape[i] = int64(length)
+	}
+	if h.shape.Layout != nil {
+		for _, stride := range h.shape.Strides() {
+			t.Strides = append(t.Strides, int64(stride))
+		}
+	}
+	var pinner runtime.Pinner
+	if len(data) > 0 {
+		t.Data = unsafe.Pointer(&data[0])
+		pinner.Pin(t.Data)
+	}
+	t.Deleter = sync.OnceFunc(pinner.Unpin)
+	return t, nil
+}
+
+// ImportDLPack returns a handle sharing the memory of a tensor stored in host memory.
+// Only tensors stored in row-major order without gaps are supported.
+func (d *Device) ImportDLPack(t *dlpack.Tensor) (platform.DeviceHandle, error) {
+	switch t.Device.Type {
+	case dlpack.CPU, dlpack.CUDAHost, dlpack.ROCMHost:
+	default:
+		return nil, errors.Wrapf(dlpack.ErrUnsupported, "cannot import a tensor from device type %d", t.Device.Type)
+	}
+	sh, err := t.ArrayShape()
+	if err != nil {
+		return nil, err
+	}
+	if sh.Layout != nil {
+		return nil, errors.Wrapf(dlpack.ErrUnsupported, "cannot import a tensor of shape %s: strided tensors are not supported", sh)
+	}
+	var data []byte
+	if size := sh.ByteSize(); size > 0 {
+		data = unsafe.Slice((*byte)(unsafe.Add(t.Data, t.ByteOffset)), size)
+	}
+	handle, err := d.NewHandle(data, sh)
+	if err != nil {
+		return nil, err
+	}
+	handle.onFree = t.Deleter
+	return handle, nil
+}
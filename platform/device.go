@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "fmt"
+
+// DeviceKind is the kind of hardware of a device.
+type DeviceKind int
+
+// Kinds of devices.
+const (
+	UnknownDevice DeviceKind = iota
+	CPU
+	GPU
+	TPU
+)
+
+// String returns a string representation of the kind of device.
+func (k DeviceKind) String() string {
+	switch k {
+	case CPU:
+		return "CPU"
+	case GPU:
+		return "GPU"
+	case TPU:
+		return "TPU"
+	}
+	return "unknown"
+}
+
+// Description describes a device.
+type Description struct {
+	// Kind of hardware.
+	Kind DeviceKind
+
+	// Name of the device, as reported by its driver.
+	Name string
+
+	// Vendor of the device.
+	Vendor string
+
+	// Ordinal of the device on its platform.
+	Ordinal int
+
+	// TotalMemory is the total memory of the device, in bytes, or 0 if unknown.
+	TotalMemory int64
+
+	// UnifiedMemory is true if the device can allocate memory accessible by both
+	// the host and the device (see Unified).
+	UnifiedMemory bool
+
+	// Attributes are additional vendor-specific properties,
+	// such as the compute capability of a GPU.
+	Attributes map[string]string
+}
+
+// String returns a short description of the device.
+func (d *Description) String() string {
+	return fmt.Sprintf("%s:%d(%s)", d.Kind, d.Ordinal, d.Name)
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ErrHandleConsumed is returned when using a donated handle which has already been consumed.
+var ErrHandleConsumed = errors.New("donated handle already consumed")
+
+// DonatedHandle wraps a device handle which can be consumed by the next operation
+// using it as an input. Backends can then reuse the memory of the handle to store
+// outputs, which makes in-place state updates possible.
+//
+// The donated handle must not be used by the caller once donated.
+type DonatedHandle struct {
+	DeviceHandle
+	consumed atomic.Bool
+}
+
+// Donate marks a device handle as consumable by the next operation.
+func Donate(h DeviceHandle) *DonatedHandle {
+	if donated, ok := h.(*DonatedHandle); ok {
+		return donated
+	}
+	return &DonatedHandle{DeviceHandle: h}
+}
+
+// Consume returns the underlying handle such that its memory can be reused by the caller.
+// Only the first call succeeds. All subsequent calls return ErrHandleConsumed.
+func (h *DonatedHandle) Consume() (DeviceHandle, error) {
+	if h.consumed.Swap(true) {
+		return nil, ErrHandleConsumed
+	}
+	return h.DeviceHandle, nil
+}
+
+// Consumed returns true if the handle has been consumed.
+func (h *DonatedHandle) Consumed() bool {
+	return h.consumed.Load()
+}
+
+// SetLabel sets the label of the donated handle.
+func (h *DonatedHandle) SetLabel(label string) {
+	SetLabel(h.DeviceHandle, label)
+}
+
+// Label returns the label of the donated handle.
+func (h *DonatedHandle) Label() string {
+	return Label(h.DeviceHandle)
+}
+
+// String returns a description of the donated handle.
+func (h *DonatedHandle) String() string {
+	return fmt.Sprintf("Donated(%s)", h.DeviceHandle.Shape())
+}
+
+// IsDonated returns true if a handle has been donated and not consumed yet.
+func IsDonated(h Handle) bool {
+	donated, ok := h.(*DonatedHandle)
+	return ok && !donated.Consumed()
+}
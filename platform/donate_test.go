@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestDonate(t *testing.T) {
+	plat := platformtest.New(1)
+	h, err := plat.MockDevice(0).Send(dtype.FromSlice([]float32{1, 2}), shape.Of(dtype.Float32, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Free()
+	if platform.IsDonated(h) {
+		t.Errorf("handle reported as donated before being donated")
+	}
+	donated := platform.Donate(h)
+	if platform.Donate(donated) != donated {
+		t.Errorf("donating a donated handle returned a new handle")
+	}
+	if !platform.IsDonated(donated) {
+		t.Errorf("donated handle not reported as donated")
+	}
+	donated.SetLabel("weights")
+	if got := platform.Label(h); got != "weights" {
+		t.Errorf("got label %q on the underlying handle but want %q", got, "weights")
+	}
+	consumed, err := donated.Consume()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumed != h {
+		t.Errorf("Consume returned %v but want the underlying handle %v", consumed, h)
+	}
+	if !donated.Consumed() || platform.IsDonated(donated) {
+		t.Errorf("consumed handle still reported as donated")
+	}
+	// A donated handle can only be consumed once.
+	for range 2 {
+		if _, err := donated.Consume(); !errors.Is(err, platform.ErrHandleConsumed) {
+			t.Errorf("got error %v but want %v", err, platform.ErrHandleConsumed)
+		}
+	}
+}
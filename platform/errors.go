@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrOutOfMemory is returned when a device or the host runs out of memory.
+	// Use errors.As with *OutOfMemoryError to get the number of bytes requested.
+	ErrOutOfMemory = errors.New("out of memory")
+
+	// ErrUnsupportedDType is returned when a platform does not support a data type.
+	ErrUnsupportedDType = errors.New("unsupported data type")
+
+	// ErrDeviceLost is returned when a device is not reachable anymore.
+	// All the handles on the device are invalid.
+	ErrDeviceLost = errors.New("device lost")
+
+	// ErrInvalidShape is returned when a shape is invalid or does not match the data.
+	ErrInvalidShape = errors.New("invalid shape")
+)
+
+// OutOfMemoryError is returned when an allocation fails because not enough memory is available.
+type OutOfMemoryError struct {
+	// Device is the ordinal of the device or -1 for the host.
+	Device int
+	// Requested is the number of bytes requested.
+	Requested int64
+	// Available is the number of bytes available at the time of the request or -1 if unknown.
+	Available int64
+	// Label of the array being allocated, if any.
+	Label string
+}
+
+func (e *OutOfMemoryError) Error() string {
+	where := "host"
+	if e.Device >= 0 {
+		where = fmt.Sprintf("device %d", e.Device)
+	}
+	if e.Label != "" {
+		where += fmt.Sprintf(" for %q", e.Label)
+	}
+	if e.Available < 0 {
+		return fmt.Sprintf("out of memory on %s: requested %d bytes", where, e.Requested)
+	}
+	return fmt.Sprintf("out of memory on %s: requested %d bytes but only %d bytes available", where, e.Requested, e.Available)
+}
+
+// Is returns true if the target is ErrOutOfMemory.
+func (e *OutOfMemoryError) Is(target error) bool {
+	return target == ErrOutOfMemory
+}
+
+// IsRetryable returns true if an operation failing with err may succeed if tried again,
+// for example after memory has been freed.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrOutOfMemory)
+}
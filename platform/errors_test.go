@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	plat, err := cpu.New(platform.NewConfig(platform.WithMemoryLimit(16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	sh := shape.Of(dtype.Float32, 2, 2)
+	h, err := plat.CPU().Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Free()
+	sentinels := []error{
+		platform.ErrOutOfMemory,
+		platform.ErrUnsupportedDType,
+		platform.ErrDeviceLost,
+		platform.ErrInvalidShape,
+		platform.ErrViewUnsupported,
+		platform.ErrHandleConsumed,
+		platform.ErrClosed,
+	}
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{
+			name: "borrow size",
+			err:  func() error { _, err := platform.Borrow(make([]byte, 3), sh); return err },
+			want: platform.ErrInvalidShape,
+		},
+		{
+			name: "slice data type",
+			err:  func() error { _, err := platform.BufferFromSlice([]int32{1, 2, 3, 4}, sh); return err },
+			want: platform.ErrInvalidShape,
+		},
+		{
+			name: "send size",
+			err:  func() error { _, err := plat.CPU().Send(make([]byte, 4), sh); return err },
+			want: platform.ErrInvalidShape,
+		},
+		{
+			name: "send out of memory",
+			err:  func() error { _, err := plat.CPU().Send(make([]byte, sh.ByteSize()), sh); return err },
+			want: platform.ErrOutOfMemory,
+		},
+		{
+			name: "budget",
+			err:  func() error { return platform.NewBudget(0, 4).Reserve(8, "weights") },
+			want: platform.ErrOutOfMemory,
+		},
+		{
+			name: "view",
+			err:  func() error { _, err := h.(platform.Viewer).View([]int{0, 1}, []int{2, 1}); return err },
+			want: platform.ErrViewUnsupported,
+		},
+		{
+			name: "consumed",
+			err: func() error {
+				donated := platform.Donate(h)
+				donated.Consume()
+				_, err := donated.Consume()
+				return err
+			},
+			want: platform.ErrHandleConsumed,
+		},
+		{
+			name: "closed",
+			err: func() error {
+				var l platform.Lifecycle
+				l.Close(nil)
+				return l.Enter()
+			},
+			want: platform.ErrClosed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.err()
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			// Callers wrap platform errors with more context.
+			chains := map[string]error{
+				"error":         err,
+				"errors.Wrap":   errors.Wrap(err, "cannot run"),
+				"errors.Wrapf":  errors.Wrapf(errors.Wrap(err, "cannot transfer"), "step %d", 3),
+				"fmt.Errorf %w": fmt.Errorf("backend: %w", errors.WithMessage(err, "cannot run")),
+			}
+			for name, chain := range chains {
+				for _, sentinel := range sentinels {
+					if got, want := errors.Is(chain, sentinel), sentinel == test.want; got != want {
+						t.Errorf("%s: errors.Is(%q, %v) = %v but want %v", name, chain, sentinel, got, want)
+					}
+				}
+				if got, want := platform.IsRetryable(chain), test.want == platform.ErrOutOfMemory; got != want {
+					t.Errorf("%s: IsRetryable(%q) = %v but want %v", name, chain, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutOfMemoryError(t *testing.T) {
+	err := errors.Wrap(platform.NewBudget(1, 16).Reserve(32, "weights"), "cannot send")
+	var oom *platform.OutOfMemoryError
+	if !errors.As(err, &oom) {
+		t.Fatalf("cannot get an *OutOfMemoryError from %v", err)
+	}
+	if oom.Device != 1 || oom.Requested != 32 || oom.Available != 16 || oom.Label != "weights" {
+		t.Errorf("got %+v but want device 1, 32 bytes requested, 16 bytes available and label weights", *oom)
+	}
+	if got, want := err.Error(), `cannot send: out of memory on device 1 for "weights": requested 32 bytes but only 16 bytes available`; got != want {
+		t.Errorf("got %q but want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "sync"
+
+// Event marks a point in the sequence of operations of a stream.
+// An event completes when all the operations enqueued before it have completed.
+type Event interface {
+	// Query returns true if the event has completed without blocking.
+	// The error is the first error of the operations preceding the event, if any.
+	Query() (bool, error)
+
+	// Wait blocks the host until the event has completed.
+	Wait() error
+}
+
+// HostEvent is an event completed by the host.
+// It can be used by platforms to implement events, or by asynchronous
+// operations executed on the host.
+type HostEvent struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+var _ Event = (*HostEvent)(nil)
+
+// NewHostEvent returns a new event which has not completed.
+func NewHostEvent() *HostEvent {
+	return &HostEvent{done: make(chan struct{})}
+}
+
+// CompletedEvent returns an event which has already completed with a given error.
+func CompletedEvent(err error) *HostEvent {
+	ev := NewHostEvent()
+	ev.Complete(err)
+	return ev
+}
+
+// Complete marks the event as completed with a given error.
+// Only the first call has an effect.
+func (ev *HostEvent) Complete(err error) {
+	ev.once.Do(func() {
+		ev.err = err
+		close(ev.done)
+	})
+}
+
+// Done returns a channel closed when the event completes.
+func (ev *HostEvent) Done() <-chan struct{} {
+	return ev.done
+}
+
+// Query returns true if the event has completed.
+func (ev *HostEvent) Query() (bool, error) {
+	select {
+	case <-ev.done:
+		return true, ev.err
+	default:
+		return false, nil
+	}
+}
+
+// Wait blocks until the event has completed.
+func (ev *HostEvent) Wait() error {
+	<-ev.done
+	return ev.err
+}
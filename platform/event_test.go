@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/platform"
+)
+
+func TestHostEvent(t *testing.T) {
+	ev := platform.NewHostEvent()
+	if done, err := ev.Query(); done || err != nil {
+		t.Errorf("new event: got %v, %v but want false, nil", done, err)
+	}
+	want := errors.New("transfer failed")
+	go ev.Complete(want)
+	if err := ev.Wait(); err != want {
+		t.Errorf("got error %v but want %v", err, want)
+	}
+	ev.Complete(nil)
+	if done, err := ev.Query(); !done || err != want {
+		t.Errorf("completed event: got %v, %v but want true, %v", done, err, want)
+	}
+}
+
+func TestPendingHandle(t *testing.T) {
+	p := platform.NewPendingHandle()
+	if done, _ := p.Query(); done {
+		t.Fatalf("pending handle completed before being resolved")
+	}
+	want := errors.New("transfer failed")
+	go p.Resolve(nil, want)
+	if _, err := p.Handle(); err != want {
+		t.Errorf("got error %v but want %v", err, want)
+	}
+	p.Resolve(nil, nil)
+	if err := p.Wait(); err != want {
+		t.Errorf("second Resolve changed the error to %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// ExternalMemoryKind is the mechanism used to share device memory with another process or framework.
+type ExternalMemoryKind int
+
+const (
+	// UnknownExternalMemory is an invalid kind of external memory.
+	UnknownExternalMemory ExternalMemoryKind = iota
+	// CUDAIPC is a CUDA inter-process memory handle.
+	CUDAIPC
+	// DMABuf is a Linux dma-buf file descriptor.
+	DMABuf
+	// OpaqueFD is a platform-specific file descriptor.
+	OpaqueFD
+)
+
+func (k ExternalMemoryKind) String() string {
+	switch k {
+	case CUDAIPC:
+		return "cuda_ipc"
+	case DMABuf:
+		return "dma_buf"
+	case OpaqueFD:
+		return "opaque_fd"
+	}
+	return fmt.Sprintf("ExternalMemoryKind(%d)", int(k))
+}
+
+// ErrExternalMemoryUnsupported is returned when a kind of external memory is not supported.
+var ErrExternalMemoryUnsupported = errors.New("external memory not supported")
+
+// ExternalHandle is an OS-shareable reference to device memory.
+type ExternalHandle struct {
+	// Kind of the handle.
+	Kind ExternalMemoryKind
+	// Descriptor is the serialized OS handle, for example a cudaIpcMemHandle_t.
+	Descriptor []byte
+	// FD is the file descriptor of the memory for file-based kinds, or -1.
+	FD int
+	// Offset of the array in the shared memory, in bytes.
+	Offset int
+	// Shape of the array stored in the memory.
+	Shape *shape.Shape
+}
+
+type (
+	// Exporter is implemented by device handles which can be shared with other processes.
+	Exporter interface {
+		DeviceHandle
+
+		// Export returns an OS-shareable handle to the memory of the array.
+		// The memory remains owned by the device handle, which must outlive all importers.
+		Export(ExternalMemoryKind) (*ExternalHandle, error)
+	}
+
+	// Importer is implemented by devices which can access memory exported by other processes.
+	Importer interface {
+		Device
+
+		// Import returns a device handle aliasing the memory of an external handle without copying it.
+		Import(*ExternalHandle) (DeviceHandle, error)
+	}
+)
+
+// Export returns an OS-shareable handle to the memory of a device handle.
+// It returns ErrExternalMemoryUnsupported if the handle does not implement Exporter.
+func Export(h DeviceHandle, kind ExternalMemoryKind) (*ExternalHandle, error) {
+	exporter, ok := h.(Exporter)
+	if !ok {
+		return nil, errors.Wrapf(ErrExternalMemoryUnsupported, "cannot export %s", kind)
+	}
+	return exporter.Export(kind)
+}
+
+// Import returns a device handle aliasing memory exported by another process or framework.
+// It returns ErrExternalMemoryUnsupported if the device does not implement Importer.
+func Import(dev Device, ext *ExternalHandle) (DeviceHandle, error) {
+	if ext.Shape == nil {
+		return nil, errors.Errorf("cannot import an external handle without shape")
+	}
+	if err := ext.Shape.Check(); err != nil {
+		return nil, errors.Errorf("cannot import an external handle: %v", err)
+	}
+	importer, ok := dev.(Importer)
+	if !ok {
+		return nil, errors.Wrapf(ErrExternalMemoryUnsupported, "cannot import %s on device %d", ext.Kind, dev.Ordinal())
+	}
+	return importer.Import(ext)
+}
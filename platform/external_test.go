@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// exportHandle is a device handle exported as an opaque file descriptor.
+type exportHandle struct {
+	*platformtest.Handle
+}
+
+func (h exportHandle) Export(kind platform.ExternalMemoryKind) (*platform.ExternalHandle, error) {
+	if kind != platform.OpaqueFD {
+		return nil, platform.ErrExternalMemoryUnsupported
+	}
+	return &platform.ExternalHandle{Kind: kind, FD: 3, Shape: h.Shape()}, nil
+}
+
+// importDevice is a device importing external handles as new arrays.
+type importDevice struct {
+	*platformtest.Device
+}
+
+func (d importDevice) Import(ext *platform.ExternalHandle) (platform.DeviceHandle, error) {
+	return d.Send(make([]byte, ext.Shape.ByteSize()), ext.Shape)
+}
+
+func TestExport(t *testing.T) {
+	plat := platformtest.New(1)
+	sh := shape.Of(dtype.Float32, 2, 3)
+	h, err := plat.MockDevice(0).Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Free()
+	tests := []struct {
+		name    string
+		handle  platform.DeviceHandle
+		kind    platform.ExternalMemoryKind
+		wantErr error
+	}{
+		{name: "not an exporter", handle: h, kind: platform.OpaqueFD, wantErr: platform.ErrExternalMemoryUnsupported},
+		{name: "unsupported kind", handle: exportHandle{h.(*platformtest.Handle)}, kind: platform.CUDAIPC, wantErr: platform.ErrExternalMemoryUnsupported},
+		{name: "exported", handle: exportHandle{h.(*platformtest.Handle)}, kind: platform.OpaqueFD},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ext, err := platform.Export(test.handle, test.kind)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v but want %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ext.Kind != test.kind || !ext.Shape.Equal(sh) {
+				t.Errorf("got external handle of kind %s and shape %s but want %s and %s", ext.Kind, ext.Shape, test.kind, sh)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	sh := shape.Of(dtype.Int32, 4)
+	tests := []struct {
+		name    string
+		dev     platform.Device
+		ext     *platform.ExternalHandle
+		wantErr bool
+	}{
+		{name: "no shape", dev: importDevice{dev}, ext: &platform.ExternalHandle{Kind: platform.DMABuf}, wantErr: true},
+		{name: "invalid shape", dev: importDevice{dev}, ext: &platform.ExternalHandle{Kind: platform.DMABuf, Shape: shape.Of(dtype.Int32, -1)}, wantErr: true},
+		{name: "not an importer", dev: dev, ext: &platform.ExternalHandle{Kind: platform.DMABuf, Shape: sh}, wantErr: true},
+		{name: "imported", dev: importDevice{dev}, ext: &platform.ExternalHandle{Kind: platform.DMABuf, Shape: sh}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := platform.Import(test.dev, test.ext)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Free()
+			if !h.Shape().Equal(sh) {
+				t.Errorf("got shape %s but want %s", h.Shape(), sh)
+			}
+		})
+	}
+	if _, err := platform.Import(dev, &platform.ExternalHandle{Shape: sh}); !errors.Is(err, platform.ErrExternalMemoryUnsupported) {
+		t.Errorf("got error %v but want %v", err, platform.ErrExternalMemoryUnsupported)
+	}
+}
+
+func TestExternalMemoryKindString(t *testing.T) {
+	tests := []struct {
+		kind platform.ExternalMemoryKind
+		want string
+	}{
+		{kind: platform.CUDAIPC, want: "cuda_ipc"},
+		{kind: platform.DMABuf, want: "dma_buf"},
+		{kind: platform.OpaqueFD, want: "opaque_fd"},
+		{kind: platform.UnknownExternalMemory, want: "ExternalMemoryKind(0)"},
+	}
+	for _, test := range tests {
+		if got := test.kind.String(); got != test.want {
+			t.Errorf("got %q but want %q", got, test.want)
+		}
+	}
+}
@@ -15,8 +15,11 @@
 package platform
 
 import (
-	"github.com/pkg/errors"
+	"reflect"
+	"runtime"
+
 	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -38,41 +41,137 @@ type (
 
 		// Device on which the array is located.
 		Device() Device
+
+		// Free the device memory of the array. The handle is invalid after calling this function.
+		// Platforms may also release the memory when the handle is garbage collected.
+		Free()
+	}
+
+	// PhysicalShaper is implemented by device handles able to report how their data
+	// is physically stored on the device, for example with a tiled layout.
+	PhysicalShaper interface {
+		// PhysicalShape returns the shape of the array including its physical layout.
+		// The returned shape is equal to Shape() ignoring the layout.
+		PhysicalShape() *shape.Shape
 	}
 
 	// HostBuffer is a handle to a buffer of data located locally on the platform,
 	// typically in CPU memory, and shared between a platform and its users.
 	//
-	// The data needs to be acquired before being written or read. All access to the data
-	// is blocked until that is released.
+	// The data needs to be acquired before being written or read. The buffer follows
+	// reader-writer semantics: many goroutines can read the data concurrently
+	// while writers have exclusive access.
 	HostBuffer interface {
 		Handle
-		// Acquire locks the buffer and returns it.
+		// Acquire locks the buffer for writing and returns it.
 		// The buffer can be read or written by the caller. All other access is locked.
 		// Returns nil if the handle has been freed.
 		Acquire() []byte
-		// Release the buffer. The caller of that function should not read or write data
-		// from the buffer.
+		// Release the buffer acquired by Acquire. The caller of that function should
+		// not read or write data from the buffer.
 		Release()
+		// AcquireRead locks the buffer for reading and returns it.
+		// The buffer must not be written by the caller. Other readers can access
+		// the buffer concurrently while writers are locked.
+		// Returns nil if the handle has been freed.
+		AcquireRead() []byte
+		// ReleaseRead releases the buffer acquired by AcquireRead.
+		ReleaseRead()
 		// Free the memory occupied by the buffer. The handle is invalid after calling this function.
 		Free()
 	}
 
 	// Allocator allocates memory on the host.
 	Allocator interface {
-		Allocate(*shape.Shape) (HostBuffer, error)
+		// Allocate returns a host buffer to store an array of the given shape.
+		// The memory of the buffer must be aligned as specified by dtype.AlignOf
+		// for the data type of the shape, such that it can be safely reinterpreted
+		// using dtype.ToSlice, or as specified by the options if larger.
+		// The buffer stores the elements with the layout of the shape: its size is sh.ByteSize().
+		Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error)
 	}
 )
 
+// ErrBufferFreed is returned when accessing a host buffer which has been freed.
+var ErrBufferFreed = errors.New("host buffer freed")
+
+const (
+	// parallelCopyThreshold is the size, in bytes, above which buffers are copied in parallel.
+	parallelCopyThreshold = 64 << 20
+	// copyChunkSize is the size of the chunks copied in parallel.
+	copyChunkSize = 16 << 20
+)
+
 // HostTransfer transfers data from a source host buffer to another.
+// Both buffers must have the same shape. Large buffers are copied in parallel.
 func HostTransfer(dstB, srcB HostBuffer) error {
-	src := srcB.Acquire()
-	defer srcB.Release()
+	srcShape, dstShape := srcB.Shape(), dstB.Shape()
+	if err := srcShape.Check(); err != nil {
+		return errors.Wrapf(ErrInvalidShape, "cannot transfer data from source buffer: %v", err)
+	}
+	if err := dstShape.Check(); err != nil {
+		return errors.Wrapf(ErrInvalidShape, "cannot transfer data to destination buffer: %v", err)
+	}
+	if !srcShape.Equal(dstShape) {
+		return errors.Wrapf(ErrInvalidShape, "cannot transfer data from a buffer of shape %s to a buffer of shape %s", srcShape, dstShape)
+	}
+	if sameBuffer(dstB, srcB) {
+		// Acquiring the same buffer for reading and writing would deadlock.
+		return nil
+	}
+	src := srcB.AcquireRead()
+	defer srcB.ReleaseRead()
+	if src == nil {
+		return errors.Wrap(ErrBufferFreed, "cannot transfer data from source buffer")
+	}
+	dst := dstB.Acquire()
+	defer dstB.Release()
+	if dst == nil {
+		return errors.Wrap(ErrBufferFreed, "cannot transfer data to destination buffer")
+	}
+	return copyBytes(dst, src)
+}
+
+// sameBuffer returns true if x and y are the same buffer.
+// Buffers of types which cannot be compared are never the same, such that
+// comparing them does not panic.
+func sameBuffer(x, y HostBuffer) bool {
+	tx := reflect.TypeOf(x)
+	return tx == reflect.TypeOf(y) && tx.Comparable() && x == y
+}
+
+// CopyToHostBuffer copies raw data into a host buffer.
+func CopyToHostBuffer(dstB HostBuffer, src []byte) error {
 	dst := dstB.Acquire()
 	defer dstB.Release()
+	if dst == nil {
+		return errors.Wrap(ErrBufferFreed, "cannot copy data to host buffer")
+	}
+	return copyBytes(dst, src)
+}
+
+// CopyFromHostBuffer copies the data of a host buffer into a raw slice.
+func CopyFromHostBuffer(dst []byte, srcB HostBuffer) error {
+	src := srcB.AcquireRead()
+	defer srcB.ReleaseRead()
+	if src == nil {
+		return errors.Wrap(ErrBufferFreed, "cannot copy data from host buffer")
+	}
+	return copyBytes(dst, src)
+}
+
+// copyBytes copies src into dst, using multiple goroutines for large slices.
+func copyBytes(dst, src []byte) error {
 	if len(src) != len(dst) {
-		return errors.Errorf("cannot transfer data from a buffer of size %d to a buffer of size %d", len(src), len(dst))
+		return errors.Wrapf(ErrInvalidShape, "cannot copy data from a buffer of size %d to a buffer of size %d", len(src), len(dst))
+	}
+	if len(src) < parallelCopyThreshold {
+		copy(dst, src)
+		return nil
 	}
-	copy(src, dst)
-	return nil
+	opts := ParallelOptions{ChunkBytes: copyChunkSize, Streams: runtime.GOMAXPROCS(0)}
+	return inChunks(len(src), opts, func(start, end int) error {
+		copy(dst[start:end], src[start:end])
+		return nil
+	})
 }
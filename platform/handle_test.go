@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+func borrow(t *testing.T, data []byte, sh *shape.Shape) platform.HostBuffer {
+	t.Helper()
+	buf, err := platform.Borrow(data, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestHostTransfer(t *testing.T) {
+	sh := shape.Vector(dtype.Float32, 3)
+	want := dtype.FromSlice([]float32{1, 2, 3})
+	src := borrow(t, bytes.Clone(want), sh)
+	dstData := make([]byte, len(want))
+	dst := borrow(t, dstData, sh)
+	if err := platform.HostTransfer(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dstData, want) {
+		t.Errorf("got %v but want %v", dstData, want)
+	}
+	if err := platform.HostTransfer(src, src); err != nil {
+		t.Errorf("transfer to the same buffer: %v", err)
+	}
+	other := borrow(t, make([]byte, len(want)), shape.Vector(dtype.Int32, 3))
+	if err := platform.HostTransfer(other, src); !errors.Is(err, platform.ErrInvalidShape) {
+		t.Errorf("got error %v but want %v", err, platform.ErrInvalidShape)
+	}
+}
+
+// valueBuffer is a host buffer of a type which cannot be compared.
+type valueBuffer struct {
+	shape *shape.Shape
+	data  []byte
+}
+
+func (b valueBuffer) Shape() *shape.Shape { return b.shape }
+func (b valueBuffer) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	return dev.Send(b.data, b.shape)
+}
+func (b valueBuffer) ToHost(dst platform.HostBuffer) error { return platform.HostTransfer(dst, b) }
+func (b valueBuffer) Acquire() []byte                      { return b.data }
+func (b valueBuffer) Release()                             {}
+func (b valueBuffer) AcquireRead() []byte                  { return b.data }
+func (b valueBuffer) ReleaseRead()                         {}
+func (b valueBuffer) Free()                                {}
+
+func TestHostTransferValueBuffers(t *testing.T) {
+	sh := shape.Vector(dtype.Uint8, 3)
+	src := valueBuffer{shape: sh, data: []byte{1, 2, 3}}
+	dst := valueBuffer{shape: sh, data: make([]byte, 3)}
+	if err := platform.HostTransfer(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dst.data, src.data) {
+		t.Errorf("got %v but want %v", dst.data, src.data)
+	}
+}
+
+func TestHostTransferLarge(t *testing.T) {
+	const n = 80 << 20
+	sh := shape.Vector(dtype.Uint32, n/4)
+	srcData := make([]byte, n)
+	for i := range srcData {
+		srcData[i] = byte(i * 7)
+	}
+	dstData := make([]byte, n)
+	if err := platform.HostTransfer(borrow(t, dstData, sh), borrow(t, srcData, sh)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dstData, srcData) {
+		t.Errorf("parallel copy does not match the source")
+	}
+	raw := make([]byte, n)
+	if err := platform.CopyFromHostBuffer(raw, borrow(t, srcData, sh)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, srcData) {
+		t.Errorf("CopyFromHostBuffer does not match the source")
+	}
+}
+
+func TestAcquireAs(t *testing.T) {
+	sh := shape.Vector(dtype.Float32, 3)
+	buf := borrow(t, dtype.CopyFromSlice([]float32{1, 2, 3}), sh)
+	vals, release, err := platform.AcquireAs[float32](buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals[0] = 42
+	release()
+	release()
+	got, releaseRead, err := platform.AcquireReadAs[float32](buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != 42 || len(got) != 3 {
+		t.Errorf("got %v but want [42 2 3]", got)
+	}
+	releaseRead()
+	if _, _, err := platform.AcquireAs[int32](buf); !errors.Is(err, platform.ErrInvalidShape) {
+		t.Errorf("got error %v but want %v", err, platform.ErrInvalidShape)
+	}
+	buf.Free()
+	if _, _, err := platform.AcquireReadAs[float32](buf); !errors.Is(err, platform.ErrBufferFreed) {
+		t.Errorf("got error %v but want %v", err, platform.ErrBufferFreed)
+	}
+}
+
+func TestBufferFromSlice(t *testing.T) {
+	vals := []int32{1, 2, 3}
+	buf, err := platform.BufferFromSlice(vals, shape.Vector(dtype.Int32, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, release, err := platform.AcquireAs[int32](buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got[1] = 42
+	release()
+	if vals[1] != 42 {
+		t.Errorf("got %v: write not visible in the slice", vals)
+	}
+	if _, err := platform.BufferFromSlice(vals, shape.Vector(dtype.Int32, 4)); !errors.Is(err, platform.ErrInvalidShape) {
+		t.Errorf("got error %v but want %v", err, platform.ErrInvalidShape)
+	}
+	if _, err := platform.BufferFromSlice(vals, shape.Vector(dtype.Float32, 3)); !errors.Is(err, platform.ErrInvalidShape) {
+		t.Errorf("got error %v but want %v", err, platform.ErrInvalidShape)
+	}
+	buf.Free()
+	if data := buf.AcquireRead(); data != nil {
+		t.Errorf("got %d bytes from a freed buffer", len(data))
+	}
+	buf.ReleaseRead()
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"math/bits"
+	"sync"
+	"unsafe"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+type (
+	// HeapAllocator allocates host buffers in Go memory and recycles freed buffers
+	// by size class: the memory of a buffer is rounded up to a power of two bytes,
+	// such that a freed buffer can serve any later allocation of the same class.
+	// Recycled buffers are not cleared. Pinned and unified memory are not supported.
+	// A HeapAllocator is safe for concurrent use.
+	HeapAllocator struct {
+		maxRetained int
+
+		mu       sync.Mutex
+		free     [bits.UintSize][][]byte
+		retained int
+		stats    PoolStats
+	}
+
+	// heapBuffer is a host buffer returning its memory to a heap allocator when freed.
+	heapBuffer struct {
+		bytesBuffer
+		alloc *HeapAllocator
+		raw   []byte
+	}
+)
+
+var _ Allocator = (*HeapAllocator)(nil)
+
+// NewHeapAllocator returns an allocator of Go memory retaining at most maxRetained bytes
+// of freed buffers. Buffers freed when the allocator is full are left to the garbage collector.
+func NewHeapAllocator(maxRetained int) *HeapAllocator {
+	return &HeapAllocator{maxRetained: maxRetained}
+}
+
+// sizeClass returns the size class of an allocation of n bytes.
+func sizeClass(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Allocate returns a buffer of the size class of the shape, recycled if one is available.
+func (a *HeapAllocator) Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(ErrInvalidShape, err.Error())
+	}
+	options := NewAllocOptions(opts...)
+	if err := options.Check(); err != nil {
+		return nil, err
+	}
+	align := max(options.Alignment, dtype.AlignOf(sh.DType), 1)
+	size := sh.ByteSize()
+	class := sizeClass(size + options.Padding + align - 1)
+	raw := a.take(class)
+	if raw == nil {
+		raw = make([]byte, 1<<class)
+	}
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(unsafe.SliceData(raw))) % uintptr(align)); rem != 0 {
+		offset = align - rem
+	}
+	data := raw[offset : offset+size : offset+size+options.Padding]
+	return &heapBuffer{bytesBuffer: bytesBuffer{shape: sh, data: data}, alloc: a, raw: raw}, nil
+}
+
+func (a *HeapAllocator) take(class int) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bufs := a.free[class]
+	if len(bufs) == 0 {
+		a.stats.Misses++
+		return nil
+	}
+	raw := bufs[len(bufs)-1]
+	a.free[class] = bufs[:len(bufs)-1]
+	a.retained -= len(raw)
+	a.stats.Hits++
+	return raw
+}
+
+func (a *HeapAllocator) put(raw []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.retained+len(raw) > a.maxRetained {
+		return
+	}
+	class := sizeClass(len(raw))
+	a.free[class] = append(a.free[class], raw)
+	a.retained += len(raw)
+}
+
+// Stats returns statistics about the buffers recycled by the allocator.
+func (a *HeapAllocator) Stats() PoolStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.stats
+	stats.RetainedBytes = a.retained
+	return stats
+}
+
+// Purge releases all the buffers retained by the allocator to the garbage collector.
+func (a *HeapAllocator) Purge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.free = [bits.UintSize][][]byte{}
+	a.retained = 0
+}
+
+// Free returns the memory of the buffer to the allocator.
+func (b *heapBuffer) Free() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.data == nil {
+		return
+	}
+	b.data = nil
+	b.alloc.put(b.raw)
+	b.raw = nil
+}
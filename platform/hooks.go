@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// HookOp is the operation reported to hooks.
+type HookOp int
+
+const (
+	// SendOp is a transfer from the host to a device.
+	SendOp HookOp = iota
+	// ToHostOp is a transfer from a device to the host.
+	ToHostOp
+	// AllocateOp is an allocation of memory.
+	AllocateOp
+	// FreeOp is a release of memory.
+	FreeOp
+	// RunOp is the execution of a compiled computation.
+	RunOp
+)
+
+func (op HookOp) String() string {
+	switch op {
+	case SendOp:
+		return "send"
+	case ToHostOp:
+		return "to_host"
+	case AllocateOp:
+		return "allocate"
+	case FreeOp:
+		return "free"
+	case RunOp:
+		return "run"
+	}
+	return fmt.Sprintf("HookOp(%d)", int(op))
+}
+
+// HookEvent describes an operation which has completed.
+type HookEvent struct {
+	// Op is the operation.
+	Op HookOp
+	// Platform is the name of the platform executing the operation.
+	Platform string
+	// Device is the ordinal of the device or -1 for the host.
+	Device int
+	// Bytes moved, allocated or freed by the operation.
+	Bytes int64
+	// Duration of the operation.
+	Duration time.Duration
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// Hook is called when an operation completes.
+// Hooks are called synchronously and must return quickly.
+type Hook func(HookEvent)
+
+var hooks struct {
+	mu     sync.Mutex
+	nextID int
+	funcs  atomic.Pointer[map[int]Hook]
+}
+
+// AddHook registers a hook and returns a function to remove it.
+func AddHook(hook Hook) (remove func()) {
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	id := hooks.nextID
+	hooks.nextID++
+	updateHooks(func(m map[int]Hook) { m[id] = hook })
+	return func() {
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		updateHooks(func(m map[int]Hook) { delete(m, id) })
+	}
+}
+
+// updateHooks copies the map of hooks, updates it, and stores it.
+// Must be called with hooks.mu held.
+func updateHooks(update func(map[int]Hook)) {
+	next := make(map[int]Hook)
+	if current := hooks.funcs.Load(); current != nil {
+		for id, hook := range *current {
+			next[id] = hook
+		}
+	}
+	update(next)
+	hooks.funcs.Store(&next)
+}
+
+// HasHooks returns true if at least one hook is registered.
+// Platforms can use it to avoid measuring operations when nobody is listening.
+func HasHooks() bool {
+	current := hooks.funcs.Load()
+	return current != nil && len(*current) > 0
+}
+
+// NotifyHooks calls all the registered hooks with an event.
+func NotifyHooks(ev HookEvent) {
+	current := hooks.funcs.Load()
+	if current == nil {
+		return
+	}
+	for _, hook := range *current {
+		hook(ev)
+	}
+}
+
+// Observe starts measuring an operation and returns a function to call when the operation completes.
+// Calling the returned function notifies the hooks with the duration of the operation.
+func Observe(op HookOp, platform string, device int, bytes int64) func(error) {
+	if !HasHooks() {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		NotifyHooks(HookEvent{
+			Op:       op,
+			Platform: platform,
+			Device:   device,
+			Bytes:    bytes,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+}
+
+// ObservedAllocator notifies the hooks of the allocations and releases of an allocator.
+type ObservedAllocator struct {
+	Allocator
+}
+
+var _ Allocator = ObservedAllocator{}
+
+// Allocate a host buffer and notify the hooks.
+func (a ObservedAllocator) Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error) {
+	done := Observe(AllocateOp, "", -1, int64(sh.ByteSize()))
+	buf, err := a.Allocator.Allocate(sh, opts...)
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+	return &observedBuffer{HostBuffer: buf}, nil
+}
+
+type observedBuffer struct {
+	HostBuffer
+}
+
+func (b *observedBuffer) Free() {
+	done := Observe(FreeOp, "", -1, int64(b.Shape().ByteSize()))
+	b.HostBuffer.Free()
+	done(nil)
+}
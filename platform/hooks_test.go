@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestAddHook(t *testing.T) {
+	if platform.HasHooks() {
+		t.Fatalf("hooks registered before the test")
+	}
+	platform.Observe(platform.RunOp, "mock", 0, 0)(nil)
+	var first, second []platform.HookEvent
+	removeFirst := platform.AddHook(func(ev platform.HookEvent) { first = append(first, ev) })
+	removeSecond := platform.AddHook(func(ev platform.HookEvent) { second = append(second, ev) })
+	if !platform.HasHooks() {
+		t.Errorf("no hooks registered after AddHook")
+	}
+	errRun := errors.New("run failed")
+	platform.Observe(platform.RunOp, "mock", 1, 16)(errRun)
+	removeFirst()
+	platform.NotifyHooks(platform.HookEvent{Op: platform.SendOp, Device: 0})
+	removeSecond()
+	platform.NotifyHooks(platform.HookEvent{Op: platform.ToHostOp, Device: 0})
+	if platform.HasHooks() {
+		t.Errorf("hooks still registered after removing all of them")
+	}
+	if len(first) != 1 {
+		t.Fatalf("first hook called %d times but want 1", len(first))
+	}
+	if ev := first[0]; ev.Op != platform.RunOp || ev.Platform != "mock" || ev.Device != 1 || ev.Bytes != 16 || ev.Err != errRun {
+		t.Errorf("got event %+v", ev)
+	}
+	ops := make([]platform.HookOp, len(second))
+	for i, ev := range second {
+		ops[i] = ev.Op
+	}
+	if want := []platform.HookOp{platform.RunOp, platform.SendOp}; !slices.Equal(ops, want) {
+		t.Errorf("second hook got operations %v but want %v", ops, want)
+	}
+}
+
+func TestObservedAllocator(t *testing.T) {
+	var events []platform.HookEvent
+	defer platform.AddHook(func(ev platform.HookEvent) { events = append(events, ev) })()
+	plat := platformtest.New(1)
+	plat.FailAllocationAt(2)
+	alloc := platform.ObservedAllocator{Allocator: plat}
+	sh := shape.Of(dtype.Float64, 4)
+	buf, err := alloc.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Free()
+	if _, err := alloc.Allocate(sh); err == nil {
+		t.Errorf("expected an out of memory error")
+	}
+	want := []struct {
+		op     platform.HookOp
+		failed bool
+	}{
+		{op: platform.AllocateOp},
+		{op: platform.FreeOp},
+		{op: platform.AllocateOp, failed: true},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events but want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		if ev.Op != want[i].op || (ev.Err != nil) != want[i].failed || ev.Device != -1 || ev.Bytes != int64(sh.ByteSize()) {
+			t.Errorf("event %d: got %+v", i, ev)
+		}
+	}
+}
+
+func TestHookOpString(t *testing.T) {
+	tests := []struct {
+		op   platform.HookOp
+		want string
+	}{
+		{op: platform.SendOp, want: "send"},
+		{op: platform.ToHostOp, want: "to_host"},
+		{op: platform.AllocateOp, want: "allocate"},
+		{op: platform.FreeOp, want: "free"},
+		{op: platform.RunOp, want: "run"},
+		{op: platform.HookOp(-1), want: "HookOp(-1)"},
+	}
+	for _, test := range tests {
+		if got := test.op.String(); got != test.want {
+			t.Errorf("got %q but want %q", got, test.want)
+		}
+	}
+}
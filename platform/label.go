@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "sync/atomic"
+
+// Labeler is implemented by handles which can be tagged with a debug label,
+// for example "layer3/weights". Platforms report labels in memory statistics,
+// profiler records and out of memory errors.
+type Labeler interface {
+	// SetLabel sets the label of the handle.
+	SetLabel(string)
+	// Label returns the label of the handle.
+	Label() string
+}
+
+// Labeled implements Labeler. It can be embedded in handle implementations.
+type Labeled struct {
+	label atomic.Pointer[string]
+}
+
+var _ Labeler = (*Labeled)(nil)
+
+// SetLabel sets the label.
+func (l *Labeled) SetLabel(label string) {
+	l.label.Store(&label)
+}
+
+// Label returns the label or an empty string if no label has been set.
+func (l *Labeled) Label() string {
+	label := l.label.Load()
+	if label == nil {
+		return ""
+	}
+	return *label
+}
+
+// SetLabel sets the label of a handle. It returns false if the handle does not support labels.
+func SetLabel(h Handle, label string) bool {
+	labeler, ok := h.(Labeler)
+	if ok {
+		labeler.SetLabel(label)
+	}
+	return ok
+}
+
+// Label returns the label of a handle or an empty string if the handle has no label.
+func Label(h Handle) string {
+	labeler, ok := h.(Labeler)
+	if !ok {
+		return ""
+	}
+	return labeler.Label()
+}
@@ -0,0 +1,347 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// LeakReport describes a handle which has not been freed.
+type LeakReport struct {
+	// Shape of the array.
+	Shape *shape.Shape
+	// Host is true if the handle is a host buffer.
+	Host bool
+	// Device is the ordinal of the device storing the array, or -1 for host buffers.
+	Device int
+	// Label of the handle.
+	Label string
+	// Stack where the handle has been tracked.
+	// Only available when leak detection is enabled.
+	Stack string
+}
+
+// liveEntry is a handle tracked while leak detection is enabled.
+type liveEntry struct {
+	report LeakReport
+	// label returns the current label of the handle, or false if it has been garbage collected.
+	label func() (string, bool)
+}
+
+var leaks struct {
+	enabled  atomic.Bool
+	mu       sync.Mutex
+	nextID   uint64
+	live     map[uint64]*liveEntry
+	reporter func(LeakReport)
+}
+
+// EnableLeakDetection records the stack of every tracked handle and host buffer
+// such that the ones which are never freed can be reported.
+// It is meant for debugging: recording stacks is slow.
+func EnableLeakDetection(enabled bool) {
+	leaks.enabled.Store(enabled)
+}
+
+// LeakDetectionEnabled returns true if leak detection is enabled.
+func LeakDetectionEnabled() bool {
+	return leaks.enabled.Load()
+}
+
+// SetLeakReporter sets the function called when a tracked handle is garbage collected
+// without having been freed. By default, leaks are logged if leak detection is enabled.
+func SetLeakReporter(reporter func(LeakReport)) {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	leaks.reporter = reporter
+}
+
+// register records a live handle if leak detection is enabled and returns its id, or 0.
+func register(report LeakReport, label func() (string, bool)) (id uint64, stack string) {
+	if !leaks.enabled.Load() {
+		return 0, ""
+	}
+	report.Stack = string(debug.Stack())
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	leaks.nextID++
+	if leaks.live == nil {
+		leaks.live = make(map[uint64]*liveEntry)
+	}
+	leaks.live[leaks.nextID] = &liveEntry{report: report, label: label}
+	return leaks.nextID, report.Stack
+}
+
+func untrack(id uint64) {
+	if id == 0 {
+		return
+	}
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	delete(leaks.live, id)
+}
+
+// reportLeak reports a handle garbage collected without being freed.
+func reportLeak(report LeakReport) {
+	leaks.mu.Lock()
+	reporter := leaks.reporter
+	leaks.mu.Unlock()
+	if reporter != nil {
+		reporter(report)
+		return
+	}
+	where := fmt.Sprintf("device %d", report.Device)
+	if report.Host {
+		where = "host"
+	}
+	log.Printf("platform: handle %s on %s garbage collected without being freed. Tracked at:\n%s", report.Shape, where, report.Stack)
+}
+
+// trackedHandle frees the memory of a device handle when it is garbage collected.
+type trackedHandle struct {
+	DeviceHandle
+	id    uint64
+	stack string
+	freed atomic.Bool
+}
+
+// Track returns a handle freeing the memory of a device handle when garbage collected
+// if Free has not been called. Platforms should call Track on the handles they create
+// when they cannot rely on the garbage collector to release device memory.
+func Track(h DeviceHandle) DeviceHandle {
+	tracked := &trackedHandle{DeviceHandle: h}
+	ptr := weak.Make(tracked)
+	tracked.id, tracked.stack = register(tracked.report(), func() (string, bool) {
+		if h := ptr.Value(); h != nil {
+			return h.Label(), true
+		}
+		return "", false
+	})
+	runtime.SetFinalizer(tracked, (*trackedHandle).finalize)
+	return tracked
+}
+
+func (h *trackedHandle) report() LeakReport {
+	return LeakReport{
+		Shape:  h.DeviceHandle.Shape(),
+		Device: h.DeviceHandle.Device().Ordinal(),
+		Label:  Label(h.DeviceHandle),
+		Stack:  h.stack,
+	}
+}
+
+// SetLabel sets the label of the tracked handle.
+func (h *trackedHandle) SetLabel(label string) {
+	SetLabel(h.DeviceHandle, label)
+}
+
+// Label returns the label of the tracked handle.
+func (h *trackedHandle) Label() string {
+	return Label(h.DeviceHandle)
+}
+
+// Free the memory of the handle.
+func (h *trackedHandle) Free() {
+	if h.freed.Swap(true) {
+		return
+	}
+	runtime.SetFinalizer(h, nil)
+	untrack(h.id)
+	h.DeviceHandle.Free()
+}
+
+func (h *trackedHandle) finalize() {
+	if h.freed.Swap(true) {
+		return
+	}
+	untrack(h.id)
+	if h.id != 0 {
+		reportLeak(h.report())
+	}
+	h.DeviceHandle.Free()
+}
+
+// trackedBuffer frees a host buffer when it is garbage collected.
+type trackedBuffer struct {
+	HostBuffer
+	id    uint64
+	stack string
+	freed atomic.Bool
+}
+
+// TrackHostBuffer returns a host buffer freed when garbage collected if Free has not been called.
+// When leak detection is enabled, the buffer is reported by LiveHandles until it is freed.
+func TrackHostBuffer(buf HostBuffer) HostBuffer {
+	tracked := &trackedBuffer{HostBuffer: buf}
+	ptr := weak.Make(tracked)
+	tracked.id, tracked.stack = register(tracked.report(), func() (string, bool) {
+		if b := ptr.Value(); b != nil {
+			return b.Label(), true
+		}
+		return "", false
+	})
+	runtime.SetFinalizer(tracked, (*trackedBuffer).finalize)
+	return tracked
+}
+
+func (b *trackedBuffer) report() LeakReport {
+	return LeakReport{
+		Shape:  b.HostBuffer.Shape(),
+		Host:   true,
+		Device: -1,
+		Label:  Label(b.HostBuffer),
+		Stack:  b.stack,
+	}
+}
+
+// SetLabel sets the label of the tracked buffer.
+func (b *trackedBuffer) SetLabel(label string) {
+	SetLabel(b.HostBuffer, label)
+}
+
+// Label returns the label of the tracked buffer.
+func (b *trackedBuffer) Label() string {
+	return Label(b.HostBuffer)
+}
+
+// Free the memory of the buffer.
+func (b *trackedBuffer) Free() {
+	if b.freed.Swap(true) {
+		return
+	}
+	runtime.SetFinalizer(b, nil)
+	untrack(b.id)
+	b.HostBuffer.Free()
+}
+
+func (b *trackedBuffer) finalize() {
+	if b.freed.Swap(true) {
+		return
+	}
+	untrack(b.id)
+	if b.id != 0 {
+		reportLeak(b.report())
+	}
+	b.HostBuffer.Free()
+}
+
+// LiveHandles returns the reports of all the handles and host buffers tracked while leak
+// detection was enabled which have not been freed or garbage collected yet,
+// sorted by creation order.
+func LiveHandles() []LeakReport {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	ids := make([]uint64, 0, len(leaks.live))
+	for id := range leaks.live {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	reports := make([]LeakReport, 0, len(ids))
+	for _, id := range ids {
+		entry := leaks.live[id]
+		report := entry.report
+		label, alive := entry.label()
+		if !alive {
+			continue
+		}
+		report.Label = label
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// LiveGroup is a group of live handles with the same shape, label and location.
+type LiveGroup struct {
+	// Shape of the arrays, as a string.
+	Shape string
+	// Label of the handles.
+	Label string
+	// Host is true for host buffers.
+	Host bool
+	// Count is the number of live handles in the group.
+	Count int
+	// Bytes is the total size of the arrays of the group.
+	Bytes int64
+	// Stacks are the distinct stacks where the handles have been tracked.
+	Stacks []string
+}
+
+// LiveHandleGroups groups the live handles by shape, label and location,
+// sorted by decreasing number of bytes.
+func LiveHandleGroups() []LiveGroup {
+	type key struct {
+		shape, label string
+		host         bool
+	}
+	var order []key
+	groups := make(map[key]*LiveGroup)
+	for _, report := range LiveHandles() {
+		k := key{shape: report.Shape.String(), label: report.Label, host: report.Host}
+		group, ok := groups[k]
+		if !ok {
+			group = &LiveGroup{Shape: k.shape, Label: k.label, Host: k.host}
+			groups[k] = group
+			order = append(order, k)
+		}
+		group.Count++
+		group.Bytes += int64(report.Shape.ByteSize())
+		if !slices.Contains(group.Stacks, report.Stack) {
+			group.Stacks = append(group.Stacks, report.Stack)
+		}
+	}
+	res := make([]LiveGroup, len(order))
+	for i, k := range order {
+		res[i] = *groups[k]
+	}
+	slices.SortStableFunc(res, func(a, b LiveGroup) int {
+		switch {
+		case a.Bytes > b.Bytes:
+			return -1
+		case a.Bytes < b.Bytes:
+			return 1
+		}
+		return 0
+	})
+	return res
+}
+
+// DumpLiveHandles writes a summary of the live handles, grouped by shape and label, to a writer.
+func DumpLiveHandles(w io.Writer) error {
+	for _, group := range LiveHandleGroups() {
+		where := "device"
+		if group.Host {
+			where = "host"
+		}
+		if _, err := fmt.Fprintf(w, "%d x %s (%s, label=%q): %d bytes\n", group.Count, group.Shape, where, group.Label, group.Bytes); err != nil {
+			return err
+		}
+		for _, stack := range group.Stacks {
+			if _, err := fmt.Fprintf(w, "  tracked at:\n%s\n", stack); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestLiveHandles(t *testing.T) {
+	platform.EnableLeakDetection(true)
+	defer platform.EnableLeakDetection(false)
+	dev := platformtest.New(1).MockDevice(0)
+	sh := shape.Vector(dtype.Float32, 2)
+	var handles []platform.DeviceHandle
+	for range 3 {
+		h, err := dev.Send(make([]byte, 8), sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracked := platform.Track(h)
+		platform.SetLabel(tracked, "weights")
+		handles = append(handles, tracked)
+	}
+	groups := platform.LiveHandleGroups()
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups but want 1: %v", len(groups), groups)
+	}
+	if got := groups[0]; got.Count != 3 || got.Bytes != 24 || got.Label != "weights" {
+		t.Errorf("unexpected group %+v", got)
+	}
+	for _, h := range handles {
+		h.Free()
+	}
+	if got := platform.LiveHandles(); len(got) != 0 {
+		t.Errorf("got %d live handles after free but want 0", len(got))
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClosed is returned when using a platform or a backend which has been closed.
+var ErrClosed = errors.New("closed")
+
+// Lifecycle implements the closing semantics of platforms and backends.
+// Operations call Enter before starting and Exit when done. Close rejects new
+// operations, waits for the operations in flight, and then releases the resources.
+type Lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+	once     sync.Once
+	err      error
+}
+
+// Enter marks the start of an operation. It returns ErrClosed if Close has been called.
+func (l *Lifecycle) Enter() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	l.inFlight.Add(1)
+	return nil
+}
+
+// Exit marks the end of an operation started by Enter.
+func (l *Lifecycle) Exit() {
+	l.inFlight.Done()
+}
+
+// Closed returns true if Close has been called.
+func (l *Lifecycle) Closed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// Close waits for the operations in flight and calls release.
+// Only the first call releases the resources. All calls return the error of release.
+func (l *Lifecycle) Close(release func() error) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	l.inFlight.Wait()
+	l.once.Do(func() {
+		if release != nil {
+			l.err = release()
+		}
+	})
+	return l.err
+}
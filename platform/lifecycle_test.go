@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+)
+
+func TestLifecycleWaitsForOperations(t *testing.T) {
+	var l platform.Lifecycle
+	if err := l.Enter(); err != nil {
+		t.Fatal(err)
+	}
+	var released atomic.Bool
+	closed := make(chan error)
+	go func() {
+		closed <- l.Close(func() error {
+			released.Store(true)
+			return nil
+		})
+	}()
+	// New operations are rejected as soon as Close has been called,
+	// but the resources are only released when the operation in flight exits.
+	for !l.Closed() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := l.Enter(); !errors.Is(err, platform.ErrClosed) {
+		t.Errorf("got error %v but want %v", err, platform.ErrClosed)
+	}
+	select {
+	case <-closed:
+		t.Fatalf("Close returned before the operation in flight exited")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if released.Load() {
+		t.Errorf("resources released before the operation in flight exited")
+	}
+	l.Exit()
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if !released.Load() {
+		t.Errorf("resources not released after Close returned")
+	}
+}
+
+func TestLifecycleConcurrentClose(t *testing.T) {
+	const numOps = 64
+	var l platform.Lifecycle
+	var (
+		inFlight, entered, done atomic.Int32
+		releases                atomic.Int32
+	)
+	errRelease := errors.New("release failed")
+	release := func() error {
+		releases.Add(1)
+		if n := inFlight.Load(); n != 0 {
+			t.Errorf("resources released with %d operations in flight", n)
+		}
+		if e, d := entered.Load(), done.Load(); e != d {
+			t.Errorf("resources released after %d operations entered but only %d completed", e, d)
+		}
+		return errRelease
+	}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range numOps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if i%16 == 0 {
+				if err := l.Close(release); err != errRelease {
+					t.Errorf("Close returned %v but want %v", err, errRelease)
+				}
+				return
+			}
+			if err := l.Enter(); err != nil {
+				if !errors.Is(err, platform.ErrClosed) {
+					t.Errorf("got error %v but want %v", err, platform.ErrClosed)
+				}
+				return
+			}
+			entered.Add(1)
+			inFlight.Add(1)
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			done.Add(1)
+			l.Exit()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if got := releases.Load(); got != 1 {
+		t.Errorf("resources released %d times but want 1", got)
+	}
+	if err := l.Enter(); !errors.Is(err, platform.ErrClosed) {
+		t.Errorf("Enter after Close: got error %v but want %v", err, platform.ErrClosed)
+	}
+	if err := l.Close(release); err != errRelease {
+		t.Errorf("Close after Close returned %v but want %v", err, errRelease)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "github.com/gx-org/backend/shape"
+
+type (
+	// HandleMemory is the memory used by a live handle on a device.
+	HandleMemory struct {
+		// Shape of the array.
+		Shape *shape.Shape
+		// Label of the handle.
+		Label string
+		// Bytes allocated for the array.
+		Bytes int64
+	}
+
+	// MemoryStats describes the memory used on a device.
+	MemoryStats struct {
+		// Allocated is the number of bytes currently allocated.
+		Allocated int64
+		// Peak is the maximum number of bytes allocated at the same time
+		// since the device has been created or since the peak has been reset.
+		Peak int64
+		// Limit is the maximum number of bytes which can be allocated, or zero or less if unknown.
+		Limit int64
+		// Handles lists the memory used by each live handle allocated by the device.
+		Handles []HandleMemory
+	}
+
+	// MemoryReporter is implemented by devices reporting the memory they use.
+	MemoryReporter interface {
+		Device
+
+		// MemoryStats returns the memory currently used on the device.
+		MemoryStats() MemoryStats
+
+		// ResetPeakMemory resets the peak of the statistics to the number of bytes currently allocated.
+		ResetPeakMemory()
+	}
+)
+
+// DeviceMemoryStats returns the memory used on a device.
+// It returns false if the device does not implement MemoryReporter.
+func DeviceMemoryStats(dev Device) (MemoryStats, bool) {
+	reporter, ok := dev.(MemoryReporter)
+	if !ok {
+		return MemoryStats{}, false
+	}
+	return reporter.MemoryStats(), true
+}
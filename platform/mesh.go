@@ -0,0 +1,461 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Mesh is an n-dimensional arrangement of devices with named axes.
+// Devices are stored in row-major order of the mesh coordinates.
+type Mesh struct {
+	axisNames []string
+	axisSizes []int
+	devices   []Device
+}
+
+// NewMesh returns a mesh given its devices in row-major order, the names of its axes,
+// and the number of devices along each axis.
+func NewMesh(devices []Device, axisNames []string, axisSizes []int) (*Mesh, error) {
+	if len(axisNames) != len(axisSizes) {
+		return nil, errors.Errorf("got %d mesh axis names for %d mesh axes", len(axisNames), len(axisSizes))
+	}
+	for i, name := range axisNames {
+		if name == "" {
+			return nil, errors.Errorf("mesh axis %d has no name", i)
+		}
+		if slices.Index(axisNames, name) != i {
+			return nil, errors.Errorf("mesh axis name %q is used more than once", name)
+		}
+		if axisSizes[i] <= 0 {
+			return nil, errors.Errorf("mesh axis %q has a non-positive size %d", name, axisSizes[i])
+		}
+	}
+	if got, want := len(devices), shape.Size(axisSizes); got != want {
+		return nil, errors.Errorf("got %d devices for a mesh of %d devices", got, want)
+	}
+	return &Mesh{
+		axisNames: slices.Clone(axisNames),
+		axisSizes: slices.Clone(axisSizes),
+		devices:   slices.Clone(devices),
+	}, nil
+}
+
+// Rank returns the number of axes of the mesh.
+func (m *Mesh) Rank() int {
+	return len(m.axisSizes)
+}
+
+// AxisNames returns the names of the mesh axes.
+func (m *Mesh) AxisNames() []string {
+	return m.axisNames
+}
+
+// AxisSizes returns the number of devices along each axis of the mesh.
+func (m *Mesh) AxisSizes() []int {
+	return m.axisSizes
+}
+
+// AxisIndex returns the index of a mesh axis given its name or -1 if the axis does not exist.
+func (m *Mesh) AxisIndex(name string) int {
+	return slices.Index(m.axisNames, name)
+}
+
+// Devices returns all the devices of the mesh in row-major order.
+func (m *Mesh) Devices() []Device {
+	return m.devices
+}
+
+// Device returns the device at the given mesh coordinates.
+func (m *Mesh) Device(coords ...int) (Device, error) {
+	if len(coords) != len(m.axisSizes) {
+		return nil, errors.Errorf("got %d coordinates for a mesh of rank %d", len(coords), len(m.axisSizes))
+	}
+	i := 0
+	for axis, c := range coords {
+		if c < 0 || c >= m.axisSizes[axis] {
+			return nil, errors.Errorf("coordinate %d out of range [0, %d) for mesh axis %q", c, m.axisSizes[axis], m.axisNames[axis])
+		}
+		i = i*m.axisSizes[axis] + c
+	}
+	return m.devices[i], nil
+}
+
+// Coords returns the mesh coordinates of the ith device of the mesh.
+func (m *Mesh) Coords(i int) []int {
+	coords := make([]int, len(m.axisSizes))
+	for axis := len(m.axisSizes) - 1; axis >= 0; axis-- {
+		coords[axis] = i % m.axisSizes[axis]
+		i /= m.axisSizes[axis]
+	}
+	return coords
+}
+
+// Groups returns the groups of devices along a mesh axis.
+// Devices within a group differ only by their coordinate along that axis,
+// such that each group can be used to create a Communicator.
+func (m *Mesh) Groups(axisName string) ([][]Device, error) {
+	axis := m.AxisIndex(axisName)
+	if axis < 0 {
+		return nil, errors.Errorf("mesh %s has no axis %q", m, axisName)
+	}
+	size := m.axisSizes[axis]
+	groups := make([][]Device, len(m.devices)/size)
+	for i, dev := range m.devices {
+		coords := m.Coords(i)
+		coords[axis] = 0
+		g := 0
+		for a, c := range coords {
+			if a != axis {
+				g = g*m.axisSizes[a] + c
+			}
+		}
+		groups[g] = append(groups[g], dev)
+	}
+	return groups, nil
+}
+
+// Communicators returns a communicator for each group of devices along a mesh axis.
+func (m *Mesh) Communicators(plat Platform, axisName string) ([]Communicator, error) {
+	groups, err := m.Groups(axisName)
+	if err != nil {
+		return nil, err
+	}
+	comms := make([]Communicator, len(groups))
+	for i, group := range groups {
+		if comms[i], err = NewCommunicator(plat, group); err != nil {
+			return nil, err
+		}
+	}
+	return comms, nil
+}
+
+// ShardingSpec returns a sharding spec splitting each axis of an array along a mesh axis.
+// meshAxes maps each axis of the array to the name of a mesh axis or to an empty string
+// if the array axis is not split. All mesh axes with more than one device must be used
+// since ShardingSpec does not support replication.
+func (m *Mesh) ShardingSpec(meshAxes ...string) (*ShardingSpec, error) {
+	splits := make([]int, len(meshAxes))
+	toMesh := make([]int, len(meshAxes))
+	used := make([]bool, len(m.axisSizes))
+	for i, name := range meshAxes {
+		splits[i], toMesh[i] = 1, -1
+		if name == "" {
+			continue
+		}
+		axis := m.AxisIndex(name)
+		if axis < 0 {
+			return nil, errors.Errorf("mesh %s has no axis %q", m, name)
+		}
+		if used[axis] {
+			return nil, errors.Errorf("mesh axis %q is used more than once", name)
+		}
+		used[axis] = true
+		splits[i], toMesh[i] = m.axisSizes[axis], axis
+	}
+	for axis, ok := range used {
+		if !ok && m.axisSizes[axis] > 1 {
+			return nil, errors.Errorf("mesh axis %q of size %d is not used: replication is not supported", m.axisNames[axis], m.axisSizes[axis])
+		}
+	}
+	spec := &ShardingSpec{Splits: splits, Devices: make([]Device, shape.Size(splits))}
+	grid := make([]int, len(splits))
+	coords := make([]int, len(m.axisSizes))
+	for i := range spec.Devices {
+		rest := i
+		for a := len(splits) - 1; a >= 0; a-- {
+			grid[a] = rest % splits[a]
+			rest /= splits[a]
+		}
+		for a, meshAxis := range toMesh {
+			if meshAxis >= 0 {
+				coords[meshAxis] = grid[a]
+			}
+		}
+		dev, err := m.Device(coords...)
+		if err != nil {
+			return nil, err
+		}
+		spec.Devices[i] = dev
+	}
+	return spec, nil
+}
+
+// String returns a description of the mesh axes.
+func (m *Mesh) String() string {
+	axes := make([]string, len(m.axisNames))
+	for i, name := range m.axisNames {
+		axes[i] = fmt.Sprintf("%s:%d", name, m.axisSizes[i])
+	}
+	return "Mesh[" + strings.Join(axes, ",") + "]"
+}
+
+// PartitionSpec specifies how an array is distributed over the devices of a mesh.
+// It maps each axis of the array to the name of the mesh axis splitting it, or to an
+// empty string if the array axis is not split. The array is replicated along the mesh
+// axes which are not used. A nil spec replicates the array on all the devices of the mesh.
+type PartitionSpec []string
+
+// CheckPartition returns an error if a partition spec cannot be used for an array of a given rank.
+func (m *Mesh) CheckPartition(spec PartitionSpec, rank int) error {
+	if spec == nil {
+		return nil
+	}
+	if len(spec) != rank {
+		return errors.Errorf("partition spec %v has %d axes for an array of rank %d", spec, len(spec), rank)
+	}
+	for i, name := range spec {
+		if name == "" {
+			continue
+		}
+		if m.AxisIndex(name) < 0 {
+			return errors.Errorf("mesh %s has no axis %q", m, name)
+		}
+		if slices.Index(spec, name) != i {
+			return errors.Errorf("mesh axis %q is used more than once in partition spec %v", name, spec)
+		}
+	}
+	return nil
+}
+
+// GlobalShape returns the shape of an array distributed over the mesh given the shape of its shards.
+func (m *Mesh) GlobalShape(spec PartitionSpec, shardShape *shape.Shape) (*shape.Shape, error) {
+	if err := m.CheckPartition(spec, len(shardShape.AxisLengths)); err != nil {
+		return nil, err
+	}
+	axisLengths := slices.Clone(shardShape.AxisLengths)
+	for i, name := range spec {
+		if name != "" {
+			axisLengths[i] *= m.axisSizes[m.AxisIndex(name)]
+		}
+	}
+	return shardShape.WithAxes(axisLengths...), nil
+}
+
+// Replicas returns a sharding spec for each copy of an array distributed over the mesh.
+// The devices of the ith spec are the devices with the ith row-major coordinates along the
+// mesh axes not used by the partition spec. An array which is not replicated has a single spec.
+func (m *Mesh) Replicas(spec PartitionSpec, rank int) ([]*ShardingSpec, error) {
+	if err := m.CheckPartition(spec, rank); err != nil {
+		return nil, err
+	}
+	replicas, shards := m.locate(spec, rank)
+	splits := make([]int, rank)
+	for i := range splits {
+		splits[i] = 1
+		if spec != nil && spec[i] != "" {
+			splits[i] = m.axisSizes[m.AxisIndex(spec[i])]
+		}
+	}
+	numShards := shape.Size(splits)
+	specs := make([]*ShardingSpec, len(m.devices)/numShards)
+	for r := range specs {
+		specs[r] = &ShardingSpec{Splits: splits, Devices: make([]Device, numShards)}
+	}
+	for i, dev := range m.devices {
+		specs[replicas[i]].Devices[shards[i]] = dev
+	}
+	return specs, nil
+}
+
+// locate returns, for every device of the mesh, the index of the replica and the index
+// of the shard it stores for an array distributed with a partition spec.
+func (m *Mesh) locate(spec PartitionSpec, rank int) (replicas, shards []int) {
+	toMesh := make([]int, rank)
+	used := make([]bool, len(m.axisSizes))
+	for i := range toMesh {
+		toMesh[i] = -1
+		if spec != nil && spec[i] != "" {
+			toMesh[i] = m.AxisIndex(spec[i])
+			used[toMesh[i]] = true
+		}
+	}
+	replicas = make([]int, len(m.devices))
+	shards = make([]int, len(m.devices))
+	for i := range m.devices {
+		coords := m.Coords(i)
+		for _, axis := range toMesh {
+			if axis >= 0 {
+				shards[i] = shards[i]*m.axisSizes[axis] + coords[axis]
+			}
+		}
+		for axis, ok := range used {
+			if !ok {
+				replicas[i] = replicas[i]*m.axisSizes[axis] + coords[axis]
+			}
+		}
+	}
+	return replicas, shards
+}
+
+// Distribute returns the handle of the shard of an array stored on each device of the mesh,
+// in the row-major order of the mesh. The array is either a sharded handle distributed as
+// the partition spec requires, in which case its shards are used without any copy, or an
+// array of the global shape, which is split and transferred to the devices.
+// The caller must call the returned function to free the handles created by Distribute.
+func (m *Mesh) Distribute(arg Handle, spec PartitionSpec, shardShape *shape.Shape) ([]DeviceHandle, func(), error) {
+	global, err := m.GlobalShape(spec, shardShape)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !arg.Shape().Equal(global) {
+		return nil, nil, errors.Errorf("array of shape %s cannot be distributed as shards of shape %s with partition spec %v: want shape %s", arg.Shape(), shardShape, spec, global)
+	}
+	specs, err := m.Replicas(spec, len(shardShape.AxisLengths))
+	if err != nil {
+		return nil, nil, err
+	}
+	replicas, shards := m.locate(spec, len(shardShape.AxisLengths))
+	var owned []DeviceHandle
+	release := func() {
+		for _, h := range owned {
+			h.Free()
+		}
+	}
+	perDevice := make([]DeviceHandle, len(m.devices))
+	if sharded, ok := arg.(*ShardedHandle); ok && len(specs) == 1 && sharded.spec.equal(specs[0]) {
+		for i := range perDevice {
+			perDevice[i] = sharded.shards[shards[i]]
+		}
+		return perDevice, release, nil
+	}
+	if specs[0].NumShards() == 1 {
+		for i, dev := range m.devices {
+			if h, ok := arg.(DeviceHandle); ok && h.Device() == dev {
+				perDevice[i] = h
+				continue
+			}
+			if perDevice[i], err = arg.ToDevice(dev); err != nil {
+				release()
+				return nil, nil, errors.Errorf("cannot transfer array to device %d: %v", dev.Ordinal(), err)
+			}
+			owned = append(owned, perDevice[i])
+		}
+		return perDevice, release, nil
+	}
+	staging, err := stageToHost(arg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer staging.release()
+	for r, rspec := range specs {
+		sharded, err := Shard(staging.data, global, rspec)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+		owned = append(owned, sharded.shards...)
+		for i := range perDevice {
+			if replicas[i] == r {
+				perDevice[i] = sharded.shards[shards[i]]
+			}
+		}
+	}
+	return perDevice, release, nil
+}
+
+// Collect returns a sharded handle given the handles of the shards of an array stored on
+// each device of the mesh, in the row-major order of the mesh. Collect takes the ownership
+// of the handles: the handles of the first replica are owned by the returned sharded handle
+// and all other handles are freed.
+func (m *Mesh) Collect(perDevice []DeviceHandle, spec PartitionSpec) (*ShardedHandle, error) {
+	freeAll := func() {
+		for _, h := range perDevice {
+			h.Free()
+		}
+	}
+	if len(perDevice) != len(m.devices) {
+		freeAll()
+		return nil, errors.Errorf("got %d handles for a mesh of %d devices", len(perDevice), len(m.devices))
+	}
+	shardShape := perDevice[0].Shape()
+	global, err := m.GlobalShape(spec, shardShape)
+	if err != nil {
+		freeAll()
+		return nil, err
+	}
+	specs, err := m.Replicas(spec, len(shardShape.AxisLengths))
+	if err != nil {
+		freeAll()
+		return nil, err
+	}
+	replicas, shards := m.locate(spec, len(shardShape.AxisLengths))
+	first := make([]DeviceHandle, specs[0].NumShards())
+	for i, h := range perDevice {
+		if replicas[i] == 0 {
+			first[shards[i]] = h
+		}
+	}
+	sharded, err := NewShardedHandle(global, specs[0], first)
+	if err != nil {
+		freeAll()
+		return nil, err
+	}
+	for i, h := range perDevice {
+		if replicas[i] != 0 {
+			h.Free()
+		}
+	}
+	return sharded, nil
+}
+
+// GroupOf returns the indices of the devices of the group of the ith device of the mesh.
+// A group is made of the devices differing from the ith device only by their coordinates
+// along the given mesh axes, or along all the mesh axes if meshAxes is empty. Indices are
+// sorted in the row-major order of the mesh.
+func (m *Mesh) GroupOf(i int, meshAxes []string) ([]int, error) {
+	if i < 0 || i >= len(m.devices) {
+		return nil, errors.Errorf("device index %d out of range [0, %d)", i, len(m.devices))
+	}
+	inGroup := make([]bool, len(m.axisSizes))
+	for _, name := range meshAxes {
+		axis := m.AxisIndex(name)
+		if axis < 0 {
+			return nil, errors.Errorf("mesh %s has no axis %q", m, name)
+		}
+		inGroup[axis] = true
+	}
+	coords := m.Coords(i)
+	var group []int
+	for j := range m.devices {
+		other := m.Coords(j)
+		same := true
+		for axis, c := range other {
+			if len(meshAxes) > 0 && !inGroup[axis] && c != coords[axis] {
+				same = false
+				break
+			}
+		}
+		if same {
+			group = append(group, j)
+		}
+	}
+	return group, nil
+}
+
+// GroupSize returns the number of devices of the groups along the given mesh axes,
+// or the number of devices of the mesh if meshAxes is empty.
+func (m *Mesh) GroupSize(meshAxes []string) (int, error) {
+	group, err := m.GroupOf(0, meshAxes)
+	if err != nil {
+		return 0, err
+	}
+	return len(group), nil
+}
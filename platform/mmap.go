@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// MappedFile is a file mapped in memory, for example a checkpoint storing weights.
+// Arrays stored in the file are exposed as host buffers without loading the whole
+// file in memory: pages are read from the file when accessed.
+//
+// The mapping is private: writing to a buffer does not modify the file.
+type MappedFile struct {
+	mu     sync.Mutex
+	data   []byte
+	unmap  func([]byte) error
+	closed bool
+}
+
+// MapFile maps a file in memory.
+func MapFile(path string) (*MappedFile, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, errors.Errorf("cannot map file %s: %v", path, err)
+	}
+	return &MappedFile{data: data, unmap: unmap}, nil
+}
+
+// Len returns the size of the file in bytes.
+func (f *MappedFile) Len() int {
+	return len(f.data)
+}
+
+// Buffer returns a host buffer for an array of a given shape stored at an offset in the file.
+// The buffer must not be used after the file has been closed.
+func (f *MappedFile) Buffer(offset int, sh *shape.Shape) (HostBuffer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, errors.Errorf("cannot create a buffer from a closed file")
+	}
+	size := sh.ByteSize()
+	if offset < 0 || offset+size > len(f.data) {
+		return nil, errors.Errorf("array of shape %s at offset %d out of range for a file of %d bytes", sh, offset, len(f.data))
+	}
+	return Borrow(f.data[offset:offset+size:offset+size], sh)
+}
+
+// Close unmaps the file. All the buffers returned by the file become invalid.
+func (f *MappedFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	data := f.data
+	f.data = nil
+	return f.unmap(data)
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestMappedFile(t *testing.T) {
+	want := []float32{1, 2, 3, 4, 5, 6}
+	path := filepath.Join(t.TempDir(), "weights.bin")
+	if err := os.WriteFile(path, dtype.FromSlice(want), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := platform.MapFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	buf, err := file.Buffer(8, shape.Vector(dtype.Float32, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := dtype.ToSlice[float32](buf.Acquire())
+	if !slices.Equal(got, want[2:]) {
+		t.Errorf("got %v but want %v", got, want[2:])
+	}
+	got[0] = 42
+	buf.Release()
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dtype.ToSlice[float32](onDisk), want) {
+		t.Errorf("writing to a mapped buffer modified the file")
+	}
+	if _, err := file.Buffer(16, shape.Vector(dtype.Float32, 4)); err == nil {
+		t.Errorf("expected an error for an out of range buffer")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+type (
+	// Upload is an array being assembled on a device from chunks of its data.
+	Upload interface {
+		// WriteAt copies p into the array at byte offset off.
+		// WriteAt can be called concurrently for disjoint ranges.
+		WriteAt(p []byte, off int64) (int, error)
+
+		// Finish completes the upload once all the chunks have been written
+		// and returns the handle of the array.
+		Finish() (DeviceHandle, error)
+
+		// Abort releases the memory of an upload which will not be finished.
+		Abort()
+	}
+
+	// ChunkedSender is implemented by devices able to assemble an array
+	// from chunks uploaded concurrently, for example on several streams.
+	ChunkedSender interface {
+		Device
+
+		// NewUpload allocates an array of a given shape on the device.
+		NewUpload(sh *shape.Shape) (Upload, error)
+	}
+
+	// ChunkedFetcher is implemented by handles able to transfer ranges
+	// of their data to the host concurrently.
+	ChunkedFetcher interface {
+		Handle
+
+		// ReadAt copies the data of the array at byte offset off into p.
+		// ReadAt can be called concurrently.
+		ReadAt(p []byte, off int64) (int, error)
+	}
+)
+
+// ParallelOptions configures how large arrays are split across concurrent transfers.
+type ParallelOptions struct {
+	// ChunkBytes is the size, in bytes, of each chunk. Arrays smaller than
+	// two chunks are transferred in a single call. Defaults to 8MiB.
+	ChunkBytes int
+	// Streams is the maximum number of chunks transferred concurrently.
+	// Defaults to 4.
+	Streams int
+}
+
+const (
+	defaultChunkBytes = 8 << 20
+	defaultStreams    = 4
+)
+
+func (o ParallelOptions) withDefaults() ParallelOptions {
+	if o.ChunkBytes <= 0 {
+		o.ChunkBytes = defaultChunkBytes
+	}
+	if o.Streams <= 0 {
+		o.Streams = defaultStreams
+	}
+	return o
+}
+
+// SendParallel sends raw data to a device, splitting large arrays into chunks
+// uploaded concurrently. It is equivalent to dev.Send if the device does not
+// implement ChunkedSender or if the array is too small to be split.
+func SendParallel(dev Device, buf []byte, sh *shape.Shape, opts ParallelOptions) (DeviceHandle, error) {
+	opts = opts.withDefaults()
+	sender, ok := dev.(ChunkedSender)
+	if !ok || len(buf) < 2*opts.ChunkBytes {
+		return dev.Send(buf, sh)
+	}
+	if len(buf) != sh.ByteSize() {
+		return nil, errors.Wrapf(ErrInvalidShape, "got %d bytes for shape %s: want %d bytes", len(buf), sh, sh.ByteSize())
+	}
+	up, err := sender.NewUpload(sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := inChunks(len(buf), opts, func(start, end int) error {
+		_, err := up.WriteAt(buf[start:end], int64(start))
+		return err
+	}); err != nil {
+		up.Abort()
+		return nil, err
+	}
+	return up.Finish()
+}
+
+// ToHostParallel fetches the data of a handle into a host buffer, splitting large
+// arrays into chunks transferred concurrently. It is equivalent to h.ToHost if the
+// handle does not implement ChunkedFetcher or if the array is too small to be split.
+func ToHostParallel(h Handle, dst HostBuffer, opts ParallelOptions) error {
+	opts = opts.withDefaults()
+	sh := h.Shape()
+	fetcher, ok := h.(ChunkedFetcher)
+	if !ok || sh.ByteSize() < 2*opts.ChunkBytes {
+		return h.ToHost(dst)
+	}
+	if !dst.Shape().Equal(sh) {
+		return errors.Wrapf(ErrInvalidShape, "cannot transfer an array of shape %s to a buffer of shape %s", sh, dst.Shape())
+	}
+	data := dst.Acquire()
+	defer dst.Release()
+	if data == nil {
+		return errors.Wrap(ErrBufferFreed, "cannot copy data to host buffer")
+	}
+	return inChunks(len(data), opts, func(start, end int) error {
+		_, err := fetcher.ReadAt(data[start:end], int64(start))
+		return err
+	})
+}
+
+// inChunks calls fn for consecutive ranges of [0, size), running at most opts.Streams calls concurrently.
+// It returns the error of the chunk with the lowest offset, if any.
+func inChunks(size int, opts ParallelOptions, fn func(start, end int) error) error {
+	numChunks := (size + opts.ChunkBytes - 1) / opts.ChunkBytes
+	errs := make([]error, numChunks)
+	sem := make(chan struct{}, opts.Streams)
+	var wg sync.WaitGroup
+	for i := range numChunks {
+		start := i * opts.ChunkBytes
+		end := min(start+opts.ChunkBytes, size)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(start, end); err != nil {
+				errs[i] = errors.Errorf("cannot transfer bytes [%d, %d): %v", start, end, err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
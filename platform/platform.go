@@ -18,7 +18,12 @@
 // also providing the means to exchange data among them.
 package platform
 
-import "github.com/gx-org/backend/shape"
+import (
+	"time"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
 
 type (
 	// Platform is a host orchestrating one or more devices.
@@ -29,8 +34,26 @@ type (
 		// Device returns the device managed by the backend.
 		Device(int) (Device, error)
 
+		// NumDevices returns the number of devices currently available on the platform.
+		NumDevices() int
+
+		// Devices returns all the devices currently available on the platform,
+		// ordered by ordinal.
+		Devices() ([]Device, error)
+
+		// DefaultDevice returns the device to use when the caller has no preference.
+		// Platforms typically select the device using the policy of their configuration.
+		DefaultDevice() (Device, error)
+
+		// Close releases everything linked to the platform, for example driver contexts
+		// and device memory. Operations in flight complete before the resources are released.
+		// Once closed, all devices and handles of the platform are invalid and new operations
+		// return ErrClosed. Calling Close more than once returns the error of the first call.
+		Close() error
+
 		// Release everything linked to the platform.
-		// It is invalid to use any device from the platform after this call.
+		//
+		// Deprecated: use Close.
 		Release() error
 	}
 
@@ -40,9 +63,46 @@ type (
 		Platform() Platform
 
 		// Send raw data to the device.
+		// Implementations should call shape.Check on the shape before transferring any data.
+		// The layout of the shape is the layout of buf. Devices which store arrays with a
+		// different layout convert the data (see shape.Relayout) or return an error.
 		Send(buf []byte, sh *shape.Shape) (DeviceHandle, error)
 
 		// Ordinal of the device on the platform.
 		Ordinal() int
+
+		// Description returns the properties of the device.
+		Description() *Description
+
+		// HealthCheck returns an error if the device cannot run computations anymore,
+		// for example after an unrecoverable hardware error. The error wraps ErrDeviceLost
+		// if the device needs to be reset.
+		HealthCheck() error
+
+		// Reset reinitializes the device. All handles located on the device,
+		// compiled computations and streams become invalid.
+		Reset() error
+
+		// Now returns the current timestamp of the device clock, measured from
+		// an arbitrary device-specific epoch. Use SyncClock to convert device
+		// timestamps to the host clock.
+		Now() (time.Duration, error)
 	}
 )
+
+// IntDataTyper is implemented by platforms specifying the fixed-size data type
+// used to represent dtype.Int.
+type IntDataTyper interface {
+	IntDataType() dtype.DataType
+}
+
+// Resolve returns the fixed-size data type used by a platform to represent a data type.
+// dtype.Int is resolved to the data type returned by the platform if it implements IntDataTyper,
+// or to dtype.HostInt otherwise. All other data types are returned unchanged.
+func Resolve(plat Platform, dt dtype.DataType) dtype.DataType {
+	intType := dtype.Invalid
+	if typer, ok := plat.(IntDataTyper); ok {
+		intType = typer.IntDataType()
+	}
+	return dtype.Resolve(dt, intType)
+}
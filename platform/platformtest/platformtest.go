@@ -0,0 +1,358 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package platformtest provides an in-memory platform to unit test code
+// built on the platform interfaces, with scriptable failures.
+package platformtest
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Counts are the number of operations executed by a platform.
+type Counts struct {
+	// Allocations is the number of host buffers allocated, including failed allocations.
+	Allocations int
+	// Sends is the number of transfers to a device, including failed transfers.
+	Sends int
+	// ToHosts is the number of transfers to the host, including failed transfers.
+	ToHosts int
+	// Frees is the number of device handles freed.
+	Frees int
+	// Live is the number of device handles which have not been freed.
+	Live int
+}
+
+// Platform is an in-memory platform.
+type Platform struct {
+	name    string
+	devices []*Device
+
+	mu        sync.Mutex
+	counts    Counts
+	oomAt     int
+	sendErrs  map[int]error
+	toHostErr map[int]error
+	released  bool
+}
+
+var (
+	_ platform.Platform  = (*Platform)(nil)
+	_ platform.Allocator = (*Platform)(nil)
+)
+
+// New returns an in-memory platform with a given number of devices.
+func New(numDevices int) *Platform {
+	p := &Platform{
+		name:      "mock",
+		sendErrs:  make(map[int]error),
+		toHostErr: make(map[int]error),
+	}
+	for i := range numDevices {
+		p.devices = append(p.devices, &Device{plat: p, ordinal: i})
+	}
+	return p
+}
+
+// Name of the platform.
+func (p *Platform) Name() string {
+	return p.name
+}
+
+// Device returns a device given its ordinal.
+func (p *Platform) Device(ordinal int) (platform.Device, error) {
+	if ordinal < 0 || ordinal >= len(p.devices) {
+		return nil, errors.Errorf("device %d out of range [0, %d)", ordinal, len(p.devices))
+	}
+	return p.devices[ordinal], nil
+}
+
+// MockDevice returns the mock device given its ordinal.
+func (p *Platform) MockDevice(ordinal int) *Device {
+	return p.devices[ordinal]
+}
+
+// NumDevices returns the number of devices of the platform.
+func (p *Platform) NumDevices() int {
+	return len(p.devices)
+}
+
+// Devices returns all the devices of the platform.
+func (p *Platform) Devices() ([]platform.Device, error) {
+	devices := make([]platform.Device, len(p.devices))
+	for i, dev := range p.devices {
+		devices[i] = dev
+	}
+	return devices, nil
+}
+
+// DefaultDevice returns the first device of the platform.
+func (p *Platform) DefaultDevice() (platform.Device, error) {
+	return platform.SelectDevice(p, nil)
+}
+
+// Close the platform.
+func (p *Platform) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.released = true
+	return nil
+}
+
+// Release the platform.
+//
+// Deprecated: use Close.
+func (p *Platform) Release() error {
+	return p.Close()
+}
+
+// Released returns true if Close has been called.
+func (p *Platform) Released() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.released
+}
+
+// FailAllocationAt makes the nth allocation (counting from 1, including past allocations)
+// fail with an out of memory error.
+func (p *Platform) FailAllocationAt(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.oomAt = n
+}
+
+// FailSendAt makes the nth transfer to a device (counting from 1, including past transfers)
+// fail with the given error.
+func (p *Platform) FailSendAt(n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sendErrs[n] = err
+}
+
+// FailToHostAt makes the nth transfer to the host (counting from 1, including past transfers)
+// fail with the given error.
+func (p *Platform) FailToHostAt(n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.toHostErr[n] = err
+}
+
+// Counts returns the number of operations executed by the platform.
+func (p *Platform) Counts() Counts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.counts
+}
+
+// Allocate a host buffer in Go memory.
+func (p *Platform) Allocate(sh *shape.Shape, opts ...platform.AllocOption) (platform.HostBuffer, error) {
+	p.mu.Lock()
+	p.counts.Allocations++
+	fail := p.counts.Allocations == p.oomAt
+	p.mu.Unlock()
+	if fail {
+		return nil, &platform.OutOfMemoryError{Device: -1, Requested: int64(sh.ByteSize()), Available: -1}
+	}
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	data, err := platform.AlignedBytes(sh.ByteSize(), platform.NewAllocOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+	return platform.Borrow(data, sh)
+}
+
+func (p *Platform) sendError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts.Sends++
+	return p.sendErrs[p.counts.Sends]
+}
+
+func (p *Platform) toHostError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts.ToHosts++
+	return p.toHostErr[p.counts.ToHosts]
+}
+
+func (p *Platform) updateLive(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts.Live += delta
+	if delta < 0 {
+		p.counts.Frees -= delta
+	}
+}
+
+// Device is an in-memory device.
+type Device struct {
+	plat    *Platform
+	ordinal int
+
+	mu     sync.Mutex
+	health error
+	clock  time.Duration
+}
+
+var _ platform.Device = (*Device)(nil)
+
+// Platform owning the device.
+func (d *Device) Platform() platform.Platform {
+	return d.plat
+}
+
+// Send copies data into a new device handle.
+func (d *Device) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	if err := d.plat.sendError(); err != nil {
+		return nil, err
+	}
+	if err := d.HealthCheck(); err != nil {
+		return nil, err
+	}
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	if len(buf) != sh.ByteSize() {
+		return nil, errors.Wrapf(platform.ErrInvalidShape, "got %d bytes for shape %s: want %d bytes", len(buf), sh, sh.ByteSize())
+	}
+	d.plat.updateLive(1)
+	return &Handle{dev: d, shape: sh, data: slices.Clone(buf)}, nil
+}
+
+// Ordinal of the device.
+func (d *Device) Ordinal() int {
+	return d.ordinal
+}
+
+// Description of the device.
+func (d *Device) Description() *platform.Description {
+	return &platform.Description{
+		Kind:    platform.UnknownDevice,
+		Name:    d.plat.name,
+		Vendor:  "gx",
+		Ordinal: d.ordinal,
+	}
+}
+
+// SetHealth sets the error returned by HealthCheck.
+// Transfers to the device fail while the device is not healthy.
+func (d *Device) SetHealth(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.health = err
+}
+
+// HealthCheck returns the error set by SetHealth.
+func (d *Device) HealthCheck() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.health
+}
+
+// SetClock sets the timestamp returned by Now.
+func (d *Device) SetClock(now time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = now
+}
+
+// Now returns the timestamp set by SetClock.
+func (d *Device) Now() (time.Duration, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.clock, nil
+}
+
+// Reset makes the device healthy again.
+func (d *Device) Reset() error {
+	d.SetHealth(nil)
+	return nil
+}
+
+// Handle is an array stored by an in-memory device.
+type Handle struct {
+	platform.Labeled
+	dev   *Device
+	shape *shape.Shape
+	data  []byte
+	freed atomic.Bool
+}
+
+var _ platform.DeviceHandle = (*Handle)(nil)
+
+// Shape of the array.
+func (h *Handle) Shape() *shape.Shape {
+	return h.shape
+}
+
+// Device storing the array.
+func (h *Handle) Device() platform.Device {
+	return h.dev
+}
+
+// Data returns the content of the array.
+func (h *Handle) Data() []byte {
+	return h.data
+}
+
+// ToDevice copies the array to another device.
+func (h *Handle) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	if h.freed.Load() {
+		return nil, errors.Errorf("cannot transfer a freed handle")
+	}
+	return dev.Send(h.data, h.shape)
+}
+
+// ToHost copies the array into a host buffer.
+func (h *Handle) ToHost(dst platform.HostBuffer) error {
+	if err := h.dev.plat.toHostError(); err != nil {
+		return err
+	}
+	if h.freed.Load() {
+		return errors.Errorf("cannot transfer a freed handle")
+	}
+	if !dst.Shape().Equal(h.shape) {
+		return errors.Wrapf(platform.ErrInvalidShape, "cannot transfer an array of shape %s to a buffer of shape %s", h.shape, dst.Shape())
+	}
+	return platform.CopyToHostBuffer(dst, h.data)
+}
+
+// Free the array.
+func (h *Handle) Free() {
+	if h.freed.Swap(true) {
+		return
+	}
+	h.dev.plat.updateLive(-1)
+	h.data = nil
+}
+
+// Freed returns true if the handle has been freed.
+func (h *Handle) Freed() bool {
+	return h.freed.Load()
+}
+
+// String returns a description of the handle.
+func (h *Handle) String() string {
+	return fmt.Sprintf("MockHandle(%s,device=%d)", h.shape, h.dev.ordinal)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platformtest_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestRoundTrip(t *testing.T) {
+	plat := platformtest.New(2)
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Vector(dtype.Int32, 3)
+	handle, err := dev.Send(dtype.FromSlice([]int32{1, 2, 3}), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := plat.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.ToHost(buf); err != nil {
+		t.Fatal(err)
+	}
+	got := dtype.ToSlice[int32](buf.Acquire())
+	buf.Release()
+	if want := []int32{1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	handle.Free()
+	if counts := plat.Counts(); counts.Live != 0 || counts.Frees != 1 {
+		t.Errorf("unexpected counts after free: %+v", counts)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	sh := shape.Scalar(dtype.Float32)
+	plat.FailAllocationAt(2)
+	if _, err := plat.Allocate(sh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plat.Allocate(sh); !errors.Is(err, platform.ErrOutOfMemory) {
+		t.Errorf("got error %v but want %v", err, platform.ErrOutOfMemory)
+	}
+	sendErr := errors.New("link down")
+	plat.FailSendAt(1, sendErr)
+	if _, err := dev.Send(make([]byte, 4), sh); err != sendErr {
+		t.Errorf("got error %v but want %v", err, sendErr)
+	}
+	dev.SetHealth(platform.ErrDeviceLost)
+	if _, err := dev.Send(make([]byte, 4), sh); !errors.Is(err, platform.ErrDeviceLost) {
+		t.Errorf("got error %v but want %v", err, platform.ErrDeviceLost)
+	}
+	if err := dev.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dev.Send(make([]byte, 4), sh); err != nil {
+		t.Errorf("send after reset: %v", err)
+	}
+}
+
+func TestSyncClock(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	dev.SetClock(time.Hour)
+	before := time.Now()
+	sync, err := platform.SyncClock(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+	got := sync.HostTime(time.Hour)
+	if got.Before(before) || got.After(after) {
+		t.Errorf("device time mapped to %v: want between %v and %v", got, before, after)
+	}
+}
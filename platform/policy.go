@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "github.com/pkg/errors"
+
+type (
+	// DevicePolicy selects a device among the devices of a platform.
+	DevicePolicy func(devices []Device) (Device, error)
+
+	// LoadReporter is implemented by devices reporting how busy they are.
+	LoadReporter interface {
+		Device
+
+		// Load returns the number of operations enqueued on the device and not completed yet.
+		Load() int
+	}
+
+	// FreeMemoryReporter is implemented by devices reporting their available memory.
+	FreeMemoryReporter interface {
+		Device
+
+		// FreeMemory returns the number of bytes currently available on the device.
+		FreeMemory() int64
+	}
+)
+
+// SelectDevice selects a device of a platform using a policy.
+// The device with the lowest ordinal is selected if the policy is nil.
+func SelectDevice(plat Platform, policy DevicePolicy) (Device, error) {
+	devices, err := plat.Devices()
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, errors.Errorf("platform %s has no device", plat.Name())
+	}
+	if policy == nil {
+		return devices[0], nil
+	}
+	return policy(devices)
+}
+
+// ByOrdinal selects the device with a given ordinal.
+func ByOrdinal(ordinal int) DevicePolicy {
+	return func(devices []Device) (Device, error) {
+		for _, dev := range devices {
+			if dev.Ordinal() == ordinal {
+				return dev, nil
+			}
+		}
+		return nil, errors.Errorf("no device with ordinal %d", ordinal)
+	}
+}
+
+// LeastLoaded selects the device with the smallest load.
+// Devices not implementing LoadReporter are considered idle.
+func LeastLoaded() DevicePolicy {
+	return func(devices []Device) (Device, error) {
+		return selectMin(devices, func(dev Device) int64 {
+			if reporter, ok := dev.(LoadReporter); ok {
+				return int64(reporter.Load())
+			}
+			return 0
+		})
+	}
+}
+
+// MostFreeMemory selects the device with the most available memory.
+// Devices not implementing FreeMemoryReporter are selected last.
+func MostFreeMemory() DevicePolicy {
+	return func(devices []Device) (Device, error) {
+		return selectMin(devices, func(dev Device) int64 {
+			if reporter, ok := dev.(FreeMemoryReporter); ok {
+				return -reporter.FreeMemory()
+			}
+			return 0
+		})
+	}
+}
+
+// selectMin returns the first device with the minimum cost.
+func selectMin(devices []Device, cost func(Device) int64) (Device, error) {
+	if len(devices) == 0 {
+		return nil, errors.Errorf("no device to select from")
+	}
+	best, bestCost := devices[0], cost(devices[0])
+	for _, dev := range devices[1:] {
+		if c := cost(dev); c < bestCost {
+			best, bestCost = dev, c
+		}
+	}
+	return best, nil
+}
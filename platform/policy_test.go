@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+type loadDevice struct {
+	*platformtest.Device
+	load int
+}
+
+func (d loadDevice) Load() int {
+	return d.load
+}
+
+type freeMemoryDevice struct {
+	*platformtest.Device
+	free int64
+}
+
+func (d freeMemoryDevice) FreeMemory() int64 {
+	return d.free
+}
+
+func TestDevicePolicies(t *testing.T) {
+	plat := platformtest.New(4)
+	dev := plat.MockDevice
+	tests := []struct {
+		name    string
+		policy  platform.DevicePolicy
+		devices []platform.Device
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "by ordinal",
+			policy:  platform.ByOrdinal(2),
+			devices: []platform.Device{dev(0), dev(1), dev(2), dev(3)},
+			want:    2,
+		},
+		{
+			name:    "by missing ordinal",
+			policy:  platform.ByOrdinal(5),
+			devices: []platform.Device{dev(0), dev(1)},
+			wantErr: true,
+		},
+		{
+			name:   "least loaded",
+			policy: platform.LeastLoaded(),
+			devices: []platform.Device{
+				loadDevice{dev(0), 3},
+				loadDevice{dev(1), 1},
+				loadDevice{dev(2), 2},
+			},
+			want: 1,
+		},
+		{
+			name:   "least loaded tie",
+			policy: platform.LeastLoaded(),
+			devices: []platform.Device{
+				loadDevice{dev(0), 2},
+				loadDevice{dev(1), 1},
+				loadDevice{dev(2), 1},
+			},
+			want: 1,
+		},
+		{
+			name:   "least loaded without reporter",
+			policy: platform.LeastLoaded(),
+			devices: []platform.Device{
+				loadDevice{dev(0), 1},
+				dev(1),
+				loadDevice{dev(2), 0},
+			},
+			want: 1,
+		},
+		{
+			name:    "least loaded without device",
+			policy:  platform.LeastLoaded(),
+			wantErr: true,
+		},
+		{
+			name:   "most free memory",
+			policy: platform.MostFreeMemory(),
+			devices: []platform.Device{
+				freeMemoryDevice{dev(0), 1 << 20},
+				freeMemoryDevice{dev(1), 4 << 20},
+				freeMemoryDevice{dev(2), 2 << 20},
+			},
+			want: 1,
+		},
+		{
+			name:   "most free memory without reporter",
+			policy: platform.MostFreeMemory(),
+			devices: []platform.Device{
+				dev(0),
+				freeMemoryDevice{dev(1), 1},
+				dev(2),
+			},
+			want: 1,
+		},
+		{
+			name:    "most free memory without device",
+			policy:  platform.MostFreeMemory(),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.policy(test.devices)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error but got device %d", got.Ordinal())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Ordinal() != test.want {
+				t.Errorf("got device %d but want %d", got.Ordinal(), test.want)
+			}
+		})
+	}
+}
+
+func TestSelectDevice(t *testing.T) {
+	plat := platformtest.New(3)
+	dev, err := platform.SelectDevice(plat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.Ordinal() != 0 {
+		t.Errorf("got default device %d but want 0", dev.Ordinal())
+	}
+	if dev, err = platform.SelectDevice(plat, platform.ByOrdinal(2)); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Ordinal() != 2 {
+		t.Errorf("got device %d but want 2", dev.Ordinal())
+	}
+	if _, err := platform.SelectDevice(platformtest.New(0), nil); err == nil {
+		t.Errorf("expected an error when selecting a device of a platform without device")
+	}
+}
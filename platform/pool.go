@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// PoolAllocator is an allocator recycling freed host buffers.
+	// Buffers are recycled for any shape requiring the same number of bytes,
+	// or only for equal shapes if the pool has been created by NewShapePoolAllocator.
+	// A PoolAllocator is safe for concurrent use.
+	PoolAllocator struct {
+		alloc       Allocator
+		maxRetained int
+		byShape     bool
+
+		mu       sync.Mutex
+		free     map[poolKey][]HostBuffer
+		retained int
+		stats    PoolStats
+	}
+
+	// PoolStats are statistics about the usage of a pool.
+	PoolStats struct {
+		// Hits is the number of allocations served by a recycled buffer.
+		Hits int
+		// Misses is the number of allocations forwarded to the underlying allocator.
+		Misses int
+		// RetainedBytes is the number of bytes currently held by the pool for future allocations.
+		RetainedBytes int
+	}
+
+	poolKey struct {
+		// shape is the hash of the shape of the buffer, or 0 if buffers are recycled by size.
+		shape     uint64
+		size      int
+		pinned    bool
+		alignment int
+		padding   int
+	}
+
+	// pooledBuffer is a host buffer returning its memory to a pool when freed.
+	pooledBuffer struct {
+		HostBuffer
+		pool  *PoolAllocator
+		key   poolKey
+		shape *shape.Shape
+		freed atomic.Bool
+	}
+)
+
+var _ Allocator = (*PoolAllocator)(nil)
+
+// NewPoolAllocator returns an allocator recycling the buffers allocated by alloc.
+// The pool retains at most maxRetained bytes of freed buffers.
+// Buffers freed when the pool is full are freed by the underlying allocator.
+func NewPoolAllocator(alloc Allocator, maxRetained int) *PoolAllocator {
+	return &PoolAllocator{
+		alloc:       alloc,
+		maxRetained: maxRetained,
+		free:        make(map[poolKey][]HostBuffer),
+	}
+}
+
+// NewShapePoolAllocator returns an allocator recycling the buffers allocated by alloc
+// only for allocations of the same shape, for example for the inputs and outputs
+// of recurring requests. The pool retains at most maxRetained bytes of freed buffers.
+func NewShapePoolAllocator(alloc Allocator, maxRetained int) *PoolAllocator {
+	p := NewPoolAllocator(alloc, maxRetained)
+	p.byShape = true
+	return p
+}
+
+// Allocate returns a recycled buffer if one of the same size, or of the same shape,
+// is available. Otherwise, a new buffer is allocated by the underlying allocator.
+func (p *PoolAllocator) Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error) {
+	options := NewAllocOptions(opts...)
+	key := poolKey{
+		size:      sh.ByteSize(),
+		pinned:    options.Pinned,
+		alignment: options.Alignment,
+		padding:   options.Padding,
+	}
+	if p.byShape {
+		key.shape = sh.Hash()
+	}
+	if buf := p.take(key); buf != nil {
+		return &pooledBuffer{HostBuffer: buf, pool: p, key: key, shape: sh}, nil
+	}
+	buf, err := p.alloc.Allocate(sh, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledBuffer{HostBuffer: buf, pool: p, key: key, shape: sh}, nil
+}
+
+func (p *PoolAllocator) take(key poolKey) HostBuffer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bufs := p.free[key]
+	if len(bufs) == 0 {
+		p.stats.Misses++
+		return nil
+	}
+	buf := bufs[len(bufs)-1]
+	p.free[key] = bufs[:len(bufs)-1]
+	p.retained -= key.bytes()
+	p.stats.Hits++
+	return buf
+}
+
+func (p *PoolAllocator) put(key poolKey, buf HostBuffer) {
+	p.mu.Lock()
+	if p.retained+key.bytes() > p.maxRetained {
+		p.mu.Unlock()
+		buf.Free()
+		return
+	}
+	p.free[key] = append(p.free[key], buf)
+	p.retained += key.bytes()
+	p.mu.Unlock()
+}
+
+// HitRate returns the fraction of allocations served by a recycled buffer,
+// or 0 if no allocation has been made.
+func (s PoolStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Stats returns statistics about the pool.
+func (p *PoolAllocator) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.RetainedBytes = p.retained
+	return stats
+}
+
+// Purge frees all the buffers retained by the pool.
+func (p *PoolAllocator) Purge() {
+	p.mu.Lock()
+	free := p.free
+	p.free = make(map[poolKey][]HostBuffer)
+	p.retained = 0
+	p.mu.Unlock()
+	for _, bufs := range free {
+		for _, buf := range bufs {
+			buf.Free()
+		}
+	}
+}
+
+// bytes returns the number of bytes held by a buffer with the given key.
+func (k poolKey) bytes() int {
+	return k.size + k.padding
+}
+
+func (b *pooledBuffer) Shape() *shape.Shape {
+	return b.shape
+}
+
+func (b *pooledBuffer) ToDevice(dev Device) (DeviceHandle, error) {
+	data := b.AcquireRead()
+	defer b.ReleaseRead()
+	return dev.Send(data, b.shape)
+}
+
+func (b *pooledBuffer) ToHost(dst HostBuffer) error {
+	return HostTransfer(dst, b)
+}
+
+func (b *pooledBuffer) IsPinned() bool {
+	return IsPinned(b.HostBuffer)
+}
+
+// Free returns the memory of the buffer to the pool.
+func (b *pooledBuffer) Free() {
+	if b.freed.Swap(true) {
+		return
+	}
+	b.pool.put(b.key, b.HostBuffer)
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type bytesBuffer struct {
+	shape *shape.Shape
+	data  []byte
+	freed *int
+}
+
+func (b *bytesBuffer) Shape() *shape.Shape { return b.shape }
+func (b *bytesBuffer) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	return dev.Send(b.data, b.shape)
+}
+func (b *bytesBuffer) ToHost(dst platform.HostBuffer) error { return platform.HostTransfer(dst, b) }
+func (b *bytesBuffer) Acquire() []byte                      { return b.data }
+func (b *bytesBuffer) Release()                             {}
+func (b *bytesBuffer) AcquireRead() []byte                  { return b.data }
+func (b *bytesBuffer) ReleaseRead()                         {}
+func (b *bytesBuffer) Free()                                { *b.freed++ }
+
+type bytesAllocator struct {
+	allocated, freed int
+}
+
+func (a *bytesAllocator) Allocate(sh *shape.Shape, opts ...platform.AllocOption) (platform.HostBuffer, error) {
+	a.allocated++
+	return &bytesBuffer{shape: sh, data: make([]byte, sh.ByteSize()), freed: &a.freed}, nil
+}
+
+func TestPoolAllocator(t *testing.T) {
+	alloc := &bytesAllocator{}
+	pool := platform.NewPoolAllocator(alloc, 64)
+	first, err := pool.Allocate(shape.Vector(dtype.Float32, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Free()
+	first.Free()
+	// Same number of bytes: the buffer is recycled.
+	second, err := pool.Allocate(shape.Vector(dtype.Float64, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := second.Shape().String(), "[2]float64"; got != want {
+		t.Errorf("recycled buffer has shape %s but want %s", got, want)
+	}
+	if alloc.allocated != 1 {
+		t.Errorf("got %d allocations but want 1", alloc.allocated)
+	}
+	// Too large to be retained.
+	large, err := pool.Allocate(shape.Vector(dtype.Float64, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	large.Free()
+	if alloc.freed != 1 {
+		t.Errorf("got %d buffers freed but want 1", alloc.freed)
+	}
+	second.Free()
+	stats := pool.Stats()
+	if want := (platform.PoolStats{Hits: 1, Misses: 2, RetainedBytes: 16}); stats != want {
+		t.Errorf("got stats %+v but want %+v", stats, want)
+	}
+	pool.Purge()
+	if alloc.freed != 2 || pool.Stats().RetainedBytes != 0 {
+		t.Errorf("purge did not free the retained buffers")
+	}
+}
+
+func TestShapePoolAllocator(t *testing.T) {
+	alloc := &bytesAllocator{}
+	pool := platform.NewShapePoolAllocator(alloc, 64)
+	for _, sh := range []*shape.Shape{
+		shape.Vector(dtype.Float32, 4),
+		shape.Vector(dtype.Float64, 2),
+		shape.Vector(dtype.Float32, 4),
+		shape.Vector(dtype.Float64, 2),
+	} {
+		buf, err := pool.Allocate(sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Free()
+	}
+	if alloc.allocated != 2 {
+		t.Errorf("got %d allocations but want 2", alloc.allocated)
+	}
+	stats := pool.Stats()
+	if want := (platform.PoolStats{Hits: 2, Misses: 2, RetainedBytes: 32}); stats != want {
+		t.Errorf("got stats %+v but want %+v", stats, want)
+	}
+	if got := stats.HitRate(); got != 0.5 {
+		t.Errorf("got hit rate %v but want 0.5", got)
+	}
+}
+
+func TestHeapAllocator(t *testing.T) {
+	alloc := platform.NewHeapAllocator(1 << 10)
+	first, err := alloc.Allocate(shape.Vector(dtype.Float32, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Free()
+	first.Free()
+	// 28 float32 are in the same size class as 30 float32.
+	second, err := alloc.Allocate(shape.Vector(dtype.Float32, 28))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(second.AcquireRead()), 28*dtype.Float32Size; got != want {
+		t.Errorf("got %d bytes but want %d", got, want)
+	}
+	second.ReleaseRead()
+	if got, want := alloc.Stats(), (platform.PoolStats{Hits: 1, Misses: 1}); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+	aligned, err := alloc.Allocate(shape.Vector(dtype.Uint8, 3), platform.Aligned(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := aligned.AcquireRead(); uintptr(unsafe.Pointer(unsafe.SliceData(data)))%64 != 0 {
+		t.Errorf("buffer not aligned on 64 bytes")
+	}
+	aligned.ReleaseRead()
+	second.Free()
+	aligned.Free()
+	if got := alloc.Stats().RetainedBytes; got == 0 {
+		t.Errorf("no byte retained after freeing buffers")
+	}
+	alloc.Purge()
+	if got := alloc.Stats().RetainedBytes; got != 0 {
+		t.Errorf("got %d retained bytes after purge", got)
+	}
+}
+
+func TestAlignedBytes(t *testing.T) {
+	for _, align := range []int{0, 1, 64, 4096} {
+		data, err := platform.AlignedBytes(100, platform.NewAllocOptions(platform.Aligned(align), platform.Padded(28)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 100 || cap(data) != 128 {
+			t.Errorf("alignment %d: got len=%d cap=%d but want len=100 cap=128", align, len(data), cap(data))
+		}
+		if align > 0 && uintptr(unsafe.Pointer(unsafe.SliceData(data)))%uintptr(align) != 0 {
+			t.Errorf("data not aligned on %d bytes", align)
+		}
+	}
+	if _, err := platform.AlignedBytes(10, platform.NewAllocOptions(platform.Aligned(3))); err == nil {
+		t.Errorf("expected an error for an alignment which is not a power of two")
+	}
+}
+
+func TestRetryOnOOM(t *testing.T) {
+	var calls int
+	remove := platform.OnMemoryPressure(func(requested int64) int64 {
+		return requested
+	})
+	defer remove()
+	got, err := platform.RetryOnOOM(16, func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, &platform.OutOfMemoryError{Device: 0, Requested: 16, Available: 8}
+		}
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 42 || calls != 2 {
+		t.Errorf("got %d after %d calls but want 42 after 2 calls", got, calls)
+	}
+}
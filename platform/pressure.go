@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// PressureCallback is called when an allocation fails because memory is exhausted.
+// The callback receives the number of bytes requested and returns the number of bytes
+// it has freed, for example by purging a cache.
+type PressureCallback func(requested int64) (freed int64)
+
+// maxOOMRetries is the number of times an allocation is retried after callbacks freed memory.
+const maxOOMRetries = 3
+
+var pressure struct {
+	mu        sync.Mutex
+	nextID    int
+	callbacks map[int]PressureCallback
+}
+
+// OnMemoryPressure registers a callback called when memory is exhausted
+// and returns a function to remove it.
+func OnMemoryPressure(cb PressureCallback) (remove func()) {
+	pressure.mu.Lock()
+	defer pressure.mu.Unlock()
+	if pressure.callbacks == nil {
+		pressure.callbacks = make(map[int]PressureCallback)
+	}
+	id := pressure.nextID
+	pressure.nextID++
+	pressure.callbacks[id] = cb
+	return func() {
+		pressure.mu.Lock()
+		defer pressure.mu.Unlock()
+		delete(pressure.callbacks, id)
+	}
+}
+
+// NotifyMemoryPressure calls all the registered callbacks and returns the total number of bytes freed.
+// Platforms call it when an allocation fails.
+func NotifyMemoryPressure(requested int64) int64 {
+	pressure.mu.Lock()
+	callbacks := make([]PressureCallback, 0, len(pressure.callbacks))
+	for _, cb := range pressure.callbacks {
+		callbacks = append(callbacks, cb)
+	}
+	pressure.mu.Unlock()
+	var freed int64
+	for _, cb := range callbacks {
+		freed += cb(requested)
+	}
+	return freed
+}
+
+// RetryOnOOM calls alloc and, if it fails with ErrOutOfMemory, notifies the memory pressure
+// callbacks and tries again as long as the callbacks free some memory.
+func RetryOnOOM[T any](requested int64, alloc func() (T, error)) (T, error) {
+	res, err := alloc()
+	for range maxOOMRetries {
+		if err == nil || !errors.Is(err, ErrOutOfMemory) {
+			break
+		}
+		if NotifyMemoryPressure(requested) <= 0 {
+			break
+		}
+		res, err = alloc()
+	}
+	return res, err
+}
+
+// RetryingAllocator retries allocations failing with ErrOutOfMemory
+// after notifying the memory pressure callbacks.
+type RetryingAllocator struct {
+	Allocator
+}
+
+var _ Allocator = RetryingAllocator{}
+
+// Allocate a host buffer, retrying on memory exhaustion.
+func (a RetryingAllocator) Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error) {
+	return RetryOnOOM(int64(sh.ByteSize()), func() (HostBuffer, error) {
+		return a.Allocator.Allocate(sh, opts...)
+	})
+}
+
+// PressureCallback returns a callback purging the pool when memory is exhausted.
+func (p *PoolAllocator) PressureCallback() PressureCallback {
+	return func(int64) int64 {
+		freed := int64(p.Stats().RetainedBytes)
+		p.Purge()
+		return freed
+	}
+}
+
+// PressureCallback returns a callback releasing the retained buffers when memory is exhausted.
+func (a *HeapAllocator) PressureCallback() PressureCallback {
+	return func(int64) int64 {
+		freed := int64(a.Stats().RetainedBytes)
+		a.Purge()
+		return freed
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RecordKind is the kind of activity of a profile record.
+type RecordKind int
+
+const (
+	// KernelRecord is the execution of a kernel on a device.
+	KernelRecord RecordKind = iota
+	// HostToDeviceRecord is a transfer from the host to a device.
+	HostToDeviceRecord
+	// DeviceToHostRecord is a transfer from a device to the host.
+	DeviceToHostRecord
+	// DeviceToDeviceRecord is a transfer between two devices.
+	DeviceToDeviceRecord
+	// HostRecord is an activity on the host, for example the compilation of a graph.
+	HostRecord
+)
+
+func (k RecordKind) String() string {
+	switch k {
+	case KernelRecord:
+		return "kernel"
+	case HostToDeviceRecord:
+		return "host_to_device"
+	case DeviceToHostRecord:
+		return "device_to_host"
+	case DeviceToDeviceRecord:
+		return "device_to_device"
+	case HostRecord:
+		return "host"
+	}
+	return fmt.Sprintf("RecordKind(%d)", int(k))
+}
+
+// ProfileRecord is an activity measured by a profiler.
+type ProfileRecord struct {
+	// Kind of activity.
+	Kind RecordKind
+	// Name of the kernel, or label of the transferred array (see Labeler).
+	Name string
+	// Device is the ordinal of the device running the activity, or -1 for host activities.
+	Device int
+	// Start and End of the activity.
+	Start, End time.Time
+	// Bytes moved by a transfer.
+	Bytes int64
+}
+
+// Duration of the activity.
+func (r ProfileRecord) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// Profiler records the activity of the devices of a platform.
+type Profiler interface {
+	// Start recording activities.
+	Start() error
+	// Stop recording activities and returns all the records since Start.
+	Stop() ([]ProfileRecord, error)
+}
+
+// ProfilerPlatform is implemented by platforms providing a profiler.
+type ProfilerPlatform interface {
+	Platform
+
+	// NewProfiler returns a profiler for all the devices of the platform.
+	NewProfiler() (Profiler, error)
+}
+
+// ErrProfilerUnsupported is returned when a platform does not provide a profiler.
+var ErrProfilerUnsupported = errors.New("profiler not supported")
+
+// NewProfiler returns a profiler for a platform.
+// It returns ErrProfilerUnsupported if the platform does not implement ProfilerPlatform.
+func NewProfiler(plat Platform) (Profiler, error) {
+	prof, ok := plat.(ProfilerPlatform)
+	if !ok {
+		return nil, errors.Wrapf(ErrProfilerUnsupported, "platform %s", plat.Name())
+	}
+	return prof.NewProfiler()
+}
+
+// Collector is a profiler storing records in memory.
+// Platforms can use it to implement Profiler by adding records as activities complete.
+type Collector struct {
+	mu      sync.Mutex
+	active  bool
+	records []ProfileRecord
+}
+
+var _ Profiler = (*Collector)(nil)
+
+// Start recording activities. Records from a previous session are discarded.
+func (c *Collector) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active {
+		return errors.Errorf("profiler already started")
+	}
+	c.active = true
+	c.records = nil
+	return nil
+}
+
+// Active returns true if the collector is recording.
+func (c *Collector) Active() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+// Add a record. The record is ignored if the collector is not recording.
+func (c *Collector) Add(rec ProfileRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active {
+		c.records = append(c.records, rec)
+	}
+}
+
+// Stop recording and returns all the records since Start.
+func (c *Collector) Stop() ([]ProfileRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.active {
+		return nil, errors.Errorf("profiler not started")
+	}
+	c.active = false
+	records := c.records
+	c.records = nil
+	return records, nil
+}
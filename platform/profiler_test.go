@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+func TestCollector(t *testing.T) {
+	var c platform.Collector
+	if _, err := c.Stop(); err == nil {
+		t.Errorf("expected an error when stopping a collector which has not been started")
+	}
+	c.Add(platform.ProfileRecord{Name: "ignored"})
+	if c.Active() {
+		t.Errorf("collector active before Start")
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Active() {
+		t.Errorf("collector not active after Start")
+	}
+	if err := c.Start(); err == nil {
+		t.Errorf("expected an error when starting a collector twice")
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Add(platform.ProfileRecord{Kind: platform.KernelRecord, Name: name, Start: start, End: start.Add(time.Second)})
+		}()
+	}
+	wg.Wait()
+	records, err := c.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, rec := range records {
+		names = append(names, rec.Name)
+		if got := rec.Duration(); got != time.Second {
+			t.Errorf("record %s: got duration %s but want %s", rec.Name, got, time.Second)
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(names, want) {
+		t.Errorf("got records %v but want %v", names, want)
+	}
+	if c.Active() {
+		t.Errorf("collector active after Stop")
+	}
+	c.Add(platform.ProfileRecord{Name: "ignored"})
+	if _, err := c.Stop(); err == nil {
+		t.Errorf("expected an error when stopping a collector twice")
+	}
+	// A new session discards the records of the previous one.
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	c.Add(platform.ProfileRecord{Name: "e"})
+	if records, err = c.Stop(); err != nil || len(records) != 1 || records[0].Name != "e" {
+		t.Errorf("second session: got %v, %v but want a single record e", records, err)
+	}
+}
+
+func TestNewProfilerUnsupported(t *testing.T) {
+	if _, err := platform.NewProfiler(platformtest.New(1)); !errors.Is(err, platform.ErrProfilerUnsupported) {
+		t.Errorf("got error %v but want %v", err, platform.ErrProfilerUnsupported)
+	}
+}
+
+func TestRecordKindString(t *testing.T) {
+	for kind, want := range map[platform.RecordKind]string{
+		platform.KernelRecord:         "kernel",
+		platform.HostToDeviceRecord:   "host_to_device",
+		platform.DeviceToHostRecord:   "device_to_host",
+		platform.DeviceToDeviceRecord: "device_to_device",
+		platform.HostRecord:           "host",
+		platform.RecordKind(42):       "RecordKind(42)",
+	} {
+		if got := kind.String(); got != want {
+			t.Errorf("got %q but want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"context"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// ProgressFunc is called during a transfer with the number of bytes moved so far
+// and the total number of bytes of the transfer.
+type ProgressFunc func(done, total int64)
+
+type (
+	// ProgressSender is implemented by devices reporting the progress of transfers to the device.
+	ProgressSender interface {
+		Device
+
+		// SendContext sends raw data to the device, calling progress as data is moved.
+		// The transfer is aborted when the context is done.
+		SendContext(ctx context.Context, buf []byte, sh *shape.Shape, progress ProgressFunc) (DeviceHandle, error)
+	}
+
+	// ProgressFetcher is implemented by handles reporting the progress of transfers to the host.
+	ProgressFetcher interface {
+		Handle
+
+		// ToHostContext fetches the data into a buffer, calling progress as data is moved.
+		// The transfer is aborted when the context is done.
+		ToHostContext(ctx context.Context, buffer HostBuffer, progress ProgressFunc) error
+	}
+)
+
+// SendContext sends raw data to a device, reporting progress and enforcing the context deadline.
+// If the device does not implement ProgressSender, progress is only reported when the transfer
+// starts and completes. If the context is done before the transfer completes, the error of
+// the context is returned and the handle is freed once the transfer completes.
+func SendContext(ctx context.Context, dev Device, buf []byte, sh *shape.Shape, progress ProgressFunc) (DeviceHandle, error) {
+	if sender, ok := dev.(ProgressSender); ok {
+		return sender.SendContext(ctx, buf, sh, progress)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	total := int64(len(buf))
+	report(progress, 0, total)
+	p := NewPendingHandle()
+	go func() {
+		p.Resolve(dev.Send(buf, sh))
+	}()
+	select {
+	case <-p.Done():
+		handle, err := p.Handle()
+		if err == nil {
+			report(progress, total, total)
+		}
+		return handle, err
+	case <-ctx.Done():
+		go func() {
+			if handle, err := p.Handle(); err == nil {
+				handle.Free()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// ToHostContext fetches the data of a handle into a buffer, reporting progress and enforcing
+// the context deadline. If the handle does not implement ProgressFetcher, progress is only
+// reported when the transfer starts and completes. If the context is done before the transfer
+// completes, the error of the context is returned and the content of the buffer is undefined.
+func ToHostContext(ctx context.Context, h Handle, buffer HostBuffer, progress ProgressFunc) error {
+	if fetcher, ok := h.(ProgressFetcher); ok {
+		return fetcher.ToHostContext(ctx, buffer, progress)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	total := int64(h.Shape().ByteSize())
+	report(progress, 0, total)
+	ev := ToHostAsync(h, buffer)
+	done := make(chan error, 1)
+	go func() { done <- ev.Wait() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			report(progress, total, total)
+		}
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func report(progress ProgressFunc, done, total int64) {
+	if progress != nil {
+		progress(done, total)
+	}
+}
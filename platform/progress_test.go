@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// blockingDevice blocks transfers to the device until release is closed.
+type blockingDevice struct {
+	*platformtest.Device
+	release chan struct{}
+	freed   chan struct{}
+}
+
+func (d *blockingDevice) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	<-d.release
+	handle, err := d.Device.Send(buf, sh)
+	if err != nil {
+		return nil, err
+	}
+	return &signalHandle{DeviceHandle: handle, freed: d.freed}, nil
+}
+
+// signalHandle closes a channel when freed.
+type signalHandle struct {
+	platform.DeviceHandle
+	freed chan struct{}
+}
+
+func (h *signalHandle) Free() {
+	h.DeviceHandle.Free()
+	close(h.freed)
+}
+
+// blockingHandle blocks transfers to the host until release is closed.
+type blockingHandle struct {
+	platform.DeviceHandle
+	release chan struct{}
+}
+
+func (h blockingHandle) ToHost(buf platform.HostBuffer) error {
+	<-h.release
+	return h.DeviceHandle.ToHost(buf)
+}
+
+// progressDevice reports the progress of transfers to the device in two steps.
+type progressDevice struct {
+	*platformtest.Device
+}
+
+func (d progressDevice) SendContext(ctx context.Context, buf []byte, sh *shape.Shape, progress platform.ProgressFunc) (platform.DeviceHandle, error) {
+	total := int64(len(buf))
+	progress(total/2, total)
+	return d.Send(buf, sh)
+}
+
+// progressHandle reports the progress of transfers to the host in two steps.
+type progressHandle struct {
+	platform.DeviceHandle
+}
+
+func (h progressHandle) ToHostContext(ctx context.Context, buf platform.HostBuffer, progress platform.ProgressFunc) error {
+	total := int64(h.Shape().ByteSize())
+	progress(total/2, total)
+	return h.ToHost(buf)
+}
+
+type progressRecorder [][2]int64
+
+func (r *progressRecorder) report(done, total int64) {
+	*r = append(*r, [2]int64{done, total})
+}
+
+func TestSendContext(t *testing.T) {
+	sh := shape.Of(dtype.Int64, 4)
+	data := make([]byte, sh.ByteSize())
+	total := int64(len(data))
+	tests := []struct {
+		name         string
+		dev          func(*platformtest.Device) platform.Device
+		wantProgress progressRecorder
+	}{
+		{
+			name:         "fallback",
+			dev:          func(dev *platformtest.Device) platform.Device { return dev },
+			wantProgress: progressRecorder{{0, total}, {total, total}},
+		},
+		{
+			name:         "progress sender",
+			dev:          func(dev *platformtest.Device) platform.Device { return progressDevice{dev} },
+			wantProgress: progressRecorder{{total / 2, total}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plat := platformtest.New(1)
+			var got progressRecorder
+			handle, err := platform.SendContext(context.Background(), test.dev(plat.MockDevice(0)), data, sh, got.report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			handle.Free()
+			if !slices.Equal(got, test.wantProgress) {
+				t.Errorf("got progress %v but want %v", got, test.wantProgress)
+			}
+		})
+	}
+}
+
+func TestSendContextCancel(t *testing.T) {
+	plat := platformtest.New(1)
+	sh := shape.Of(dtype.Int64, 4)
+	data := make([]byte, sh.ByteSize())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := platform.SendContext(ctx, plat.MockDevice(0), data, sh, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+	if got := plat.Counts().Sends; got != 0 {
+		t.Errorf("got %d transfers with a cancelled context", got)
+	}
+
+	dev := &blockingDevice{Device: plat.MockDevice(0), release: make(chan struct{}), freed: make(chan struct{})}
+	ctx, cancel = context.WithCancel(context.Background())
+	var got progressRecorder
+	go cancel()
+	if _, err := platform.SendContext(ctx, dev, data, sh, got.report); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+	close(dev.release)
+	<-dev.freed
+	if want := (progressRecorder{{0, int64(len(data))}}); !slices.Equal(got, want) {
+		t.Errorf("got progress %v but want %v", got, want)
+	}
+	if live := plat.Counts().Live; live != 0 {
+		t.Errorf("%d handles leaked after cancelling a transfer", live)
+	}
+}
+
+func TestToHostContext(t *testing.T) {
+	plat := platformtest.New(1)
+	vals := []int32{1, 2, 3, 4}
+	sh := shape.Of(dtype.Int32, len(vals))
+	handle, err := plat.MockDevice(0).Send(dtype.CopyFromSlice(vals), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handle.Free()
+	total := int64(sh.ByteSize())
+	tests := []struct {
+		name         string
+		handle       platform.Handle
+		wantProgress progressRecorder
+	}{
+		{name: "fallback", handle: handle, wantProgress: progressRecorder{{0, total}, {total, total}}},
+		{name: "progress fetcher", handle: progressHandle{handle}, wantProgress: progressRecorder{{total / 2, total}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := plat.Allocate(sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer buf.Free()
+			var got progressRecorder
+			if err := platform.ToHostContext(context.Background(), test.handle, buf, got.report); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.wantProgress) {
+				t.Errorf("got progress %v but want %v", got, test.wantProgress)
+			}
+			data := buf.Acquire()
+			defer buf.Release()
+			if got := dtype.ToSlice[int32](data); !slices.Equal(got, vals) {
+				t.Errorf("got %v but want %v", got, vals)
+			}
+		})
+	}
+}
+
+func TestToHostContextCancel(t *testing.T) {
+	plat := platformtest.New(1)
+	sh := shape.Of(dtype.Int32, 4)
+	handle, err := plat.MockDevice(0).Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handle.Free()
+	buf, err := plat.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := platform.ToHostContext(ctx, handle, buf, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+	if got := plat.Counts().ToHosts; got != 0 {
+		t.Errorf("got %d transfers with a cancelled context", got)
+	}
+
+	blocked := blockingHandle{DeviceHandle: handle, release: make(chan struct{})}
+	ctx, cancel = context.WithCancel(context.Background())
+	go cancel()
+	if err := platform.ToHostContext(ctx, blocked, buf, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v but want %v", err, context.Canceled)
+	}
+	close(blocked.release)
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Factory creates a new platform given a configuration.
+type Factory func(Config) (Platform, error)
+
+var registry = struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a platform available by name, for example "cpu" or "cuda".
+// Packages implementing a platform typically call Register from an init function.
+// Register panics if a platform is registered twice with the same name.
+func Register(name string, factory Factory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if factory == nil {
+		panic("platform: Register factory is nil for platform " + name)
+	}
+	if _, dup := registry.factories[name]; dup {
+		panic("platform: Register called twice for platform " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// Registered returns the sorted names of all the registered platforms.
+func Registered() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New creates a registered platform given its name and configuration options.
+func New(name string, opts ...Option) (Platform, error) {
+	return NewWithConfig(name, NewConfig(opts...))
+}
+
+// NewWithConfig creates a registered platform given its name and a configuration.
+func NewWithConfig(name string, cfg Config) (Platform, error) {
+	registry.mu.RLock()
+	factory, ok := registry.factories[name]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("platform %q not registered: available platforms are %v", name, Registered())
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, errors.Errorf("cannot create platform %q: %v", name, err)
+	}
+	plat, err := factory(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create platform %q", name)
+	}
+	return plat, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+var (
+	errFactory     = errors.New("factory failed")
+	registryConfig platform.Config
+)
+
+func init() {
+	platform.Register("registrytest", func(cfg platform.Config) (platform.Platform, error) {
+		registryConfig = cfg
+		return platformtest.New(1), nil
+	})
+	platform.Register("registrytest_error", func(platform.Config) (platform.Platform, error) {
+		return nil, errFactory
+	})
+}
+
+func panics(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return false
+}
+
+func TestRegister(t *testing.T) {
+	names := platform.Registered()
+	for _, name := range []string{"cpu", "registrytest", "registrytest_error"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("platform %q not in registered platforms %v", name, names)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("registered platforms %v not sorted", names)
+	}
+	factory := func(platform.Config) (platform.Platform, error) { return nil, nil }
+	if !panics(func() { platform.Register("registrytest", factory) }) {
+		t.Errorf("registering a platform twice did not panic")
+	}
+	if !panics(func() { platform.Register("registrytest_nil", nil) }) {
+		t.Errorf("registering a nil factory did not panic")
+	}
+}
+
+func TestNew(t *testing.T) {
+	plat, err := platform.New("registrytest", platform.WithMemoryLimit(1<<20), platform.WithDeterminism())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	if registryConfig.MemoryLimit != 1<<20 || !registryConfig.Deterministic {
+		t.Errorf("got config %+v", registryConfig)
+	}
+	tests := []struct {
+		name     string
+		platform string
+		opts     []platform.Option
+		wantErr  error
+	}{
+		{name: "unknown platform", platform: "unknown"},
+		{name: "invalid config", platform: "registrytest", opts: []platform.Option{platform.WithMemoryLimit(-1)}},
+		{name: "factory error", platform: "registrytest_error", wantErr: errFactory},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := platform.New(test.platform, test.opts...)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v but want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/gx-org/backend/stablehlo"
+)
+
+// Backend builds StableHLO graphs compiled and run by a remote server.
+type Backend struct {
+	plat *Platform
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+// NewBackend returns a backend compiling graphs on the server of a remote platform.
+// The server must have been created with NewBackendServer.
+// The platform is closed when the backend is closed.
+func NewBackend(plat *Platform) *Backend {
+	return &Backend{plat: plat}
+}
+
+// Platform of the backend.
+func (b *Backend) Platform() platform.Platform {
+	return b.plat
+}
+
+// NewOps returns a new StableHLO graph. Options are ignored.
+func (b *Backend) NewOps(name string, opts ...ops.GraphOption) (ops.Graph, error) {
+	return stablehlo.New(b.plat, name, compiler{plat: b.plat}), nil
+}
+
+// Capabilities of the backend. The server does not report which operations
+// its backend supports: unsupported operations fail when the graph is compiled.
+func (b *Backend) Capabilities() ops.Capabilities {
+	return ops.AllCapabilities{}
+}
+
+// Close the backend and its platform.
+func (b *Backend) Close() error {
+	return b.plat.Close()
+}
+
+// Release the backend.
+//
+// Deprecated: use Close.
+func (b *Backend) Release() error {
+	return b.Close()
+}
+
+// compiler sends StableHLO modules to the server of a platform.
+type compiler struct {
+	plat *Platform
+}
+
+func (c compiler) Compile(dev platform.Device, mod *stablehlo.Module) (ops.Runner, error) {
+	runner, err := c.plat.Compile(dev, &protobuf.Program{
+		Name:    mod.Name,
+		Module:  []byte(mod.Text),
+		Params:  mod.Params,
+		Outputs: mod.Outputs,
+		Traced:  mod.Traced,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Platform is a client forwarding device operations to a remote server.
+type Platform struct {
+	life    platform.Lifecycle
+	client  *client
+	name    string
+	devices []*Device
+}
+
+var _ platform.Platform = (*Platform)(nil)
+
+// Dial connects to a server listening on a TCP address.
+func Dial(address string) (*Platform, error) {
+	c := newClient("http://"+address, &http.Transport{}, func() error { return nil })
+	plat, err := newPlatform(c)
+	if err != nil {
+		return nil, errors.Errorf("cannot connect to remote platform %s: %v", address, err)
+	}
+	return plat, nil
+}
+
+// NewClient returns a platform communicating with a server over an existing connection.
+func NewClient(conn net.Conn) (*Platform, error) {
+	var dialed atomic.Bool
+	transport := &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			if dialed.Swap(true) {
+				return nil, errors.Errorf("connection to remote platform closed")
+			}
+			return conn, nil
+		},
+	}
+	plat, err := newPlatform(newClient("http://"+conn.RemoteAddr().String(), transport, conn.Close))
+	if err != nil {
+		return nil, errors.Errorf("cannot query remote platform: %v", err)
+	}
+	return plat, nil
+}
+
+func newPlatform(c *client) (*Platform, error) {
+	reply, err := c.call("Info", nil)
+	if err == nil {
+		var info *protobuf.PlatformInfo
+		if info, err = protobuf.UnmarshalPlatformInfo(reply); err == nil {
+			p := &Platform{client: c, name: info.Name}
+			for _, desc := range info.Devices {
+				p.devices = append(p.devices, &Device{plat: p, desc: *desc})
+			}
+			return p, nil
+		}
+	}
+	c.close()
+	return nil, err
+}
+
+// call calls a method of the server with an encoded request and returns the encoded reply.
+func (p *Platform) call(method string, req []byte) ([]byte, error) {
+	if err := p.life.Enter(); err != nil {
+		return nil, err
+	}
+	defer p.life.Exit()
+	return p.client.call(method, req)
+}
+
+// Name of the platform, prefixed with "remote:".
+func (p *Platform) Name() string {
+	return "remote:" + p.name
+}
+
+// Device returns a remote device given its ordinal.
+func (p *Platform) Device(ordinal int) (platform.Device, error) {
+	if ordinal < 0 || ordinal >= len(p.devices) {
+		return nil, errors.Errorf("device %d out of range [0, %d)", ordinal, len(p.devices))
+	}
+	return p.devices[ordinal], nil
+}
+
+// NumDevices returns the number of devices of the remote platform.
+func (p *Platform) NumDevices() int {
+	return len(p.devices)
+}
+
+// Devices returns all the remote devices.
+func (p *Platform) Devices() ([]platform.Device, error) {
+	devices := make([]platform.Device, len(p.devices))
+	for i, dev := range p.devices {
+		devices[i] = dev
+	}
+	return devices, nil
+}
+
+// DefaultDevice returns the first remote device.
+func (p *Platform) DefaultDevice() (platform.Device, error) {
+	return platform.SelectDevice(p, nil)
+}
+
+// Close closes the connection to the server.
+// The handles held by the server for this client are released when the server shuts down.
+func (p *Platform) Close() error {
+	return p.life.Close(p.client.close)
+}
+
+// Release closes the connection to the server.
+//
+// Deprecated: use Close.
+func (p *Platform) Release() error {
+	return p.Close()
+}
+
+// Device is a device of a remote platform.
+type Device struct {
+	plat *Platform
+	desc platform.Description
+}
+
+var _ platform.Device = (*Device)(nil)
+
+// Platform owning the device.
+func (d *Device) Platform() platform.Platform {
+	return d.plat
+}
+
+// Send transfers data to the remote device.
+func (d *Device) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(platform.ErrInvalidShape, err.Error())
+	}
+	req := &protobuf.SendRequest{Device: d.desc.Ordinal, Shape: sh, Data: buf}
+	reply, err := d.plat.call("Send", req.Marshal())
+	if err == nil {
+		var h *protobuf.Handle
+		if h, err = protobuf.UnmarshalHandle(reply); err == nil {
+			return &Handle{dev: d, id: h.ID, shape: sh}, nil
+		}
+	}
+	return nil, errors.Errorf("cannot send data to remote device %d: %v", d.desc.Ordinal, err)
+}
+
+// Ordinal of the device on the remote platform.
+func (d *Device) Ordinal() int {
+	return d.desc.Ordinal
+}
+
+// Description of the remote device.
+func (d *Device) Description() *platform.Description {
+	desc := d.desc
+	return &desc
+}
+
+// HealthCheck checks the health of the remote device.
+func (d *Device) HealthCheck() error {
+	if _, err := d.plat.call("HealthCheck", d.ref()); err != nil {
+		return errors.Wrap(platform.ErrDeviceLost, err.Error())
+	}
+	return nil
+}
+
+// Reset resets the remote device.
+func (d *Device) Reset() error {
+	_, err := d.plat.call("Reset", d.ref())
+	return err
+}
+
+// Now returns the timestamp of the remote device clock.
+// The timestamp includes the latency of the network: use platform.SyncClock to estimate it.
+func (d *Device) Now() (time.Duration, error) {
+	reply, err := d.plat.call("Now", d.ref())
+	if err != nil {
+		return 0, err
+	}
+	ts, err := protobuf.UnmarshalTimestamp(reply)
+	if err != nil {
+		return 0, err
+	}
+	return ts.Now, nil
+}
+
+// ref returns the request identifying the device on the server.
+func (d *Device) ref() []byte {
+	return (&protobuf.DeviceRef{Device: d.desc.Ordinal}).Marshal()
+}
+
+// Handle is an array stored on a remote device.
+type Handle struct {
+	platform.Labeled
+	dev   *Device
+	id    uint64
+	shape *shape.Shape
+	freed atomic.Bool
+}
+
+var _ platform.DeviceHandle = (*Handle)(nil)
+
+// Shape of the array.
+func (h *Handle) Shape() *shape.Shape {
+	return h.shape
+}
+
+// Device storing the array.
+func (h *Handle) Device() platform.Device {
+	return h.dev
+}
+
+// ToDevice transfers the array to a device.
+// Arrays are fetched from the server and sent to the destination device.
+func (h *Handle) ToDevice(dev platform.Device) (platform.DeviceHandle, error) {
+	data, err := h.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return dev.Send(data, h.shape)
+}
+
+// ToHost fetches the array from the server into a host buffer.
+func (h *Handle) ToHost(dst platform.HostBuffer) error {
+	if !dst.Shape().Equal(h.shape) {
+		return errors.Wrapf(platform.ErrInvalidShape, "cannot transfer an array of shape %s to a buffer of shape %s", h.shape, dst.Shape())
+	}
+	data, err := h.fetch()
+	if err != nil {
+		return err
+	}
+	return platform.CopyToHostBuffer(dst, data)
+}
+
+func (h *Handle) fetch() ([]byte, error) {
+	if h.freed.Load() {
+		return nil, errors.Errorf("cannot transfer a freed handle")
+	}
+	reply, err := h.dev.plat.call("ToHost", h.ref())
+	if err == nil {
+		var data *protobuf.HostData
+		if data, err = protobuf.UnmarshalHostData(reply); err == nil {
+			return data.Data, nil
+		}
+	}
+	return nil, errors.Errorf("cannot fetch data from remote device %d: %v", h.dev.desc.Ordinal, err)
+}
+
+// ref returns the request identifying the handle on the server.
+func (h *Handle) ref() []byte {
+	return (&protobuf.Handle{ID: h.id}).Marshal()
+}
+
+// Free releases the array on the server.
+func (h *Handle) Free() {
+	if h.freed.Swap(true) {
+		return
+	}
+	// The error is ignored: the server releases all handles when it shuts down.
+	_, _ = h.dev.plat.call("Free", h.ref())
+}
+
+// String returns a description of the handle.
+func (h *Handle) String() string {
+	return fmt.Sprintf("RemoteHandle(%s,device=%d)", h.shape, h.dev.desc.Ordinal)
+}
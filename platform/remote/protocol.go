@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remote exposes a platform running on another machine.
+//
+// A Server wraps any local platform and serves its devices over a network
+// connection. A client Platform, returned by Dial, forwards device operations
+// (Send, ToHost, Free, health checks) to the server such that remote devices
+// can be used like local ones.
+//
+// A server created with NewBackendServer also compiles and runs graphs with its backend.
+// Graphs are shipped as protobuf.Program messages storing a StableHLO module (see the
+// portable package). A client Backend builds StableHLO graphs and compiles them on the
+// server, such that GX programs can run on the devices of another machine.
+//
+// Clients and servers communicate with gRPC: the server implements the Platform service
+// defined in protobuf/platform.proto, such that clients can be written in any language.
+// The transport only uses the HTTP/2 support of the standard library, without TLS.
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// serviceName is the full name of the gRPC service defined in protobuf/platform.proto.
+const serviceName = "gx.backend.Platform"
+
+// contentType is the content type of gRPC requests and responses with protobuf messages.
+const contentType = "application/grpc+proto"
+
+// gRPC status codes.
+const (
+	codeOK              = 0
+	codeUnknown         = 2
+	codeInvalidArgument = 3
+	codeUnimplemented   = 12
+)
+
+// h2c returns the protocols of clients and servers: HTTP/2 without TLS.
+func h2c() *http.Protocols {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	return &protocols
+}
+
+// frame prefixes a message with the header of the gRPC framing: a flag marking
+// compressed messages and the length of the message.
+func frame(msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// unframe returns the single message of the body of a request or a response.
+func unframe(body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, errors.Errorf("gRPC message of %d bytes too short", len(body))
+	}
+	if body[0] != 0 {
+		return nil, errors.Errorf("compressed gRPC messages not supported")
+	}
+	if n := binary.BigEndian.Uint32(body[1:]); uint64(n) != uint64(len(body)-5) {
+		return nil, errors.Errorf("gRPC message of %d bytes but the body stores %d bytes", n, len(body)-5)
+	}
+	return body[5:], nil
+}
+
+// encodeStatusMessage percent-encodes a status message as specified by gRPC.
+func encodeStatusMessage(msg string) string {
+	var b strings.Builder
+	for i := range len(msg) {
+		if c := msg[i]; c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// statusError is an error returned by the server.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (err *statusError) Error() string {
+	if err.code == codeUnknown {
+		return err.message
+	}
+	return fmt.Sprintf("%s (gRPC status %d)", err.message, err.code)
+}
+
+// client calls the methods of the service over HTTP/2.
+type client struct {
+	http *http.Client
+	// base is the URL of the server.
+	base  string
+	close func() error
+}
+
+func newClient(base string, transport *http.Transport, close func() error) *client {
+	transport.Protocols = h2c()
+	return &client{
+		http: &http.Client{Transport: transport},
+		base: base,
+		close: func() error {
+			transport.CloseIdleConnections()
+			return close()
+		},
+	}
+}
+
+// call calls a method of the service with an encoded request and returns the encoded reply.
+func (c *client) call(method string, req []byte) ([]byte, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, c.base+"/"+serviceName+"/"+method, bytes.NewReader(frame(req)))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("TE", "trailers")
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	status, msg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		// Responses without message can store the status in their headers.
+		status, msg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return nil, errors.Errorf("invalid gRPC status %q", status)
+	}
+	if code != codeOK {
+		if decoded, err := url.PathUnescape(msg); err == nil {
+			msg = decoded
+		}
+		return nil, &statusError{code: code, message: msg}
+	}
+	return unframe(body)
+}
+
+// connListener is a listener accepting a single connection.
+// It is closed when the connection is closed.
+type connListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	addr  net.Addr
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	l := &connListener{
+		conns: make(chan net.Conn, 1),
+		done:  make(chan struct{}),
+		addr:  conn.LocalAddr(),
+	}
+	l.conns <- &notifyConn{Conn: conn, done: l.done}
+	return l
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error {
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
+
+// notifyConn closes a channel when the connection is closed.
+type notifyConn struct {
+	net.Conn
+	once sync.Once
+	done chan struct{}
+}
+
+func (c *notifyConn) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote_test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/array"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/goeval"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/platform/remote"
+	"github.com/gx-org/backend/shape"
+)
+
+// connect returns a client platform connected to a server.
+func connect(t *testing.T, server *remote.Server) *remote.Platform {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	go server.ServeConn(serverConn)
+	plat, err := remote.NewClient(clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plat
+}
+
+func TestRemote(t *testing.T) {
+	local := platformtest.New(2)
+	server, err := remote.NewServer(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plat := connect(t, server)
+	defer plat.Close()
+	if got := plat.NumDevices(); got != 2 {
+		t.Fatalf("got %d devices but want 2", got)
+	}
+	dev, err := plat.Device(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Vector(dtype.Int64, 3)
+	handle, err := dev.Send(dtype.FromSlice([]int64{4, 5, 6}), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := local.Counts().Live; got != 1 {
+		t.Errorf("got %d live handles on the server but want 1", got)
+	}
+	data := make([]byte, sh.ByteSize())
+	buf, err := platform.Borrow(data, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.ToHost(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[int64](data), []int64{4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	handle.Free()
+	if got := server.NumHandles(); got != 0 {
+		t.Errorf("got %d handles on the server after free but want 0", got)
+	}
+	local.MockDevice(1).SetHealth(platform.ErrDeviceLost)
+	if err := dev.HealthCheck(); err == nil {
+		t.Errorf("expected an error from an unhealthy remote device")
+	}
+}
+
+func TestDial(t *testing.T) {
+	server, err := remote.NewServer(platformtest.New(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go server.Serve(lis)
+	plat, err := remote.Dial(lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	if got := plat.NumDevices(); got != 3 {
+		t.Fatalf("got %d devices but want 3", got)
+	}
+	dev, err := plat.Device(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dev.Description().Ordinal; got != 2 {
+		t.Errorf("got device ordinal %d but want 2", got)
+	}
+	if _, err := dev.Now(); err != nil {
+		t.Error(err)
+	}
+	if err := dev.Reset(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	server, err := remote.NewServer(platformtest.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		path   string
+		body   []byte
+		status string
+	}{
+		{name: "unknown method", path: "/gx.backend.Platform/Unknown", body: []byte{0, 0, 0, 0, 0}, status: "12"},
+		{name: "unknown service", path: "/Platform/Info", body: []byte{0, 0, 0, 0, 0}, status: "12"},
+		{name: "invalid frame", path: "/gx.backend.Platform/Info", body: []byte{0, 0, 0}, status: "3"},
+		// Device 5 (field 1) does not exist.
+		{name: "method error", path: "/gx.backend.Platform/Reset", body: []byte{0, 0, 0, 0, 2, 8, 5}, status: "2"},
+		{name: "ok", path: "/gx.backend.Platform/Reset", body: []byte{0, 0, 0, 0, 0}, status: "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, test.path, bytes.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/grpc+proto")
+			server.ServeHTTP(rec, req)
+			resp := rec.Result()
+			if got := resp.Trailer.Get("Grpc-Status"); got != test.status {
+				t.Errorf("got gRPC status %q but want %q", got, test.status)
+			}
+			if msg := resp.Trailer.Get("Grpc-Message"); (msg == "") != (test.status == "0") {
+				t.Errorf("got gRPC message %q for status %s", msg, test.status)
+			}
+		})
+	}
+}
+
+func TestRemoteBackend(t *testing.T) {
+	local, err := goeval.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	server, err := remote.NewBackendServer(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := remote.NewBackend(connect(t, server))
+	defer b.Close()
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := shape.Of(dtype.Float32, 3)
+	x, err := g.Core().Argument("x", param, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.Core().BinaryOp(ops.Add, x, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: sum, Shape: param}}, nil, []*shape.Shape{param})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.NumRunners(); got != 1 {
+		t.Errorf("got %d runners on the server but want 1", got)
+	}
+	remoteArg, err := dev.Send(dtype.FromSlice([]float32{4, 5, 6}), param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remoteArg.Free()
+	hostArg, err := array.New([]float32{1, 2, 3}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		arg  platform.Handle
+		want []float32
+	}{
+		{name: "remote handle", arg: remoteArg, want: []float32{8, 10, 12}},
+		{name: "host buffer", arg: hostArg.HostBuffer(), want: []float32{2, 4, 6}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, traces, err := runner.Run([]platform.Handle{test.arg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) != 1 || len(traces) != 0 {
+				t.Fatalf("got %d outputs and %d traces but want 1 and 0", len(out), len(traces))
+			}
+			if !out[0].Shape().Equal(param) {
+				t.Errorf("got output of shape %s but want %s", out[0].Shape(), param)
+			}
+			res, err := array.FromHandle[float32](out[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			out[0].Free()
+			if got := res.Flat(); !slices.Equal(got, test.want) {
+				t.Errorf("got %v but want %v", got, test.want)
+			}
+		})
+	}
+	if got := server.NumHandles(); got != 1 {
+		t.Errorf("got %d handles on the server but want 1", got)
+	}
+	runner.(*remote.Runner).Free()
+	if got := server.NumRunners(); got != 0 {
+		t.Errorf("got %d runners on the server after free but want 0", got)
+	}
+	if _, _, err := runner.Run([]platform.Handle{remoteArg}); err == nil {
+		t.Errorf("expected an error when running a freed runner")
+	}
+}
+
+func TestRemoteCompileErrors(t *testing.T) {
+	server, err := remote.NewServer(platformtest.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := remote.NewBackend(connect(t, server))
+	defer b.Close()
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := shape.Of(dtype.Float32, 3)
+	x, err := g.Core().Argument("x", param, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := b.Platform().DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: x, Shape: param}}, nil, []*shape.Shape{param}); err == nil {
+		t.Errorf("expected an error when compiling on a server without backend")
+	}
+	if _, err := g.Compile(platformtest.New(1).MockDevice(0), []*ops.OutputNode{{Node: x, Shape: param}}, nil, []*shape.Shape{param}); err == nil {
+		t.Errorf("expected an error when compiling for a device of another platform")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"sync/atomic"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/pkg/errors"
+)
+
+// Runner is a graph compiled on the server.
+type Runner struct {
+	dev   *Device
+	id    uint64
+	freed atomic.Bool
+}
+
+var _ ops.Runner = (*Runner)(nil)
+
+// Compile sends a program to the server, which compiles it for a remote device.
+func (p *Platform) Compile(dev platform.Device, prog *protobuf.Program) (*Runner, error) {
+	rdev, ok := dev.(*Device)
+	if !ok || rdev.plat != p {
+		return nil, errors.Errorf("cannot compile program %s for device %d: not a device of platform %s", prog.Name, dev.Ordinal(), p.Name())
+	}
+	req := &protobuf.CompileRequest{Device: rdev.desc.Ordinal, Program: prog}
+	reply, err := p.call("Compile", req.Marshal())
+	if err == nil {
+		var runner *protobuf.Runner
+		if runner, err = protobuf.UnmarshalRunner(reply); err == nil {
+			return &Runner{dev: rdev, id: runner.ID}, nil
+		}
+	}
+	return nil, errors.Errorf("cannot compile program %s on remote device %d: %v", prog.Name, rdev.desc.Ordinal, err)
+}
+
+// Run runs the compiled graph on the server. Arguments which are not handles
+// of the remote device are sent to the device for the duration of the run.
+func (r *Runner) Run(args []platform.Handle) (out, traces []platform.DeviceHandle, err error) {
+	if r.freed.Load() {
+		return nil, nil, errors.Errorf("cannot run a freed runner")
+	}
+	ids := make([]uint64, len(args))
+	for i, arg := range args {
+		h, ok := arg.(*Handle)
+		if !ok || h.dev != r.dev {
+			sent, err := arg.ToDevice(r.dev)
+			if err != nil {
+				return nil, nil, errors.Errorf("cannot send argument %d to remote device %d: %v", i, r.dev.desc.Ordinal, err)
+			}
+			defer sent.Free()
+			if h, ok = sent.(*Handle); !ok {
+				return nil, nil, errors.Errorf("cannot send argument %d to remote device %d: got handle %T", i, r.dev.desc.Ordinal, sent)
+			}
+		}
+		ids[i] = h.id
+	}
+	req := &protobuf.RunRequest{Runner: r.id, Args: ids}
+	reply, err := r.dev.plat.call("Run", req.Marshal())
+	if err != nil {
+		return nil, nil, errors.Errorf("cannot run graph on remote device %d: %v", r.dev.desc.Ordinal, err)
+	}
+	run, err := protobuf.UnmarshalRunReply(reply)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := append(append([]*protobuf.Handle{}, run.Outputs...), run.Traces...)
+	handles := make([]platform.DeviceHandle, len(results))
+	for i, h := range results {
+		handles[i] = &Handle{dev: r.dev, id: h.ID, shape: h.Shape}
+	}
+	for _, h := range results {
+		if h.Shape == nil {
+			for _, h := range handles {
+				h.Free()
+			}
+			return nil, nil, errors.Errorf("remote run returned handle %d without shape", h.ID)
+		}
+	}
+	return handles[:len(run.Outputs)], handles[len(run.Outputs):], nil
+}
+
+// Free releases the compiled graph on the server.
+func (r *Runner) Free() {
+	if r.freed.Swap(true) {
+		return
+	}
+	// The error is ignored: the server releases all runners when it shuts down.
+	_, _ = r.dev.plat.call("FreeRunner", (&protobuf.Runner{ID: r.id}).Marshal())
+}
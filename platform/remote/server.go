@@ -0,0 +1,366 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/portable"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/pkg/errors"
+)
+
+// Server serves the devices of a local platform to remote clients.
+type Server struct {
+	plat platform.Platform
+	// backend compiles the graphs of clients, nil if the server only serves devices.
+	backend backend.Backend
+	// methods are the methods of the gRPC service, indexed by name.
+	methods map[string]func(req []byte) ([]byte, error)
+
+	mu      sync.Mutex
+	nextID  uint64
+	handles map[uint64]platform.DeviceHandle
+	runners map[uint64]ops.Runner
+}
+
+// NewServer returns a server for a local platform.
+// The server does not compile graphs: see NewBackendServer.
+func NewServer(plat platform.Platform) (*Server, error) {
+	s := &Server{
+		plat:    plat,
+		handles: make(map[uint64]platform.DeviceHandle),
+		runners: make(map[uint64]ops.Runner),
+	}
+	svc := &service{s: s}
+	s.methods = map[string]func([]byte) ([]byte, error){
+		"Info":        svc.Info,
+		"Send":        svc.Send,
+		"ToHost":      svc.ToHost,
+		"Free":        svc.Free,
+		"HealthCheck": svc.HealthCheck,
+		"Reset":       svc.Reset,
+		"Now":         svc.Now,
+		"Compile":     svc.Compile,
+		"Run":         svc.Run,
+		"FreeRunner":  svc.FreeRunner,
+	}
+	return s, nil
+}
+
+// NewBackendServer returns a server for the platform of a local backend.
+// The server compiles and runs the graphs of its clients with the backend.
+func NewBackendServer(b backend.Backend) (*Server, error) {
+	s, err := NewServer(b.Platform())
+	if err != nil {
+		return nil, err
+	}
+	s.backend = b
+	return s, nil
+}
+
+// Serve accepts connections on a listener and serves each connection in a new goroutine.
+// Serve blocks until the listener is closed.
+func (s *Server) Serve(lis net.Listener) {
+	srv := &http.Server{Handler: s, Protocols: h2c()}
+	_ = srv.Serve(lis)
+}
+
+// ServeConn serves a single connection. It blocks until the client hangs up.
+func (s *Server) ServeConn(conn net.Conn) {
+	s.Serve(newConnListener(conn))
+}
+
+// ServeHTTP serves a gRPC request.
+// Servers can be registered on the HTTP/2 server of an application.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	reply, code, err := s.serve(r)
+	if err == nil {
+		_, err = w.Write(frame(reply))
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if err != nil {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeStatusMessage(err.Error()))
+	}
+}
+
+// serve calls the method of a request and returns its reply with a gRPC status code.
+func (s *Server) serve(r *http.Request) ([]byte, int, error) {
+	if r.Method != http.MethodPost {
+		return nil, codeUnimplemented, errors.Errorf("unsupported HTTP method %s", r.Method)
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, "/"+serviceName+"/")
+	method := s.methods[name]
+	if !ok || method == nil {
+		return nil, codeUnimplemented, errors.Errorf("unknown method %s", r.URL.Path)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, codeUnknown, err
+	}
+	req, err := unframe(body)
+	if err != nil {
+		return nil, codeInvalidArgument, err
+	}
+	reply, err := method(req)
+	if err != nil {
+		return nil, codeUnknown, err
+	}
+	return reply, codeOK, nil
+}
+
+// NumHandles returns the number of device handles held by the server for its clients.
+func (s *Server) NumHandles() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.handles)
+}
+
+// NumRunners returns the number of compiled graphs held by the server for its clients.
+func (s *Server) NumRunners() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.runners)
+}
+
+func (s *Server) device(ordinal int) (platform.Device, error) {
+	return s.plat.Device(ordinal)
+}
+
+func (s *Server) handle(id uint64) (platform.DeviceHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.handles[id]
+	if !ok {
+		return nil, errors.Errorf("unknown handle %d", id)
+	}
+	return h, nil
+}
+
+// register stores a device handle created for a client and returns its identifier.
+func (s *Server) register(h platform.DeviceHandle) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handles[s.nextID] = h
+	return s.nextID
+}
+
+func (s *Server) runner(id uint64) (ops.Runner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runners[id]
+	if !ok {
+		return nil, errors.Errorf("unknown runner %d", id)
+	}
+	return r, nil
+}
+
+// service implements the methods of the gRPC service.
+type service struct {
+	s *Server
+}
+
+// Info returns the description of the platform and its devices.
+func (svc *service) Info([]byte) ([]byte, error) {
+	devices, err := svc.s.plat.Devices()
+	if err != nil {
+		return nil, err
+	}
+	info := &protobuf.PlatformInfo{Name: svc.s.plat.Name()}
+	for _, dev := range devices {
+		info.Devices = append(info.Devices, dev.Description())
+	}
+	return info.Marshal(), nil
+}
+
+// Send transfers data to a device.
+func (svc *service) Send(b []byte) ([]byte, error) {
+	req, err := protobuf.UnmarshalSendRequest(b)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := svc.s.device(req.Device)
+	if err != nil {
+		return nil, err
+	}
+	h, err := dev.Send(req.Data, req.Shape)
+	if err != nil {
+		return nil, err
+	}
+	return (&protobuf.Handle{ID: svc.s.register(h)}).Marshal(), nil
+}
+
+// ToHost returns the data of a device handle.
+func (svc *service) ToHost(b []byte) ([]byte, error) {
+	req, err := protobuf.UnmarshalHandle(b)
+	if err != nil {
+		return nil, err
+	}
+	h, err := svc.s.handle(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	sh := h.Shape()
+	data := make([]byte, sh.ByteSize())
+	buf, err := platform.Borrow(data, sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.ToHost(buf); err != nil {
+		return nil, err
+	}
+	return (&protobuf.HostData{Data: data}).Marshal(), nil
+}
+
+// Free releases a device handle.
+func (svc *service) Free(b []byte) ([]byte, error) {
+	req, err := protobuf.UnmarshalHandle(b)
+	if err != nil {
+		return nil, err
+	}
+	svc.s.mu.Lock()
+	h, ok := svc.s.handles[req.ID]
+	delete(svc.s.handles, req.ID)
+	svc.s.mu.Unlock()
+	if ok {
+		h.Free()
+	}
+	return nil, nil
+}
+
+// deviceRef returns the device identified by a request.
+func (svc *service) deviceRef(b []byte) (platform.Device, error) {
+	req, err := protobuf.UnmarshalDeviceRef(b)
+	if err != nil {
+		return nil, err
+	}
+	return svc.s.device(req.Device)
+}
+
+// HealthCheck checks the health of a device.
+func (svc *service) HealthCheck(b []byte) ([]byte, error) {
+	dev, err := svc.deviceRef(b)
+	if err != nil {
+		return nil, err
+	}
+	return nil, dev.HealthCheck()
+}
+
+// Reset resets a device.
+func (svc *service) Reset(b []byte) ([]byte, error) {
+	dev, err := svc.deviceRef(b)
+	if err != nil {
+		return nil, err
+	}
+	return nil, dev.Reset()
+}
+
+// Now returns the timestamp of a device clock.
+func (svc *service) Now(b []byte) ([]byte, error) {
+	dev, err := svc.deviceRef(b)
+	if err != nil {
+		return nil, err
+	}
+	now, err := dev.Now()
+	if err != nil {
+		return nil, err
+	}
+	return (&protobuf.Timestamp{Now: now}).Marshal(), nil
+}
+
+// Compile compiles a graph for a device with the backend of the server.
+func (svc *service) Compile(b []byte) ([]byte, error) {
+	if svc.s.backend == nil {
+		return nil, errors.Errorf("server of platform %s cannot compile graphs", svc.s.plat.Name())
+	}
+	req, err := protobuf.UnmarshalCompileRequest(b)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := svc.s.device(req.Device)
+	if err != nil {
+		return nil, err
+	}
+	prog := req.Program
+	g, err := svc.s.backend.NewOps(prog.Name)
+	if err != nil {
+		return nil, err
+	}
+	output, traced, err := portable.Load(prog)(g)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := g.Compile(dev, output, traced, prog.Params)
+	if err != nil {
+		return nil, err
+	}
+	svc.s.mu.Lock()
+	defer svc.s.mu.Unlock()
+	svc.s.nextID++
+	svc.s.runners[svc.s.nextID] = runner
+	return (&protobuf.Runner{ID: svc.s.nextID}).Marshal(), nil
+}
+
+// Run runs a compiled graph and returns the handles of its outputs and traces.
+func (svc *service) Run(b []byte) ([]byte, error) {
+	req, err := protobuf.UnmarshalRunRequest(b)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := svc.s.runner(req.Runner)
+	if err != nil {
+		return nil, err
+	}
+	handles := make([]platform.Handle, len(req.Args))
+	for i, id := range req.Args {
+		if handles[i], err = svc.s.handle(id); err != nil {
+			return nil, errors.Wrapf(err, "argument %d", i)
+		}
+	}
+	out, traces, err := runner.Run(handles)
+	if err != nil {
+		return nil, err
+	}
+	reply := &protobuf.RunReply{}
+	for _, h := range out {
+		reply.Outputs = append(reply.Outputs, &protobuf.Handle{ID: svc.s.register(h), Shape: h.Shape()})
+	}
+	for _, h := range traces {
+		reply.Traces = append(reply.Traces, &protobuf.Handle{ID: svc.s.register(h), Shape: h.Shape()})
+	}
+	return reply.Marshal(), nil
+}
+
+// FreeRunner releases a compiled graph.
+func (svc *service) FreeRunner(b []byte) ([]byte, error) {
+	req, err := protobuf.UnmarshalRunner(b)
+	if err != nil {
+		return nil, err
+	}
+	svc.s.mu.Lock()
+	defer svc.s.mu.Unlock()
+	delete(svc.s.runners, req.ID)
+	return nil, nil
+}
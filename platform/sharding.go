@@ -0,0 +1,247 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// ShardingSpec specifies how a logical array is split in shards of equal sizes
+// stored on different devices.
+type ShardingSpec struct {
+	// Splits is the number of shards along each axis of the array.
+	// An axis which is not split has a value of 1.
+	Splits []int
+
+	// Devices storing the shards. The shards form a grid with Splits[i] shards along axis i.
+	// Devices lists the device of each shard of the grid in row-major order.
+	Devices []Device
+}
+
+// NumShards returns the total number of shards.
+func (spec *ShardingSpec) NumShards() int {
+	return shape.Size(spec.Splits)
+}
+
+// Check returns an error if the spec cannot be used to shard an array of a given shape.
+func (spec *ShardingSpec) Check(sh *shape.Shape) error {
+	if len(spec.Splits) != len(sh.AxisLengths) {
+		return errors.Errorf("sharding spec with %d splits for an array of rank %d", len(spec.Splits), len(sh.AxisLengths))
+	}
+	for axis, split := range spec.Splits {
+		if split <= 0 {
+			return errors.Errorf("sharding spec has a non-positive split %d for axis %d", split, axis)
+		}
+		if sh.AxisLengths[axis]%split != 0 {
+			return errors.Errorf("axis %d of length %d cannot be split in %d shards of equal sizes", axis, sh.AxisLengths[axis], split)
+		}
+	}
+	if got, want := len(spec.Devices), spec.NumShards(); got != want {
+		return errors.Errorf("sharding spec has %d devices for %d shards", got, want)
+	}
+	if dtype.IsSubByte(sh.DType) || sh.IsBitPacked() || !sh.IsContiguous() {
+		return errors.Errorf("cannot shard an array of shape %s: packed data or non-default layout", sh)
+	}
+	return nil
+}
+
+// ShardShape returns the shape of each shard of an array.
+func (spec *ShardingSpec) ShardShape(sh *shape.Shape) (*shape.Shape, error) {
+	if err := spec.Check(sh); err != nil {
+		return nil, err
+	}
+	axisLengths := make([]int, len(sh.AxisLengths))
+	for axis, length := range sh.AxisLengths {
+		axisLengths[axis] = length / spec.Splits[axis]
+	}
+	return sh.WithAxes(axisLengths...), nil
+}
+
+// ShardOrigin returns the indices, in the logical array, of the first element of the ith shard.
+func (spec *ShardingSpec) ShardOrigin(sh *shape.Shape, i int) []int {
+	gridPos := make([]int, len(spec.Splits))
+	for axis := len(spec.Splits) - 1; axis >= 0; axis-- {
+		gridPos[axis] = i % spec.Splits[axis]
+		i /= spec.Splits[axis]
+	}
+	origin := make([]int, len(gridPos))
+	for axis, pos := range gridPos {
+		origin[axis] = pos * (sh.AxisLengths[axis] / spec.Splits[axis])
+	}
+	return origin
+}
+
+func (spec *ShardingSpec) equal(other *ShardingSpec) bool {
+	return slices.Equal(spec.Splits, other.Splits) && slices.Equal(spec.Devices, other.Devices)
+}
+
+// ShardedHandle is a logical array split in shards stored on multiple devices.
+type ShardedHandle struct {
+	Labeled
+	shape  *shape.Shape
+	spec   *ShardingSpec
+	shards []DeviceHandle
+}
+
+var _ Handle = (*ShardedHandle)(nil)
+
+// NewShardedHandle returns a sharded handle given the handles of its shards.
+func NewShardedHandle(sh *shape.Shape, spec *ShardingSpec, shards []DeviceHandle) (*ShardedHandle, error) {
+	shardShape, err := spec.ShardShape(sh)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) != spec.NumShards() {
+		return nil, errors.Errorf("got %d shards but want %d", len(shards), spec.NumShards())
+	}
+	for i, shard := range shards {
+		if !shard.Shape().Equal(shardShape) {
+			return nil, errors.Errorf("shard %d has shape %s but want %s", i, shard.Shape(), shardShape)
+		}
+	}
+	return &ShardedHandle{shape: sh, spec: spec, shards: slices.Clone(shards)}, nil
+}
+
+// Shard splits an array stored in host memory and sends each shard to its device.
+func Shard(data []byte, sh *shape.Shape, spec *ShardingSpec) (*ShardedHandle, error) {
+	shardShape, err := spec.ShardShape(sh)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != sh.ByteSize() {
+		return nil, errors.Errorf("got %d bytes for an array of shape %s: want %d bytes", len(data), sh, sh.ByteSize())
+	}
+	shards := make([]DeviceHandle, spec.NumShards())
+	buf := make([]byte, shardShape.ByteSize())
+	for i, dev := range spec.Devices {
+		copyShard(buf, data, sh, shardShape, spec.ShardOrigin(sh, i), true)
+		if shards[i], err = dev.Send(buf, shardShape); err != nil {
+			for _, shard := range shards[:i] {
+				shard.Free()
+			}
+			return nil, errors.Wrapf(err, "cannot send shard %d to device %d", i, dev.Ordinal())
+		}
+	}
+	return &ShardedHandle{shape: sh, spec: spec, shards: shards}, nil
+}
+
+// Shape of the logical array.
+func (h *ShardedHandle) Shape() *shape.Shape {
+	return h.shape
+}
+
+// Spec returns how the array is sharded.
+func (h *ShardedHandle) Spec() *ShardingSpec {
+	return h.spec
+}
+
+// Shards returns the handles of all the shards, in the order of the sharding spec devices.
+func (h *ShardedHandle) Shards() []DeviceHandle {
+	return h.shards
+}
+
+// Assemble fetches all the shards and writes the logical array into dst.
+func (h *ShardedHandle) Assemble(dst []byte) error {
+	if len(dst) != h.shape.ByteSize() {
+		return errors.Errorf("cannot assemble an array of shape %s into %d bytes: want %d bytes", h.shape, len(dst), h.shape.ByteSize())
+	}
+	shardShape, err := h.spec.ShardShape(h.shape)
+	if err != nil {
+		return err
+	}
+	for i, shard := range h.shards {
+		staging, err := stageToHost(shard)
+		if err != nil {
+			return errors.Errorf("cannot fetch shard %d: %v", i, err)
+		}
+		copyShard(staging.data, dst, h.shape, shardShape, h.spec.ShardOrigin(h.shape, i), false)
+		staging.release()
+	}
+	return nil
+}
+
+// ToHost assembles the logical array into a host buffer.
+func (h *ShardedHandle) ToHost(dst HostBuffer) error {
+	data := dst.Acquire()
+	defer dst.Release()
+	if data == nil {
+		return errors.Errorf("cannot assemble an array into a freed buffer")
+	}
+	return h.Assemble(data)
+}
+
+// ToDevice assembles the logical array on the host and sends it to a single device.
+func (h *ShardedHandle) ToDevice(dev Device) (DeviceHandle, error) {
+	data := make([]byte, h.shape.ByteSize())
+	if err := h.Assemble(data); err != nil {
+		return nil, err
+	}
+	return dev.Send(data, h.shape)
+}
+
+// Free the memory of all the shards.
+func (h *ShardedHandle) Free() {
+	for _, shard := range h.shards {
+		shard.Free()
+	}
+}
+
+// String returns a description of the sharded array.
+func (h *ShardedHandle) String() string {
+	return fmt.Sprintf("ShardedHandle(%s,splits=%v)", h.shape, h.spec.Splits)
+}
+
+// copyShard copies the elements of a shard between a shard buffer and the buffer of the whole array.
+// If extract is true, the data is copied from the array into the shard. Otherwise, it is copied
+// from the shard into the array.
+func copyShard(shard, full []byte, fullShape, shardShape *shape.Shape, origin []int, extract bool) {
+	elemSize := dtype.Sizeof(fullShape.DType)
+	rank := len(fullShape.AxisLengths)
+	if rank == 0 {
+		if extract {
+			copy(shard, full)
+		} else {
+			copy(full, shard)
+		}
+		return
+	}
+	fullIndexer := shape.NewIndexer(fullShape.WithAxes(fullShape.AxisLengths...))
+	runLength := shardShape.AxisLengths[rank-1] * elemSize
+	numRuns := shape.Size(shardShape.AxisLengths[:rank-1])
+	if runLength == 0 || numRuns == 0 {
+		return
+	}
+	indices := make([]int, rank)
+	for run := range numRuns {
+		rest := run
+		for axis := rank - 2; axis >= 0; axis-- {
+			indices[axis] = origin[axis] + rest%shardShape.AxisLengths[axis]
+			rest /= shardShape.AxisLengths[axis]
+		}
+		indices[rank-1] = origin[rank-1]
+		fullStart := fullIndexer.Offset(indices...) * elemSize
+		shardStart := run * runLength
+		if extract {
+			copy(shard[shardStart:shardStart+runLength], full[fullStart:])
+		} else {
+			copy(full[fullStart:fullStart+runLength], shard[shardStart:])
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/cpu"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestShardRoundTrip(t *testing.T) {
+	plat, err := cpu.New(platform.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plat.Close()
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		axisLengths []int
+		splits      []int
+	}{
+		{name: "rank 0", axisLengths: nil, splits: nil},
+		{name: "vector", axisLengths: []int{6}, splits: []int{3}},
+		{name: "rows", axisLengths: []int{4, 3}, splits: []int{2, 1}},
+		{name: "columns", axisLengths: []int{2, 6}, splits: []int{1, 3}},
+		{name: "grid", axisLengths: []int{4, 6}, splits: []int{2, 3}},
+		{name: "rank 3", axisLengths: []int{2, 3, 4}, splits: []int{2, 1, 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sh := shape.Of(dtype.Int32, test.axisLengths...)
+			vals := make([]int32, sh.Size())
+			for i := range vals {
+				vals[i] = int32(i)
+			}
+			spec := &platform.ShardingSpec{Splits: test.splits}
+			for range shape.Size(test.splits) {
+				spec.Devices = append(spec.Devices, dev)
+			}
+			h, err := platform.Shard(dtype.CopyFromSlice(vals), sh, spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Free()
+			shardShape, err := spec.ShardShape(sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, shard := range h.Shards() {
+				if !shard.Shape().Equal(shardShape) {
+					t.Errorf("shard %d has shape %s but want %s", i, shard.Shape(), shardShape)
+				}
+				buf, err := plat.Allocate(shard.Shape())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := shard.ToHost(buf); err != nil {
+					t.Fatal(err)
+				}
+				buf.Free()
+			}
+			got := make([]byte, sh.ByteSize())
+			if err := h.Assemble(got); err != nil {
+				t.Fatal(err)
+			}
+			if got := dtype.ToSlice[int32](got); !slices.Equal(got, vals) {
+				t.Errorf("got %v but want %v", got, vals)
+			}
+		})
+	}
+}
+
+func TestShardErrors(t *testing.T) {
+	plat := platformtest.New(1)
+	dev, err := plat.DefaultDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Of(dtype.Int32, 4)
+	data := make([]byte, sh.ByteSize())
+	uneven := &platform.ShardingSpec{Splits: []int{3}, Devices: []platform.Device{dev, dev, dev}}
+	if _, err := platform.Shard(data, sh, uneven); err == nil {
+		t.Errorf("expected an error when splitting 4 elements in 3 shards")
+	}
+	errSend := errors.New("send failed")
+	plat.FailSendAt(3, errSend)
+	spec := &platform.ShardingSpec{Splits: []int{4}, Devices: []platform.Device{dev, dev, dev, dev}}
+	if _, err := platform.Shard(data, sh, spec); !errors.Is(err, errSend) {
+		t.Errorf("got error %v but want %v", err, errSend)
+	}
+	if got := plat.Counts().Live; got != 0 {
+		t.Errorf("%d shards leaked after a failed send", got)
+	}
+}
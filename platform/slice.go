@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// sliceBuffer is a host buffer storing its data in a Go slice of values.
+type sliceBuffer[T dtype.GoDataType] struct {
+	Labeled
+	mu    sync.RWMutex
+	shape *shape.Shape
+	vals  []T
+	// acquired is the copy of the values returned by Acquire when dtype.ZeroCopy is false.
+	acquired []byte
+}
+
+// BufferFromSlice returns a host buffer using an existing slice of values as its storage
+// without copying it. The data type of the shape must match T.
+//
+// As for Borrow, the caller keeps ownership of the slice: Free only drops the reference
+// held by the buffer. The slice must not be modified while the buffer is acquired.
+// When dtype.ZeroCopy is false, Acquire and AcquireRead return copies of the values
+// and Release writes the copy back into the slice.
+func BufferFromSlice[T dtype.GoDataType](data []T, sh *shape.Shape) (HostBuffer, error) {
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrapf(ErrInvalidShape, "cannot use slice: %v", err)
+	}
+	if sh.IsBitPacked() || dtype.Resolve(sh.DType, dtype.HostInt) != dtype.Resolve(dtype.Generic[T](), dtype.HostInt) {
+		return nil, errors.Wrapf(ErrInvalidShape, "cannot use a []%s as an array of shape %s", dtype.Generic[T](), sh)
+	}
+	if size := len(data) * dtype.Sizeof(dtype.Generic[T]()); size != sh.ByteSize() {
+		return nil, errors.Wrapf(ErrInvalidShape, "cannot use %d bytes for an array of shape %s: want %d bytes", size, sh, sh.ByteSize())
+	}
+	if data == nil {
+		// A nil slice would be reported as a freed buffer.
+		data = []T{}
+	}
+	return &sliceBuffer[T]{shape: sh, vals: data}, nil
+}
+
+func (b *sliceBuffer[T]) Shape() *shape.Shape {
+	return b.shape
+}
+
+func (b *sliceBuffer[T]) ToDevice(dev Device) (DeviceHandle, error) {
+	data := b.AcquireRead()
+	defer b.ReleaseRead()
+	return dev.Send(data, b.shape)
+}
+
+func (b *sliceBuffer[T]) ToHost(dst HostBuffer) error {
+	return HostTransfer(dst, b)
+}
+
+func (b *sliceBuffer[T]) Acquire() []byte {
+	b.mu.Lock()
+	if b.vals == nil {
+		return nil
+	}
+	data := dtype.FromSlice(b.vals)
+	if !dtype.ZeroCopy {
+		b.acquired = data
+	}
+	return data
+}
+
+func (b *sliceBuffer[T]) Release() {
+	if b.acquired != nil {
+		dtype.CopyToSlice(b.vals, b.acquired)
+		b.acquired = nil
+	}
+	b.mu.Unlock()
+}
+
+func (b *sliceBuffer[T]) AcquireRead() []byte {
+	b.mu.RLock()
+	if b.vals == nil {
+		return nil
+	}
+	return dtype.FromSlice(b.vals)
+}
+
+func (b *sliceBuffer[T]) ReleaseRead() {
+	b.mu.RUnlock()
+}
+
+func (b *sliceBuffer[T]) Free() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.vals = nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync/atomic"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultStagingRetained   = 256 << 20
+	defaultStagingBufferSize = 64 << 20
+)
+
+// StagingPool recycles the pinned host buffers used to stage data between devices
+// and the host, for example by CopyTo or View. A StagingPool is safe for concurrent use.
+type StagingPool struct {
+	alloc         Allocator
+	pool          *PoolAllocator
+	maxBufferSize int
+}
+
+// NewStagingPool returns a staging pool allocating buffers with alloc.
+// The pool retains at most maxRetained bytes of buffers. Buffers larger than
+// maxBufferSize bytes are never retained.
+func NewStagingPool(alloc Allocator, maxRetained, maxBufferSize int) *StagingPool {
+	return &StagingPool{
+		alloc:         alloc,
+		pool:          NewPoolAllocator(alloc, maxRetained),
+		maxBufferSize: maxBufferSize,
+	}
+}
+
+// Get returns a pinned staging buffer for an array of a given shape.
+// The buffer returns to the pool when freed.
+func (s *StagingPool) Get(sh *shape.Shape) (HostBuffer, error) {
+	if sh.ByteSize() > s.maxBufferSize {
+		return s.alloc.Allocate(sh, Pinned())
+	}
+	return s.pool.Allocate(sh, Pinned())
+}
+
+// Stats returns statistics about the usage of the pool.
+func (s *StagingPool) Stats() PoolStats {
+	return s.pool.Stats()
+}
+
+// Purge frees all the buffers retained by the pool.
+func (s *StagingPool) Purge() {
+	s.pool.Purge()
+}
+
+var stagingPool atomic.Pointer[StagingPool]
+
+func init() {
+	stagingPool.Store(NewStagingPool(goAllocator{}, defaultStagingRetained, defaultStagingBufferSize))
+}
+
+// SetStagingPool sets the pool used by this package to stage data through the host
+// and returns the previous pool. Platforms able to pin memory can set a pool
+// using their allocator.
+func SetStagingPool(pool *StagingPool) (previous *StagingPool) {
+	return stagingPool.Swap(pool)
+}
+
+// CurrentStagingPool returns the pool used by this package to stage data through the host.
+func CurrentStagingPool() *StagingPool {
+	return stagingPool.Load()
+}
+
+// goAllocator allocates host buffers in Go memory.
+type goAllocator struct{}
+
+func (goAllocator) Allocate(sh *shape.Shape, opts ...AllocOption) (HostBuffer, error) {
+	options := NewAllocOptions(opts...)
+	if err := sh.Check(); err != nil {
+		return nil, errors.Wrap(ErrInvalidShape, err.Error())
+	}
+	data, err := AlignedBytes(sh.ByteSize(), options)
+	if err != nil {
+		return nil, err
+	}
+	return newBytesBuffer(data, sh), nil
+}
+
+// staged is the data of a handle staged in a host buffer.
+type staged struct {
+	buf  HostBuffer
+	data []byte
+}
+
+// stageToHost fetches the data of a handle into a staging buffer.
+// The caller must call release once it does not use the data anymore.
+func stageToHost(h Handle) (*staged, error) {
+	sh := h.Shape()
+	buf, err := CurrentStagingPool().Get(sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.ToHost(buf); err != nil {
+		buf.Free()
+		return nil, errors.Errorf("cannot stage array %s to the host: %v", sh, err)
+	}
+	return &staged{buf: buf, data: buf.AcquireRead()}, nil
+}
+
+func (s *staged) release() {
+	s.buf.ReleaseRead()
+	s.buf.Free()
+}
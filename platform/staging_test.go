@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// pinnedAllocator records whether allocations requested pinned memory.
+type pinnedAllocator struct {
+	bytesAllocator
+	pinned int
+}
+
+func (a *pinnedAllocator) Allocate(sh *shape.Shape, opts ...platform.AllocOption) (platform.HostBuffer, error) {
+	if platform.NewAllocOptions(opts...).Pinned {
+		a.pinned++
+	}
+	return a.bytesAllocator.Allocate(sh, opts...)
+}
+
+func TestStagingPool(t *testing.T) {
+	alloc := &pinnedAllocator{}
+	pool := platform.NewStagingPool(alloc, 64, 32)
+	small := shape.Of(dtype.Float32, 4)
+	for range 3 {
+		buf, err := pool.Get(small)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Free()
+	}
+	if got, want := pool.Stats(), (platform.PoolStats{Hits: 2, Misses: 1, RetainedBytes: 16}); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+	large, err := pool.Get(shape.Of(dtype.Float32, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	large.Free()
+	if alloc.allocated != 2 || alloc.freed != 1 {
+		t.Errorf("got %d allocations and %d buffers freed but want 2 and 1", alloc.allocated, alloc.freed)
+	}
+	if got := pool.Stats(); got.Misses != 1 || got.RetainedBytes != 16 {
+		t.Errorf("buffer larger than the maximum buffer size went through the pool: got stats %+v", got)
+	}
+	if alloc.pinned != alloc.allocated {
+		t.Errorf("got %d pinned allocations but want %d", alloc.pinned, alloc.allocated)
+	}
+	pool.Purge()
+	if got := pool.Stats().RetainedBytes; got != 0 || alloc.freed != 2 {
+		t.Errorf("got %d retained bytes and %d buffers freed after Purge but want 0 and 2", got, alloc.freed)
+	}
+}
+
+func TestSetStagingPool(t *testing.T) {
+	pool := platform.NewStagingPool(&bytesAllocator{}, 1<<10, 1<<10)
+	previous := platform.SetStagingPool(pool)
+	defer platform.SetStagingPool(previous)
+	if platform.CurrentStagingPool() != pool {
+		t.Fatalf("staging pool not set")
+	}
+	plat := platformtest.New(2)
+	sh := shape.Of(dtype.Int32, 8)
+	src, err := plat.MockDevice(0).Send(make([]byte, sh.ByteSize()), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Free()
+	for range 2 {
+		dst, err := platform.CopyTo(src, plat.MockDevice(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst.Free()
+	}
+	if got, want := pool.Stats(), (platform.PoolStats{Hits: 1, Misses: 1, RetainedBytes: sh.ByteSize()}); got != want {
+		t.Errorf("got stats %+v but want %+v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import "github.com/gx-org/backend/shape"
+
+type (
+	// Stream is a queue of operations executed in order on a device.
+	// Operations enqueued on different streams may run concurrently,
+	// for example to overlap data transfers with computations.
+	//
+	// Enqueuing functions return before the operation completes.
+	// Buffers passed to a stream must not be modified or read until
+	// the stream has been synchronized.
+	Stream interface {
+		// Device on which the operations are executed.
+		Device() Device
+
+		// Send enqueues a transfer of raw data to the device.
+		Send(buf []byte, sh *shape.Shape) (DeviceHandle, error)
+
+		// ToHost enqueues a transfer of the data of a handle into a host buffer.
+		ToHost(src DeviceHandle, dst HostBuffer) error
+
+		// Record enqueues an event completing when all the operations
+		// enqueued before it on the stream have completed.
+		Record() (Event, error)
+
+		// WaitEvent makes all the operations enqueued after this call wait
+		// for the event to complete. The event can come from another stream
+		// of the same platform. WaitEvent does not block the host.
+		WaitEvent(Event) error
+
+		// Synchronize blocks until all the operations enqueued on the stream have completed.
+		// It returns the first error encountered by any of these operations.
+		Synchronize() error
+
+		// Release the stream once all its operations have completed.
+		// It is invalid to use the stream after this call.
+		Release() error
+	}
+
+	// StreamDevice is implemented by devices supporting multiple streams.
+	StreamDevice interface {
+		Device
+
+		// NewStream returns a new stream to enqueue operations on the device.
+		NewStream() (Stream, error)
+	}
+)
+
+// NewStream returns a new stream for a device.
+// If the device does not implement StreamDevice, the returned stream executes
+// all operations synchronously when they are enqueued.
+func NewStream(dev Device) (Stream, error) {
+	if sd, ok := dev.(StreamDevice); ok {
+		return sd.NewStream()
+	}
+	return &syncStream{dev: dev}, nil
+}
+
+// syncStream executes operations synchronously.
+// Errors are returned when operations are enqueued and the first one
+// is also returned by Synchronize and by the events recorded after it.
+type syncStream struct {
+	dev Device
+	err error
+}
+
+// fail records the first error of the operations of the stream.
+func (s *syncStream) fail(err error) error {
+	if s.err == nil {
+		s.err = err
+	}
+	return err
+}
+
+func (s *syncStream) Device() Device {
+	return s.dev
+}
+
+func (s *syncStream) Send(buf []byte, sh *shape.Shape) (DeviceHandle, error) {
+	h, err := s.dev.Send(buf, sh)
+	if err != nil {
+		return nil, s.fail(err)
+	}
+	return h, nil
+}
+
+func (s *syncStream) ToHost(src DeviceHandle, dst HostBuffer) error {
+	if err := src.ToHost(dst); err != nil {
+		return s.fail(err)
+	}
+	return nil
+}
+
+func (s *syncStream) Record() (Event, error) {
+	return CompletedEvent(s.err), nil
+}
+
+func (s *syncStream) WaitEvent(ev Event) error {
+	if err := ev.Wait(); err != nil {
+		return s.fail(err)
+	}
+	return nil
+}
+
+func (s *syncStream) Synchronize() error {
+	return s.err
+}
+
+func (s *syncStream) Release() error {
+	return nil
+}
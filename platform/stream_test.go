@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// streamDevice returns a given stream.
+type streamDevice struct {
+	platform.Device
+	stream platform.Stream
+}
+
+func (d *streamDevice) NewStream() (platform.Stream, error) {
+	return d.stream, nil
+}
+
+func TestNewStream(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := plat.MockDevice(0)
+	sync, err := platform.NewStream(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sync.Device() != dev {
+		t.Errorf("got device %v but want %v", sync.Device(), dev)
+	}
+	sd := &streamDevice{Device: dev, stream: sync}
+	got, err := platform.NewStream(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != sync {
+		t.Errorf("NewStream did not return the stream of a StreamDevice")
+	}
+}
+
+func TestStreamOrder(t *testing.T) {
+	plat := platformtest.New(1)
+	stream, err := platform.NewStream(plat.MockDevice(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Release()
+	sh := shape.Of(dtype.Float32, 2)
+	var handles []platform.DeviceHandle
+	for _, vals := range [][]float32{{1, 2}, {3, 4}} {
+		h, err := stream.Send(dtype.FromSlice(vals), sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer h.Free()
+		handles = append(handles, h)
+	}
+	// The second transfer waits for an event completed by the host.
+	hostEvent := platform.NewHostEvent()
+	go hostEvent.Complete(nil)
+	if err := stream.WaitEvent(hostEvent); err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := hostEvent.Query(); !done {
+		t.Errorf("WaitEvent returned before the event completed")
+	}
+	var got []float32
+	for _, h := range handles {
+		buf, err := plat.Allocate(sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer buf.Free()
+		if err := stream.ToHost(h, buf); err != nil {
+			t.Fatal(err)
+		}
+		ev, err := stream.Record()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Operations recorded before the event have completed when the event completes.
+		if err := ev.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, dtype.ToSlice[float32](buf.AcquireRead())...)
+		buf.ReleaseRead()
+	}
+	if want := []float32{1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	if err := stream.Synchronize(); err != nil {
+		t.Errorf("Synchronize: %v", err)
+	}
+	if got := plat.Counts(); got.Sends != 2 || got.ToHosts != 2 {
+		t.Errorf("got %d sends and %d transfers to host but want 2 and 2", got.Sends, got.ToHosts)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	errFirst, errSecond := errors.New("first error"), errors.New("second error")
+	sh := shape.Of(dtype.Float32, 2)
+	tests := []struct {
+		name  string
+		setup func(*platformtest.Platform)
+		event platform.Event
+	}{
+		{
+			name: "send",
+			setup: func(plat *platformtest.Platform) {
+				plat.FailSendAt(2, errFirst)
+				plat.FailToHostAt(2, errSecond)
+			},
+			event: platform.CompletedEvent(nil),
+		},
+		{
+			name: "to host",
+			setup: func(plat *platformtest.Platform) {
+				plat.FailToHostAt(1, errFirst)
+				plat.FailSendAt(3, errSecond)
+			},
+			event: platform.CompletedEvent(nil),
+		},
+		{
+			name:  "wait event",
+			setup: func(plat *platformtest.Platform) { plat.FailSendAt(3, errSecond) },
+			event: platform.CompletedEvent(errFirst),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plat := platformtest.New(1)
+			test.setup(plat)
+			stream, err := platform.NewStream(plat.MockDevice(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stream.Release()
+			h, err := stream.Send(dtype.FromSlice([]float32{1, 2}), sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Free()
+			buf, err := plat.Allocate(sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer buf.Free()
+			// Enqueue operations after the failure: the stream keeps the first error.
+			var errs []error
+			for range 2 {
+				if h, err := stream.Send(dtype.FromSlice([]float32{3, 4}), sh); err != nil {
+					errs = append(errs, err)
+				} else {
+					h.Free()
+				}
+				errs = append(errs, stream.ToHost(h, buf))
+				errs = append(errs, stream.WaitEvent(test.event))
+			}
+			if !slices.ContainsFunc(errs, func(err error) bool { return err == errFirst }) {
+				t.Errorf("no operation returned %v", errFirst)
+			}
+			if err := stream.Synchronize(); err != errFirst {
+				t.Errorf("Synchronize returned %v but want %v", err, errFirst)
+			}
+			ev, err := stream.Record()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if done, err := ev.Query(); !done || err != errFirst {
+				t.Errorf("recorded event: got %v, %v but want true, %v", done, err, errFirst)
+			}
+		})
+	}
+}
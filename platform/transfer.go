@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// BatchSender is implemented by devices able to upload many arrays in a single batch.
+type BatchSender interface {
+	Device
+
+	// SendBatch sends the arrays to the device and returns one handle per array.
+	SendBatch(bufs [][]byte, shapes []*shape.Shape) ([]DeviceHandle, error)
+}
+
+// TransferOptions configures how a TransferManager schedules uploads.
+type TransferOptions struct {
+	// CoalesceBytes is the maximum total size, in bytes, of consecutive small arrays
+	// grouped into a single upload task. Arrays larger than this size are uploaded
+	// by their own task. Defaults to 1MiB.
+	CoalesceBytes int
+	// MaxInFlight is the maximum number of upload tasks running concurrently.
+	// Defaults to 4.
+	MaxInFlight int
+}
+
+const (
+	defaultCoalesceBytes = 1 << 20
+	defaultMaxInFlight   = 4
+)
+
+type transfer struct {
+	data []byte
+	sh   *shape.Shape
+}
+
+// TransferManager accumulates arrays in host memory and uploads them to a device as a batch.
+type TransferManager struct {
+	dev     Device
+	opts    TransferOptions
+	pending []transfer
+}
+
+// NewTransferManager returns a transfer manager uploading arrays to a device.
+func NewTransferManager(dev Device, opts TransferOptions) *TransferManager {
+	if opts.CoalesceBytes <= 0 {
+		opts.CoalesceBytes = defaultCoalesceBytes
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultMaxInFlight
+	}
+	return &TransferManager{dev: dev, opts: opts}
+}
+
+// Add schedules an array for upload and returns its index in the handles returned by Flush.
+// The data must not be modified until Flush returns.
+func (m *TransferManager) Add(data []byte, sh *shape.Shape) int {
+	m.pending = append(m.pending, transfer{data: data, sh: sh})
+	return len(m.pending) - 1
+}
+
+// Len returns the number of arrays scheduled for upload.
+func (m *TransferManager) Len() int {
+	return len(m.pending)
+}
+
+// Flush uploads all the scheduled arrays and returns their handles in the order they were added.
+// The manager is empty after the call and can be reused.
+func (m *TransferManager) Flush() ([]DeviceHandle, error) {
+	pending := m.pending
+	m.pending = nil
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	if batcher, ok := m.dev.(BatchSender); ok {
+		bufs := make([][]byte, len(pending))
+		shapes := make([]*shape.Shape, len(pending))
+		for i, t := range pending {
+			bufs[i], shapes[i] = t.data, t.sh
+		}
+		return batcher.SendBatch(bufs, shapes)
+	}
+	handles := make([]DeviceHandle, len(pending))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, m.opts.MaxInFlight)
+	for _, task := range m.tasks(pending) {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for _, i := range task {
+				handle, err := m.dev.Send(pending[i].data, pending[i].sh)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = errors.Wrapf(err, "cannot upload array %d of shape %s", i, pending[i].sh)
+					})
+					return
+				}
+				handles[i] = handle
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		for _, handle := range handles {
+			if handle != nil {
+				handle.Free()
+			}
+		}
+		return nil, firstErr
+	}
+	return handles, nil
+}
+
+// tasks groups consecutive small transfers together. Each task is a list of indices.
+func (m *TransferManager) tasks(pending []transfer) [][]int {
+	var (
+		tasks   [][]int
+		current []int
+		size    int
+	)
+	for i, t := range pending {
+		n := len(t.data)
+		if n > m.opts.CoalesceBytes {
+			tasks = append(tasks, []int{i})
+			continue
+		}
+		if size+n > m.opts.CoalesceBytes && len(current) > 0 {
+			tasks = append(tasks, current)
+			current, size = nil, 0
+		}
+		current = append(current, i)
+		size += n
+	}
+	if len(current) > 0 {
+		tasks = append(tasks, current)
+	}
+	return tasks
+}
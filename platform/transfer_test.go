@@ -0,0 +1,178 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+// concurrentDevice records the maximum number of concurrent transfers.
+type concurrentDevice struct {
+	platform.Device
+
+	mu          sync.Mutex
+	active, max int
+}
+
+func (d *concurrentDevice) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	d.mu.Lock()
+	d.active++
+	d.max = max(d.max, d.active)
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.active--
+		d.mu.Unlock()
+	}()
+	return d.Device.Send(buf, sh)
+}
+
+// batchDevice counts the number of batches sent to a device.
+type batchDevice struct {
+	platform.Device
+	batches int
+}
+
+func (d *batchDevice) SendBatch(bufs [][]byte, shapes []*shape.Shape) ([]platform.DeviceHandle, error) {
+	d.batches++
+	handles := make([]platform.DeviceHandle, len(bufs))
+	for i := range bufs {
+		var err error
+		if handles[i], err = d.Send(bufs[i], shapes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return handles, nil
+}
+
+func TestTransferManager(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          platform.TransferOptions
+		lengths       []int
+		maxConcurrent int
+	}{
+		{
+			name:          "defaults",
+			lengths:       []int{1, 2, 3, 4},
+			maxConcurrent: 1,
+		},
+		{
+			name:          "coalesced",
+			opts:          platform.TransferOptions{CoalesceBytes: 1 << 10, MaxInFlight: 8},
+			lengths:       []int{4, 4, 4, 4, 4, 4},
+			maxConcurrent: 1,
+		},
+		{
+			name:          "one task per array",
+			opts:          platform.TransferOptions{CoalesceBytes: 4, MaxInFlight: 2},
+			lengths:       []int{2, 3, 4, 5, 6, 7},
+			maxConcurrent: 2,
+		},
+		{
+			name:          "serial",
+			opts:          platform.TransferOptions{CoalesceBytes: 8, MaxInFlight: 1},
+			lengths:       []int{1, 2, 3, 4, 5, 6},
+			maxConcurrent: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plat := platformtest.New(1)
+			dev := &concurrentDevice{Device: plat.MockDevice(0)}
+			m := platform.NewTransferManager(dev, test.opts)
+			var want [][]int32
+			for i, n := range test.lengths {
+				vals := make([]int32, n)
+				for j := range vals {
+					vals[j] = int32(i*100 + j)
+				}
+				want = append(want, vals)
+				if got := m.Add(dtype.CopyFromSlice(vals), shape.Of(dtype.Int32, n)); got != i {
+					t.Errorf("Add returned index %d but want %d", got, i)
+				}
+			}
+			if got := m.Len(); got != len(test.lengths) {
+				t.Errorf("got %d pending arrays but want %d", got, len(test.lengths))
+			}
+			handles, err := m.Flush()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Len() != 0 {
+				t.Errorf("transfer manager not empty after Flush")
+			}
+			for i, handle := range handles {
+				got := dtype.ToSlice[int32](handle.(*platformtest.Handle).Data())
+				if !slices.Equal(got, want[i]) {
+					t.Errorf("handle %d: got %v but want %v", i, got, want[i])
+				}
+				handle.Free()
+			}
+			if dev.max > test.maxConcurrent {
+				t.Errorf("got %d concurrent transfers but want at most %d", dev.max, test.maxConcurrent)
+			}
+		})
+	}
+}
+
+func TestTransferManagerBatch(t *testing.T) {
+	plat := platformtest.New(1)
+	dev := &batchDevice{Device: plat.MockDevice(0)}
+	m := platform.NewTransferManager(dev, platform.TransferOptions{})
+	if handles, err := m.Flush(); err != nil || handles != nil {
+		t.Errorf("got %v, %v when flushing an empty manager but want nil, nil", handles, err)
+	}
+	for i := range 3 {
+		m.Add(dtype.CopyFromSlice([]int32{int32(i)}), shape.Of(dtype.Int32, 1))
+	}
+	handles, err := m.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handles) != 3 {
+		t.Errorf("got %d handles but want 3", len(handles))
+	}
+	if dev.batches != 1 {
+		t.Errorf("got %d batches but want 1", dev.batches)
+	}
+	for _, handle := range handles {
+		handle.Free()
+	}
+}
+
+func TestTransferManagerError(t *testing.T) {
+	plat := platformtest.New(1)
+	errSend := errors.New("send failed")
+	plat.FailSendAt(3, errSend)
+	m := platform.NewTransferManager(plat.MockDevice(0), platform.TransferOptions{CoalesceBytes: 4, MaxInFlight: 2})
+	for i := range 5 {
+		m.Add(dtype.CopyFromSlice([]int32{int32(i)}), shape.Of(dtype.Int32, 1))
+	}
+	if _, err := m.Flush(); !errors.Is(err, errSend) {
+		t.Errorf("got error %v but want %v", err, errSend)
+	}
+	if got := plat.Counts().Live; got != 0 {
+		t.Errorf("%d handles leaked after a failed upload", got)
+	}
+}
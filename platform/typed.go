@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"sync"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/pkg/errors"
+)
+
+// AcquireAs locks a host buffer for writing and returns its content as a slice of T
+// without copying it, together with a function releasing the buffer.
+// The slice must not be used once release has been called. Calling release more than once
+// has no effect. The data type of the buffer must match T.
+func AcquireAs[T dtype.GoDataType](buf HostBuffer) (vals []T, release func(), err error) {
+	if err := checkElementType[T](buf); err != nil {
+		return nil, nil, err
+	}
+	data := buf.Acquire()
+	if data == nil {
+		buf.Release()
+		return nil, nil, errors.Wrap(ErrBufferFreed, "cannot acquire host buffer")
+	}
+	if vals, err = dtype.ToSliceErr[T](data); err != nil {
+		buf.Release()
+		return nil, nil, err
+	}
+	vals = vals[:len(vals):len(vals)]
+	var once sync.Once
+	return vals, func() {
+		once.Do(func() {
+			dtype.WriteBack(data, vals)
+			buf.Release()
+		})
+	}, nil
+}
+
+// AcquireReadAs locks a host buffer for reading and returns its content as a slice of T
+// without copying it, together with a function releasing the buffer.
+// The slice must not be written, nor used once release has been called.
+func AcquireReadAs[T dtype.GoDataType](buf HostBuffer) (vals []T, release func(), err error) {
+	if err := checkElementType[T](buf); err != nil {
+		return nil, nil, err
+	}
+	data := buf.AcquireRead()
+	if data == nil {
+		buf.ReleaseRead()
+		return nil, nil, errors.Wrap(ErrBufferFreed, "cannot acquire host buffer")
+	}
+	if vals, err = dtype.ToSliceErr[T](data); err != nil {
+		buf.ReleaseRead()
+		return nil, nil, err
+	}
+	var once sync.Once
+	return vals[:len(vals):len(vals)], func() { once.Do(buf.ReleaseRead) }, nil
+}
+
+func checkElementType[T dtype.GoDataType](buf HostBuffer) error {
+	sh := buf.Shape()
+	want := dtype.Generic[T]()
+	if sh.IsBitPacked() || dtype.Resolve(sh.DType, dtype.HostInt) != dtype.Resolve(want, dtype.HostInt) {
+		return errors.Wrapf(ErrInvalidShape, "cannot access a buffer of shape %s as []%s", sh, want)
+	}
+	return nil
+}
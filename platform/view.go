@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Viewer is implemented by device handles able to alias a sub-region of their array
+// without copying any data.
+type Viewer interface {
+	DeviceHandle
+
+	// View returns a handle aliasing the sub-region starting at offsets with the given sizes.
+	// The view shares the memory of the handle: the handle must outlive the view.
+	// It returns ErrViewUnsupported if the sub-region cannot be aliased.
+	View(offsets, sizes []int) (DeviceHandle, error)
+}
+
+// ErrViewUnsupported is returned by Viewer.View when a sub-region cannot be aliased.
+var ErrViewUnsupported = errors.New("view not supported")
+
+// ViewShape returns the shape of the sub-region of an array starting at offsets with the given sizes.
+func ViewShape(sh *shape.Shape, offsets, sizes []int) (*shape.Shape, error) {
+	rank := len(sh.AxisLengths)
+	if len(offsets) != rank || len(sizes) != rank {
+		return nil, errors.Errorf("got %d offsets and %d sizes for an array of rank %d", len(offsets), len(sizes), rank)
+	}
+	for axis, length := range sh.AxisLengths {
+		if offsets[axis] < 0 || sizes[axis] < 0 || offsets[axis]+sizes[axis] > length {
+			return nil, errors.Errorf("region [%d:%d] out of range for axis %d of length %d", offsets[axis], offsets[axis]+sizes[axis], axis, length)
+		}
+	}
+	return sh.WithAxes(sizes...), nil
+}
+
+// View returns a handle to the sub-region of an array starting at offsets with the given sizes.
+// The view is zero-copy if the handle implements Viewer and supports the sub-region. Otherwise, the sub-region is
+// extracted through host memory and sent back to the device as a new array.
+func View(h DeviceHandle, offsets, sizes []int) (DeviceHandle, error) {
+	sh := h.Shape()
+	sub, err := ViewShape(sh, offsets, sizes)
+	if err != nil {
+		return nil, err
+	}
+	if viewer, ok := h.(Viewer); ok {
+		view, err := viewer.View(offsets, sizes)
+		if err == nil {
+			return view, nil
+		}
+		if !errors.Is(err, ErrViewUnsupported) {
+			return nil, err
+		}
+	}
+	if dtype.IsSubByte(sh.DType) || sh.IsBitPacked() || !sh.IsContiguous() {
+		return nil, errors.Errorf("cannot extract a view from an array of shape %s: packed data or non-default layout", sh)
+	}
+	staging, err := stageToHost(h)
+	if err != nil {
+		return nil, err
+	}
+	defer staging.release()
+	data := make([]byte, sub.ByteSize())
+	copyShard(data, staging.data, sh, sub, offsets, true)
+	return h.Device().Send(data, sub)
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package virtual partitions the physical devices of a platform into virtual devices.
+//
+// Each virtual device has its own memory and compute quotas such that
+// independent workloads can share a physical device.
+package virtual
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+	"github.com/pkg/errors"
+)
+
+// Partition defines a virtual device.
+type Partition struct {
+	// Physical is the ordinal of the physical device on the base platform.
+	Physical int
+	// Memory is the maximum number of bytes the virtual device can allocate.
+	// There is no limit if zero.
+	Memory int64
+	// Compute is the maximum number of computations running concurrently on the virtual device.
+	// There is no limit if zero.
+	Compute int
+}
+
+// Platform exposes virtual devices as the devices of a platform.
+type Platform struct {
+	base    platform.Platform
+	devices []*Device
+}
+
+var _ platform.Platform = (*Platform)(nil)
+
+// New returns a platform with one virtual device per partition.
+// The ordinal of a virtual device is the index of its partition.
+// The base platform is owned by the caller and is not released by Release.
+func New(base platform.Platform, partitions []Partition) (*Platform, error) {
+	p := &Platform{base: base}
+	for i, part := range partitions {
+		physical, err := base.Device(part.Physical)
+		if err != nil {
+			return nil, errors.Errorf("partition %d: %v", i, err)
+		}
+		if part.Memory < 0 || part.Compute < 0 {
+			return nil, errors.Errorf("partition %d: negative quota", i)
+		}
+		dev := &Device{
+			plat:     p,
+			physical: physical,
+			ordinal:  i,
+			part:     part,
+			budget:   platform.NewBudget(i, part.Memory),
+		}
+		if part.Compute > 0 {
+			dev.compute = make(chan struct{}, part.Compute)
+		}
+		p.devices = append(p.devices, dev)
+	}
+	return p, nil
+}
+
+// Name of the platform.
+func (p *Platform) Name() string {
+	return "virtual:" + p.base.Name()
+}
+
+// Base returns the platform owning the physical devices.
+func (p *Platform) Base() platform.Platform {
+	return p.base
+}
+
+// Device returns a virtual device given its ordinal.
+func (p *Platform) Device(ordinal int) (platform.Device, error) {
+	if ordinal < 0 || ordinal >= len(p.devices) {
+		return nil, errors.Errorf("virtual device %d out of range [0, %d)", ordinal, len(p.devices))
+	}
+	return p.devices[ordinal], nil
+}
+
+// NumDevices returns the number of virtual devices.
+func (p *Platform) NumDevices() int {
+	return len(p.devices)
+}
+
+// Devices returns all the virtual devices.
+func (p *Platform) Devices() ([]platform.Device, error) {
+	devices := make([]platform.Device, len(p.devices))
+	for i, dev := range p.devices {
+		devices[i] = dev
+	}
+	return devices, nil
+}
+
+// DefaultDevice returns the first virtual device.
+func (p *Platform) DefaultDevice() (platform.Device, error) {
+	return platform.SelectDevice(p, nil)
+}
+
+// Close does nothing: the base platform is owned by the caller.
+func (p *Platform) Close() error {
+	return nil
+}
+
+// Release does nothing.
+//
+// Deprecated: use Close.
+func (p *Platform) Release() error {
+	return p.Close()
+}
+
+// Device is a partition of a physical device.
+type Device struct {
+	plat     *Platform
+	physical platform.Device
+	ordinal  int
+	part     Partition
+	budget   *platform.Budget
+	compute  chan struct{}
+}
+
+var _ platform.Device = (*Device)(nil)
+
+// Platform owning the virtual device.
+func (d *Device) Platform() platform.Platform {
+	return d.plat
+}
+
+// Physical returns the physical device of the partition.
+func (d *Device) Physical() platform.Device {
+	return d.physical
+}
+
+// Budget returns the memory budget of the virtual device.
+func (d *Device) Budget() *platform.Budget {
+	return d.budget
+}
+
+// Send data to the physical device if the memory quota allows it.
+func (d *Device) Send(buf []byte, sh *shape.Shape) (platform.DeviceHandle, error) {
+	size := int64(len(buf))
+	if err := d.budget.Reserve(size, ""); err != nil {
+		return nil, err
+	}
+	h, err := d.physical.Send(buf, sh)
+	if err != nil {
+		d.budget.Release(size)
+		return nil, err
+	}
+	return &Handle{DeviceHandle: h, dev: d, reserved: size}, nil
+}
+
+// Ordinal of the virtual device.
+func (d *Device) Ordinal() int {
+	return d.ordinal
+}
+
+// Description returns the description of the physical device with the quotas of the partition.
+func (d *Device) Description() *platform.Description {
+	desc := *d.physical.Description()
+	desc.Ordinal = d.ordinal
+	if d.part.Memory > 0 {
+		desc.TotalMemory = d.part.Memory
+	}
+	desc.Attributes = maps.Clone(desc.Attributes)
+	if desc.Attributes == nil {
+		desc.Attributes = make(map[string]string)
+	}
+	desc.Attributes["physical_device"] = strconv.Itoa(d.physical.Ordinal())
+	desc.Attributes["compute_quota"] = strconv.Itoa(d.part.Compute)
+	return &desc
+}
+
+// HealthCheck checks the health of the physical device.
+func (d *Device) HealthCheck() error {
+	return d.physical.HealthCheck()
+}
+
+// Reset returns an error: resetting the physical device would invalidate the other partitions.
+func (d *Device) Reset() error {
+	return errors.Errorf("cannot reset virtual device %d: reset physical device %d instead", d.ordinal, d.physical.Ordinal())
+}
+
+// Now returns the timestamp of the physical device clock.
+func (d *Device) Now() (time.Duration, error) {
+	return d.physical.Now()
+}
+
+// AcquireCompute blocks until a computation can run on the virtual device given its compute quota.
+// The returned function must be called when the computation completes.
+func (d *Device) AcquireCompute(ctx context.Context) (release func(), err error) {
+	if d.compute == nil {
+		return func() {}, nil
+	}
+	select {
+	case d.compute <- struct{}{}:
+		return func() { <-d.compute }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Handle is an array stored on a virtual device.
+type Handle struct {
+	platform.DeviceHandle
+	dev      *Device
+	reserved int64
+	freed    atomic.Bool
+}
+
+// Device returns the virtual device storing the array.
+func (h *Handle) Device() platform.Device {
+	return h.dev
+}
+
+// Physical returns the handle on the physical device.
+func (h *Handle) Physical() platform.DeviceHandle {
+	return h.DeviceHandle
+}
+
+// Free the array and returns its memory to the quota of the virtual device.
+func (h *Handle) Free() {
+	if h.freed.Swap(true) {
+		return
+	}
+	h.DeviceHandle.Free()
+	h.dev.budget.Release(h.reserved)
+}
+
+// String returns a description of the handle.
+func (h *Handle) String() string {
+	return fmt.Sprintf("VirtualHandle(%s,device=%d)", h.Shape(), h.dev.ordinal)
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtual_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/platform/virtual"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestQuotas(t *testing.T) {
+	base := platformtest.New(1)
+	plat, err := virtual.New(base, []virtual.Partition{
+		{Physical: 0, Memory: 8},
+		{Physical: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := plat.Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := plat.Device(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Vector(dtype.Float32, 4)
+	if _, err := small.Send(make([]byte, 16), sh); !errors.Is(err, platform.ErrOutOfMemory) {
+		t.Errorf("got error %v but want %v", err, platform.ErrOutOfMemory)
+	}
+	h, err := large.Send(make([]byte, 16), sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Device() != large {
+		t.Errorf("handle is on device %v but want %v", h.Device(), large)
+	}
+	h.Free()
+	if got := base.Counts().Live; got != 0 {
+		t.Errorf("got %d live handles on the physical device but want 0", got)
+	}
+}
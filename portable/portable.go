@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package portable records built graphs in a backend-independent format, such that a graph
+// built once can be cached on disk or shipped to another process and replayed onto the
+// graph of any backend without being built again from GX source.
+//
+// Graphs are recorded as StableHLO modules stored in protobuf.Program messages.
+// Operations without a StableHLO equivalent cannot be recorded, and data types
+// depending on the platform, such as dtype.Int, are resolved when the graph is recorded.
+package portable
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/gx-org/backend/shape"
+	"github.com/gx-org/backend/stablehlo"
+)
+
+// recorder is the compiler of the graphs used to record programs, which are never compiled.
+type recorder struct{}
+
+func (recorder) Compile(platform.Device, *stablehlo.Module) (ops.Runner, error) {
+	return nil, fmt.Errorf("cannot compile a graph recording a program")
+}
+
+// Record builds a graph for a platform and returns the program computing its outputs.
+func Record(plat platform.Platform, name string, params []*shape.Shape, build backend.BuildFunc) (*protobuf.Program, error) {
+	g := stablehlo.New(plat, name, recorder{})
+	output, traced, err := build(g)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build graph %s: %v", name, err)
+	}
+	mod, err := g.Module(output, traced, params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot record graph %s: %v", name, err)
+	}
+	return &protobuf.Program{
+		Name:    name,
+		Module:  []byte(mod.Text),
+		Params:  params,
+		Outputs: outputShapes(output),
+		Traced:  outputShapes(traced),
+	}, nil
+}
+
+func outputShapes(outs []*ops.OutputNode) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(outs))
+	for i, out := range outs {
+		shapes[i] = out.Shape
+	}
+	return shapes
+}
+
+// Load returns a function replaying a program onto a graph.
+// The graph can be created by any backend.
+func Load(prog *protobuf.Program) backend.BuildFunc {
+	return func(g ops.Graph) (output, traced []*ops.OutputNode, err error) {
+		outs, err := stablehlo.Import(g, prog.Module)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot load program %s: %v", prog.Name, err)
+		}
+		if want := len(prog.Outputs) + len(prog.Traced); len(outs) != want {
+			return nil, nil, fmt.Errorf("cannot load program %s: module returns %d values but want %d outputs and %d traced values", prog.Name, len(outs), len(prog.Outputs), len(prog.Traced))
+		}
+		for i, want := range append(append([]*shape.Shape{}, prog.Outputs...), prog.Traced...) {
+			resolved := want.Clone()
+			resolved.DType = platform.Resolve(g.Platform(), want.DType)
+			if got := outs[i].Shape; !got.EqualIgnoringLayout(resolved) {
+				return nil, nil, fmt.Errorf("cannot load program %s: module returns %s for value %d but want %s", prog.Name, got, i, want)
+			}
+			// Keep the shape of the signature, which may carry a layout or axis names.
+			outs[i].Shape = want
+		}
+		return outs[:len(prog.Outputs)], outs[len(prog.Outputs):], nil
+	}
+}
+
+// Signature returns the signature of a program, for example to precompile it.
+func Signature(prog *protobuf.Program, opts ...ops.GraphOption) backend.Signature {
+	return backend.Signature{
+		Name:    prog.Name,
+		Params:  prog.Params,
+		Build:   Load(prog),
+		Options: opts,
+	}
+}
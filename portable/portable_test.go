@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package portable_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/portable"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/gx-org/backend/shape"
+	"github.com/gx-org/backend/stablehlo"
+)
+
+type compiler struct{}
+
+func (compiler) Compile(platform.Device, *stablehlo.Module) (ops.Runner, error) {
+	return nil, nil
+}
+
+func build(g ops.Graph) (output, traced []*ops.OutputNode, err error) {
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum, err := g.Core().ReduceSum(x, []int{1})
+	if err != nil {
+		return nil, nil, err
+	}
+	ints, err := g.Core().Cast(x, dtype.Int)
+	if err != nil {
+		return nil, nil, err
+	}
+	output = []*ops.OutputNode{{Node: sum, Shape: shape.Of(dtype.Float32, 2)}}
+	traced = []*ops.OutputNode{{Node: ints, Shape: shape.Of(dtype.Int, 2, 3)}}
+	return output, traced, nil
+}
+
+func TestRecordLoad(t *testing.T) {
+	plat := platformtest.New(1)
+	params := []*shape.Shape{shape.Of(dtype.Float32, 2, 3)}
+	prog, err := portable.Record(plat, "prog", params, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := protobuf.UnmarshalProgram(prog.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "prog" || string(got.Module) != string(prog.Module) {
+		t.Errorf("got program %q with module:\n%s\nwant program %q with module:\n%s", got.Name, got.Module, prog.Name, prog.Module)
+	}
+	if len(got.Params) != 1 || len(got.Outputs) != 1 || len(got.Traced) != 1 || !got.Traced[0].Equal(shape.Of(dtype.Int, 2, 3)) {
+		t.Errorf("got signature %v -> %v, %v", got.Params, got.Outputs, got.Traced)
+	}
+	g := stablehlo.New(plat, "prog", compiler{})
+	output, traced, err := portable.Signature(got).Build(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 1 || len(traced) != 1 {
+		t.Fatalf("got %d outputs and %d traced values but want 1 and 1", len(output), len(traced))
+	}
+	mod, err := g.Module(output, traced, got.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range []string{`"stablehlo.reduce"`, `"stablehlo.convert"`} {
+		if !strings.Contains(mod.Text, op) {
+			t.Errorf("loaded module has no %s operation:\n%s", op, mod.Text)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	plat := platformtest.New(1)
+	prog, err := portable.Record(plat, "prog", []*shape.Shape{shape.Of(dtype.Float32, 2, 3)}, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog.Traced = nil
+	if _, _, err := portable.Load(prog)(stablehlo.New(plat, "prog", compiler{})); err == nil {
+		t.Errorf("expected an error when the module returns more values than the signature")
+	}
+	prog.Traced = []*shape.Shape{shape.Of(dtype.Float32, 2, 3)}
+	if _, _, err := portable.Load(prog)(stablehlo.New(plat, "prog", compiler{})); err == nil {
+		t.Errorf("expected an error when the module returns a value of another shape")
+	}
+	if _, err := portable.Record(plat, "prog", nil, build); err == nil {
+		t.Errorf("expected an error when the parameters are missing")
+	}
+}
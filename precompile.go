@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// BuildFunc builds the nodes of a graph and returns its outputs.
+// It may build the graph from scratch or from a serialized graph.
+type BuildFunc func(g ops.Graph) (output, traced []*ops.OutputNode, err error)
+
+// Signature describes a graph known in advance, for example by a serving process.
+type Signature struct {
+	// Name of the graph.
+	Name string
+	// Params are the shapes of the arguments the graph is compiled for.
+	Params []*shape.Shape
+	// Build builds the graph.
+	Build BuildFunc
+	// Options used to create the graph.
+	Options []ops.GraphOption
+}
+
+// Precompile builds and compiles a set of graphs on a device, such that the
+// compilation latency is paid at startup instead of on the first request.
+// It returns the runners indexed by the name of their signature.
+func Precompile(b Backend, dev platform.Device, sigs []Signature) (map[string]ops.Runner, error) {
+	runners := make(map[string]ops.Runner, len(sigs))
+	for _, sig := range sigs {
+		if _, dup := runners[sig.Name]; dup {
+			return nil, fmt.Errorf("cannot precompile %q: duplicate signature name", sig.Name)
+		}
+		runner, err := precompile(b, dev, sig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot precompile %q: %v", sig.Name, err)
+		}
+		runners[sig.Name] = runner
+	}
+	return runners, nil
+}
+
+func precompile(b Backend, dev platform.Device, sig Signature) (ops.Runner, error) {
+	g, err := b.NewOps(sig.Name, sig.Options...)
+	if err != nil {
+		return nil, err
+	}
+	output, traced, err := sig.Build(g)
+	if err != nil {
+		return nil, err
+	}
+	return g.Compile(dev, output, traced, sig.Params)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"testing"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+)
+
+func identity(sh *shape.Shape) backend.BuildFunc {
+	return func(g ops.Graph) (output, traced []*ops.OutputNode, err error) {
+		x, err := g.Core().Argument("x", sh, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*ops.OutputNode{{Node: x, Shape: sh}}, nil, nil
+	}
+}
+
+func TestPrecompile(t *testing.T) {
+	b := opstest.NewBackend(platformtest.New(1))
+	dev, err := b.Platform().Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, large := shape.Of(dtype.Float32, 2), shape.Of(dtype.Float32, 1024)
+	runners, err := backend.Precompile(b, dev, []backend.Signature{
+		{Name: "small", Params: []*shape.Shape{small}, Build: identity(small)},
+		{Name: "large", Params: []*shape.Shape{large}, Build: identity(large)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runners) != 2 || runners["small"] == nil || runners["large"] == nil {
+		t.Errorf("got runners %v but want small and large", runners)
+	}
+	for _, g := range b.Graphs() {
+		if g.Compiled != 1 {
+			t.Errorf("graph %s compiled %d times but want 1", g.Name(), g.Compiled)
+		}
+	}
+	_, err = backend.Precompile(b, dev, []backend.Signature{
+		{Name: "small", Params: []*shape.Shape{small}, Build: identity(small)},
+		{Name: "small", Params: []*shape.Shape{small}, Build: identity(small)},
+	})
+	if err == nil {
+		t.Errorf("duplicate signatures did not return an error")
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Attr is a parameter of an operation which is not a node, formatted as text.
+	Attr struct {
+		Name  string
+		Value string
+	}
+
+	// Op is a builder call creating a node.
+	Op struct {
+		// ID of the node, unique within the root graph.
+		ID int
+		// Op is the operation.
+		Op ops.OpID
+		// Inputs are the identifiers of the input nodes.
+		Inputs []int
+		// Attrs are the other parameters of the operation.
+		Attrs []Attr
+		// Shape is the inferred shape of the node, or nil if unknown.
+		Shape *shape.Shape
+		// Graph is the path of the graph in which the node has been created.
+		Graph string
+	}
+
+	// OutputNode is an output of a graph.
+	OutputNode struct {
+		// Node is the identifier of the output node.
+		Node  int
+		Shape *shape.Shape
+	}
+
+	// Graph is the list of operations of a graph and its subgraphs.
+	Graph struct {
+		Name    string
+		Ops     []*Op
+		Outputs []*OutputNode
+		Traced  []*OutputNode
+		Params  []*shape.Shape
+	}
+)
+
+// Field numbers of the Attr message.
+const (
+	attrName  = 1
+	attrValue = 2
+)
+
+// Field numbers of the Op message.
+const (
+	opID     = 1
+	opOp     = 2
+	opInputs = 3
+	opAttrs  = 4
+	opShape  = 5
+	opGraph  = 6
+)
+
+// Field numbers of the OutputNode message.
+const (
+	outputNode  = 1
+	outputShape = 2
+)
+
+// Field numbers of the Graph message.
+const (
+	graphName    = 1
+	graphOps     = 2
+	graphOutputs = 3
+	graphTraced  = 4
+	graphParams  = 5
+)
+
+// FromGraph returns the operations recorded by a graph and its subgraphs,
+// sorted by node identifiers, together with the outputs of the graph.
+// The output nodes must have been created by the graph.
+func FromGraph(g *intercept.Graph, outputs, traced []*ops.OutputNode, params []*shape.Shape) (*Graph, error) {
+	pb := &Graph{Name: g.Name(), Params: params}
+	appendOps(pb, g)
+	slices.SortFunc(pb.Ops, func(a, b *Op) int { return a.ID - b.ID })
+	var err error
+	if pb.Outputs, err = outputNodes(outputs); err != nil {
+		return nil, err
+	}
+	if pb.Traced, err = outputNodes(traced); err != nil {
+		return nil, err
+	}
+	return pb, nil
+}
+
+func appendOps(pb *Graph, g *intercept.Graph) {
+	for _, node := range g.Nodes() {
+		call := node.Call()
+		op := &Op{
+			ID:    node.ID(),
+			Op:    call.Op,
+			Shape: call.Shape,
+			Graph: g.Path(),
+		}
+		for _, input := range call.Inputs {
+			op.Inputs = append(op.Inputs, input.ID())
+		}
+		for _, attr := range call.Attrs {
+			op.Attrs = append(op.Attrs, Attr{Name: attr.Name, Value: intercept.FormatValue(attr.Value)})
+		}
+		pb.Ops = append(pb.Ops, op)
+	}
+	for _, sub := range g.Subgraphs() {
+		appendOps(pb, sub)
+	}
+}
+
+func outputNodes(outs []*ops.OutputNode) ([]*OutputNode, error) {
+	pbs := make([]*OutputNode, len(outs))
+	for i, out := range outs {
+		var err error
+		if pbs[i], err = NewOutputNode(out); err != nil {
+			return nil, err
+		}
+	}
+	return pbs, nil
+}
+
+// NewOutputNode converts an output node created by an intercepted graph.
+func NewOutputNode(out *ops.OutputNode) (*OutputNode, error) {
+	node, ok := out.Node.(*intercept.Node)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert output node %T: not created by an intercepted graph", out.Node)
+	}
+	return &OutputNode{Node: node.ID(), Shape: out.Shape}, nil
+}
+
+// Marshal encodes the graph as a Graph message.
+func (g *Graph) Marshal() []byte {
+	var b []byte
+	b = appendString(b, graphName, g.Name)
+	for _, op := range g.Ops {
+		b = appendBytesField(b, graphOps, op.marshal())
+	}
+	for _, out := range g.Outputs {
+		b = appendBytesField(b, graphOutputs, out.marshal())
+	}
+	for _, out := range g.Traced {
+		b = appendBytesField(b, graphTraced, out.marshal())
+	}
+	for _, param := range g.Params {
+		b = appendBytesField(b, graphParams, MarshalShape(param))
+	}
+	return b
+}
+
+func (op *Op) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, opID, uint64(int64(op.ID)))
+	b = appendString(b, opOp, string(op.Op))
+	b = appendPackedInts(b, opInputs, op.Inputs)
+	for _, attr := range op.Attrs {
+		var ab []byte
+		ab = appendString(ab, attrName, attr.Name)
+		ab = appendString(ab, attrValue, attr.Value)
+		b = appendBytesField(b, opAttrs, ab)
+	}
+	if op.Shape != nil {
+		b = appendBytesField(b, opShape, MarshalShape(op.Shape))
+	}
+	return appendString(b, opGraph, op.Graph)
+}
+
+func (out *OutputNode) marshal() []byte {
+	b := appendVarintField(nil, outputNode, uint64(int64(out.Node)))
+	if out.Shape != nil {
+		b = appendBytesField(b, outputShape, MarshalShape(out.Shape))
+	}
+	return b
+}
+
+// UnmarshalGraph decodes a Graph message.
+func UnmarshalGraph(b []byte) (*Graph, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode graph: %v", err)
+	}
+	g := &Graph{}
+	for _, f := range fields {
+		switch f.num {
+		case graphName:
+			g.Name = string(f.bytes)
+		case graphOps:
+			var op *Op
+			op, err = unmarshalOp(f.bytes)
+			g.Ops = append(g.Ops, op)
+		case graphOutputs:
+			var out *OutputNode
+			out, err = unmarshalOutputNode(f.bytes)
+			g.Outputs = append(g.Outputs, out)
+		case graphTraced:
+			var out *OutputNode
+			out, err = unmarshalOutputNode(f.bytes)
+			g.Traced = append(g.Traced, out)
+		case graphParams:
+			var sh *shape.Shape
+			sh, err = UnmarshalShape(f.bytes)
+			g.Params = append(g.Params, sh)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode graph %q: %v", g.Name, err)
+		}
+	}
+	return g, nil
+}
+
+func unmarshalOp(b []byte) (*Op, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	op := &Op{}
+	for _, f := range fields {
+		switch f.num {
+		case opID:
+			op.ID = int(int64(f.varint))
+		case opOp:
+			op.Op = ops.OpID(f.bytes)
+		case opInputs:
+			op.Inputs, err = f.ints(op.Inputs)
+		case opAttrs:
+			var attr Attr
+			attr, err = unmarshalAttr(f.bytes)
+			op.Attrs = append(op.Attrs, attr)
+		case opShape:
+			op.Shape, err = UnmarshalShape(f.bytes)
+		case opGraph:
+			op.Graph = string(f.bytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("op %d: %v", op.ID, err)
+		}
+	}
+	return op, nil
+}
+
+func unmarshalAttr(b []byte) (Attr, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return Attr{}, err
+	}
+	var attr Attr
+	for _, f := range fields {
+		switch f.num {
+		case attrName:
+			attr.Name = string(f.bytes)
+		case attrValue:
+			attr.Value = string(f.bytes)
+		}
+	}
+	return attr, nil
+}
+
+func unmarshalOutputNode(b []byte) (*OutputNode, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	out := &OutputNode{}
+	for _, f := range fields {
+		switch f.num {
+		case outputNode:
+			out.Node = int(int64(f.varint))
+		case outputShape:
+			if out.Shape, err = UnmarshalShape(f.bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
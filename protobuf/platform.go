@@ -0,0 +1,489 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// Messages of the Platform service defined by platform.proto.
+type (
+	// PlatformInfo describes a platform and its devices.
+	PlatformInfo struct {
+		Name    string
+		Devices []*platform.Description
+	}
+
+	// DeviceRef identifies a device by its ordinal.
+	DeviceRef struct {
+		Device int
+	}
+
+	// SendRequest transfers an array to a device.
+	SendRequest struct {
+		Device int
+		Shape  *shape.Shape
+		Data   []byte
+	}
+
+	// Handle identifies an array stored on a device of the server.
+	Handle struct {
+		ID uint64
+		// Shape of the array, nil in requests.
+		Shape *shape.Shape
+	}
+
+	// HostData is the data of an array.
+	HostData struct {
+		Data []byte
+	}
+
+	// Timestamp is a timestamp of the clock of a device.
+	Timestamp struct {
+		Now time.Duration
+	}
+
+	// CompileRequest compiles a program for a device.
+	CompileRequest struct {
+		Device  int
+		Program *Program
+	}
+
+	// Runner identifies a program compiled by the server.
+	Runner struct {
+		ID uint64
+	}
+
+	// RunRequest runs a compiled program.
+	RunRequest struct {
+		Runner uint64
+		// Args are the identifiers of the handles passed as arguments.
+		Args []uint64
+	}
+
+	// RunReply returns the outputs and traced values of a run.
+	RunReply struct {
+		Outputs []*Handle
+		Traces  []*Handle
+	}
+)
+
+// Field numbers of the DeviceDescription message.
+const (
+	descKind          = 1
+	descName          = 2
+	descVendor        = 3
+	descOrdinal       = 4
+	descTotalMemory   = 5
+	descUnifiedMemory = 6
+	descAttributes    = 7
+)
+
+// Field numbers of the entries of map fields.
+const (
+	mapKey   = 1
+	mapValue = 2
+)
+
+// MarshalDescription encodes the description of a device as a DeviceDescription message.
+func MarshalDescription(desc *platform.Description) []byte {
+	var b []byte
+	b = appendVarintField(b, descKind, uint64(desc.Kind))
+	b = appendString(b, descName, desc.Name)
+	b = appendString(b, descVendor, desc.Vendor)
+	b = appendVarintField(b, descOrdinal, uint64(int64(desc.Ordinal)))
+	b = appendVarintField(b, descTotalMemory, uint64(desc.TotalMemory))
+	b = appendBool(b, descUnifiedMemory, desc.UnifiedMemory)
+	for _, key := range slices.Sorted(maps.Keys(desc.Attributes)) {
+		var entry []byte
+		entry = appendString(entry, mapKey, key)
+		entry = appendString(entry, mapValue, desc.Attributes[key])
+		b = appendBytesField(b, descAttributes, entry)
+	}
+	return b
+}
+
+// UnmarshalDescription decodes a DeviceDescription message.
+func UnmarshalDescription(b []byte) (*platform.Description, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode device description: %v", err)
+	}
+	desc := &platform.Description{}
+	for _, f := range fields {
+		switch f.num {
+		case descKind:
+			desc.Kind = platform.DeviceKind(f.varint)
+		case descName:
+			desc.Name = string(f.bytes)
+		case descVendor:
+			desc.Vendor = string(f.bytes)
+		case descOrdinal:
+			desc.Ordinal = int(int64(f.varint))
+		case descTotalMemory:
+			desc.TotalMemory = int64(f.varint)
+		case descUnifiedMemory:
+			desc.UnifiedMemory = f.varint != 0
+		case descAttributes:
+			entry, err := parseFields(f.bytes)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode device description: %v", err)
+			}
+			var key, value string
+			for _, f := range entry {
+				switch f.num {
+				case mapKey:
+					key = string(f.bytes)
+				case mapValue:
+					value = string(f.bytes)
+				}
+			}
+			if desc.Attributes == nil {
+				desc.Attributes = make(map[string]string)
+			}
+			desc.Attributes[key] = value
+		}
+	}
+	return desc, nil
+}
+
+// Field numbers of the PlatformInfo message.
+const (
+	infoName    = 1
+	infoDevices = 2
+)
+
+// Marshal encodes the description of a platform as a PlatformInfo message.
+func (m *PlatformInfo) Marshal() []byte {
+	var b []byte
+	b = appendString(b, infoName, m.Name)
+	for _, desc := range m.Devices {
+		b = appendBytesField(b, infoDevices, MarshalDescription(desc))
+	}
+	return b
+}
+
+// UnmarshalPlatformInfo decodes a PlatformInfo message.
+func UnmarshalPlatformInfo(b []byte) (*PlatformInfo, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode platform info: %v", err)
+	}
+	m := &PlatformInfo{}
+	for _, f := range fields {
+		switch f.num {
+		case infoName:
+			m.Name = string(f.bytes)
+		case infoDevices:
+			desc, err := UnmarshalDescription(f.bytes)
+			if err != nil {
+				return nil, err
+			}
+			m.Devices = append(m.Devices, desc)
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the DeviceRef message.
+const deviceRefDevice = 1
+
+// Marshal encodes the reference as a DeviceRef message.
+func (m *DeviceRef) Marshal() []byte {
+	return appendVarintField(nil, deviceRefDevice, uint64(int64(m.Device)))
+}
+
+// UnmarshalDeviceRef decodes a DeviceRef message.
+func UnmarshalDeviceRef(b []byte) (*DeviceRef, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode device reference: %v", err)
+	}
+	m := &DeviceRef{}
+	for _, f := range fields {
+		if f.num == deviceRefDevice {
+			m.Device = int(int64(f.varint))
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the SendRequest message.
+const (
+	sendDevice = 1
+	sendShape  = 2
+	sendData   = 3
+)
+
+// Marshal encodes the request as a SendRequest message.
+func (m *SendRequest) Marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, sendDevice, uint64(int64(m.Device)))
+	b = appendBytesField(b, sendShape, MarshalShape(m.Shape))
+	return appendBytesField(b, sendData, m.Data)
+}
+
+// UnmarshalSendRequest decodes a SendRequest message.
+func UnmarshalSendRequest(b []byte) (*SendRequest, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode send request: %v", err)
+	}
+	m := &SendRequest{}
+	for _, f := range fields {
+		switch f.num {
+		case sendDevice:
+			m.Device = int(int64(f.varint))
+		case sendShape:
+			if m.Shape, err = UnmarshalShape(f.bytes); err != nil {
+				return nil, err
+			}
+		case sendData:
+			m.Data = f.bytes
+		}
+	}
+	if m.Shape == nil {
+		return nil, fmt.Errorf("cannot decode send request: missing shape")
+	}
+	return m, nil
+}
+
+// Field numbers of the Handle message.
+const (
+	handleID    = 1
+	handleShape = 2
+)
+
+// Marshal encodes the handle as a Handle message.
+func (m *Handle) Marshal() []byte {
+	b := appendVarintField(nil, handleID, m.ID)
+	if m.Shape != nil {
+		b = appendBytesField(b, handleShape, MarshalShape(m.Shape))
+	}
+	return b
+}
+
+// UnmarshalHandle decodes a Handle message.
+func UnmarshalHandle(b []byte) (*Handle, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode handle: %v", err)
+	}
+	m := &Handle{}
+	for _, f := range fields {
+		switch f.num {
+		case handleID:
+			m.ID = f.varint
+		case handleShape:
+			if m.Shape, err = UnmarshalShape(f.bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the HostData message.
+const hostDataData = 1
+
+// Marshal encodes the data as a HostData message.
+func (m *HostData) Marshal() []byte {
+	return appendBytesField(nil, hostDataData, m.Data)
+}
+
+// UnmarshalHostData decodes a HostData message.
+// The data of the message references b.
+func UnmarshalHostData(b []byte) (*HostData, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode host data: %v", err)
+	}
+	m := &HostData{}
+	for _, f := range fields {
+		if f.num == hostDataData {
+			m.Data = f.bytes
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the Timestamp message.
+const timestampNanos = 1
+
+// Marshal encodes the timestamp as a Timestamp message.
+func (m *Timestamp) Marshal() []byte {
+	return appendVarintField(nil, timestampNanos, uint64(m.Now))
+}
+
+// UnmarshalTimestamp decodes a Timestamp message.
+func UnmarshalTimestamp(b []byte) (*Timestamp, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode timestamp: %v", err)
+	}
+	m := &Timestamp{}
+	for _, f := range fields {
+		if f.num == timestampNanos {
+			m.Now = time.Duration(f.varint)
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the CompileRequest message.
+const (
+	compileDevice  = 1
+	compileProgram = 2
+)
+
+// Marshal encodes the request as a CompileRequest message.
+func (m *CompileRequest) Marshal() []byte {
+	b := appendVarintField(nil, compileDevice, uint64(int64(m.Device)))
+	return appendBytesField(b, compileProgram, m.Program.Marshal())
+}
+
+// UnmarshalCompileRequest decodes a CompileRequest message.
+func UnmarshalCompileRequest(b []byte) (*CompileRequest, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode compile request: %v", err)
+	}
+	m := &CompileRequest{}
+	for _, f := range fields {
+		switch f.num {
+		case compileDevice:
+			m.Device = int(int64(f.varint))
+		case compileProgram:
+			if m.Program, err = UnmarshalProgram(f.bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if m.Program == nil {
+		return nil, fmt.Errorf("cannot decode compile request: missing program")
+	}
+	return m, nil
+}
+
+// Field numbers of the Runner message.
+const runnerID = 1
+
+// Marshal encodes the runner as a Runner message.
+func (m *Runner) Marshal() []byte {
+	return appendVarintField(nil, runnerID, m.ID)
+}
+
+// UnmarshalRunner decodes a Runner message.
+func UnmarshalRunner(b []byte) (*Runner, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode runner: %v", err)
+	}
+	m := &Runner{}
+	for _, f := range fields {
+		if f.num == runnerID {
+			m.ID = f.varint
+		}
+	}
+	return m, nil
+}
+
+// Field numbers of the RunRequest message.
+const (
+	runRunner = 1
+	runArgs   = 2
+)
+
+// Marshal encodes the request as a RunRequest message.
+func (m *RunRequest) Marshal() []byte {
+	b := appendVarintField(nil, runRunner, m.Runner)
+	args := make([]int, len(m.Args))
+	for i, arg := range m.Args {
+		args[i] = int(arg)
+	}
+	return appendPackedInts(b, runArgs, args)
+}
+
+// UnmarshalRunRequest decodes a RunRequest message.
+func UnmarshalRunRequest(b []byte) (*RunRequest, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode run request: %v", err)
+	}
+	m := &RunRequest{}
+	var args []int
+	for _, f := range fields {
+		switch f.num {
+		case runRunner:
+			m.Runner = f.varint
+		case runArgs:
+			if args, err = f.ints(args); err != nil {
+				return nil, fmt.Errorf("cannot decode run request: %v", err)
+			}
+		}
+	}
+	for _, arg := range args {
+		m.Args = append(m.Args, uint64(arg))
+	}
+	return m, nil
+}
+
+// Field numbers of the RunReply message.
+const (
+	runOutputs = 1
+	runTraces  = 2
+)
+
+// Marshal encodes the reply as a RunReply message.
+func (m *RunReply) Marshal() []byte {
+	var b []byte
+	for _, h := range m.Outputs {
+		b = appendBytesField(b, runOutputs, h.Marshal())
+	}
+	for _, h := range m.Traces {
+		b = appendBytesField(b, runTraces, h.Marshal())
+	}
+	return b
+}
+
+// UnmarshalRunReply decodes a RunReply message.
+func UnmarshalRunReply(b []byte) (*RunReply, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode run reply: %v", err)
+	}
+	m := &RunReply{}
+	for _, f := range fields {
+		if f.num != runOutputs && f.num != runTraces {
+			continue
+		}
+		h, err := UnmarshalHandle(f.bytes)
+		if err != nil {
+			return nil, err
+		}
+		if f.num == runOutputs {
+			m.Outputs = append(m.Outputs, h)
+		} else {
+			m.Traces = append(m.Traces, h)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/shape"
+)
+
+// Program is a built graph in a backend-independent format, together with its signature.
+type Program struct {
+	Name string
+	// Module is the StableHLO module, in the MLIR textual format, computing the outputs
+	// followed by the traced values of the graph.
+	Module []byte
+	// Params are the shapes of the arguments of the graph.
+	Params []*shape.Shape
+	// Outputs are the shapes of the outputs of the graph.
+	Outputs []*shape.Shape
+	// Traced are the shapes of the traced values of the graph.
+	Traced []*shape.Shape
+}
+
+// Field numbers of the Program message.
+const (
+	programName    = 1
+	programModule  = 2
+	programParams  = 3
+	programOutputs = 4
+	programTraced  = 5
+)
+
+// Marshal encodes the program as a Program message.
+func (p *Program) Marshal() []byte {
+	var b []byte
+	b = appendString(b, programName, p.Name)
+	b = appendBytesField(b, programModule, p.Module)
+	for _, sh := range p.Params {
+		b = appendBytesField(b, programParams, MarshalShape(sh))
+	}
+	for _, sh := range p.Outputs {
+		b = appendBytesField(b, programOutputs, MarshalShape(sh))
+	}
+	for _, sh := range p.Traced {
+		b = appendBytesField(b, programTraced, MarshalShape(sh))
+	}
+	return b
+}
+
+// UnmarshalProgram decodes a Program message.
+func UnmarshalProgram(b []byte) (*Program, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode program: %v", err)
+	}
+	p := &Program{}
+	for _, f := range fields {
+		var sh *shape.Shape
+		switch f.num {
+		case programName:
+			p.Name = string(f.bytes)
+		case programModule:
+			p.Module = append([]byte(nil), f.bytes...)
+		case programParams:
+			sh, err = UnmarshalShape(f.bytes)
+			p.Params = append(p.Params, sh)
+		case programOutputs:
+			sh, err = UnmarshalShape(f.bytes)
+			p.Outputs = append(p.Outputs, sh)
+		case programTraced:
+			sh, err = UnmarshalShape(f.bytes)
+			p.Traced = append(p.Traced, sh)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode program %q: %v", p.Name, err)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf_test
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/protobuf"
+	"github.com/gx-org/backend/shape"
+)
+
+func TestShapeWire(t *testing.T) {
+	// Shape{dtype: FLOAT32, axis_lengths: [2, 3]} as encoded by any protobuf implementation.
+	want := []byte{0x08, 0x08, 0x12, 0x02, 0x02, 0x03}
+	if got := protobuf.MarshalShape(shape.Of(dtype.Float32, 2, 3)); !bytes.Equal(got, want) {
+		t.Errorf("got encoding %x but want %x", got, want)
+	}
+}
+
+func TestShapeRoundTrip(t *testing.T) {
+	shapes := []*shape.Shape{
+		shape.Scalar(dtype.Bool),
+		{DType: dtype.Float64, AxisLengths: []int{4, 5}, AxisNames: []string{"batch", "features"}},
+		{DType: dtype.Float32, AxisLengths: []int{2, 3}, Layout: &shape.Layout{MinorToMajor: []int{0, 1}}},
+		{
+			DType:       dtype.Int32,
+			AxisLengths: []int{2, 3},
+			Quant: &dtype.Quantization{
+				Storage:    dtype.Int32,
+				Expressed:  dtype.Float32,
+				Axis:       1,
+				Scales:     []float64{0.5, 0.25, 2},
+				ZeroPoints: []int64{0, -1, 3},
+			},
+		},
+	}
+	for _, want := range shapes {
+		got, err := protobuf.UnmarshalShape(protobuf.MarshalShape(want))
+		if err != nil {
+			t.Errorf("%s: %v", want, err)
+			continue
+		}
+		if !got.Equal(want) || !reflect.DeepEqual(got.AxisNames, want.AxisNames) {
+			t.Errorf("got shape %s but want %s", got, want)
+		}
+	}
+}
+
+func TestGraphRoundTrip(t *testing.T) {
+	b := intercept.NewBackend(opstest.NewBackend(platformtest.New(1)), nopInterceptor{})
+	graph, err := b.NewOps("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := graph.(*intercept.Graph)
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Core().Reshape(x, []int{2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := shape.Of(dtype.Float32, 2, 3)
+	pb, err := protobuf.FromGraph(g, []*ops.OutputNode{{Node: y, Shape: out}}, nil, []*shape.Shape{shape.Of(dtype.Float32, 6)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := protobuf.UnmarshalGraph(pb.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Ops) != 2 {
+		t.Fatalf("got %d ops but want 2", len(got.Ops))
+	}
+	reshape := got.Ops[1]
+	if reshape.Op != ops.OpReshape || !reflect.DeepEqual(reshape.Inputs, []int{got.Ops[0].ID}) {
+		t.Errorf("got op %+v but want %s(%%%d)", reshape, ops.OpReshape, got.Ops[0].ID)
+	}
+	if reshape.Shape == nil || !reshape.Shape.Equal(out) {
+		t.Errorf("got op shape %s but want %s", reshape.Shape, out)
+	}
+	if len(got.Outputs) != 1 || got.Outputs[0].Node != reshape.ID || !got.Outputs[0].Shape.Equal(out) {
+		t.Errorf("got outputs %+v but want node %d", got.Outputs, reshape.ID)
+	}
+	if len(got.Params) != 1 || got.Name != "test" {
+		t.Errorf("got graph %q with %d params but want graph %q with 1 param", got.Name, len(got.Params), "test")
+	}
+}
+
+func TestProgramRoundTrip(t *testing.T) {
+	want := &protobuf.Program{
+		Name:    "prog",
+		Module:  []byte("module @prog {}"),
+		Params:  []*shape.Shape{shape.Of(dtype.Float16, 2, 3), shape.Scalar(dtype.Complex64)},
+		Outputs: []*shape.Shape{shape.Of(dtype.Int8, 2)},
+	}
+	got, err := protobuf.UnmarshalProgram(want.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != want.Name || !bytes.Equal(got.Module, want.Module) {
+		t.Errorf("got program %q with module %q but want %q with module %q", got.Name, got.Module, want.Name, want.Module)
+	}
+	equal := func(a, b []*shape.Shape) bool {
+		return slices.EqualFunc(a, b, func(x, y *shape.Shape) bool { return x.Equal(y) })
+	}
+	if !equal(got.Params, want.Params) || !equal(got.Outputs, want.Outputs) || len(got.Traced) != 0 {
+		t.Errorf("got signature %v -> %v, %v but want %v -> %v", got.Params, got.Outputs, got.Traced, want.Params, want.Outputs)
+	}
+}
+
+func TestPlatformInfoRoundTrip(t *testing.T) {
+	want := &protobuf.PlatformInfo{
+		Name: "gpu",
+		Devices: []*platform.Description{
+			{Kind: platform.CPU, Name: "host", Ordinal: 0, UnifiedMemory: true},
+			{Kind: platform.GPU, Name: "gpu", Vendor: "acme", Ordinal: 1, TotalMemory: 1 << 34, Attributes: map[string]string{"arch": "x", "cores": "64"}},
+		},
+	}
+	got, err := protobuf.UnmarshalPlatformInfo(want.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got platform info %+v but want %+v", got, want)
+	}
+}
+
+func TestRunReplyRoundTrip(t *testing.T) {
+	want := &protobuf.RunReply{
+		Outputs: []*protobuf.Handle{{ID: 3, Shape: shape.Of(dtype.Float32, 2)}, {ID: 4, Shape: shape.Scalar(dtype.Bool)}},
+		Traces:  []*protobuf.Handle{{ID: 5, Shape: shape.Of(dtype.Int64, 1, 2)}},
+	}
+	got, err := protobuf.UnmarshalRunReply(want.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal := func(a, b []*protobuf.Handle) bool {
+		return slices.EqualFunc(a, b, func(x, y *protobuf.Handle) bool { return x.ID == y.ID && x.Shape.Equal(y.Shape) })
+	}
+	if !equal(got.Outputs, want.Outputs) || !equal(got.Traces, want.Traces) {
+		t.Errorf("got run reply %v, %v but want %v, %v", got.Outputs, got.Traces, want.Outputs, want.Traces)
+	}
+}
+
+type nopInterceptor struct{}
+
+func (nopInterceptor) Before(*intercept.Call) error { return nil }
+
+func (nopInterceptor) After(*intercept.Call, error) {}
@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protobuf encodes the core types of the backend interfaces in the
+// protobuf wire format defined by backend.proto. platform.proto defines the
+// messages of the gRPC service of remote platforms (see the platform/remote package).
+//
+// Messages are encoded and decoded without depending on a protobuf runtime,
+// such that RPC services and persistent caches written in any language can
+// exchange shapes and graphs with Go programs using the code generated from the .proto files.
+package protobuf
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/shape"
+)
+
+// Field numbers of the Shape message.
+const (
+	shapeDType       = 1
+	shapeAxisLengths = 2
+	shapeAxisNames   = 3
+	shapeQuant       = 4
+	shapeBitPacked   = 5
+	shapeLayout      = 6
+)
+
+// Field numbers of the Quantization message.
+const (
+	quantStorage    = 1
+	quantExpressed  = 2
+	quantAxis       = 3
+	quantScales     = 4
+	quantZeroPoints = 5
+)
+
+// Field numbers of the Layout message.
+const (
+	layoutMinorToMajor = 1
+	layoutStrides      = 2
+	layoutTile         = 3
+)
+
+// MarshalShape encodes a shape as a Shape message.
+func MarshalShape(sh *shape.Shape) []byte {
+	var b []byte
+	b = appendVarintField(b, shapeDType, uint64(sh.DType))
+	b = appendPackedInts(b, shapeAxisLengths, sh.AxisLengths)
+	for _, name := range sh.AxisNames {
+		b = appendBytesField(b, shapeAxisNames, []byte(name))
+	}
+	if q := sh.Quant; q != nil {
+		b = appendBytesField(b, shapeQuant, marshalQuant(q))
+	}
+	b = appendBool(b, shapeBitPacked, sh.BitPacked)
+	if l := sh.Layout; l != nil {
+		b = appendBytesField(b, shapeLayout, marshalLayout(l))
+	}
+	return b
+}
+
+func marshalQuant(q *dtype.Quantization) []byte {
+	var b []byte
+	b = appendVarintField(b, quantStorage, uint64(q.Storage))
+	b = appendVarintField(b, quantExpressed, uint64(q.Expressed))
+	b = appendVarintField(b, quantAxis, uint64(int64(q.Axis)))
+	b = appendPackedDoubles(b, quantScales, q.Scales)
+	zeroPoints := make([]int, len(q.ZeroPoints))
+	for i, zp := range q.ZeroPoints {
+		zeroPoints[i] = int(zp)
+	}
+	return appendPackedInts(b, quantZeroPoints, zeroPoints)
+}
+
+func marshalLayout(l *shape.Layout) []byte {
+	var b []byte
+	b = appendPackedInts(b, layoutMinorToMajor, l.MinorToMajor)
+	b = appendPackedInts(b, layoutStrides, l.Strides)
+	return appendPackedInts(b, layoutTile, l.Tile)
+}
+
+// UnmarshalShape decodes a Shape message.
+// The decoded shape is checked with Shape.Check.
+func UnmarshalShape(b []byte) (*shape.Shape, error) {
+	sh, err := unmarshalShape(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := sh.Check(); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+func unmarshalShape(b []byte) (*shape.Shape, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode shape: %v", err)
+	}
+	sh := &shape.Shape{}
+	for _, f := range fields {
+		switch f.num {
+		case shapeDType:
+			sh.DType = dtype.DataType(f.varint)
+		case shapeAxisLengths:
+			sh.AxisLengths, err = f.ints(sh.AxisLengths)
+		case shapeAxisNames:
+			sh.AxisNames = append(sh.AxisNames, string(f.bytes))
+		case shapeQuant:
+			sh.Quant, err = unmarshalQuant(f.bytes)
+		case shapeBitPacked:
+			sh.BitPacked = f.varint != 0
+		case shapeLayout:
+			sh.Layout, err = unmarshalLayout(f.bytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode shape: %v", err)
+		}
+	}
+	return sh, nil
+}
+
+func unmarshalQuant(b []byte) (*dtype.Quantization, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	q := &dtype.Quantization{}
+	var zeroPoints []int
+	for _, f := range fields {
+		switch f.num {
+		case quantStorage:
+			q.Storage = dtype.DataType(f.varint)
+		case quantExpressed:
+			q.Expressed = dtype.DataType(f.varint)
+		case quantAxis:
+			q.Axis = int(int64(f.varint))
+		case quantScales:
+			q.Scales, err = f.doubles(q.Scales)
+		case quantZeroPoints:
+			zeroPoints, err = f.ints(zeroPoints)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, zp := range zeroPoints {
+		q.ZeroPoints = append(q.ZeroPoints, int64(zp))
+	}
+	return q, nil
+}
+
+func unmarshalLayout(b []byte) (*shape.Layout, error) {
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	l := &shape.Layout{}
+	for _, f := range fields {
+		switch f.num {
+		case layoutMinorToMajor:
+			l.MinorToMajor, err = f.ints(l.MinorToMajor)
+		case layoutStrides:
+			l.Strides, err = f.ints(l.Strides)
+		case layoutTile:
+			l.Tile, err = f.ints(l.Tile)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
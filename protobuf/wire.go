@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireBytes  = 2
+	wireI32    = 5
+)
+
+func appendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendVarintField(b, field, 1)
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendBytesField(b, field, []byte(v))
+}
+
+func appendPackedInts(b []byte, field int, vals []int) []byte {
+	if len(vals) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vals {
+		packed = binary.AppendUvarint(packed, uint64(int64(v)))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+func appendPackedDoubles(b []byte, field int, vals []float64) []byte {
+	if len(vals) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vals {
+		packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(v))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+// field is a decoded field of a message.
+type field struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+// parseFields decodes all the fields of a message.
+func parseFields(b []byte) ([]field, error) {
+	var fields []field
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid field tag")
+		}
+		b = b[n:]
+		f := field{num: int(tag >> 3), wireType: int(tag & 7)}
+		switch f.wireType {
+		case wireVarint:
+			if f.varint, n = binary.Uvarint(b); n <= 0 {
+				return nil, fmt.Errorf("invalid varint in field %d", f.num)
+			}
+			b = b[n:]
+		case wireI64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireI32:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.varint, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.bytes, b = b[n:n+int(length)], b[n+int(length):]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", f.wireType, f.num)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// ints decodes a repeated integer field, packed or not.
+func (f field) ints(dst []int) ([]int, error) {
+	if f.wireType == wireVarint {
+		return append(dst, int(int64(f.varint))), nil
+	}
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid packed varint in field %d", f.num)
+		}
+		dst, b = append(dst, int(int64(v))), b[n:]
+	}
+	return dst, nil
+}
+
+// doubles decodes a repeated double field, packed or not.
+func (f field) doubles(dst []float64) ([]float64, error) {
+	if f.wireType == wireI64 {
+		return append(dst, math.Float64frombits(f.varint)), nil
+	}
+	if len(f.bytes)%8 != 0 {
+		return nil, fmt.Errorf("invalid packed doubles in field %d", f.num)
+	}
+	for b := f.bytes; len(b) > 0; b = b[8:] {
+		dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	}
+	return dst, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gx-org/backend/platform"
+)
+
+// Factory creates a new backend given the configuration of its platform.
+type Factory func(platform.Config) (Backend, error)
+
+var registry = struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a backend available by name.
+// Packages implementing a backend typically call Register from an init function.
+// Register panics if a backend is registered twice with the same name.
+func Register(name string, factory Factory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if factory == nil {
+		panic("backend: Register factory is nil for backend " + name)
+	}
+	if _, dup := registry.factories[name]; dup {
+		panic("backend: Register called twice for backend " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// Registered returns the sorted names of all the registered backends.
+func Registered() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Config selects and configures a registered backend.
+type Config struct {
+	// Name of the backend. If empty, the only registered backend is used.
+	Name string
+
+	// Device is the ordinal of the default device, or -1 to let the platform choose.
+	Device int
+
+	// Platform configures the platform of the backend.
+	Platform platform.Config
+}
+
+// Environment variables read by ConfigFromEnv.
+const (
+	// EnvBackend is the name of the backend.
+	EnvBackend = "GX_BACKEND"
+	// EnvDevice is the ordinal of the default device.
+	EnvDevice = "GX_DEVICE"
+	// EnvVisibleDevices is a comma-separated list of visible device ordinals.
+	EnvVisibleDevices = "GX_VISIBLE_DEVICES"
+	// EnvMemoryLimit is the maximum number of bytes per device, with an optional
+	// KiB, MiB or GiB suffix.
+	EnvMemoryLimit = "GX_MEMORY_LIMIT"
+	// EnvMemoryFraction is the fraction of the memory of each device the platform can use.
+	EnvMemoryFraction = "GX_MEMORY_FRACTION"
+	// EnvAllocator is the name of the allocator.
+	EnvAllocator = "GX_ALLOCATOR"
+	// EnvDeterministic requests bit-exact results across runs if true.
+	EnvDeterministic = "GX_DETERMINISTIC"
+)
+
+// ConfigFromEnv returns a configuration read from environment variables.
+// Variables which are not set keep the default value of the configuration.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Name: os.Getenv(EnvBackend), Device: -1}
+	var err error
+	if v := os.Getenv(EnvDevice); v != "" {
+		if cfg.Device, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid %s=%q: %v", EnvDevice, v, err)
+		}
+	}
+	if v := os.Getenv(EnvVisibleDevices); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			ordinal, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s=%q: %v", EnvVisibleDevices, v, err)
+			}
+			cfg.Platform.VisibleDevices = append(cfg.Platform.VisibleDevices, ordinal)
+		}
+	}
+	if v := os.Getenv(EnvMemoryLimit); v != "" {
+		if cfg.Platform.MemoryLimit, err = parseBytes(v); err != nil {
+			return cfg, fmt.Errorf("invalid %s=%q: %v", EnvMemoryLimit, v, err)
+		}
+	}
+	if v := os.Getenv(EnvMemoryFraction); v != "" {
+		if cfg.Platform.MemoryFraction, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid %s=%q: %v", EnvMemoryFraction, v, err)
+		}
+	}
+	cfg.Platform.Allocator = os.Getenv(EnvAllocator)
+	if v := os.Getenv(EnvDeterministic); v != "" {
+		if cfg.Platform.Deterministic, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("invalid %s=%q: %v", EnvDeterministic, v, err)
+		}
+	}
+	return cfg, nil
+}
+
+// parseBytes parses a number of bytes with an optional binary suffix.
+func parseBytes(s string) (int64, error) {
+	for _, unit := range []struct {
+		suffix string
+		shift  uint
+	}{{"KiB", 10}, {"MiB", 20}, {"GiB", 30}} {
+		if num, ok := strings.CutSuffix(s, unit.suffix); ok {
+			n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+			return n << unit.shift, err
+		}
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// FromEnv creates a registered backend configured by environment variables.
+func FromEnv() (Backend, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return FromConfig(cfg)
+}
+
+// FromConfig creates a registered backend given a configuration.
+func FromConfig(cfg Config) (Backend, error) {
+	name := cfg.Name
+	if name == "" {
+		registered := Registered()
+		if len(registered) != 1 {
+			return nil, fmt.Errorf("no backend name specified: available backends are %v", registered)
+		}
+		name = registered[0]
+	}
+	registry.mu.RLock()
+	factory, ok := registry.factories[name]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("backend %q not registered: available backends are %v", name, Registered())
+	}
+	platCfg := cfg.Platform
+	if cfg.Device >= 0 {
+		platCfg.DevicePolicy = platform.ByOrdinal(cfg.Device)
+	}
+	if err := platCfg.Check(); err != nil {
+		return nil, fmt.Errorf("cannot create backend %q: %v", name, err)
+	}
+	b, err := factory(platCfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create backend %q: %v", name, err)
+	}
+	return b, nil
+}
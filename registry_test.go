@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+)
+
+var lastConfig platform.Config
+
+func init() {
+	backend.Register("opstest", func(cfg platform.Config) (backend.Backend, error) {
+		lastConfig = cfg
+		return opstest.NewBackend(platformtest.New(2)), nil
+	})
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(backend.EnvBackend, "opstest")
+	t.Setenv(backend.EnvDevice, "1")
+	t.Setenv(backend.EnvVisibleDevices, "0, 1")
+	t.Setenv(backend.EnvMemoryLimit, "512MiB")
+	t.Setenv(backend.EnvDeterministic, "true")
+	b, err := backend.FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if got, want := lastConfig.MemoryLimit, int64(512<<20); got != want {
+		t.Errorf("got memory limit %d but want %d", got, want)
+	}
+	if !slices.Equal(lastConfig.VisibleDevices, []int{0, 1}) || !lastConfig.Deterministic {
+		t.Errorf("got config %+v", lastConfig)
+	}
+	dev, err := platform.SelectDevice(b.Platform(), lastConfig.DevicePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.Ordinal() != 1 {
+		t.Errorf("got default device %d but want 1", dev.Ordinal())
+	}
+}
+
+func TestFromEnvErrors(t *testing.T) {
+	t.Setenv(backend.EnvBackend, "unknown")
+	if _, err := backend.FromEnv(); err == nil {
+		t.Errorf("unknown backend did not return an error")
+	}
+	t.Setenv(backend.EnvBackend, "opstest")
+	t.Setenv(backend.EnvMemoryLimit, "lots")
+	if _, err := backend.FromEnv(); err == nil {
+		t.Errorf("invalid memory limit did not return an error")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import "fmt"
+
+// NormalizeAxis returns the axis in the range [0, rank) given an axis in the range [-rank, rank).
+// Negative axes count from the last axis: -1 is the last axis, -rank the first.
+func NormalizeAxis(axis, rank int) (int, error) {
+	if axis < -rank || axis >= rank {
+		return 0, fmt.Errorf("axis %d out of range [%d, %d)", axis, -rank, rank)
+	}
+	if axis < 0 {
+		axis += rank
+	}
+	return axis, nil
+}
+
+// NormalizeAxes normalizes a list of axes using NormalizeAxis.
+// It returns an error if an axis is out of range or if two axes refer to the same axis.
+func NormalizeAxes(axes []int, rank int) ([]int, error) {
+	normalized := make([]int, len(axes))
+	for i, axis := range axes {
+		var err error
+		if normalized[i], err = NormalizeAxis(axis, rank); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkAxes(normalized, rank); err != nil {
+		return nil, fmt.Errorf("invalid axes %v: %v", axes, err)
+	}
+	return normalized, nil
+}
+
+// NormalizeAxis normalizes an axis of the shape using NormalizeAxis.
+func (s *Shape) NormalizeAxis(axis int) (int, error) {
+	return NormalizeAxis(axis, len(s.AxisLengths))
+}
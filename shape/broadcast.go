@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+// BroadcastableTo returns true if an array of shape s can be broadcast to the target shape
+// following NumPy rules: axes are aligned starting from the innermost one and each axis
+// of s must either have a length of 1 or the length of the corresponding target axis.
+// The returned axes can be passed to BroadcastInDim: the axis i of s maps to the axis axes[i] of the target.
+func (s *Shape) BroadcastableTo(target *Shape) (axes []int, ok bool) {
+	if s.DType != target.DType {
+		return nil, false
+	}
+	offset := len(target.AxisLengths) - len(s.AxisLengths)
+	if offset < 0 {
+		return nil, false
+	}
+	axes = make([]int, len(s.AxisLengths))
+	for i, length := range s.AxisLengths {
+		axis := offset + i
+		if length != 1 && length != target.AxisLengths[axis] {
+			return nil, false
+		}
+		axes[i] = axis
+	}
+	return axes, true
+}
+
+// BroadcastAxisLengths returns the axis lengths of the result of an element-wise operation
+// between arrays of the given axis lengths, following NumPy broadcasting rules.
+// ok is false if the axis lengths are not compatible.
+func BroadcastAxisLengths(all ...[]int) (axisLengths []int, ok bool) {
+	rank := 0
+	for _, lengths := range all {
+		rank = max(rank, len(lengths))
+	}
+	axisLengths = make([]int, rank)
+	for i := range axisLengths {
+		axisLengths[i] = 1
+	}
+	for _, lengths := range all {
+		offset := rank - len(lengths)
+		for i, length := range lengths {
+			current := &axisLengths[offset+i]
+			switch {
+			case length == *current || length == 1:
+			case *current == 1:
+				*current = length
+			default:
+				return nil, false
+			}
+		}
+	}
+	return axisLengths, true
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// Check returns an error if the shape is invalid, that is if its data type is unknown,
+// an axis length is negative, the size of the buffer overflows int, or if
+// its optional fields are inconsistent.
+func (s *Shape) Check() error {
+	if !dtype.IsValid(s.DType) {
+		return fmt.Errorf("invalid shape: unknown data type %d", uint32(s.DType))
+	}
+	if _, err := s.CheckedByteSize(); err != nil {
+		return fmt.Errorf("invalid shape %s: %v", s, err)
+	}
+	if s.AxisNames != nil && len(s.AxisNames) != len(s.AxisLengths) {
+		return fmt.Errorf("invalid shape %s: %d axis names for %d axes", s, len(s.AxisNames), len(s.AxisLengths))
+	}
+	if s.Symbols != nil && len(s.Symbols) != len(s.AxisLengths) {
+		return fmt.Errorf("invalid shape %s: %d symbols for %d axes", s, len(s.Symbols), len(s.AxisLengths))
+	}
+	if err := s.checkSymbols(); err != nil {
+		return fmt.Errorf("invalid shape %s: %v", s, err)
+	}
+	if s.Layout != nil {
+		if err := s.Layout.Check(s.AxisLengths); err != nil {
+			return fmt.Errorf("invalid shape %s: %v", s, err)
+		}
+	}
+	if q := s.Quant; q != nil {
+		if err := q.Check(); err != nil {
+			return fmt.Errorf("invalid shape %s: %v", s, err)
+		}
+		if q.Storage != s.DType {
+			return fmt.Errorf("invalid shape %s: quantization storage type %s does not match data type", s, q.Storage)
+		}
+		if q.IsPerAxis() {
+			if q.Axis >= len(s.AxisLengths) {
+				return fmt.Errorf("invalid shape %s: quantization axis %d out of range", s, q.Axis)
+			}
+			if len(q.Scales) != s.AxisLengths[q.Axis] {
+				return fmt.Errorf("invalid shape %s: %d quantization scales for axis %d of length %d", s, len(q.Scales), q.Axis, s.AxisLengths[q.Axis])
+			}
+		}
+	}
+	return nil
+}
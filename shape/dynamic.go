@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// AxisLength is the length of an axis: either a constant,
+// or a symbol bounded by an upper bound whose value is only known when a graph runs.
+type AxisLength struct {
+	// Symbol of a dynamic length. Empty for a constant length.
+	Symbol string
+	// Value is the constant length or the upper bound of a dynamic length.
+	Value int
+}
+
+// Static returns a constant axis length.
+func Static(length int) AxisLength {
+	return AxisLength{Value: length}
+}
+
+// Dynamic returns an axis length represented by a symbol and bounded by bound.
+func Dynamic(symbol string, bound int) AxisLength {
+	return AxisLength{Symbol: symbol, Value: bound}
+}
+
+// IsDynamic returns true if the length is only known when a graph runs.
+func (a AxisLength) IsDynamic() bool {
+	return a.Symbol != ""
+}
+
+// String returns the length, for example 3 or n<=8.
+func (a AxisLength) String() string {
+	if !a.IsDynamic() {
+		return strconv.Itoa(a.Value)
+	}
+	return a.Symbol + "<=" + strconv.Itoa(a.Value)
+}
+
+// OfAxes returns the shape of an array of a given data type and axis lengths,
+// some of which may be dynamic.
+func OfAxes(dt dtype.DataType, axes ...AxisLength) *Shape {
+	s := &Shape{DType: dt, AxisLengths: make([]int, len(axes))}
+	for i, axis := range axes {
+		s.AxisLengths[i] = axis.Value
+		if !axis.IsDynamic() {
+			continue
+		}
+		if s.Symbols == nil {
+			s.Symbols = make([]string, len(axes))
+		}
+		s.Symbols[i] = axis.Symbol
+	}
+	return s
+}
+
+// Symbol returns the symbol of an axis or an empty string if the axis is static.
+func (s *Shape) Symbol(axis int) string {
+	if axis < 0 || axis >= len(s.Symbols) {
+		return ""
+	}
+	return s.Symbols[axis]
+}
+
+// Axis returns the length of an axis.
+func (s *Shape) Axis(axis int) AxisLength {
+	return AxisLength{Symbol: s.Symbol(axis), Value: s.AxisLengths[axis]}
+}
+
+// Axes returns the lengths of all the axes.
+func (s *Shape) Axes() []AxisLength {
+	axes := make([]AxisLength, len(s.AxisLengths))
+	for i := range axes {
+		axes[i] = s.Axis(i)
+	}
+	return axes
+}
+
+// IsDynamic returns true if at least one axis of the shape is dynamic.
+func (s *Shape) IsDynamic() bool {
+	return slices.ContainsFunc(s.Symbols, func(sym string) bool { return sym != "" })
+}
+
+func (s *Shape) sameSymbols(o *Shape) bool {
+	if !s.IsDynamic() && !o.IsDynamic() {
+		return true
+	}
+	return slices.Equal(s.Symbols, o.Symbols)
+}
+
+// checkSymbols returns an error if a symbol is used with different upper bounds.
+func (s *Shape) checkSymbols() error {
+	bounds := make(map[string]int)
+	for axis, sym := range s.Symbols {
+		if sym == "" {
+			continue
+		}
+		if bound, ok := bounds[sym]; ok && bound != s.AxisLengths[axis] {
+			return fmt.Errorf("symbol %s has upper bounds %d and %d", sym, bound, s.AxisLengths[axis])
+		}
+		bounds[sym] = s.AxisLengths[axis]
+	}
+	return nil
+}
+
+// Bind returns a static copy of the shape in which the dynamic axes
+// have the lengths given by values.
+// It returns an error if a symbol has no value or if a value exceeds its upper bound.
+func (s *Shape) Bind(values map[string]int) (*Shape, error) {
+	cpy := s.Clone()
+	cpy.Symbols = nil
+	for axis, sym := range s.Symbols {
+		if sym == "" {
+			continue
+		}
+		length, ok := values[sym]
+		if !ok {
+			return nil, fmt.Errorf("cannot bind shape %s: no value for symbol %s", s, sym)
+		}
+		if length < 0 || length > s.AxisLengths[axis] {
+			return nil, fmt.Errorf("cannot bind shape %s: %s=%d out of range [0, %d]", s, sym, length, s.AxisLengths[axis])
+		}
+		cpy.AxisLengths[axis] = length
+	}
+	if cpy.Layout != nil {
+		// Explicit strides were computed for the upper bounds.
+		cpy.Layout.Strides = nil
+	}
+	return cpy, nil
+}
+
+// Bindings returns the values of the symbols of the shape such that it matches arg,
+// the static shape of an array. Values already present in values must match.
+// The returned map is values, allocated if nil.
+func (s *Shape) Bindings(arg *Shape, values map[string]int) (map[string]int, error) {
+	if s.DType != arg.DType || !s.Quant.Equal(arg.Quant) || len(s.AxisLengths) != len(arg.AxisLengths) || arg.IsDynamic() {
+		return nil, fmt.Errorf("shape %s does not match %s", arg, s)
+	}
+	if values == nil {
+		values = make(map[string]int)
+	}
+	for axis, length := range arg.AxisLengths {
+		sym := s.Symbol(axis)
+		if sym == "" {
+			if length != s.AxisLengths[axis] {
+				return nil, fmt.Errorf("shape %s does not match %s: axis %d has length %d", arg, s, axis, length)
+			}
+			continue
+		}
+		if length > s.AxisLengths[axis] {
+			return nil, fmt.Errorf("shape %s does not match %s: %s=%d exceeds upper bound %d", arg, s, sym, length, s.AxisLengths[axis])
+		}
+		if prev, ok := values[sym]; ok && prev != length {
+			return nil, fmt.Errorf("shape %s does not match %s: %s=%d but %s=%d before", arg, s, sym, length, sym, prev)
+		}
+		values[sym] = length
+	}
+	return values, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// Flags encoding the optional fields of a shape in its canonical encoding.
+const (
+	flagBitPacked = 1 << iota
+	flagLayout
+	flagQuant
+	flagTile
+	flagDynamic
+)
+
+// AppendCanonical appends a canonical binary encoding of the shape to b and returns the result.
+// Two shapes have the same encoding if and only if they are equal.
+// The encoding is stable across processes and versions of this package.
+func (s *Shape) AppendCanonical(b []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(s.DType))
+	b = appendInts(b, s.AxisLengths)
+	var strides []int
+	if s.Layout != nil {
+		if defaultStrides := (&Shape{AxisLengths: s.AxisLengths}).Strides(); !slices.Equal(s.Strides(), defaultStrides) {
+			strides = s.Strides()
+		}
+	}
+	flags := uint64(0)
+	if s.IsBitPacked() {
+		flags |= flagBitPacked
+	}
+	if strides != nil {
+		flags |= flagLayout
+	}
+	if s.Quant != nil {
+		flags |= flagQuant
+	}
+	if s.IsTiled() {
+		flags |= flagTile
+	}
+	if s.IsDynamic() {
+		flags |= flagDynamic
+	}
+	b = binary.AppendUvarint(b, flags)
+	if strides != nil {
+		b = appendInts(b, strides)
+	}
+	if s.IsTiled() {
+		b = appendInts(b, s.Layout.Tile)
+	}
+	if s.IsDynamic() {
+		for _, sym := range s.Symbols {
+			b = binary.AppendUvarint(b, uint64(len(sym)))
+			b = append(b, sym...)
+		}
+	}
+	if q := s.Quant; q != nil {
+		b = binary.AppendUvarint(b, uint64(q.Storage))
+		b = binary.AppendUvarint(b, uint64(q.Expressed))
+		b = binary.AppendVarint(b, int64(q.Axis))
+		b = binary.AppendUvarint(b, uint64(len(q.Scales)))
+		for _, scale := range q.Scales {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(scale))
+		}
+		b = binary.AppendUvarint(b, uint64(len(q.ZeroPoints)))
+		for _, zero := range q.ZeroPoints {
+			b = binary.AppendVarint(b, zero)
+		}
+	}
+	return b
+}
+
+func appendInts(b []byte, vals []int) []byte {
+	b = binary.AppendUvarint(b, uint64(len(vals)))
+	for _, val := range vals {
+		b = binary.AppendVarint(b, int64(val))
+	}
+	return b
+}
+
+// Hash returns a hash of the shape computed from its canonical encoding.
+// Equal shapes have the same hash.
+func (s *Shape) Hash() uint64 {
+	var buf [64]byte
+	h := fnv.New64a()
+	h.Write(s.AppendCanonical(buf[:0]))
+	return h.Sum64()
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Indexer converts multi-dimensional indices of an array into offsets,
+// in number of elements, in the buffer storing the array and back.
+// It takes the layout of the shape into account.
+type Indexer struct {
+	axisLengths []int
+	strides     []int
+	// order lists the axes from the slowest varying in memory to the fastest.
+	order []int
+}
+
+// NewIndexer returns an indexer for a shape.
+func NewIndexer(s *Shape) *Indexer {
+	strides := s.Strides()
+	order := axisRange(0, len(s.AxisLengths))
+	slices.SortStableFunc(order, func(a, b int) int { return strides[b] - strides[a] })
+	return &Indexer{
+		axisLengths: slices.Clone(s.AxisLengths),
+		strides:     strides,
+		order:       order,
+	}
+}
+
+// Check returns an error if the indices are out of the bounds of the array.
+func (ix *Indexer) Check(indices ...int) error {
+	if len(indices) != len(ix.axisLengths) {
+		return fmt.Errorf("got %d indices for an array of rank %d", len(indices), len(ix.axisLengths))
+	}
+	for axis, index := range indices {
+		if index < 0 || index >= ix.axisLengths[axis] {
+			return fmt.Errorf("index %d out of range [0, %d) for axis %d", index, ix.axisLengths[axis], axis)
+		}
+	}
+	return nil
+}
+
+// Offset returns the offset of the element at the given indices.
+// It panics if the indices are out of bounds.
+func (ix *Indexer) Offset(indices ...int) int {
+	if err := ix.Check(indices...); err != nil {
+		panic(err)
+	}
+	offset := 0
+	for axis, index := range indices {
+		offset += index * ix.strides[axis]
+	}
+	return offset
+}
+
+// Coords returns the indices of the element stored at a given offset.
+// It returns an error if no element is stored at that offset.
+func (ix *Indexer) Coords(offset int) ([]int, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("negative offset %d", offset)
+	}
+	indices := make([]int, len(ix.axisLengths))
+	rest := offset
+	for _, axis := range ix.order {
+		if ix.strides[axis] == 0 {
+			continue
+		}
+		indices[axis] = rest / ix.strides[axis]
+		rest %= ix.strides[axis]
+	}
+	if rest != 0 {
+		return nil, fmt.Errorf("no element is stored at offset %d", offset)
+	}
+	if err := ix.Check(indices...); err != nil {
+		return nil, fmt.Errorf("no element is stored at offset %d: %v", offset, err)
+	}
+	return indices, nil
+}
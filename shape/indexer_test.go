@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndexer(t *testing.T) {
+	tests := []struct {
+		desc    string
+		shape   *Shape
+		indices []int
+		offset  int
+	}{
+		{desc: "row-major", shape: f32(2, 3), indices: []int{1, 2}, offset: 5},
+		{desc: "column-major", shape: f32(2, 3).WithLayout(ColumnMajor(2)), indices: []int{1, 2}, offset: 5},
+		{desc: "column-major", shape: f32(2, 3).WithLayout(ColumnMajor(2)), indices: []int{0, 1}, offset: 2},
+		{desc: "padded", shape: f32(2, 3).WithLayout(&Layout{MinorToMajor: []int{1, 0}, Strides: []int{4, 1}}), indices: []int{1, 0}, offset: 4},
+		{desc: "atomic", shape: f32(), indices: []int{}, offset: 0},
+	}
+	for _, test := range tests {
+		ix := NewIndexer(test.shape)
+		if got := ix.Offset(test.indices...); got != test.offset {
+			t.Errorf("%s: Offset(%v) = %d but want %d", test.desc, test.indices, got, test.offset)
+		}
+		got, err := ix.Coords(test.offset)
+		if err != nil {
+			t.Errorf("%s: %v", test.desc, err)
+			continue
+		}
+		if !slices.Equal(got, test.indices) {
+			t.Errorf("%s: Coords(%d) = %v but want %v", test.desc, test.offset, got, test.indices)
+		}
+	}
+	padded := NewIndexer(f32(2, 3).WithLayout(&Layout{MinorToMajor: []int{1, 0}, Strides: []int{4, 1}}))
+	if _, err := padded.Coords(3); err == nil {
+		t.Errorf("expected an error for an offset in the padding")
+	}
+	if err := NewIndexer(f32(2, 3)).Check(2, 0); err == nil {
+		t.Errorf("expected an error for out of bound indices")
+	}
+}
+
+type testArray struct {
+	shape []int
+	flat  []float32
+}
+
+func (a testArray) Shape() []int    { return a.shape }
+func (a testArray) Flat() []float32 { return a.flat }
+
+func TestAt(t *testing.T) {
+	a := testArray{shape: []int{2, 3}, flat: []float32{0, 1, 2, 3, 4, 5}}
+	if got := At[float32](a, 1, 1); got != 4 {
+		t.Errorf("got %v but want 4", got)
+	}
+}
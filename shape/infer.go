@@ -0,0 +1,287 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// This file computes the shape of the result of the operators defined in the ops package.
+// Results always use the default layout.
+// Axis names are propagated when an axis of the result maps to a single axis of an operand.
+
+// derive returns a new shape with the data type of x and given axis lengths and names.
+func derive(x *Shape, axisLengths []int, axisNames []string) *Shape {
+	res := &Shape{DType: x.DType, AxisLengths: axisLengths, AxisNames: axisNames}
+	if x.Quant != nil && !x.Quant.IsPerAxis() {
+		res.Quant = x.Quant
+	}
+	return res
+}
+
+func checkAxis(axis, rank int) error {
+	if axis < 0 || axis >= rank {
+		return fmt.Errorf("axis %d out of range for rank %d", axis, rank)
+	}
+	return nil
+}
+
+// checkAxes returns an error if an axis is out of range or present more than once.
+func checkAxes(axes []int, rank int) error {
+	seen := make([]bool, rank)
+	for _, axis := range axes {
+		if err := checkAxis(axis, rank); err != nil {
+			return err
+		}
+		if seen[axis] {
+			return fmt.Errorf("axis %d specified more than once in %v", axis, axes)
+		}
+		seen[axis] = true
+	}
+	return nil
+}
+
+// ReshapeShape returns the shape of x reshaped to new axis lengths.
+func ReshapeShape(x *Shape, axisLengths []int) (*Shape, error) {
+	if _, err := Size64(axisLengths); err != nil {
+		return nil, fmt.Errorf("cannot reshape %s into %v: %v", x, axisLengths, err)
+	}
+	if got, want := Size(axisLengths), x.Size(); got != want {
+		return nil, fmt.Errorf("cannot reshape %s with %d elements into %v with %d elements", x, want, axisLengths, got)
+	}
+	return derive(x, slices.Clone(axisLengths), nil), nil
+}
+
+// CastShape returns the shape of x cast to a target data type.
+func CastShape(x *Shape, target dtype.DataType) (*Shape, error) {
+	return &Shape{DType: target, AxisLengths: slices.Clone(x.AxisLengths), AxisNames: slices.Clone(x.AxisNames)}, nil
+}
+
+// SliceShape returns the shape of an element of x along its outermost axis.
+func SliceShape(x *Shape, index int) (*Shape, error) {
+	if x.IsAtomic() {
+		return nil, fmt.Errorf("cannot slice atomic value of shape %s", x)
+	}
+	if index < 0 || index >= x.AxisLengths[0] {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, x.AxisLengths[0])
+	}
+	return derive(x, slices.Clone(x.AxisLengths[1:]), namesOf(x, axisRange(1, len(x.AxisLengths)))), nil
+}
+
+// ConcatShape returns the shape of the concatenation of arrays along an axis.
+// Errors identify the first input which does not match the first array.
+func ConcatShape(axis int, shapes ...*Shape) (*Shape, error) {
+	if len(shapes) == 0 {
+		return nil, fmt.Errorf("cannot concatenate an empty list of arrays")
+	}
+	first := shapes[0]
+	if err := checkAxis(axis, len(first.AxisLengths)); err != nil {
+		return nil, fmt.Errorf("cannot concatenate arrays of shape %s: %v", first, err)
+	}
+	axisLengths := slices.Clone(first.AxisLengths)
+	for i, sh := range shapes[1:] {
+		if err := checkSameShape(first, sh, axis); err != nil {
+			return nil, fmt.Errorf("cannot concatenate input %d with input 0 along axis %d: %v", i+1, axis, err)
+		}
+		axisLengths[axis] += sh.AxisLengths[axis]
+	}
+	return derive(first, axisLengths, slices.Clone(first.AxisNames)), nil
+}
+
+// StackShape returns the shape of arrays stacked along a new axis inserted at a given position.
+// All the arrays must have the same shape.
+func StackShape(axis int, shapes ...*Shape) (*Shape, error) {
+	if len(shapes) == 0 {
+		return nil, fmt.Errorf("cannot stack an empty list of arrays")
+	}
+	first := shapes[0]
+	if err := checkAxis(axis, len(first.AxisLengths)+1); err != nil {
+		return nil, fmt.Errorf("cannot stack arrays of shape %s: %v", first, err)
+	}
+	for i, sh := range shapes[1:] {
+		if err := checkSameShape(first, sh, -1); err != nil {
+			return nil, fmt.Errorf("cannot stack input %d with input 0: %v", i+1, err)
+		}
+	}
+	axisLengths := slices.Insert(slices.Clone(first.AxisLengths), axis, len(shapes))
+	var axisNames []string
+	if first.HasAxisNames() {
+		axisNames = slices.Insert(slices.Clone(first.AxisNames), axis, "")
+	}
+	return derive(first, axisLengths, axisNames), nil
+}
+
+// checkSameShape returns an error describing the first difference between the data types
+// and axis lengths of two shapes, ignoring a given axis.
+func checkSameShape(want, got *Shape, ignoreAxis int) error {
+	if got.DType != want.DType {
+		return fmt.Errorf("data type %s does not match %s", got.DType, want.DType)
+	}
+	if len(got.AxisLengths) != len(want.AxisLengths) {
+		return fmt.Errorf("rank %d of %s does not match rank %d of %s", len(got.AxisLengths), got, len(want.AxisLengths), want)
+	}
+	for axis, length := range got.AxisLengths {
+		if axis == ignoreAxis {
+			continue
+		}
+		if length != want.AxisLengths[axis] {
+			return fmt.Errorf("axis %d of length %d in %s does not match length %d in %s", axis, length, got, want.AxisLengths[axis], want)
+		}
+	}
+	return nil
+}
+
+// DotGeneralShape returns the shape of a general dot product between x and y.
+// The axes of the result are the batch axes, followed by the axes of x which are
+// neither batched nor reduced, followed by the axes of y which are neither batched nor reduced.
+func DotGeneralShape(x, y *Shape, batchAxes, reduceAxes [2][]int) (*Shape, error) {
+	if x.DType != y.DType {
+		return nil, fmt.Errorf("dot product between different data types: %s and %s", x.DType, y.DType)
+	}
+	if len(batchAxes[0]) != len(batchAxes[1]) {
+		return nil, fmt.Errorf("dot product with %d batch axes for x but %d batch axes for y", len(batchAxes[0]), len(batchAxes[1]))
+	}
+	if len(reduceAxes[0]) != len(reduceAxes[1]) {
+		return nil, fmt.Errorf("dot product with %d reduce axes for x but %d reduce axes for y", len(reduceAxes[0]), len(reduceAxes[1]))
+	}
+	operands := [2]*Shape{x, y}
+	for i, operand := range operands {
+		if err := checkAxes(slices.Concat(batchAxes[i], reduceAxes[i]), len(operand.AxisLengths)); err != nil {
+			return nil, fmt.Errorf("invalid dot product axes for %s: %v", operand, err)
+		}
+	}
+	var axisLengths []int
+	var axisNames []string
+	hasNames := x.HasAxisNames() || y.HasAxisNames()
+	for i, xAxis := range batchAxes[0] {
+		xLen, yLen := x.AxisLengths[xAxis], y.AxisLengths[batchAxes[1][i]]
+		if xLen != yLen {
+			return nil, fmt.Errorf("dot product batch axis %d of %s does not match batch axis %d of %s", xAxis, x, batchAxes[1][i], y)
+		}
+		axisLengths = append(axisLengths, xLen)
+		if hasNames {
+			axisNames = append(axisNames, x.AxisName(xAxis))
+		}
+	}
+	for i, xAxis := range reduceAxes[0] {
+		if x.AxisLengths[xAxis] != y.AxisLengths[reduceAxes[1][i]] {
+			return nil, fmt.Errorf("dot product reduce axis %d of %s does not match reduce axis %d of %s", xAxis, x, reduceAxes[1][i], y)
+		}
+	}
+	for i, operand := range operands {
+		for axis, length := range operand.AxisLengths {
+			if slices.Contains(batchAxes[i], axis) || slices.Contains(reduceAxes[i], axis) {
+				continue
+			}
+			axisLengths = append(axisLengths, length)
+			if hasNames {
+				axisNames = append(axisNames, operand.AxisName(axis))
+			}
+		}
+	}
+	return &Shape{DType: x.DType, AxisLengths: axisLengths, AxisNames: axisNames}, nil
+}
+
+// ReduceShape returns the shape of x reduced along a set of axes.
+func ReduceShape(x *Shape, axes []int) (*Shape, error) {
+	if err := checkAxes(axes, len(x.AxisLengths)); err != nil {
+		return nil, fmt.Errorf("cannot reduce %s: %v", x, err)
+	}
+	var axisLengths, kept []int
+	for axis, length := range x.AxisLengths {
+		if !slices.Contains(axes, axis) {
+			axisLengths = append(axisLengths, length)
+			kept = append(kept, axis)
+		}
+	}
+	return &Shape{DType: x.DType, AxisLengths: axisLengths, AxisNames: namesOf(x, kept)}, nil
+}
+
+// TransposeShape returns the shape of x with its axes permuted.
+// The axis i of the result is the axis permutation[i] of x.
+func TransposeShape(x *Shape, permutation []int) (*Shape, error) {
+	if err := CheckPermutation(permutation, len(x.AxisLengths)); err != nil {
+		return nil, fmt.Errorf("cannot transpose %s: %v", x, err)
+	}
+	axisLengths := make([]int, len(permutation))
+	for i, axis := range permutation {
+		axisLengths[i] = x.AxisLengths[axis]
+	}
+	return derive(x, axisLengths, namesOf(x, permutation)), nil
+}
+
+// ReverseShape returns the shape of x with its elements reversed along a set of axes,
+// which is a copy of the shape of x.
+func ReverseShape(x *Shape, axes []int) (*Shape, error) {
+	if err := checkAxes(axes, len(x.AxisLengths)); err != nil {
+		return nil, fmt.Errorf("cannot reverse %s: %v", x, err)
+	}
+	return derive(x, slices.Clone(x.AxisLengths), slices.Clone(x.AxisNames)), nil
+}
+
+// PadShape returns the shape of x padded with low elements before, high elements after
+// and interior elements between each element along each axis.
+// low and high can be negative to remove elements.
+func PadShape(x *Shape, low, high, interior []int) (*Shape, error) {
+	rank := len(x.AxisLengths)
+	if len(low) != rank || len(high) != rank || len(interior) != rank {
+		return nil, fmt.Errorf("cannot pad %s: padding %v, %v, %v does not match rank %d", x, low, high, interior, rank)
+	}
+	axisLengths := make([]int, rank)
+	for axis, length := range x.AxisLengths {
+		if interior[axis] < 0 {
+			return nil, fmt.Errorf("cannot pad %s: negative interior padding %d for axis %d", x, interior[axis], axis)
+		}
+		padded := low[axis] + length + high[axis]
+		if length > 0 {
+			padded += (length - 1) * interior[axis]
+		}
+		if padded < 0 {
+			return nil, fmt.Errorf("cannot pad %s: axis %d has a negative length %d after padding", x, axis, padded)
+		}
+		axisLengths[axis] = padded
+	}
+	return derive(x, axisLengths, slices.Clone(x.AxisNames)), nil
+}
+
+// BroadcastInDimShape returns the shape of x broadcast to target axis lengths.
+// The axis i of x is mapped to the axis broadcastAxes[i] of the result.
+func BroadcastInDimShape(x *Shape, axisLengths []int, broadcastAxes []int) (*Shape, error) {
+	if len(broadcastAxes) != len(x.AxisLengths) {
+		return nil, fmt.Errorf("cannot broadcast %s to %v: %d broadcast axes for rank %d", x, axisLengths, len(broadcastAxes), len(x.AxisLengths))
+	}
+	if _, err := Size64(axisLengths); err != nil {
+		return nil, fmt.Errorf("cannot broadcast %s to %v: %v", x, axisLengths, err)
+	}
+	if err := checkAxes(broadcastAxes, len(axisLengths)); err != nil {
+		return nil, fmt.Errorf("cannot broadcast %s to %v: %v", x, axisLengths, err)
+	}
+	for i, axis := range broadcastAxes {
+		if length := x.AxisLengths[i]; length != 1 && length != axisLengths[axis] {
+			return nil, fmt.Errorf("cannot broadcast %s to %v: axis %d of length %d cannot be broadcast to length %d", x, axisLengths, i, length, axisLengths[axis])
+		}
+	}
+	var axisNames []string
+	if x.HasAxisNames() {
+		axisNames = make([]string, len(axisLengths))
+		for i, axis := range broadcastAxes {
+			axisNames[axis] = x.AxisName(i)
+		}
+	}
+	return derive(x, slices.Clone(axisLengths), axisNames), nil
+}
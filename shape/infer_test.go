@@ -0,0 +1,267 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+func f32(axisLengths ...int) *Shape {
+	return Of(dtype.Float32, axisLengths...)
+}
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		desc string
+		got  func() (*Shape, error)
+		want *Shape
+	}{
+		{
+			desc: "concat",
+			got:  func() (*Shape, error) { return ConcatShape(1, f32(2, 3), f32(2, 4)) },
+			want: f32(2, 7),
+		},
+		{
+			desc: "matmul",
+			got: func() (*Shape, error) {
+				return DotGeneralShape(f32(2, 3), f32(3, 4), [2][]int{}, [2][]int{{1}, {0}})
+			},
+			want: f32(2, 4),
+		},
+		{
+			desc: "batched matmul",
+			got: func() (*Shape, error) {
+				return DotGeneralShape(f32(5, 2, 3), f32(5, 3, 4), [2][]int{{0}, {0}}, [2][]int{{2}, {1}})
+			},
+			want: f32(5, 2, 4),
+		},
+		{
+			desc: "reduce",
+			got:  func() (*Shape, error) { return ReduceShape(f32(2, 3, 4), []int{0, 2}) },
+			want: f32(3),
+		},
+		{
+			desc: "transpose",
+			got:  func() (*Shape, error) { return TransposeShape(f32(2, 3, 4), []int{2, 0, 1}) },
+			want: f32(4, 2, 3),
+		},
+		{
+			desc: "reverse",
+			got:  func() (*Shape, error) { return ReverseShape(f32(2, 3), []int{1}) },
+			want: f32(2, 3),
+		},
+		{
+			desc: "pad",
+			got:  func() (*Shape, error) { return PadShape(f32(3, 2), []int{1, -1}, []int{2, 0}, []int{1, 0}) },
+			want: f32(8, 1),
+		},
+		{
+			desc: "broadcast",
+			got:  func() (*Shape, error) { return BroadcastInDimShape(f32(1, 3), []int{2, 4, 3}, []int{0, 2}) },
+			want: f32(2, 4, 3),
+		},
+		{
+			desc: "reshape",
+			got:  func() (*Shape, error) { return ReshapeShape(f32(2, 3), []int{3, 2}) },
+			want: f32(3, 2),
+		},
+	}
+	for _, test := range tests {
+		got, err := test.got()
+		if err != nil {
+			t.Errorf("%s: %v", test.desc, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("%s: got %s but want %s", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestInferErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  func() error
+	}{
+		{
+			desc: "concat mismatch",
+			err:  func() error { _, err := ConcatShape(1, f32(2, 3), f32(3, 4)); return err },
+		},
+		{
+			desc: "matmul mismatch",
+			err: func() error {
+				_, err := DotGeneralShape(f32(2, 3), f32(4, 4), [2][]int{}, [2][]int{{1}, {0}})
+				return err
+			},
+		},
+		{
+			desc: "duplicate reduce axis",
+			err:  func() error { _, err := ReduceShape(f32(2, 3), []int{0, 0}); return err },
+		},
+		{
+			desc: "reverse axis out of range",
+			err:  func() error { _, err := ReverseShape(f32(2, 3), []int{2}); return err },
+		},
+		{
+			desc: "reshape size",
+			err:  func() error { _, err := ReshapeShape(f32(2, 3), []int{4}); return err },
+		},
+		{
+			desc: "reshape negative lengths",
+			err:  func() error { _, err := ReshapeShape(f32(2, 3), []int{-2, -3}); return err },
+		},
+		{
+			desc: "broadcast negative length",
+			err:  func() error { _, err := BroadcastInDimShape(f32(1), []int{-1}, []int{0}); return err },
+		},
+		{
+			desc: "broadcast negative unmapped length",
+			err:  func() error { _, err := BroadcastInDimShape(f32(3), []int{-2, 3}, []int{1}); return err },
+		},
+	}
+	for _, test := range tests {
+		if err := test.err(); err == nil {
+			t.Errorf("%s: expected an error", test.desc)
+		}
+	}
+}
+
+func TestInferAxisNames(t *testing.T) {
+	x, err := f32(2, 3, 4).WithAxisNames("batch", "", "features")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transposed, err := TransposeShape(x, []int{2, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := transposed.String(), "[features:4][batch:2][3]float32"; got != want {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	reduced, err := ReduceShape(x, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if axis, err := reduced.AxisIndex("features"); err != nil || axis != 1 {
+		t.Errorf("AxisIndex(features) = %d, %v but want 1", axis, err)
+	}
+	reversed, err := ReverseShape(x, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reversed.String(), x.String(); got != want {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	reversed.AxisNames[0] = "time"
+	reversed.AxisLengths[0] = 5
+	if got, want := x.String(), "[batch:2][3][features:4]float32"; got != want {
+		t.Errorf("changing the reversed shape changed its operand to %s but want %s", got, want)
+	}
+}
+
+func TestSwapAxes(t *testing.T) {
+	got, err := f32(2, 3, 4).SwapAxes(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := f32(4, 3, 2); !got.Equal(want) {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	if _, err := f32(2, 3).SwapAxes(0, 2); err == nil {
+		t.Errorf("expected an error for an out of range axis")
+	}
+	perm := []int{2, 0, 1}
+	inv := InversePermutation(perm)
+	back, err := f32(2, 3, 4).Permute(perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back, err = back.Permute(inv); err != nil || !back.Equal(f32(2, 3, 4)) {
+		t.Errorf("inverse permutation returned %s, %v", back, err)
+	}
+}
+
+func TestSliceShapes(t *testing.T) {
+	got, err := StridedSliceShape(f32(10, 4), []int{1, 0}, []int{8, 4}, []int{3, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := f32(3, 4); !got.Equal(want) {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	if _, err := StridedSliceShape(f32(10), []int{2}, []int{11}, nil); err == nil {
+		t.Errorf("expected an error for an out of range limit")
+	}
+	if _, err := DynamicSliceShape(f32(10, 4), []int{5, 5}); err == nil {
+		t.Errorf("expected an error for a size larger than the axis")
+	}
+	if _, err := DynamicUpdateSliceShape(f32(10, 4), f32(2, 4)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNormalizeAxes(t *testing.T) {
+	got, err := NormalizeAxes([]int{-1, 0, -2}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{3, 0, 2}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	for _, axes := range [][]int{{4}, {-5}, {1, -3}} {
+		if _, err := NormalizeAxes(axes, 4); err == nil {
+			t.Errorf("expected an error for axes %v", axes)
+		}
+	}
+}
+
+func TestStackShape(t *testing.T) {
+	got, err := StackShape(1, f32(2, 3), f32(2, 3), f32(2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := f32(2, 3, 3); !got.Equal(want) {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	_, err = ConcatShape(0, f32(2, 3), f32(1, 3), f32(2, 4))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if want := "cannot concatenate input 2 with input 0 along axis 0: axis 1 of length 4 in [2][4]float32 does not match length 3 in [2][3]float32"; err.Error() != want {
+		t.Errorf("got error:\n%s\nbut want:\n%s", err, want)
+	}
+	if _, err := StackShape(0, f32(2, 3), f32(3, 2)); err == nil {
+		t.Errorf("expected an error when stacking different shapes")
+	}
+}
+
+func TestBroadcastableTo(t *testing.T) {
+	axes, ok := f32(3, 1).BroadcastableTo(f32(2, 3, 4))
+	if !ok {
+		t.Fatal("[3][1] is not broadcastable to [2][3][4]")
+	}
+	if want := []int{1, 2}; !slices.Equal(axes, want) {
+		t.Errorf("got axes %v but want %v", axes, want)
+	}
+	if _, ok := f32(2).BroadcastableTo(f32(2, 3)); ok {
+		t.Errorf("[2] is broadcastable to [2][3]")
+	}
+	if got, ok := BroadcastAxisLengths([]int{3, 1}, []int{2, 1, 4}); !ok || !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("got %v, %v but want [2 3 4], true", got, ok)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import "sync"
+
+// Interner returns a single canonical instance for all the shapes which are identical,
+// including their axis names.
+// Canonical shapes are shared and must never be modified.
+// An Interner is safe for concurrent use.
+type Interner struct {
+	mu     sync.Mutex
+	shapes map[string]*Shape
+}
+
+// NewInterner returns a new empty interner.
+// All its shapes are released when the interner is garbage collected.
+func NewInterner() *Interner {
+	return &Interner{shapes: make(map[string]*Shape)}
+}
+
+func internKey(s *Shape, buf []byte) []byte {
+	buf = s.AppendCanonical(buf)
+	if !s.HasAxisNames() {
+		return buf
+	}
+	for _, name := range s.AxisNames {
+		buf = append(buf, name...)
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// Intern returns the canonical instance of a shape.
+// A copy of s becomes the canonical instance if no identical shape has been interned before.
+func (in *Interner) Intern(s *Shape) *Shape {
+	var buf [64]byte
+	key := internKey(s, buf[:0])
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if canonical, ok := in.shapes[string(key)]; ok {
+		return canonical
+	}
+	canonical := s.Clone()
+	in.shapes[string(key)] = canonical
+	return canonical
+}
+
+// Len returns the number of canonical shapes stored by the interner.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.shapes)
+}
+
+var global = NewInterner()
+
+// Intern returns the canonical instance of a shape from a process-wide interner.
+// Shapes interned by this function are never released.
+func Intern(s *Shape) *Shape {
+	return global.Intern(s)
+}
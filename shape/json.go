@@ -0,0 +1,115 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// The structures below define the stable JSON representation of a shape.
+// Field names must not change.
+type (
+	jsonShape struct {
+		DType     dtype.DataType `json:"dtype"`
+		Axes      []int          `json:"axes"`
+		Names     []string       `json:"names,omitempty"`
+		Symbols   []string       `json:"symbols,omitempty"`
+		BitPacked bool           `json:"bit_packed,omitempty"`
+		Layout    *jsonLayout    `json:"layout,omitempty"`
+		Quant     *jsonQuant     `json:"quant,omitempty"`
+	}
+
+	jsonLayout struct {
+		MinorToMajor []int `json:"minor_to_major"`
+		Strides      []int `json:"strides,omitempty"`
+		Tile         []int `json:"tile,omitempty"`
+	}
+
+	jsonQuant struct {
+		Storage    dtype.DataType `json:"storage"`
+		Expressed  dtype.DataType `json:"expressed"`
+		Axis       int            `json:"axis"`
+		Scales     []float64      `json:"scales"`
+		ZeroPoints []int64        `json:"zero_points"`
+	}
+)
+
+// MarshalJSON encodes the shape in JSON, for example:
+//
+//	{"dtype":"float32","axes":[2,3]}
+func (s *Shape) MarshalJSON() ([]byte, error) {
+	js := jsonShape{
+		DType:     s.DType,
+		Axes:      s.AxisLengths,
+		Names:     s.AxisNames,
+		Symbols:   s.Symbols,
+		BitPacked: s.IsBitPacked(),
+	}
+	if js.Axes == nil {
+		js.Axes = []int{}
+	}
+	if l := s.Layout; l != nil {
+		js.Layout = &jsonLayout{MinorToMajor: l.MinorToMajor, Strides: l.Strides, Tile: l.Tile}
+	}
+	if q := s.Quant; q != nil {
+		js.Quant = &jsonQuant{
+			Storage:    q.Storage,
+			Expressed:  q.Expressed,
+			Axis:       q.Axis,
+			Scales:     q.Scales,
+			ZeroPoints: q.ZeroPoints,
+		}
+	}
+	return json.Marshal(js)
+}
+
+// UnmarshalJSON decodes a shape encoded by MarshalJSON.
+// The decoded shape is checked with Shape.Check.
+func (s *Shape) UnmarshalJSON(data []byte) error {
+	var js jsonShape
+	if err := json.Unmarshal(data, &js); err != nil {
+		return fmt.Errorf("cannot unmarshal shape: %v", err)
+	}
+	res := Shape{
+		DType:       js.DType,
+		AxisLengths: js.Axes,
+		AxisNames:   js.Names,
+		Symbols:     js.Symbols,
+		BitPacked:   js.BitPacked,
+	}
+	if len(res.AxisLengths) == 0 {
+		res.AxisLengths = nil
+	}
+	if l := js.Layout; l != nil {
+		res.Layout = &Layout{MinorToMajor: l.MinorToMajor, Strides: l.Strides, Tile: l.Tile}
+	}
+	if q := js.Quant; q != nil {
+		res.Quant = &dtype.Quantization{
+			Storage:    q.Storage,
+			Expressed:  q.Expressed,
+			Axis:       q.Axis,
+			Scales:     q.Scales,
+			ZeroPoints: q.ZeroPoints,
+		}
+	}
+	if err := res.Check(); err != nil {
+		return fmt.Errorf("cannot unmarshal shape: %v", err)
+	}
+	*s = res
+	return nil
+}
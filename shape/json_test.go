@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		shape *Shape
+		json  string
+	}{
+		{
+			shape: &Shape{DType: dtype.Float32},
+			json:  `{"dtype":"float32","axes":[]}`,
+		},
+		{
+			shape: &Shape{DType: dtype.Int64, AxisLengths: []int{2, 3}, AxisNames: []string{"batch", ""}},
+			json:  `{"dtype":"int64","axes":[2,3],"names":["batch",""]}`,
+		},
+		{
+			shape: f32(2, 3).WithLayout(ColumnMajor(2)),
+			json:  `{"dtype":"float32","axes":[2,3],"layout":{"minor_to_major":[0,1]}}`,
+		},
+		{
+			shape: &Shape{DType: dtype.Int32, AxisLengths: []int{4}, Quant: &dtype.Quantization{
+				Storage:    dtype.Int32,
+				Expressed:  dtype.Float32,
+				Axis:       dtype.PerTensor,
+				Scales:     []float64{0.5},
+				ZeroPoints: []int64{1},
+			}},
+			json: `{"dtype":"int32","axes":[4],"quant":{"storage":"int32","expressed":"float32","axis":-1,"scales":[0.5],"zero_points":[1]}}`,
+		},
+	}
+	for i, test := range tests {
+		data, err := json.Marshal(test.shape)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.json {
+			t.Errorf("test %d: got %s but want %s", i, data, test.json)
+		}
+		var got Shape
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !got.Equal(test.shape) || got.String() != test.shape.String() {
+			t.Errorf("test %d: got %v but want %v", i, &got, test.shape)
+		}
+	}
+	var sh Shape
+	if err := json.Unmarshal([]byte(`{"dtype":"float32","axes":[-1]}`), &sh); err == nil {
+		t.Errorf("expected an error for a negative axis length")
+	}
+}
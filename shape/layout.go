@@ -0,0 +1,231 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Layout describes how the elements of an array are stored in memory.
+// A nil layout is the default row-major layout: the last axis varies the fastest
+// and elements are stored without gaps.
+// Backends which do not support layouts use the default layout and reject shapes
+// with other layouts instead of misreading their elements.
+type Layout struct {
+	// MinorToMajor lists the axes from the fastest varying in memory to the slowest.
+	// For example, {1, 0} is row-major and {0, 1} is column-major for a matrix.
+	MinorToMajor []int
+
+	// Strides is the distance, in number of elements, between two consecutive
+	// elements along each axis. Axes are listed in the logical order of the shape.
+	// If nil, the strides are computed from MinorToMajor assuming
+	// elements are stored without gaps.
+	Strides []int
+
+	// Tile is the shape of the blocks in which the elements are physically stored,
+	// as used by TPU-like devices. The last element of Tile applies to the fastest varying
+	// axis, the one before to the second fastest varying axis, and so on.
+	// Axes covered by the tile are padded to a multiple of the tile length.
+	// Tiling describes device memory only: a tiled layout cannot have explicit strides.
+	Tile []int
+}
+
+// RowMajor returns the default row-major layout for a given rank.
+func RowMajor(rank int) *Layout {
+	order := make([]int, rank)
+	for i := range order {
+		order[i] = rank - 1 - i
+	}
+	return &Layout{MinorToMajor: order}
+}
+
+// ColumnMajor returns the column-major layout for a given rank.
+func ColumnMajor(rank int) *Layout {
+	order := make([]int, rank)
+	for i := range order {
+		order[i] = i
+	}
+	return &Layout{MinorToMajor: order}
+}
+
+// Check returns an error if the layout is invalid for a given list of axis lengths.
+func (l *Layout) Check(axisLengths []int) error {
+	rank := len(axisLengths)
+	if len(l.MinorToMajor) != rank {
+		return fmt.Errorf("layout minor-to-major order %v does not match rank %d", l.MinorToMajor, rank)
+	}
+	if err := CheckPermutation(l.MinorToMajor, rank); err != nil {
+		return fmt.Errorf("invalid layout minor-to-major order: %v", err)
+	}
+	if err := l.checkTile(rank); err != nil {
+		return err
+	}
+	if l.Strides == nil {
+		return nil
+	}
+	if len(l.Strides) != rank {
+		return fmt.Errorf("layout has %d strides for rank %d", len(l.Strides), rank)
+	}
+	for axis, stride := range l.Strides {
+		if stride < 0 {
+			return fmt.Errorf("layout has a negative stride %d for axis %d", stride, axis)
+		}
+	}
+	return nil
+}
+
+func (l *Layout) checkTile(rank int) error {
+	if l.Tile == nil {
+		return nil
+	}
+	if l.Strides != nil {
+		return fmt.Errorf("layout cannot have both strides and a tile")
+	}
+	if len(l.Tile) > rank {
+		return fmt.Errorf("layout tile %v has more axes than rank %d", l.Tile, rank)
+	}
+	for _, length := range l.Tile {
+		if length <= 0 {
+			return fmt.Errorf("layout tile %v has a non-positive length", l.Tile)
+		}
+	}
+	return nil
+}
+
+// tiledAxisLengths returns the axis lengths padded to a multiple of the tile.
+func (l *Layout) tiledAxisLengths(axisLengths []int) []int {
+	padded := slices.Clone(axisLengths)
+	for i, tileLength := range slices.Backward(l.Tile) {
+		axis := l.MinorToMajor[len(l.Tile)-1-i]
+		padded[axis] = (padded[axis] + tileLength - 1) / tileLength * tileLength
+	}
+	return padded
+}
+
+// Equal returns true if o is the same layout.
+func (l *Layout) Equal(o *Layout) bool {
+	if l == nil || o == nil {
+		return l == o
+	}
+	return slices.Equal(l.MinorToMajor, o.MinorToMajor) &&
+		slices.Equal(l.Strides, o.Strides) &&
+		slices.Equal(l.Tile, o.Tile)
+}
+
+func (l *Layout) String() string {
+	var suffix string
+	if l.Strides != nil {
+		suffix += fmt.Sprintf(":%v", l.Strides)
+	}
+	if l.Tile != nil {
+		suffix += fmt.Sprintf("T%v", l.Tile)
+	}
+	return fmt.Sprintf("{%v%s}", l.MinorToMajor, suffix)
+}
+
+// denseStrides returns the strides of elements stored without gaps given an axis order.
+func denseStrides(axisLengths, minorToMajor []int) []int {
+	strides := make([]int, len(axisLengths))
+	stride := 1
+	for _, axis := range minorToMajor {
+		strides[axis] = stride
+		stride *= axisLengths[axis]
+	}
+	return strides
+}
+
+// Strides returns the distance, in number of elements, between two consecutive
+// elements along each axis.
+func (s *Shape) Strides() []int {
+	if s.Layout == nil {
+		return denseStrides(s.AxisLengths, RowMajor(len(s.AxisLengths)).MinorToMajor)
+	}
+	if s.Layout.Strides != nil {
+		return slices.Clone(s.Layout.Strides)
+	}
+	return denseStrides(s.AxisLengths, s.Layout.MinorToMajor)
+}
+
+// IsContiguous returns true if the elements are stored in row-major order without gaps.
+func (s *Shape) IsContiguous() bool {
+	if s.Layout == nil {
+		return true
+	}
+	if s.IsTiled() {
+		return false
+	}
+	want := denseStrides(s.AxisLengths, RowMajor(len(s.AxisLengths)).MinorToMajor)
+	got := s.Strides()
+	for axis, length := range s.AxisLengths {
+		if length > 1 && got[axis] != want[axis] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithLayout returns a copy of the shape with a given layout.
+func (s *Shape) WithLayout(l *Layout) *Shape {
+	cpy := *s
+	cpy.AxisLengths = slices.Clone(s.AxisLengths)
+	cpy.Layout = l
+	return &cpy
+}
+
+// storageSize returns the number of elements spanned in memory by the shape.
+func (s *Shape) storageSize() int {
+	if s.Layout == nil || s.Layout.Strides == nil {
+		return s.Size()
+	}
+	span := 1
+	for axis, length := range s.AxisLengths {
+		if length == 0 {
+			return 0
+		}
+		span += (length - 1) * s.Layout.Strides[axis]
+	}
+	return span
+}
+
+// IsTiled returns true if the layout of the shape has a tile which is not trivial.
+func (s *Shape) IsTiled() bool {
+	if s.Layout == nil {
+		return false
+	}
+	return slices.ContainsFunc(s.Layout.Tile, func(length int) bool { return length != 1 })
+}
+
+// PhysicalByteSize returns the number of bytes occupied by the array on a device,
+// including the padding introduced by tiling.
+func (s *Shape) PhysicalByteSize() int {
+	if !s.IsTiled() {
+		return s.ByteSize()
+	}
+	padded := &Shape{
+		DType:       s.DType,
+		AxisLengths: s.Layout.tiledAxisLengths(s.AxisLengths),
+		BitPacked:   s.BitPacked,
+	}
+	return padded.ByteSize()
+}
+
+// tile returns the tile of the layout or nil if the shape is not tiled.
+func (s *Shape) tile() []int {
+	if !s.IsTiled() {
+		return nil
+	}
+	return s.Layout.Tile
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+func TestLayout(t *testing.T) {
+	rowMajor := &Shape{DType: dtype.Float32, AxisLengths: []int{2, 3}}
+	if got, want := rowMajor.Strides(), []int{3, 1}; !slices.Equal(got, want) {
+		t.Errorf("row-major strides: got %v but want %v", got, want)
+	}
+	if !rowMajor.IsContiguous() {
+		t.Errorf("%v is not contiguous", rowMajor)
+	}
+	if explicit := rowMajor.WithLayout(RowMajor(2)); !explicit.Equal(rowMajor) {
+		t.Errorf("explicit row-major layout is not equal to the default layout")
+	}
+	colMajor := rowMajor.WithLayout(ColumnMajor(2))
+	if got, want := colMajor.Strides(), []int{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("column-major strides: got %v but want %v", got, want)
+	}
+	if colMajor.IsContiguous() {
+		t.Errorf("column-major shape %v is contiguous", colMajor)
+	}
+	if colMajor.Equal(rowMajor) {
+		t.Errorf("column-major shape is equal to row-major shape")
+	}
+	if rowMajor.Layout != nil {
+		t.Errorf("WithLayout modified the original shape")
+	}
+	padded := rowMajor.WithLayout(&Layout{MinorToMajor: []int{1, 0}, Strides: []int{4, 1}})
+	if got, want := padded.ByteSize(), 7*dtype.Float32Size; got != want {
+		t.Errorf("padded byte size: got %d but want %d", got, want)
+	}
+	if err := (&Layout{MinorToMajor: []int{0, 0}}).Check(rowMajor.AxisLengths); err == nil {
+		t.Errorf("expected an error for an invalid permutation")
+	}
+}
+
+func TestTiledLayout(t *testing.T) {
+	sh := (&Shape{DType: dtype.Float32, AxisLengths: []int{3, 130}}).WithLayout(&Layout{
+		MinorToMajor: []int{1, 0},
+		Tile:         []int{8, 128},
+	})
+	if err := sh.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sh.ByteSize(), 3*130*dtype.Float32Size; got != want {
+		t.Errorf("logical byte size: got %d but want %d", got, want)
+	}
+	if got, want := sh.PhysicalByteSize(), 8*256*dtype.Float32Size; got != want {
+		t.Errorf("physical byte size: got %d but want %d", got, want)
+	}
+	if sh.IsContiguous() {
+		t.Errorf("tiled shape %v is contiguous", sh)
+	}
+	if sh.Equal(&Shape{DType: dtype.Float32, AxisLengths: []int{3, 130}}) {
+		t.Errorf("tiled shape is equal to untiled shape")
+	}
+}
+
+func TestEqualIgnoringLayout(t *testing.T) {
+	rowMajor := Of(dtype.Float32, 2, 3)
+	colMajor := rowMajor.WithLayout(ColumnMajor(2))
+	if !rowMajor.EqualIgnoringLayout(colMajor) {
+		t.Errorf("%v and %v are not equal ignoring layout", rowMajor, colMajor)
+	}
+	if rowMajor.EqualIgnoringLayout(Of(dtype.Float32, 3, 2)) {
+		t.Errorf("[2][3] and [3][2] are equal ignoring layout")
+	}
+	if !rowMajor.SameElements(Of(dtype.Float32, 3, 2)) {
+		t.Errorf("[2][3] and [3][2] do not have the same elements")
+	}
+}
+
+func TestRelayout(t *testing.T) {
+	rowMajor := Of(dtype.Int32, 2, 3)
+	colMajor := rowMajor.WithLayout(ColumnMajor(2))
+	src := dtype.FromSlice([]int32{1, 2, 3, 4, 5, 6})
+	dst := make([]byte, colMajor.ByteSize())
+	if err := Relayout(dst, colMajor, src, rowMajor); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[int32](dst), []int32{1, 4, 2, 5, 3, 6}; !slices.Equal(got, want) {
+		t.Errorf("got %v but want %v", got, want)
+	}
+	back := make([]byte, rowMajor.ByteSize())
+	if err := Relayout(back, rowMajor, dst, colMajor); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dtype.ToSlice[int32](back), []int32{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("round trip: got %v but want %v", got, want)
+	}
+	tiled := rowMajor.WithLayout(&Layout{MinorToMajor: []int{1, 0}, Tile: []int{2, 2}})
+	if err := Relayout(make([]byte, tiled.PhysicalByteSize()), tiled, src, rowMajor); err == nil {
+		t.Errorf("expected an error for a tiled layout")
+	}
+	if err := Relayout(dst, Of(dtype.Int32, 3, 2), src, rowMajor); err == nil {
+		t.Errorf("expected an error for different logical shapes")
+	}
+}
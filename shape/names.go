@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+)
+
+// HasAxisNames returns true if at least one axis of the shape has a name.
+func (s *Shape) HasAxisNames() bool {
+	return slices.ContainsFunc(s.AxisNames, func(name string) bool { return name != "" })
+}
+
+// AxisName returns the name of an axis or an empty string if the axis has no name.
+func (s *Shape) AxisName(axis int) string {
+	if axis < 0 || axis >= len(s.AxisNames) {
+		return ""
+	}
+	return s.AxisNames[axis]
+}
+
+// AxisIndex returns the index of the axis with a given name.
+func (s *Shape) AxisIndex(name string) (int, error) {
+	if name == "" {
+		return -1, fmt.Errorf("empty axis name")
+	}
+	axis := slices.Index(s.AxisNames, name)
+	if axis < 0 || axis >= len(s.AxisLengths) {
+		return -1, fmt.Errorf("shape %s has no axis named %q", s, name)
+	}
+	return axis, nil
+}
+
+// WithAxisNames returns a copy of the shape with names given to its axes.
+// An empty name leaves the corresponding axis unnamed.
+func (s *Shape) WithAxisNames(names ...string) (*Shape, error) {
+	if len(names) != len(s.AxisLengths) {
+		return nil, fmt.Errorf("cannot name the %d axes of %s with %d names", len(s.AxisLengths), s, len(names))
+	}
+	for i, name := range names {
+		if name != "" && slices.Index(names, name) != i {
+			return nil, fmt.Errorf("axis name %q used more than once in %v", name, names)
+		}
+	}
+	cpy := *s
+	cpy.AxisLengths = slices.Clone(s.AxisLengths)
+	cpy.AxisNames = slices.Clone(names)
+	return &cpy, nil
+}
+
+// namesOf returns the names of a list of axes of a shape,
+// or nil if the shape has no axis names.
+func namesOf(s *Shape, axes []int) []string {
+	if !s.HasAxisNames() {
+		return nil
+	}
+	names := make([]string, len(axes))
+	for i, axis := range axes {
+		names[i] = s.AxisName(axis)
+	}
+	return names
+}
+
+// axisRange returns the list of axes from start (included) to end (excluded).
+func axisRange(start, end int) []int {
+	axes := make([]int, 0, end-start)
+	for axis := start; axis < end; axis++ {
+		axes = append(axes, axis)
+	}
+	return axes
+}
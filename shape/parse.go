@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// Parse returns the shape represented by a string, such as "[2][3]float32"
+// or "[batch:2][3]float32" with named axes, or "[n<=8]float32" with dynamic axes.
+// It is the inverse of Shape.String.
+func Parse(s string) (*Shape, error) {
+	rest := s
+	var axisLengths []int
+	var axisNames, symbols []string
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("cannot parse shape %q: missing ]", s)
+		}
+		axis := rest[1:end]
+		name := ""
+		if colon := strings.LastIndexByte(axis, ':'); colon >= 0 {
+			name, axis = axis[:colon], axis[colon+1:]
+			if name == "" {
+				return nil, fmt.Errorf("cannot parse shape %q: empty axis name", s)
+			}
+		}
+		symbol := ""
+		if le := strings.Index(axis, "<="); le >= 0 {
+			symbol, axis = axis[:le], axis[le+2:]
+			if symbol == "" {
+				return nil, fmt.Errorf("cannot parse shape %q: empty axis symbol", s)
+			}
+		}
+		length, err := strconv.Atoi(axis)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse shape %q: invalid axis length %q", s, axis)
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("cannot parse shape %q: negative axis length %d", s, length)
+		}
+		axisLengths = append(axisLengths, length)
+		axisNames = append(axisNames, name)
+		symbols = append(symbols, symbol)
+		rest = rest[end+1:]
+	}
+	dt, err := dtype.Parse(rest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse shape %q: %v", s, err)
+	}
+	sh := &Shape{DType: dt, AxisLengths: axisLengths}
+	if slices.ContainsFunc(symbols, func(sym string) bool { return sym != "" }) {
+		sh.Symbols = symbols
+		if err := sh.checkSymbols(); err != nil {
+			return nil, fmt.Errorf("cannot parse shape %q: %v", s, err)
+		}
+	}
+	if slices.ContainsFunc(axisNames, func(name string) bool { return name != "" }) {
+		if sh, err = sh.WithAxisNames(axisNames...); err != nil {
+			return nil, fmt.Errorf("cannot parse shape %q: %v", s, err)
+		}
+	}
+	return sh, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"float32", "[2]int64", "[2][3]bfloat16", "[0][1]bool", "[n<=8][3]float32", "[batch:n<=8]int32"} {
+		sh, err := Parse(s)
+		if err != nil {
+			t.Errorf("cannot parse %q: %v", s, err)
+			continue
+		}
+		if got := sh.String(); got != s {
+			t.Errorf("round trip of %q returned %q", s, got)
+		}
+	}
+	for _, s := range []string{"", "[2", "[a]float32", "[-1]float32", "[2]float33", "[2]float32[3]", "[<=2]float32", "[n<=2][n<=3]float32"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected an error when parsing %q", s)
+		}
+	}
+}
+
+func TestParseAxisNames(t *testing.T) {
+	sh, err := Parse("[batch:2][3]float32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sh.AxisName(0); got != "batch" {
+		t.Errorf("got axis name %q but want %q", got, "batch")
+	}
+	if _, err := Parse("[a:2][a:3]float32"); err == nil {
+		t.Errorf("expected an error for duplicate axis names")
+	}
+}
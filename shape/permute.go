@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import "fmt"
+
+// CheckPermutation returns an error if perm is not a permutation of the axes of an array of a given rank.
+func CheckPermutation(perm []int, rank int) error {
+	if len(perm) != rank {
+		return fmt.Errorf("permutation %v has %d axes but want %d", perm, len(perm), rank)
+	}
+	if err := checkAxes(perm, rank); err != nil {
+		return fmt.Errorf("invalid permutation %v: %v", perm, err)
+	}
+	return nil
+}
+
+// InversePermutation returns the permutation undoing perm.
+// perm is assumed to be valid.
+func InversePermutation(perm []int) []int {
+	inv := make([]int, len(perm))
+	for i, axis := range perm {
+		inv[axis] = i
+	}
+	return inv
+}
+
+// Permute returns a new shape with the axes permuted:
+// the axis i of the result is the axis perm[i] of s.
+func (s *Shape) Permute(perm []int) (*Shape, error) {
+	return TransposeShape(s, perm)
+}
+
+// SwapAxes returns a new shape with two axes swapped.
+func (s *Shape) SwapAxes(i, j int) (*Shape, error) {
+	rank := len(s.AxisLengths)
+	for _, axis := range []int{i, j} {
+		if err := checkAxis(axis, rank); err != nil {
+			return nil, fmt.Errorf("cannot swap axes %d and %d of %s: %v", i, j, s, err)
+		}
+	}
+	perm := axisRange(0, rank)
+	perm[i], perm[j] = perm[j], perm[i]
+	return s.Permute(perm)
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// Relayout copies the elements of src, stored with the layout of srcShape,
+// into dst, stored with the layout of dstShape.
+// Both shapes must have the same logical shape (see Shape.EqualIgnoringLayout).
+// Tiled layouts and elements smaller than a byte are only supported if both layouts are the same.
+func Relayout(dst []byte, dstShape *Shape, src []byte, srcShape *Shape) error {
+	if !dstShape.EqualIgnoringLayout(srcShape) || dstShape.IsBitPacked() != srcShape.IsBitPacked() {
+		return fmt.Errorf("cannot relayout an array of shape %s into an array of shape %s", srcShape, dstShape)
+	}
+	if len(src) < srcShape.PhysicalByteSize() {
+		return fmt.Errorf("got %d bytes for an array of shape %s: want %d bytes", len(src), srcShape, srcShape.PhysicalByteSize())
+	}
+	if len(dst) < dstShape.PhysicalByteSize() {
+		return fmt.Errorf("got %d bytes for an array of shape %s: want %d bytes", len(dst), dstShape, dstShape.PhysicalByteSize())
+	}
+	if dstShape.Equal(srcShape) {
+		copy(dst, src[:srcShape.PhysicalByteSize()])
+		return nil
+	}
+	if dstShape.IsTiled() || srcShape.IsTiled() {
+		return fmt.Errorf("cannot relayout an array of shape %s into an array of shape %s: tiled layouts not supported", srcShape, dstShape)
+	}
+	if dtype.IsSubByte(dstShape.DType) || dstShape.IsBitPacked() {
+		return fmt.Errorf("cannot relayout an array of shape %s: elements smaller than a byte not supported", srcShape)
+	}
+	if dstShape.Size() == 0 {
+		return nil
+	}
+	size := dtype.Sizeof(dstShape.DType)
+	dstStrides, srcStrides := dstShape.Strides(), srcShape.Strides()
+	var walk func(axis, dstOffset, srcOffset int)
+	walk = func(axis, dstOffset, srcOffset int) {
+		if axis == len(dstShape.AxisLengths) {
+			copy(dst[dstOffset*size:(dstOffset+1)*size], src[srcOffset*size:(srcOffset+1)*size])
+			return
+		}
+		for i := range dstShape.AxisLengths[axis] {
+			walk(axis+1, dstOffset+i*dstStrides[axis], srcOffset+i*srcStrides[axis])
+		}
+	}
+	walk(0, 0, 0)
+	return nil
+}
@@ -16,8 +16,8 @@
 package shape
 
 import (
-	"fmt"
-	"strings"
+	"slices"
+	"strconv"
 
 	"github.com/gx-org/backend/dtype"
 )
@@ -28,6 +28,49 @@ import (
 type Shape struct {
 	DType       dtype.DataType
 	AxisLengths []int
+
+	// AxisNames are optional names of the axes, such as "batch" or "heads".
+	// If not nil, it has the same length as AxisLengths. An empty string is an unnamed axis.
+	// Axis names are ignored when comparing shapes.
+	AxisNames []string
+
+	// Symbols are optional symbols of dynamic axes, such as "n".
+	// If not nil, it has the same length as AxisLengths. An empty string is a static axis.
+	// The length of a dynamic axis is only known when a graph runs:
+	// AxisLengths then holds its upper bound. See AxisLength.
+	Symbols []string
+
+	// Quant describes how the values are quantized.
+	// If not nil, DType is the storage type of the quantized values.
+	Quant *dtype.Quantization
+
+	// BitPacked is true if boolean values are stored 8 per byte.
+	// Ignored for data types other than dtype.Bool.
+	BitPacked bool
+
+	// Layout of the elements in memory.
+	// The default row-major layout is used if nil.
+	Layout *Layout
+}
+
+// Of returns the shape of an array of a given data type and axis lengths.
+func Of(dt dtype.DataType, axisLengths ...int) *Shape {
+	return &Shape{DType: dt, AxisLengths: slices.Clone(axisLengths)}
+}
+
+// Scalar returns the shape of an atomic value of a given data type.
+func Scalar(dt dtype.DataType) *Shape {
+	return &Shape{DType: dt}
+}
+
+// Vector returns the shape of an array with a single axis.
+func Vector(dt dtype.DataType, length int) *Shape {
+	return &Shape{DType: dt, AxisLengths: []int{length}}
+}
+
+// Matrix returns the shape of an array with two axes.
+func Matrix(dt dtype.DataType, rows, cols int) *Shape {
+	return &Shape{DType: dt, AxisLengths: []int{rows, cols}}
 }
 
 // OuterAxisLength returns the shape's outermost axis length, or 1 for rank-0 shapes.
@@ -45,13 +88,27 @@ func (s *Shape) IsAtomic() bool {
 }
 
 // Size returns the number of elements of DType are needed for this shape. It's the product of all dimensions.
+// Dynamic axes count for their upper bound.
 func (s *Shape) Size() int {
 	return Size(s.AxisLengths)
 }
 
 // ByteSize returns the size of the buffer, in bytes, to store the data specified by the shape.
+// For dynamic shapes, it is the size of the largest buffer, that is with all dynamic axes at their upper bound.
 func (s *Shape) ByteSize() int {
-	return dtype.Sizeof(s.DType) * s.Size()
+	size := s.storageSize()
+	if s.IsBitPacked() {
+		return dtype.PackedBoolsSize(size)
+	}
+	if dtype.IsSubByte(s.DType) {
+		return dtype.PackedSize(s.DType, size)
+	}
+	return dtype.Sizeof(s.DType) * size
+}
+
+// IsBitPacked returns true if the elements of the array are stored 8 per byte.
+func (s *Shape) IsBitPacked() bool {
+	return s.BitPacked && s.DType == dtype.Bool
 }
 
 // Equal returns true if o represents the same shape.
@@ -59,23 +116,61 @@ func (s *Shape) Equal(o *Shape) bool {
 	if s.DType != o.DType {
 		return false
 	}
-	if len(s.AxisLengths) != len(o.AxisLengths) {
+	if !s.Quant.Equal(o.Quant) {
 		return false
 	}
-	for i, li := range s.AxisLengths {
-		if o.AxisLengths[i] != li {
-			return false
-		}
+	if s.IsBitPacked() != o.IsBitPacked() {
+		return false
 	}
-	return true
+	if !slices.Equal(s.AxisLengths, o.AxisLengths) || !s.sameSymbols(o) {
+		return false
+	}
+	if s.Layout == nil && o.Layout == nil {
+		return true
+	}
+	return slices.Equal(s.Strides(), o.Strides()) && s.IsTiled() == o.IsTiled() && slices.Equal(s.tile(), o.tile())
+}
+
+// EqualIgnoringLayout returns true if o represents the same logical shape,
+// that is the same data type, quantization, axis lengths and symbols,
+// regardless of how the elements are stored in memory.
+func (s *Shape) EqualIgnoringLayout(o *Shape) bool {
+	return s.DType == o.DType &&
+		s.Quant.Equal(o.Quant) &&
+		slices.Equal(s.AxisLengths, o.AxisLengths) &&
+		s.sameSymbols(o)
 }
 
+// SameElements returns true if o has the same data type and the same number of elements,
+// such that one can be reshaped into the other.
+func (s *Shape) SameElements(o *Shape) bool {
+	return s.DType == o.DType && s.Quant.Equal(o.Quant) && s.Size() == o.Size()
+}
+
+// String returns the axis lengths followed by the data type, for example [2][batch:3]float32.
+// Dynamic axes are printed with their symbol and upper bound, for example [n<=8]float32.
 func (s *Shape) String() string {
-	axes := make([]string, len(s.AxisLengths))
+	// Shapes of usual ranks are formatted on the stack: the returned string is the only allocation.
+	var buf [64]byte
+	return string(s.AppendString(buf[:0]))
+}
+
+// AppendString appends the string representation of the shape, as returned by String, to b.
+func (s *Shape) AppendString(b []byte) []byte {
 	for i, axisLength := range s.AxisLengths {
-		axes[i] = fmt.Sprintf("[%d]", axisLength)
+		b = append(b, '[')
+		if name := s.AxisName(i); name != "" {
+			b = append(b, name...)
+			b = append(b, ':')
+		}
+		if sym := s.Symbol(i); sym != "" {
+			b = append(b, sym...)
+			b = append(b, "<="...)
+		}
+		b = strconv.AppendInt(b, int64(axisLength), 10)
+		b = append(b, ']')
 	}
-	return strings.Join(axes, "") + s.DType.String()
+	return append(b, s.DType.String()...)
 }
 
 // ArrayI is a minimum generic array interface.
@@ -88,6 +183,28 @@ type ArrayI[T dtype.GoDataType] interface {
 	Flat() []T
 }
 
+// ArrayND is an array providing multi-dimensional access to its elements.
+type ArrayND[T dtype.GoDataType] interface {
+	ArrayI[T]
+
+	// Rank returns the number of axes of the array.
+	Rank() int
+
+	// At returns the element at the given indices.
+	// It panics if the indices are out of bounds.
+	At(indices ...int) T
+}
+
+// At returns the element of an array at the given indices, assuming the
+// elements returned by Flat are stored in row-major order.
+// It panics if the indices are out of bounds.
+func At[T dtype.GoDataType](a ArrayI[T], indices ...int) T {
+	if nd, ok := a.(ArrayND[T]); ok {
+		return nd.At(indices...)
+	}
+	return a.Flat()[NewIndexer(&Shape{AxisLengths: a.Shape()}).Offset(indices...)]
+}
+
 // Size returns the total number of elements given a slice of axis lengths.
 func Size(dims []int) int {
 	size := 1
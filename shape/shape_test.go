@@ -16,6 +16,7 @@
 package shape
 
 import (
+	"math"
 	"testing"
 
 	"github.com/gx-org/backend/dtype"
@@ -54,3 +55,256 @@ func TestShapeEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestShapeEqualQuantization(t *testing.T) {
+	quant := func(scale float64) *dtype.Quantization {
+		return &dtype.Quantization{
+			Storage:    dtype.Int32,
+			Expressed:  dtype.Float32,
+			Axis:       dtype.PerTensor,
+			Scales:     []float64{scale},
+			ZeroPoints: []int64{0},
+		}
+	}
+	x := Shape{DType: dtype.Int32, AxisLengths: []int{2}, Quant: quant(0.5)}
+	if is := (Shape{DType: dtype.Int32, AxisLengths: []int{2}, Quant: quant(0.5)}); !x.Equal(&is) {
+		t.Errorf("%v == %v is false", x, is)
+	}
+	for _, isNot := range []Shape{
+		{DType: dtype.Int32, AxisLengths: []int{2}},
+		{DType: dtype.Int32, AxisLengths: []int{2}, Quant: quant(0.25)},
+	} {
+		if x.Equal(&isNot) {
+			t.Errorf("%v != %v is false", x, isNot)
+		}
+	}
+}
+
+func TestShapeHash(t *testing.T) {
+	x := &Shape{DType: dtype.Float32, AxisLengths: []int{2, 3}}
+	same := x.WithLayout(RowMajor(2))
+	if x.Hash() != same.Hash() {
+		t.Errorf("equal shapes %v and %v have different hashes", x, same)
+	}
+	for _, other := range []*Shape{
+		{DType: dtype.Float64, AxisLengths: []int{2, 3}},
+		{DType: dtype.Float32, AxisLengths: []int{3, 2}},
+		{DType: dtype.Float32, AxisLengths: []int{2, 3, 1}},
+		x.WithLayout(ColumnMajor(2)),
+	} {
+		if x.Hash() == other.Hash() {
+			t.Errorf("different shapes %v and %v have the same hash", x, other)
+		}
+	}
+}
+
+func TestShapeCheck(t *testing.T) {
+	if err := (&Shape{DType: dtype.Float32, AxisLengths: []int{2, 3}}).Check(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, sh := range []*Shape{
+		{DType: dtype.Invalid},
+		{DType: dtype.DataType(1000)},
+		{DType: dtype.Float32, AxisLengths: []int{2, -1}},
+		{DType: dtype.Float32, AxisLengths: []int{math.MaxInt/2 + 1, 4}},
+		{DType: dtype.Float64, AxisLengths: []int{math.MaxInt/8 + 1}},
+		{DType: dtype.Float32, AxisLengths: []int{2}, AxisNames: []string{"a", "b"}},
+	} {
+		if err := sh.Check(); err == nil {
+			t.Errorf("expected an error for shape %v", sh)
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	x := &Shape{DType: dtype.Float32, AxisLengths: []int{2, 3}}
+	prepended := x.PrependAxis(4)
+	prepended.AxisLengths[1] = 42
+	if x.AxisLengths[0] != 2 {
+		t.Errorf("PrependAxis shares axis lengths with the original shape")
+	}
+	if got, want := x.AppendAxis(5).String(), "[2][3][5]float32"; got != want {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	if got, want := x.WithDType(dtype.Int64).String(), "[2][3]int64"; got != want {
+		t.Errorf("got %s but want %s", got, want)
+	}
+	if got, want := x.WithAxes(7).String(), "[7]float32"; got != want {
+		t.Errorf("got %s but want %s", got, want)
+	}
+}
+
+func TestByteSize64(t *testing.T) {
+	sh := &Shape{DType: dtype.Float64, AxisLengths: []int{1 << 20, 1 << 20}}
+	got, err := sh.ByteSize64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(8) << 40; got != want {
+		t.Errorf("got %d but want %d", got, want)
+	}
+	if _, err := (&Shape{DType: dtype.Float64, AxisLengths: []int{math.MaxInt, math.MaxInt, 4}}).ByteSize64(); err == nil {
+		t.Errorf("expected an overflow error")
+	}
+	if got, err := (&Shape{DType: dtype.Int4, AxisLengths: []int{3}}).ByteSize64(); err != nil || got != 2 {
+		t.Errorf("got %d, %v but want 2", got, err)
+	}
+}
+
+func TestCheckedByteSize(t *testing.T) {
+	tests := []struct {
+		strides []int
+		want    int
+		wantErr bool
+	}{
+		{strides: nil, want: 16},
+		{strides: []int{4, 1}, want: 24},
+		{strides: []int{math.MaxInt / 2, 1}, wantErr: true},
+	}
+	for _, test := range tests {
+		sh := Of(dtype.Float32, 2, 2).WithLayout(&Layout{MinorToMajor: []int{1, 0}, Strides: test.strides})
+		got, err := sh.CheckedByteSize()
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("strides %v: expected an overflow error", test.strides)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("strides %v: got %d, %v but want %d", test.strides, got, err, test.want)
+		}
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		got  *Shape
+		want string
+	}{
+		{got: Scalar(dtype.Bool), want: "bool"},
+		{got: Vector(dtype.Int32, 3), want: "[3]int32"},
+		{got: Matrix(dtype.Float64, 2, 3), want: "[2][3]float64"},
+		{got: Of(dtype.Float32, 1, 2, 3), want: "[1][2][3]float32"},
+	}
+	for _, test := range tests {
+		if got := test.got.String(); got != test.want {
+			t.Errorf("got %s but want %s", got, test.want)
+		}
+	}
+}
+
+func TestIntern(t *testing.T) {
+	in := NewInterner()
+	a := in.Intern(Of(dtype.Float32, 2, 3))
+	b := in.Intern(Of(dtype.Float32, 2, 3))
+	if a != b {
+		t.Errorf("equal shapes interned to different instances")
+	}
+	named, err := Of(dtype.Float32, 2, 3).WithAxisNames("batch", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := in.Intern(named); c == a {
+		t.Errorf("shapes with different axis names interned to the same instance")
+	}
+	if got := in.Len(); got != 2 {
+		t.Errorf("got %d interned shapes but want 2", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	sh, err := Of(dtype.Float32, 2, 3).WithAxisNames("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "[2][batch:3]float32"
+	if got := sh.String(); got != want {
+		t.Errorf("got %q but want %q", got, want)
+	}
+	if got := string(sh.AppendString([]byte("x="))); got != "x="+want {
+		t.Errorf("got %q but want %q", got, "x="+want)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { _ = sh.String() }); allocs > 1 {
+		t.Errorf("String allocated %v times but want at most 1", allocs)
+	}
+	buf := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(10, func() { buf = sh.AppendString(buf[:0]) }); allocs != 0 {
+		t.Errorf("AppendString allocated %v times in a large enough buffer", allocs)
+	}
+	sh.AxisLengths[0] = 4
+	if got, want := sh.String(), "[4][batch:3]float32"; got != want {
+		t.Errorf("after modification: got %q but want %q", got, want)
+	}
+	if got, want := sh.WithDType(dtype.Int32).String(), "[4][batch:3]int32"; got != want {
+		t.Errorf("copy: got %q but want %q", got, want)
+	}
+}
+
+func TestDynamic(t *testing.T) {
+	sh := OfAxes(dtype.Float32, Dynamic("n", 8), Static(3))
+	if got, want := sh.String(), "[n<=8][3]float32"; got != want {
+		t.Errorf("got %q but want %q", got, want)
+	}
+	if !sh.IsDynamic() || sh.Axis(1).IsDynamic() {
+		t.Errorf("shape %s: got dynamic axes %v", sh, sh.Axes())
+	}
+	if got, want := sh.Size(), 24; got != want {
+		t.Errorf("got size %d but want the upper bound %d", got, want)
+	}
+	if sh.Equal(Of(dtype.Float32, 8, 3)) || !sh.Equal(OfAxes(dtype.Float32, Dynamic("n", 8), Static(3))) {
+		t.Errorf("shape %s: symbols not compared", sh)
+	}
+	if !Of(dtype.Float32, 2).Equal(&Shape{DType: dtype.Float32, AxisLengths: []int{2}, Symbols: []string{""}}) {
+		t.Errorf("static shapes with and without symbols are not equal")
+	}
+	values, err := sh.Bindings(Of(dtype.Float32, 5, 3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bound, err := sh.Bind(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Of(dtype.Float32, 5, 3); !bound.Equal(want) {
+		t.Errorf("got bound shape %s but want %s", bound, want)
+	}
+	for _, arg := range []*Shape{Of(dtype.Float32, 9, 3), Of(dtype.Float32, 5, 4), Of(dtype.Int32, 5, 3)} {
+		if _, err := sh.Bindings(arg, nil); err == nil {
+			t.Errorf("expected an error when matching %s with %s", arg, sh)
+		}
+	}
+	if _, err := sh.Bindings(Of(dtype.Float32, 5, 3), map[string]int{"n": 4}); err == nil {
+		t.Errorf("expected an error for inconsistent values of n")
+	}
+	if _, err := sh.Bind(map[string]int{"n": 9}); err == nil {
+		t.Errorf("expected an error when a value exceeds its upper bound")
+	}
+	if a, b := sh.AppendCanonical(nil), Of(dtype.Float32, 8, 3).AppendCanonical(nil); string(a) == string(b) {
+		t.Errorf("dynamic and static shapes have the same canonical encoding")
+	}
+}
+
+func benchmarkShape(b *testing.B) *Shape {
+	sh, err := Of(dtype.Float32, 32, 128, 768).WithAxisNames("batch", "seq", "features")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return sh
+}
+
+func BenchmarkString(b *testing.B) {
+	sh := benchmarkShape(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = sh.String()
+	}
+}
+
+func BenchmarkAppendString(b *testing.B) {
+	sh := benchmarkShape(b)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for b.Loop() {
+		buf = sh.AppendString(buf[:0])
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// mul64 returns a*b or an error if the result overflows int64.
+func mul64(a, b int64) (int64, error) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi != 0 || lo > math.MaxInt64 {
+		return 0, fmt.Errorf("%d * %d overflows int64", a, b)
+	}
+	return int64(lo), nil
+}
+
+// Size64 returns the total number of elements given a slice of axis lengths
+// computed using 64-bit integers.
+// It returns an error if an axis length is negative or if the result overflows.
+func Size64(dims []int) (int64, error) {
+	size := int64(1)
+	for axis, d := range dims {
+		if d < 0 {
+			return 0, fmt.Errorf("negative length %d for axis %d", d, axis)
+		}
+		var err error
+		if size, err = mul64(size, int64(d)); err != nil {
+			return 0, fmt.Errorf("number of elements of %v overflows: %v", dims, err)
+		}
+	}
+	return size, nil
+}
+
+// Size64 returns the number of elements of the shape computed using 64-bit integers.
+// It returns an error if an axis length is negative or if the result overflows.
+func (s *Shape) Size64() (int64, error) {
+	return Size64(s.AxisLengths)
+}
+
+// ByteSize64 returns the size in bytes to store the data specified by the shape,
+// computed using 64-bit integers, ignoring explicit strides.
+// It returns an error if the result overflows.
+func (s *Shape) ByteSize64() (int64, error) {
+	size, err := s.Size64()
+	if err != nil {
+		return 0, err
+	}
+	return s.bytesOf64(size)
+}
+
+// bytesOf64 returns the number of bytes storing size elements of the shape.
+func (s *Shape) bytesOf64(size int64) (int64, error) {
+	bitSize := int64(dtype.BitSizeof(s.DType))
+	if s.IsBitPacked() {
+		bitSize = 1
+	}
+	numBits, err := mul64(size, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("size in bytes of %s overflows: %v", s, err)
+	}
+	return numBits/8 + min(numBits%8, 1), nil
+}
+
+// storageSize64 returns the number of elements spanned in memory by the shape,
+// including the gaps introduced by explicit strides, computed using 64-bit integers.
+func (s *Shape) storageSize64() (int64, error) {
+	if s.Layout == nil || s.Layout.Strides == nil || len(s.Layout.Strides) != len(s.AxisLengths) {
+		return s.Size64()
+	}
+	span := int64(1)
+	for axis, length := range s.AxisLengths {
+		if length < 0 {
+			return 0, fmt.Errorf("negative length %d for axis %d", length, axis)
+		}
+		if length == 0 {
+			return 0, nil
+		}
+		stride := s.Layout.Strides[axis]
+		if stride < 0 {
+			return 0, fmt.Errorf("negative stride %d for axis %d", stride, axis)
+		}
+		extent, err := mul64(int64(length-1), int64(stride))
+		if err != nil || span > math.MaxInt64-extent {
+			return 0, fmt.Errorf("storage size of %s overflows int64", s)
+		}
+		span += extent
+	}
+	return span, nil
+}
+
+// CheckedByteSize returns the size in bytes to store the data specified by the shape,
+// including the gaps introduced by explicit strides,
+// or an error if the size does not fit in an int on the current platform.
+func (s *Shape) CheckedByteSize() (int, error) {
+	storage, err := s.storageSize64()
+	if err != nil {
+		return 0, err
+	}
+	size, err := s.bytesOf64(storage)
+	if err != nil {
+		return 0, err
+	}
+	if size > math.MaxInt {
+		return 0, fmt.Errorf("size in bytes %d of %s does not fit in an int", size, s)
+	}
+	return int(size), nil
+}
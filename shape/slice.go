@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"slices"
+)
+
+// StridedSliceShape returns the shape of the slice of x starting at starts (included),
+// ending at limits (excluded) and taking every strides element along each axis.
+// A nil strides is a stride of 1 for all axes.
+func StridedSliceShape(x *Shape, starts, limits, strides []int) (*Shape, error) {
+	rank := len(x.AxisLengths)
+	if strides == nil {
+		strides = slices.Repeat([]int{1}, rank)
+	}
+	if len(starts) != rank || len(limits) != rank || len(strides) != rank {
+		return nil, fmt.Errorf("cannot slice %s: starts %v, limits %v and strides %v do not match rank %d", x, starts, limits, strides, rank)
+	}
+	axisLengths := make([]int, rank)
+	for axis, length := range x.AxisLengths {
+		start, limit, stride := starts[axis], limits[axis], strides[axis]
+		if stride <= 0 {
+			return nil, fmt.Errorf("cannot slice %s: stride %d of axis %d is not positive", x, stride, axis)
+		}
+		if start < 0 || limit > length || start > limit {
+			return nil, fmt.Errorf("cannot slice %s: [%d:%d] out of range [0:%d] for axis %d", x, start, limit, length, axis)
+		}
+		axisLengths[axis] = (limit - start + stride - 1) / stride
+	}
+	return derive(x, axisLengths, slices.Clone(x.AxisNames)), nil
+}
+
+// DynamicSliceShape returns the shape of a slice of x with the given sizes
+// and starting indices only known at runtime.
+// Backends clamp the starting indices such that the slice is in bounds.
+func DynamicSliceShape(x *Shape, sizes []int) (*Shape, error) {
+	rank := len(x.AxisLengths)
+	if len(sizes) != rank {
+		return nil, fmt.Errorf("cannot slice %s: sizes %v do not match rank %d", x, sizes, rank)
+	}
+	for axis, size := range sizes {
+		if size < 0 || size > x.AxisLengths[axis] {
+			return nil, fmt.Errorf("cannot slice %s: size %d out of range [0, %d] for axis %d", x, size, x.AxisLengths[axis], axis)
+		}
+	}
+	return derive(x, slices.Clone(sizes), slices.Clone(x.AxisNames)), nil
+}
+
+// DynamicUpdateSliceShape checks that an update can be written into x at starting indices
+// only known at runtime and returns the shape of the result.
+func DynamicUpdateSliceShape(x, update *Shape) (*Shape, error) {
+	if x.DType != update.DType {
+		return nil, fmt.Errorf("cannot update %s with %s: data types differ", x, update)
+	}
+	if _, err := DynamicSliceShape(x, update.AxisLengths); err != nil {
+		return nil, fmt.Errorf("cannot update %s with %s: %v", x, update, err)
+	}
+	return derive(x, slices.Clone(x.AxisLengths), slices.Clone(x.AxisNames)), nil
+}
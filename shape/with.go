@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"slices"
+
+	"github.com/gx-org/backend/dtype"
+)
+
+// The methods below never modify the shape on which they are called nor share
+// slices with it, such that the returned shapes can be modified freely.
+
+// Clone returns a deep copy of the shape.
+func (s *Shape) Clone() *Shape {
+	cpy := *s
+	cpy.AxisLengths = slices.Clone(s.AxisLengths)
+	cpy.AxisNames = slices.Clone(s.AxisNames)
+	cpy.Symbols = slices.Clone(s.Symbols)
+	if s.Layout != nil {
+		cpy.Layout = &Layout{
+			MinorToMajor: slices.Clone(s.Layout.MinorToMajor),
+			Strides:      slices.Clone(s.Layout.Strides),
+			Tile:         slices.Clone(s.Layout.Tile),
+		}
+	}
+	if s.Quant != nil {
+		q := *s.Quant
+		q.Scales = slices.Clone(s.Quant.Scales)
+		q.ZeroPoints = slices.Clone(s.Quant.ZeroPoints)
+		cpy.Quant = &q
+	}
+	return &cpy
+}
+
+// WithDType returns a copy of the shape with a different data type.
+// The quantization is dropped.
+func (s *Shape) WithDType(dt dtype.DataType) *Shape {
+	cpy := s.Clone()
+	cpy.DType = dt
+	cpy.Quant = nil
+	return cpy
+}
+
+// WithAxes returns a shape with the same data type and different axis lengths.
+// Axis names, layout and per-axis quantization are dropped.
+func (s *Shape) WithAxes(axisLengths ...int) *Shape {
+	return derive(s, slices.Clone(axisLengths), nil).Clone()
+}
+
+// PrependAxis returns a copy of the shape with a new outermost axis.
+// Layout and per-axis quantization are dropped.
+func (s *Shape) PrependAxis(length int) *Shape {
+	return s.InsertAxis(0, length)
+}
+
+// AppendAxis returns a copy of the shape with a new innermost axis.
+// Layout and per-axis quantization are dropped.
+func (s *Shape) AppendAxis(length int) *Shape {
+	return s.InsertAxis(len(s.AxisLengths), length)
+}
+
+// InsertAxis returns a copy of the shape with a new axis inserted at a given position.
+// Layout and per-axis quantization are dropped.
+// It panics if the position is not in [0, rank].
+func (s *Shape) InsertAxis(axis, length int) *Shape {
+	axisLengths := slices.Insert(slices.Clone(s.AxisLengths), axis, length)
+	var axisNames []string
+	if s.AxisNames != nil {
+		axisNames = slices.Insert(slices.Clone(s.AxisNames), axis, "")
+	}
+	return derive(s, axisLengths, axisNames).Clone()
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shapecheck provides a backend checking every call made by the interpreter
+// before forwarding it to another backend.
+//
+// Shapes and data types are checked with the shape inference rules of the shape
+// package, such that an invalid call fails when it is built instead of being
+// silently miscompiled by the backend.
+package shapecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+// Error is returned when a call is invalid.
+type Error struct {
+	// Graph is the path of the graph in which the call has been made.
+	Graph string
+	// Op is the invalid operation.
+	Op ops.OpID
+	// Inputs are the shapes of the inputs of the operation.
+	Inputs []*shape.Shape
+	// Err is the reason why the call is invalid.
+	Err error
+}
+
+func (e *Error) Error() string {
+	inputs := make([]string, len(e.Inputs))
+	for i, sh := range e.Inputs {
+		inputs[i] = fmt.Sprint(sh)
+	}
+	return fmt.Sprintf("%s: invalid call %s(%s): %v", e.Graph, e.Op, strings.Join(inputs, ", "), e.Err)
+}
+
+// Unwrap returns the reason why the call is invalid.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Checker is an interceptor rejecting invalid calls.
+type Checker struct {
+	caps ops.Capabilities
+}
+
+var _ intercept.Interceptor = (*Checker)(nil)
+
+// NewChecker returns an interceptor rejecting the calls which are invalid or
+// not supported given the capabilities of a backend.
+func NewChecker(caps ops.Capabilities) *Checker {
+	return &Checker{caps: caps}
+}
+
+// New returns a backend checking all the calls before forwarding them to b.
+func New(b backend.Backend) backend.Backend {
+	return intercept.NewBackend(b, NewChecker(b.Capabilities()))
+}
+
+// Before returns an error if the call is invalid.
+func (c *Checker) Before(call *intercept.Call) error {
+	if err := c.check(call); err != nil {
+		return &Error{
+			Graph:  call.Graph.Path(),
+			Op:     call.Op,
+			Inputs: inputShapes(call),
+			Err:    err,
+		}
+	}
+	return nil
+}
+
+// After does nothing.
+func (c *Checker) After(*intercept.Call, error) {}
+
+func (c *Checker) check(call *intercept.Call) error {
+	switch call.Op {
+	case intercept.OpCompile:
+		return checkCompile(call)
+	case intercept.OpRun:
+		return checkRun(call)
+	case intercept.OpElement, ops.OpSubgraph:
+		return nil
+	}
+	if !c.caps.SupportsOp(call.Op) {
+		return fmt.Errorf("operation not supported by the backend")
+	}
+	if call.ShapeErr != nil {
+		return call.ShapeErr
+	}
+	if call.Shape == nil {
+		return nil
+	}
+	if err := call.Shape.Check(); err != nil {
+		return err
+	}
+	if !c.caps.SupportsDType(call.Shape.DType) {
+		return fmt.Errorf("data type %s not supported by the backend", call.Shape.DType)
+	}
+	if maxRank := c.caps.MaxRank(); maxRank >= 0 && len(call.Shape.AxisLengths) > maxRank {
+		return fmt.Errorf("result of shape %s has %d axes but the backend supports at most %d", call.Shape, len(call.Shape.AxisLengths), maxRank)
+	}
+	return nil
+}
+
+// checkCompile checks that the shapes of the outputs match the inferred shapes of their nodes.
+func checkCompile(call *intercept.Call) error {
+	outputs, _ := call.Attr("outputs").([]*shape.Shape)
+	traced, _ := call.Attr("traced").([]*shape.Shape)
+	want := append(append([]*shape.Shape{}, outputs...), traced...)
+	for i, node := range call.Inputs {
+		if i >= len(want) || node.Shape() == nil {
+			continue
+		}
+		if !node.Shape().Equal(want[i]) {
+			return fmt.Errorf("output %d is declared with shape %s but node %s has shape %s", i, want[i], node, node.Shape())
+		}
+	}
+	return nil
+}
+
+// checkRun checks the shapes of the arguments against the parameters of the compiled graph.
+func checkRun(call *intercept.Call) error {
+	args, _ := call.Attr("args").([]*shape.Shape)
+	params, _ := call.Attr("params").([]*shape.Shape)
+	if len(args) != len(params) {
+		return fmt.Errorf("got %d arguments but the graph has been compiled for %d", len(args), len(params))
+	}
+	for i, arg := range args {
+		if !arg.Equal(params[i]) {
+			return fmt.Errorf("argument %d has shape %s but the graph has been compiled for %s", i, arg, params[i])
+		}
+	}
+	return nil
+}
+
+func inputShapes(call *intercept.Call) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(call.Inputs))
+	for i, input := range call.Inputs {
+		shapes[i] = input.Shape()
+	}
+	return shapes
+}
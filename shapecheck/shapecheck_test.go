@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shapecheck_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+	"github.com/gx-org/backend/shapecheck"
+)
+
+func TestShapeCheck(t *testing.T) {
+	inner := opstest.NewBackend(platformtest.New(1))
+	g, err := shapecheck.New(inner).NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2, 3), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Core().Argument("y", shape.Of(dtype.Float32, 3, 2), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Core().Binary(&ast.BinaryExpr{Op: token.ADD}, x, y)
+	var checkErr *shapecheck.Error
+	if !errors.As(err, &checkErr) {
+		t.Fatalf("got error %v but want a *shapecheck.Error", err)
+	}
+	if checkErr.Op != ops.OpBinary || checkErr.Graph != "main" || len(checkErr.Inputs) != 2 {
+		t.Errorf("error %+v does not locate the invalid call", checkErr)
+	}
+	if got := len(inner.Graphs()[0].Nodes()); got != 2 {
+		t.Errorf("invalid call forwarded to the backend: got %d nodes but want 2", got)
+	}
+	if _, err := g.Core().Reshape(x, []int{4}); err == nil {
+		t.Errorf("invalid reshape did not return an error")
+	}
+	z, err := g.Core().Reshape(x, []int{6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := g.Platform().Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*shape.Shape{shape.Of(dtype.Float32, 2, 3)}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: z, Shape: shape.Of(dtype.Float32, 3)}}, nil, params); err == nil {
+		t.Errorf("compiling with a wrong output shape did not return an error")
+	}
+	runner, err := g.Compile(dev, []*ops.OutputNode{{Node: z, Shape: shape.Of(dtype.Float32, 6)}}, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, err := dev.Send(make([]byte, 24), shape.Of(dtype.Float32, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runner.Run([]platform.Handle{arg}); err == nil {
+		t.Errorf("running with a wrong argument shape did not return an error")
+	}
+}
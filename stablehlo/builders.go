@@ -0,0 +1,1514 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"fmt"
+	"go/ast"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type coreBuilder struct {
+	g *Graph
+}
+
+var _ ops.CoreBuilder = coreBuilder{}
+
+func (b coreBuilder) Graph() ops.Graph {
+	return b.g
+}
+
+func (b coreBuilder) Constant(value platform.HostBuffer) (ops.Node, error) {
+	sh := value.Shape()
+	data := value.AcquireRead()
+	defer value.ReleaseRead()
+	if data == nil {
+		return nil, platform.ErrBufferFreed
+	}
+	literal, err := denseLiteral(sh, data)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.constant", nil, "value = "+literal+" : "+typ, valueType{shape: sh}))
+}
+
+func (b coreBuilder) Tuple(nodes []ops.Node) (ops.Tuple, error) {
+	elems, err := b.g.nodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	typ := valueType{elems: make([]valueType, len(elems))}
+	for i, elem := range elems {
+		typ.elems[i] = elem.typ
+	}
+	node, err := b.g.emit("stablehlo.tuple", elems, "", typ)
+	if err != nil {
+		return nil, err
+	}
+	return &Tuple{Node: node}, nil
+}
+
+// subgraph returns the graph of a subgraph after having set its result.
+func (b coreBuilder) subgraph(sg *ops.Subgraph) (*Graph, *Node, error) {
+	sub, ok := sg.Graph.(*Graph)
+	if !ok || sub.mod != b.g.mod {
+		return nil, nil, fmt.Errorf("subgraph %T has not been created by the StableHLO graph %s", sg.Graph, b.g.name)
+	}
+	result, err := sub.setResult(sg.Result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sub, result, nil
+}
+
+func (b coreBuilder) call(sub *Graph, args []*Node, result valueType) (*Node, error) {
+	if len(args) != len(sub.args) {
+		return nil, fmt.Errorf("subgraph %s called with %d arguments but has %d parameters", sub.name, len(args), len(sub.args))
+	}
+	for i, arg := range args {
+		if arg.typ.isTuple() || !arg.typ.shape.EqualIgnoringLayout(sub.args[i]) {
+			return nil, fmt.Errorf("argument %d of subgraph %s is %s but want %s", i, sub.name, arg, sub.args[i])
+		}
+	}
+	return b.g.emit("func.call", args, "callee = @"+sub.symbol, result)
+}
+
+func (b coreBuilder) Call(sg *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	sub, result, err := b.subgraph(sg)
+	if err != nil {
+		return nil, err
+	}
+	argNodes, err := b.g.nodes(args)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.call(sub, argNodes, result.typ))
+}
+
+func (b coreBuilder) Subgraph(name string, args []*shape.Shape) (ops.Graph, error) {
+	return b.g.newSubgraph(name, args), nil
+}
+
+func (b coreBuilder) Argument(name string, sh *shape.Shape, index int) (ops.Node, error) {
+	if b.g.parent != nil {
+		if index < 0 || index >= len(b.g.args) {
+			return nil, fmt.Errorf("argument %s: index %d out of range [0, %d)", name, index, len(b.g.args))
+		}
+		if !sh.EqualIgnoringLayout(b.g.args[index]) {
+			return nil, fmt.Errorf("argument %s has shape %s but subgraph %s expects %s", name, sh, b.g.name, b.g.args[index])
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("argument %s: invalid index %d", name, index)
+	}
+	if err := ops.CheckStatic(sh); err != nil {
+		return nil, fmt.Errorf("argument %s: %w", name, err)
+	}
+	if arg, ok := b.g.params[index]; ok {
+		if !sh.EqualIgnoringLayout(arg.typ.shape) {
+			return nil, fmt.Errorf("argument %s has shape %s but argument %d already has shape %s", name, sh, index, arg.typ.shape)
+		}
+		return arg, nil
+	}
+	arg := &Node{graph: b.g, name: fmt.Sprintf("%%arg%d", index), typ: valueType{shape: sh}}
+	b.g.params[index] = arg
+	return arg, nil
+}
+
+func (b coreBuilder) Unary(op *ast.UnaryExpr, x ops.Node) (ops.Node, error) {
+	return ops.UnaryFromAST(b, op, x)
+}
+
+func (b coreBuilder) UnaryOp(op ops.UnaryOperator, x ops.Node) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.UnaryOpShape(op, xNode.typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case ops.Plus:
+		return xNode, nil
+	case ops.Neg:
+		return wrap(b.g.emit("stablehlo.negate", []*Node{xNode}, "", valueType{shape: sh}))
+	case ops.Not, ops.BitNot:
+		return wrap(b.g.emit("stablehlo.not", []*Node{xNode}, "", valueType{shape: sh}))
+	}
+	return nil, fmt.Errorf("unary operator %s not supported", op)
+}
+
+var binaryOps = map[ops.BinaryOperator]string{
+	ops.Add:        "stablehlo.add",
+	ops.Sub:        "stablehlo.subtract",
+	ops.Mul:        "stablehlo.multiply",
+	ops.Div:        "stablehlo.divide",
+	ops.Rem:        "stablehlo.remainder",
+	ops.Pow:        "stablehlo.power",
+	ops.BitAnd:     "stablehlo.and",
+	ops.LogicalAnd: "stablehlo.and",
+	ops.BitOr:      "stablehlo.or",
+	ops.LogicalOr:  "stablehlo.or",
+	ops.BitXor:     "stablehlo.xor",
+	ops.Shl:        "stablehlo.shift_left",
+}
+
+var comparisons = map[ops.BinaryOperator]string{
+	ops.Equal:        "EQ",
+	ops.NotEqual:     "NE",
+	ops.Less:         "LT",
+	ops.LessEqual:    "LE",
+	ops.Greater:      "GT",
+	ops.GreaterEqual: "GE",
+}
+
+// comparisonType returns the StableHLO comparison type of a data type.
+func comparisonType(plat platform.Platform, dt dtype.DataType) string {
+	switch dt = platform.Resolve(plat, dt); {
+	case dtype.IsFloat(dt), dtype.IsHalf(dt), dtype.IsComplex(dt):
+		return "FLOAT"
+	case dtype.IsSigned(dt), dt == dtype.Int4:
+		return "SIGNED"
+	}
+	return "UNSIGNED"
+}
+
+// broadcastScalar broadcasts an atomic operand to the axis lengths of the other operand.
+func (b coreBuilder) broadcastScalar(x *Node, axisLengths []int) (*Node, error) {
+	if len(x.typ.shape.AxisLengths) == len(axisLengths) {
+		return x, nil
+	}
+	sh := &shape.Shape{DType: x.typ.shape.DType, AxisLengths: slices.Clone(axisLengths)}
+	return b.g.emit("stablehlo.broadcast_in_dim", []*Node{x}, "broadcast_dimensions = "+i64Array(nil), valueType{shape: sh})
+}
+
+func (b coreBuilder) Binary(op *ast.BinaryExpr, x, y ops.Node) (ops.Node, error) {
+	return ops.BinaryFromAST(b, op, x, y)
+}
+
+func (b coreBuilder) BinaryOp(op ops.BinaryOperator, x, y ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.BinaryOpShape(op, operands[0].typ.shape, operands[1].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	for i, operand := range operands {
+		if operands[i], err = b.broadcastScalar(operand, sh.AxisLengths); err != nil {
+			return nil, err
+		}
+	}
+	typ := valueType{shape: sh}
+	dt := operands[0].typ.shape.DType
+	if direction, ok := comparisons[op]; ok {
+		attrs := fmt.Sprintf("comparison_direction = #stablehlo<comparison_direction %s>, compare_type = #stablehlo<comparison_type %s>", direction, comparisonType(b.g.plat, dt))
+		return wrap(b.g.emit("stablehlo.compare", operands, attrs, typ))
+	}
+	switch op {
+	case ops.Shr:
+		if dtype.IsSigned(platform.Resolve(b.g.plat, dt)) {
+			return wrap(b.g.emit("stablehlo.shift_right_arithmetic", operands, "", typ))
+		}
+		return wrap(b.g.emit("stablehlo.shift_right_logical", operands, "", typ))
+	case ops.BitAndNot:
+		notY, err := b.g.emit("stablehlo.not", operands[1:], "", operands[1].typ)
+		if err != nil {
+			return nil, err
+		}
+		return wrap(b.g.emit("stablehlo.and", []*Node{operands[0], notY}, "", typ))
+	}
+	name, ok := binaryOps[op]
+	if !ok {
+		return nil, fmt.Errorf("binary operator %s not supported", op)
+	}
+	return wrap(b.g.emit(name, operands, "", typ))
+}
+
+func (b coreBuilder) Reshape(x ops.Node, axisLengths []int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.ReshapeShape(xNode.typ.shape, axisLengths)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.reshape", []*Node{xNode}, "", valueType{shape: sh}))
+}
+
+func (b coreBuilder) Concat(axis int, nodes []ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	shapes := make([]*shape.Shape, len(operands))
+	for i, operand := range operands {
+		shapes[i] = operand.typ.shape
+	}
+	sh, err := shape.ConcatShape(axis, shapes...)
+	if err != nil {
+		return nil, err
+	}
+	if axis, err = shape.NormalizeAxis(axis, len(sh.AxisLengths)); err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.concatenate", operands, fmt.Sprintf("dimension = %d : i64", axis), valueType{shape: sh}))
+}
+
+func (b coreBuilder) Cast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.CastShape(xNode.typ.shape, target)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.convert", []*Node{xNode}, "", valueType{shape: sh}))
+}
+
+func (b coreBuilder) Slice(x ops.Node, index int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.SliceShape(xNode.typ.shape, index)
+	if err != nil {
+		return nil, err
+	}
+	axisLengths := xNode.typ.shape.AxisLengths
+	start := make([]int, len(axisLengths))
+	limit := slices.Clone(axisLengths)
+	strides := make([]int, len(axisLengths))
+	for i := range strides {
+		strides[i] = 1
+	}
+	start[0], limit[0] = index, index+1
+	sliceShape := &shape.Shape{DType: sh.DType, AxisLengths: append([]int{1}, sh.AxisLengths...), Quant: sh.Quant}
+	attrs := fmt.Sprintf("start_indices = %s, limit_indices = %s, strides = %s", i64Array(start), i64Array(limit), i64Array(strides))
+	slice, err := b.g.emit("stablehlo.slice", []*Node{xNode}, attrs, valueType{shape: sliceShape})
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.reshape", []*Node{slice}, "", valueType{shape: sh}))
+}
+
+func (b coreBuilder) Set(x, updates, index ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, updates, index})
+	if err != nil {
+		return nil, err
+	}
+	xNode, updatesNode, indexNode := operands[0], operands[1], operands[2]
+	if !indexNode.typ.shape.IsAtomic() || !dtype.IsInteger(indexNode.typ.shape.DType) {
+		return nil, fmt.Errorf("cannot set a slice with an index of shape %s: index must be an atomic integer", indexNode.typ.shape)
+	}
+	want, err := shape.SliceShape(xNode.typ.shape, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(updatesNode.typ.shape.AxisLengths, want.AxisLengths) || updatesNode.typ.shape.DType != want.DType {
+		return nil, fmt.Errorf("cannot set a slice of %s with updates of shape %s", xNode.typ.shape, updatesNode.typ.shape)
+	}
+	updateShape := &shape.Shape{DType: want.DType, AxisLengths: append([]int{1}, want.AxisLengths...), Quant: want.Quant}
+	update, err := b.g.emit("stablehlo.reshape", []*Node{updatesNode}, "", valueType{shape: updateShape})
+	if err != nil {
+		return nil, err
+	}
+	indices := []*Node{indexNode}
+	if len(want.AxisLengths) > 0 {
+		zero, err := b.zero(indexNode.typ.shape.DType)
+		if err != nil {
+			return nil, err
+		}
+		for range want.AxisLengths {
+			indices = append(indices, zero)
+		}
+	}
+	return wrap(b.g.emit("stablehlo.dynamic_update_slice", append([]*Node{xNode, update}, indices...), "", xNode.typ))
+}
+
+// zero returns an atomic constant equal to zero.
+func (b coreBuilder) zero(dt dtype.DataType) (*Node, error) {
+	sh := shape.Scalar(dt)
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.emit("stablehlo.constant", nil, "value = dense<0> : "+typ, valueType{shape: sh})
+}
+
+var precisions = map[ops.Precision]string{
+	ops.DefaultPrecision: "DEFAULT",
+	ops.TF32Precision:    "HIGH",
+	ops.HighestPrecision: "HIGHEST",
+}
+
+func (b coreBuilder) DotGeneral(x, y ops.Node, batchAxes, reduceAxes [2][]int, precision ops.Precision) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.DotGeneralShape(operands[0].typ.shape, operands[1].typ.shape, batchAxes, reduceAxes)
+	if err != nil {
+		return nil, err
+	}
+	prec, ok := precisions[precision]
+	if !ok {
+		return nil, fmt.Errorf("precision %s not supported", precision)
+	}
+	var dims []string
+	for _, field := range []struct {
+		name string
+		axes []int
+	}{
+		{"lhs_batching_dimensions", batchAxes[0]},
+		{"rhs_batching_dimensions", batchAxes[1]},
+		{"lhs_contracting_dimensions", reduceAxes[0]},
+		{"rhs_contracting_dimensions", reduceAxes[1]},
+	} {
+		if len(field.axes) > 0 {
+			dims = append(dims, field.name+" = "+intList(field.axes))
+		}
+	}
+	attrs := fmt.Sprintf("dot_dimension_numbers = #stablehlo.dot<%s>, precision_config = [#stablehlo<precision %s>, #stablehlo<precision %s>]", strings.Join(dims, ", "), prec, prec)
+	return wrap(b.g.emit("stablehlo.dot_general", operands, attrs, valueType{shape: sh}))
+}
+
+// flatten returns the tensors of a value: the elements of a tuple or the value itself.
+func (b coreBuilder) flatten(n *Node) ([]*Node, error) {
+	if !n.typ.isTuple() {
+		return []*Node{n}, nil
+	}
+	elems := make([]*Node, len(n.typ.elems))
+	for i, elemType := range n.typ.elems {
+		var err error
+		if elems[i], err = b.g.emit("stablehlo.get_tuple_element", []*Node{n}, fmt.Sprintf("index = %d : i32", i), elemType); err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
+}
+
+func (b coreBuilder) While(cond, body *ops.Subgraph, state ops.Node) (ops.Node, error) {
+	stateNode, err := b.g.node(state)
+	if err != nil {
+		return nil, err
+	}
+	condGraph, condResult, err := b.subgraph(cond)
+	if err != nil {
+		return nil, err
+	}
+	bodyGraph, bodyResult, err := b.subgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	if condResult.typ.isTuple() || condResult.typ.shape.DType != dtype.Bool || !condResult.typ.shape.IsAtomic() {
+		return nil, fmt.Errorf("condition %s of while loop does not return an atomic boolean", condGraph.name)
+	}
+	operands, err := b.flatten(stateNode)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]valueType, len(operands))
+	for i, operand := range operands {
+		types[i] = operand.typ
+	}
+	if bodyResult.typ.isTuple() != stateNode.typ.isTuple() {
+		return nil, fmt.Errorf("body %s of while loop does not return the state", bodyGraph.name)
+	}
+	sig, err := b.g.signature(operands, types...)
+	if err != nil {
+		return nil, err
+	}
+	// Regions are written in the body of the function, sharing its SSA names.
+	loop := b.g.newValue()
+	b.g.body = append(b.g.body, b.head(loop, "stablehlo.while", operands, types))
+	condPred, err := b.region(condGraph, operands, condResult.typ)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.regionReturn(condPred); err != nil {
+		return nil, err
+	}
+	b.g.body = append(b.g.body, "}, {")
+	next, err := b.region(bodyGraph, operands, bodyResult.typ)
+	if err != nil {
+		return nil, err
+	}
+	nextValues, err := b.flatten(next)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.regionReturn(nextValues...); err != nil {
+		return nil, err
+	}
+	b.g.body = append(b.g.body, "}) : "+sig)
+	return b.results(loop, types, stateNode.typ)
+}
+
+// head returns the beginning of an operation with regions returning values of a list of types.
+func (b coreBuilder) head(name, op string, operands []*Node, types []valueType) string {
+	names := make([]string, len(operands))
+	for i, operand := range operands {
+		names[i] = operand.name
+	}
+	if len(types) > 1 {
+		name = fmt.Sprintf("%s:%d", name, len(types))
+	}
+	return fmt.Sprintf("%s = \"%s\"(%s) ({", name, op, strings.Join(names, ", "))
+}
+
+// results returns the value of an operation returning values of a list of types,
+// packing them into a tuple of type typ if there is more than one.
+func (b coreBuilder) results(name string, types []valueType, typ valueType) (ops.Node, error) {
+	if !typ.isTuple() {
+		return &Node{graph: b.g, name: name, typ: types[0]}, nil
+	}
+	results := make([]*Node, len(types))
+	for i := range results {
+		results[i] = &Node{graph: b.g, name: name, typ: types[i]}
+		if len(types) > 1 {
+			results[i].name = fmt.Sprintf("%s#%d", name, i)
+		}
+	}
+	return wrap(b.g.emit("stablehlo.tuple", results, "", typ))
+}
+
+func (b coreBuilder) Cond(pred ops.Node, trueBranch, falseBranch *ops.Subgraph, args ...ops.Node) (ops.Node, error) {
+	predNode, err := b.g.node(pred)
+	if err != nil {
+		return nil, err
+	}
+	argNodes, err := b.g.nodes(args)
+	if err != nil {
+		return nil, err
+	}
+	if predNode.typ.isTuple() || predNode.typ.shape.DType != dtype.Bool || !predNode.typ.shape.IsAtomic() {
+		return nil, fmt.Errorf("condition %s is not an atomic boolean", predNode)
+	}
+	branches := make([]*Graph, 2)
+	results := make([]*Node, 2)
+	for i, sg := range []*ops.Subgraph{trueBranch, falseBranch} {
+		if branches[i], results[i], err = b.subgraph(sg); err != nil {
+			return nil, err
+		}
+	}
+	typ := results[0].typ
+	trueType, err := mlirType(b.g.plat, typ)
+	if err != nil {
+		return nil, err
+	}
+	falseType, err := mlirType(b.g.plat, results[1].typ)
+	if err != nil {
+		return nil, err
+	}
+	if trueType != falseType {
+		return nil, fmt.Errorf("branches %s and %s return mismatched types %s and %s", branches[0].name, branches[1].name, trueType, falseType)
+	}
+	types := []valueType{typ}
+	if typ.isTuple() {
+		types = typ.elems
+	}
+	sig, err := b.g.signature([]*Node{predNode}, types...)
+	if err != nil {
+		return nil, err
+	}
+	// Regions have no argument: they call the branches with values of the function.
+	name := b.g.newValue()
+	b.g.body = append(b.g.body, b.head(name, "stablehlo.if", []*Node{predNode}, types))
+	for i, branch := range branches {
+		if i > 0 {
+			b.g.body = append(b.g.body, "}, {")
+		}
+		result, err := b.call(branch, argNodes, typ)
+		if err != nil {
+			return nil, err
+		}
+		values, err := b.flatten(result)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.regionReturn(values...); err != nil {
+			return nil, err
+		}
+	}
+	b.g.body = append(b.g.body, "}) : "+sig)
+	return b.results(name, types, typ)
+}
+
+func (b coreBuilder) Select(pred, onTrue, onFalse ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{pred, onTrue, onFalse})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.SelectShape(operands[0].typ.shape, operands[1].typ.shape, operands[2].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.select", operands, "", valueType{shape: sh}))
+}
+
+// region writes the block of a while region calling a subgraph with the loop state.
+func (b coreBuilder) region(sub *Graph, state []*Node, result valueType) (*Node, error) {
+	args := make([]*Node, len(state))
+	blockArgs := make([]string, len(state))
+	for i, s := range state {
+		args[i] = &Node{graph: b.g, name: b.g.newValue(), typ: s.typ}
+		typ, err := mlirType(b.g.plat, s.typ)
+		if err != nil {
+			return nil, err
+		}
+		blockArgs[i] = args[i].name + ": " + typ
+	}
+	b.g.body = append(b.g.body, "^bb0("+strings.Join(blockArgs, ", ")+"):")
+	return b.call(sub, args, result)
+}
+
+func (b coreBuilder) regionReturn(values ...*Node) error {
+	names := make([]string, len(values))
+	types := make([]string, len(values))
+	for i, v := range values {
+		var err error
+		if types[i], err = mlirType(b.g.plat, v.typ); err != nil {
+			return err
+		}
+		names[i] = v.name
+	}
+	b.g.body = append(b.g.body, fmt.Sprintf("\"stablehlo.return\"(%s) : (%s) -> ()", strings.Join(names, ", "), strings.Join(types, ", ")))
+	return nil
+}
+
+func (b coreBuilder) BroadcastInDim(x ops.Node, sh *shape.Shape, broadcastAxes []int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	out, err := shape.BroadcastInDimShape(xNode.typ.shape, sh.AxisLengths, broadcastAxes)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.broadcast_in_dim", []*Node{xNode}, "broadcast_dimensions = "+i64Array(broadcastAxes), valueType{shape: out}))
+}
+
+func (b coreBuilder) Quantize(x ops.Node, quant *dtype.Quantization) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.QuantizeShape(xNode.typ.shape, quant)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.uniform_quantize", []*Node{xNode}, "", valueType{shape: sh}))
+}
+
+func (b coreBuilder) Dequantize(x ops.Node) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.DequantizeShape(xNode.typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.uniform_dequantize", []*Node{xNode}, "", valueType{shape: sh}))
+}
+
+// reductions maps reduction operations to the StableHLO operation combining two values.
+var reductions = map[ops.OpID]string{
+	ops.OpReduceSum:  "stablehlo.add",
+	ops.OpReduceProd: "stablehlo.multiply",
+	ops.OpReduceMax:  "stablehlo.maximum",
+	ops.OpReduceMin:  "stablehlo.minimum",
+}
+
+// infinities are the literals of the negative and positive infinities of floating-point types.
+var infinities = map[dtype.DataType][2]string{
+	dtype.Bfloat16: {"0xFF80", "0x7F80"},
+	dtype.Float16:  {"0xFC00", "0x7C00"},
+	dtype.Float32:  {"0xFF800000", "0x7F800000"},
+	dtype.Float64:  {"0xFFF0000000000000", "0x7FF0000000000000"},
+}
+
+// reduceInit returns the literal of the initial value of a reduction for a data type.
+func reduceInit(op ops.OpID, dt dtype.DataType) (string, error) {
+	switch op {
+	case ops.OpReduceSum:
+		return "dense<0>", nil
+	case ops.OpReduceProd:
+		return "dense<1>", nil
+	}
+	// The maximum starts from the lowest value and the minimum from the highest.
+	lowest := op == ops.OpReduceMax
+	if inf, ok := infinities[dt]; ok {
+		if lowest {
+			return "dense<" + inf[0] + ">", nil
+		}
+		return "dense<" + inf[1] + ">", nil
+	}
+	if dt == dtype.Bool {
+		return fmt.Sprintf("dense<%t>", !lowest), nil
+	}
+	if !dtype.IsInteger(dt) && !dtype.IsSubByte(dt) {
+		return "", fmt.Errorf("%s not supported on data type %s", op, dt)
+	}
+	shift := 64 - dtype.BitSizeof(dt)
+	if dtype.IsUnsigned(dt) || dt == dtype.Uint4 {
+		if lowest {
+			return "dense<0>", nil
+		}
+		return fmt.Sprintf("dense<%d>", uint64(math.MaxUint64)>>shift), nil
+	}
+	if lowest {
+		return fmt.Sprintf("dense<%d>", int64(math.MinInt64)>>shift), nil
+	}
+	return fmt.Sprintf("dense<%d>", int64(math.MaxInt64)>>shift), nil
+}
+
+// reduce writes a stablehlo.reduce operation. combine writes the body of the region
+// from its two block arguments and returns the combined value.
+func (b coreBuilder) reduce(x, init *Node, axes []int, combine func(lhs, rhs *Node) (*Node, error)) (ops.Node, error) {
+	if init.typ.isTuple() {
+		return nil, fmt.Errorf("initial value %s of a reduction is a tuple", init)
+	}
+	sh, err := intercept.ReduceShape(init.typ.shape, x.typ.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.reduction("stablehlo.reduce", x, init, "dimensions = "+i64Array(axes), valueType{shape: sh}, combine))
+}
+
+// reduction writes an operation reducing x from init with a region of type typ.
+// combine writes the body of the region from its two block arguments and returns the combined value.
+func (b coreBuilder) reduction(op string, x, init *Node, attrs string, typ valueType, combine func(lhs, rhs *Node) (*Node, error)) (*Node, error) {
+	sig, err := b.g.signature([]*Node{x, init}, typ)
+	if err != nil {
+		return nil, err
+	}
+	elemType, err := mlirType(b.g.plat, init.typ)
+	if err != nil {
+		return nil, err
+	}
+	// The region is written in the body of the function, sharing its SSA names.
+	result := &Node{graph: b.g, name: b.g.newValue(), typ: typ}
+	b.g.body = append(b.g.body, b.head(result.name, op, []*Node{x, init}, []valueType{typ}))
+	lhs := &Node{graph: b.g, name: b.g.newValue(), typ: init.typ}
+	rhs := &Node{graph: b.g, name: b.g.newValue(), typ: init.typ}
+	b.g.body = append(b.g.body, fmt.Sprintf("^bb0(%s: %s, %s: %s):", lhs.name, elemType, rhs.name, elemType))
+	combined, err := combine(lhs, rhs)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.regionReturn(combined); err != nil {
+		return nil, err
+	}
+	b.g.body = append(b.g.body, fmt.Sprintf("}) {%s} : %s", attrs, sig))
+	return result, nil
+}
+
+// initValue emits the initial value of one of the ReduceSum, ReduceProd, ReduceMax
+// or ReduceMin operations for a data type.
+func (b coreBuilder) initValue(op ops.OpID, dt dtype.DataType) (*Node, error) {
+	sh := shape.Scalar(dt)
+	literal, err := reduceInit(op, platform.Resolve(b.g.plat, dt))
+	if err != nil {
+		return nil, err
+	}
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.emit("stablehlo.constant", nil, "value = "+literal+" : "+typ, valueType{shape: sh})
+}
+
+// reduceOp applies one of the ReduceSum, ReduceProd, ReduceMax or ReduceMin operations.
+func (b coreBuilder) reduceOp(op ops.OpID, x ops.Node, axes []int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := intercept.ReduceOpShape(op, xNode.typ.shape, axes); err != nil {
+		return nil, err
+	}
+	init, err := b.initValue(op, xNode.typ.shape.DType)
+	if err != nil {
+		return nil, err
+	}
+	return b.reduce(xNode, init, axes, func(lhs, rhs *Node) (*Node, error) {
+		return b.g.emit(reductions[op], []*Node{lhs, rhs}, "", init.typ)
+	})
+}
+
+func (b coreBuilder) ReduceSum(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceSum, x, axes)
+}
+
+func (b coreBuilder) ReduceProd(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceProd, x, axes)
+}
+
+func (b coreBuilder) ReduceMax(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceMax, x, axes)
+}
+
+func (b coreBuilder) ReduceMin(x ops.Node, axes []int) (ops.Node, error) {
+	return b.reduceOp(ops.OpReduceMin, x, axes)
+}
+
+func (b coreBuilder) Reduce(body *ops.Subgraph, init, x ops.Node, axes []int) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, init})
+	if err != nil {
+		return nil, err
+	}
+	combine, err := b.combiner(body, operands[1])
+	if err != nil {
+		return nil, err
+	}
+	return b.reduce(operands[0], operands[1], axes, combine)
+}
+
+// combiner returns a function calling the body of a reduction starting from init.
+func (b coreBuilder) combiner(body *ops.Subgraph, init *Node) (func(lhs, rhs *Node) (*Node, error), error) {
+	bodyGraph, bodyResult, err := b.subgraph(body)
+	if err != nil {
+		return nil, err
+	}
+	if init.typ.isTuple() || bodyResult.typ.isTuple() || !bodyResult.typ.shape.EqualIgnoringLayout(init.typ.shape) {
+		return nil, fmt.Errorf("body %s of reduction returns %s but want %s", bodyGraph.name, bodyResult, init)
+	}
+	return func(lhs, rhs *Node) (*Node, error) {
+		return b.call(bodyGraph, []*Node{lhs, rhs}, bodyResult.typ)
+	}, nil
+}
+
+// dimensionNumbers returns the fields of a StableHLO dimension numbers attribute.
+// Empty lists are omitted.
+func dimensionNumbers(lists []string, axes [][]int, name string, index int) string {
+	var fields []string
+	for i, list := range lists {
+		if len(axes[i]) > 0 {
+			fields = append(fields, list+" = "+intList(axes[i]))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("%s = %d", name, index))
+	return strings.Join(fields, ", ")
+}
+
+func (b coreBuilder) Gather(x, indices ops.Node, dims ops.GatherDims, sliceSizes []int) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, indices})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.GatherShape(operands[0].typ.shape, operands[1].typ.shape, dims, sliceSizes)
+	if err != nil {
+		return nil, err
+	}
+	numbers := dimensionNumbers(
+		[]string{"offset_dims", "collapsed_slice_dims", "start_index_map"},
+		[][]int{dims.OffsetAxes, dims.CollapsedAxes, dims.StartIndexMap},
+		"index_vector_dim", dims.IndexVectorAxis)
+	attrs := fmt.Sprintf("dimension_numbers = #stablehlo.gather<%s>, slice_sizes = %s, indices_are_sorted = false", numbers, i64Array(sliceSizes))
+	return wrap(b.g.emit("stablehlo.gather", operands, attrs, valueType{shape: sh}))
+}
+
+func (b coreBuilder) Scatter(x, indices, updates ops.Node, dims ops.ScatterDims) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, indices, updates})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ScatterShape(operands[0].typ.shape, operands[1].typ.shape, operands[2].typ.shape, dims)
+	if err != nil {
+		return nil, err
+	}
+	typ := valueType{shape: sh}
+	sig, err := b.g.signature(operands, typ)
+	if err != nil {
+		return nil, err
+	}
+	elem := valueType{shape: shape.Scalar(sh.DType)}
+	elemType, err := mlirType(b.g.plat, elem)
+	if err != nil {
+		return nil, err
+	}
+	// The region replaces the values of the operand with the updates.
+	result := &Node{graph: b.g, name: b.g.newValue(), typ: typ}
+	b.g.body = append(b.g.body, fmt.Sprintf("%s = \"stablehlo.scatter\"(%s, %s, %s) ({", result.name, operands[0].name, operands[1].name, operands[2].name))
+	current := &Node{graph: b.g, name: b.g.newValue(), typ: elem}
+	update := &Node{graph: b.g, name: b.g.newValue(), typ: elem}
+	b.g.body = append(b.g.body, fmt.Sprintf("^bb0(%s: %s, %s: %s):", current.name, elemType, update.name, elemType))
+	if err := b.regionReturn(update); err != nil {
+		return nil, err
+	}
+	numbers := dimensionNumbers(
+		[]string{"update_window_dims", "inserted_window_dims", "scatter_dims_to_operand_dims"},
+		[][]int{dims.UpdateWindowAxes, dims.InsertedWindowAxes, dims.ScatterAxesToOperandAxes},
+		"index_vector_dim", dims.IndexVectorAxis)
+	b.g.body = append(b.g.body, fmt.Sprintf("}) {scatter_dimension_numbers = #stablehlo.scatter<%s>, indices_are_sorted = false, unique_indices = false} : %s", numbers, sig))
+	return result, nil
+}
+
+func (b coreBuilder) Transpose(x ops.Node, permutation []int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.TransposeShape(xNode.typ.shape, permutation)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.transpose", []*Node{xNode}, "permutation = "+i64Array(permutation), valueType{shape: sh}))
+}
+
+func (b coreBuilder) Reverse(x ops.Node, axes []int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := shape.ReverseShape(xNode.typ.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.reverse", []*Node{xNode}, "dimensions = "+i64Array(axes), valueType{shape: sh}))
+}
+
+func (b coreBuilder) Pad(x, value ops.Node, low, high, interior []int) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, value})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.PadShape(operands[0].typ.shape, operands[1].typ.shape, low, high, interior)
+	if err != nil {
+		return nil, err
+	}
+	attrs := fmt.Sprintf("edge_padding_low = %s, edge_padding_high = %s, interior_padding = %s", i64Array(low), i64Array(high), i64Array(interior))
+	return wrap(b.g.emit("stablehlo.pad", operands, attrs, valueType{shape: sh}))
+}
+
+// sortType returns the type of the comparison sorting values of a data type.
+// Floating-point values are compared with a total order.
+func sortType(plat platform.Platform, dt dtype.DataType) string {
+	if typ := comparisonType(plat, dt); typ != "FLOAT" {
+		return typ
+	}
+	return "TOTALORDER"
+}
+
+// sort writes a stablehlo.sort operation sorting operands along an axis by the values of the first operand.
+func (b coreBuilder) sort(operands []*Node, axis int, descending bool) ([]*Node, error) {
+	types := make([]valueType, len(operands))
+	for i, operand := range operands {
+		if operand.typ.isTuple() {
+			return nil, fmt.Errorf("cannot sort tuple %s", operand)
+		}
+		types[i] = operand.typ
+	}
+	sig, err := b.g.signature(operands, types...)
+	if err != nil {
+		return nil, err
+	}
+	// The region is written in the body of the function, sharing its SSA names.
+	name := b.g.newValue()
+	b.g.body = append(b.g.body, b.head(name, "stablehlo.sort", operands, types))
+	blockArgs := make([]string, 0, 2*len(operands))
+	var lhs, rhs *Node
+	for i, operand := range operands {
+		elem := valueType{shape: shape.Scalar(operand.typ.shape.DType)}
+		elemType, err := mlirType(b.g.plat, elem)
+		if err != nil {
+			return nil, err
+		}
+		l := &Node{graph: b.g, name: b.g.newValue(), typ: elem}
+		r := &Node{graph: b.g, name: b.g.newValue(), typ: elem}
+		if i == 0 {
+			lhs, rhs = l, r
+		}
+		blockArgs = append(blockArgs, l.name+": "+elemType, r.name+": "+elemType)
+	}
+	b.g.body = append(b.g.body, "^bb0("+strings.Join(blockArgs, ", ")+"):")
+	direction := "LT"
+	if descending {
+		direction = "GT"
+	}
+	attrs := fmt.Sprintf("comparison_direction = #stablehlo<comparison_direction %s>, compare_type = #stablehlo<comparison_type %s>", direction, sortType(b.g.plat, lhs.typ.shape.DType))
+	ordered, err := b.g.emit("stablehlo.compare", []*Node{lhs, rhs}, attrs, valueType{shape: shape.Scalar(dtype.Bool)})
+	if err != nil {
+		return nil, err
+	}
+	if err := b.regionReturn(ordered); err != nil {
+		return nil, err
+	}
+	b.g.body = append(b.g.body, fmt.Sprintf("}) {dimension = %d : i64, is_stable = true} : %s", axis, sig))
+	results := make([]*Node, len(types))
+	for i, typ := range types {
+		results[i] = &Node{graph: b.g, name: name, typ: typ}
+		if len(types) > 1 {
+			results[i].name = fmt.Sprintf("%s#%d", name, i)
+		}
+	}
+	return results, nil
+}
+
+// sortWithIndices sorts x along an axis together with the int32 indices of its elements along that axis.
+func (b coreBuilder) sortWithIndices(x *Node, axis int, descending bool) (values, indices *Node, err error) {
+	indicesShape := &shape.Shape{DType: dtype.Int32, AxisLengths: slices.Clone(x.typ.shape.AxisLengths)}
+	iota, err := b.g.emit("stablehlo.iota", nil, fmt.Sprintf("iota_dimension = %d : i64", axis), valueType{shape: indicesShape})
+	if err != nil {
+		return nil, nil, err
+	}
+	sorted, err := b.sort([]*Node{x, iota}, axis, descending)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sorted[0], sorted[1], nil
+}
+
+// sortAxis returns the node of the array to sort and the axis along which it is sorted.
+func (b coreBuilder) sortAxis(op ops.OpID, x ops.Node, axis int) (*Node, int, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, 0, err
+	}
+	if xNode.typ.isTuple() {
+		return nil, 0, fmt.Errorf("cannot sort tuple %s", xNode)
+	}
+	if _, err := intercept.SortShape(op, xNode.typ.shape, axis); err != nil {
+		return nil, 0, err
+	}
+	axis, err = shape.NormalizeAxis(axis, len(xNode.typ.shape.AxisLengths))
+	if err != nil {
+		return nil, 0, err
+	}
+	return xNode, axis, nil
+}
+
+func (b coreBuilder) Sort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	xNode, axis, err := b.sortAxis(ops.OpSort, x, axis)
+	if err != nil {
+		return nil, err
+	}
+	sorted, err := b.sort([]*Node{xNode}, axis, descending)
+	if err != nil {
+		return nil, err
+	}
+	return sorted[0], nil
+}
+
+func (b coreBuilder) ArgSort(x ops.Node, axis int, descending bool) (ops.Node, error) {
+	xNode, axis, err := b.sortAxis(ops.OpArgSort, x, axis)
+	if err != nil {
+		return nil, err
+	}
+	_, indices, err := b.sortWithIndices(xNode, axis, descending)
+	if err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (b coreBuilder) TopK(x ops.Node, k int) (ops.Tuple, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if xNode.typ.isTuple() {
+		return nil, fmt.Errorf("cannot select the top elements of tuple %s", xNode)
+	}
+	sh, err := intercept.TopKShape(xNode.typ.shape, k)
+	if err != nil {
+		return nil, err
+	}
+	indicesShape := &shape.Shape{DType: dtype.Int32, AxisLengths: sh.AxisLengths}
+	types := []valueType{{shape: sh}, {shape: indicesShape}}
+	results, err := b.g.emitResults("chlo.top_k", []*Node{xNode}, fmt.Sprintf("k = %d : i64", k), types)
+	if err != nil {
+		return nil, err
+	}
+	tuple, err := b.g.emit("stablehlo.tuple", results, "", valueType{elems: types})
+	if err != nil {
+		return nil, err
+	}
+	return &Tuple{Node: tuple}, nil
+}
+
+type dtypeBuilder struct {
+	g *Graph
+}
+
+var _ ops.DTypeBuilder = dtypeBuilder{}
+
+// Bitcast reinterprets the bits of x. If the sizes of the data types differ,
+// the innermost axis is added or removed as in StableHLO.
+func (b dtypeBuilder) Bitcast(x ops.Node, target dtype.DataType) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	src := xNode.typ.shape
+	srcSize := dtype.Sizeof(platform.Resolve(b.g.plat, src.DType))
+	dstSize := dtype.Sizeof(platform.Resolve(b.g.plat, target))
+	axisLengths := slices.Clone(src.AxisLengths)
+	switch {
+	case srcSize == 0 || dstSize == 0:
+		return nil, fmt.Errorf("cannot bitcast %s to %s", src, target)
+	case srcSize > dstSize:
+		axisLengths = append(axisLengths, srcSize/dstSize)
+	case srcSize < dstSize:
+		if len(axisLengths) == 0 || axisLengths[len(axisLengths)-1]*srcSize != dstSize {
+			return nil, fmt.Errorf("cannot bitcast %s to %s: innermost axis must have length %d", src, target, dstSize/srcSize)
+		}
+		axisLengths = axisLengths[:len(axisLengths)-1]
+	}
+	return wrap(b.g.emit("stablehlo.bitcast_convert", []*Node{xNode}, "", valueType{shape: shape.Of(target, axisLengths...)}))
+}
+
+type numBuilder struct {
+	g *Graph
+}
+
+var _ ops.NumBuilder = numBuilder{}
+
+func (b numBuilder) Iota(sh *shape.Shape, iotaAxis int) (ops.Node, error) {
+	axis, err := shape.NormalizeAxis(iotaAxis, len(sh.AxisLengths))
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.iota", nil, fmt.Sprintf("iota_dimension = %d : i64", axis), valueType{shape: sh}))
+}
+
+// totalOrderBounds are the literals of the lowest and highest floating-point values
+// in the total order used to sort: NaNs with the sign bit set and cleared respectively.
+var totalOrderBounds = map[dtype.DataType][2]string{
+	dtype.Bfloat16: {"0xFFFF", "0x7FFF"},
+	dtype.Float16:  {"0xFFFF", "0x7FFF"},
+	dtype.Float32:  {"0xFFFFFFFF", "0x7FFFFFFF"},
+	dtype.Float64:  {"0xFFFFFFFFFFFFFFFF", "0x7FFFFFFFFFFFFFFF"},
+}
+
+// argInit emits the initial value of one of the Argmax or Argmin operations for a data type:
+// the lowest value for Argmax and the highest value for Argmin.
+func (b numBuilder) argInit(op ops.OpID, dt dtype.DataType) (*Node, error) {
+	bounds, ok := totalOrderBounds[platform.Resolve(b.g.plat, dt)]
+	if !ok {
+		reduceOp := ops.OpReduceMax
+		if op == ops.OpArgmin {
+			reduceOp = ops.OpReduceMin
+		}
+		return coreBuilder{g: b.g}.initValue(reduceOp, dt)
+	}
+	literal := bounds[0]
+	if op == ops.OpArgmin {
+		literal = bounds[1]
+	}
+	sh := shape.Scalar(dt)
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.emit("stablehlo.constant", nil, "value = dense<"+literal+"> : "+typ, valueType{shape: sh})
+}
+
+// arg reduces x together with the indices of its elements along an axis, keeping the greater
+// element for Argmax or the lesser element for Argmin, and the lower index for equal elements.
+func (b numBuilder) arg(op ops.OpID, x ops.Node, axis int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if xNode.typ.isTuple() {
+		return nil, fmt.Errorf("%s applied to tuple %s", op, xNode)
+	}
+	indicesShape, err := intercept.ArgShape(op, xNode.typ.shape, axis)
+	if err != nil {
+		return nil, err
+	}
+	dt := xNode.typ.shape.DType
+	axis, err = shape.NormalizeAxis(axis, len(xNode.typ.shape.AxisLengths))
+	if err != nil {
+		return nil, err
+	}
+	iotaShape := &shape.Shape{DType: dtype.Int32, AxisLengths: xNode.typ.shape.AxisLengths}
+	iota, err := b.g.emit("stablehlo.iota", nil, fmt.Sprintf("iota_dimension = %d : i64", axis), valueType{shape: iotaShape})
+	if err != nil {
+		return nil, err
+	}
+	initValue, err := b.argInit(op, dt)
+	if err != nil {
+		return nil, err
+	}
+	index := valueType{shape: shape.Scalar(dtype.Int32)}
+	initIndex, err := b.g.emit("stablehlo.constant", nil, fmt.Sprintf("value = dense<%d> : tensor<i32>", math.MaxInt32), index)
+	if err != nil {
+		return nil, err
+	}
+	operands := []*Node{xNode, iota, initValue, initIndex}
+	types := []valueType{{shape: &shape.Shape{DType: dt, AxisLengths: indicesShape.AxisLengths}}, {shape: indicesShape}}
+	sig, err := b.g.signature(operands, types...)
+	if err != nil {
+		return nil, err
+	}
+	// The region is written in the body of the function, sharing its SSA names.
+	core := coreBuilder{g: b.g}
+	name := b.g.newValue()
+	b.g.body = append(b.g.body, core.head(name, "stablehlo.reduce", operands, types))
+	args := make([]*Node, 4)
+	blockArgs := make([]string, len(args))
+	for i := range args {
+		args[i] = &Node{graph: b.g, name: b.g.newValue(), typ: operands[2+i%2].typ}
+		typ, err := mlirType(b.g.plat, args[i].typ)
+		if err != nil {
+			return nil, err
+		}
+		blockArgs[i] = args[i].name + ": " + typ
+	}
+	b.g.body = append(b.g.body, "^bb0("+strings.Join(blockArgs, ", ")+"):")
+	lhs, lhsIndex, rhs, rhsIndex := args[0], args[1], args[2], args[3]
+	direction := "GT"
+	if op == ops.OpArgmin {
+		direction = "LT"
+	}
+	boolType := valueType{shape: shape.Scalar(dtype.Bool)}
+	compare := func(direction, typ string, x, y *Node) (*Node, error) {
+		attrs := fmt.Sprintf("comparison_direction = #stablehlo<comparison_direction %s>, compare_type = #stablehlo<comparison_type %s>", direction, typ)
+		return b.g.emit("stablehlo.compare", []*Node{x, y}, attrs, boolType)
+	}
+	better, err := compare(direction, sortType(b.g.plat, dt), lhs, rhs)
+	if err != nil {
+		return nil, err
+	}
+	equal, err := compare("EQ", sortType(b.g.plat, dt), lhs, rhs)
+	if err != nil {
+		return nil, err
+	}
+	first, err := compare("LT", "SIGNED", lhsIndex, rhsIndex)
+	if err != nil {
+		return nil, err
+	}
+	tie, err := b.g.emit("stablehlo.and", []*Node{equal, first}, "", boolType)
+	if err != nil {
+		return nil, err
+	}
+	pick, err := b.g.emit("stablehlo.or", []*Node{better, tie}, "", boolType)
+	if err != nil {
+		return nil, err
+	}
+	value, err := b.g.emit("stablehlo.select", []*Node{pick, lhs, rhs}, "", lhs.typ)
+	if err != nil {
+		return nil, err
+	}
+	valueIndex, err := b.g.emit("stablehlo.select", []*Node{pick, lhsIndex, rhsIndex}, "", index)
+	if err != nil {
+		return nil, err
+	}
+	if err := core.regionReturn(value, valueIndex); err != nil {
+		return nil, err
+	}
+	b.g.body = append(b.g.body, fmt.Sprintf("}) {dimensions = %s} : %s", i64Array([]int{axis}), sig))
+	return &Node{graph: b.g, name: name + "#1", typ: types[1]}, nil
+}
+
+func (b numBuilder) Argmax(x ops.Node, axis int) (ops.Node, error) {
+	return b.arg(ops.OpArgmax, x, axis)
+}
+
+func (b numBuilder) Argmin(x ops.Node, axis int) (ops.Node, error) {
+	return b.arg(ops.OpArgmin, x, axis)
+}
+
+// cumulative reduces the windows of x ending at every element along an axis with one of the
+// ReduceSum or ReduceProd operations.
+func (b numBuilder) cumulative(op, reduce ops.OpID, x ops.Node, axis int) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if xNode.typ.isTuple() {
+		return nil, fmt.Errorf("%s applied to tuple %s", op, xNode)
+	}
+	if _, err := intercept.CumShape(op, xNode.typ.shape, axis); err != nil {
+		return nil, err
+	}
+	axisLengths := xNode.typ.shape.AxisLengths
+	axis, err = shape.NormalizeAxis(axis, len(axisLengths))
+	if err != nil {
+		return nil, err
+	}
+	if axisLengths[axis] == 0 {
+		return xNode, nil
+	}
+	window := ops.Window{
+		Dimensions: make([]int, len(axisLengths)),
+		Padding:    make([][2]int, len(axisLengths)),
+	}
+	for i := range window.Dimensions {
+		window.Dimensions[i] = 1
+	}
+	window.Dimensions[axis] = axisLengths[axis]
+	window.Padding[axis][0] = axisLengths[axis] - 1
+	init, err := coreBuilder{g: b.g}.initValue(reduce, xNode.typ.shape.DType)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(nnBuilder{g: b.g}.reduceWindow(xNode, init, window, func(lhs, rhs *Node) (*Node, error) {
+		return b.g.emit(reductions[reduce], []*Node{lhs, rhs}, "", init.typ)
+	}))
+}
+
+func (b numBuilder) CumSum(x ops.Node, axis int) (ops.Node, error) {
+	return b.cumulative(ops.OpCumSum, ops.OpReduceSum, x, axis)
+}
+
+func (b numBuilder) CumProd(x ops.Node, axis int) (ops.Node, error) {
+	return b.cumulative(ops.OpCumProd, ops.OpReduceProd, x, axis)
+}
+
+type mathBuilder struct {
+	g *Graph
+}
+
+var _ ops.MathBuilder = mathBuilder{}
+
+// unary emits an element-wise math function.
+func (b mathBuilder) unary(op string, x ops.Node) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit(op, []*Node{xNode}, "", xNode.typ))
+}
+
+// Abs emits stablehlo.abs, which returns the modulus of complex numbers as floats.
+func (b mathBuilder) Abs(x ops.Node) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.abs", []*Node{xNode}, "", valueType{shape: intercept.AbsShape(xNode.typ.shape)}))
+}
+
+// binary emits an element-wise math function of two arguments, broadcasting an atomic argument.
+func (b mathBuilder) binary(op ops.OpID, name string, x, y ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, y})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.MathBinaryShape(op, operands[0].typ.shape, operands[1].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	core := coreBuilder{g: b.g}
+	for i, operand := range operands {
+		if operands[i], err = core.broadcastScalar(operand, sh.AxisLengths); err != nil {
+			return nil, err
+		}
+	}
+	return wrap(b.g.emit(name, operands, "", valueType{shape: sh}))
+}
+
+// compare emits an element-wise comparison of two floating-point arrays of the same shape.
+func (b mathBuilder) compare(direction string, x, y *Node) (*Node, error) {
+	sh := &shape.Shape{DType: dtype.Bool, AxisLengths: x.typ.shape.AxisLengths}
+	attrs := fmt.Sprintf("comparison_direction = #stablehlo<comparison_direction %s>, compare_type = #stablehlo<comparison_type FLOAT>", direction)
+	return b.g.emit("stablehlo.compare", []*Node{x, y}, attrs, valueType{shape: sh})
+}
+
+// predicate returns the node of the argument of IsInf or IsNaN.
+func (b mathBuilder) predicate(op ops.OpID, x ops.Node) (*Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := intercept.MathPredicateShape(op, xNode.typ.shape); err != nil {
+		return nil, err
+	}
+	return xNode, nil
+}
+
+func (b mathBuilder) Atan2(y, x ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpAtan2, "stablehlo.atan2", y, x)
+}
+
+func (b mathBuilder) Ceil(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.ceil", x)
+}
+
+func (b mathBuilder) Complex(re, im ops.Node) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{re, im})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ComplexShape(operands[0].typ.shape, operands[1].typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	core := coreBuilder{g: b.g}
+	for i, operand := range operands {
+		if operands[i], err = core.broadcastScalar(operand, sh.AxisLengths); err != nil {
+			return nil, err
+		}
+	}
+	return wrap(b.g.emit("stablehlo.complex", operands, "", valueType{shape: sh}))
+}
+
+// complexPart emits stablehlo.real or stablehlo.imag.
+func (b mathBuilder) complexPart(op ops.OpID, name string, x ops.Node) (*Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ComplexPartShape(op, xNode.typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.emit(name, []*Node{xNode}, "", valueType{shape: sh})
+}
+
+// Conj builds a complex number from the real part of x and the negated imaginary part of x,
+// StableHLO having no conjugate operation.
+func (b mathBuilder) Conj(x ops.Node) (ops.Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := intercept.ComplexPartShape(ops.OpConj, xNode.typ.shape); err != nil {
+		return nil, err
+	}
+	re, err := b.complexPart(ops.OpReal, "stablehlo.real", xNode)
+	if err != nil {
+		return nil, err
+	}
+	im, err := b.complexPart(ops.OpImag, "stablehlo.imag", xNode)
+	if err != nil {
+		return nil, err
+	}
+	negIm, err := b.g.emit("stablehlo.negate", []*Node{im}, "", im.typ)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.g.emit("stablehlo.complex", []*Node{re, negIm}, "", xNode.typ))
+}
+
+func (b mathBuilder) Cos(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.cosine", x)
+}
+
+func (b mathBuilder) Erf(x ops.Node) (ops.Node, error) {
+	return b.unary("chlo.erf", x)
+}
+
+func (b mathBuilder) Exp(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.exponential", x)
+}
+
+func (b mathBuilder) Expm1(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.exponential_minus_one", x)
+}
+
+func (b mathBuilder) Floor(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.floor", x)
+}
+
+func (b mathBuilder) Imag(x ops.Node) (ops.Node, error) {
+	return wrap(b.complexPart(ops.OpImag, "stablehlo.imag", x))
+}
+
+// IsInf compares the absolute value of x with positive infinity.
+func (b mathBuilder) IsInf(x ops.Node) (ops.Node, error) {
+	xNode, err := b.predicate(ops.OpIsInf, x)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := b.g.emit("stablehlo.abs", []*Node{xNode}, "", xNode.typ)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := tensorType(b.g.plat, xNode.typ.shape)
+	if err != nil {
+		return nil, err
+	}
+	inf := infinities[platform.Resolve(b.g.plat, xNode.typ.shape.DType)][1]
+	posInf, err := b.g.emit("stablehlo.constant", nil, "value = dense<"+inf+"> : "+typ, xNode.typ)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.compare("EQ", abs, posInf))
+}
+
+// IsNaN compares x with itself: only NaNs are not equal to themselves.
+func (b mathBuilder) IsNaN(x ops.Node) (ops.Node, error) {
+	xNode, err := b.predicate(ops.OpIsNaN, x)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.compare("NE", xNode, xNode))
+}
+
+func (b mathBuilder) Log(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.log", x)
+}
+
+func (b mathBuilder) Log1p(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.log_plus_one", x)
+}
+
+func (b mathBuilder) Logistic(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.logistic", x)
+}
+
+func (b mathBuilder) Pow(x, y ops.Node) (ops.Node, error) {
+	return b.binary(ops.OpPow, "stablehlo.power", x, y)
+}
+
+func (b mathBuilder) Real(x ops.Node) (ops.Node, error) {
+	return wrap(b.complexPart(ops.OpReal, "stablehlo.real", x))
+}
+
+func (b mathBuilder) Round(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.round_nearest_afz", x)
+}
+
+func (b mathBuilder) Rsqrt(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.rsqrt", x)
+}
+
+func (b mathBuilder) Sign(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.sign", x)
+}
+
+func (b mathBuilder) Sin(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.sine", x)
+}
+
+func (b mathBuilder) Sqrt(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.sqrt", x)
+}
+
+func (b mathBuilder) Tanh(x ops.Node) (ops.Node, error) {
+	return b.unary("stablehlo.tanh", x)
+}
@@ -0,0 +1,420 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stablehlo builds graphs as StableHLO modules in the MLIR textual format.
+//
+// The graph implements ops.Graph. Compiling the graph hands the module to a
+// Compiler provided by the caller, which returns the runner. Import replays a
+// module in the MLIR textual format onto any ops.Graph. Decoding the MLIR bytecode
+// format is not implemented.
+package stablehlo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+type (
+	// Module is a StableHLO module ready to be compiled.
+	Module struct {
+		// Name of the module.
+		Name string
+		// Text of the module in the MLIR textual format.
+		Text string
+		// Params are the shapes of the arguments of the main function.
+		Params []*shape.Shape
+		// Outputs are the shapes of the first results of the main function.
+		Outputs []*shape.Shape
+		// Traced are the shapes of the results following the outputs.
+		Traced []*shape.Shape
+	}
+
+	// Compiler compiles StableHLO modules for a device.
+	Compiler interface {
+		// Compile a module for a device.
+		// The runner returns as many outputs and traces as the module.
+		Compile(dev platform.Device, mod *Module) (ops.Runner, error)
+	}
+)
+
+// Node is a value in a StableHLO function.
+type Node struct {
+	graph *Graph
+	name  string
+	typ   valueType
+}
+
+var _ ops.Node = (*Node)(nil)
+
+// Graph returns the graph owning the node.
+func (n *Node) Graph() ops.Graph {
+	return n.graph
+}
+
+// Shape returns the shape of the node or nil if the node is a tuple.
+func (n *Node) Shape() *shape.Shape {
+	return n.typ.shape
+}
+
+// String returns the SSA name of the node.
+func (n *Node) String() string {
+	return n.name
+}
+
+// Tuple is a tuple value in a StableHLO function.
+type Tuple struct {
+	*Node
+}
+
+var _ ops.Tuple = (*Tuple)(nil)
+
+// Element returns a node representing the ith element of the tuple.
+func (t *Tuple) Element(i int) (ops.Node, error) {
+	if i < 0 || i >= t.Size() {
+		return nil, fmt.Errorf("tuple element %d out of range [0, %d)", i, t.Size())
+	}
+	return wrap(t.graph.emit("stablehlo.get_tuple_element", []*Node{t.Node}, fmt.Sprintf("index = %d : i32", i), t.typ.elems[i]))
+}
+
+// Size returns the number of elements in the tuple.
+func (t *Tuple) Size() int {
+	return len(t.typ.elems)
+}
+
+// Unpack returns the elements of the tuple.
+func (t *Tuple) Unpack() ([]ops.Node, error) {
+	nodes := make([]ops.Node, t.Size())
+	for i := range nodes {
+		var err error
+		if nodes[i], err = t.Element(i); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// module is shared by a root graph and all its subgraphs.
+type module struct {
+	compiler Compiler
+	symbols  map[string]bool
+	// funcs are all the subgraphs, in creation order.
+	funcs []*Graph
+}
+
+// Graph is a StableHLO function. The root graph becomes the main function of the module.
+type Graph struct {
+	mod    *module
+	plat   platform.Platform
+	name   string
+	symbol string
+	parent *Graph
+	// args are the shapes of the arguments of a subgraph.
+	args   []*shape.Shape
+	params map[int]*Node
+	body   []string
+	next   int
+	result *Node
+}
+
+var _ ops.Graph = (*Graph)(nil)
+
+// New returns a root graph compiling its module with a compiler.
+func New(plat platform.Platform, name string, compiler Compiler) *Graph {
+	mod := &module{compiler: compiler, symbols: map[string]bool{"main": true}}
+	return &Graph{mod: mod, plat: plat, name: name, symbol: "main", params: make(map[int]*Node)}
+}
+
+// Name of the graph.
+func (g *Graph) Name() string {
+	return g.name
+}
+
+// Platform used by the graph.
+func (g *Graph) Platform() platform.Platform {
+	return g.plat
+}
+
+// Core returns the builder for core operations.
+func (g *Graph) Core() ops.CoreBuilder {
+	return coreBuilder{g: g}
+}
+
+// Num returns the builder for functions of the num package.
+func (g *Graph) Num() ops.NumBuilder {
+	return numBuilder{g: g}
+}
+
+// Math returns the builder for functions of the math package.
+func (g *Graph) Math() ops.MathBuilder {
+	return mathBuilder{g: g}
+}
+
+// DType returns the builder for functions of the dtype package.
+func (g *Graph) DType() ops.DTypeBuilder {
+	return dtypeBuilder{g: g}
+}
+
+// NN returns the builder for neural network operations.
+func (g *Graph) NN() ops.NNBuilder {
+	return nnBuilder{g: g}
+}
+
+// Rand returns the builder for random number generation.
+func (g *Graph) Rand() ops.RandBuilder {
+	return randBuilder{g: g}
+}
+
+// Compile the module of the graph for a given device.
+func (g *Graph) Compile(dev platform.Device, output, traced []*ops.OutputNode, params []*shape.Shape) (ops.Runner, error) {
+	mod, err := g.Module(output, traced, params)
+	if err != nil {
+		return nil, err
+	}
+	return g.mod.compiler.Compile(dev, mod)
+}
+
+// Module returns the StableHLO module computing the outputs and the traced values of the graph.
+func (g *Graph) Module(output, traced []*ops.OutputNode, params []*shape.Shape) (*Module, error) {
+	if g.parent != nil {
+		return nil, fmt.Errorf("cannot compile subgraph %s", g.name)
+	}
+	if err := ops.CheckStatic(params...); err != nil {
+		return nil, fmt.Errorf("cannot compile graph %s: %w", g.name, err)
+	}
+	for index := range g.params {
+		if index >= len(params) {
+			return nil, fmt.Errorf("argument %d not in the %d parameters of graph %s", index, len(params), g.name)
+		}
+	}
+	var results []*Node
+	for _, out := range append(append([]*ops.OutputNode{}, output...), traced...) {
+		node, err := g.node(out.Node)
+		if err != nil {
+			return nil, err
+		}
+		if node.typ.isTuple() {
+			return nil, fmt.Errorf("cannot return tuple %s from graph %s", node, g.name)
+		}
+		results = append(results, node)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "module @%s {\n", symbolName(g.name))
+	for _, sub := range g.mod.funcs {
+		if sub.result == nil {
+			// The subgraph has never been called.
+			continue
+		}
+		if err := sub.writeFunc(&b, "private", sub.args, []*Node{sub.result}); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.writeFunc(&b, "public", params, results); err != nil {
+		return nil, err
+	}
+	b.WriteString("}\n")
+	return &Module{
+		Name:    g.name,
+		Text:    b.String(),
+		Params:  params,
+		Outputs: outputShapes(results[:len(output)]),
+		Traced:  outputShapes(results[len(output):]),
+	}, nil
+}
+
+func outputShapes(nodes []*Node) []*shape.Shape {
+	shapes := make([]*shape.Shape, len(nodes))
+	for i, n := range nodes {
+		shapes[i] = n.typ.shape
+	}
+	return shapes
+}
+
+// writeFunc writes the function of the graph.
+func (g *Graph) writeFunc(b *strings.Builder, visibility string, args []*shape.Shape, results []*Node) error {
+	argTypes := make([]string, len(args))
+	for i, arg := range args {
+		typ, err := tensorType(g.plat, arg)
+		if err != nil {
+			return err
+		}
+		argTypes[i] = fmt.Sprintf("%%arg%d: %s", i, typ)
+	}
+	resultNames := make([]string, len(results))
+	resultTypes := make([]string, len(results))
+	for i, res := range results {
+		typ, err := mlirType(g.plat, res.typ)
+		if err != nil {
+			return err
+		}
+		resultNames[i] = res.name
+		resultTypes[i] = typ
+	}
+	fmt.Fprintf(b, "  func.func %s @%s(%s) -> (%s) {\n", visibility, g.symbol, strings.Join(argTypes, ", "), strings.Join(resultTypes, ", "))
+	for _, line := range g.body {
+		b.WriteString("    ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "    \"func.return\"(%s) : (%s) -> ()\n", strings.Join(resultNames, ", "), strings.Join(resultTypes, ", "))
+	b.WriteString("  }\n")
+	return nil
+}
+
+// newSubgraph returns a graph for a private function of the module.
+func (g *Graph) newSubgraph(name string, args []*shape.Shape) *Graph {
+	base := symbolName(name)
+	symbol := base
+	for i := 1; g.mod.symbols[symbol]; i++ {
+		symbol = base + "_" + strconv.Itoa(i)
+	}
+	g.mod.symbols[symbol] = true
+	sub := &Graph{
+		mod:    g.mod,
+		plat:   g.plat,
+		name:   name,
+		symbol: symbol,
+		parent: g,
+		args:   args,
+		params: make(map[int]*Node),
+	}
+	g.mod.funcs = append(g.mod.funcs, sub)
+	return sub
+}
+
+// setResult sets the value returned by the function of a subgraph.
+func (g *Graph) setResult(out ops.OutputNode) (*Node, error) {
+	result, err := g.node(out.Node)
+	if err != nil {
+		return nil, err
+	}
+	if g.result != nil && g.result != result {
+		return nil, fmt.Errorf("subgraph %s called with different results", g.name)
+	}
+	g.result = result
+	return result, nil
+}
+
+// node returns the StableHLO node of a node of the graph.
+func (g *Graph) node(n ops.Node) (*Node, error) {
+	var node *Node
+	switch nT := n.(type) {
+	case *Node:
+		node = nT
+	case *Tuple:
+		node = nT.Node
+	default:
+		return nil, fmt.Errorf("node %T has not been created by a StableHLO graph", n)
+	}
+	if node.graph != g {
+		return nil, fmt.Errorf("node %s of graph %s used in graph %s", node, node.graph.name, g.name)
+	}
+	return node, nil
+}
+
+func (g *Graph) nodes(ns []ops.Node) ([]*Node, error) {
+	res := make([]*Node, len(ns))
+	for i, n := range ns {
+		var err error
+		if res[i], err = g.node(n); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// newValue returns a new SSA name.
+func (g *Graph) newValue() string {
+	name := "%" + strconv.Itoa(g.next)
+	g.next++
+	return name
+}
+
+// signature returns the MLIR type of an operation given its operands and results.
+func (g *Graph) signature(operands []*Node, results ...valueType) (string, error) {
+	operandTypes := make([]string, len(operands))
+	for i, op := range operands {
+		var err error
+		if operandTypes[i], err = mlirType(g.plat, op.typ); err != nil {
+			return "", err
+		}
+	}
+	resultTypes := make([]string, len(results))
+	for i, res := range results {
+		var err error
+		if resultTypes[i], err = mlirType(g.plat, res); err != nil {
+			return "", err
+		}
+	}
+	sig := "(" + strings.Join(operandTypes, ", ") + ") -> "
+	if len(resultTypes) == 1 {
+		return sig + resultTypes[0], nil
+	}
+	return sig + "(" + strings.Join(resultTypes, ", ") + ")", nil
+}
+
+// emit appends an operation in the generic MLIR form to the function and returns its result.
+func (g *Graph) emit(op string, operands []*Node, attrs string, typ valueType) (*Node, error) {
+	sig, err := g.signature(operands, typ)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(operands))
+	for i, operand := range operands {
+		names[i] = operand.name
+	}
+	if attrs != "" {
+		attrs = " {" + attrs + "}"
+	}
+	node := &Node{graph: g, name: g.newValue(), typ: typ}
+	g.body = append(g.body, fmt.Sprintf("%s = \"%s\"(%s)%s : %s", node.name, op, strings.Join(names, ", "), attrs, sig))
+	return node, nil
+}
+
+// emitResults appends an operation returning several values to the function and returns its results.
+func (g *Graph) emitResults(op string, operands []*Node, attrs string, types []valueType) ([]*Node, error) {
+	sig, err := g.signature(operands, types...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(operands))
+	for i, operand := range operands {
+		names[i] = operand.name
+	}
+	if attrs != "" {
+		attrs = " {" + attrs + "}"
+	}
+	name := g.newValue()
+	g.body = append(g.body, fmt.Sprintf("%s:%d = \"%s\"(%s)%s : %s", name, len(types), op, strings.Join(names, ", "), attrs, sig))
+	results := make([]*Node, len(types))
+	for i, typ := range types {
+		results[i] = &Node{graph: g, name: fmt.Sprintf("%s#%d", name, i), typ: typ}
+	}
+	return results, nil
+}
+
+// wrap returns a node as an ops.Node, or as an ops.Tuple if the node is a tuple.
+func wrap(n *Node, err error) (ops.Node, error) {
+	if err != nil {
+		return nil, err
+	}
+	if n.typ.isTuple() {
+		return &Tuple{Node: n}, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,1218 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// bytecodeMagic starts MLIR modules in the bytecode format.
+const bytecodeMagic = "ML\xefR"
+
+// Import replays the main function of a StableHLO module onto a graph and returns its results.
+//
+// The module must be in the MLIR textual format with operations in the generic form, as
+// emitted by this package or by mlir-opt --mlir-print-op-generic. Modules in the MLIR
+// bytecode format are not decoded: they must first be converted to the textual format
+// with mlir-opt.
+func Import(g ops.Graph, module []byte) ([]*ops.OutputNode, error) {
+	if bytes.HasPrefix(module, []byte(bytecodeMagic)) {
+		return nil, fmt.Errorf("MLIR bytecode not supported: convert the module to the textual format with mlir-opt --mlir-print-op-generic")
+	}
+	p := &parser{src: string(module)}
+	funcs, err := p.module()
+	if err != nil {
+		return nil, err
+	}
+	main, ok := funcs["main"]
+	if !ok {
+		return nil, fmt.Errorf("module has no main function")
+	}
+	imp := &importer{funcs: funcs}
+	ret, types, err := imp.newScope(g).function(main)
+	if err != nil {
+		return nil, err
+	}
+	outs := make([]*ops.OutputNode, len(ret))
+	for i, node := range ret {
+		if types[i].isTuple() {
+			return nil, fmt.Errorf("main function returns a tuple")
+		}
+		outs[i] = &ops.OutputNode{Node: node, Shape: types[i].shape}
+	}
+	return outs, nil
+}
+
+type importer struct {
+	funcs map[string]*funcDecl
+}
+
+// scope replays operations in a graph.
+type scope struct {
+	imp    *importer
+	g      ops.Graph
+	values map[string]ops.Node
+	// zeros are the constants equal to zero.
+	zeros map[string]bool
+	// literals are the literals of the atomic constants.
+	literals map[string]string
+	// iotas are the axes of the iota values.
+	iotas map[string]int
+	subs  map[string]*ops.Subgraph
+	// numRegions is the number of regions imported in the graph.
+	numRegions int
+}
+
+func (imp *importer) newScope(g ops.Graph) *scope {
+	return &scope{
+		imp:      imp,
+		g:        g,
+		values:   make(map[string]ops.Node),
+		zeros:    make(map[string]bool),
+		literals: make(map[string]string),
+		iotas:    make(map[string]int),
+		subs:     make(map[string]*ops.Subgraph),
+	}
+}
+
+// function replays a function with its arguments and returns its results.
+func (s *scope) function(fn *funcDecl) ([]ops.Node, []valueType, error) {
+	if err := s.arguments(fn.args); err != nil {
+		return nil, nil, fmt.Errorf("function @%s: %v", fn.symbol, err)
+	}
+	ret, types, err := s.run(fn.ops)
+	if err != nil {
+		return nil, nil, fmt.Errorf("function @%s: %v", fn.symbol, err)
+	}
+	return ret, types, nil
+}
+
+func (s *scope) arguments(args []blockArg) error {
+	for i, arg := range args {
+		sh, err := parseTensorType(arg.typ)
+		if err != nil {
+			return err
+		}
+		node, err := s.g.Core().Argument(strings.TrimPrefix(arg.name, "%"), sh, i)
+		if err != nil {
+			return err
+		}
+		s.values[arg.name] = node
+	}
+	return nil
+}
+
+func argShapes(args []blockArg) ([]*shape.Shape, error) {
+	shapes := make([]*shape.Shape, len(args))
+	for i, arg := range args {
+		var err error
+		if shapes[i], err = parseTensorType(arg.typ); err != nil {
+			return nil, err
+		}
+	}
+	return shapes, nil
+}
+
+// subgraph returns the subgraph of a function, importing it on first use.
+func (s *scope) subgraph(symbol string) (*ops.Subgraph, error) {
+	if sg, ok := s.subs[symbol]; ok {
+		return sg, nil
+	}
+	fn, ok := s.imp.funcs[symbol]
+	if !ok {
+		return nil, fmt.Errorf("undefined function @%s", symbol)
+	}
+	shapes, err := argShapes(fn.args)
+	if err != nil {
+		return nil, err
+	}
+	sg, err := s.newSubgraph(symbol, shapes, func(sub *scope) ([]ops.Node, []valueType, error) {
+		return sub.function(fn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.subs[symbol] = sg
+	return sg, nil
+}
+
+// newSubgraph creates a subgraph and replays operations in it with a function returning a single value.
+func (s *scope) newSubgraph(name string, args []*shape.Shape, replay func(*scope) ([]ops.Node, []valueType, error)) (*ops.Subgraph, error) {
+	g, err := s.g.Core().Subgraph(name, args)
+	if err != nil {
+		return nil, err
+	}
+	ret, types, err := replay(s.imp.newScope(g))
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) != 1 {
+		// Multiple values are returned as a tuple.
+		tuple, err := g.Core().Tuple(ret)
+		if err != nil {
+			return nil, err
+		}
+		return &ops.Subgraph{Graph: g, Result: ops.OutputNode{Node: tuple}}, nil
+	}
+	return &ops.Subgraph{Graph: g, Result: ops.OutputNode{Node: ret[0], Shape: types[0].shape}}, nil
+}
+
+// run replays operations until a return operation and returns the returned values.
+func (s *scope) run(operations []*operation) ([]ops.Node, []valueType, error) {
+	for _, op := range operations {
+		operands := make([]ops.Node, len(op.operands))
+		for i, name := range op.operands {
+			var ok bool
+			if operands[i], ok = s.values[name]; !ok {
+				return nil, nil, fmt.Errorf("undefined value %s", name)
+			}
+		}
+		types := make([]valueType, len(op.types))
+		for i, typ := range op.types {
+			var err error
+			if types[i], err = parseType(typ); err != nil {
+				return nil, nil, err
+			}
+		}
+		if op.name == "func.return" || op.name == "stablehlo.return" {
+			return operands, operandTypes(op, s), nil
+		}
+		results, err := s.apply(op, operands, types)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", op.name, err)
+		}
+		if len(results) != op.numResults {
+			return nil, nil, fmt.Errorf("%s: got %d results but want %d", op.name, len(results), op.numResults)
+		}
+		if len(results) == 1 {
+			s.values[op.result] = results[0]
+			continue
+		}
+		for i, res := range results {
+			s.values[fmt.Sprintf("%s#%d", op.result, i)] = res
+		}
+	}
+	return nil, nil, fmt.Errorf("missing return operation")
+}
+
+// operandTypes returns the types of the operands of a return operation.
+func operandTypes(op *operation, s *scope) []valueType {
+	types := make([]valueType, len(op.operands))
+	for i, name := range op.operands {
+		if n, ok := s.values[name].(interface{ Shape() *shape.Shape }); ok {
+			types[i] = valueType{shape: n.Shape()}
+		}
+	}
+	return types
+}
+
+var importedBinaryOps = map[string]ops.BinaryOperator{
+	"stablehlo.add":                    ops.Add,
+	"stablehlo.subtract":               ops.Sub,
+	"stablehlo.multiply":               ops.Mul,
+	"stablehlo.divide":                 ops.Div,
+	"stablehlo.remainder":              ops.Rem,
+	"stablehlo.power":                  ops.Pow,
+	"stablehlo.and":                    ops.BitAnd,
+	"stablehlo.or":                     ops.BitOr,
+	"stablehlo.xor":                    ops.BitXor,
+	"stablehlo.shift_left":             ops.Shl,
+	"stablehlo.shift_right_logical":    ops.Shr,
+	"stablehlo.shift_right_arithmetic": ops.Shr,
+}
+
+var importedComparisons = map[string]ops.BinaryOperator{
+	"EQ": ops.Equal,
+	"NE": ops.NotEqual,
+	"LT": ops.Less,
+	"LE": ops.LessEqual,
+	"GT": ops.Greater,
+	"GE": ops.GreaterEqual,
+}
+
+var importedMathOps = map[string]func(ops.MathBuilder, ops.Node) (ops.Node, error){
+	"stablehlo.abs":                   ops.MathBuilder.Abs,
+	"stablehlo.ceil":                  ops.MathBuilder.Ceil,
+	"stablehlo.cosine":                ops.MathBuilder.Cos,
+	"chlo.erf":                        ops.MathBuilder.Erf,
+	"stablehlo.exponential":           ops.MathBuilder.Exp,
+	"stablehlo.exponential_minus_one": ops.MathBuilder.Expm1,
+	"stablehlo.floor":                 ops.MathBuilder.Floor,
+	"stablehlo.imag":                  ops.MathBuilder.Imag,
+	"stablehlo.log":                   ops.MathBuilder.Log,
+	"stablehlo.log_plus_one":          ops.MathBuilder.Log1p,
+	"stablehlo.logistic":              ops.MathBuilder.Logistic,
+	"stablehlo.real":                  ops.MathBuilder.Real,
+	"stablehlo.round_nearest_afz":     ops.MathBuilder.Round,
+	"stablehlo.rsqrt":                 ops.MathBuilder.Rsqrt,
+	"stablehlo.sign":                  ops.MathBuilder.Sign,
+	"stablehlo.sine":                  ops.MathBuilder.Sin,
+	"stablehlo.sqrt":                  ops.MathBuilder.Sqrt,
+	"stablehlo.tanh":                  ops.MathBuilder.Tanh,
+}
+
+var importedRngAlgorithms = map[string]ops.RngAlgorithm{
+	"DEFAULT":   ops.DefaultRng,
+	"THREE_FRY": ops.ThreeFryRng,
+	"PHILOX":    ops.PhiloxRng,
+}
+
+var importedPrecisions = map[string]ops.Precision{
+	"DEFAULT": ops.DefaultPrecision,
+	"HIGH":    ops.TF32Precision,
+	"HIGHEST": ops.HighestPrecision,
+}
+
+func one(n ops.Node, err error) ([]ops.Node, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []ops.Node{n}, nil
+}
+
+// apply replays an operation and returns its results.
+func (s *scope) apply(op *operation, operands []ops.Node, types []valueType) ([]ops.Node, error) {
+	core := s.g.Core()
+	var result *shape.Shape
+	if len(types) == 1 {
+		result = types[0].shape
+	}
+	if build, ok := importedMathOps[op.name]; ok {
+		return one(build(s.g.Math(), operands[0]))
+	}
+	switch op.name {
+	case "stablehlo.atan2":
+		return one(s.g.Math().Atan2(operands[0], operands[1]))
+	case "stablehlo.complex":
+		return one(s.g.Math().Complex(operands[0], operands[1]))
+	}
+	if binOp, ok := importedBinaryOps[op.name]; ok {
+		if result != nil && result.DType == dtype.Bool {
+			switch binOp {
+			case ops.BitAnd:
+				binOp = ops.LogicalAnd
+			case ops.BitOr:
+				binOp = ops.LogicalOr
+			}
+		}
+		return one(core.BinaryOp(binOp, operands[0], operands[1]))
+	}
+	switch op.name {
+	case "stablehlo.constant":
+		literal, _, _ := strings.Cut(op.attrs["value"], " : ")
+		data, err := decodeDense(literal, result)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := platform.Borrow(data, result)
+		if err != nil {
+			return nil, err
+		}
+		if result.IsAtomic() {
+			s.literals[op.result] = literal
+			s.zeros[op.result] = !slices.ContainsFunc(data, func(b byte) bool { return b != 0 })
+		}
+		return one(core.Constant(buf))
+	case "stablehlo.tuple":
+		return one(core.Tuple(operands))
+	case "stablehlo.get_tuple_element":
+		tuple, ok := operands[0].(ops.Tuple)
+		if !ok {
+			return nil, fmt.Errorf("operand %s is not a tuple", op.operands[0])
+		}
+		index, err := intAttr(op, "index")
+		if err != nil {
+			return nil, err
+		}
+		return one(tuple.Element(index))
+	case "func.call":
+		sg, err := s.subgraph(strings.TrimPrefix(op.attrs["callee"], "@"))
+		if err != nil {
+			return nil, err
+		}
+		return one(core.Call(sg, operands...))
+	case "stablehlo.negate":
+		return one(core.UnaryOp(ops.Neg, operands[0]))
+	case "stablehlo.not":
+		unOp := ops.BitNot
+		if result.DType == dtype.Bool {
+			unOp = ops.Not
+		}
+		return one(core.UnaryOp(unOp, operands[0]))
+	case "stablehlo.compare":
+		direction := strings.TrimSuffix(strings.TrimPrefix(op.attrs["comparison_direction"], "#stablehlo<comparison_direction "), ">")
+		cmp, ok := importedComparisons[direction]
+		if !ok {
+			return nil, fmt.Errorf("comparison direction %q not supported", direction)
+		}
+		return one(core.BinaryOp(cmp, operands[0], operands[1]))
+	case "stablehlo.broadcast_in_dim":
+		axes, err := parseI64Array(op.attrs["broadcast_dimensions"])
+		if err != nil {
+			return nil, err
+		}
+		return one(core.BroadcastInDim(operands[0], result, axes))
+	case "stablehlo.reshape":
+		return one(core.Reshape(operands[0], result.AxisLengths))
+	case "stablehlo.concatenate":
+		axis, err := intAttr(op, "dimension")
+		if err != nil {
+			return nil, err
+		}
+		return one(core.Concat(axis, operands))
+	case "stablehlo.convert":
+		return one(core.Cast(operands[0], result.DType))
+	case "stablehlo.slice":
+		return s.slice(op, operands[0], result)
+	case "stablehlo.dynamic_update_slice":
+		return s.dynamicUpdateSlice(op, operands, result)
+	case "stablehlo.dot_general":
+		return s.dotGeneral(op, operands)
+	case "stablehlo.while":
+		return s.while(op, operands)
+	case "stablehlo.if":
+		return s.cond(op, operands)
+	case "stablehlo.select":
+		return one(core.Select(operands[0], operands[1], operands[2]))
+	case "stablehlo.reduce":
+		return s.reduce(op, operands)
+	case "stablehlo.rng_bit_generator":
+		return s.rngBitGenerator(op, operands, types)
+	case "stablehlo.sort":
+		return s.sort(op, operands)
+	case "chlo.top_k":
+		k, err := intAttr(op, "k")
+		if err != nil {
+			return nil, err
+		}
+		tuple, err := core.TopK(operands[0], k)
+		if err != nil {
+			return nil, err
+		}
+		return tuple.Unpack()
+	case "stablehlo.reduce_window":
+		return s.reduceWindow(op, operands)
+	case "stablehlo.convolution":
+		return s.convolution(op, operands)
+	case "stablehlo.transpose":
+		permutation, err := parseI64Array(op.attrs["permutation"])
+		if err != nil {
+			return nil, err
+		}
+		return one(core.Transpose(operands[0], permutation))
+	case "stablehlo.reverse":
+		axes, err := parseI64Array(op.attrs["dimensions"])
+		if err != nil {
+			return nil, err
+		}
+		return one(core.Reverse(operands[0], axes))
+	case "stablehlo.pad":
+		return s.pad(op, operands)
+	case "stablehlo.gather":
+		return s.gather(op, operands)
+	case "stablehlo.scatter":
+		return s.scatter(op, operands)
+	case "stablehlo.uniform_quantize":
+		return one(core.Quantize(operands[0], result.Quant))
+	case "stablehlo.uniform_dequantize":
+		return one(core.Dequantize(operands[0]))
+	case "stablehlo.bitcast_convert":
+		return one(s.g.DType().Bitcast(operands[0], result.DType))
+	case "stablehlo.iota":
+		axis, err := intAttr(op, "iota_dimension")
+		if err != nil {
+			return nil, err
+		}
+		s.iotas[op.result] = axis
+		return one(s.g.Num().Iota(result, axis))
+	}
+	return nil, fmt.Errorf("operation not supported")
+}
+
+// slice replays a slice selecting a single element along the outermost axis.
+func (s *scope) slice(op *operation, x ops.Node, result *shape.Shape) ([]ops.Node, error) {
+	start, err := parseI64Array(op.attrs["start_indices"])
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseI64Array(op.attrs["limit_indices"])
+	if err != nil {
+		return nil, err
+	}
+	strides, err := parseI64Array(op.attrs["strides"])
+	if err != nil {
+		return nil, err
+	}
+	if len(start) == 0 || limit[0] != start[0]+1 || len(result.AxisLengths) != len(start) {
+		return nil, fmt.Errorf("only slices of a single element along the outermost axis are supported")
+	}
+	for i := 1; i < len(start); i++ {
+		if start[i] != 0 || limit[i] != result.AxisLengths[i] {
+			return nil, fmt.Errorf("only slices of a single element along the outermost axis are supported")
+		}
+	}
+	for _, stride := range strides {
+		if stride != 1 {
+			return nil, fmt.Errorf("strided slices not supported")
+		}
+	}
+	elem, err := s.g.Core().Slice(x, start[0])
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().Reshape(elem, result.AxisLengths))
+}
+
+// dynamicUpdateSlice replays an update of a single element along the outermost axis.
+func (s *scope) dynamicUpdateSlice(op *operation, operands []ops.Node, result *shape.Shape) ([]ops.Node, error) {
+	if len(operands) != 2+len(result.AxisLengths) {
+		return nil, fmt.Errorf("got %d operands for an array of rank %d", len(operands), len(result.AxisLengths))
+	}
+	for _, name := range op.operands[3:] {
+		if !s.zeros[name] {
+			return nil, fmt.Errorf("only updates at index 0 of the inner axes are supported")
+		}
+	}
+	updates, err := s.g.Core().Reshape(operands[1], result.AxisLengths[1:])
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().Set(operands[0], updates, operands[2]))
+}
+
+var dotFieldRE = regexp.MustCompile(`(\w+_dimensions) = \[([^\]]*)\]`)
+
+func (s *scope) dotGeneral(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	var batchAxes, reduceAxes [2][]int
+	fields := map[string]*[]int{
+		"lhs_batching_dimensions":    &batchAxes[0],
+		"rhs_batching_dimensions":    &batchAxes[1],
+		"lhs_contracting_dimensions": &reduceAxes[0],
+		"rhs_contracting_dimensions": &reduceAxes[1],
+	}
+	for _, match := range dotFieldRE.FindAllStringSubmatch(op.attrs["dot_dimension_numbers"], -1) {
+		dst, ok := fields[match[1]]
+		if !ok {
+			return nil, fmt.Errorf("dot dimension %s not supported", match[1])
+		}
+		var err error
+		if *dst, err = parseInts(match[2]); err != nil {
+			return nil, err
+		}
+	}
+	precision, err := parsePrecision(op)
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().DotGeneral(operands[0], operands[1], batchAxes, reduceAxes, precision))
+}
+
+// parsePrecision returns the precision of the first operand in the precision config of an operation.
+func parsePrecision(op *operation) (ops.Precision, error) {
+	config := op.attrs["precision_config"]
+	if config == "" {
+		return ops.DefaultPrecision, nil
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(config, "[#stablehlo<precision "), ">")
+	precision, ok := importedPrecisions[name]
+	if !ok {
+		return ops.DefaultPrecision, fmt.Errorf("precision %q not supported", name)
+	}
+	return precision, nil
+}
+
+func (s *scope) rngBitGenerator(op *operation, operands []ops.Node, types []valueType) ([]ops.Node, error) {
+	if len(types) != 2 || types[1].isTuple() {
+		return nil, fmt.Errorf("got %d results but want a state and an array", len(types))
+	}
+	algorithm := ops.DefaultRng
+	if attr := op.attrs["rng_algorithm"]; attr != "" {
+		name, _, _ := strings.Cut(strings.TrimPrefix(attr, "#stablehlo<rng_algorithm "), ">")
+		var ok bool
+		if algorithm, ok = importedRngAlgorithms[name]; !ok {
+			return nil, fmt.Errorf("random number generator algorithm %q not supported", name)
+		}
+	}
+	res, err := s.g.Rand().RngBitGenerator(algorithm, operands[0], types[1].shape)
+	if err != nil {
+		return nil, err
+	}
+	tuple, ok := res.(ops.Tuple)
+	if !ok {
+		return nil, fmt.Errorf("random number generator does not return a tuple")
+	}
+	return tuple.Unpack()
+}
+
+// sort replays a sort of an array, or of an array with the iota of its sorting axis
+// as emitted by ArgSort. The region must compare the elements of the first array.
+func (s *scope) sort(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	axis, err := intAttr(op, "dimension")
+	if err != nil {
+		return nil, err
+	}
+	if len(op.regions) != 1 {
+		return nil, fmt.Errorf("got %d regions but want 1", len(op.regions))
+	}
+	reg := op.regions[0]
+	if len(reg.ops) != 2 || len(reg.args) != 2*len(operands) {
+		return nil, fmt.Errorf("only comparisons of the elements of the first array are supported")
+	}
+	compare, ret := reg.ops[0], reg.ops[1]
+	if compare.name != "stablehlo.compare" || !slices.Equal(compare.operands, []string{reg.args[0].name, reg.args[1].name}) ||
+		ret.name != "stablehlo.return" || !slices.Equal(ret.operands, []string{compare.result}) {
+		return nil, fmt.Errorf("only comparisons of the elements of the first array are supported")
+	}
+	var descending bool
+	switch direction := compare.attrs["comparison_direction"]; direction {
+	case "#stablehlo<comparison_direction LT>":
+	case "#stablehlo<comparison_direction GT>":
+		descending = true
+	default:
+		return nil, fmt.Errorf("comparison %s not supported", direction)
+	}
+	sorted, err := s.g.Core().Sort(operands[0], axis, descending)
+	if err != nil {
+		return nil, err
+	}
+	if len(operands) == 1 {
+		return []ops.Node{sorted}, nil
+	}
+	iotaAxis, ok := s.iotas[op.operands[1]]
+	indicesShape, _ := operands[1].(interface{ Shape() *shape.Shape })
+	if len(operands) != 2 || !ok || iotaAxis != axis || indicesShape == nil || indicesShape.Shape().DType != dtype.Int32 {
+		return nil, fmt.Errorf("only sorts of an array with the indices of its elements are supported")
+	}
+	indices, err := s.g.Core().ArgSort(operands[0], axis, descending)
+	if err != nil {
+		return nil, err
+	}
+	return []ops.Node{sorted, indices}, nil
+}
+
+// while replays a while loop. The regions become subgraphs taking the loop values as arguments.
+func (s *scope) while(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if len(op.regions) != 2 {
+		return nil, fmt.Errorf("got %d regions but want 2", len(op.regions))
+	}
+	var state ops.Node
+	if len(operands) == 1 {
+		state = operands[0]
+	} else {
+		tuple, err := s.g.Core().Tuple(operands)
+		if err != nil {
+			return nil, err
+		}
+		state = tuple
+	}
+	s.numRegions++
+	name := fmt.Sprintf("while%d", s.numRegions)
+	var subgraphs [2]*ops.Subgraph
+	for i, suffix := range []string{"cond", "body"} {
+		reg := op.regions[i]
+		shapes, err := argShapes(reg.args)
+		if err != nil {
+			return nil, err
+		}
+		if subgraphs[i], err = s.newSubgraph(name+"."+suffix, shapes, func(sub *scope) ([]ops.Node, []valueType, error) {
+			if err := sub.arguments(reg.args); err != nil {
+				return nil, nil, err
+			}
+			return sub.run(reg.ops)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	loop, err := s.g.Core().While(subgraphs[0], subgraphs[1], state)
+	if err != nil {
+		return nil, err
+	}
+	if len(operands) == 1 {
+		return []ops.Node{loop}, nil
+	}
+	tuple, ok := loop.(ops.Tuple)
+	if !ok {
+		return nil, fmt.Errorf("while loop with %d values does not return a tuple", len(operands))
+	}
+	return tuple.Unpack()
+}
+
+var importedReductions = map[string]ops.OpID{
+	"stablehlo.add":      ops.OpReduceSum,
+	"stablehlo.multiply": ops.OpReduceProd,
+	"stablehlo.maximum":  ops.OpReduceMax,
+	"stablehlo.minimum":  ops.OpReduceMin,
+}
+
+var reductionBuilders = map[ops.OpID]func(ops.CoreBuilder, ops.Node, []int) (ops.Node, error){
+	ops.OpReduceSum:  ops.CoreBuilder.ReduceSum,
+	ops.OpReduceProd: ops.CoreBuilder.ReduceProd,
+	ops.OpReduceMax:  ops.CoreBuilder.ReduceMax,
+	ops.OpReduceMin:  ops.CoreBuilder.ReduceMin,
+}
+
+// reduction returns the reduction operation of a region applying a single operation
+// to its arguments from the initial value of that operation, as emitted by ReduceSum,
+// ReduceProd, ReduceMax or ReduceMin.
+func (s *scope) reduction(op *operation, init *shape.Shape) (ops.OpID, bool) {
+	reg := op.regions[0]
+	if len(reg.ops) != 2 || len(reg.args) != 2 {
+		return "", false
+	}
+	combine, ret := reg.ops[0], reg.ops[1]
+	id, ok := importedReductions[combine.name]
+	if !ok || !slices.Equal(combine.operands, []string{reg.args[0].name, reg.args[1].name}) {
+		return "", false
+	}
+	if ret.name != "stablehlo.return" || !slices.Equal(ret.operands, []string{combine.result}) {
+		return "", false
+	}
+	literal, err := reduceInit(id, init.DType)
+	if err != nil || s.literals[op.operands[1]] != literal {
+		return "", false
+	}
+	return id, true
+}
+
+// reduce replays a reduction. The region becomes a subgraph combining two values,
+// unless it is one of the reductions of the core builder.
+func (s *scope) reduce(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if len(op.regions) == 1 && len(operands) == 4 {
+		return s.argReduce(op, operands)
+	}
+	if len(op.regions) != 1 || len(operands) != 2 {
+		return nil, fmt.Errorf("only reductions of a single array are supported")
+	}
+	axes, err := parseI64Array(op.attrs["dimensions"])
+	if err != nil {
+		return nil, err
+	}
+	if init, ok := operands[1].(interface{ Shape() *shape.Shape }); ok {
+		if id, ok := s.reduction(op, init.Shape()); ok {
+			return one(reductionBuilders[id](s.g.Core(), operands[0], axes))
+		}
+	}
+	body, err := s.regionSubgraph("reduce", op.regions[0])
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().Reduce(body, operands[1], operands[0], axes))
+}
+
+// argReduce replays a reduction of an array together with the iota of the reduced axis,
+// as emitted by Argmax or Argmin. The direction of the first comparison of the region
+// selects the operation.
+func (s *scope) argReduce(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	axes, err := parseI64Array(op.attrs["dimensions"])
+	if err != nil {
+		return nil, err
+	}
+	if iotaAxis, ok := s.iotas[op.operands[1]]; len(axes) != 1 || !ok || iotaAxis != axes[0] {
+		return nil, fmt.Errorf("only reductions of an array with the indices of its elements are supported")
+	}
+	reg := op.regions[0]
+	var direction string
+	for _, regOp := range reg.ops {
+		if regOp.name == "stablehlo.compare" {
+			direction = regOp.attrs["comparison_direction"]
+			break
+		}
+	}
+	core, num := s.g.Core(), s.g.Num()
+	var values, indices ops.Node
+	switch direction {
+	case "#stablehlo<comparison_direction GT>":
+		if values, err = core.ReduceMax(operands[0], axes); err != nil {
+			return nil, err
+		}
+		indices, err = num.Argmax(operands[0], axes[0])
+	case "#stablehlo<comparison_direction LT>":
+		if values, err = core.ReduceMin(operands[0], axes); err != nil {
+			return nil, err
+		}
+		indices, err = num.Argmin(operands[0], axes[0])
+	default:
+		return nil, fmt.Errorf("comparison %q not supported in a reduction of indices", direction)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []ops.Node{values, indices}, nil
+}
+
+// regionSubgraph returns a subgraph replaying the body region of a reduction.
+func (s *scope) regionSubgraph(prefix string, reg *region) (*ops.Subgraph, error) {
+	shapes, err := argShapes(reg.args)
+	if err != nil {
+		return nil, err
+	}
+	s.numRegions++
+	return s.newSubgraph(fmt.Sprintf("%s%d.body", prefix, s.numRegions), shapes, func(sub *scope) ([]ops.Node, []valueType, error) {
+		if err := sub.arguments(reg.args); err != nil {
+			return nil, nil, err
+		}
+		return sub.run(reg.ops)
+	})
+}
+
+func (s *scope) pad(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	var padding [3][]int
+	for i, name := range []string{"edge_padding_low", "edge_padding_high", "interior_padding"} {
+		var err error
+		if padding[i], err = parseI64Array(op.attrs[name]); err != nil {
+			return nil, err
+		}
+	}
+	return one(s.g.Core().Pad(operands[0], operands[1], padding[0], padding[1], padding[2]))
+}
+
+var dimensionFieldRE = regexp.MustCompile(`(\w+) = (\[[^\]]*\]|\d+)`)
+
+// parseDimensionNumbers parses the fields of a dimension numbers attribute into lists of axes.
+// Missing fields are left empty.
+func parseDimensionNumbers(attr string, fields map[string]*[]int) error {
+	for _, match := range dimensionFieldRE.FindAllStringSubmatch(attr, -1) {
+		dst, ok := fields[match[1]]
+		if !ok {
+			return fmt.Errorf("dimension %s not supported", match[1])
+		}
+		var err error
+		if *dst, err = parseInts(strings.Trim(match[2], "[]")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexVectorAxis returns the single axis parsed for the index_vector_dim field.
+func indexVectorAxis(axes []int) (int, error) {
+	if len(axes) != 1 {
+		return 0, fmt.Errorf("missing index_vector_dim")
+	}
+	return axes[0], nil
+}
+
+func (s *scope) gather(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	var dims ops.GatherDims
+	var index []int
+	if err := parseDimensionNumbers(op.attrs["dimension_numbers"], map[string]*[]int{
+		"offset_dims":          &dims.OffsetAxes,
+		"collapsed_slice_dims": &dims.CollapsedAxes,
+		"start_index_map":      &dims.StartIndexMap,
+		"index_vector_dim":     &index,
+	}); err != nil {
+		return nil, err
+	}
+	var err error
+	if dims.IndexVectorAxis, err = indexVectorAxis(index); err != nil {
+		return nil, err
+	}
+	sliceSizes, err := parseI64Array(op.attrs["slice_sizes"])
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().Gather(operands[0], operands[1], dims, sliceSizes))
+}
+
+// scatter replays a scatter replacing the values of the operand with the updates.
+func (s *scope) scatter(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if len(operands) != 3 || len(op.regions) != 1 {
+		return nil, fmt.Errorf("only scatters of a single array are supported")
+	}
+	reg := op.regions[0]
+	if len(reg.args) != 2 || len(reg.ops) != 1 || reg.ops[0].name != "stablehlo.return" || !slices.Equal(reg.ops[0].operands, []string{reg.args[1].name}) {
+		return nil, fmt.Errorf("only scatters replacing values are supported")
+	}
+	var dims ops.ScatterDims
+	var index []int
+	if err := parseDimensionNumbers(op.attrs["scatter_dimension_numbers"], map[string]*[]int{
+		"update_window_dims":           &dims.UpdateWindowAxes,
+		"inserted_window_dims":         &dims.InsertedWindowAxes,
+		"scatter_dims_to_operand_dims": &dims.ScatterAxesToOperandAxes,
+		"index_vector_dim":             &index,
+	}); err != nil {
+		return nil, err
+	}
+	var err error
+	if dims.IndexVectorAxis, err = indexVectorAxis(index); err != nil {
+		return nil, err
+	}
+	return one(s.g.Core().Scatter(operands[0], operands[1], operands[2], dims))
+}
+
+// capturedValues returns the values used by operations of regions but defined outside of them.
+func capturedValues(regions []*region) []string {
+	defined := make(map[string]bool)
+	var captured []string
+	var visit func(reg *region)
+	visit = func(reg *region) {
+		for _, arg := range reg.args {
+			defined[arg.name] = true
+		}
+		for _, op := range reg.ops {
+			for _, name := range op.operands {
+				if !defined[name] && !slices.Contains(captured, name) {
+					captured = append(captured, name)
+				}
+			}
+			for _, sub := range op.regions {
+				visit(sub)
+			}
+			defined[op.result] = true
+			for i := range op.numResults {
+				defined[fmt.Sprintf("%s#%d", op.result, i)] = true
+			}
+		}
+	}
+	for _, reg := range regions {
+		visit(reg)
+	}
+	return captured
+}
+
+// cond replays a conditional. The regions use values of the enclosing function:
+// they become subgraphs taking these values as arguments.
+func (s *scope) cond(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if len(op.regions) != 2 {
+		return nil, fmt.Errorf("got %d regions but want 2", len(op.regions))
+	}
+	captured := capturedValues(op.regions)
+	args := make([]ops.Node, len(captured))
+	shapes := make([]*shape.Shape, len(captured))
+	for i, name := range captured {
+		node, ok := s.values[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined value %s", name)
+		}
+		n, ok := node.(interface{ Shape() *shape.Shape })
+		if !ok || n.Shape() == nil {
+			return nil, fmt.Errorf("cannot pass value %s of unknown shape to a branch", name)
+		}
+		args[i], shapes[i] = node, n.Shape()
+	}
+	s.numRegions++
+	name := fmt.Sprintf("if%d", s.numRegions)
+	var branches [2]*ops.Subgraph
+	for i, suffix := range []string{"true", "false"} {
+		reg := op.regions[i]
+		var err error
+		if branches[i], err = s.newSubgraph(name+"."+suffix, shapes, func(sub *scope) ([]ops.Node, []valueType, error) {
+			for j, value := range captured {
+				arg, err := sub.g.Core().Argument(strings.TrimPrefix(value, "%"), shapes[j], j)
+				if err != nil {
+					return nil, nil, err
+				}
+				sub.values[value] = arg
+			}
+			return sub.run(reg.ops)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	result, err := s.g.Core().Cond(operands[0], branches[0], branches[1], args...)
+	if err != nil {
+		return nil, err
+	}
+	if op.numResults == 1 {
+		return []ops.Node{result}, nil
+	}
+	tuple, ok := result.(ops.Tuple)
+	if !ok {
+		return nil, fmt.Errorf("conditional with %d results does not return a tuple", op.numResults)
+	}
+	return tuple.Unpack()
+}
+
+func intAttr(op *operation, name string) (int, error) {
+	val, _, _ := strings.Cut(op.attrs[name], ":")
+	i, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return 0, fmt.Errorf("invalid attribute %s = %q", name, op.attrs[name])
+	}
+	return i, nil
+}
+
+// parseI64Array parses an MLIR dense array attribute, for example array<i64: 0, 1>.
+func parseI64Array(s string) ([]int, error) {
+	if !strings.HasPrefix(s, "array<i64") || !strings.HasSuffix(s, ">") {
+		return nil, fmt.Errorf("invalid array attribute %q", s)
+	}
+	_, vals, _ := strings.Cut(strings.TrimSuffix(s, ">"), ":")
+	return parseInts(vals)
+}
+
+func parseInts(s string) ([]int, error) {
+	var ints []int
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		i, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+var importedElementTypes = map[string]dtype.DataType{
+	"i1":           dtype.Bool,
+	"i8":           dtype.Int8,
+	"i16":          dtype.Int16,
+	"i32":          dtype.Int32,
+	"i64":          dtype.Int64,
+	"ui8":          dtype.Uint8,
+	"ui16":         dtype.Uint16,
+	"ui32":         dtype.Uint32,
+	"ui64":         dtype.Uint64,
+	"bf16":         dtype.Bfloat16,
+	"f16":          dtype.Float16,
+	"f32":          dtype.Float32,
+	"f64":          dtype.Float64,
+	"complex<f32>": dtype.Complex64,
+	"complex<f64>": dtype.Complex128,
+	"i4":           dtype.Int4,
+	"ui4":          dtype.Uint4,
+}
+
+// splitTopLevel splits a string at the separator outside of brackets.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '<' || c == '{' || c == '[' || c == '(':
+			depth++
+		case c == '>' || c == '}' || c == ']' || c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// parseType parses a tensor or a tuple type.
+func parseType(s string) (valueType, error) {
+	if inner, ok := strings.CutPrefix(s, "tuple<"); ok {
+		inner = strings.TrimSuffix(inner, ">")
+		typ := valueType{elems: []valueType{}}
+		if inner == "" {
+			return typ, nil
+		}
+		for _, elem := range splitTopLevel(inner, ',') {
+			elemType, err := parseType(elem)
+			if err != nil {
+				return valueType{}, err
+			}
+			typ.elems = append(typ.elems, elemType)
+		}
+		return typ, nil
+	}
+	sh, err := parseTensorType(s)
+	return valueType{shape: sh}, err
+}
+
+// parseTensorType parses a tensor type, for example tensor<2x3xf32>.
+func parseTensorType(s string) (*shape.Shape, error) {
+	inner, ok := strings.CutPrefix(s, "tensor<")
+	if !ok || !strings.HasSuffix(inner, ">") {
+		return nil, fmt.Errorf("type %q not supported", s)
+	}
+	inner = strings.TrimSuffix(inner, ">")
+	axisLengths := []int{}
+	for {
+		i := 0
+		for i < len(inner) && inner[i] >= '0' && inner[i] <= '9' {
+			i++
+		}
+		if i == 0 || i >= len(inner) || inner[i] != 'x' {
+			break
+		}
+		length, err := strconv.Atoi(inner[:i])
+		if err != nil {
+			return nil, err
+		}
+		axisLengths = append(axisLengths, length)
+		inner = inner[i+1:]
+	}
+	if quant, ok := strings.CutPrefix(inner, "!quant.uniform<"); ok {
+		q, err := parseQuant(strings.TrimSuffix(quant, ">"))
+		if err != nil {
+			return nil, err
+		}
+		return &shape.Shape{DType: q.Storage, AxisLengths: axisLengths, Quant: q}, nil
+	}
+	dt, ok := importedElementTypes[inner]
+	if !ok {
+		return nil, fmt.Errorf("element type %q not supported", inner)
+	}
+	return &shape.Shape{DType: dt, AxisLengths: axisLengths}, nil
+}
+
+// parseQuant parses the parameters of a uniform quantized type, for example i8:f32, 0.5:1.
+func parseQuant(s string) (*dtype.Quantization, error) {
+	parts := splitTopLevel(s, ',')
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid quantized type %q", s)
+	}
+	types := strings.Split(parts[0], ":")
+	if len(types) < 2 {
+		return nil, fmt.Errorf("invalid quantized type %q", s)
+	}
+	storage := types[0]
+	if strings.HasPrefix(storage, "u") {
+		storage = "ui" + storage[1:]
+	}
+	q := &dtype.Quantization{Storage: importedElementTypes[storage], Expressed: importedElementTypes[types[1]]}
+	if len(types) > 2 {
+		var err error
+		if q.Axis, err = strconv.Atoi(types[2]); err != nil {
+			return nil, fmt.Errorf("invalid quantization axis in %q", s)
+		}
+	}
+	params := parts[1:]
+	if len(params) == 1 && strings.HasPrefix(params[0], "{") {
+		params = splitTopLevel(strings.Trim(params[0], "{}"), ',')
+	}
+	for _, param := range params {
+		scale, zeroPoint, _ := strings.Cut(param, ":")
+		sc, err := strconv.ParseFloat(scale, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scale in quantized type %q", s)
+		}
+		var zp int64
+		if zeroPoint != "" {
+			if zp, err = strconv.ParseInt(zeroPoint, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid zero point in quantized type %q", s)
+			}
+		}
+		q.Scales = append(q.Scales, sc)
+		q.ZeroPoints = append(q.ZeroPoints, zp)
+	}
+	if err := q.Check(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// decodeDense decodes a dense elements literal into the bytes of an array.
+func decodeDense(literal string, sh *shape.Shape) ([]byte, error) {
+	if sh == nil || sh.Quant != nil || dtype.IsSubByte(sh.DType) {
+		return nil, fmt.Errorf("constant of type %s not supported", sh)
+	}
+	inner, ok := strings.CutPrefix(literal, "dense<")
+	if !ok || !strings.HasSuffix(inner, ">") {
+		return nil, fmt.Errorf("literal %q not supported", literal)
+	}
+	inner = strings.TrimSuffix(inner, ">")
+	size, elemSize := sh.Size(), dtype.Sizeof(sh.DType)
+	if inner == "" {
+		if size != 0 {
+			return nil, fmt.Errorf("empty literal for shape %s", sh)
+		}
+		return []byte{}, nil
+	}
+	var data []byte
+	if hexData, ok := strings.CutPrefix(inner, "\"0x"); ok {
+		var err error
+		if data, err = hex.DecodeString(strings.TrimSuffix(hexData, "\"")); err != nil {
+			return nil, fmt.Errorf("invalid hexadecimal literal: %v", err)
+		}
+	} else {
+		fields := strings.FieldsFunc(inner, func(r rune) bool {
+			return r == '[' || r == ']' || r == ',' || r == ' ' || r == '\n'
+		})
+		for _, field := range fields {
+			var err error
+			if data, err = appendElement(data, sh.DType, field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(data) == elemSize && size > 1 {
+		// Splat: the single value fills the array.
+		data = bytes.Repeat(data, size)
+	}
+	if len(data) != size*elemSize {
+		return nil, fmt.Errorf("literal has %d bytes but shape %s requires %d bytes", len(data), sh, size*elemSize)
+	}
+	return data, nil
+}
+
+func appendElement(data []byte, dt dtype.DataType, s string) ([]byte, error) {
+	var err error
+	switch dt {
+	case dtype.Bool:
+		switch s {
+		case "true", "1":
+			return append(data, 1), nil
+		case "false", "0":
+			return append(data, 0), nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", s)
+	case dtype.Int8, dtype.Int16, dtype.Int32, dtype.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 0, 64); err != nil {
+			return nil, err
+		}
+		// Little-endian integers are truncated to their size.
+		return binary.LittleEndian.AppendUint64(data, uint64(i))[:len(data)+dtype.Sizeof(dt)], nil
+	case dtype.Uint8, dtype.Uint16, dtype.Uint32, dtype.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 0, 64); err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint64(data, u)[:len(data)+dtype.Sizeof(dt)], nil
+	case dtype.Bfloat16, dtype.Float16, dtype.Float32, dtype.Float64:
+		if bits, ok := strings.CutPrefix(s, "0x"); ok {
+			// Hexadecimal floating-point literals are the bits of the value.
+			var u uint64
+			if u, err = strconv.ParseUint(bits, 16, dtype.BitSizeof(dt)); err != nil {
+				return nil, err
+			}
+			return binary.LittleEndian.AppendUint64(data, u)[:len(data)+dtype.Sizeof(dt)], nil
+		}
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); err != nil {
+			return nil, err
+		}
+		switch dt {
+		case dtype.Bfloat16:
+			return binary.LittleEndian.AppendUint16(data, uint16(dtype.BFloat16FromFloat64(f))), nil
+		case dtype.Float16:
+			return binary.LittleEndian.AppendUint16(data, dtype.Float16FromFloat64(f).Bits()), nil
+		case dtype.Float32:
+			return binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(f))), nil
+		}
+		return binary.LittleEndian.AppendUint64(data, math.Float64bits(f)), nil
+	}
+	return nil, fmt.Errorf("constant of data type %s not supported", dt)
+}
@@ -0,0 +1,337 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type nnBuilder struct {
+	g *Graph
+}
+
+var _ ops.NNBuilder = nnBuilder{}
+
+func (b nnBuilder) core() coreBuilder {
+	return coreBuilder{g: b.g}
+}
+
+// filled returns vals or n copies of def if vals is nil.
+func filled[T any](vals []T, n int, def T) []T {
+	if vals != nil {
+		return vals
+	}
+	res := make([]T, n)
+	for i := range res {
+		res[i] = def
+	}
+	return res
+}
+
+// paddingLiteral returns the dense literal of a list of low and high paddings.
+func paddingLiteral(padding [][2]int) string {
+	pairs := make([]string, len(padding))
+	for i, p := range padding {
+		pairs[i] = fmt.Sprintf("[%d, %d]", p[0], p[1])
+	}
+	return fmt.Sprintf("dense<[%s]> : tensor<%dx2xi64>", strings.Join(pairs, ", "), len(padding))
+}
+
+// convLayout returns the layout of the axes of an array in a convolution, for example [b, 0, 1, f].
+func convLayout(first, second string, firstAxis, secondAxis int, spatial []int) string {
+	layout := make([]string, len(spatial)+2)
+	layout[firstAxis] = first
+	layout[secondAxis] = second
+	for i, axis := range spatial {
+		layout[axis] = strconv.Itoa(i)
+	}
+	return "[" + strings.Join(layout, ", ") + "]"
+}
+
+func (b nnBuilder) ConvGeneralDilated(x, kernel ops.Node, dims ops.ConvDims, strides []int, padding [][2]int, inputDilation, kernelDilation []int, featureGroupCount int, precision ops.Precision) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, kernel})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := intercept.ConvShape(operands[0].typ.shape, operands[1].typ.shape, dims, strides, padding, inputDilation, kernelDilation, featureGroupCount)
+	if err != nil {
+		return nil, err
+	}
+	prec, ok := precisions[precision]
+	if !ok {
+		return nil, fmt.Errorf("precision %s not supported", precision)
+	}
+	spatialRank := len(dims.InputSpatialAxes)
+	numbers := fmt.Sprintf("#stablehlo.conv<%sx%s->%s>",
+		convLayout("b", "f", dims.InputBatchAxis, dims.InputFeatureAxis, dims.InputSpatialAxes),
+		convLayout("i", "o", dims.KernelInputFeatureAxis, dims.KernelOutputFeatureAxis, dims.KernelSpatialAxes),
+		convLayout("b", "f", dims.OutputBatchAxis, dims.OutputFeatureAxis, dims.OutputSpatialAxes))
+	attrs := fmt.Sprintf("window_strides = %s, padding = %s, lhs_dilation = %s, rhs_dilation = %s, dimension_numbers = %s, feature_group_count = %d : i64, batch_group_count = 1 : i64, precision_config = [#stablehlo<precision %s>, #stablehlo<precision %s>]",
+		i64Array(filled(strides, spatialRank, 1)),
+		paddingLiteral(filled(padding, spatialRank, [2]int{})),
+		i64Array(filled(inputDilation, spatialRank, 1)),
+		i64Array(filled(kernelDilation, spatialRank, 1)),
+		numbers, featureGroupCount, prec, prec)
+	return wrap(b.g.emit("stablehlo.convolution", operands, attrs, valueType{shape: sh}))
+}
+
+// reduceWindow writes a stablehlo.reduce_window operation.
+func (b nnBuilder) reduceWindow(x, init *Node, window ops.Window, combine func(lhs, rhs *Node) (*Node, error)) (*Node, error) {
+	sh, err := intercept.ReduceWindowShape(init.typ.shape, x.typ.shape, window)
+	if err != nil {
+		return nil, err
+	}
+	rank := len(x.typ.shape.AxisLengths)
+	attrs := fmt.Sprintf("window_dimensions = %s, window_strides = %s, base_dilations = %s, window_dilations = %s, padding = %s",
+		i64Array(window.Dimensions),
+		i64Array(filled(window.Strides, rank, 1)),
+		i64Array(filled(window.BaseDilations, rank, 1)),
+		i64Array(filled(window.WindowDilations, rank, 1)),
+		paddingLiteral(filled(window.Padding, rank, [2]int{})))
+	return b.core().reduction("stablehlo.reduce_window", x, init, attrs, valueType{shape: sh}, combine)
+}
+
+func (b nnBuilder) ReduceWindow(body *ops.Subgraph, init, x ops.Node, window ops.Window) (ops.Node, error) {
+	operands, err := b.g.nodes([]ops.Node{x, init})
+	if err != nil {
+		return nil, err
+	}
+	combine, err := b.core().combiner(body, operands[1])
+	if err != nil {
+		return nil, err
+	}
+	return wrap(b.reduceWindow(operands[0], operands[1], window, combine))
+}
+
+// pool reduces the windows of x with one of the ReduceSum or ReduceMax operations.
+func (b nnBuilder) pool(op, reduce ops.OpID, x ops.Node, window ops.Window) (*Node, error) {
+	xNode, err := b.g.node(x)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := intercept.PoolShape(op, xNode.typ.shape, window); err != nil {
+		return nil, err
+	}
+	init, err := b.core().initValue(reduce, xNode.typ.shape.DType)
+	if err != nil {
+		return nil, err
+	}
+	return b.reduceWindow(xNode, init, window, func(lhs, rhs *Node) (*Node, error) {
+		return b.g.emit(reductions[reduce], []*Node{lhs, rhs}, "", init.typ)
+	})
+}
+
+func (b nnBuilder) MaxPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	return wrap(b.pool(ops.OpMaxPool, ops.OpReduceMax, x, window))
+}
+
+func (b nnBuilder) AvgPool(x ops.Node, window ops.Window) (ops.Node, error) {
+	sum, err := b.pool(ops.OpAvgPool, ops.OpReduceSum, x, window)
+	if err != nil {
+		return nil, err
+	}
+	sh := shape.Scalar(sum.typ.shape.DType)
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	size := shape.Size(window.Dimensions)
+	count, err := b.g.emit("stablehlo.constant", nil, fmt.Sprintf("value = dense<%d> : %s", size, typ), valueType{shape: sh})
+	if err != nil {
+		return nil, err
+	}
+	return b.core().BinaryOp(ops.Div, sum, count)
+}
+
+// parseConvLayout parses the layout of the axes of an array in a convolution, for example [b, 0, 1, f].
+func parseConvLayout(layout, first, second string) (firstAxis, secondAxis int, spatial []int, err error) {
+	fields := strings.Split(strings.Trim(layout, "[]"), ",")
+	spatial = make([]int, len(fields)-2)
+	firstAxis, secondAxis = -1, -1
+	for axis, field := range fields {
+		switch field = strings.TrimSpace(field); field {
+		case first:
+			firstAxis = axis
+		case second:
+			secondAxis = axis
+		default:
+			i, err := strconv.Atoi(field)
+			if err != nil || i < 0 || i >= len(spatial) {
+				return 0, 0, nil, fmt.Errorf("invalid convolution layout %s", layout)
+			}
+			spatial[i] = axis
+		}
+	}
+	if firstAxis < 0 || secondAxis < 0 {
+		return 0, 0, nil, fmt.Errorf("invalid convolution layout %s", layout)
+	}
+	return firstAxis, secondAxis, spatial, nil
+}
+
+// parseConvDims parses convolution dimension numbers, for example #stablehlo.conv<[b, 0, f]x[0, i, o]->[b, 0, f]>.
+func parseConvDims(attr string) (ops.ConvDims, error) {
+	var dims ops.ConvDims
+	inner := strings.TrimSuffix(strings.TrimPrefix(attr, "#stablehlo.conv<"), ">")
+	input, rest, ok1 := strings.Cut(inner, "x")
+	kernel, output, ok2 := strings.Cut(rest, "->")
+	if !ok1 || !ok2 {
+		return dims, fmt.Errorf("convolution dimension numbers %q not supported", attr)
+	}
+	var err error
+	if dims.InputBatchAxis, dims.InputFeatureAxis, dims.InputSpatialAxes, err = parseConvLayout(input, "b", "f"); err != nil {
+		return dims, err
+	}
+	if dims.KernelInputFeatureAxis, dims.KernelOutputFeatureAxis, dims.KernelSpatialAxes, err = parseConvLayout(kernel, "i", "o"); err != nil {
+		return dims, err
+	}
+	if dims.OutputBatchAxis, dims.OutputFeatureAxis, dims.OutputSpatialAxes, err = parseConvLayout(output, "b", "f"); err != nil {
+		return dims, err
+	}
+	return dims, nil
+}
+
+// parsePadding parses a dense literal of low and high paddings, for example dense<[[0, 0], [1, 1]]>.
+func parsePadding(attr string) ([][2]int, error) {
+	literal, _, _ := strings.Cut(attr, " : ")
+	inner, ok := strings.CutPrefix(literal, "dense<")
+	if !ok {
+		return nil, fmt.Errorf("invalid padding %q", attr)
+	}
+	vals, err := parseInts(strings.NewReplacer("[", "", "]", "", ">", "").Replace(inner))
+	if err != nil || len(vals)%2 != 0 {
+		return nil, fmt.Errorf("invalid padding %q", attr)
+	}
+	padding := make([][2]int, len(vals)/2)
+	for i := range padding {
+		padding[i] = [2]int{vals[2*i], vals[2*i+1]}
+	}
+	return padding, nil
+}
+
+func (s *scope) convolution(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if count, err := intAttr(op, "batch_group_count"); err == nil && count != 1 {
+		return nil, fmt.Errorf("batch group count %d not supported", count)
+	}
+	dims, err := parseConvDims(op.attrs["dimension_numbers"])
+	if err != nil {
+		return nil, err
+	}
+	var params [3][]int
+	for i, name := range []string{"window_strides", "lhs_dilation", "rhs_dilation"} {
+		if attr, ok := op.attrs[name]; ok {
+			if params[i], err = parseI64Array(attr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var padding [][2]int
+	if attr, ok := op.attrs["padding"]; ok {
+		if padding, err = parsePadding(attr); err != nil {
+			return nil, err
+		}
+	}
+	featureGroupCount := 1
+	if _, ok := op.attrs["feature_group_count"]; ok {
+		if featureGroupCount, err = intAttr(op, "feature_group_count"); err != nil {
+			return nil, err
+		}
+	}
+	precision, err := parsePrecision(op)
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.NN().ConvGeneralDilated(operands[0], operands[1], dims, params[0], padding, params[1], params[2], featureGroupCount, precision))
+}
+
+// reduceWindow replays a reduction of windows. Reductions computing the maximum of windows
+// are replayed as max pooling, other reductions call a subgraph combining two values.
+func (s *scope) reduceWindow(op *operation, operands []ops.Node) ([]ops.Node, error) {
+	if len(op.regions) != 1 || len(operands) != 2 {
+		return nil, fmt.Errorf("only reductions of a single array are supported")
+	}
+	var window ops.Window
+	var err error
+	for _, field := range []struct {
+		name string
+		dst  *[]int
+	}{
+		{"window_dimensions", &window.Dimensions},
+		{"window_strides", &window.Strides},
+		{"base_dilations", &window.BaseDilations},
+		{"window_dilations", &window.WindowDilations},
+	} {
+		if attr, ok := op.attrs[field.name]; ok {
+			if *field.dst, err = parseI64Array(attr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if attr, ok := op.attrs["padding"]; ok {
+		if window.Padding, err = parsePadding(attr); err != nil {
+			return nil, err
+		}
+	}
+	init, initOK := operands[1].(interface{ Shape() *shape.Shape })
+	x, xOK := operands[0].(interface{ Shape() *shape.Shape })
+	if initOK && xOK {
+		id, ok := s.reduction(op, init.Shape())
+		axis, cumulative := cumulativeAxis(x.Shape(), window)
+		switch {
+		case ok && id == ops.OpReduceMax:
+			return one(s.g.NN().MaxPool(operands[0], window))
+		case ok && id == ops.OpReduceSum && cumulative:
+			return one(s.g.Num().CumSum(operands[0], axis))
+		case ok && id == ops.OpReduceProd && cumulative:
+			return one(s.g.Num().CumProd(operands[0], axis))
+		}
+	}
+	body, err := s.regionSubgraph("reduce_window", op.regions[0])
+	if err != nil {
+		return nil, err
+	}
+	return one(s.g.NN().ReduceWindow(body, operands[1], operands[0], window))
+}
+
+// cumulativeAxis returns the axis along which the windows end at every element of x,
+// as emitted by CumSum and CumProd.
+func cumulativeAxis(x *shape.Shape, window ops.Window) (int, bool) {
+	for _, params := range [][]int{window.Strides, window.BaseDilations, window.WindowDilations} {
+		if slices.ContainsFunc(params, func(p int) bool { return p != 1 }) {
+			return 0, false
+		}
+	}
+	rank := len(x.AxisLengths)
+	if len(window.Dimensions) != rank || len(window.Padding) != rank {
+		return 0, false
+	}
+	axis := -1
+	for i, length := range x.AxisLengths {
+		switch {
+		case window.Dimensions[i] == 1 && window.Padding[i] == [2]int{}:
+		case axis < 0 && window.Dimensions[i] == length && window.Padding[i] == [2]int{length - 1, 0}:
+			axis = i
+		default:
+			return 0, false
+		}
+	}
+	return axis, axis >= 0
+}
@@ -0,0 +1,397 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type (
+	// blockArg is an argument of a function or of a region.
+	blockArg struct {
+		name string
+		typ  string
+	}
+
+	// operation in the generic MLIR form.
+	operation struct {
+		// result is the SSA name of the results without the % prefix, empty if there is no result.
+		result     string
+		numResults int
+		name       string
+		operands   []string
+		regions    []*region
+		attrs      map[string]string
+		// types are the types of the results.
+		types []string
+	}
+
+	region struct {
+		args []blockArg
+		ops  []*operation
+	}
+
+	funcDecl struct {
+		symbol  string
+		args    []blockArg
+		results []string
+		ops     []*operation
+	}
+)
+
+// parser parses the subset of the MLIR textual format produced by this package:
+// operations in the generic form grouped in functions of a module.
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line := 1 + strings.Count(p.src[:p.pos], "\n")
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// accept consumes s if it is the next token.
+func (p *parser) accept(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func (p *parser) peek(s string) bool {
+	p.skipSpace()
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func isIdentRune(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || c == '-' || c == '#' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// ident returns an identifier, including the prefix character (%, @, ^) if any.
+func (p *parser) ident() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.src) && strings.ContainsRune("%@^", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	for p.pos < len(p.src) && isIdentRune(p.src[p.pos]) && p.src[p.pos] != '-' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected an identifier")
+	}
+	return p.src[start:p.pos], nil
+}
+
+// balanced returns the text up to the first of the stop characters outside of brackets and strings.
+func (p *parser) balanced(stops string) (string, error) {
+	p.skipSpace()
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			end := strings.IndexByte(p.src[p.pos+1:], '"')
+			if end < 0 {
+				return "", p.errorf("unterminated string")
+			}
+			p.pos += end + 2
+			continue
+		case c == '-' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '>':
+			p.pos += 2
+			continue
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			return strings.TrimSpace(p.src[start:p.pos]), nil
+		case c == '<' || c == '[' || c == '(' || c == '{':
+			depth++
+		case c == '>' || c == ']' || c == ')' || c == '}':
+			depth--
+		}
+		p.pos++
+	}
+	return "", p.errorf("unexpected end of input")
+}
+
+// typeList parses a parenthesized list of types.
+func (p *parser) typeList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var types []string
+	for !p.accept(")") {
+		typ, err := p.balanced(",)")
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, typ)
+		p.accept(",")
+	}
+	return types, nil
+}
+
+// results parses the result types of a function or an operation.
+func (p *parser) results() ([]string, error) {
+	if p.peek("(") {
+		return p.typeList()
+	}
+	typ, err := p.balanced(" \n{")
+	if err != nil {
+		return nil, err
+	}
+	return []string{typ}, nil
+}
+
+func (p *parser) module() (map[string]*funcDecl, error) {
+	if err := p.expect("module"); err != nil {
+		return nil, err
+	}
+	if p.peek("@") {
+		if _, err := p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("attributes") {
+		if _, err := p.balanced(" \n"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	funcs := make(map[string]*funcDecl)
+	for !p.accept("}") {
+		fn, err := p.function()
+		if err != nil {
+			return nil, err
+		}
+		funcs[fn.symbol] = fn
+	}
+	return funcs, nil
+}
+
+func (p *parser) function() (*funcDecl, error) {
+	if err := p.expect("func.func"); err != nil {
+		return nil, err
+	}
+	p.accept("public")
+	p.accept("private")
+	symbol, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	fn := &funcDecl{symbol: strings.TrimPrefix(symbol, "@")}
+	if fn.args, err = p.blockArgs(); err != nil {
+		return nil, err
+	}
+	if p.accept("->") {
+		if fn.results, err = p.results(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("attributes") {
+		if _, err := p.balanced(" \n"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if fn.ops, err = p.operations(); err != nil {
+		return nil, err
+	}
+	return fn, p.expect("}")
+}
+
+func (p *parser) blockArgs() ([]blockArg, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []blockArg
+	for !p.accept(")") {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		typ, err := p.balanced(",)")
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, blockArg{name: name, typ: typ})
+		p.accept(",")
+	}
+	return args, nil
+}
+
+// operations parses operations until the end of a block.
+func (p *parser) operations() ([]*operation, error) {
+	var ops []*operation
+	for !p.peek("}") && !p.peek("^") {
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{numResults: 0}
+	if p.peek("%") {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		op.result, op.numResults = name, 1
+		if p.accept(":") {
+			if _, err := fmt.Sscanf(p.src[p.pos:], "%d", &op.numResults); err != nil {
+				return nil, p.errorf("invalid number of results")
+			}
+			for p.pos < len(p.src) && unicode.IsDigit(rune(p.src[p.pos])) {
+				p.pos++
+			}
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("\""); err != nil {
+		return nil, p.errorf("only operations in the generic form are supported")
+	}
+	end := strings.IndexByte(p.src[p.pos:], '"')
+	if end < 0 {
+		return nil, p.errorf("unterminated operation name")
+	}
+	op.name = p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for !p.accept(")") {
+		operand, err := p.balanced(",)")
+		if err != nil {
+			return nil, err
+		}
+		op.operands = append(op.operands, operand)
+		p.accept(",")
+	}
+	if p.accept("(") {
+		for !p.accept(")") {
+			reg, err := p.region()
+			if err != nil {
+				return nil, err
+			}
+			op.regions = append(op.regions, reg)
+			p.accept(",")
+		}
+	}
+	op.attrs = make(map[string]string)
+	if p.accept("{") {
+		for !p.accept("}") {
+			key, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			if op.attrs[key], err = p.balanced(",}"); err != nil {
+				return nil, err
+			}
+			p.accept(",")
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if _, err := p.typeList(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("->"); err != nil {
+		return nil, err
+	}
+	var err error
+	if op.types, err = p.opResults(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// opResults parses the result types of an operation, which end with the line.
+func (p *parser) opResults() ([]string, error) {
+	if p.peek("(") {
+		return p.typeList()
+	}
+	typ, err := p.balanced("\n")
+	if err != nil {
+		return nil, err
+	}
+	return []string{typ}, nil
+}
+
+func (p *parser) region() (*region, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	reg := &region{}
+	if p.accept("^") {
+		if _, err := p.ident(); err != nil {
+			return nil, err
+		}
+		var err error
+		if reg.args, err = p.blockArgs(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if reg.ops, err = p.operations(); err != nil {
+		return nil, err
+	}
+	return reg, p.expect("}")
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/intercept"
+	"github.com/gx-org/backend/shape"
+)
+
+type randBuilder struct {
+	g *Graph
+}
+
+var _ ops.RandBuilder = randBuilder{}
+
+var rngAlgorithms = map[ops.RngAlgorithm]string{
+	ops.DefaultRng:  "DEFAULT",
+	ops.ThreeFryRng: "THREE_FRY",
+	ops.PhiloxRng:   "PHILOX",
+}
+
+// uniformBits are the parameters to generate uniform floating-point values from random bits:
+// the data type of the bits, the number of bits of the mantissa, and the bits of 1.
+var uniformBits = map[dtype.DataType]struct {
+	bits     dtype.DataType
+	mantissa int
+	one      uint64
+}{
+	dtype.Float32: {bits: dtype.Uint32, mantissa: 23, one: uint64(math.Float32bits(1))},
+	dtype.Float64: {bits: dtype.Uint64, mantissa: 52, one: math.Float64bits(1)},
+}
+
+// splat returns a constant array of the shape sh filled with a literal.
+func (b randBuilder) splat(literal string, sh *shape.Shape) (*Node, error) {
+	typ, err := tensorType(b.g.plat, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.g.emit("stablehlo.constant", nil, "value = dense<"+literal+"> : "+typ, valueType{shape: sh})
+}
+
+// op writes an element-wise operation returning an array of the shape sh.
+func (b randBuilder) op(name string, sh *shape.Shape, operands ...*Node) (*Node, error) {
+	return b.g.emit(name, operands, "", valueType{shape: sh})
+}
+
+// bits writes a stablehlo.rng_bit_generator operation and returns the next state and the random bits.
+func (b randBuilder) bits(algorithm ops.RngAlgorithm, state *Node, sh *shape.Shape) (next, bits *Node, err error) {
+	alg, ok := rngAlgorithms[algorithm]
+	if !ok {
+		return nil, nil, fmt.Errorf("random number generator algorithm %s not supported", algorithm)
+	}
+	results, err := b.g.emitResults("stablehlo.rng_bit_generator", []*Node{state}, "rng_algorithm = #stablehlo<rng_algorithm "+alg+">", []valueType{state.typ, {shape: sh}})
+	if err != nil {
+		return nil, nil, err
+	}
+	return results[0], results[1], nil
+}
+
+// tuple packs the next state and the generated values into a tuple.
+func (b randBuilder) tuple(next, values *Node) (ops.Node, error) {
+	return wrap(b.g.emit("stablehlo.tuple", []*Node{next, values}, "", valueType{elems: []valueType{next.typ, values.typ}}))
+}
+
+// state returns the node of a state after checking the shape of the generated values.
+func (b randBuilder) state(op ops.OpID, state ops.Node, sh *shape.Shape) (*Node, error) {
+	stateNode, err := b.g.node(state)
+	if err != nil {
+		return nil, err
+	}
+	if stateNode.typ.isTuple() {
+		return nil, fmt.Errorf("random number generator state %s is a tuple", stateNode)
+	}
+	if _, err := intercept.RandShape(op, stateNode.typ.shape, sh); err != nil {
+		return nil, err
+	}
+	return stateNode, nil
+}
+
+func (b randBuilder) RngBitGenerator(algorithm ops.RngAlgorithm, state ops.Node, sh *shape.Shape) (ops.Node, error) {
+	stateNode, err := b.state(ops.OpRngBitGenerator, state, sh)
+	if err != nil {
+		return nil, err
+	}
+	next, bits, err := b.bits(algorithm, stateNode, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.tuple(next, bits)
+}
+
+// uniform generates values in [0, 1) by setting the mantissa of 1 to random bits and subtracting 1.
+func (b randBuilder) uniform(state *Node, sh *shape.Shape) (next, values *Node, err error) {
+	params, ok := uniformBits[sh.DType]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s not supported on data type %s", ops.OpUniform, sh.DType)
+	}
+	bitsShape := &shape.Shape{DType: params.bits, AxisLengths: sh.AxisLengths}
+	next, bits, err := b.bits(ops.ThreeFryRng, state, bitsShape)
+	if err != nil {
+		return nil, nil, err
+	}
+	shift, err := b.splat(fmt.Sprint(dtype.BitSizeof(params.bits)-params.mantissa), bitsShape)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bits, err = b.op("stablehlo.shift_right_logical", bitsShape, bits, shift); err != nil {
+		return nil, nil, err
+	}
+	oneBits, err := b.splat(fmt.Sprint(params.one), bitsShape)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bits, err = b.op("stablehlo.or", bitsShape, bits, oneBits); err != nil {
+		return nil, nil, err
+	}
+	if values, err = b.op("stablehlo.bitcast_convert", sh, bits); err != nil {
+		return nil, nil, err
+	}
+	one, err := b.splat(floatLiteral(1), sh)
+	if err != nil {
+		return nil, nil, err
+	}
+	if values, err = b.op("stablehlo.subtract", sh, values, one); err != nil {
+		return nil, nil, err
+	}
+	return next, values, nil
+}
+
+func (b randBuilder) Uniform(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	stateNode, err := b.state(ops.OpUniform, state, sh)
+	if err != nil {
+		return nil, err
+	}
+	next, values, err := b.uniform(stateNode, sh)
+	if err != nil {
+		return nil, err
+	}
+	return b.tuple(next, values)
+}
+
+// Normal uses the Box-Muller transform: sqrt(-2*log(1-u1))*cos(2*pi*u2)
+// is normally distributed if u1 and u2 are uniformly distributed in [0, 1).
+func (b randBuilder) Normal(sh *shape.Shape, state ops.Node) (ops.Node, error) {
+	stateNode, err := b.state(ops.OpNormal, state, sh)
+	if err != nil {
+		return nil, err
+	}
+	next, u1, err := b.uniform(stateNode, sh)
+	if err != nil {
+		return nil, err
+	}
+	next, u2, err := b.uniform(next, sh)
+	if err != nil {
+		return nil, err
+	}
+	one, err := b.splat(floatLiteral(1), sh)
+	if err != nil {
+		return nil, err
+	}
+	radius, err := b.op("stablehlo.subtract", sh, one, u1)
+	if err != nil {
+		return nil, err
+	}
+	if radius, err = b.op("stablehlo.log", sh, radius); err != nil {
+		return nil, err
+	}
+	minusTwo, err := b.splat(floatLiteral(-2), sh)
+	if err != nil {
+		return nil, err
+	}
+	if radius, err = b.op("stablehlo.multiply", sh, minusTwo, radius); err != nil {
+		return nil, err
+	}
+	if radius, err = b.op("stablehlo.sqrt", sh, radius); err != nil {
+		return nil, err
+	}
+	twoPi, err := b.splat(floatLiteral(2*math.Pi), sh)
+	if err != nil {
+		return nil, err
+	}
+	angle, err := b.op("stablehlo.multiply", sh, twoPi, u2)
+	if err != nil {
+		return nil, err
+	}
+	if angle, err = b.op("stablehlo.cosine", sh, angle); err != nil {
+		return nil, err
+	}
+	values, err := b.op("stablehlo.multiply", sh, radius, angle)
+	if err != nil {
+		return nil, err
+	}
+	return b.tuple(next, values)
+}
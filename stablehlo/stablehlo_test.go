@@ -0,0 +1,1112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo_test
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+	"github.com/gx-org/backend/stablehlo"
+)
+
+type compiler struct {
+	mod *stablehlo.Module
+}
+
+func (c *compiler) Compile(dev platform.Device, mod *stablehlo.Module) (ops.Runner, error) {
+	c.mod = mod
+	return nil, nil
+}
+
+func constant(t *testing.T, plat *platformtest.Platform, sh *shape.Shape, data []byte) platform.HostBuffer {
+	t.Helper()
+	buf, err := plat.Allocate(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(buf.Acquire(), data)
+	buf.Release()
+	return buf
+}
+
+func TestModule(t *testing.T) {
+	plat := platformtest.New(1)
+	comp := &compiler{}
+	g := stablehlo.New(plat, "add", comp)
+	sh := shape.Of(dtype.Float32, 2)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := g.Core().Constant(constant(t, plat, sh, []byte{0, 0, 0x80, 0x3f, 0, 0, 0, 0x40}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.Core().BinaryOp(ops.Add, x, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := g.Core().Constant(constant(t, plat, shape.Scalar(dtype.Float32), []byte{0, 0, 0, 0x40}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmp, err := g.Core().BinaryOp(ops.Less, sum, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := plat.Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs := []*ops.OutputNode{{Node: cmp, Shape: shape.Of(dtype.Bool, 2)}}
+	if _, err := g.Compile(dev, outs, nil, []*shape.Shape{sh}); err != nil {
+		t.Fatal(err)
+	}
+	want := `module @add {
+  func.func public @main(%arg0: tensor<2xf32>) -> (tensor<2xi1>) {
+    %0 = "stablehlo.constant"() {value = dense<"0x0000803F00000040"> : tensor<2xf32>} : () -> tensor<2xf32>
+    %1 = "stablehlo.add"(%arg0, %0) : (tensor<2xf32>, tensor<2xf32>) -> tensor<2xf32>
+    %2 = "stablehlo.constant"() {value = dense<"0x00000040"> : tensor<f32>} : () -> tensor<f32>
+    %3 = "stablehlo.broadcast_in_dim"(%2) {broadcast_dimensions = array<i64>} : (tensor<f32>) -> tensor<2xf32>
+    %4 = "stablehlo.compare"(%1, %3) {comparison_direction = #stablehlo<comparison_direction LT>, compare_type = #stablehlo<comparison_type FLOAT>} : (tensor<2xf32>, tensor<2xf32>) -> tensor<2xi1>
+    "func.return"(%4) : (tensor<2xi1>) -> ()
+  }
+}
+`
+	if comp.mod.Text != want {
+		t.Errorf("got module:\n%s\nwant:\n%s", comp.mod.Text, want)
+	}
+	if got := comp.mod.Outputs[0].String(); got != "[2]bool" {
+		t.Errorf("got output shape %s but want [2]bool", got)
+	}
+	// Importing the module into a new graph emits the same module.
+	imported := stablehlo.New(plat, "add", comp)
+	outs, err = stablehlo.Import(imported, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := imported.Module(outs, nil, comp.mod.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Text != want {
+		t.Errorf("got imported module:\n%s\nwant:\n%s", mod.Text, want)
+	}
+}
+
+func TestWhile(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "loop", &compiler{})
+	i32 := shape.Scalar(dtype.Int32)
+	ten, err := g.Core().Constant(constant(t, plat, i32, []byte{10, 0, 0, 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, err := g.Core().Constant(constant(t, plat, i32, make([]byte, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := g.Core().Tuple([]ops.Node{zero, ten})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond, err := g.Core().Subgraph("cond", []*shape.Shape{i32, i32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := cond.Core().Argument("i", i32, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := cond.Core().Argument("n", i32, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	less, err := cond.Core().BinaryOp(ops.Less, i, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := g.Core().Subgraph("body", []*shape.Shape{i32, i32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err = body.Core().Argument("i", i32, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = body.Core().Argument("n", i32, 1); err != nil {
+		t.Fatal(err)
+	}
+	next, err := body.Core().BinaryOp(ops.Add, i, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextState, err := body.Core().Tuple([]ops.Node{next, n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop, err := g.Core().While(
+		&ops.Subgraph{Graph: cond, Result: ops.OutputNode{Node: less}},
+		&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: nextState}},
+		state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := loop.(ops.Tuple).Element(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := g.Module([]*ops.OutputNode{{Node: result, Shape: i32}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`func.func private @cond(%arg0: tensor<i32>, %arg1: tensor<i32>) -> (tensor<i1>)`,
+		`%5:2 = "stablehlo.while"(%3, %4) ({`,
+		`%11 = "func.call"(%9, %10) {callee = @body}`,
+		`"stablehlo.return"(%12, %13) : (tensor<i32>, tensor<i32>) -> ()`,
+		`%14 = "stablehlo.tuple"(%5#0, %5#1)`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "loop", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reimported.Text, `"stablehlo.while"`) {
+		t.Errorf("imported module has no while loop:\n%s", reimported.Text)
+	}
+	if _, err := stablehlo.Import(imported, []byte("ML\xefR\x00")); err == nil {
+		t.Errorf("expected an error when importing MLIR bytecode")
+	}
+}
+
+func TestReduce(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "reduce", &compiler{})
+	sh := shape.Of(dtype.Float32, 2, 3)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.Core().ReduceSum(x, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	max, err := g.Core().ReduceMax(x, []int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f32 := shape.Scalar(dtype.Float32)
+	body, err := g.Core().Subgraph("mul", []*shape.Shape{f32, f32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lhs, err := body.Core().Argument("lhs", f32, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhs, err := body.Core().Argument("rhs", f32, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mul, err := body.Core().BinaryOp(ops.Mul, lhs, rhs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one, err := g.Core().Constant(constant(t, plat, f32, []byte{0, 0, 0x80, 0x3f}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod, err := g.Core().Reduce(&ops.Subgraph{Graph: body, Result: ops.OutputNode{Node: mul}}, one, x, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs := []*ops.OutputNode{
+		{Node: sum, Shape: shape.Of(dtype.Float32, 2)},
+		{Node: max, Shape: f32},
+		{Node: prod, Shape: shape.Of(dtype.Float32, 3)},
+	}
+	mod, err := g.Module(outs, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.constant"() {value = dense<0> : tensor<f32>} : () -> tensor<f32>`,
+		`%1 = "stablehlo.reduce"(%arg0, %0) ({`,
+		`^bb0(%2: tensor<f32>, %3: tensor<f32>):`,
+		`%4 = "stablehlo.add"(%2, %3) : (tensor<f32>, tensor<f32>) -> tensor<f32>`,
+		`}) {dimensions = array<i64: 1>} : (tensor<2x3xf32>, tensor<f32>) -> tensor<2xf32>`,
+		`value = dense<0xFF800000> : tensor<f32>`,
+		`"stablehlo.maximum"`,
+		`"func.call"(%12, %13) {callee = @mul}`,
+		`}) {dimensions = array<i64: 0>} : (tensor<2x3xf32>, tensor<f32>) -> tensor<3xf32>`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "reduce", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(reimported.Text, `"stablehlo.reduce"`); got != 3 {
+		t.Errorf("imported module has %d reductions but want 3:\n%s", got, reimported.Text)
+	}
+}
+
+func TestGatherScatter(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "embed", &compiler{})
+	table := shape.Of(dtype.Float32, 10, 4)
+	ids := shape.Of(dtype.Int32, 3)
+	x, err := g.Core().Argument("table", table, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices, err := g.Core().Argument("ids", ids, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := g.Core().Gather(x, indices, ops.GatherDims{
+		OffsetAxes:      []int{1},
+		CollapsedAxes:   []int{0},
+		StartIndexMap:   []int{0},
+		IndexVectorAxis: 1,
+	}, []int{1, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := g.Core().Scatter(x, indices, rows, ops.ScatterDims{
+		UpdateWindowAxes:         []int{1},
+		InsertedWindowAxes:       []int{0},
+		ScatterAxesToOperandAxes: []int{0},
+		IndexVectorAxis:          1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*shape.Shape{table, ids}
+	mod, err := g.Module([]*ops.OutputNode{{Node: updated, Shape: table}}, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.gather"(%arg0, %arg1) {dimension_numbers = #stablehlo.gather<offset_dims = [1], collapsed_slice_dims = [0], start_index_map = [0], index_vector_dim = 1>, slice_sizes = array<i64: 1, 4>, indices_are_sorted = false} : (tensor<10x4xf32>, tensor<3xi32>) -> tensor<3x4xf32>`,
+		`%1 = "stablehlo.scatter"(%arg0, %arg1, %0) ({`,
+		`"stablehlo.return"(%3) : (tensor<f32>) -> ()`,
+		`}) {scatter_dimension_numbers = #stablehlo.scatter<update_window_dims = [1], inserted_window_dims = [0], scatter_dims_to_operand_dims = [0], index_vector_dim = 1>, indices_are_sorted = false, unique_indices = false} : (tensor<10x4xf32>, tensor<3xi32>, tensor<3x4xf32>) -> tensor<10x4xf32>`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "embed", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reimported.Text != mod.Text {
+		t.Errorf("got imported module:\n%s\nwant:\n%s", reimported.Text, mod.Text)
+	}
+}
+
+func TestTransposeReversePad(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "pad", &compiler{})
+	sh := shape.Of(dtype.Float32, 2, 3)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transposed, err := g.Core().Transpose(x, []int{1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed, err := g.Core().Reverse(transposed, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, err := g.Core().Constant(constant(t, plat, shape.Scalar(dtype.Float32), make([]byte, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded, err := g.Core().Pad(reversed, zero, []int{1, 0}, []int{0, 2}, []int{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := shape.Of(dtype.Float32, 4, 5)
+	mod, err := g.Module([]*ops.OutputNode{{Node: padded, Shape: out}}, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module @pad {
+  func.func public @main(%arg0: tensor<2x3xf32>) -> (tensor<4x5xf32>) {
+    %0 = "stablehlo.transpose"(%arg0) {permutation = array<i64: 1, 0>} : (tensor<2x3xf32>) -> tensor<3x2xf32>
+    %1 = "stablehlo.reverse"(%0) {dimensions = array<i64: 0>} : (tensor<3x2xf32>) -> tensor<3x2xf32>
+    %2 = "stablehlo.constant"() {value = dense<"0x00000000"> : tensor<f32>} : () -> tensor<f32>
+    %3 = "stablehlo.pad"(%1, %2) {edge_padding_low = array<i64: 1, 0>, edge_padding_high = array<i64: 0, 2>, interior_padding = array<i64: 0, 1>} : (tensor<3x2xf32>, tensor<f32>) -> tensor<4x5xf32>
+    "func.return"(%3) : (tensor<4x5xf32>) -> ()
+  }
+}
+`
+	if mod.Text != want {
+		t.Errorf("got module:\n%s\nwant:\n%s", mod.Text, want)
+	}
+	imported := stablehlo.New(plat, "pad", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reimported.Text != want {
+		t.Errorf("got imported module:\n%s\nwant:\n%s", reimported.Text, want)
+	}
+}
+
+func TestConvPool(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "conv", &compiler{})
+	xShape := shape.Of(dtype.Float32, 1, 4, 4, 2)
+	kShape := shape.Of(dtype.Float32, 2, 2, 2, 3)
+	x, err := g.Core().Argument("x", xShape, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kernel, err := g.Core().Argument("kernel", kShape, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv, err := g.NN().ConvGeneralDilated(x, kernel, ops.NHWC(2), []int{2, 2}, [][2]int{{0, 1}, {0, 1}}, nil, nil, 1, ops.DefaultPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := ops.Window{Dimensions: []int{1, 2, 2, 1}, Strides: []int{1, 2, 2, 1}}
+	max, err := g.NN().MaxPool(x, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avg, err := g.NN().AvgPool(x, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs := []*ops.OutputNode{
+		{Node: conv, Shape: shape.Of(dtype.Float32, 1, 2, 2, 3)},
+		{Node: max, Shape: shape.Of(dtype.Float32, 1, 2, 2, 2)},
+		{Node: avg, Shape: shape.Of(dtype.Float32, 1, 2, 2, 2)},
+	}
+	args := []*shape.Shape{xShape, kShape}
+	mod, err := g.Module(outs, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.convolution"(%arg0, %arg1) {window_strides = array<i64: 2, 2>, padding = dense<[[0, 1], [0, 1]]> : tensor<2x2xi64>, lhs_dilation = array<i64: 1, 1>, rhs_dilation = array<i64: 1, 1>, dimension_numbers = #stablehlo.conv<[b, 0, 1, f]x[0, 1, i, o]->[b, 0, 1, f]>, feature_group_count = 1 : i64, batch_group_count = 1 : i64, precision_config = [#stablehlo<precision DEFAULT>, #stablehlo<precision DEFAULT>]} : (tensor<1x4x4x2xf32>, tensor<2x2x2x3xf32>) -> tensor<1x2x2x3xf32>`,
+		`"stablehlo.reduce_window"(%arg0, %1) ({`,
+		`}) {window_dimensions = array<i64: 1, 2, 2, 1>, window_strides = array<i64: 1, 2, 2, 1>, base_dilations = array<i64: 1, 1, 1, 1>, window_dilations = array<i64: 1, 1, 1, 1>, padding = dense<[[0, 0], [0, 0], [0, 0], [0, 0]]> : tensor<4x2xi64>} : (tensor<1x4x4x2xf32>, tensor<f32>) -> tensor<1x2x2x2xf32>`,
+		`"stablehlo.maximum"`,
+		`value = dense<4> : tensor<f32>`,
+		`"stablehlo.divide"`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "conv", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(reimported.Text, `"stablehlo.reduce_window"`), 2; got != want {
+		t.Errorf("imported module has %d window reductions but want %d:\n%s", got, want, reimported.Text)
+	}
+	if !strings.Contains(reimported.Text, `"stablehlo.convolution"`) {
+		t.Errorf("imported module has no convolution:\n%s", reimported.Text)
+	}
+}
+
+func TestRand(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "rand", &compiler{})
+	stateShape := ops.RngStateShape()
+	state, err := g.Core().Argument("state", stateShape, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := shape.Of(dtype.Float32, 2, 3)
+	uniform, err := g.Rand().Uniform(sh, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := uniform.(ops.Tuple).Element(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := uniform.(ops.Tuple).Element(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normal, err := g.Rand().Normal(shape.Of(dtype.Float64, 4), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalValues, err := normal.(ops.Tuple).Element(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Rand().Uniform(shape.Of(dtype.Int32, 4), state); err == nil {
+		t.Errorf("expected an error when generating uniform integers")
+	}
+	outs := []*ops.OutputNode{
+		{Node: values, Shape: sh},
+		{Node: normalValues, Shape: shape.Of(dtype.Float64, 4)},
+	}
+	args := []*shape.Shape{stateShape}
+	mod, err := g.Module(outs, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0:2 = "stablehlo.rng_bit_generator"(%arg0) {rng_algorithm = #stablehlo<rng_algorithm THREE_FRY>} : (tensor<2xui64>) -> (tensor<2xui64>, tensor<2x3xui32>)`,
+		`%1 = "stablehlo.constant"() {value = dense<9> : tensor<2x3xui32>} : () -> tensor<2x3xui32>`,
+		`%2 = "stablehlo.shift_right_logical"(%0#1, %1)`,
+		`"stablehlo.bitcast_convert"`,
+		`%11:2 = "stablehlo.rng_bit_generator"(%9) {rng_algorithm = #stablehlo<rng_algorithm THREE_FRY>} : (tensor<2xui64>) -> (tensor<2xui64>, tensor<4xui64>)`,
+		`"stablehlo.cosine"`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "rand", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(reimported.Text, `"stablehlo.rng_bit_generator"`), 3; got != want {
+		t.Errorf("imported module has %d random number generators but want %d:\n%s", got, want, reimported.Text)
+	}
+}
+
+func TestSort(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "sort", &compiler{})
+	sh := shape.Of(dtype.Float32, 2, 3)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := g.Core().Sort(x, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices, err := g.Core().ArgSort(x, -1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, err := g.Core().TopK(x, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topIndices, err := top.Element(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Core().TopK(x, 4); err == nil {
+		t.Errorf("expected an error when selecting more elements than the axis length")
+	}
+	outs := []*ops.OutputNode{
+		{Node: sorted, Shape: sh},
+		{Node: indices, Shape: shape.Of(dtype.Int32, 2, 3)},
+		{Node: topIndices, Shape: shape.Of(dtype.Int32, 2, 2)},
+	}
+	mod, err := g.Module(outs, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.sort"(%arg0) ({`,
+		`^bb0(%1: tensor<f32>, %2: tensor<f32>):`,
+		`%3 = "stablehlo.compare"(%1, %2) {comparison_direction = #stablehlo<comparison_direction GT>, compare_type = #stablehlo<comparison_type TOTALORDER>} : (tensor<f32>, tensor<f32>) -> tensor<i1>`,
+		`}) {dimension = 1 : i64, is_stable = true} : (tensor<2x3xf32>) -> tensor<2x3xf32>`,
+		`%4 = "stablehlo.iota"() {iota_dimension = 1 : i64} : () -> tensor<2x3xi32>`,
+		`%5:2 = "stablehlo.sort"(%arg0, %4) ({`,
+		`^bb0(%6: tensor<f32>, %7: tensor<f32>, %8: tensor<i32>, %9: tensor<i32>):`,
+		`%11:2 = "chlo.top_k"(%arg0) {k = 2 : i64} : (tensor<2x3xf32>) -> (tensor<2x2xf32>, tensor<2x2xi32>)`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "sort", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for op, want := range map[string]int{`"stablehlo.sort"`: 3, `"chlo.top_k"`: 1} {
+		if got := strings.Count(reimported.Text, op); got != want {
+			t.Errorf("imported module has %d %s operations but want %d:\n%s", got, op, want, reimported.Text)
+		}
+	}
+}
+
+func TestMath(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "math", &compiler{})
+	sh := shape.Of(dtype.Float32, 3)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := g.Core().Constant(constant(t, plat, shape.Scalar(dtype.Float32), []byte{0, 0, 0, 0x40}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pow, err := g.Math().Pow(x, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atan2, err := g.Math().Atan2(pow, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isNaN, err := g.Math().IsNaN(atan2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isInf, err := g.Math().IsInf(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Math().IsNaN(isInf); err == nil {
+		t.Errorf("expected an error when testing booleans for NaN")
+	}
+	boolShape := shape.Of(dtype.Bool, 3)
+	mod, err := g.Module([]*ops.OutputNode{{Node: isNaN, Shape: boolShape}, {Node: isInf, Shape: boolShape}}, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module @math {
+  func.func public @main(%arg0: tensor<3xf32>) -> (tensor<3xi1>, tensor<3xi1>) {
+    %0 = "stablehlo.constant"() {value = dense<"0x00000040"> : tensor<f32>} : () -> tensor<f32>
+    %1 = "stablehlo.broadcast_in_dim"(%0) {broadcast_dimensions = array<i64>} : (tensor<f32>) -> tensor<3xf32>
+    %2 = "stablehlo.power"(%arg0, %1) : (tensor<3xf32>, tensor<3xf32>) -> tensor<3xf32>
+    %3 = "stablehlo.atan2"(%2, %arg0) : (tensor<3xf32>, tensor<3xf32>) -> tensor<3xf32>
+    %4 = "stablehlo.compare"(%3, %3) {comparison_direction = #stablehlo<comparison_direction NE>, compare_type = #stablehlo<comparison_type FLOAT>} : (tensor<3xf32>, tensor<3xf32>) -> tensor<3xi1>
+    %5 = "stablehlo.abs"(%arg0) : (tensor<3xf32>) -> tensor<3xf32>
+    %6 = "stablehlo.constant"() {value = dense<0x7F800000> : tensor<3xf32>} : () -> tensor<3xf32>
+    %7 = "stablehlo.compare"(%5, %6) {comparison_direction = #stablehlo<comparison_direction EQ>, compare_type = #stablehlo<comparison_type FLOAT>} : (tensor<3xf32>, tensor<3xf32>) -> tensor<3xi1>
+    "func.return"(%4, %7) : (tensor<3xi1>, tensor<3xi1>) -> ()
+  }
+}
+`
+	if mod.Text != want {
+		t.Errorf("got module:\n%s\nwant:\n%s", mod.Text, want)
+	}
+	imported := stablehlo.New(plat, "math", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := imported.Module(outs, nil, []*shape.Shape{sh}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "half", &compiler{})
+	sh := shape.Of(dtype.Float16, 2)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 and -2 in half precision.
+	c, err := g.Core().Constant(constant(t, plat, sh, []byte{0x00, 0x3c, 0x00, 0xc0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.Core().Binary(&ast.BinaryExpr{Op: token.ADD}, x, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	max, err := g.Core().ReduceMax(sum, []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := g.Module([]*ops.OutputNode{{Node: max, Shape: shape.Scalar(dtype.Float16)}}, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`func.func public @main(%arg0: tensor<2xf16>) -> (tensor<f16>)`,
+		`%0 = "stablehlo.constant"() {value = dense<"0x003C00C0"> : tensor<2xf16>} : () -> tensor<2xf16>`,
+		`value = dense<0xFC00> : tensor<f16>`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "half", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, []*shape.Shape{sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reimported.Text, `dense<"0x003C00C0"> : tensor<2xf16>`) {
+		t.Errorf("imported module does not contain the half-precision constant:\n%s", reimported.Text)
+	}
+}
+
+func TestComplex(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "complex", &compiler{})
+	sh := shape.Of(dtype.Float32, 2)
+	re, err := g.Core().Argument("re", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := g.Core().Argument("im", sh, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := g.Math().Complex(re, im)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conj, err := g.Math().Conj(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := g.Math().Abs(conj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Math().Real(re); err == nil {
+		t.Errorf("expected an error when taking the real part of a float")
+	}
+	outs := []*ops.OutputNode{
+		{Node: conj, Shape: shape.Of(dtype.Complex64, 2)},
+		{Node: abs, Shape: sh},
+	}
+	args := []*shape.Shape{sh, sh}
+	mod, err := g.Module(outs, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module @complex {
+  func.func public @main(%arg0: tensor<2xf32>, %arg1: tensor<2xf32>) -> (tensor<2xcomplex<f32>>, tensor<2xf32>) {
+    %0 = "stablehlo.complex"(%arg0, %arg1) : (tensor<2xf32>, tensor<2xf32>) -> tensor<2xcomplex<f32>>
+    %1 = "stablehlo.real"(%0) : (tensor<2xcomplex<f32>>) -> tensor<2xf32>
+    %2 = "stablehlo.imag"(%0) : (tensor<2xcomplex<f32>>) -> tensor<2xf32>
+    %3 = "stablehlo.negate"(%2) : (tensor<2xf32>) -> tensor<2xf32>
+    %4 = "stablehlo.complex"(%1, %3) : (tensor<2xf32>, tensor<2xf32>) -> tensor<2xcomplex<f32>>
+    %5 = "stablehlo.abs"(%4) : (tensor<2xcomplex<f32>>) -> tensor<2xf32>
+    "func.return"(%4, %5) : (tensor<2xcomplex<f32>>, tensor<2xf32>) -> ()
+  }
+}
+`
+	if mod.Text != want {
+		t.Errorf("got module:\n%s\nwant:\n%s", mod.Text, want)
+	}
+	imported := stablehlo.New(plat, "complex", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reimported.Text != want {
+		t.Errorf("got imported module:\n%s\nwant:\n%s", reimported.Text, want)
+	}
+}
+
+func TestSmallIntegers(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "small", &compiler{})
+	sh := shape.Of(dtype.Uint8, 2)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, err := g.Core().Cast(x, dtype.Int16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 300 and -1 as int16.
+	c, err := g.Core().Constant(constant(t, plat, shape.Of(dtype.Int16, 2), []byte{0x2c, 0x01, 0xff, 0xff}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := g.Core().Binary(&ast.BinaryExpr{Op: token.ADD}, wide, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrow, err := g.Core().Cast(sum, dtype.Int8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []*shape.Shape{sh}
+	mod, err := g.Module([]*ops.OutputNode{{Node: narrow, Shape: shape.Of(dtype.Int8, 2)}}, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `module @small {
+  func.func public @main(%arg0: tensor<2xui8>) -> (tensor<2xi8>) {
+    %0 = "stablehlo.convert"(%arg0) : (tensor<2xui8>) -> tensor<2xi16>
+    %1 = "stablehlo.constant"() {value = dense<"0x2C01FFFF"> : tensor<2xi16>} : () -> tensor<2xi16>
+    %2 = "stablehlo.add"(%0, %1) : (tensor<2xi16>, tensor<2xi16>) -> tensor<2xi16>
+    %3 = "stablehlo.convert"(%2) : (tensor<2xi16>) -> tensor<2xi8>
+    "func.return"(%3) : (tensor<2xi8>) -> ()
+  }
+}
+`
+	if mod.Text != want {
+		t.Errorf("got module:\n%s\nwant:\n%s", mod.Text, want)
+	}
+	imported := stablehlo.New(plat, "small", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reimported.Text != want {
+		t.Errorf("got imported module:\n%s\nwant:\n%s", reimported.Text, want)
+	}
+}
+
+func TestArgCumulative(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "num", &compiler{})
+	sh := shape.Of(dtype.Float32, 2, 3)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	argmax, err := g.Num().Argmax(x, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	argmin, err := g.Num().Argmin(x, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cumSum, err := g.Num().CumSum(x, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cumProd, err := g.Num().CumProd(x, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs := []*ops.OutputNode{
+		{Node: argmax, Shape: shape.Of(dtype.Int32, 2)},
+		{Node: argmin, Shape: shape.Of(dtype.Int32, 3)},
+		{Node: cumSum, Shape: sh},
+		{Node: cumProd, Shape: sh},
+	}
+	args := []*shape.Shape{sh}
+	mod, err := g.Module(outs, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.iota"() {iota_dimension = 1 : i64} : () -> tensor<2x3xi32>`,
+		`%1 = "stablehlo.constant"() {value = dense<0xFFFFFFFF> : tensor<f32>} : () -> tensor<f32>`,
+		`%2 = "stablehlo.constant"() {value = dense<2147483647> : tensor<i32>} : () -> tensor<i32>`,
+		`%3:2 = "stablehlo.reduce"(%arg0, %0, %1, %2) ({`,
+		`^bb0(%4: tensor<f32>, %5: tensor<i32>, %6: tensor<f32>, %7: tensor<i32>):`,
+		`%8 = "stablehlo.compare"(%4, %6) {comparison_direction = #stablehlo<comparison_direction GT>, compare_type = #stablehlo<comparison_type TOTALORDER>}`,
+		`}) {dimensions = array<i64: 1>} : (tensor<2x3xf32>, tensor<2x3xi32>, tensor<f32>, tensor<i32>) -> (tensor<2xf32>, tensor<2xi32>)`,
+		`window_dimensions = array<i64: 1, 3>, window_strides = array<i64: 1, 1>, base_dilations = array<i64: 1, 1>, window_dilations = array<i64: 1, 1>, padding = dense<[[0, 0], [2, 0]]> : tensor<2x2xi64>`,
+		`"stablehlo.multiply"`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "num", &compiler{})
+	importedOuts, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(importedOuts, nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for op, want := range map[string]int{`:2 = "stablehlo.reduce"`: 2, `"stablehlo.reduce_window"`: 2, `func.func private`: 0} {
+		if got := strings.Count(reimported.Text, op); got != want {
+			t.Errorf("imported module has %d %s but want %d:\n%s", got, op, want, reimported.Text)
+		}
+	}
+}
+
+// branch returns a subgraph applying a binary operator to its two arguments.
+func branch(t *testing.T, g ops.Graph, name string, op ops.BinaryOperator, sh *shape.Shape) *ops.Subgraph {
+	t.Helper()
+	sub, err := g.Core().Subgraph(name, []*shape.Shape{sh, sh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := sub.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := sub.Core().Argument("y", sh, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := sub.Core().BinaryOp(op, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ops.Subgraph{Graph: sub, Result: ops.OutputNode{Node: res}}
+}
+
+func TestCondSelect(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "cond", &compiler{})
+	sh := shape.Of(dtype.Float32, 2)
+	x, err := g.Core().Argument("x", sh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Core().Argument("y", sh, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pred, err := g.Core().Argument("pred", shape.Scalar(dtype.Bool), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond, err := g.Core().Cond(pred, branch(t, g, "add", ops.Add, sh), branch(t, g, "sub", ops.Sub, sh), x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	less, err := g.Core().BinaryOp(ops.Less, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, err := g.Core().Select(less, cond, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := []*shape.Shape{sh, sh, shape.Scalar(dtype.Bool)}
+	mod, err := g.Module([]*ops.OutputNode{{Node: selected, Shape: sh}}, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`%0 = "stablehlo.if"(%arg2) ({`,
+		`%1 = "func.call"(%arg0, %arg1) {callee = @add}`,
+		`}, {`,
+		`%2 = "func.call"(%arg0, %arg1) {callee = @sub}`,
+		`}) : (tensor<i1>) -> tensor<2xf32>`,
+		`%4 = "stablehlo.select"(%3, %0, %arg1) : (tensor<2xi1>, tensor<2xf32>, tensor<2xf32>) -> tensor<2xf32>`,
+	} {
+		if !strings.Contains(mod.Text, want) {
+			t.Errorf("module does not contain %q:\n%s", want, mod.Text)
+		}
+	}
+	imported := stablehlo.New(plat, "cond", &compiler{})
+	outs, err := stablehlo.Import(imported, []byte(mod.Text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported, err := imported.Module(outs, nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reimported.Text, `"stablehlo.if"`) || !strings.Contains(reimported.Text, `"stablehlo.select"`) {
+		t.Errorf("imported module has no conditional or no select:\n%s", reimported.Text)
+	}
+	if _, err := g.Core().Cond(x, branch(t, g, "mul", ops.Mul, sh), branch(t, g, "div", ops.Div, sh), x, y); err == nil {
+		t.Errorf("expected an error for a non-boolean condition")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	plat := platformtest.New(1)
+	g := stablehlo.New(plat, "errors", &compiler{})
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 2), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := g.Core().Subgraph("sub", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.Math().Exp(x); err == nil {
+		t.Errorf("expected an error when using a node of the parent graph")
+	}
+	if _, err := g.Core().Reshape(x, []int{3}); err == nil {
+		t.Errorf("expected an error when reshaping to a different size")
+	}
+	if _, err := g.Module([]*ops.OutputNode{{Node: x}}, nil, nil); err == nil {
+		t.Errorf("expected an error when an argument is missing from the parameters")
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	plat := platformtest.New(1)
+	const body = `
+    %0 = "stablehlo.constant"() {value = dense<"0x0000803F"> : tensor<f32>} : () -> tensor<f32>
+    %1 = "stablehlo.add"(%arg0, %0) : (tensor<f32>, tensor<f32>) -> tensor<f32>
+    "func.return"(%1) : (tensor<f32>) -> ()
+  }
+}
+`
+	for _, test := range []struct {
+		name   string
+		module string
+		want   string
+	}{
+		{
+			name:   "bytecode",
+			module: "ML\xefR\x00",
+			want:   "MLIR bytecode not supported",
+		},
+		{
+			name:   "empty",
+			module: "",
+			want:   `line 1: expected "module"`,
+		},
+		{
+			name:   "no main",
+			module: "module @m {\n}\n",
+			want:   "module has no main function",
+		},
+		{
+			name:   "unterminated function",
+			module: "module @m {\n  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {\n",
+			want:   "line 3:",
+		},
+		{
+			name: "pretty form",
+			module: `module @m {
+  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {
+    %0 = stablehlo.add %arg0, %arg0 : tensor<f32>
+    "func.return"(%0) : (tensor<f32>) -> ()
+  }
+}
+`,
+			want: "line 3: only operations in the generic form are supported",
+		},
+		{
+			name: "unknown operation",
+			module: `module @m {
+  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {
+    %0 = "stablehlo.unknown"(%arg0) : (tensor<f32>) -> tensor<f32>
+    "func.return"(%0) : (tensor<f32>) -> ()
+  }
+}
+`,
+			want: "stablehlo.unknown: operation not supported",
+		},
+		{
+			name:   "undefined value",
+			module: "module @m {\n  func.func public @main(%arg1: tensor<f32>) -> (tensor<f32>) {" + body,
+			want:   "undefined value %arg0",
+		},
+		{
+			name:   "unsupported type",
+			module: "module @m {\n  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {" + strings.ReplaceAll(body, "tensor<f32>", "vector<f32>"),
+			want:   `type "vector<f32>" not supported`,
+		},
+		{
+			name: "missing return",
+			module: `module @m {
+  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {
+    %0 = "stablehlo.add"(%arg0, %arg0) : (tensor<f32>, tensor<f32>) -> tensor<f32>
+  }
+}
+`,
+			want: "missing return operation",
+		},
+		{
+			name: "invalid literal",
+			module: "module @m {\n  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {" +
+				strings.Replace(body, `"0x0000803F"`, `"0x0000803F00"`, 1),
+			want: "literal has 5 bytes but shape float32 requires 4 bytes",
+		},
+		{
+			name: "undefined function",
+			module: `module @m {
+  func.func public @main(%arg0: tensor<f32>) -> (tensor<f32>) {
+    %0 = "func.call"(%arg0) {callee = @f} : (tensor<f32>) -> tensor<f32>
+    "func.return"(%0) : (tensor<f32>) -> ()
+  }
+}
+`,
+			want: "undefined function @f",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := stablehlo.New(plat, "import", &compiler{})
+			_, err := stablehlo.Import(g, []byte(test.module))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %q but want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
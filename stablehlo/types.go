@@ -0,0 +1,232 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablehlo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/platform"
+	"github.com/gx-org/backend/shape"
+)
+
+// valueType is the type of a value: a tensor or a tuple.
+type valueType struct {
+	shape *shape.Shape
+	// elems are the types of the elements of a tuple, nil for tensors.
+	elems []valueType
+}
+
+func (t valueType) isTuple() bool {
+	return t.elems != nil
+}
+
+// elementType returns the MLIR type of the elements of an array.
+func elementType(plat platform.Platform, dt dtype.DataType) (string, error) {
+	switch platform.Resolve(plat, dt) {
+	case dtype.Bool:
+		return "i1", nil
+	case dtype.Int8:
+		return "i8", nil
+	case dtype.Int16:
+		return "i16", nil
+	case dtype.Int32:
+		return "i32", nil
+	case dtype.Int64:
+		return "i64", nil
+	case dtype.Uint8:
+		return "ui8", nil
+	case dtype.Uint16:
+		return "ui16", nil
+	case dtype.Uint32:
+		return "ui32", nil
+	case dtype.Uint64:
+		return "ui64", nil
+	case dtype.Bfloat16:
+		return "bf16", nil
+	case dtype.Float16:
+		return "f16", nil
+	case dtype.Float32:
+		return "f32", nil
+	case dtype.Float64:
+		return "f64", nil
+	case dtype.Complex64:
+		return "complex<f32>", nil
+	case dtype.Complex128:
+		return "complex<f64>", nil
+	case dtype.Int4:
+		return "i4", nil
+	case dtype.Uint4:
+		return "ui4", nil
+	}
+	return "", fmt.Errorf("data type %s not supported by StableHLO", dt)
+}
+
+// quantStorageType returns the storage type of a quantized type, for example "i8" or "u8".
+func quantStorageType(plat platform.Platform, dt dtype.DataType) (string, error) {
+	elem, err := elementType(plat, dt)
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(elem, "ui", "u", 1), nil
+}
+
+// quantType returns the MLIR type of quantized values.
+func quantType(plat platform.Platform, q *dtype.Quantization) (string, error) {
+	storage, err := quantStorageType(plat, q.Storage)
+	if err != nil {
+		return "", err
+	}
+	expressed, err := elementType(plat, q.Expressed)
+	if err != nil {
+		return "", err
+	}
+	params := make([]string, len(q.Scales))
+	for i, scale := range q.Scales {
+		params[i] = fmt.Sprintf("%s:%d", floatLiteral(scale), q.ZeroPoints[i])
+	}
+	if !q.IsPerAxis() {
+		return fmt.Sprintf("!quant.uniform<%s:%s, %s>", storage, expressed, params[0]), nil
+	}
+	return fmt.Sprintf("!quant.uniform<%s:%s:%d, {%s}>", storage, expressed, q.Axis, strings.Join(params, ",")), nil
+}
+
+// tensorType returns the MLIR type of an array, for example tensor<2x3xf32>.
+func tensorType(plat platform.Platform, sh *shape.Shape) (string, error) {
+	var elem string
+	var err error
+	if sh.Quant != nil {
+		elem, err = quantType(plat, sh.Quant)
+	} else {
+		elem, err = elementType(plat, sh.DType)
+	}
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("tensor<")
+	for _, axisLength := range sh.AxisLengths {
+		fmt.Fprintf(&b, "%dx", axisLength)
+	}
+	b.WriteString(elem)
+	b.WriteString(">")
+	return b.String(), nil
+}
+
+// mlirType returns the MLIR type of a value.
+func mlirType(plat platform.Platform, t valueType) (string, error) {
+	if !t.isTuple() {
+		return tensorType(plat, t.shape)
+	}
+	elems := make([]string, len(t.elems))
+	for i, elem := range t.elems {
+		var err error
+		if elems[i], err = mlirType(plat, elem); err != nil {
+			return "", err
+		}
+	}
+	return "tuple<" + strings.Join(elems, ", ") + ">", nil
+}
+
+// floatLiteral formats a float as an MLIR float literal, which requires a decimal point.
+func floatLiteral(v float64) string {
+	s := strconv.FormatFloat(v, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	return mantissa + "e" + exp
+}
+
+// denseLiteral returns the MLIR dense elements literal of an array.
+func denseLiteral(sh *shape.Shape, data []byte) (string, error) {
+	if dtype.IsSubByte(sh.DType) || sh.IsBitPacked() || !sh.IsContiguous() {
+		return "", fmt.Errorf("constant of shape %s not supported", sh)
+	}
+	if sh.Size() == 0 {
+		return "dense<>", nil
+	}
+	if sh.DType != dtype.Bool {
+		return fmt.Sprintf("dense<\"0x%s\">", strings.ToUpper(hex.EncodeToString(data))), nil
+	}
+	// Booleans are written as nested lists since their storage differs from MLIR packed bits.
+	var b strings.Builder
+	b.WriteString("dense<")
+	writeBools(&b, sh.AxisLengths, data)
+	b.WriteString(">")
+	return b.String(), nil
+}
+
+func writeBools(b *strings.Builder, axisLengths []int, data []byte) {
+	if len(axisLengths) == 0 {
+		b.WriteString(strconv.FormatBool(data[0] != 0))
+		return
+	}
+	stride := shape.Size(axisLengths[1:])
+	b.WriteString("[")
+	for i := range axisLengths[0] {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writeBools(b, axisLengths[1:], data[i*stride:])
+	}
+	b.WriteString("]")
+}
+
+// i64Array returns an MLIR dense array attribute of integers.
+func i64Array(vals []int) string {
+	if len(vals) == 0 {
+		return "array<i64>"
+	}
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = strconv.Itoa(v)
+	}
+	return "array<i64: " + strings.Join(strs, ", ") + ">"
+}
+
+// intList returns a list of integers, for example [0, 1].
+func intList(vals []int) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = strconv.Itoa(v)
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+// symbolName returns a valid MLIR symbol name.
+func symbolName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9', r == '.':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
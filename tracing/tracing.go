@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing provides a backend logging every call made by the interpreter.
+//
+// The tracing backend wraps any backend. Each builder call, Compile and Run is
+// forwarded to the wrapped backend and logged with its arguments, the shape of
+// its result and its duration.
+package tracing
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gx-org/backend"
+	"github.com/gx-org/backend/ops/intercept"
+)
+
+// Tracer is an interceptor writing one line per call to a writer.
+// A Tracer is safe for concurrent use.
+type Tracer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ intercept.Interceptor = (*Tracer)(nil)
+
+// NewTracer returns an interceptor writing the calls to w.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{w: w}
+}
+
+// New returns a backend forwarding all calls to b and logging them to w.
+func New(b backend.Backend, w io.Writer) backend.Backend {
+	return intercept.NewBackend(b, NewTracer(w))
+}
+
+// Before does nothing: calls are logged once they complete.
+func (t *Tracer) Before(*intercept.Call) error {
+	return nil
+}
+
+// After writes the call to the writer.
+func (t *Tracer) After(call *intercept.Call, err error) {
+	line := Format(call, err)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintln(t.w, line)
+}
+
+// Format returns a single-line description of a call including its duration.
+func Format(call *intercept.Call, err error) string {
+	line := fmt.Sprintf("%s [%s]", call, call.Duration())
+	if err != nil {
+		line += fmt.Sprintf(" error: %v", err)
+	}
+	return line
+}
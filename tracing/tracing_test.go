@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gx-org/backend/dtype"
+	"github.com/gx-org/backend/ops"
+	"github.com/gx-org/backend/ops/opstest"
+	"github.com/gx-org/backend/platform/platformtest"
+	"github.com/gx-org/backend/shape"
+	"github.com/gx-org/backend/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	var log strings.Builder
+	b := tracing.New(opstest.NewBackend(platformtest.New(1)), &log)
+	g, err := b.NewOps("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := g.Core().Argument("x", shape.Of(dtype.Float32, 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := g.Core().Reshape(x, []int{2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev, err := g.Platform().Device(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Compile(dev, []*ops.OutputNode{{Node: y, Shape: shape.Of(dtype.Float32, 2, 3)}}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	wants := []string{
+		"[main] %0 = core.Argument() name=x shape=[6]float32 index=0 -> [6]float32",
+		"[main] %1 = core.Reshape(%0) axisLengths=[2 3] -> [2][3]float32",
+		"[main] graph.Compile(%1) device=0 outputs=[[2][3]float32]",
+	}
+	if len(lines) != len(wants) {
+		t.Fatalf("got %d lines but want %d:\n%s", len(lines), len(wants), log.String())
+	}
+	for i, want := range wants {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d: got %q but want prefix %q", i, lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/backend/ops"
+)
+
+// Version is a revision of the interfaces defined by this module.
+// The major version changes when a required method is added to an interface.
+// The minor version changes when optional features are added.
+type Version struct {
+	Major, Minor int
+}
+
+// InterfaceVersion is the revision of the interfaces defined by this module.
+var InterfaceVersion = Version{Major: 2, Minor: 1}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d", v.Major, v.Minor)
+}
+
+// Compatible returns true if a backend implementing revision v can be used
+// by code written for revision want.
+func (v Version) Compatible(want Version) bool {
+	return v.Major == want.Major && v.Minor >= want.Minor
+}
+
+// Feature is an optional feature a backend may implement.
+type Feature string
+
+// Optional features.
+const (
+	// StreamsFeature is the execution of runners on streams (see ops.StreamRunner).
+	StreamsFeature Feature = "streams"
+	// QuantizationFeature is the support of quantized arrays.
+	QuantizationFeature Feature = "quantization"
+	// DonationFeature is the reuse of donated input buffers (see platform.Donate).
+	DonationFeature Feature = "donation"
+	// ShardingFeature is the compilation of graphs for multiple devices.
+	ShardingFeature Feature = "sharding"
+	// AsyncFeature is the cancellable execution of runners (see ops.AsyncRunner).
+	AsyncFeature Feature = "async"
+	// ProfilingFeature is the timing of runs and of their nodes (see ops.ProfilingRunner).
+	ProfilingFeature Feature = "profiling"
+	// DynamicShapesFeature is the compilation of graphs with dynamic axis lengths (see shape.AxisLength).
+	// Backends without this feature return errors wrapping ops.ErrDynamicShapes.
+	DynamicShapesFeature Feature = "dynamic_shapes"
+)
+
+// Versioned is implemented by backends reporting the revision of the interfaces
+// they implement and their optional features. Backends not implementing Versioned
+// are assumed to implement the major version of InterfaceVersion without optional features.
+type Versioned interface {
+	Backend
+
+	// InterfaceVersion returns the revision of the interfaces implemented by the backend.
+	InterfaceVersion() Version
+
+	// Features returns the optional features implemented by the backend.
+	Features() []Feature
+}
+
+// VersionOf returns the revision of the interfaces implemented by a backend.
+func VersionOf(b Backend) Version {
+	if v, ok := b.(Versioned); ok {
+		return v.InterfaceVersion()
+	}
+	return Version{Major: InterfaceVersion.Major}
+}
+
+// HasFeature returns true if a backend implements an optional feature.
+func HasFeature(b Backend, feature Feature) bool {
+	v, ok := b.(Versioned)
+	return ok && slices.Contains(v.Features(), feature)
+}
+
+// Negotiate checks that a backend implements a revision compatible with want,
+// all the required features, and all the required operations.
+func Negotiate(b Backend, want Version, features []Feature, opIDs []ops.OpID) error {
+	if got := VersionOf(b); !got.Compatible(want) {
+		return fmt.Errorf("backend implements interfaces %s: not compatible with %s", got, want)
+	}
+	var missing []string
+	for _, feature := range features {
+		if !HasFeature(b, feature) {
+			missing = append(missing, "feature "+string(feature))
+		}
+	}
+	caps := b.Capabilities()
+	for _, id := range opIDs {
+		if !caps.SupportsOp(id) {
+			missing = append(missing, "op "+string(id))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("backend does not support: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}